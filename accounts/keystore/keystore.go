@@ -552,6 +552,10 @@ func (ks *KeyStore) SignBLS(a accounts.Account, msg []byte, extraData []byte, us
 	//	return blscrypto.SerializedSignature{}, err
 	//}
 	//pk, err := bn256.UnmarshalPk(pubkey)
+	msg, err = blscrypto.PrepareMessage(msg, extraData, useComposite)
+	if err != nil {
+		return blscrypto.SerializedSignature{}, err
+	}
 	var sign *bn256.UnsafeSignature
 	if params.IsBN256Fork(fork, cur) {
 		sign, err = bn256.UnsafeSign2(blskey, msg)
@@ -29,6 +29,7 @@ import (
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/common/math"
 	"github.com/ethereum/go-ethereum/consensus/ethash"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/log"
 	ethparams "github.com/ethereum/go-ethereum/params"
@@ -41,6 +42,8 @@ import (
 	"github.com/mapprotocol/atlas/accounts/keystore"
 	"github.com/mapprotocol/atlas/accounts/scwallet"
 	"github.com/mapprotocol/atlas/chains"
+	"github.com/mapprotocol/atlas/chains/eth2"
+	"github.com/mapprotocol/atlas/chains/ethereum"
 	"github.com/mapprotocol/atlas/chains/interfaces"
 	"github.com/mapprotocol/atlas/consensus/misc"
 	"github.com/mapprotocol/atlas/core"
@@ -2068,9 +2071,13 @@ func (p *PublicHeaderStoreAPI) LatestState() (*state.StateDB, error) {
 	return statedb, nil
 }
 
+func (p *PublicHeaderStoreAPI) chainGroup(chainID uint64) (chains.ChainGroup, error) {
+	return chains.ChainType2ChainGroupAt(p.b.ChainConfig(), p.b.CurrentHeader().Number, chains.ChainType(chainID))
+}
+
 func (p *PublicHeaderStoreAPI) CurrentHeaderNumber(chainID uint64) (uint64, error) {
 	//return new(ethereum.Validate).GetCurrentHeaderNumber(chains.ChainType(chainID))
-	group, err := chains.ChainType2ChainGroup(chains.ChainType(chainID))
+	group, err := p.chainGroup(chainID)
 	if err != nil {
 		return 0, err
 	}
@@ -2092,7 +2099,7 @@ func (p *PublicHeaderStoreAPI) CurrentHeaderNumber(chainID uint64) (uint64, erro
 
 func (p *PublicHeaderStoreAPI) GetHashByNumber(chainID uint64, number uint64) (common.Hash, error) {
 	//return new(ethereum.Validate).GetHashByNumber(chains.ChainType(chainID), number)
-	group, err := chains.ChainType2ChainGroup(chains.ChainType(chainID))
+	group, err := p.chainGroup(chainID)
 	if err != nil {
 		return common.Hash{}, err
 	}
@@ -2109,7 +2116,7 @@ func (p *PublicHeaderStoreAPI) GetHashByNumber(chainID uint64, number uint64) (c
 }
 
 func (p *PublicHeaderStoreAPI) CurrentNumberAndHash(chainID uint64) (map[string]interface{}, error) {
-	group, err := chains.ChainType2ChainGroup(chains.ChainType(chainID))
+	group, err := p.chainGroup(chainID)
 	if err != nil {
 		return nil, err
 	}
@@ -2133,3 +2140,176 @@ func (p *PublicHeaderStoreAPI) CurrentNumberAndHash(chainID uint64) (map[string]
 	}
 	return nh, nil
 }
+
+var (
+	errNotEthereumStore = errors.New("chain is not followed by the ethereum header store")
+	errNotEth2Store     = errors.New("chain is not followed by the eth2 header store")
+	errHeaderNotFound   = errors.New("header not found")
+)
+
+// stateAt returns the state and the header of the given block, the latest one if
+// blockNrOrHash is nil.
+func (p *PublicHeaderStoreAPI) stateAt(ctx context.Context, blockNrOrHash *rpc.BlockNumberOrHash) (*state.StateDB, *types.Header, error) {
+	if blockNrOrHash == nil {
+		latest := rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
+		blockNrOrHash = &latest
+	}
+	statedb, header, err := p.b.StateAndHeaderByNumberOrHash(ctx, *blockNrOrHash)
+	if err != nil {
+		return nil, nil, err
+	}
+	if statedb == nil || header == nil {
+		return nil, nil, errors.New("failed to get state by block number or hash")
+	}
+	return statedb, header, nil
+}
+
+// ethereumStoreAt loads the ethereum header store of the chain at the given block.
+func (p *PublicHeaderStoreAPI) ethereumStoreAt(ctx context.Context, chainID uint64, blockNrOrHash *rpc.BlockNumberOrHash) (*ethereum.HeaderStore, *state.StateDB, error) {
+	statedb, header, err := p.stateAt(ctx, blockNrOrHash)
+	if err != nil {
+		return nil, nil, err
+	}
+	group, err := chains.ChainType2ChainGroupAt(p.b.ChainConfig(), header.Number, chains.ChainType(chainID))
+	if err != nil {
+		return nil, nil, err
+	}
+	if group != chains.ChainGroupETH {
+		return nil, nil, errNotEthereumStore
+	}
+	hs := ethereum.NewHeaderStore()
+	if err := hs.Load(statedb); err != nil {
+		return nil, nil, err
+	}
+	return hs, statedb, nil
+}
+
+func marshalEthereumHeader(hs *ethereum.HeaderStore, header *ethereum.Header, db *state.StateDB) map[string]interface{} {
+	hash := header.Hash()
+	number := header.Number.Uint64()
+	return map[string]interface{}{
+		"header":          header,
+		"hash":            hash,
+		"number":          hexutil.Uint64(number),
+		"totalDifficulty": (*hexutil.Big)(hs.GetTd(hash, number, db)),
+		"canonical":       hs.ReadCanonicalHash(number, db) == hash,
+	}
+}
+
+// GetHeaderByNumber returns the canonical header of an ethereum chain with the given
+// number, together with its total difficulty, as stored at the given atlas block.
+func (p *PublicHeaderStoreAPI) GetHeaderByNumber(ctx context.Context, chainID uint64, number hexutil.Uint64, blockNrOrHash *rpc.BlockNumberOrHash) (map[string]interface{}, error) {
+	hs, statedb, err := p.ethereumStoreAt(ctx, chainID, blockNrOrHash)
+	if err != nil {
+		return nil, err
+	}
+	header := hs.GetHeaderByNumber(uint64(number), statedb)
+	if header == nil {
+		return nil, errHeaderNotFound
+	}
+	return marshalEthereumHeader(hs, header, statedb), nil
+}
+
+// GetHeaderByHash returns the header of an ethereum chain with the given hash, which
+// may be on a side chain, as stored at the given atlas block. Only the most recent
+// ethereum.MaxHashLookupDepth heights are searched, older headers are looked up by
+// number.
+func (p *PublicHeaderStoreAPI) GetHeaderByHash(ctx context.Context, chainID uint64, hash common.Hash, blockNrOrHash *rpc.BlockNumberOrHash) (map[string]interface{}, error) {
+	hs, statedb, err := p.ethereumStoreAt(ctx, chainID, blockNrOrHash)
+	if err != nil {
+		return nil, err
+	}
+	header := hs.GetHeaderByHash(hash, statedb)
+	if header == nil {
+		return nil, errHeaderNotFound
+	}
+	return marshalEthereumHeader(hs, header, statedb), nil
+}
+
+// GetFinalizedHeader returns the finalized beacon header of an eth2 chain and its sync
+// committee period, as stored at the given atlas block.
+func (p *PublicHeaderStoreAPI) GetFinalizedHeader(ctx context.Context, chainID uint64, blockNrOrHash *rpc.BlockNumberOrHash) (map[string]interface{}, error) {
+	statedb, header, err := p.stateAt(ctx, blockNrOrHash)
+	if err != nil {
+		return nil, err
+	}
+	group, err := chains.ChainType2ChainGroupAt(p.b.ChainConfig(), header.Number, chains.ChainType(chainID))
+	if err != nil {
+		return nil, err
+	}
+	if group != chains.ChainGroupETH2 {
+		return nil, errNotEth2Store
+	}
+	hs := eth2.NewHeaderStore()
+	if err := hs.Load(statedb); err != nil {
+		return nil, err
+	}
+	finalized := hs.FinalizedHeader
+	return map[string]interface{}{
+		"slot":          hexutil.Uint64(finalized.Slot),
+		"proposerIndex": hexutil.Uint64(finalized.ProposerIndex),
+		"parentRoot":    hexutil.Bytes(finalized.ParentRoot),
+		"stateRoot":     hexutil.Bytes(finalized.StateRoot),
+		"bodyRoot":      hexutil.Bytes(finalized.BodyRoot),
+		"period":        hexutil.Uint64(hs.FinalizedPeriod()),
+		"number":        hexutil.Uint64(hs.CurNumber),
+		"hash":          hs.CurHash,
+	}, nil
+}
+
+// VerifyProofResult is the outcome of a receipt proof dry-run.
+type VerifyProofResult struct {
+	Success bool            `json:"success"`
+	Error   string          `json:"error,omitempty"`
+	Logs    []*ethtypes.Log `json:"logs"`
+}
+
+// VerifyProof runs the receipt proof verification of the tx verify contract against
+// the given atlas block without sending a transaction. The proof is encoded the same
+// way as for verifyProofData, a verification failure is reported in the result.
+func (p *PublicHeaderStoreAPI) VerifyProof(ctx context.Context, receiptProof hexutil.Bytes, blockNrOrHash *rpc.BlockNumberOrHash) (*VerifyProofResult, error) {
+	statedb, header, err := p.stateAt(ctx, blockNrOrHash)
+	if err != nil {
+		return nil, err
+	}
+	return dryRunVerifyProof(statedb, p.b.ChainConfig(), header.Number, receiptProof)
+}
+
+func dryRunVerifyProof(db types.StateDB, config *params.ChainConfig, number *big.Int, receiptProof []byte) (*VerifyProofResult, error) {
+	args := struct {
+		Router   common.Address
+		Coin     common.Address
+		SrcChain *big.Int
+		DstChain *big.Int
+		TxProve  []byte
+	}{}
+	if err := rlp.DecodeBytes(receiptProof, &args); err != nil {
+		return nil, fmt.Errorf("rlp decode receipt proof failed: %v", err)
+	}
+
+	result := &VerifyProofResult{Logs: []*ethtypes.Log{}}
+	fail := func(err error) (*VerifyProofResult, error) {
+		result.Error = err.Error()
+		return result, nil
+	}
+	if args.Router == (common.Address{}) {
+		return fail(errors.New("router address is empty"))
+	}
+	group, err := chains.ChainType2ChainGroupAt(config, number, chains.ChainType(args.SrcChain.Uint64()))
+	if err != nil {
+		return fail(err)
+	}
+	v, err := interfaces.VerifyFactory(group)
+	if err != nil {
+		return fail(err)
+	}
+	logs, err := v.Verify(db, args.Router, args.TxProve)
+	if err != nil {
+		return fail(err)
+	}
+	if err := rlp.DecodeBytes(logs, &result.Logs); err != nil {
+		return fail(fmt.Errorf("rlp decode logs failed: %v", err))
+	}
+	result.Success = true
+	return result, nil
+}
@@ -0,0 +1,48 @@
+package atlasapi
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mapprotocol/atlas/chains"
+	"github.com/mapprotocol/atlas/core/rawdb"
+	"github.com/mapprotocol/atlas/core/state"
+	"github.com/mapprotocol/atlas/params"
+)
+
+func TestDryRunVerifyProof(t *testing.T) {
+	db, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	encode := func(router common.Address, src chains.ChainType) []byte {
+		data, err := rlp.EncodeToBytes([]interface{}{
+			router, common.Address{}, new(big.Int).SetUint64(uint64(src)), big.NewInt(22776), []byte{},
+		})
+		assert.NoError(t, err)
+		return data
+	}
+
+	_, err := dryRunVerifyProof(db, params.TestChainConfig, big.NewInt(1), []byte{0x01})
+	assert.Error(t, err)
+
+	tests := []struct {
+		name  string
+		input []byte
+		want  string
+	}{
+		{"empty router", encode(common.Address{}, chains.ChainTypeETH), "router address is empty"},
+		{"unsupported chain", encode(common.Address{1}, 12345), chains.ErrNotSupportChain.Error()},
+		{"invalid proof", encode(common.Address{1}, chains.ChainTypeETH), "EOF"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := dryRunVerifyProof(db, params.TestChainConfig, big.NewInt(1), tt.input)
+			assert.NoError(t, err)
+			assert.False(t, res.Success)
+			assert.Contains(t, res.Error, tt.want)
+			assert.Empty(t, res.Logs)
+		})
+	}
+}
@@ -18,20 +18,22 @@
 package web3ext
 
 var Modules = map[string]string{
-	"admin":    AdminJs,
-	"clique":   CliqueJs,
-	"ethash":   EthashJs,
-	"debug":    DebugJs,
-	"eth":      EthJs,
-	"istanbul": Istanbul_JS,
-	"relayer":  Relayer_JS,
-	"miner":    MinerJs,
-	"net":      NetJs,
-	"personal": PersonalJs,
-	"rpc":      RpcJs,
-	"txpool":   TxpoolJs,
-	"les":      LESJs,
-	"vflux":    VfluxJs,
+	"admin":       AdminJs,
+	"clique":      CliqueJs,
+	"ethash":      EthashJs,
+	"debug":       DebugJs,
+	"eth":         EthJs,
+	"istanbul":    Istanbul_JS,
+	"relayer":     Relayer_JS,
+	"lightclient": LightClient_JS,
+	"chainsdb":    ChainsDB_JS,
+	"miner":       MinerJs,
+	"net":         NetJs,
+	"personal":    PersonalJs,
+	"rpc":         RpcJs,
+	"txpool":      TxpoolJs,
+	"les":         LESJs,
+	"vflux":       VfluxJs,
 }
 
 const Relayer_JS = `
@@ -74,6 +76,52 @@ web3._extend({
 });
 `
 
+const LightClient_JS = `
+web3._extend({
+	property: 'lightclient',
+	methods:
+	[
+		new web3._extend.Method({
+			name: 'getEpochUpdates',
+			call: 'lightclient_getEpochUpdates',
+			params: 2,
+			inputFormatter: [null, null]
+		}),
+	],
+	properties: []
+});
+`
+
+const ChainsDB_JS = `
+web3._extend({
+	property: 'chainsdb',
+	methods:
+	[
+		new web3._extend.Method({
+			name: 'currentHeader',
+			call: 'chainsdb_currentHeader',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'getHeaderByNumber',
+			call: 'chainsdb_getHeaderByNumber',
+			params: 2
+		}),
+		new web3._extend.Method({
+			name: 'getHeaderByHash',
+			call: 'chainsdb_getHeaderByHash',
+			params: 2
+		}),
+		new web3._extend.Method({
+			name: 'getHeadersByReceiptsRoot',
+			call: 'chainsdb_getHeadersByReceiptsRoot',
+			params: 2
+		}),
+	],
+	properties: []
+});
+`
+
 const Istanbul_JS = `
 web3._extend({
 	property: 'istanbul',
@@ -109,6 +157,23 @@ web3._extend({
 			params: 1,
 			inputFormatter: [web3._extend.formatters.inputBlockNumberFormatter]
 		}),
+		new web3._extend.Method({
+			name: 'getMmrProof',
+			call: 'istanbul_getMmrProof',
+			params: 2,
+			inputFormatter: [null, web3._extend.formatters.inputBlockNumberFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'getValidatorParticipation',
+			call: 'istanbul_getValidatorParticipation',
+			params: 2,
+			inputFormatter: [web3._extend.formatters.inputBlockNumberFormatter, web3._extend.formatters.inputBlockNumberFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'getSlashingEvents',
+			call: 'istanbul_getSlashingEvents',
+			params: 1
+		}),
 		new web3._extend.Method({
 			name: 'addProxy',
 			call: 'istanbul_addProxy',
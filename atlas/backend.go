@@ -30,6 +30,7 @@ import (
 	ethparams "github.com/ethereum/go-ethereum/params"
 	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/mapprotocol/atlas/chains/chainsdb"
 	"github.com/mapprotocol/atlas/chains/ethereum"
 	"math/big"
 	"runtime"
@@ -50,6 +51,7 @@ import (
 	"github.com/mapprotocol/atlas/consensus/consensustest"
 	"github.com/mapprotocol/atlas/consensus/istanbul"
 	istanbulBackend "github.com/mapprotocol/atlas/consensus/istanbul/backend"
+	istanbulSigner "github.com/mapprotocol/atlas/consensus/istanbul/signer"
 	"github.com/mapprotocol/atlas/core/bloombits"
 	"github.com/mapprotocol/atlas/core/chain"
 	"github.com/mapprotocol/atlas/core/indexer"
@@ -89,6 +91,9 @@ type Ethereum struct {
 	bloomIndexer      *indexer.ChainIndexer          // Bloom indexer operating during block imports
 	closeBloomHandler chan struct{}
 
+	chainsDb      ethdb.Database        // Index of the chains followed by the light clients, nil if disabled
+	chainsIndexer *indexer.ChainIndexer // Indexer mirroring the header store into chainsDb
+
 	APIBackend *EthAPIBackend
 
 	miner     *miner.Miner
@@ -208,6 +213,14 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 	}
 	eth.bloomIndexer.Start(eth.blockchain)
 
+	if config.ChainsDB {
+		if eth.chainsDb, err = stack.OpenDatabase("chainsdb", 0, 0, "atlas/db/chainsdb/", false); err != nil {
+			return nil, err
+		}
+		eth.chainsIndexer = chainsdb.NewIndexer(chainDb, eth.chainsDb, chainConfig)
+		eth.chainsIndexer.Start(eth.blockchain)
+	}
+
 	if config.TxPool.Journal != "" {
 		config.TxPool.Journal = stack.ResolvePath(config.TxPool.Journal)
 	}
@@ -320,6 +333,15 @@ func (s *Ethereum) APIs() []rpc.API {
 	// Append any APIs exposed explicitly by the consensus engine
 	apis = append(apis, s.engine.APIs(s.BlockChain())...)
 
+	if s.chainsDb != nil {
+		apis = append(apis, rpc.API{
+			Namespace: "chainsdb",
+			Version:   "1.0",
+			Service:   chainsdb.NewPublicChainsDBAPI(chainsdb.NewHeaderChainStore(s.chainsDb)),
+			Public:    true,
+		})
+	}
+
 	// Append all the local APIs and return
 	return append(apis, []rpc.API{
 		{
@@ -530,24 +552,10 @@ func (s *Ethereum) StartMining(threads int) error {
 		}
 
 		if istanbul, isIstanbul := s.engine.(*istanbulBackend.Backend); isIstanbul {
-			valAccount := accounts.Account{Address: validator}
-			wallet, err := s.accountManager.Find(valAccount)
-			if wallet == nil || err != nil {
-				log.Error("Validator account unavailable locally", "err", err)
-				return fmt.Errorf("signer missing: %v", err)
-			}
-			publicKey, err := wallet.GetPublicKey(valAccount)
-			if err != nil {
-				return fmt.Errorf("ECDSA public key missing: %v", err)
-			}
-			blswallet, err := s.accountManager.Find(accounts.Account{Address: blsbase})
-			if blswallet == nil || err != nil {
-				log.Error("BLSbase account unavailable locally", "err", err)
-				return fmt.Errorf("BLS signer missing: %v", err)
+			if err := s.authorizeValidator(istanbul, validator, blsbase); err != nil {
+				return err
 			}
 
-			istanbul.Authorize(validator, blsbase, publicKey, wallet.Decrypt, wallet.SignData, blswallet.SignBLS, wallet.SignHash)
-
 			if istanbul.IsProxiedValidator() {
 				if err := istanbul.StartProxiedValidatorEngine(); err != nil {
 					log.Error("Error in starting proxied validator engine", "err", err)
@@ -565,6 +573,48 @@ func (s *Ethereum) StartMining(threads int) error {
 	return nil
 }
 
+// authorizeValidator authorizes the istanbul engine to sign with the keys of the validator,
+// held by the remote signer if one is configured or else by the local keystore.
+func (s *Ethereum) authorizeValidator(istanbul *istanbulBackend.Backend, validator, blsbase common.Address) error {
+	if url := s.config.Istanbul.RemoteSigner; url != "" {
+		token, err := istanbulSigner.ReadToken(s.config.Istanbul.RemoteSignerTokenFile)
+		if err != nil {
+			log.Error("Cannot read the remote signer token", "err", err)
+			return fmt.Errorf("remote signer token unavailable: %v", err)
+		}
+		remote, err := istanbulSigner.DialRemote(url, token)
+		if err != nil {
+			log.Error("Cannot connect to the remote signer", "err", err)
+			return fmt.Errorf("remote signer unavailable: %v", err)
+		}
+		if err := istanbul.AuthorizeSigner(validator, blsbase, remote); err != nil {
+			remote.Close()
+			log.Error("Validator account unavailable on the remote signer", "err", err)
+			return fmt.Errorf("signer missing: %v", err)
+		}
+		return nil
+	}
+
+	valAccount := accounts.Account{Address: validator}
+	wallet, err := s.accountManager.Find(valAccount)
+	if wallet == nil || err != nil {
+		log.Error("Validator account unavailable locally", "err", err)
+		return fmt.Errorf("signer missing: %v", err)
+	}
+	publicKey, err := wallet.GetPublicKey(valAccount)
+	if err != nil {
+		return fmt.Errorf("ECDSA public key missing: %v", err)
+	}
+	blswallet, err := s.accountManager.Find(accounts.Account{Address: blsbase})
+	if blswallet == nil || err != nil {
+		log.Error("BLSbase account unavailable locally", "err", err)
+		return fmt.Errorf("BLS signer missing: %v", err)
+	}
+
+	istanbul.Authorize(validator, blsbase, publicKey, wallet.Decrypt, wallet.SignData, blswallet.SignBLS, wallet.SignHash, wallet.SignTx)
+	return nil
+}
+
 // StopMining terminates the miner, both at the consensus engine level as well as
 // at the block creation level.
 // todo ibft
@@ -661,6 +711,10 @@ func (s *Ethereum) Stop() error {
 	// Then stop everything else.
 	s.bloomIndexer.Close()
 	close(s.closeBloomHandler)
+	if s.chainsIndexer != nil {
+		s.chainsIndexer.Close()
+		s.chainsDb.Close()
+	}
 	s.txPool.Stop()
 	s.miner.Close()
 	s.blockchain.Stop()
@@ -1312,7 +1312,7 @@ func (d *Downloader) fetchBodies(from uint64) error {
 	var (
 		deliver = func(packet dataPack) (int, error) {
 			pack := packet.(*bodyPack)
-			return d.queue.DeliverBodies(pack.peerID, pack.transactions, pack.randomness, pack.epochSnarkData)
+			return d.queue.DeliverBodies(pack.peerID, pack.transactions, pack.randomness, pack.epochSnarkData, d.epochSnarkDataRequired)
 		}
 		expire   = func() map[string]int { return d.queue.ExpireBodies(d.peers.rates.TargetTimeout()) }
 		fetch    = func(p *peerConnection, req *fetchRequest) error { return p.FetchBodies(req) }
@@ -1816,6 +1816,15 @@ func (d *Downloader) calcPivot(height uint64) uint64 {
 	return computePivot(height, d.epoch)
 }
 
+// epochSnarkDataRequired reports whether the body of the block must carry epoch snark
+// data, which from the snark fork on is the case for the last block of each epoch.
+func (d *Downloader) epochSnarkDataRequired(header *types.Header) bool {
+	if !d.ibftConsensus || header.Number.Sign() == 0 || !d.lightchain.Config().IsSnark(header.Number) {
+		return false
+	}
+	return istanbul.IsLastBlockOfEpoch(header.Number.Uint64(), d.epoch)
+}
+
 // processFastSyncContent takes fetch results from the queue and writes them to the
 // database. It also controls the synchronisation of state nodes of the pivot block.
 func (d *Downloader) processFastSyncContent() error {
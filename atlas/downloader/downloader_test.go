@@ -457,7 +457,7 @@ func (dlp *downloadTesterPeer) RequestHeadersByNumber(origin uint64, amount int,
 // batches of block bodies from the particularly requested peer.
 func (dlp *downloadTesterPeer) RequestBodies(hashes []common.Hash) error {
 	txs := dlp.chain.bodies(hashes)
-	go dlp.dl.downloader.DeliverBodies(dlp.id, txs, []*types.Randomness{}, []*types.EpochSnarkData{})
+	go dlp.dl.downloader.DeliverBodies(dlp.id, txs, make([]*types.Randomness, len(txs)), make([]*types.EpochSnarkData, len(txs)))
 	return nil
 }
 
@@ -789,14 +789,30 @@ func (q *queue) DeliverHeaders(id string, headers []*types.Header, headerProcCh
 // DeliverBodies injects a block body retrieval response into the results queue.
 // The method returns the number of blocks bodies accepted from the delivery and
 // also wakes any threads waiting for data delivery.
-func (q *queue) DeliverBodies(id string, txLists [][]*types.Transaction, randomnessList []*types.Randomness, epochSnarkDataList []*types.EpochSnarkData) (int, error) {
+//
+// The epoch snark data is not committed by the header, so a body whose snark data
+// presence differs from what snarkDataRequired expects for the header is rejected
+// and fetched again from another peer. A nil snarkDataRequired skips the check. A
+// delivery whose randomness or snark data lists don't match its transaction lists is
+// rejected as a whole.
+func (q *queue) DeliverBodies(id string, txLists [][]*types.Transaction, randomnessList []*types.Randomness, epochSnarkDataList []*types.EpochSnarkData,
+	snarkDataRequired func(*types.Header) bool) (int, error) {
 	q.lock.Lock()
 	defer q.lock.Unlock()
 	trieHasher := trie.NewStackTrie(nil)
 	validate := func(index int, header *types.Header) error {
+		if len(randomnessList) != len(txLists) || len(epochSnarkDataList) != len(txLists) {
+			return errInvalidBody
+		}
 		if types.DeriveSha(types.Transactions(txLists[index]), trieHasher) != header.TxHash {
 			return errInvalidBody
 		}
+		if snarkDataRequired != nil {
+			snarkData := epochSnarkDataList[index]
+			if hasData := snarkData != nil && !snarkData.IsEmpty(); hasData != snarkDataRequired(header) {
+				return errInvalidBody
+			}
+		}
 		//if types.CalcUncleHash(uncleLists[index]) != header.UncleHash {
 		//	return errInvalidBody
 		//}
@@ -23,6 +23,7 @@ import (
 	params2 "github.com/mapprotocol/atlas/params"
 	"math/big"
 	"math/rand"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -257,6 +258,63 @@ func TestEmptyBlocks(t *testing.T) {
 	}
 }
 
+// TestDeliverBodiesEpochSnarkData checks that bodies whose epoch snark data does not
+// match the header are returned to the queue to be fetched from another peer.
+func TestDeliverBodiesEpochSnarkData(t *testing.T) {
+	q := newQueue(10, 10)
+	q.Prepare(1, FullSync)
+	q.Schedule(emptyChain.headers(), 1)
+
+	peer := dummyPeer("peer-1")
+	fetchReq, _, _ := q.ReserveBodies(peer, 4)
+	if fetchReq == nil || len(fetchReq.Headers) != 4 {
+		t.Fatal("there should be four body fetch tasks reserved")
+	}
+	epochBlock := fetchReq.Headers[2].Number.Uint64()
+	required := func(header *types.Header) bool { return header.Number.Uint64() == epochBlock }
+
+	var (
+		txs            [][]*types.Transaction
+		randomnessList []*types.Randomness
+		snarkDataList  []*types.EpochSnarkData
+	)
+	for range fetchReq.Headers {
+		txs = append(txs, nil)
+		randomnessList = append(randomnessList, &types.Randomness{})
+		snarkDataList = append(snarkDataList, &types.EpochSnarkData{})
+	}
+	// The body of the epoch block has been stripped of its snark data
+	accepted, err := q.DeliverBodies(peer.id, txs, randomnessList, snarkDataList, required)
+	if err == nil || !strings.Contains(err.Error(), errInvalidBody.Error()) {
+		t.Fatalf("delivery error mismatch: have %v, want %v", err, errInvalidBody)
+	}
+	if accepted != 2 {
+		t.Fatalf("accepted bodies mismatch: have %d, want 2", accepted)
+	}
+	if got, exp := q.blockTaskQueue.Size(), len(emptyChain.blocks)-2; got != exp {
+		t.Fatalf("block task queue mismatch: have %d, want %d", got, exp)
+	}
+
+	// Another peer delivers the body with its snark data
+	other := dummyPeer("peer-2")
+	fetchReq, _, _ = q.ReserveBodies(other, 2)
+	if fetchReq == nil || fetchReq.Headers[0].Number.Uint64() != epochBlock {
+		t.Fatal("the epoch block should be fetched again")
+	}
+	snarkDataList = []*types.EpochSnarkData{{Bitmap: big.NewInt(1), Signature: []byte{0x01}}, {}}
+	// A delivery missing the randomness of a body is rejected as a whole
+	accepted, err = q.DeliverBodies(other.id, txs[:2], randomnessList[:1], snarkDataList, required)
+	if err == nil || !strings.Contains(err.Error(), errInvalidBody.Error()) || accepted != 0 {
+		t.Fatalf("delivery mismatch: have %d, %v, want 0, %v", accepted, err, errInvalidBody)
+	}
+	if fetchReq, _, _ = q.ReserveBodies(other, 2); fetchReq == nil || fetchReq.Headers[0].Number.Uint64() != epochBlock {
+		t.Fatal("the epoch block should be fetched again")
+	}
+	if accepted, err = q.DeliverBodies(other.id, txs[:2], randomnessList[:2], snarkDataList, required); err != nil || accepted != 2 {
+		t.Fatalf("delivery mismatch: have %d, %v, want 2, nil", accepted, err)
+	}
+}
+
 // TestDelivery does some more extensive testing of events that happen,
 // blocks that become known and peers that make reservations and deliveries.
 // disabled since it's not really a unit-test, but can be executed to test
@@ -324,7 +382,7 @@ func TestDelivery(t *testing.T) {
 					epochSnarkDataList = append(epochSnarkDataList, &types.EpochSnarkData{})
 				}
 				time.Sleep(100 * time.Millisecond)
-				_, err := q.DeliverBodies(peer.id, txs, randomnessList, epochSnarkDataList)
+				_, err := q.DeliverBodies(peer.id, txs, randomnessList, epochSnarkDataList, nil)
 				if err != nil {
 					fmt.Printf("delivered %d bodies %v\n", len(txs), err)
 				}
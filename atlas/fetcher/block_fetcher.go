@@ -806,6 +806,10 @@ func (f *BlockFetcher) importBlocks(peer string, block *types.Block) {
 		// Run the actual import and log any issues
 		if _, err := f.insertChain(types.Blocks{block}); err != nil {
 			log.Debug("Propagated block import failed", "peer", peer, "number", block.Number(), "hash", hash, "err", err)
+			// The peer tampered with the body, drop it so the block is fetched from another one
+			if errors.Is(err, consensus.ErrInvalidEpochSnarkData) {
+				f.dropPeer(peer)
+			}
 			return
 		}
 		// If import succeeded, broadcast the block
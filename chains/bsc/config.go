@@ -0,0 +1,89 @@
+package bsc
+
+import (
+	"math/big"
+
+	"github.com/mapprotocol/atlas/chains"
+)
+
+const (
+	defaultEpoch      = uint64(200)  // Default number of blocks after which to checkpoint the validator set
+	lorentzEpoch      = uint64(500)  // Number of blocks between checkpoints since Lorentz
+	maxwellEpoch      = uint64(1000) // Number of blocks between checkpoints since Maxwell
+	defaultTurnLength = uint64(1)    // Number of consecutive blocks an in-turn validator seals before Bohr
+)
+
+// EpochFork changes the number of blocks between validator set checkpoints from its
+// activation time on.
+type EpochFork struct {
+	Time  uint64 // activation time, in seconds
+	Epoch uint64
+}
+
+// Config are the parlia parameters of a BNB Smart Chain network. The turn length is
+// not scheduled, since Bohr it is carried by every epoch block.
+type Config struct {
+	ChainID    *big.Int
+	Epoch      uint64      // Epoch length at genesis
+	EpochForks []EpochFork // Epoch length changes, ordered by time
+	LubanBlock *big.Int    // Validator BLS keys are added to the epoch extra data
+	BohrTime   *uint64     // Turn length is added to the epoch extra data
+}
+
+func newUint64(v uint64) *uint64 {
+	return &v
+}
+
+var mainnetConfig = &Config{
+	ChainID: big.NewInt(56),
+	Epoch:   defaultEpoch,
+	EpochForks: []EpochFork{
+		{Time: 1_745_903_100, Epoch: lorentzEpoch}, // Lorentz
+		{Time: 1_751_250_600, Epoch: maxwellEpoch}, // Maxwell
+	},
+	LubanBlock: big.NewInt(29_020_050),
+	BohrTime:   newUint64(1_727_317_200),
+}
+
+var testnetConfig = &Config{
+	ChainID: big.NewInt(97),
+	Epoch:   defaultEpoch,
+	EpochForks: []EpochFork{
+		{Time: 1_744_097_580, Epoch: lorentzEpoch}, // Lorentz
+		{Time: 1_748_243_100, Epoch: maxwellEpoch}, // Maxwell
+	},
+	LubanBlock: big.NewInt(29_295_050),
+	BohrTime:   newUint64(1_724_116_996),
+}
+
+// getConfig returns the network configuration registered for the chain.
+func getConfig(chainID uint64) (*Config, error) {
+	network, err := chains.Network(chains.ChainGroupBSC, chains.ChainType(chainID))
+	if err != nil {
+		return nil, err
+	}
+	c, ok := network.(*Config)
+	if !ok {
+		return nil, chains.ErrNotSupportChain
+	}
+	return c, nil
+}
+
+func (c *Config) IsLuban(num *big.Int) bool {
+	return c.LubanBlock != nil && num != nil && c.LubanBlock.Cmp(num) <= 0
+}
+
+func (c *Config) IsBohr(time uint64) bool {
+	return c.BohrTime != nil && *c.BohrTime <= time
+}
+
+// EpochAt returns the epoch length scheduled at the given time. The chain switches to
+// it at the first block after which a new epoch of that length starts.
+func (c *Config) EpochAt(time uint64) uint64 {
+	for i := len(c.EpochForks) - 1; i >= 0; i-- {
+		if c.EpochForks[i].Time <= time {
+			return c.EpochForks[i].Epoch
+		}
+	}
+	return c.Epoch
+}
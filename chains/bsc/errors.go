@@ -0,0 +1,22 @@
+package bsc
+
+import "errors"
+
+var (
+	errNotInitialized     = errors.New("please initialize header store")
+	errMissingVanity      = errors.New("extra-data 32 byte vanity prefix missing")
+	errMissingSignature   = errors.New("extra-data 65 byte signature suffix missing")
+	errInvalidValidators  = errors.New("invalid validator list in epoch block")
+	errExtraValidators    = errors.New("non-epoch block contains validator list")
+	errInvalidTurnLength  = errors.New("invalid turn length")
+	errUnknownAncestor    = errors.New("unknown ancestor")
+	errInvalidNumber      = errors.New("invalid block number")
+	errOlderBlockTime     = errors.New("timestamp older than parent")
+	errUnauthorizedSigner = errors.New("unauthorized validator")
+	errRecentlySigned     = errors.New("recently signed")
+	errCoinBaseMismatch   = errors.New("coinbase do not match with signature")
+	errWrongDifficulty    = errors.New("wrong difficulty")
+	errNotEpochBlock      = errors.New("light client must be initialized with an epoch block")
+	errNotConfirmed       = errors.New("block is not confirmed by enough validators")
+	errUnknownBlock       = errors.New("block is not stored in the light client")
+)
@@ -0,0 +1,210 @@
+package bsc
+
+import (
+	"bytes"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+	"golang.org/x/crypto/sha3"
+
+	"github.com/mapprotocol/atlas/chains"
+)
+
+const (
+	extraVanity = 32 // Fixed number of extra-data prefix bytes reserved for signer vanity
+	extraSeal   = 65 // Fixed number of extra-data suffix bytes reserved for signer seal
+
+	validatorBytesLengthBeforeLuban = common.AddressLength
+	validatorBytesLength            = common.AddressLength + 48 // address and BLS public key
+	validatorNumberSize             = 1                         // Fixed number of extra prefix bytes reserved for validator number after Luban
+	turnLengthSize                  = 1                         // Fixed number of extra-data suffix bytes reserved for turnLength after Bohr
+)
+
+var (
+	diffInTurn = big.NewInt(2) // Block difficulty for in-turn signatures
+	diffNoTurn = big.NewInt(1) // Block difficulty for out-of-turn signatures
+)
+
+// Header is a BNB Smart Chain block header.
+type Header struct {
+	ParentHash  common.Hash      `json:"parentHash"       gencodec:"required"`
+	UncleHash   common.Hash      `json:"sha3Uncles"       gencodec:"required"`
+	Coinbase    common.Address   `json:"miner"            gencodec:"required"`
+	Root        common.Hash      `json:"stateRoot"        gencodec:"required"`
+	TxHash      common.Hash      `json:"transactionsRoot" gencodec:"required"`
+	ReceiptHash common.Hash      `json:"receiptsRoot"     gencodec:"required"`
+	Bloom       types.Bloom      `json:"logsBloom"        gencodec:"required"`
+	Difficulty  *big.Int         `json:"difficulty"       gencodec:"required"`
+	Number      *big.Int         `json:"number"           gencodec:"required"`
+	GasLimit    uint64           `json:"gasLimit"         gencodec:"required"`
+	GasUsed     uint64           `json:"gasUsed"          gencodec:"required"`
+	Time        uint64           `json:"timestamp"        gencodec:"required"`
+	Extra       []byte           `json:"extraData"        gencodec:"required"`
+	MixDigest   common.Hash      `json:"mixHash"`
+	Nonce       types.BlockNonce `json:"nonce"`
+
+	// BaseFee was added by EIP-1559 and is ignored in legacy headers.
+	BaseFee *big.Int `json:"baseFeePerGas" rlp:"optional"`
+
+	// WithdrawalsHash, BlobGasUsed, ExcessBlobGas and ParentBeaconRoot were added by
+	// the Cancun upgrade and are ignored in legacy headers.
+	WithdrawalsHash  *common.Hash `json:"withdrawalsRoot" rlp:"optional"`
+	BlobGasUsed      *uint64      `json:"blobGasUsed" rlp:"optional"`
+	ExcessBlobGas    *uint64      `json:"excessBlobGas" rlp:"optional"`
+	ParentBeaconRoot *common.Hash `json:"parentBeaconBlockRoot" rlp:"optional"`
+}
+
+func (h *Header) Hash() common.Hash {
+	return rlpHash(h)
+}
+
+// Index returns the indexed part of the header, it implements chains.IIndexable.
+func (h *Header) Index(raw rlp.RawValue) (*chains.IndexedHeader, error) {
+	if h.Number == nil {
+		return nil, errInvalidNumber
+	}
+	return &chains.IndexedHeader{
+		Number:      h.Number.Uint64(),
+		Hash:        h.Hash(),
+		ParentHash:  h.ParentHash,
+		ReceiptHash: h.ReceiptHash,
+		Raw:         raw,
+	}, nil
+}
+
+func rlpHash(x interface{}) (h common.Hash) {
+	hw := sha3.NewLegacyKeccak256()
+	rlp.Encode(hw, x)
+	hw.Sum(h[:0])
+	return h
+}
+
+// SealHash returns the hash of a block prior to it being sealed.
+func SealHash(header *Header, chainID *big.Int) common.Hash {
+	return rlpHash(sigHeader(header, chainID))
+}
+
+func sigHeader(header *Header, chainID *big.Int) []interface{} {
+	fields := []interface{}{
+		chainID,
+		header.ParentHash,
+		header.UncleHash,
+		header.Coinbase,
+		header.Root,
+		header.TxHash,
+		header.ReceiptHash,
+		header.Bloom,
+		header.Difficulty,
+		header.Number,
+		header.GasLimit,
+		header.GasUsed,
+		header.Time,
+		header.Extra[:len(header.Extra)-extraSeal], // Yes, this will panic if extra is too short
+		header.MixDigest,
+		header.Nonce,
+	}
+	if header.ParentBeaconRoot != nil && *header.ParentBeaconRoot == (common.Hash{}) {
+		fields = append(fields,
+			header.BaseFee,
+			header.WithdrawalsHash,
+			header.BlobGasUsed,
+			header.ExcessBlobGas,
+			header.ParentBeaconRoot,
+		)
+	}
+	return fields
+}
+
+// ecrecover extracts the validator address from a signed header.
+func ecrecover(header *Header, chainID *big.Int) (common.Address, error) {
+	if len(header.Extra) < extraSeal {
+		return common.Address{}, errMissingSignature
+	}
+	signature := header.Extra[len(header.Extra)-extraSeal:]
+
+	pubkey, err := crypto.Ecrecover(SealHash(header, chainID).Bytes(), signature)
+	if err != nil {
+		return common.Address{}, err
+	}
+	var signer common.Address
+	copy(signer[:], crypto.Keccak256(pubkey[1:])[12:])
+	return signer, nil
+}
+
+// validatorBytes returns the validator list carried in the extra data of an epoch block.
+func validatorBytes(header *Header, config *Config) ([]byte, error) {
+	if len(header.Extra) < extraVanity {
+		return nil, errMissingVanity
+	}
+	if len(header.Extra) < extraVanity+extraSeal {
+		return nil, errMissingSignature
+	}
+	if !config.IsLuban(header.Number) {
+		data := header.Extra[extraVanity : len(header.Extra)-extraSeal]
+		if len(data)%validatorBytesLengthBeforeLuban != 0 {
+			return nil, errInvalidValidators
+		}
+		return data, nil
+	}
+
+	if len(header.Extra) <= extraVanity+extraSeal {
+		return nil, nil
+	}
+	num := int(header.Extra[extraVanity])
+	start := extraVanity + validatorNumberSize
+	end := start + num*validatorBytesLength
+	extraMinLen := end + extraSeal
+	if config.IsBohr(header.Time) {
+		extraMinLen += turnLengthSize
+	}
+	if num == 0 || len(header.Extra) < extraMinLen {
+		return nil, errInvalidValidators
+	}
+	return header.Extra[start:end], nil
+}
+
+// parseValidators returns the sorted validator set of an epoch block.
+func parseValidators(header *Header, config *Config) ([]common.Address, error) {
+	data, err := validatorBytes(header, config)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return nil, errInvalidValidators
+	}
+
+	size := validatorBytesLengthBeforeLuban
+	if config.IsLuban(header.Number) {
+		size = validatorBytesLength
+	}
+	validators := make([]common.Address, len(data)/size)
+	for i := range validators {
+		copy(validators[i][:], data[i*size:i*size+common.AddressLength])
+	}
+	sort.Slice(validators, func(i, j int) bool {
+		return bytes.Compare(validators[i][:], validators[j][:]) < 0
+	})
+	return validators, nil
+}
+
+// parseTurnLength returns the number of consecutive blocks sealed by the in-turn
+// validator, as set by an epoch block.
+func parseTurnLength(header *Header, config *Config) (uint64, error) {
+	if !config.IsBohr(header.Time) {
+		return defaultTurnLength, nil
+	}
+	num := int(header.Extra[extraVanity])
+	pos := extraVanity + validatorNumberSize + num*validatorBytesLength
+	if len(header.Extra) < pos+turnLengthSize+extraSeal {
+		return 0, errInvalidTurnLength
+	}
+	turnLength := uint64(header.Extra[pos])
+	if turnLength == 0 {
+		return 0, errInvalidTurnLength
+	}
+	return turnLength, nil
+}
@@ -0,0 +1,167 @@
+package bsc
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
+
+	"github.com/mapprotocol/atlas/chains"
+	"github.com/mapprotocol/atlas/core/types"
+	"github.com/mapprotocol/atlas/params"
+)
+
+var (
+	stateStore = chains.NewStateStore(chains.BSCHeaderStoreAddress)
+	headerRing = chains.NewHeaderRing(chains.BSCHeaderStoreAddress, "bsc")
+)
+
+// HeaderStore is the parlia light client of a BNB Smart Chain network.
+type HeaderStore struct {
+	ChainID  uint64
+	Snapshot *Snapshot
+}
+
+type LightHeader = chains.LightHeader
+
+// resetInput initializes the light client with a trusted epoch block of the chain.
+type resetInput struct {
+	ChainID uint64
+	Header  *Header
+}
+
+func NewHeaderStore() *HeaderStore {
+	return &HeaderStore{}
+}
+
+func (hs *HeaderStore) config() (*Config, error) {
+	return getConfig(hs.ChainID)
+}
+
+// headerChain returns the snapshot of the light client advanced by its headers.
+func (hs *HeaderStore) headerChain() (*snapshotChain, error) {
+	config, err := hs.config()
+	if err != nil {
+		return nil, err
+	}
+	return &snapshotChain{snap: hs.Snapshot, config: config}, nil
+}
+
+// ResetHeaderStore initializes the light client with a trusted epoch block, encoded
+// as rlp(chainID, header). The block must start an epoch of the length scheduled at
+// its time.
+func (hs *HeaderStore) ResetHeaderStore(db types.StateDB, input []byte, td *big.Int) error {
+	var ri resetInput
+	if err := rlp.DecodeBytes(input, &ri); err != nil {
+		log.Error("rlp decode bsc reset input failed", "err", err)
+		return chains.ErrRLPDecode
+	}
+	if ri.Header == nil || ri.Header.Number == nil {
+		return errInvalidNumber
+	}
+	config, err := getConfig(ri.ChainID)
+	if err != nil {
+		return err
+	}
+
+	header := ri.Header
+	number := header.Number.Uint64()
+	epoch := config.EpochAt(header.Time)
+	if number%epoch != 0 {
+		return errNotEpochBlock
+	}
+	validators, err := parseValidators(header, config)
+	if err != nil {
+		return err
+	}
+	turnLength, err := parseTurnLength(header, config)
+	if err != nil {
+		return err
+	}
+
+	h := &HeaderStore{
+		ChainID: ri.ChainID,
+		Snapshot: &Snapshot{
+			Number:      number,
+			Hash:        header.Hash(),
+			Time:        header.Time,
+			Validators:  validators,
+			TurnLength:  turnLength,
+			EpochLength: epoch,
+		},
+	}
+	if err := h.StoreHeader(db, &LightHeader{Number: number, Hash: header.Hash(), ReceiptHash: header.ReceiptHash}); err != nil {
+		return err
+	}
+	return h.Store(db)
+}
+
+func (hs *HeaderStore) Store(db types.StateDB) error {
+	return stateStore.Store(db, hs)
+}
+
+func (hs *HeaderStore) Load(db types.StateDB) error {
+	ok, err := stateStore.Load(db, hs)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errNotInitialized
+	}
+	return nil
+}
+
+func (hs *HeaderStore) StoreHeader(db types.StateDB, header *LightHeader) error {
+	return headerRing.Store(db, header)
+}
+
+// LoadHeader returns the stored header with the given number, or nil if it was
+// never stored or its slot has been reused since.
+func (hs *HeaderStore) LoadHeader(db types.StateDB, number uint64) (*LightHeader, error) {
+	return headerRing.Load(db, number)
+}
+
+// InsertHeaders extends the light client with already validated headers.
+func (hs *HeaderStore) InsertHeaders(db types.StateDB, input []byte) ([]*params.NumberHash, error) {
+	if err := hs.Load(db); err != nil {
+		return nil, err
+	}
+	chain, err := hs.headerChain()
+	if err != nil {
+		return nil, err
+	}
+	nums, err := chains.InsertHeaderChain(db, chain, headerRing, input)
+	if err != nil {
+		return nil, err
+	}
+	if err := hs.Store(db); err != nil {
+		return nil, err
+	}
+	log.Info("stored new bsc headers", "count", len(nums), "number", hs.Snapshot.Number, "hash", hs.Snapshot.Hash)
+	return nums, nil
+}
+
+func (hs *HeaderStore) GetCurrentNumberAndHash(db types.StateDB) (uint64, common.Hash, error) {
+	if err := hs.Load(db); err != nil {
+		return 0, common.Hash{}, err
+	}
+	return hs.Snapshot.Number, hs.Snapshot.Hash, nil
+}
+
+func (hs *HeaderStore) GetHashByNumber(db types.StateDB, number uint64) (common.Hash, error) {
+	if err := hs.Load(db); err != nil {
+		return common.Hash{}, err
+	}
+	header, err := hs.LoadHeader(db, number)
+	if err != nil || header == nil {
+		return common.Hash{}, err
+	}
+	return header.Hash, nil
+}
+
+// DecodeHeaders decodes the headers of an updateBlockHeader input for node-local
+// indexes, it implements chains.IHeaderDecoder.
+func (hs *HeaderStore) DecodeHeaders(input []byte, _ chains.ChainType) ([]*chains.IndexedHeader, error) {
+	return chains.DecodeIndexedHeaders(input, func() chains.IIndexable { return new(Header) })
+}
@@ -0,0 +1,153 @@
+package bsc
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mapprotocol/atlas/chains/chainstest"
+)
+
+func testSets() []*validatorSet {
+	return []*validatorSet{
+		newValidatorSet(chainstest.ECDSAKeys("genesis", 3), 1),
+		newValidatorSet(chainstest.ECDSAKeys("rotated", 4), 2),
+	}
+}
+
+func TestHeaderStore_InsertHeaders(t *testing.T) {
+	headers := makeChain(35, testSets(), nil)
+	db := chainstest.NewStore(t, NewHeaderStore(), &resetInput{ChainID: uint64(testChainType), Header: headers[0]})
+
+	v := new(Validate)
+	for _, batch := range [][]*Header{headers[1:12], headers[12:25], headers[25:]} {
+		_, err := v.ValidateHeaderChain(db, chainstest.EncodeRLP(batch), testChainType)
+		assert.NoError(t, err)
+		nums, err := NewHeaderStore().InsertHeaders(db, chainstest.EncodeRLP(batch))
+		assert.NoError(t, err)
+		assert.Equal(t, len(batch), len(nums))
+	}
+
+	hs := NewHeaderStore()
+	number, hash, err := hs.GetCurrentNumberAndHash(db)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(35), number)
+	assert.Equal(t, headers[35].Hash(), hash)
+	assert.Equal(t, testSets()[1].validators, hs.Snapshot.Validators)
+	assert.Equal(t, uint64(2), hs.Snapshot.TurnLength)
+
+	for _, h := range headers {
+		got, err := hs.GetHashByNumber(db, h.Number.Uint64())
+		assert.NoError(t, err)
+		assert.Equal(t, h.Hash(), got)
+	}
+}
+
+func TestHeaderStore_EpochFork(t *testing.T) {
+	sets := append(testSets(), newValidatorSet(chainstest.ECDSAKeys("forked", 5), 3))
+
+	// the epoch blocks are 0, 10, ..., 50, 75 and 100, the ones after the fork announce
+	// the third set
+	headers := makeChain(110, []*validatorSet{sets[0], sets[1], sets[1], sets[1], sets[1], sets[1], sets[2]}, nil)
+	db := chainstest.NewStore(t, NewHeaderStore(), &resetInput{ChainID: uint64(testChainType), Header: headers[0]})
+	_, err := new(Validate).ValidateHeaderChain(db, chainstest.EncodeRLP(headers[1:]), testChainType)
+	assert.NoError(t, err)
+	_, err = NewHeaderStore().InsertHeaders(db, chainstest.EncodeRLP(headers[1:]))
+	assert.NoError(t, err)
+
+	hs := NewHeaderStore()
+	assert.NoError(t, hs.Load(db))
+	assert.Equal(t, uint64(25), hs.Snapshot.EpochLength)
+	assert.Equal(t, sets[2].validators, hs.Snapshot.Validators)
+	assert.Equal(t, uint64(3), hs.Snapshot.TurnLength)
+
+	// a chain keeping the epoch length of genesis misses the validators of block 75
+	legacy := *testConfig
+	legacy.EpochForks = nil
+	headers = makeChainWith(&legacy, 110, sets[:2], nil)
+	db = chainstest.NewStore(t, NewHeaderStore(), &resetInput{ChainID: uint64(testChainType), Header: headers[0]})
+	i, err := new(Validate).ValidateHeaderChain(db, chainstest.EncodeRLP(headers[1:]), testChainType)
+	assert.True(t, errors.Is(err, errInvalidValidators), "got %v, want %v", err, errInvalidValidators)
+	assert.Equal(t, 74, i)
+
+	// the store is reset with an epoch block of the scheduled epoch length
+	headers = makeChain(75, sets[:2], nil)
+	assert.NoError(t, NewHeaderStore().ResetHeaderStore(chainstest.NewStateDB(), chainstest.EncodeRLP(&resetInput{ChainID: uint64(testChainType), Header: headers[75]}), nil))
+	assert.Equal(t, errNotEpochBlock, NewHeaderStore().ResetHeaderStore(chainstest.NewStateDB(), chainstest.EncodeRLP(&resetInput{ChainID: uint64(testChainType), Header: headers[60]}), nil))
+}
+
+func TestHeaderStore_ResetNotEpoch(t *testing.T) {
+	headers := makeChain(1, testSets(), nil)
+	err := NewHeaderStore().ResetHeaderStore(chainstest.NewStateDB(), chainstest.EncodeRLP(&resetInput{ChainID: uint64(testChainType), Header: headers[1]}), nil)
+	assert.Equal(t, errNotEpochBlock, err)
+}
+
+func TestValidate_InvalidHeaders(t *testing.T) {
+	sets := testSets()
+	outsider := chainstest.ECDSAKeys("outsider", 1)[0]
+
+	tests := []struct {
+		name   string
+		index  int
+		modify func(h *Header)
+		want   error
+	}{
+		{
+			name: "unauthorized signer",
+			modify: func(h *Header) {
+				h.Coinbase = crypto.PubkeyToAddress(outsider.PublicKey)
+				sealHeader(h, outsider)
+			},
+			want: errUnauthorizedSigner,
+		},
+		{
+			name:   "coinbase mismatch",
+			modify: func(h *Header) { h.Coinbase = common.Address{0x01}; sealHeader(h, sets[0].keys[sets[0].inturn(1)]) },
+			want:   errCoinBaseMismatch,
+		},
+		{
+			name: "wrong difficulty",
+			modify: func(h *Header) {
+				h.Difficulty = new(big.Int).Set(diffNoTurn)
+				sealHeader(h, sets[0].keys[h.Coinbase])
+			},
+			want: errWrongDifficulty,
+		},
+		{
+			name:  "recently signed",
+			index: 1,
+			modify: func(h *Header) {
+				// the in-turn validator of block 1 seals block 2 as well
+				h.Coinbase = sets[0].inturn(1)
+				h.Difficulty = new(big.Int).Set(diffNoTurn)
+				sealHeader(h, sets[0].keys[h.Coinbase])
+			},
+			want: errRecentlySigned,
+		},
+		{
+			name:   "wrong number",
+			modify: func(h *Header) { h.Number = big.NewInt(2); sealHeader(h, sets[0].keys[h.Coinbase]) },
+			want:   errInvalidNumber,
+		},
+		{
+			name:   "unknown ancestor",
+			modify: func(h *Header) { h.ParentHash = common.Hash{0x01}; sealHeader(h, sets[0].keys[h.Coinbase]) },
+			want:   errUnknownAncestor,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			headers := makeChain(2, sets, nil)
+			db := chainstest.NewStore(t, NewHeaderStore(), &resetInput{ChainID: uint64(testChainType), Header: headers[0]})
+			tt.modify(headers[tt.index+1])
+
+			i, err := new(Validate).ValidateHeaderChain(db, chainstest.EncodeRLP(headers[1:]), testChainType)
+			assert.True(t, errors.Is(err, tt.want), "got %v, want %v", err, tt.want)
+			assert.Equal(t, tt.index, i)
+		})
+	}
+}
@@ -0,0 +1,140 @@
+package bsc
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/mapprotocol/atlas/chains"
+	"github.com/mapprotocol/atlas/chains/chainstest"
+)
+
+const (
+	testChainType chains.ChainType = 1_000_056
+	testGenesis                    = uint64(1_700_000_000)
+	testBlockTime                  = uint64(3)
+)
+
+// testConfig raises the epoch length from 10 to 25 blocks at the time of block 45, so
+// that block 50 is the first epoch block of 25 blocks.
+var testConfig = &Config{
+	ChainID:    big.NewInt(1_000_056),
+	Epoch:      10,
+	EpochForks: []EpochFork{{Time: testGenesis + 45*testBlockTime, Epoch: 25}},
+	LubanBlock: big.NewInt(0),
+	BohrTime:   newUint64(0),
+}
+
+func init() {
+	chainstest.RegisterChain(chains.ChainGroupBSC, testChainType, testConfig)
+}
+
+// validatorSet is a sorted validator set with the keys of its members.
+type validatorSet struct {
+	validators []common.Address
+	keys       map[common.Address]*ecdsa.PrivateKey
+	turnLength uint64
+}
+
+func newValidatorSet(keys []*ecdsa.PrivateKey, turnLength uint64) *validatorSet {
+	s := &validatorSet{keys: make(map[common.Address]*ecdsa.PrivateKey), turnLength: turnLength}
+	for _, key := range keys {
+		addr := crypto.PubkeyToAddress(key.PublicKey)
+		s.keys[addr] = key
+		s.validators = append(s.validators, addr)
+	}
+	sort.Slice(s.validators, func(i, j int) bool {
+		return bytes.Compare(s.validators[i][:], s.validators[j][:]) < 0
+	})
+	return s
+}
+
+func (s *validatorSet) inturn(number uint64) common.Address {
+	return s.validators[(number/s.turnLength)%uint64(len(s.validators))]
+}
+
+func (s *validatorSet) switchOffset() uint64 {
+	return (uint64(len(s.validators))/2+1)*s.turnLength - 1
+}
+
+func epochExtra(next *validatorSet) []byte {
+	extra := make([]byte, extraVanity)
+	extra = append(extra, byte(len(next.validators)))
+	for _, v := range next.validators {
+		extra = append(extra, v.Bytes()...)
+		extra = append(extra, make([]byte, 48)...)
+	}
+	extra = append(extra, byte(next.turnLength))
+	return append(extra, make([]byte, extraSeal)...)
+}
+
+func sealHeader(header *Header, key *ecdsa.PrivateKey) {
+	sig, err := crypto.Sign(SealHash(header, testConfig.ChainID).Bytes(), key)
+	if err != nil {
+		panic(err)
+	}
+	copy(header.Extra[len(header.Extra)-extraSeal:], sig)
+}
+
+// makeChain creates the epoch block 0 sealed by the first validator set and n blocks
+// on top of it. Every epoch block announces the next set of the schedule, sets[0]
+// being the genesis set.
+func makeChain(n int, sets []*validatorSet, receiptHashes map[uint64]common.Hash) []*Header {
+	return makeChainWith(testConfig, n, sets, receiptHashes)
+}
+
+// makeChainWith is like makeChain, with the epoch blocks of the schedule of config.
+func makeChainWith(config *Config, n int, sets []*validatorSet, receiptHashes map[uint64]common.Hash) []*Header {
+	var (
+		headers     = make([]*Header, 0, n+1)
+		current     = sets[0]
+		pending     *validatorSet
+		epoch       uint64
+		epochs      int
+		epochLength = config.EpochAt(testGenesis)
+		parent      common.Hash
+	)
+	for i := 0; i <= n; i++ {
+		number := uint64(i)
+		signer := current.inturn(number)
+		header := &Header{
+			ParentHash:  parent,
+			UncleHash:   ethtypes.EmptyUncleHash,
+			Coinbase:    signer,
+			ReceiptHash: receiptHashes[number],
+			Difficulty:  new(big.Int).Set(diffInTurn),
+			Number:      new(big.Int).SetUint64(number),
+			GasLimit:    30_000_000,
+			Time:        testGenesis + testBlockTime*number,
+			Extra:       make([]byte, extraVanity+extraSeal),
+		}
+		if number%epochLength == 0 {
+			idx := epochs
+			if idx >= len(sets) {
+				idx = len(sets) - 1
+			}
+			pending, epoch = sets[idx], number
+			header.Extra = epochExtra(pending)
+			epochs++
+		}
+		sealHeader(header, current.keys[signer])
+		headers = append(headers, header)
+		parent = header.Hash()
+
+		if pending != nil && number > 0 && number-epoch == current.switchOffset() {
+			current, pending = pending, nil
+		}
+		if number == 0 {
+			pending = nil
+		}
+		if next := config.EpochAt(header.Time); next != epochLength && (number+1)%next == 0 {
+			epochLength = next
+		}
+	}
+	return headers
+}
@@ -0,0 +1,22 @@
+package bsc
+
+import (
+	"math/big"
+
+	"github.com/mapprotocol/atlas/chains"
+	"github.com/mapprotocol/atlas/params"
+)
+
+func init() {
+	chains.Register(&chains.Module{
+		Group: chains.ChainGroupBSC,
+		Chains: map[chains.ChainType]*chains.ChainParams{
+			chains.ChainTypeBSC:     {AtlasChainID: params.MainNetChainID, Network: mainnetConfig},
+			chains.ChainTypeBSCTest: {AtlasChainID: params.TestNetChainID, Network: testnetConfig},
+		},
+		ForkBlock:      func(config *params.ChainConfig) *big.Int { return config.BSCBlock },
+		NewValidate:    func() chains.IValidate { return new(Validate) },
+		NewHeaderStore: func() chains.IHeaderStore { return new(HeaderStore) },
+		NewVerify:      func() chains.IVerify { return new(Verify) },
+	})
+}
@@ -0,0 +1,173 @@
+package bsc
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
+
+	"github.com/mapprotocol/atlas/chains"
+)
+
+// Recent is a block sealed by a validator in the recent history.
+type Recent struct {
+	Number uint64
+	Signer common.Address
+}
+
+// PendingValidators is a validator set announced by an epoch block, which is
+// switched to once enough blocks have been sealed by the previous set.
+type PendingValidators struct {
+	Number     uint64
+	Validators []common.Address
+	TurnLength uint64
+}
+
+// Snapshot is the parlia authorization state at the head of the light client.
+type Snapshot struct {
+	Number      uint64
+	Hash        common.Hash
+	Time        uint64
+	Validators  []common.Address
+	TurnLength  uint64
+	EpochLength uint64
+	Recents     []Recent
+	Pending     *PendingValidators `rlp:"nil"`
+}
+
+func (s *Snapshot) isValidator(signer common.Address) bool {
+	for _, v := range s.Validators {
+		if v == signer {
+			return true
+		}
+	}
+	return false
+}
+
+// inturn returns if a validator at a given block height is in-turn or not.
+func (s *Snapshot) inturn(number uint64, signer common.Address) bool {
+	offset := (number / s.TurnLength) % uint64(len(s.Validators))
+	return s.Validators[offset] == signer
+}
+
+// minerHistoryCheckLen is the number of recent blocks a validator may seal at most
+// TurnLength of.
+func (s *Snapshot) minerHistoryCheckLen() uint64 {
+	return (uint64(len(s.Validators))/2+1)*s.TurnLength - 1
+}
+
+// confirmations is the number of blocks on top of a block after which it has been
+// sealed by a majority of the validators.
+func (s *Snapshot) confirmations() uint64 {
+	return s.minerHistoryCheckLen() + 1
+}
+
+func (s *Snapshot) signedRecently(number uint64, signer common.Address) bool {
+	times := uint64(0)
+	for _, r := range s.Recents {
+		if r.Number+s.minerHistoryCheckLen() >= number && r.Signer == signer {
+			times++
+		}
+	}
+	return times >= s.TurnLength
+}
+
+// apply advances the snapshot with the next header of the chain.
+func (s *Snapshot) apply(header *Header, config *Config) error {
+	number := header.Number.Uint64()
+	if number != s.Number+1 {
+		return errInvalidNumber
+	}
+	if header.ParentHash != s.Hash {
+		return errUnknownAncestor
+	}
+	if header.Time < s.Time {
+		return errOlderBlockTime
+	}
+	if header.Difficulty == nil {
+		return errWrongDifficulty
+	}
+
+	isEpoch := number%s.EpochLength == 0
+	if !isEpoch && !config.IsLuban(header.Number) && len(header.Extra) != extraVanity+extraSeal {
+		return errExtraValidators
+	}
+
+	signer, err := ecrecover(header, config.ChainID)
+	if err != nil {
+		return err
+	}
+	if signer != header.Coinbase {
+		return errCoinBaseMismatch
+	}
+	if !s.isValidator(signer) {
+		return fmt.Errorf("%w: %v", errUnauthorizedSigner, signer)
+	}
+	if s.signedRecently(number, signer) {
+		return fmt.Errorf("%w: %v", errRecentlySigned, signer)
+	}
+	inturn := s.inturn(number, signer)
+	if inturn && header.Difficulty.Cmp(diffInTurn) != 0 {
+		return errWrongDifficulty
+	}
+	if !inturn && header.Difficulty.Cmp(diffNoTurn) != 0 {
+		return errWrongDifficulty
+	}
+
+	if isEpoch {
+		validators, err := parseValidators(header, config)
+		if err != nil {
+			return err
+		}
+		turnLength, err := parseTurnLength(header, config)
+		if err != nil {
+			return err
+		}
+		s.Pending = &PendingValidators{Number: number, Validators: validators, TurnLength: turnLength}
+	}
+
+	s.Recents = append(s.Recents, Recent{Number: number, Signer: signer})
+	limit := s.minerHistoryCheckLen()
+	for len(s.Recents) > 0 && s.Recents[0].Number+limit < number {
+		s.Recents = s.Recents[1:]
+	}
+
+	// the validator set announced at the epoch block takes effect once the previous
+	// set had the chance to seal the blocks it is responsible for
+	if s.Pending != nil && number-s.Pending.Number == limit {
+		s.Validators, s.TurnLength = s.Pending.Validators, s.Pending.TurnLength
+		s.Pending = nil
+	}
+
+	// a scheduled epoch length is used from the first epoch boundary after its fork
+	if epoch := config.EpochAt(header.Time); epoch != s.EpochLength && (number+1)%epoch == 0 {
+		s.EpochLength = epoch
+	}
+
+	s.Number, s.Hash, s.Time = number, header.Hash(), header.Time
+	return nil
+}
+
+// snapshotChain advances a snapshot with the headers of the chain, it implements
+// chains.HeaderChain.
+type snapshotChain struct {
+	snap   *Snapshot
+	config *Config
+}
+
+func (c *snapshotChain) ApplyHeader(raw rlp.RawValue) (*chains.LightHeader, error) {
+	var header Header
+	if err := rlp.DecodeBytes(raw, &header); err != nil {
+		log.Error("rlp decode bsc header failed", "err", err)
+		return nil, chains.ErrRLPDecode
+	}
+	if header.Number == nil {
+		return nil, errInvalidNumber
+	}
+	if err := c.snap.apply(&header, c.config); err != nil {
+		log.Warn("invalid bsc header", "number", header.Number, "hash", header.Hash(), "err", err)
+		return nil, err
+	}
+	return &chains.LightHeader{Number: c.snap.Number, Hash: c.snap.Hash, ReceiptHash: header.ReceiptHash}, nil
+}
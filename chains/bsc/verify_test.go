@@ -0,0 +1,92 @@
+package bsc
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethdb/memorydb"
+	"github.com/ethereum/go-ethereum/light"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mapprotocol/atlas/chains/chainstest"
+)
+
+func receiptProof(t *testing.T, receipts ethtypes.Receipts, txIndex uint) (common.Hash, light.NodeList) {
+	tr, err := trie.New(common.Hash{}, trie.NewDatabase(memorydb.New()))
+	assert.NoError(t, err)
+	for i := range receipts {
+		key, err := rlp.EncodeToBytes(uint(i))
+		assert.NoError(t, err)
+		var buf bytes.Buffer
+		receipts.EncodeIndex(i, &buf)
+		tr.Update(key, buf.Bytes())
+	}
+
+	proof := light.NewNodeSet()
+	key, err := rlp.EncodeToBytes(txIndex)
+	assert.NoError(t, err)
+	assert.NoError(t, tr.Prove(key, 0, proof))
+	return tr.Hash(), proof.NodeList()
+}
+
+func TestVerify_Verify(t *testing.T) {
+	receipts := ethtypes.Receipts{
+		{Type: ethtypes.LegacyTxType, Status: ethtypes.ReceiptStatusSuccessful, CumulativeGasUsed: 21000, Logs: []*ethtypes.Log{}},
+		{Type: ethtypes.DynamicFeeTxType, Status: ethtypes.ReceiptStatusSuccessful, CumulativeGasUsed: 63000, Logs: []*ethtypes.Log{
+			{Address: common.HexToAddress("0xd6199276959b95a68c1ee30e8569f5fe060903a6"), Topics: []common.Hash{{0x01}}, Data: []byte{0x02}},
+		}},
+	}
+	root, prove := receiptProof(t, receipts, 1)
+
+	const proven = 5
+	headers := makeChain(12, testSets(), map[uint64]common.Hash{proven: root, 10: root})
+	db := chainstest.NewStore(t, NewHeaderStore(), &resetInput{ChainID: uint64(testChainType), Header: headers[0]})
+	_, err := NewHeaderStore().InsertHeaders(db, chainstest.EncodeRLP(headers[1:]))
+	assert.NoError(t, err)
+
+	input, err := rlp.EncodeToBytes(&TxProve{Receipt: receipts[1], Prove: prove, BlockNumber: proven, TxIndex: 1})
+	assert.NoError(t, err)
+	logs, err := new(Verify).Verify(db, common.Address{}, input)
+	assert.NoError(t, err)
+	want, _ := rlp.EncodeToBytes(receipts[1].Logs)
+	assert.Equal(t, want, logs)
+
+	// the receipt is not the proven one
+	input, _ = rlp.EncodeToBytes(&TxProve{Receipt: receipts[0], Prove: prove, BlockNumber: proven, TxIndex: 1})
+	_, err = new(Verify).Verify(db, common.Address{}, input)
+	assert.Error(t, err)
+
+	// the block has not been sealed over by a majority of the validators yet
+	input, _ = rlp.EncodeToBytes(&TxProve{Receipt: receipts[1], Prove: prove, BlockNumber: 11, TxIndex: 1})
+	_, err = new(Verify).Verify(db, common.Address{}, input)
+	assert.True(t, errors.Is(err, errNotConfirmed))
+}
+
+func TestParseValidatorsBeforeLuban(t *testing.T) {
+	set := newValidatorSet(chainstest.ECDSAKeys("legacy", 3), 1)
+	config := &Config{ChainID: big.NewInt(1), Epoch: defaultEpoch}
+
+	extra := make([]byte, extraVanity)
+	for i := len(set.validators) - 1; i >= 0; i-- {
+		extra = append(extra, set.validators[i].Bytes()...)
+	}
+	extra = append(extra, make([]byte, extraSeal)...)
+	header := &Header{Number: big.NewInt(200), Extra: extra}
+
+	validators, err := parseValidators(header, config)
+	assert.NoError(t, err)
+	assert.Equal(t, set.validators, validators)
+	turnLength, err := parseTurnLength(header, config)
+	assert.NoError(t, err)
+	assert.Equal(t, defaultTurnLength, turnLength)
+
+	header.Extra = append(extra[:extraVanity+1], make([]byte, extraSeal)...)
+	_, err = parseValidators(header, config)
+	assert.Equal(t, errInvalidValidators, err)
+}
@@ -0,0 +1,100 @@
+package chainsdb
+
+import (
+	"errors"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	"github.com/mapprotocol/atlas/chains"
+)
+
+// MaxReceiptRootHeaders is the largest number of headers returned for a receipt root.
+const MaxReceiptRootHeaders = 256
+
+var errUnsupportedChain = errors.New("chain is not followed by a light client")
+
+// RPCHeader is the json form of an indexed header.
+type RPCHeader struct {
+	Number       hexutil.Uint64 `json:"number"`
+	Hash         common.Hash    `json:"hash"`
+	ParentHash   common.Hash    `json:"parentHash"`
+	ReceiptsRoot common.Hash    `json:"receiptsRoot"`
+	Canonical    bool           `json:"canonical"`
+	Raw          hexutil.Bytes  `json:"raw,omitempty"`
+}
+
+// PublicChainsDBAPI serves the headers of the followed chains from the node-local
+// index.
+type PublicChainsDBAPI struct {
+	store *HeaderChainStore
+}
+
+// NewPublicChainsDBAPI creates the api of the index kept in db.
+func NewPublicChainsDBAPI(db *HeaderChainStore) *PublicChainsDBAPI {
+	return &PublicChainsDBAPI{store: db}
+}
+
+func (api *PublicChainsDBAPI) chain(chainID hexutil.Uint64) (chains.ChainType, error) {
+	chain := chains.ChainType(chainID)
+	if !chains.IsRegisteredChain(chain) {
+		return 0, errUnsupportedChain
+	}
+	return chain, nil
+}
+
+func (api *PublicChainsDBAPI) toRPC(chain chains.ChainType, header *chains.IndexedHeader) *RPCHeader {
+	if header == nil {
+		return nil
+	}
+	return &RPCHeader{
+		Number:       hexutil.Uint64(header.Number),
+		Hash:         header.Hash,
+		ParentHash:   header.ParentHash,
+		ReceiptsRoot: header.ReceiptHash,
+		Canonical:    api.store.ReadCanonicalHash(chain, header.Number) == header.Hash,
+		Raw:          header.Raw,
+	}
+}
+
+// CurrentHeader returns the canonical head of a chain.
+func (api *PublicChainsDBAPI) CurrentHeader(chainID hexutil.Uint64) (*RPCHeader, error) {
+	chain, err := api.chain(chainID)
+	if err != nil {
+		return nil, err
+	}
+	return api.toRPC(chain, api.store.CurrentHeader(chain)), nil
+}
+
+// GetHeaderByNumber returns the canonical header of a chain at the given number.
+func (api *PublicChainsDBAPI) GetHeaderByNumber(chainID hexutil.Uint64, number hexutil.Uint64) (*RPCHeader, error) {
+	chain, err := api.chain(chainID)
+	if err != nil {
+		return nil, err
+	}
+	return api.toRPC(chain, api.store.ReadHeaderByNumber(chain, uint64(number))), nil
+}
+
+// GetHeaderByHash returns the header of a chain with the given hash, canonical or not.
+func (api *PublicChainsDBAPI) GetHeaderByHash(chainID hexutil.Uint64, hash common.Hash) (*RPCHeader, error) {
+	chain, err := api.chain(chainID)
+	if err != nil {
+		return nil, err
+	}
+	return api.toRPC(chain, api.store.ReadHeaderByHash(chain, hash)), nil
+}
+
+// GetHeadersByReceiptsRoot returns the headers of a chain committing to the given
+// receipts root, at most MaxReceiptRootHeaders of them.
+func (api *PublicChainsDBAPI) GetHeadersByReceiptsRoot(chainID hexutil.Uint64, root common.Hash) ([]*RPCHeader, error) {
+	chain, err := api.chain(chainID)
+	if err != nil {
+		return nil, err
+	}
+	headers := api.store.ReadHeadersByReceiptRoot(chain, root, MaxReceiptRootHeaders)
+	result := make([]*RPCHeader, len(headers))
+	for i, header := range headers {
+		result[i] = api.toRPC(chain, header)
+	}
+	return result, nil
+}
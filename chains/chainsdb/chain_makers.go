@@ -1,187 +0,0 @@
-// Copyright 2015 The go-ethereum Authors
-// This file is part of the go-ethereum library.
-//
-// The go-ethereum library is free software: you can redistribute it and/or modify
-// it under the terms of the GNU Lesser General Public License as published by
-// the Free Software Foundation, either version 3 of the License, or
-// (at your option) any later version.
-//
-// The go-ethereum library is distributed in the hope that it will be useful,
-// but WITHOUT ANY WARRANTY; without even the implied warranty of
-// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
-// GNU Lesser General Public License for more details.
-//
-// You should have received a copy of the GNU Lesser General Public License
-// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
-
-package chainsdb
-
-//import (
-//	"fmt"
-//	"math/big"
-//
-//	"github.com/ethereum/go-ethereum/common"
-//	"github.com/ethereum/go-ethereum/consensus"
-//	"github.com/ethereum/go-ethereum/consensus/misc"
-//	"github.com/ethereum/go-ethereum/core"
-//	"github.com/ethereum/go-ethereum/core/state"
-//	"github.com/ethereum/go-ethereum/core/types"
-//	"github.com/ethereum/go-ethereum/ethdb"
-//	"github.com/ethereum/go-ethereum/params"
-//)
-//
-//// BlockGen creates blocks for testing.
-//// See GenerateChain for a detailed explanation.
-//type BlockGen struct {
-//	i       int
-//	parent  *types.Block
-//	chain   []*types.Block
-//	header  *types.Header
-//	statedb *state.StateDB
-//
-//	gasPool  *core.GasPool
-//	txs      []*types.Transaction
-//	receipts []*types.Receipt
-//	uncles   []*types.Header
-//
-//	config *params.ChainConfig
-//	engine consensus.Engine
-//}
-//
-//// SetCoinbase sets the coinbase of the generated block.
-//// It can be called at most once.
-//func (b *BlockGen) SetCoinbase(addr common.Address) {
-//	if b.gasPool != nil {
-//		if len(b.txs) > 0 {
-//			panic("coinbase must be set before adding transactions")
-//		}
-//		panic("coinbase can only be set once")
-//	}
-//	b.header.Coinbase = addr
-//
-//}
-//
-//// GenerateChain creates a chain of n blocks. The first block's
-//// parent will be the provided parent. db is used to store
-//// intermediate states and should contain the parent's state trie.
-////
-//// The generator function is called with a new block generator for
-//// every block. Any transactions and uncles added to the generator
-//// become part of the block. If gen is nil, the blocks will be empty
-//// and their coinbase will be the zero address.
-////
-//// Blocks created by GenerateChain do not contain valid proof of work
-//// values. Inserting them into BlockChain requires use of FakePow or
-//// a similar non-validating proof of work implementation.
-//func GenerateChain(config *params.ChainConfig, parent *types.Block, engine consensus.Engine, db ethdb.Database, n int, gen func(int, *BlockGen)) ([]*types.Block, []types.Receipts) {
-//	if config == nil {
-//		config = params.TestChainConfig
-//	}
-//	blocks, receipts := make(types.Blocks, n), make([]types.Receipts, n)
-//	chainreader := &fakeChainReader{config: config}
-//	genblock := func(i int, parent *types.Block, statedb *state.StateDB) (*types.Block, types.Receipts) {
-//		b := &BlockGen{i: i, chain: blocks, parent: parent, statedb: statedb, config: config, engine: engine}
-//		b.header = makeHeader(chainreader, parent, statedb, b.engine)
-//
-//		// Mutate the state and block according to any hard-fork specs
-//		if daoBlock := config.DAOForkBlock; daoBlock != nil {
-//			limit := new(big.Int).Add(daoBlock, params.DAOForkExtraRange)
-//			if b.header.Number.Cmp(daoBlock) >= 0 && b.header.Number.Cmp(limit) < 0 {
-//				if config.DAOForkSupport {
-//					b.header.Extra = common.CopyBytes(params.DAOForkBlockExtra)
-//				}
-//			}
-//		}
-//		if config.DAOForkSupport && config.DAOForkBlock != nil && config.DAOForkBlock.Cmp(b.header.Number) == 0 {
-//			misc.ApplyDAOHardFork(statedb)
-//		}
-//		// Execute any user modifications to the block
-//		if gen != nil {
-//			gen(i, b)
-//		}
-//		if b.engine != nil {
-//			// Finalize and seal the block
-//			block, _ := b.engine.FinalizeAndAssemble(chainreader, b.header, statedb, b.txs, b.uncles, b.receipts)
-//
-//			// Write state changes to db
-//			root, err := statedb.Commit(config.IsEIP158(b.header.Number))
-//			if err != nil {
-//				panic(fmt.Sprintf("state write error: %v", err))
-//			}
-//			if err := statedb.Database().TrieDB().Commit(root, false, nil); err != nil {
-//				panic(fmt.Sprintf("trie write error: %v", err))
-//			}
-//			return block, b.receipts
-//		}
-//		return nil, nil
-//	}
-//	for i := 0; i < n; i++ {
-//		statedb, err := state.New(parent.Root(), state.NewDatabase(db), nil)
-//		if err != nil {
-//			panic(err)
-//		}
-//		block, receipt := genblock(i, parent, statedb)
-//		blocks[i] = block
-//		receipts[i] = receipt
-//		parent = block
-//	}
-//	return blocks, receipts
-//}
-//
-//func makeHeader(chain consensus.ChainReader, parent *types.Block, state *state.StateDB, engine consensus.Engine) *types.Header {
-//	var time uint64
-//	if parent.Time() == 0 {
-//		time = 10
-//	} else {
-//		time = parent.Time() + 10 // block time is fixed at 10 seconds
-//	}
-//	header := &types.Header{
-//		Root:       state.IntermediateRoot(chain.Config().IsEIP158(parent.Number())),
-//		ParentHash: parent.Hash(),
-//		Coinbase:   parent.Coinbase(),
-//		Difficulty: engine.CalcDifficulty(chain, time, &types.Header{
-//			Number:     parent.Number(),
-//			Time:       time - 10,
-//			Difficulty: parent.Difficulty(),
-//			UncleHash:  parent.UncleHash(),
-//		}),
-//		GasLimit: parent.GasLimit(),
-//		Number:   new(big.Int).Add(parent.Number(), common.Big1),
-//		Time:     time,
-//	}
-//
-//	return header
-//}
-//
-//// makeHeaderChain creates a deterministic chain of headers rooted at parent.
-//func makeHeaderChain(parent *types.Header, n int, engine consensus.Engine, db ethdb.Database, seed int) []*types.Header {
-//	blocks := makeBlockChain(types.NewBlockWithHeader(parent), n, engine, db, seed)
-//	headers := make([]*types.Header, len(blocks))
-//	for i, block := range blocks {
-//		headers[i] = block.Header()
-//	}
-//	return headers
-//}
-//
-//// makeBlockChain creates a deterministic chain of blocks rooted at parent.
-//func makeBlockChain(parent *types.Block, n int, engine consensus.Engine, db ethdb.Database, seed int) []*types.Block {
-//	blocks, _ := GenerateChain(params.TestChainConfig, parent, engine, db, n, func(i int, b *BlockGen) {
-//		b.SetCoinbase(common.Address{0: byte(seed), 19: byte(i)})
-//	})
-//	return blocks
-//}
-//
-//type fakeChainReader struct {
-//	config *params.ChainConfig
-//}
-//
-//// Config returns the chain configuration.
-//func (cr *fakeChainReader) Config() *params.ChainConfig {
-//	return cr.config
-//}
-//
-//func (cr *fakeChainReader) CurrentHeader() *types.Header                            { return nil }
-//func (cr *fakeChainReader) GetHeaderByNumber(number uint64) *types.Header           { return nil }
-//func (cr *fakeChainReader) GetHeaderByHash(hash common.Hash) *types.Header          { return nil }
-//func (cr *fakeChainReader) GetHeader(hash common.Hash, number uint64) *types.Header { return nil }
-//func (cr *fakeChainReader) GetBlock(hash common.Hash, number uint64) *types.Block   { return nil }
@@ -1,235 +1,245 @@
 package chainsdb
-//
-//import (
-//	"encoding/json"
-//	"fmt"
-//	"github.com/mapprotocol/atlas/chains/ethereum"
-//	"io/ioutil"
-//	"math/big"
-//	"reflect"
-//	"testing"
-//	"time"
-//
-//	"github.com/ethereum/go-ethereum/common"
-//	eth_types "github.com/ethereum/go-ethereum/core/types"
-//	"github.com/ethereum/go-ethereum/log"
-//
-//	"github.com/mapprotocol/atlas/core/rawdb"
-//)
-//
-//func testInsert01(t *testing.T, hc *HeaderChainStore, chain []*ethereum.Header, wantStatus WriteStatus, wantErr error) {
-//	t.Helper()
-//	status, _ := hc.InsertHeaderChain(chain, time.Now())
-//	if status != wantStatus {
-//		t.Errorf("wrong write status from InsertHeaderChain: got %v, want %v", status, wantStatus)
-//	}
-//}
-//
-//func converChainList(headers []*eth_types.Header) (newChains1 []*ethereum.Header) {
-//	l := len(headers)
-//	newChains := make([]ethereum.Header, l)
-//	for i := 0; i < l; i++ {
-//		newChains1 = append(newChains1, convertChain(&newChains[i], headers[i]))
-//	}
-//	return
-//}
-//
-//func convertChain(header *ethereum.Header, e *eth_types.Header) *ethereum.Header {
-//	header.ParentHash = e.ParentHash
-//	header.UncleHash = e.UncleHash
-//	header.Coinbase = e.Coinbase
-//	header.Root = e.Root
-//	header.TxHash = e.TxHash
-//	header.ReceiptHash = e.ReceiptHash
-//	header.GasLimit = e.GasLimit
-//	header.GasUsed = e.GasUsed
-//	header.Time = e.Time
-//	header.MixDigest = e.MixDigest
-//	header.Nonce = eth_types.EncodeNonce(e.Nonce.Uint64())
-//	header.Bloom.SetBytes(e.Bloom.Bytes())
-//	if header.Difficulty = new(big.Int); e.Difficulty != nil {
-//		header.Difficulty.Set(e.Difficulty)
-//	}
-//	if header.Number = new(big.Int); e.Number != nil {
-//		header.Number.Set(e.Number)
-//	}
-//	if len(e.Extra) > 0 {
-//		header.Extra = make([]byte, len(e.Extra))
-//		copy(header.Extra, e.Extra)
-//	}
-//	// test rlp
-//	//fmt.Println(e.Hash(), "/n", header.Hash())
-//	return header
-//}
-//
-//func TestHeaderChainStore_CurrentHeaderHash(t *testing.T) {
-//	chainDb0, _ := OpenDatabase("data222", 20, 20)
-//
-//	db := HeaderChainStore{
-//		chainDb: chainDb0,
-//	}
-//	storeMgr = &db
-//	hs, _ := GetStoreMgr(rawdb.ChainType(321))
-//
-//	tests := []struct {
-//		name   string
-//		fields *HeaderChainStore
-//		want   common.Hash
-//	}{
-//		{
-//			"1",
-//			hs,
-//			common.Hash{},
-//		},
-//	}
-//	for _, tt := range tests {
-//		t.Run(tt.name, func(t *testing.T) {
-//			hc := &HeaderChainStore{
-//				chainDb:          tt.fields.chainDb,
-//				currentChainType: tt.fields.currentChainType,
-//				Mu:               tt.fields.Mu,
-//				rand:             tt.fields.rand,
-//			}
-//			currentHash := hc.CurrentHeaderHash()
-//			td := hc.GetTdByHash(currentHash)
-//			GetHeaderByHash := hc.GetHeaderByHash(currentHash)
-//			fmt.Printf("CurrentHeaderHash() = %v GetTdByHash() %v GetHeaderByHash() %v ", currentHash, td, GetHeaderByHash)
-//		})
-//	}
-//}
-//
-//func TestHeaderChainStore_GetHeaderByNumber(t *testing.T) {
-//	chainDb0, _ := OpenDatabase("data222", 20, 20)
-//
-//	db := HeaderChainStore{
-//		chainDb: chainDb0,
-//	}
-//	storeMgr = &db
-//	hs, _ := GetStoreMgr(rawdb.ChainType(123))
-//
-//	type args struct {
-//		number uint64
-//	}
-//	tests := []struct {
-//		name   string
-//		fields *HeaderChainStore
-//		args   args
-//		want   common.Hash
-//	}{
-//		{
-//			"1",
-//			hs,
-//			args{uint64(1)},
-//			common.Hash{},
-//		},
-//	}
-//	for _, tt := range tests {
-//		t.Run(tt.name, func(t *testing.T) {
-//			hc := &HeaderChainStore{
-//				chainDb:          tt.fields.chainDb,
-//				currentChainType: tt.fields.currentChainType,
-//				Mu:               tt.fields.Mu,
-//				rand:             tt.fields.rand,
-//			}
-//			if got := hc.GetHeaderByNumber(tt.args.number); !reflect.DeepEqual(got, tt.want) {
-//				fmt.Printf("GetHeaderByNumber() = %v", got)
-//			}
-//		})
-//	}
-//}
-//
-//func TestHeaderChainStore_CurrentHeaderNumber(t *testing.T) {
-//	chainDb0, _ := OpenDatabase("data222", 20, 20)
-//
-//	db := HeaderChainStore{
-//		chainDb: chainDb0,
-//	}
-//	storeMgr = &db
-//	hs, _ := GetStoreMgr(rawdb.ChainType(321))
-//
-//	tests := []struct {
-//		name   string
-//		fields *HeaderChainStore
-//	}{
-//		{
-//			"1",
-//			hs,
-//		},
-//	}
-//	for _, tt := range tests {
-//		t.Run(tt.name, func(t *testing.T) {
-//			hc := &HeaderChainStore{
-//				chainDb:          tt.fields.chainDb,
-//				currentChainType: tt.fields.currentChainType,
-//				Mu:               tt.fields.Mu,
-//				rand:             tt.fields.rand,
-//			}
-//			got := hc.CurrentHeaderNumber()
-//			fmt.Printf("CurrentHeaderNumber() = %v", got)
-//		})
-//	}
-//}
-//
-//func TestHeaderChainStore_ReadCanonicalHash(t *testing.T) {
-//	chainDb0, _ := OpenDatabase("data222", 20, 20)
-//
-//	db := HeaderChainStore{
-//		chainDb: chainDb0,
-//	}
-//	storeMgr = &db
-//	hs, _ := GetStoreMgr(rawdb.ChainType(123))
-//
-//	tests := []struct {
-//		name   string
-//		fields *HeaderChainStore
-//		number uint64
-//	}{
-//		{
-//			"1",
-//			hs,
-//			uint64(128),
-//		},
-//		{
-//			"1",
-//			hs,
-//			uint64(127),
-//		},
-//	}
-//	for _, tt := range tests {
-//		t.Run(tt.name, func(t *testing.T) {
-//			hc := &HeaderChainStore{
-//				chainDb:          tt.fields.chainDb,
-//				currentChainType: tt.fields.currentChainType,
-//				Mu:               tt.fields.Mu,
-//				rand:             tt.fields.rand,
-//			}
-//			got := hc.ReadCanonicalHash(tt.number)
-//			fmt.Printf("ReadCanonicalHash() = %v", got)
-//		})
-//	}
-//}
-//
-//func TestRead_chaintype_config(t *testing.T) {
-//	data, err := ioutil.ReadFile(fmt.Sprintf("config/chaintype_config.json"))
-//	if err != nil {
-//		log.Error("readFile Err", err)
-//	}
-//	var config []struct {
-//		Name string
-//		Id   rawdb.ChainType
-//	}
-//	_ = json.Unmarshal(data, &config)
-//	fmt.Println(config)
-//}
-//func TestRead_ethconfig(t *testing.T) {
-//	data, err := ioutil.ReadFile(fmt.Sprintf("config/%v_config.json", "eth"))
-//	if err != nil {
-//		log.Error("read eht store config err", err)
-//	}
-//	genesis := &ethereum.Header{}
-//	err = json.Unmarshal(data, genesis)
-//	if err != nil {
-//		log.Error("Unmarshal Err", err.Error())
-//	}
-//	fmt.Println(genesis.Hash())
-//}
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mapprotocol/atlas/chains"
+	"github.com/mapprotocol/atlas/chains/ethereum"
+	"github.com/mapprotocol/atlas/core/rawdb"
+	"github.com/mapprotocol/atlas/core/types"
+	"github.com/mapprotocol/atlas/params"
+)
+
+// makeChain creates n linked headers on top of parent, seed tells forks apart.
+func makeChain(parent *chains.IndexedHeader, n int, seed byte) []*chains.IndexedHeader {
+	headers := make([]*chains.IndexedHeader, n)
+	for i := range headers {
+		headers[i] = &chains.IndexedHeader{
+			Number:      parent.Number + 1,
+			Hash:        common.Hash{seed, byte(parent.Number + 1)},
+			ParentHash:  parent.Hash,
+			ReceiptHash: ethtypes.EmptyRootHash,
+			Raw:         []byte{seed},
+		}
+		parent = headers[i]
+	}
+	return headers
+}
+
+func numberHash(h *chains.IndexedHeader) *params.NumberHash {
+	return &params.NumberHash{Number: h.Number, Hash: h.Hash}
+}
+
+func TestSetHead(t *testing.T) {
+	var (
+		store   = NewHeaderChainStore(rawdb.NewMemoryDatabase())
+		chain   = chains.ChainTypeETH
+		genesis = &chains.IndexedHeader{Hash: common.Hash{0xff}, Raw: []byte{0xff}}
+		main    = makeChain(genesis, 5, 1)
+		fork    = makeChain(main[2], 3, 2)
+	)
+	require.NoError(t, store.WriteHeaders(chain, append([]*chains.IndexedHeader{genesis}, main...)))
+	require.NoError(t, store.WriteHeaders(chain, fork))
+
+	require.NoError(t, store.SetHead(chain, numberHash(main[4])))
+	assert.Equal(t, main[4], store.CurrentHeader(chain))
+	assert.Equal(t, genesis, store.ReadHeaderByNumber(chain, 0))
+	for _, h := range main {
+		assert.Equal(t, h, store.ReadHeaderByNumber(chain, h.Number))
+	}
+
+	// a longer fork rewrites the canonical chain down to the common ancestor
+	require.NoError(t, store.SetHead(chain, numberHash(fork[2])))
+	assert.Equal(t, fork[2], store.CurrentHeader(chain))
+	assert.Equal(t, main[2], store.ReadHeaderByNumber(chain, 3))
+	for _, h := range fork {
+		assert.Equal(t, h, store.ReadHeaderByNumber(chain, h.Number))
+	}
+
+	// a shorter head removes the canonical assignments above it
+	require.NoError(t, store.SetHead(chain, numberHash(main[4])))
+	assert.Equal(t, main[4], store.ReadHeaderByNumber(chain, 5))
+	assert.Nil(t, store.ReadHeaderByNumber(chain, 6))
+
+	// side headers stay readable by hash
+	assert.Equal(t, fork[0], store.ReadHeaderByHash(chain, fork[0].Hash))
+	assert.Len(t, store.ReadHeadersByReceiptRoot(chain, ethtypes.EmptyRootHash, 100), 8)
+	assert.Len(t, store.ReadHeadersByReceiptRoot(chain, ethtypes.EmptyRootHash, 2), 2)
+
+	// the tables of other chains are separate
+	assert.Nil(t, store.CurrentHeader(chains.ChainTypeBSC))
+	assert.Nil(t, store.ReadHeaderByHash(chains.ChainTypeBSC, main[0].Hash))
+}
+
+func makeEthereumHeaders(n int) []*ethereum.Header {
+	headers := make([]*ethereum.Header, n)
+	parent := common.Hash{}
+	for i := range headers {
+		headers[i] = &ethereum.Header{
+			ParentHash:  parent,
+			Difficulty:  big.NewInt(1),
+			Number:      big.NewInt(int64(i + 100)),
+			ReceiptHash: common.Hash{byte(i)},
+		}
+		parent = headers[i].Hash()
+	}
+	return headers
+}
+
+func packUpdate(t *testing.T, chain chains.ChainType, headers []*ethereum.Header) []byte {
+	data, err := rlp.EncodeToBytes(headers)
+	require.NoError(t, err)
+	args, err := rlp.EncodeToBytes(&updateBlockHeaderArgs{
+		From:    new(big.Int).SetUint64(uint64(chain)),
+		To:      big.NewInt(int64(params.MainNetChainID)),
+		Headers: data,
+	})
+	require.NoError(t, err)
+	input, err := abiHeaderStore.Pack(updateBlockHeader, args)
+	require.NoError(t, err)
+	return input
+}
+
+func headChangedLog(t *testing.T, chain chains.ChainType, header *ethereum.Header) *types.Log {
+	event := abiHeaderStore.Events[chainHeadChanged]
+	data, err := event.Inputs.NonIndexed().Pack(header.Hash())
+	require.NoError(t, err)
+	return &types.Log{
+		Address: params.HeaderStoreAddress,
+		Topics: []common.Hash{
+			event.ID,
+			common.BigToHash(new(big.Int).SetUint64(uint64(chain))),
+			common.BigToHash(header.Number),
+		},
+		Data: data,
+	}
+}
+
+func TestIndexerProcess(t *testing.T) {
+	var (
+		chainDb  = rawdb.NewMemoryDatabase()
+		db       = rawdb.NewMemoryDatabase()
+		backend  = &Indexer{chainDb: chainDb, db: db, config: &params.ChainConfig{}, store: NewHeaderChainStore(db)}
+		chain    = chains.ChainTypeETH
+		headers  = makeEthereumHeaders(3)
+		failed   = makeEthereumHeaders(4)[3:]
+		to       = params.HeaderStoreAddress
+		other    = common.Address{0x01}
+		header   = &types.Header{Number: big.NewInt(7)}
+		hash     = header.Hash()
+		body     = &types.Body{Randomness: &types.Randomness{}, EpochSnarkData: &types.EpochSnarkData{Bitmap: new(big.Int)}}
+		receipts types.Receipts
+	)
+	addTx := func(to common.Address, input []byte, status uint64, logs ...*types.Log) {
+		body.Transactions = append(body.Transactions, types.NewTx(&types.LegacyTx{To: &to, Data: input}))
+		receipts = append(receipts, &types.Receipt{Status: status, Logs: logs})
+	}
+	addTx(other, packUpdate(t, chain, failed), types.ReceiptStatusSuccessful)
+	addTx(to, packUpdate(t, chain, headers), types.ReceiptStatusSuccessful, headChangedLog(t, chain, headers[1]))
+	addTx(to, packUpdate(t, chain, failed), types.ReceiptStatusFailed)
+	rawdb.WriteBody(chainDb, hash, 7, body)
+	rawdb.WriteReceipts(chainDb, hash, 7, receipts)
+
+	require.NoError(t, backend.Process(context.Background(), header))
+
+	store := backend.store
+	for _, h := range headers {
+		indexed := store.ReadHeaderByHash(chain, h.Hash())
+		require.NotNil(t, indexed)
+		assert.Equal(t, h.Number.Uint64(), indexed.Number)
+		assert.Equal(t, h.ReceiptHash, indexed.ReceiptHash)
+
+		var decoded ethereum.Header
+		require.NoError(t, rlp.DecodeBytes(indexed.Raw, &decoded))
+		assert.Equal(t, h.Hash(), decoded.Hash())
+	}
+	// the head follows the fork choice of the header store, not the last header
+	assert.Equal(t, headers[1].Hash(), store.CurrentHeader(chain).Hash)
+	assert.Equal(t, headers[0].Hash(), store.ReadHeaderByNumber(chain, 100).Hash)
+	assert.Nil(t, store.ReadHeaderByNumber(chain, 102))
+	assert.Len(t, store.ReadHeadersByReceiptRoot(chain, headers[2].ReceiptHash, 10), 1)
+
+	// only successful calls to the header store are indexed
+	assert.Nil(t, store.ReadHeaderByHash(chain, failed[0].Hash()))
+
+	// a block without the receipts of its header store calls can not be indexed
+	rawdb.DeleteReceipts(chainDb, hash, 7)
+	assert.Equal(t, errMissingReceipts, backend.Process(context.Background(), header))
+}
+
+func headersInsertedLog(t *testing.T, chain chains.ChainType, headers []*ethereum.Header) *types.Log {
+	data, err := rlp.EncodeToBytes(headers)
+	require.NoError(t, err)
+	event := abiHeaderStore.Events[headersInserted]
+	logData, err := event.Inputs.NonIndexed().Pack(data)
+	require.NoError(t, err)
+	return &types.Log{
+		Address: params.HeaderStoreAddress,
+		Topics:  []common.Hash{event.ID, common.BigToHash(new(big.Int).SetUint64(uint64(chain)))},
+		Data:    logData,
+	}
+}
+
+// writeBlock stores an atlas block whose single transaction emitted the logs.
+func writeBlock(chainDb ethdb.Database, number int64, logs ...*types.Log) *types.Header {
+	header := &types.Header{Number: big.NewInt(number)}
+	to := common.Address{0x01}
+	body := &types.Body{
+		Transactions:   []*types.Transaction{types.NewTx(&types.LegacyTx{To: &to})},
+		Randomness:     &types.Randomness{},
+		EpochSnarkData: &types.EpochSnarkData{Bitmap: new(big.Int)},
+	}
+	rawdb.WriteBody(chainDb, header.Hash(), uint64(number), body)
+	rawdb.WriteReceipts(chainDb, header.Hash(), uint64(number), types.Receipts{{Status: types.ReceiptStatusSuccessful, Logs: logs}})
+	return header
+}
+
+func TestIndexerHeaderLogs(t *testing.T) {
+	var (
+		chainDb = rawdb.NewMemoryDatabase()
+		db      = rawdb.NewMemoryDatabase()
+		config  = &params.ChainConfig{HeaderLogBlock: big.NewInt(7)}
+		backend = &Indexer{chainDb: chainDb, db: db, config: config, store: NewHeaderChainStore(db)}
+		chain   = chains.ChainTypeETH
+		headers = makeEthereumHeaders(4)
+		ctx     = context.Background()
+	)
+	// the headers of calls made by contracts are indexed from the logs
+	block7 := writeBlock(chainDb, 7, headersInsertedLog(t, chain, headers[:2]), headChangedLog(t, chain, headers[1]))
+	require.NoError(t, backend.Reset(ctx, 7, common.Hash{}))
+	require.NoError(t, backend.Process(ctx, block7))
+	assert.Equal(t, headers[1].Hash(), backend.store.CurrentHeader(chain).Hash)
+
+	block8 := writeBlock(chainDb, 8, headersInsertedLog(t, chain, headers[2:]), headChangedLog(t, chain, headers[3]))
+	require.NoError(t, backend.Reset(ctx, 8, block7.Hash()))
+	require.NoError(t, backend.Process(ctx, block8))
+	assert.Equal(t, headers[3].Hash(), backend.store.CurrentHeader(chain).Hash)
+
+	// reindexing block 8 after a reorg restores the head written by block 7
+	reorged := writeBlock(chainDb, 8)
+	require.NoError(t, backend.Reset(ctx, 8, block7.Hash()))
+	require.NoError(t, backend.Process(ctx, reorged))
+	assert.Equal(t, headers[1].Hash(), backend.store.CurrentHeader(chain).Hash)
+	assert.Nil(t, backend.store.ReadHeaderByNumber(chain, 102))
+	assert.NotNil(t, backend.store.ReadHeaderByHash(chain, headers[3].Hash()))
+
+	// a reorg from block 7 removes the head, no earlier block set it
+	require.NoError(t, backend.Reset(ctx, 7, common.Hash{}))
+	assert.Nil(t, backend.store.ReadHead(chain))
+	assert.Nil(t, backend.store.ReadHeaderByNumber(chain, 100))
+
+	// pruned blocks can no longer be undone
+	require.NoError(t, backend.Process(ctx, block7))
+	require.NoError(t, backend.Prune(8))
+	require.NoError(t, backend.Reset(ctx, 7, common.Hash{}))
+	assert.Equal(t, headers[1].Hash(), backend.store.CurrentHeader(chain).Hash)
+}
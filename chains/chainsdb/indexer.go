@@ -0,0 +1,363 @@
+package chainsdb
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
+
+	"github.com/mapprotocol/atlas/chains"
+	"github.com/mapprotocol/atlas/chains/interfaces"
+	"github.com/mapprotocol/atlas/core/indexer"
+	"github.com/mapprotocol/atlas/core/rawdb"
+	"github.com/mapprotocol/atlas/core/types"
+	"github.com/mapprotocol/atlas/params"
+)
+
+const (
+	updateBlockHeader = "updateBlockHeader"
+	chainHeadChanged  = "ChainHeadChanged"
+	headersInserted   = "HeadersInserted"
+
+	// undoRetention is the number of atlas blocks whose head changes can be undone,
+	// older blocks are not reorged out.
+	undoRetention = 90000
+)
+
+var (
+	abiHeaderStore, _ = abi.JSON(strings.NewReader(params.HeaderStoreABIJSON))
+
+	errMissingBody     = errors.New("block body missing")
+	errMissingReceipts = errors.New("block receipts missing")
+)
+
+// updateBlockHeaderArgs is the rlp encoded argument of updateBlockHeader.
+type updateBlockHeaderArgs struct {
+	From    *big.Int
+	To      *big.Int
+	Headers []byte
+}
+
+// headUndo is the head of a chain before an atlas block changed it.
+type headUndo struct {
+	Chain uint64
+	Head  *params.NumberHash `rlp:"nil"` // nil if no header of the chain was indexed
+}
+
+// headJournal collects the heads changed by an atlas block, the first change of a
+// chain records the head it replaced.
+type headJournal struct {
+	number uint64
+	undos  []*headUndo
+}
+
+// Indexer is a chain indexer backend mirroring the headers accepted by the header
+// store precompile into a HeaderChainStore. Every atlas block is its own section, the
+// headers are indexed as soon as the block is canonical.
+//
+// From HeaderLogBlock the headers are taken from the HeadersInserted events, so the
+// updates of internal calls are indexed. Before, only calls made by transactions to the
+// precompile are seen. The heads replaced by a block are journaled, when the block is
+// reorged out the section is reset and the heads it changed are restored.
+//
+// The reset method is not mirrored: after a reset the index keeps its headers, the
+// chain of the new store is linked to them by the later updates.
+type Indexer struct {
+	chainDb ethdb.Database
+	db      ethdb.Database
+	config  *params.ChainConfig
+	store   *HeaderChainStore
+}
+
+// NewIndexer returns a chain indexer mirroring the followed chains into db.
+func NewIndexer(chainDb, db ethdb.Database, config *params.ChainConfig) *indexer.ChainIndexer {
+	backend := &Indexer{
+		chainDb: chainDb,
+		db:      db,
+		config:  config,
+		store:   NewHeaderChainStore(db),
+	}
+	table := rawdb.NewTable(db, string(indexerPrefix))
+
+	return indexer.NewChainIndexer(chainDb, table, backend, 1, 0, 0, "chainsdb")
+}
+
+// Reset implements core.ChainIndexerBackend. A section is a single block, the heads
+// changed by this block and the later ones are restored in case they were indexed on a
+// chain that was reorged out.
+func (i *Indexer) Reset(ctx context.Context, section uint64, prevHead common.Hash) error {
+	if err := i.rollback(section); err != nil {
+		return err
+	}
+	if section > undoRetention {
+		return i.Prune(section - undoRetention)
+	}
+	return nil
+}
+
+// Process implements core.ChainIndexerBackend, mirroring the headers accepted by the
+// header store in the block.
+func (i *Indexer) Process(ctx context.Context, header *types.Header) error {
+	hash, number := header.Hash(), header.Number.Uint64()
+	body := rawdb.ReadBody(i.chainDb, hash, number)
+	if body == nil {
+		return errMissingBody
+	}
+	journal := &headJournal{number: number}
+	if i.config.IsHeaderLog(header.Number) {
+		if len(body.Transactions) == 0 {
+			return nil
+		}
+		receipts := rawdb.ReadRawReceipts(i.chainDb, hash, number)
+		if len(receipts) != len(body.Transactions) {
+			return errMissingReceipts
+		}
+		for _, receipt := range receipts {
+			if err := i.indexLogs(journal, header.Number, receipt.Logs); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	var receipts types.Receipts
+	for j, tx := range body.Transactions {
+		if tx.To() == nil || *tx.To() != params.HeaderStoreAddress {
+			continue
+		}
+		if receipts == nil {
+			if receipts = rawdb.ReadRawReceipts(i.chainDb, hash, number); len(receipts) != len(body.Transactions) {
+				return errMissingReceipts
+			}
+		}
+		if receipts[j].Status != types.ReceiptStatusSuccessful {
+			continue
+		}
+		if err := i.indexUpdate(journal, header.Number, tx.Data(), receipts[j].Logs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Commit implements core.ChainIndexerBackend, the headers are written while the block
+// is processed.
+func (i *Indexer) Commit() error {
+	return nil
+}
+
+// Prune implements core.ChainIndexerBackend, the head changes of the blocks below the
+// threshold can no longer be undone. The headers are never pruned.
+func (i *Indexer) Prune(threshold uint64) error {
+	batch := i.db.NewBatch()
+	it := i.db.NewIterator(undoPrefix, nil)
+	defer it.Release()
+
+	for it.Next() {
+		key := it.Key()
+		if len(key) != len(undoPrefix)+8 || binary.BigEndian.Uint64(key[len(undoPrefix):]) >= threshold {
+			break
+		}
+		if err := batch.Delete(common.CopyBytes(key)); err != nil {
+			return err
+		}
+	}
+	return batch.Write()
+}
+
+// rollback restores the heads changed by the blocks from number on, latest first.
+func (i *Indexer) rollback(number uint64) error {
+	var keys, values [][]byte
+	it := i.db.NewIterator(undoPrefix, encodeNumber(number))
+	for it.Next() {
+		keys = append(keys, common.CopyBytes(it.Key()))
+		values = append(values, common.CopyBytes(it.Value()))
+	}
+	it.Release()
+
+	for j := len(keys) - 1; j >= 0; j-- {
+		var undos []*headUndo
+		if err := rlp.DecodeBytes(values[j], &undos); err != nil {
+			return err
+		}
+		for k := len(undos) - 1; k >= 0; k-- {
+			chain := chains.ChainType(undos[k].Chain)
+			if undos[k].Head == nil {
+				if err := i.store.DeleteHead(chain); err != nil {
+					return err
+				}
+			} else if err := i.store.SetHead(chain, undos[k].Head); err != nil {
+				return err
+			}
+			log.Debug("Restored indexed chain head", "chain", chain, "head", undos[k].Head)
+		}
+		if err := i.db.Delete(keys[j]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setHead changes the head of a chain, journaling the head it replaces the first time
+// the block changes it.
+func (i *Indexer) setHead(journal *headJournal, chain chains.ChainType, head *params.NumberHash) error {
+	journaled := false
+	for _, undo := range journal.undos {
+		if chains.ChainType(undo.Chain) == chain {
+			journaled = true
+			break
+		}
+	}
+	if !journaled {
+		journal.undos = append(journal.undos, &headUndo{Chain: uint64(chain), Head: i.store.ReadHead(chain)})
+		data, err := rlp.EncodeToBytes(journal.undos)
+		if err != nil {
+			return err
+		}
+		if err := i.db.Put(undoKey(journal.number), data); err != nil {
+			return err
+		}
+	}
+	return i.store.SetHead(chain, head)
+}
+
+// indexLogs mirrors the headers and head changes of the header store events of a
+// receipt, in the order they were emitted.
+func (i *Indexer) indexLogs(journal *headJournal, number *big.Int, logs []*types.Log) error {
+	var (
+		inserted = abiHeaderStore.Events[headersInserted]
+		changed  = abiHeaderStore.Events[chainHeadChanged]
+	)
+	for _, l := range logs {
+		if l.Address != params.HeaderStoreAddress || len(l.Topics) < 2 {
+			continue
+		}
+		chain := chains.ChainType(l.Topics[1].Big().Uint64())
+		switch l.Topics[0] {
+		case inserted.ID:
+			values, err := inserted.Inputs.NonIndexed().Unpack(l.Data)
+			if err != nil || len(values) != 1 {
+				continue
+			}
+			headers, ok := values[0].([]byte)
+			if !ok {
+				continue
+			}
+			if err := i.indexHeaders(journal, number, chain, headers); err != nil {
+				return err
+			}
+		case changed.ID:
+			if head := headChange(l, chain); head != nil {
+				if err := i.setHead(journal, chain, head); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// indexUpdate mirrors the headers of an updateBlockHeader call and the head change it
+// caused. Inputs the index can not decode are skipped, the block is still indexed.
+func (i *Indexer) indexUpdate(journal *headJournal, number *big.Int, input []byte, logs []*types.Log) error {
+	method, err := abiHeaderStore.MethodById(input)
+	if err != nil || method.Name != updateBlockHeader {
+		return nil
+	}
+	unpack, err := method.Inputs.Unpack(input[4:])
+	if err != nil {
+		return nil
+	}
+	var (
+		data []byte
+		args updateBlockHeaderArgs
+	)
+	if err := method.Inputs.Copy(&data, unpack); err != nil {
+		return nil
+	}
+	if err := rlp.DecodeBytes(data, &args); err != nil || args.From == nil {
+		return nil
+	}
+	chain := chains.ChainType(args.From.Uint64())
+	if err := i.indexHeaders(journal, number, chain, args.Headers); err != nil {
+		return err
+	}
+	for _, head := range headChanges(logs, chain) {
+		if err := i.setHead(journal, chain, head); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// indexHeaders mirrors the headers inserted into the header store of a chain. Header
+// stores with a fork choice report the head they selected, the others follow the last
+// inserted header. Headers the index can not decode are skipped.
+func (i *Indexer) indexHeaders(journal *headJournal, number *big.Int, chain chains.ChainType, input []byte) error {
+	group, err := chains.ChainType2ChainGroupAt(i.config, number, chain)
+	if err != nil {
+		return nil
+	}
+	hs, err := interfaces.HeaderStoreFactory(group)
+	if err != nil {
+		return nil
+	}
+	if cc, ok := hs.(chains.IChainConfigurable); ok {
+		cc.SetChainConfig(i.config)
+	}
+	decoder, ok := hs.(chains.IHeaderDecoder)
+	if !ok {
+		return nil
+	}
+	headers, err := decoder.DecodeHeaders(input, chain)
+	if err != nil || len(headers) == 0 {
+		log.Warn("Failed to decode indexed headers", "chain", chain, "number", number, "err", err)
+		return nil
+	}
+	if err := i.store.WriteHeaders(chain, headers); err != nil {
+		return err
+	}
+	if _, ok := hs.(chains.IForkChoice); ok {
+		return nil
+	}
+	last := headers[len(headers)-1]
+	return i.setHead(journal, chain, &params.NumberHash{Number: last.Number, Hash: last.Hash})
+}
+
+// headChanges returns the heads of the ChainHeadChanged events of a chain.
+func headChanges(logs []*types.Log, chain chains.ChainType) []*params.NumberHash {
+	var heads []*params.NumberHash
+	for _, l := range logs {
+		if head := headChange(l, chain); head != nil {
+			heads = append(heads, head)
+		}
+	}
+	return heads
+}
+
+// headChange returns the head of a ChainHeadChanged event of a chain, nil if the log
+// is not one.
+func headChange(l *types.Log, chain chains.ChainType) *params.NumberHash {
+	var (
+		event   = abiHeaderStore.Events[chainHeadChanged]
+		chainID = common.BigToHash(new(big.Int).SetUint64(uint64(chain)))
+	)
+	if l.Address != params.HeaderStoreAddress || len(l.Topics) != 3 || l.Topics[0] != event.ID || l.Topics[1] != chainID {
+		return nil
+	}
+	values, err := event.Inputs.NonIndexed().Unpack(l.Data)
+	if err != nil || len(values) != 1 {
+		return nil
+	}
+	hash, ok := values[0].([32]byte)
+	if !ok {
+		return nil
+	}
+	return &params.NumberHash{Number: l.Topics[2].Big().Uint64(), Hash: hash}
+}
@@ -0,0 +1,66 @@
+package chainsdb
+
+import (
+	"encoding/binary"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/mapprotocol/atlas/chains"
+)
+
+// The chainsdb keys are prefixed by the chain they belong to, so every followed
+// chain has its own table in the database.
+var (
+	chainPrefix   = []byte("C") // chainPrefix + chain type (uint64 big endian) -> chain table
+	indexerPrefix = []byte("i") // indexerPrefix -> chain indexer metadata
+	undoPrefix    = []byte("u") // undoPrefix + atlas number (uint64 big endian) -> heads before the block changed them
+
+	headKey         = []byte("LastHeader") // headKey -> canonical head number + hash
+	headerPrefix    = []byte("h")          // headerPrefix + num (uint64 big endian) + hash -> header
+	canonicalPrefix = []byte("c")          // canonicalPrefix + num (uint64 big endian) -> hash
+	numberPrefix    = []byte("H")          // numberPrefix + hash -> num (uint64 big endian)
+	receiptPrefix   = []byte("r")          // receiptPrefix + receipt root + num (uint64 big endian) + hash -> nil
+)
+
+// encodeNumber encodes a block number as big endian uint64
+func encodeNumber(number uint64) []byte {
+	enc := make([]byte, 8)
+	binary.BigEndian.PutUint64(enc, number)
+	return enc
+}
+
+func tableKey(chain chains.ChainType, key ...[]byte) []byte {
+	out := append(append([]byte{}, chainPrefix...), encodeNumber(uint64(chain))...)
+	for _, k := range key {
+		out = append(out, k...)
+	}
+	return out
+}
+
+func chainHeadKey(chain chains.ChainType) []byte {
+	return tableKey(chain, headKey)
+}
+
+func headerKey(chain chains.ChainType, number uint64, hash common.Hash) []byte {
+	return tableKey(chain, headerPrefix, encodeNumber(number), hash.Bytes())
+}
+
+func canonicalKey(chain chains.ChainType, number uint64) []byte {
+	return tableKey(chain, canonicalPrefix, encodeNumber(number))
+}
+
+func headerNumberKey(chain chains.ChainType, hash common.Hash) []byte {
+	return tableKey(chain, numberPrefix, hash.Bytes())
+}
+
+func receiptRootPrefix(chain chains.ChainType, root common.Hash) []byte {
+	return tableKey(chain, receiptPrefix, root.Bytes())
+}
+
+func receiptRootKey(chain chains.ChainType, root common.Hash, number uint64, hash common.Hash) []byte {
+	return tableKey(chain, receiptPrefix, root.Bytes(), encodeNumber(number), hash.Bytes())
+}
+
+func undoKey(number uint64) []byte {
+	return append(append([]byte{}, undoPrefix...), encodeNumber(number)...)
+}
@@ -0,0 +1,74 @@
+// Package chainstest provides the fixtures shared by the light client tests: empty
+// states, deterministic keys, test chain registration and header store resets.
+package chainstest
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+
+	"github.com/mapprotocol/atlas/chains"
+	"github.com/mapprotocol/atlas/core/rawdb"
+	"github.com/mapprotocol/atlas/core/state"
+	"github.com/mapprotocol/atlas/params"
+)
+
+// NewStateDB returns an empty state backed by a memory database.
+func NewStateDB() *state.StateDB {
+	db, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	return db
+}
+
+// NewStore returns a state holding the header store reset from the rlp encoding of
+// the reset input.
+func NewStore(t testing.TB, hs chains.IHeaderStore, reset interface{}) *state.StateDB {
+	t.Helper()
+	db := NewStateDB()
+	if err := hs.ResetHeaderStore(db, EncodeRLP(reset), nil); err != nil {
+		t.Fatalf("failed to reset the header store: %v", err)
+	}
+	return db
+}
+
+// RegisterChain registers a test chain running on the devnet in the module of the
+// group, with the given network configuration.
+func RegisterChain(group chains.ChainGroup, chain chains.ChainType, network interface{}) {
+	m, err := chains.GetModule(group)
+	if err != nil {
+		panic(err)
+	}
+	m.Chains[chain] = &chains.ChainParams{AtlasChainID: params.DevNetChainID, Network: network}
+}
+
+// ECDSAKeys returns n secp256k1 keys derived from the seed.
+func ECDSAKeys(seed string, n int) []*ecdsa.PrivateKey {
+	keys := make([]*ecdsa.PrivateKey, n)
+	for i := range keys {
+		keys[i], _ = crypto.ToECDSA(crypto.Keccak256([]byte(seed), []byte{byte(i)}))
+	}
+	return keys
+}
+
+// Ed25519Keys returns n ed25519 keys derived from the seed.
+func Ed25519Keys(seed string, n int) []ed25519.PrivateKey {
+	keys := make([]ed25519.PrivateKey, n)
+	for i := range keys {
+		hash := sha256.Sum256(append([]byte(seed), byte(i)))
+		keys[i] = ed25519.NewKeyFromSeed(hash[:])
+	}
+	return keys
+}
+
+// EncodeRLP returns the rlp encoding of v, it panics if v can not be encoded.
+func EncodeRLP(v interface{}) []byte {
+	data, err := rlp.EncodeToBytes(v)
+	if err != nil {
+		panic(err)
+	}
+	return data
+}
@@ -15,46 +15,54 @@ const (
 )
 
 const (
-	ChainTypeETH     ChainType = 1
-	ChainTypeETHTest ChainType = 34434
+	ChainTypeETH       ChainType = 1
+	ChainTypeETHTest   ChainType = 34434
+	ChainTypeBSC       ChainType = 56
+	ChainTypeBSCTest   ChainType = 97
+	ChainTypeMatic     ChainType = 137
+	ChainTypeMaticTest ChainType = 80002
 )
 
+// The chains without a numeric chain id are given one in the 0x4d50 ("MP") range,
+// 0x4d501 for NEAR and 0x4d502 for the CometBFT chains.
 const (
-	ChainGroupMAP = 1000
-	ChainGroupETH = 1001
+	ChainTypeNear          ChainType = 1360100178526209 // 0x4d50100000001, mainnet
+	ChainTypeNearTest      ChainType = 1360100178526210 // 0x4d50100000002, testnet
+	ChainTypeCosmosHub     ChainType = 1360104473493505 // 0x4d50200000001, cosmoshub-4
+	ChainTypeCosmosHubTest ChainType = 1360104473493506 // 0x4d50200000002, provider
 )
 
+const (
+	ChainGroupMAP    = 1000
+	ChainGroupETH    = 1001
+	ChainGroupETH2   = 1002
+	ChainGroupBSC    = 1003
+	ChainGroupMatic  = 1004
+	ChainGroupCosmos = 1005
+	ChainGroupNear   = 1006
+)
+
+// ChainTypeList are the atlas chains themselves, the chains followed by light
+// clients are added by the modules in the registry.
 var ChainTypeList = []ChainType{
 	ChainTypeMAP,
 	ChainTypeMAPTest,
 	ChainTypeMAPDev,
-	ChainTypeETH,
-	ChainTypeETHTest,
-}
-
-var chainType2ChainGroup = map[ChainType]ChainGroup{
-	ChainTypeETH:     ChainGroupETH,
-	ChainTypeETHTest: ChainGroupETH,
-}
-
-var chainType2ChainID = map[ChainType]uint64{
-	ChainTypeETH:     params.MainNetChainID,
-	ChainTypeETHTest: params.TestNetChainID,
-}
-
-var chainType2LondonBlock = map[ChainType]*big.Int{
-	ChainTypeETH:     big.NewInt(12_965_000),
-	ChainTypeETHTest: big.NewInt(10_499_401),
 }
 
 var (
 	EthereumHeaderStoreAddress = common.BytesToAddress([]byte("EthereumHeaderStoreAddress"))
+	Eth2HeaderStoreAddress     = common.BytesToAddress([]byte("Eth2HeaderStoreAddress"))
+	BSCHeaderStoreAddress      = common.BytesToAddress([]byte("BSCHeaderStoreAddress"))
+	MaticHeaderStoreAddress    = common.BytesToAddress([]byte("MaticHeaderStoreAddress"))
+	CosmosHeaderStoreAddress   = common.BytesToAddress([]byte("CosmosHeaderStoreAddress"))
+	NearHeaderStoreAddress     = common.BytesToAddress([]byte("NearHeaderStoreAddress"))
 )
 
 type ChainType uint64
 type ChainGroup uint64
 
-func IsSupportedChain(chain ChainType) bool {
+func isAtlasChain(chain ChainType) bool {
 	for _, c := range ChainTypeList {
 		if c == chain {
 			return true
@@ -63,26 +71,58 @@ func IsSupportedChain(chain ChainType) bool {
 	return false
 }
 
+// IsSupportedChain returns whether the chain is an atlas chain or followed by a light
+// client module activated in the atlas chain config at the given block.
+func IsSupportedChain(config *params.ChainConfig, number *big.Int, chain ChainType) bool {
+	if isAtlasChain(chain) {
+		return true
+	}
+	_, err := LookupModule(config, number, chain)
+	return err == nil
+}
+
+// IsRegisteredChain returns whether the chain is an atlas chain or followed by any
+// registered light client module, whether it is activated or not. It must not be used
+// by consensus code.
+func IsRegisteredChain(chain ChainType) bool {
+	if isAtlasChain(chain) {
+		return true
+	}
+	_, err := chainParams(chain)
+	return err == nil
+}
+
+// ChainType2ChainGroup returns the group of the module following the chain since genesis.
 func ChainType2ChainGroup(chain ChainType) (ChainGroup, error) {
-	group, ok := chainType2ChainGroup[chain]
-	if !ok {
-		return 0, ErrNotSupportChain
+	m, err := LookupModule(nil, nil, chain)
+	if err != nil {
+		return 0, err
+	}
+	return m.Group, nil
+}
+
+// ChainType2ChainGroupAt is like ChainType2ChainGroup, but takes into account the
+// light client modules activated in the atlas chain config at the given block.
+func ChainType2ChainGroupAt(config *params.ChainConfig, number *big.Int, chain ChainType) (ChainGroup, error) {
+	m, err := LookupModule(config, number, chain)
+	if err != nil {
+		return 0, err
 	}
-	return group, nil
+	return m.Group, nil
 }
 
 func ChainType2ChainID(chain ChainType) (uint64, error) {
-	chainID, ok := chainType2ChainID[chain]
-	if !ok {
-		return 0, ErrNotSupportChain
+	p, err := chainParams(chain)
+	if err != nil {
+		return 0, err
 	}
-	return chainID, nil
+	return p.AtlasChainID, nil
 }
 
 func ChainType2LondonBlock(chain ChainType) (*big.Int, error) {
-	lb, ok := chainType2LondonBlock[chain]
-	if !ok {
+	p, err := chainParams(chain)
+	if err != nil || p.LondonBlock == nil {
 		return nil, ErrNotSupportChain
 	}
-	return lb, nil
+	return p.LondonBlock, nil
 }
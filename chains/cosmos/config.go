@@ -0,0 +1,50 @@
+package cosmos
+
+import (
+	"github.com/mapprotocol/atlas/chains"
+)
+
+const (
+	day = uint64(24 * 60 * 60)
+
+	// defaultMaxClockDrift is how far the time of a header may be ahead of the atlas
+	// block time.
+	defaultMaxClockDrift = uint64(10)
+
+	// The trust level is the share of the voting power of the trusted validator set
+	// that must sign a header the light client skips to.
+	trustLevelNumerator   = 1
+	trustLevelDenominator = 3
+)
+
+// Config are the light client parameters of a CometBFT chain.
+type Config struct {
+	ChainID        string // CometBFT chain id, signed by every vote
+	TrustingPeriod uint64 // seconds a validator set is trusted for, shorter than the unbonding period
+	MaxClockDrift  uint64 // seconds a header may be ahead of the atlas block time
+}
+
+var mainnetConfig = &Config{
+	ChainID:        "cosmoshub-4",
+	TrustingPeriod: 14 * day, // two thirds of the 21 days unbonding period
+	MaxClockDrift:  defaultMaxClockDrift,
+}
+
+var testnetConfig = &Config{
+	ChainID:        "provider",
+	TrustingPeriod: 14 * day,
+	MaxClockDrift:  defaultMaxClockDrift,
+}
+
+// getConfig returns the network configuration registered for the chain.
+func getConfig(chainID uint64) (*Config, error) {
+	network, err := chains.Network(chains.ChainGroupCosmos, chains.ChainType(chainID))
+	if err != nil {
+		return nil, err
+	}
+	c, ok := network.(*Config)
+	if !ok {
+		return nil, chains.ErrNotSupportChain
+	}
+	return c, nil
+}
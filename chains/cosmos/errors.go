@@ -0,0 +1,22 @@
+package cosmos
+
+import "errors"
+
+var (
+	errNotInitialized      = errors.New("please initialize header store")
+	errInvalidNumber       = errors.New("invalid block number")
+	errMissingClock        = errors.New("atlas block time is not set")
+	errChainIDMismatch     = errors.New("header belongs to another chain")
+	errOlderBlockTime      = errors.New("timestamp not newer than the trusted header")
+	errFutureBlock         = errors.New("header time is too far in the future")
+	errTrustExpired        = errors.New("trusted header is older than the trusting period")
+	errInvalidValidators   = errors.New("validator set does not match the header")
+	errInvalidNextVals     = errors.New("next validator set does not match the header")
+	errUntrustedValidators = errors.New("validator set is not the one trusted for the next block")
+	errInvalidCommit       = errors.New("commit does not sign the header")
+	errInvalidSignature    = errors.New("invalid commit signature")
+	errNotEnoughPower      = errors.New("commit is not signed by enough voting power")
+	errDoubleVote          = errors.New("validator signed the commit twice")
+	errUnknownBlock        = errors.New("block is not stored in the light client")
+	errInvalidProof        = errors.New("invalid membership proof")
+)
@@ -0,0 +1,221 @@
+package cosmos
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rlp"
+
+	"github.com/mapprotocol/atlas/chains"
+)
+
+const (
+	// BlockIDFlagCommit marks a commit signature for the block of the commit, the other
+	// flags mark absent validators and votes for nil, which the light client ignores.
+	BlockIDFlagCommit = 2
+
+	// precommitType is the type of the votes collected in a commit.
+	precommitType = 2
+
+	// maxTotalVotingPower is the largest voting power of a CometBFT validator set.
+	maxTotalVotingPower = uint64(1<<63-1) / 8
+)
+
+// Timestamp is a point in time since the unix epoch, as encoded by protobuf.
+type Timestamp struct {
+	Seconds uint64
+	Nanos   uint32
+}
+
+func (t Timestamp) encode() []byte {
+	b := appendVarintField(nil, 1, t.Seconds)
+	return appendVarintField(b, 2, uint64(t.Nanos))
+}
+
+// after reports whether t is later than u.
+func (t Timestamp) after(u Timestamp) bool {
+	return t.Seconds > u.Seconds || (t.Seconds == u.Seconds && t.Nanos > u.Nanos)
+}
+
+// PartSetHeader identifies the parts a block is gossiped in.
+type PartSetHeader struct {
+	Total uint32
+	Hash  []byte
+}
+
+func (p *PartSetHeader) encode() []byte {
+	b := appendVarintField(nil, 1, uint64(p.Total))
+	return appendBytesField(b, 2, p.Hash)
+}
+
+// BlockID is the hash of a block together with its part set header.
+type BlockID struct {
+	Hash          []byte
+	PartSetHeader PartSetHeader
+}
+
+func (id *BlockID) encode() []byte {
+	b := appendBytesField(nil, 1, id.Hash)
+	return appendMessageField(b, 2, id.PartSetHeader.encode())
+}
+
+func (id *BlockID) isZero() bool {
+	return len(id.Hash) == 0 && id.PartSetHeader.Total == 0 && len(id.PartSetHeader.Hash) == 0
+}
+
+// Version are the block and application protocol versions of a header.
+type Version struct {
+	Block uint64
+	App   uint64
+}
+
+// Header is a CometBFT block header.
+type Header struct {
+	Version            Version
+	ChainID            string
+	Height             uint64
+	Time               Timestamp
+	LastBlockID        BlockID
+	LastCommitHash     []byte
+	DataHash           []byte
+	ValidatorsHash     []byte
+	NextValidatorsHash []byte
+	ConsensusHash      []byte
+	AppHash            []byte
+	LastResultsHash    []byte
+	EvidenceHash       []byte
+	ProposerAddress    []byte
+}
+
+// Hash returns the merkle root of the protobuf encoded header fields, which is the
+// block hash signed by the validators.
+func (h *Header) Hash() common.Hash {
+	version := appendVarintField(nil, 1, h.Version.Block)
+	version = appendVarintField(version, 2, h.Version.App)
+
+	fields := [][]byte{
+		version,
+		appendBytesField(nil, 1, []byte(h.ChainID)),
+		appendVarintField(nil, 1, h.Height),
+		h.Time.encode(),
+		h.LastBlockID.encode(),
+		appendBytesField(nil, 1, h.LastCommitHash),
+		appendBytesField(nil, 1, h.DataHash),
+		appendBytesField(nil, 1, h.ValidatorsHash),
+		appendBytesField(nil, 1, h.NextValidatorsHash),
+		appendBytesField(nil, 1, h.ConsensusHash),
+		appendBytesField(nil, 1, h.AppHash),
+		appendBytesField(nil, 1, h.LastResultsHash),
+		appendBytesField(nil, 1, h.EvidenceHash),
+		appendBytesField(nil, 1, h.ProposerAddress),
+	}
+	return common.BytesToHash(merkleRoot(fields))
+}
+
+// CommitSig is the precommit of a validator included in a commit.
+type CommitSig struct {
+	BlockIDFlag      uint8
+	ValidatorAddress []byte
+	Timestamp        Timestamp
+	Signature        []byte
+}
+
+// Commit is the set of precommits that finalized a block, one entry per validator
+// of the set, in the order of the set.
+type Commit struct {
+	Height     uint64
+	Round      uint32
+	BlockID    BlockID
+	Signatures []*CommitSig
+}
+
+// voteSignBytes returns the length prefixed canonical vote the validator of the
+// signature at idx signed.
+func (c *Commit) voteSignBytes(chainID string, idx int) []byte {
+	sig := c.Signatures[idx]
+
+	vote := appendVarintField(nil, 1, precommitType)
+	vote = appendFixed64Field(vote, 2, c.Height)
+	vote = appendFixed64Field(vote, 3, uint64(c.Round))
+	if !c.BlockID.isZero() {
+		vote = appendMessageField(vote, 4, c.BlockID.encode())
+	}
+	vote = appendMessageField(vote, 5, sig.Timestamp.encode())
+	vote = appendBytesField(vote, 6, []byte(chainID))
+
+	return append(binary.AppendUvarint(nil, uint64(len(vote))), vote...)
+}
+
+// Validator is a member of a validator set with its ed25519 public key.
+type Validator struct {
+	PubKey      []byte
+	VotingPower uint64
+}
+
+// Address returns the address of the validator, the first 20 bytes of the sha256
+// hash of its public key.
+func (v *Validator) Address() common.Address {
+	hash := sha256.Sum256(v.PubKey)
+	return common.BytesToAddress(hash[:common.AddressLength])
+}
+
+// encode returns the simple validator encoding hashed into the validator set hash.
+func (v *Validator) encode() []byte {
+	pubKey := appendBytesField(nil, 1, v.PubKey) // ed25519 member of the public key oneof
+	b := appendMessageField(nil, 1, pubKey)
+	return appendVarintField(b, 2, v.VotingPower)
+}
+
+// validatorsHash returns the merkle root of the validator set.
+func validatorsHash(vals []*Validator) common.Hash {
+	items := make([][]byte, len(vals))
+	for i, v := range vals {
+		items[i] = v.encode()
+	}
+	return common.BytesToHash(merkleRoot(items))
+}
+
+// totalVotingPower returns the voting power of the validator set, or false if the
+// set is invalid.
+func totalVotingPower(vals []*Validator) (uint64, bool) {
+	var total uint64
+	for _, v := range vals {
+		if v == nil || len(v.PubKey) != 32 || v.VotingPower == 0 {
+			return 0, false
+		}
+		total += v.VotingPower
+		if total > maxTotalVotingPower {
+			return 0, false
+		}
+	}
+	return total, len(vals) > 0
+}
+
+// LightBlock is a header with the commit finalizing it, its validator set and the
+// validator set of the next block, the unit the light client is advanced by.
+type LightBlock struct {
+	Header         *Header
+	Commit         *Commit
+	Validators     []*Validator
+	NextValidators []*Validator
+}
+
+// Index returns the indexed part of the header of the block, it implements
+// chains.IIndexable. The raw header is the rlp encoding of the header alone.
+func (b *LightBlock) Index(_ rlp.RawValue) (*chains.IndexedHeader, error) {
+	if b.Header == nil {
+		return nil, errInvalidNumber
+	}
+	raw, err := rlp.EncodeToBytes(b.Header)
+	if err != nil {
+		return nil, err
+	}
+	return &chains.IndexedHeader{
+		Number:      b.Header.Height,
+		Hash:        b.Header.Hash(),
+		ParentHash:  common.BytesToHash(b.Header.LastBlockID.Hash),
+		ReceiptHash: common.BytesToHash(b.Header.AppHash),
+		Raw:         raw,
+	}, nil
+}
@@ -0,0 +1,203 @@
+package cosmos
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
+
+	"github.com/mapprotocol/atlas/chains"
+	"github.com/mapprotocol/atlas/core/types"
+	"github.com/mapprotocol/atlas/params"
+)
+
+const (
+	MaxHeaderLimit = 100000
+)
+
+var stateStore = chains.NewStateStore(chains.CosmosHeaderStoreAddress)
+
+// HeaderStore is the CometBFT light client of a Cosmos chain.
+type HeaderStore struct {
+	ChainID uint64
+	State   *TrustedState
+
+	now uint64
+}
+
+// LightHeader is the part of a stored header needed to verify membership proofs.
+type LightHeader struct {
+	Height  uint64
+	Hash    common.Hash
+	AppHash []byte
+}
+
+// resetInput initializes the light client with a trusted light block of the chain.
+type resetInput struct {
+	ChainID uint64
+	Block   *LightBlock
+}
+
+func NewHeaderStore() *HeaderStore {
+	return &HeaderStore{}
+}
+
+func headerDbKey(height uint64) common.Hash {
+	str := fmt.Sprintf("%s-%d", "cosmos", height%MaxHeaderLimit)
+	return common.BytesToHash([]byte(str))
+}
+
+func (hs *HeaderStore) config() (*Config, error) {
+	return getConfig(hs.ChainID)
+}
+
+// SetBlockTime sets the atlas block time the age of the trusted header is checked
+// against, it implements chains.IClockConfigurable.
+func (hs *HeaderStore) SetBlockTime(time uint64) {
+	hs.now = time
+}
+
+// ResetHeaderStore initializes the light client with a trusted light block, encoded
+// as rlp(chainID, block). The block must be signed by its own validators.
+func (hs *HeaderStore) ResetHeaderStore(db types.StateDB, input []byte, td *big.Int) error {
+	var ri resetInput
+	if err := rlp.DecodeBytes(input, &ri); err != nil {
+		log.Error("rlp decode cosmos reset input failed", "err", err)
+		return chains.ErrRLPDecode
+	}
+	if ri.Block == nil {
+		return errInvalidNumber
+	}
+	config, err := getConfig(ri.ChainID)
+	if err != nil {
+		return err
+	}
+	block := ri.Block
+	if err := block.validateBasic(config.ChainID); err != nil {
+		return err
+	}
+	if err := verifyCommitLight(config.ChainID, block.Validators, block.Commit, make(map[int]bool)); err != nil {
+		return err
+	}
+
+	header := block.Header
+	h := &HeaderStore{
+		ChainID: ri.ChainID,
+		State: &TrustedState{
+			Height:         header.Height,
+			Hash:           header.Hash(),
+			Time:           header.Time,
+			NextValidators: block.NextValidators,
+		},
+	}
+	if err := h.StoreHeader(db, &LightHeader{Height: header.Height, Hash: h.State.Hash, AppHash: header.AppHash}); err != nil {
+		return err
+	}
+	return h.Store(db)
+}
+
+func (hs *HeaderStore) Store(db types.StateDB) error {
+	return stateStore.Store(db, hs)
+}
+
+// Load reads the light client from the state, the block time set on hs is kept.
+func (hs *HeaderStore) Load(db types.StateDB) error {
+	now := hs.now
+	ok, err := stateStore.Load(db, hs)
+	hs.now = now
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errNotInitialized
+	}
+	return nil
+}
+
+func (hs *HeaderStore) StoreHeader(db types.StateDB, header *LightHeader) error {
+	data, err := rlp.EncodeToBytes(header)
+	if err != nil {
+		log.Error("Failed to RLP encode LightHeader", "err", err)
+		return err
+	}
+	db.SetPOWState(chains.CosmosHeaderStoreAddress, headerDbKey(header.Height), data)
+	return nil
+}
+
+// LoadHeader returns the stored header with the given height, or nil if it was
+// never stored or has been overwritten since.
+func (hs *HeaderStore) LoadHeader(db types.StateDB, height uint64) (*LightHeader, error) {
+	data := db.GetPOWState(chains.CosmosHeaderStoreAddress, headerDbKey(height))
+	if len(data) == 0 {
+		return nil, nil
+	}
+
+	var header LightHeader
+	if err := rlp.DecodeBytes(data, &header); err != nil {
+		return nil, fmt.Errorf("LightHeader RLP decode failed, error: %s", err.Error())
+	}
+	if header.Height != height {
+		return nil, nil
+	}
+	return &header, nil
+}
+
+// InsertHeaders advances the light client with already validated light blocks.
+func (hs *HeaderStore) InsertHeaders(db types.StateDB, input []byte) ([]*params.NumberHash, error) {
+	var blocks []*LightBlock
+	if err := rlp.DecodeBytes(input, &blocks); err != nil {
+		log.Error("rlp decode cosmos light blocks failed", "err", err)
+		return nil, chains.ErrRLPDecode
+	}
+	if err := hs.Load(db); err != nil {
+		return nil, err
+	}
+	config, err := hs.config()
+	if err != nil {
+		return nil, err
+	}
+
+	nums := make([]*params.NumberHash, 0, len(blocks))
+	for _, block := range blocks {
+		if err := hs.State.apply(block, config, hs.now); err != nil {
+			return nil, err
+		}
+		lh := &LightHeader{Height: hs.State.Height, Hash: hs.State.Hash, AppHash: block.Header.AppHash}
+		if err := hs.StoreHeader(db, lh); err != nil {
+			return nil, err
+		}
+		nums = append(nums, &params.NumberHash{Number: lh.Height, Hash: lh.Hash})
+	}
+	if err := hs.Store(db); err != nil {
+		return nil, err
+	}
+	log.Info("stored new cosmos headers", "count", len(nums), "height", hs.State.Height, "hash", hs.State.Hash)
+	return nums, nil
+}
+
+func (hs *HeaderStore) GetCurrentNumberAndHash(db types.StateDB) (uint64, common.Hash, error) {
+	if err := hs.Load(db); err != nil {
+		return 0, common.Hash{}, err
+	}
+	return hs.State.Height, hs.State.Hash, nil
+}
+
+func (hs *HeaderStore) GetHashByNumber(db types.StateDB, number uint64) (common.Hash, error) {
+	if err := hs.Load(db); err != nil {
+		return common.Hash{}, err
+	}
+	header, err := hs.LoadHeader(db, number)
+	if err != nil || header == nil {
+		return common.Hash{}, err
+	}
+	return header.Hash, nil
+}
+
+// DecodeHeaders decodes the headers of an updateBlockHeader input for node-local
+// indexes, it implements chains.IHeaderDecoder. The app hash a header commits to is
+// indexed in place of the receipts root.
+func (hs *HeaderStore) DecodeHeaders(input []byte, _ chains.ChainType) ([]*chains.IndexedHeader, error) {
+	return chains.DecodeIndexedHeaders(input, func() chains.IIndexable { return new(LightBlock) })
+}
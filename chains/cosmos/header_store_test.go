@@ -0,0 +1,176 @@
+package cosmos
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mapprotocol/atlas/chains/chainstest"
+)
+
+// now returns an atlas block time shortly after the block at height.
+func now(height uint64) uint64 {
+	return blockTime(height).Seconds + 1
+}
+
+func TestHeaderHash(t *testing.T) {
+	sum := func(s string) []byte {
+		hash := sha256.Sum256([]byte(s))
+		return hash[:]
+	}
+	// the header hash test vector of CometBFT
+	header := &Header{
+		Version:            Version{Block: 1, App: 2},
+		ChainID:            "chainId",
+		Height:             3,
+		Time:               Timestamp{Seconds: 1570983284},
+		LastBlockID:        BlockID{Hash: make([]byte, 32), PartSetHeader: PartSetHeader{Total: 6, Hash: make([]byte, 32)}},
+		LastCommitHash:     sum("last_commit_hash"),
+		DataHash:           sum("data_hash"),
+		ValidatorsHash:     sum("validators_hash"),
+		NextValidatorsHash: sum("next_validators_hash"),
+		ConsensusHash:      sum("consensus_hash"),
+		AppHash:            sum("app_hash"),
+		LastResultsHash:    sum("last_results_hash"),
+		EvidenceHash:       sum("evidence_hash"),
+		ProposerAddress:    sum("proposer_address")[:20],
+	}
+	assert.Equal(t, "f740121f553b5418c3efbd343c2dbfe9e007bb67b0d020a0741374bab65242a4", hex.EncodeToString(header.Hash().Bytes()))
+}
+
+func TestHeaderStore_InsertHeaders(t *testing.T) {
+	var (
+		genesis = newValidatorSet("genesis", 10, 20, 30)
+		rotated = newValidatorSet("rotated", 5, 5, 5, 5)
+		blocks  = makeChain(20, func(height uint64) *validatorSet {
+			if height < 10 {
+				return genesis
+			}
+			return rotated
+		})
+		db = chainstest.NewStore(t, NewHeaderStore(), &resetInput{ChainID: uint64(testChainType), Block: blocks[0]})
+	)
+
+	v := new(Validate)
+	for _, batch := range [][]*LightBlock{blocks[1:5], blocks[5:12], blocks[12:]} {
+		head := batch[len(batch)-1].Header.Height
+		v.SetBlockTime(now(head))
+		_, err := v.ValidateHeaderChain(db, chainstest.EncodeRLP(batch), testChainType)
+		assert.NoError(t, err)
+
+		hs := NewHeaderStore()
+		hs.SetBlockTime(now(head))
+		nums, err := hs.InsertHeaders(db, chainstest.EncodeRLP(batch))
+		assert.NoError(t, err)
+		assert.Equal(t, len(batch), len(nums))
+	}
+
+	hs := NewHeaderStore()
+	number, hash, err := hs.GetCurrentNumberAndHash(db)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(20), number)
+	assert.Equal(t, blocks[19].Header.Hash(), hash)
+	assert.Equal(t, rotated.validators, hs.State.NextValidators)
+
+	for _, b := range blocks {
+		got, err := hs.GetHashByNumber(db, b.Header.Height)
+		assert.NoError(t, err)
+		assert.Equal(t, b.Header.Hash(), got)
+	}
+}
+
+func TestValidate_Skipping(t *testing.T) {
+	var (
+		set     = newValidatorSet("set", 10, 10, 10, 10)
+		changed = &validatorSet{validators: set.validators[1:], keys: set.keys[1:]}
+		other   = newValidatorSet("other", 10, 10, 10)
+		genesis = makeBlock(1, set, set)
+	)
+	tests := []struct {
+		name  string
+		block *LightBlock
+		err   error
+	}{
+		{"same validators", makeBlock(50, set, set), nil},
+		{"overlapping validators", makeBlock(50, changed, changed), nil},
+		{"a third of the trusted power", makeBlock(50, set, set, 0, 1, 2), nil},
+		{"unknown validators", makeBlock(50, other, other), errNotEnoughPower},
+		{"not enough trusted power", makeBlock(50, changed, changed, 0, 1), errNotEnoughPower},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := chainstest.NewStore(t, NewHeaderStore(), &resetInput{ChainID: uint64(testChainType), Block: genesis})
+			v := &Validate{now: now(50)}
+			_, err := v.ValidateHeaderChain(db, chainstest.EncodeRLP([]*LightBlock{tt.block}), testChainType)
+			assert.True(t, errors.Is(err, tt.err), "got %v, want %v", err, tt.err)
+		})
+	}
+}
+
+func TestValidate_Invalid(t *testing.T) {
+	var (
+		set     = newValidatorSet("set", 10, 10, 10)
+		other   = newValidatorSet("other", 10, 10, 10)
+		genesis = makeBlock(1, set, set)
+	)
+	tests := []struct {
+		name  string
+		block func() *LightBlock
+		now   uint64
+		err   error
+	}{
+		{"valid", func() *LightBlock { return makeBlock(2, set, set) }, now(1), nil},
+		{"two thirds of the power", func() *LightBlock { return makeBlock(2, set, set, 0, 1) }, now(1), errNotEnoughPower},
+		{"untrusted validators", func() *LightBlock { return makeBlock(2, other, other) }, now(1), errUntrustedValidators},
+		{"missing clock", func() *LightBlock { return makeBlock(2, set, set) }, 0, errMissingClock},
+		{"trust expired", func() *LightBlock { return makeBlock(2, set, set) }, now(1) + testConfig.TrustingPeriod, errTrustExpired},
+		{"future block", func() *LightBlock { return makeBlock(2, set, set) }, blockTime(2).Seconds - testConfig.MaxClockDrift - 1, errFutureBlock},
+		{"stale block", func() *LightBlock { return makeBlock(1, set, set) }, now(1), errInvalidNumber},
+		{"bad signature", func() *LightBlock {
+			b := makeBlock(2, set, set)
+			b.Commit.Signatures[1].Signature[0] ^= 1
+			return b
+		}, now(1), errInvalidSignature},
+		{"wrong signer address", func() *LightBlock {
+			b := makeBlock(2, set, set)
+			b.Commit.Signatures[0].ValidatorAddress = b.Commit.Signatures[1].ValidatorAddress
+			return b
+		}, now(1), errInvalidSignature},
+		{"wrong chain", func() *LightBlock {
+			b := makeBlock(2, set, set)
+			b.Header.ChainID = "other-chain"
+			return b
+		}, now(1), errChainIDMismatch},
+		{"commit of another header", func() *LightBlock {
+			b := makeBlock(2, set, set)
+			b.Header.AppHash = []byte("forged")
+			return b
+		}, now(1), errInvalidCommit},
+		{"validators of another header", func() *LightBlock {
+			b := makeBlock(2, set, set)
+			b.Validators = other.validators
+			return b
+		}, now(1), errInvalidValidators},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := chainstest.NewStore(t, NewHeaderStore(), &resetInput{ChainID: uint64(testChainType), Block: genesis})
+			v := &Validate{now: tt.now}
+			_, err := v.ValidateHeaderChain(db, chainstest.EncodeRLP([]*LightBlock{tt.block()}), testChainType)
+			assert.True(t, errors.Is(err, tt.err), "got %v, want %v", err, tt.err)
+		})
+	}
+}
+
+func TestValidate_EstimateWork(t *testing.T) {
+	set := newValidatorSet("set", 10, 10, 10)
+	blocks := []*LightBlock{makeBlock(2, set, set), makeBlock(3, set, set, 0, 2)}
+	work, err := new(Validate).EstimateWork(nil, chainstest.EncodeRLP(blocks))
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2), work.Headers)
+	assert.Equal(t, uint64(5), work.Signatures)
+	assert.Equal(t, uint64(3), work.StateWrites)
+}
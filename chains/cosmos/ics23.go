@@ -0,0 +1,286 @@
+package cosmos
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+)
+
+// The membership proofs of the Cosmos SDK stores follow ICS-23: a value is proven in
+// the IAVL tree of a module store, and the root of that tree is proven in the simple
+// merkle tree of the multistore whose root is the app hash of the block.
+
+// HashOp is the hash function of an ICS-23 proof step, numbered as in ICS-23.
+type HashOp uint8
+
+const (
+	HashOpNoHash HashOp = 0
+	HashOpSHA256 HashOp = 1
+)
+
+// LengthOp is how the key and value of a leaf are length prefixed, numbered as in
+// ICS-23.
+type LengthOp uint8
+
+const (
+	LengthOpNoPrefix LengthOp = 0
+	LengthOpVarProto LengthOp = 1
+)
+
+// LeafOp computes the hash of a key value pair.
+type LeafOp struct {
+	Hash         HashOp
+	PrehashKey   HashOp
+	PrehashValue HashOp
+	Length       LengthOp
+	Prefix       []byte
+}
+
+// InnerOp computes the hash of an inner node from the hash of one of its children,
+// the hashes of the other children are part of the prefix and the suffix.
+type InnerOp struct {
+	Hash   HashOp
+	Prefix []byte
+	Suffix []byte
+}
+
+// ExistenceProof proves a key value pair is stored under a root.
+type ExistenceProof struct {
+	Key   []byte
+	Value []byte
+	Leaf  *LeafOp
+	Path  []*InnerOp
+}
+
+// InnerSpec is the shape of the inner nodes of a tree.
+type InnerSpec struct {
+	ChildOrder      []int
+	ChildSize       int
+	MinPrefixLength int
+	MaxPrefixLength int
+	Hash            HashOp
+}
+
+// ProofSpec is the shape of the proofs of a tree, proofs are checked against it so
+// that a leaf can not be presented as an inner node and the other way round.
+type ProofSpec struct {
+	LeafSpec  *LeafOp
+	InnerSpec *InnerSpec
+	iavl      bool
+}
+
+var (
+	// IavlSpec is the spec of the IAVL trees of the module stores.
+	IavlSpec = &ProofSpec{
+		LeafSpec: &LeafOp{
+			Hash:         HashOpSHA256,
+			PrehashKey:   HashOpNoHash,
+			PrehashValue: HashOpSHA256,
+			Length:       LengthOpVarProto,
+			Prefix:       []byte{0},
+		},
+		InnerSpec: &InnerSpec{
+			ChildOrder:      []int{0, 1},
+			ChildSize:       33,
+			MinPrefixLength: 4,
+			MaxPrefixLength: 12,
+			Hash:            HashOpSHA256,
+		},
+		iavl: true,
+	}
+
+	// TendermintSpec is the spec of the simple merkle tree of the multistore.
+	TendermintSpec = &ProofSpec{
+		LeafSpec: &LeafOp{
+			Hash:         HashOpSHA256,
+			PrehashKey:   HashOpNoHash,
+			PrehashValue: HashOpSHA256,
+			Length:       LengthOpVarProto,
+			Prefix:       []byte{0},
+		},
+		InnerSpec: &InnerSpec{
+			ChildOrder:      []int{0, 1},
+			ChildSize:       32,
+			MinPrefixLength: 1,
+			MaxPrefixLength: 1,
+			Hash:            HashOpSHA256,
+		},
+	}
+)
+
+func doHash(op HashOp, data []byte) ([]byte, error) {
+	switch op {
+	case HashOpNoHash:
+		return data, nil
+	case HashOpSHA256:
+		hash := sha256.Sum256(data)
+		return hash[:], nil
+	}
+	return nil, fmt.Errorf("unsupported hash op %d", op)
+}
+
+func doLength(op LengthOp, data []byte) ([]byte, error) {
+	switch op {
+	case LengthOpNoPrefix:
+		return data, nil
+	case LengthOpVarProto:
+		return append(binary.AppendUvarint(nil, uint64(len(data))), data...), nil
+	}
+	return nil, fmt.Errorf("unsupported length op %d", op)
+}
+
+func (op *LeafOp) prepare(hashOp HashOp, data []byte) ([]byte, error) {
+	hashed, err := doHash(hashOp, data)
+	if err != nil {
+		return nil, err
+	}
+	return doLength(op.Length, hashed)
+}
+
+// apply returns the hash of the leaf of the key value pair.
+func (op *LeafOp) apply(key, value []byte) ([]byte, error) {
+	if len(key) == 0 || len(value) == 0 {
+		return nil, errInvalidProof
+	}
+	pkey, err := op.prepare(op.PrehashKey, key)
+	if err != nil {
+		return nil, err
+	}
+	pvalue, err := op.prepare(op.PrehashValue, value)
+	if err != nil {
+		return nil, err
+	}
+	data := append(append(append([]byte{}, op.Prefix...), pkey...), pvalue...)
+	return doHash(op.Hash, data)
+}
+
+// apply returns the hash of the inner node with the given child.
+func (op *InnerOp) apply(child []byte) ([]byte, error) {
+	if len(child) == 0 {
+		return nil, errInvalidProof
+	}
+	data := append(append(append([]byte{}, op.Prefix...), child...), op.Suffix...)
+	return doHash(op.Hash, data)
+}
+
+// checkLeaf checks the leaf operation against the leaf spec.
+func (spec *ProofSpec) checkLeaf(op *LeafOp) error {
+	if op == nil {
+		return errInvalidProof
+	}
+	leaf := spec.LeafSpec
+	if op.Hash != leaf.Hash || op.PrehashKey != leaf.PrehashKey || op.PrehashValue != leaf.PrehashValue || op.Length != leaf.Length {
+		return fmt.Errorf("%w: unexpected leaf operation", errInvalidProof)
+	}
+	if !bytes.HasPrefix(op.Prefix, leaf.Prefix) {
+		return fmt.Errorf("%w: unexpected leaf prefix", errInvalidProof)
+	}
+	if spec.iavl {
+		return checkIavlPrefix(op.Prefix, 0)
+	}
+	return nil
+}
+
+// checkInner checks the inner operation at the given layer, the leaf is layer 0,
+// against the inner spec.
+func (spec *ProofSpec) checkInner(op *InnerOp, layer int) error {
+	if op == nil {
+		return errInvalidProof
+	}
+	inner := spec.InnerSpec
+	if op.Hash != inner.Hash {
+		return fmt.Errorf("%w: unexpected inner hash", errInvalidProof)
+	}
+	if bytes.HasPrefix(op.Prefix, spec.LeafSpec.Prefix) {
+		return fmt.Errorf("%w: inner prefix starts like a leaf", errInvalidProof)
+	}
+	maxLeftChildBytes := (len(inner.ChildOrder) - 1) * inner.ChildSize
+	if len(op.Prefix) < inner.MinPrefixLength || len(op.Prefix) > inner.MaxPrefixLength+maxLeftChildBytes {
+		return fmt.Errorf("%w: inner prefix of invalid length", errInvalidProof)
+	}
+	if len(op.Suffix)%inner.ChildSize != 0 {
+		return fmt.Errorf("%w: inner suffix of invalid length", errInvalidProof)
+	}
+	if spec.iavl {
+		return checkIavlPrefix(op.Prefix, layer)
+	}
+	return nil
+}
+
+// checkIavlPrefix checks the height, size and version varints an IAVL node prefix
+// starts with. A leaf prefix holds nothing else, an inner prefix is followed by the
+// length prefix of the left child, and the left child itself if the proven child is
+// on the right.
+func checkIavlPrefix(prefix []byte, layer int) error {
+	r := bytes.NewReader(prefix)
+	height, err := binary.ReadVarint(r)
+	if err != nil || height < int64(layer) {
+		return fmt.Errorf("%w: invalid IAVL height", errInvalidProof)
+	}
+	size, err := binary.ReadVarint(r)
+	if err != nil || size < 0 {
+		return fmt.Errorf("%w: invalid IAVL size", errInvalidProof)
+	}
+	version, err := binary.ReadVarint(r)
+	if err != nil || version < 0 {
+		return fmt.Errorf("%w: invalid IAVL version", errInvalidProof)
+	}
+	rest := r.Len()
+	if layer == 0 && rest != 0 {
+		return fmt.Errorf("%w: IAVL leaf prefix too long", errInvalidProof)
+	}
+	if layer > 0 && rest != 1 && rest != 34 {
+		return fmt.Errorf("%w: IAVL inner prefix of invalid length", errInvalidProof)
+	}
+	return nil
+}
+
+// calculate checks the proof against the spec and returns the root it leads to.
+func (p *ExistenceProof) calculate(spec *ProofSpec) ([]byte, error) {
+	if p == nil {
+		return nil, errInvalidProof
+	}
+	if err := spec.checkLeaf(p.Leaf); err != nil {
+		return nil, err
+	}
+	root, err := p.Leaf.apply(p.Key, p.Value)
+	if err != nil {
+		return nil, err
+	}
+	for i, op := range p.Path {
+		if err := spec.checkInner(op, i+1); err != nil {
+			return nil, err
+		}
+		if root, err = op.apply(root); err != nil {
+			return nil, err
+		}
+	}
+	return root, nil
+}
+
+// prove checks that the proof stores value under key and returns the root it leads to.
+func (p *ExistenceProof) prove(spec *ProofSpec, key, value []byte) ([]byte, error) {
+	if p == nil {
+		return nil, errInvalidProof
+	}
+	if !bytes.Equal(p.Key, key) {
+		return nil, fmt.Errorf("%w: proven key %x, want %x", errInvalidProof, p.Key, key)
+	}
+	if !bytes.Equal(p.Value, value) {
+		return nil, fmt.Errorf("%w: proven value does not match", errInvalidProof)
+	}
+	return p.calculate(spec)
+}
+
+// verifyMembership checks that the proof stores value under key and leads to root.
+func (p *ExistenceProof) verifyMembership(spec *ProofSpec, root, key, value []byte) error {
+	calculated, err := p.prove(spec, key, value)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(calculated, root) {
+		return fmt.Errorf("%w: calculated root %x, want %x", errInvalidProof, calculated, root)
+	}
+	return nil
+}
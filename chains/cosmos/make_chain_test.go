@@ -0,0 +1,98 @@
+package cosmos
+
+import (
+	"crypto/ed25519"
+
+	"github.com/mapprotocol/atlas/chains"
+	"github.com/mapprotocol/atlas/chains/chainstest"
+)
+
+const (
+	testChainType chains.ChainType = 1_000_005
+	testGenesis                    = uint64(1_700_000_000)
+	testBlockTime                  = uint64(5)
+)
+
+var testConfig = &Config{
+	ChainID:        "test-chain",
+	TrustingPeriod: 1000,
+	MaxClockDrift:  defaultMaxClockDrift,
+}
+
+func init() {
+	chainstest.RegisterChain(chains.ChainGroupCosmos, testChainType, testConfig)
+}
+
+// validatorSet is a validator set with the keys of its members.
+type validatorSet struct {
+	validators []*Validator
+	keys       []ed25519.PrivateKey
+}
+
+func newValidatorSet(seed string, powers ...uint64) *validatorSet {
+	s := &validatorSet{keys: chainstest.Ed25519Keys(seed, len(powers))}
+	for i, power := range powers {
+		s.validators = append(s.validators, &Validator{PubKey: s.keys[i].Public().(ed25519.PublicKey), VotingPower: power})
+	}
+	return s
+}
+
+func blockTime(height uint64) Timestamp {
+	return Timestamp{Seconds: testGenesis + height*testBlockTime, Nanos: 1}
+}
+
+// makeBlock returns the light block at height signed by the members of vals at the
+// given indexes, all of them if none are given.
+func makeBlock(height uint64, vals, nextVals *validatorSet, signers ...int) *LightBlock {
+	header := &Header{
+		Version:            Version{Block: 11, App: 1},
+		ChainID:            testConfig.ChainID,
+		Height:             height,
+		Time:               blockTime(height),
+		LastBlockID:        BlockID{Hash: make([]byte, 32), PartSetHeader: PartSetHeader{Total: 1, Hash: make([]byte, 32)}},
+		ValidatorsHash:     validatorsHash(vals.validators).Bytes(),
+		NextValidatorsHash: validatorsHash(nextVals.validators).Bytes(),
+		AppHash:            append([]byte("app hash"), byte(height)),
+		ProposerAddress:    vals.validators[0].Address().Bytes(),
+	}
+	return &LightBlock{Header: header, Commit: makeCommit(header, vals, signers...), Validators: vals.validators, NextValidators: nextVals.validators}
+}
+
+// makeCommit returns the commit of the header signed by the members of vals at the
+// given indexes, all of them if none are given.
+func makeCommit(header *Header, vals *validatorSet, signers ...int) *Commit {
+	if signers == nil {
+		for i := range vals.validators {
+			signers = append(signers, i)
+		}
+	}
+	hash := header.Hash()
+	commit := &Commit{
+		Height:     header.Height,
+		Round:      1,
+		BlockID:    BlockID{Hash: hash.Bytes(), PartSetHeader: PartSetHeader{Total: 1, Hash: hash.Bytes()}},
+		Signatures: make([]*CommitSig, len(vals.validators)),
+	}
+	for i := range commit.Signatures {
+		commit.Signatures[i] = &CommitSig{BlockIDFlag: 1}
+	}
+	for _, i := range signers {
+		commit.Signatures[i] = &CommitSig{
+			BlockIDFlag:      BlockIDFlagCommit,
+			ValidatorAddress: vals.validators[i].Address().Bytes(),
+			Timestamp:        header.Time,
+		}
+		commit.Signatures[i].Signature = ed25519.Sign(vals.keys[i], commit.voteSignBytes(testConfig.ChainID, i))
+	}
+	return commit
+}
+
+// makeChain returns the light blocks from height 1 to n, the validator set of a block
+// is the one of sets at its height.
+func makeChain(n uint64, sets func(height uint64) *validatorSet) []*LightBlock {
+	blocks := make([]*LightBlock, 0, n)
+	for height := uint64(1); height <= n; height++ {
+		blocks = append(blocks, makeBlock(height, sets(height), sets(height+1)))
+	}
+	return blocks
+}
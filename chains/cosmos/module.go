@@ -0,0 +1,22 @@
+package cosmos
+
+import (
+	"math/big"
+
+	"github.com/mapprotocol/atlas/chains"
+	"github.com/mapprotocol/atlas/params"
+)
+
+func init() {
+	chains.Register(&chains.Module{
+		Group: chains.ChainGroupCosmos,
+		Chains: map[chains.ChainType]*chains.ChainParams{
+			chains.ChainTypeCosmosHub:     {AtlasChainID: params.MainNetChainID, Network: mainnetConfig},
+			chains.ChainTypeCosmosHubTest: {AtlasChainID: params.TestNetChainID, Network: testnetConfig},
+		},
+		ForkBlock:      func(config *params.ChainConfig) *big.Int { return config.CosmosBlock },
+		NewValidate:    func() chains.IValidate { return new(Validate) },
+		NewHeaderStore: func() chains.IHeaderStore { return new(HeaderStore) },
+		NewVerify:      func() chains.IVerify { return new(Verify) },
+	})
+}
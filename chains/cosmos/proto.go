@@ -0,0 +1,94 @@
+package cosmos
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"math/bits"
+)
+
+// CometBFT hashes and signs the protobuf encoding of its types. The light client only
+// needs to produce these encodings, so the few messages involved are encoded by hand,
+// with the proto3 rule that scalar fields holding their zero value are omitted.
+
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+)
+
+func appendTag(b []byte, field int, wire int) []byte {
+	return binary.AppendUvarint(b, uint64(field)<<3|uint64(wire))
+}
+
+func appendVarintField(b []byte, field int, v uint64) []byte {
+	if v == 0 {
+		return b
+	}
+	return binary.AppendUvarint(appendTag(b, field, wireVarint), v)
+}
+
+func appendFixed64Field(b []byte, field int, v uint64) []byte {
+	if v == 0 {
+		return b
+	}
+	return binary.LittleEndian.AppendUint64(appendTag(b, field, wireFixed64), v)
+}
+
+func appendBytesField(b []byte, field int, v []byte) []byte {
+	if len(v) == 0 {
+		return b
+	}
+	return appendMessageField(b, field, v)
+}
+
+// appendMessageField encodes an embedded message, it is written even if empty as the
+// non-nullable messages of CometBFT are.
+func appendMessageField(b []byte, field int, msg []byte) []byte {
+	b = binary.AppendUvarint(appendTag(b, field, wireBytes), uint64(len(msg)))
+	return append(b, msg...)
+}
+
+// Merkle trees of CometBFT follow RFC 6962, leaves and inner nodes are hashed with
+// different prefixes and the tree is split at the largest power of two smaller than
+// the number of leaves.
+var (
+	leafPrefix  = []byte{0}
+	innerPrefix = []byte{1}
+)
+
+func leafHash(leaf []byte) []byte {
+	h := sha256.New()
+	h.Write(leafPrefix)
+	h.Write(leaf)
+	return h.Sum(nil)
+}
+
+func innerHash(left, right []byte) []byte {
+	h := sha256.New()
+	h.Write(innerPrefix)
+	h.Write(left)
+	h.Write(right)
+	return h.Sum(nil)
+}
+
+// merkleRoot returns the root of the merkle tree over the items.
+func merkleRoot(items [][]byte) []byte {
+	switch len(items) {
+	case 0:
+		empty := sha256.Sum256(nil)
+		return empty[:]
+	case 1:
+		return leafHash(items[0])
+	}
+	k := splitPoint(len(items))
+	return innerHash(merkleRoot(items[:k]), merkleRoot(items[k:]))
+}
+
+// splitPoint returns the largest power of two smaller than n.
+func splitPoint(n int) int {
+	k := 1 << (bits.Len(uint(n)) - 1)
+	if k == n {
+		k >>= 1
+	}
+	return k
+}
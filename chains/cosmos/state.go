@@ -0,0 +1,169 @@
+package cosmos
+
+import (
+	"bytes"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/mapprotocol/atlas/tools"
+)
+
+// TrustedState is the last header verified by the light client, together with the
+// validator set trusted to sign the blocks following it.
+type TrustedState struct {
+	Height         uint64
+	Hash           common.Hash
+	Time           Timestamp
+	NextValidators []*Validator
+}
+
+// validateBasic checks that the parts of the light block belong together: the commit
+// signs the header and the validator sets are the ones the header commits to.
+func (b *LightBlock) validateBasic(chainID string) error {
+	if b == nil || b.Header == nil || b.Commit == nil {
+		return errInvalidCommit
+	}
+	header := b.Header
+	if header.ChainID != chainID {
+		return errChainIDMismatch
+	}
+	if header.Height == 0 {
+		return errInvalidNumber
+	}
+	if _, ok := totalVotingPower(b.Validators); !ok || !bytes.Equal(header.ValidatorsHash, validatorsHash(b.Validators).Bytes()) {
+		return errInvalidValidators
+	}
+	if _, ok := totalVotingPower(b.NextValidators); !ok || !bytes.Equal(header.NextValidatorsHash, validatorsHash(b.NextValidators).Bytes()) {
+		return errInvalidNextVals
+	}
+	commit := b.Commit
+	if commit.Height != header.Height || !bytes.Equal(commit.BlockID.Hash, header.Hash().Bytes()) {
+		return errInvalidCommit
+	}
+	if len(commit.Signatures) != len(b.Validators) {
+		return errInvalidCommit
+	}
+	return nil
+}
+
+// apply verifies a light block against the trusted state and trusts it in turn. An
+// adjacent block must be signed by the validators trusted for it, a later one must
+// be signed by more than a third of the trusted voting power, and in both cases by
+// more than two thirds of its own validators. The trusted header must not be older
+// than the trusting period at now, the atlas block time.
+func (s *TrustedState) apply(block *LightBlock, config *Config, now uint64) error {
+	if now == 0 {
+		return errMissingClock
+	}
+	if err := block.validateBasic(config.ChainID); err != nil {
+		return err
+	}
+	header := block.Header
+	if header.Height <= s.Height {
+		return errInvalidNumber
+	}
+	if !header.Time.after(s.Time) {
+		return errOlderBlockTime
+	}
+	if header.Time.Seconds > now+config.MaxClockDrift {
+		return errFutureBlock
+	}
+	if s.Time.Seconds+config.TrustingPeriod <= now {
+		return errTrustExpired
+	}
+
+	verified := make(map[int]bool)
+	if header.Height == s.Height+1 {
+		if !bytes.Equal(header.ValidatorsHash, validatorsHash(s.NextValidators).Bytes()) {
+			return errUntrustedValidators
+		}
+	} else if err := verifyCommitTrusting(config.ChainID, s.NextValidators, block.Commit, verified); err != nil {
+		return err
+	}
+	if err := verifyCommitLight(config.ChainID, block.Validators, block.Commit, verified); err != nil {
+		return err
+	}
+
+	s.Height = header.Height
+	s.Hash = header.Hash()
+	s.Time = header.Time
+	s.NextValidators = block.NextValidators
+	return nil
+}
+
+// verifyCommitLight checks that more than two thirds of the voting power of vals, the
+// validator set of the commit, signed it.
+func verifyCommitLight(chainID string, vals []*Validator, commit *Commit, verified map[int]bool) error {
+	total, _ := totalVotingPower(vals)
+	needed := total * 2 / 3
+
+	var tally uint64
+	for idx, sig := range commit.Signatures {
+		if sig == nil || sig.BlockIDFlag != BlockIDFlagCommit {
+			continue
+		}
+		val := vals[idx]
+		if !bytes.Equal(sig.ValidatorAddress, val.Address().Bytes()) {
+			return errInvalidSignature
+		}
+		if err := verifySignature(chainID, val, commit, idx, verified); err != nil {
+			return err
+		}
+		if tally += val.VotingPower; tally > needed {
+			return nil
+		}
+	}
+	return errNotEnoughPower
+}
+
+// verifyCommitTrusting checks that more than the trust level of the voting power of
+// vals, a validator set the commit is not known to belong to, signed it.
+func verifyCommitTrusting(chainID string, vals []*Validator, commit *Commit, verified map[int]bool) error {
+	total, _ := totalVotingPower(vals)
+	needed := total * trustLevelNumerator / trustLevelDenominator
+
+	index := make(map[common.Address]int, len(vals))
+	for i, val := range vals {
+		index[val.Address()] = i
+	}
+	var (
+		tally uint64
+		seen  = make(map[int]bool)
+	)
+	for idx, sig := range commit.Signatures {
+		if sig == nil || sig.BlockIDFlag != BlockIDFlagCommit || len(sig.ValidatorAddress) != common.AddressLength {
+			continue
+		}
+		i, ok := index[common.BytesToAddress(sig.ValidatorAddress)]
+		if !ok {
+			continue
+		}
+		if seen[i] {
+			return errDoubleVote
+		}
+		seen[i] = true
+
+		val := vals[i]
+		if err := verifySignature(chainID, val, commit, idx, verified); err != nil {
+			return err
+		}
+		if tally += val.VotingPower; tally > needed {
+			return nil
+		}
+	}
+	return errNotEnoughPower
+}
+
+// verifySignature checks the commit signature at idx with the ed25519 key of val.
+// Signatures are checked once per light block, the validator address of the
+// signature binds it to a single key.
+func verifySignature(chainID string, val *Validator, commit *Commit, idx int, verified map[int]bool) error {
+	if verified[idx] {
+		return nil
+	}
+	if !tools.VerifyEd25519(val.PubKey, commit.voteSignBytes(chainID, idx), commit.Signatures[idx].Signature) {
+		return errInvalidSignature
+	}
+	verified[idx] = true
+	return nil
+}
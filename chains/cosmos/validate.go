@@ -0,0 +1,77 @@
+package cosmos
+
+import (
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
+
+	"github.com/mapprotocol/atlas/chains"
+	"github.com/mapprotocol/atlas/core/types"
+)
+
+type Validate struct {
+	now uint64
+}
+
+// SetBlockTime sets the atlas block time the age of the trusted header is checked
+// against, it implements chains.IClockConfigurable.
+func (v *Validate) SetBlockTime(time uint64) {
+	v.now = time
+}
+
+// ValidateHeaderChain checks that each light block is trusted from the one before it,
+// starting from the light client head. It returns the index of the first invalid block.
+func (v *Validate) ValidateHeaderChain(db types.StateDB, input []byte, chainType chains.ChainType) (int, error) {
+	var blocks []*LightBlock
+	if err := rlp.DecodeBytes(input, &blocks); err != nil {
+		log.Error("rlp decode cosmos light blocks failed", "err", err)
+		return 0, chains.ErrRLPDecode
+	}
+	if len(blocks) == 0 {
+		return 0, chains.ErrEmptyHeaders
+	}
+
+	hs := NewHeaderStore()
+	if err := hs.Load(db); err != nil {
+		return 0, err
+	}
+	if chains.ChainType(hs.ChainID) != chainType {
+		return 0, chains.ErrNotSupportChain
+	}
+	config, err := hs.config()
+	if err != nil {
+		return 0, err
+	}
+
+	// the loaded store is a private copy, so the trusted state can be advanced freely
+	for i, block := range blocks {
+		if err := hs.State.apply(block, config, v.now); err != nil {
+			if block != nil && block.Header != nil {
+				log.Warn("invalid cosmos light block", "height", block.Header.Height, "err", err)
+			}
+			return i, err
+		}
+	}
+	return 0, nil
+}
+
+// EstimateWork returns the work of validating and inserting the light blocks, every
+// commit signature may be verified and a store entry is written per block besides
+// the store itself.
+func (v *Validate) EstimateWork(_ types.StateDB, input []byte) (*chains.Work, error) {
+	var blocks []*LightBlock
+	if err := rlp.DecodeBytes(input, &blocks); err != nil {
+		return nil, err
+	}
+	work := &chains.Work{Headers: uint64(len(blocks)), StateWrites: uint64(len(blocks)) + 1}
+	for _, block := range blocks {
+		if block == nil || block.Commit == nil {
+			continue
+		}
+		for _, sig := range block.Commit.Signatures {
+			if sig != nil && sig.BlockIDFlag == BlockIDFlagCommit {
+				work.Signatures++
+			}
+		}
+	}
+	return work, nil
+}
@@ -0,0 +1,86 @@
+package cosmos
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
+
+	"github.com/mapprotocol/atlas/core/types"
+)
+
+// TxProve proves a value stored by the bridge module of a Cosmos chain. The module
+// stores the events of a cross-chain transfer as rlp encoded logs, Value, under Key
+// of its store StoreKey. The app hash of the header at Height commits to the state
+// after the block before it.
+type TxProve struct {
+	Height     uint64
+	StoreKey   []byte
+	Key        []byte
+	Value      []byte
+	StoreProof *ExistenceProof // Key and Value in the IAVL tree of the store
+	RootProof  *ExistenceProof // StoreKey and the store root in the multistore
+}
+
+type Verify struct {
+}
+
+func (v *Verify) Verify(db types.StateDB, routerContractAddr common.Address, txProveBytes []byte) (logs []byte, err error) {
+	txProve, err := v.decode(txProveBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	appHash, err := v.getAppHash(db, txProve.Height)
+	if err != nil {
+		return nil, err
+	}
+	if err := v.verifyProof(appHash, txProve); err != nil {
+		return nil, err
+	}
+	return txProve.Value, nil
+}
+
+func (v *Verify) decode(txProveBytes []byte) (*TxProve, error) {
+	var txProve TxProve
+	if err := rlp.DecodeBytes(txProveBytes, &txProve); err != nil {
+		return nil, err
+	}
+	if len(txProve.StoreKey) == 0 || len(txProve.Key) == 0 {
+		return nil, errors.New("proven key cannot be empty")
+	}
+	var logs []*ethtypes.Log
+	if err := rlp.DecodeBytes(txProve.Value, &logs); err != nil {
+		return nil, fmt.Errorf("proven value is not a list of logs: %w", err)
+	}
+	return &txProve, nil
+}
+
+// getAppHash returns the app hash of a stored header, CometBFT blocks are final once
+// committed so no confirmations are needed.
+func (v *Verify) getAppHash(db types.StateDB, height uint64) ([]byte, error) {
+	hs := NewHeaderStore()
+	if err := hs.Load(db); err != nil {
+		return nil, err
+	}
+	header, err := hs.LoadHeader(db, height)
+	if err != nil {
+		return nil, err
+	}
+	if header == nil {
+		return nil, fmt.Errorf("%w, height: %d", errUnknownBlock, height)
+	}
+	return header.AppHash, nil
+}
+
+// verifyProof checks the value in the store and the store in the multistore, whose
+// root is the app hash.
+func (v *Verify) verifyProof(appHash []byte, txProve *TxProve) error {
+	storeRoot, err := txProve.StoreProof.prove(IavlSpec, txProve.Key, txProve.Value)
+	if err != nil {
+		return err
+	}
+	return txProve.RootProof.verifyMembership(TendermintSpec, appHash, txProve.StoreKey, storeRoot)
+}
@@ -0,0 +1,172 @@
+package cosmos
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mapprotocol/atlas/chains/chainstest"
+)
+
+func sha(data ...[]byte) []byte {
+	h := sha256.New()
+	for _, d := range data {
+		h.Write(d)
+	}
+	return h.Sum(nil)
+}
+
+func varint(v int64) []byte {
+	return binary.AppendVarint(nil, v)
+}
+
+func lengthPrefixed(data []byte) []byte {
+	return append(binary.AppendUvarint(nil, uint64(len(data))), data...)
+}
+
+// iavlProof returns the root of a two leaves IAVL tree and the proof of the leaf at
+// idx.
+func iavlProof(keys, values [][]byte, idx int) ([]byte, *ExistenceProof) {
+	var leaves [2][]byte
+	for i := range leaves {
+		prefix := append(append(varint(0), varint(1)...), varint(1)...)
+		leaves[i] = sha(prefix, lengthPrefixed(keys[i]), lengthPrefixed(sha(values[i])))
+	}
+	node := append(append(varint(1), varint(2)...), varint(1)...)
+	root := sha(node, lengthPrefixed(leaves[0]), lengthPrefixed(leaves[1]))
+
+	inner := &InnerOp{Hash: HashOpSHA256}
+	if idx == 0 {
+		inner.Prefix = append(node, 32)
+		inner.Suffix = lengthPrefixed(leaves[1])
+	} else {
+		inner.Prefix = append(append(node, lengthPrefixed(leaves[0])...), 32)
+	}
+	leaf := *IavlSpec.LeafSpec
+	leaf.Prefix = append(append(varint(0), varint(1)...), varint(1)...)
+	return root, &ExistenceProof{Key: keys[idx], Value: values[idx], Leaf: &leaf, Path: []*InnerOp{inner}}
+}
+
+// multistoreProof returns the app hash of a multistore with the given store roots and
+// the proof of the store at idx, the leaves are hashed as by merkleRoot.
+func multistoreProof(names, roots [][]byte, idx int) ([]byte, *ExistenceProof) {
+	items := make([][]byte, len(names))
+	for i := range names {
+		items[i] = append(lengthPrefixed(names[i]), lengthPrefixed(sha(roots[i]))...)
+	}
+	proof := &ExistenceProof{Key: names[idx], Value: roots[idx], Leaf: TendermintSpec.LeafSpec}
+
+	// walk down the tree collecting the siblings, the path lists them from the leaf up
+	lo, hi := 0, len(items)
+	for hi-lo > 1 {
+		k := lo + splitPoint(hi-lo)
+		if idx < k {
+			op := &InnerOp{Hash: HashOpSHA256, Prefix: innerPrefix, Suffix: merkleRoot(items[k:hi])}
+			proof.Path = append([]*InnerOp{op}, proof.Path...)
+			hi = k
+		} else {
+			op := &InnerOp{Hash: HashOpSHA256, Prefix: append(append([]byte{}, innerPrefix...), merkleRoot(items[lo:k])...)}
+			proof.Path = append([]*InnerOp{op}, proof.Path...)
+			lo = k
+		}
+	}
+	return merkleRoot(items), proof
+}
+
+func testLogs(t *testing.T) []byte {
+	logs := []*ethtypes.Log{{Address: common.Address{1}, Topics: []common.Hash{{2}}, Data: []byte("transfer")}}
+	data, err := rlp.EncodeToBytes(logs)
+	require.NoError(t, err)
+	return data
+}
+
+func TestExistenceProof(t *testing.T) {
+	var (
+		keys   = [][]byte{[]byte("a"), []byte("b")}
+		values = [][]byte{[]byte("value a"), []byte("value b")}
+		names  = [][]byte{[]byte("acc"), []byte("bank"), []byte("bridge"), []byte("ibc"), []byte("staking")}
+		roots  = [][]byte{sha([]byte("0")), sha([]byte("1")), nil, sha([]byte("3")), sha([]byte("4"))}
+	)
+	for idx := range keys {
+		root, proof := iavlProof(keys, values, idx)
+		assert.NoError(t, proof.verifyMembership(IavlSpec, root, keys[idx], values[idx]))
+		assert.Error(t, proof.verifyMembership(IavlSpec, root, keys[idx], values[1-idx]))
+		// the spec of the multistore does not accept IAVL nodes
+		assert.Error(t, proof.verifyMembership(TendermintSpec, root, keys[idx], values[idx]))
+		roots[2] = root
+	}
+	for idx := range names {
+		appHash, proof := multistoreProof(names, roots, idx)
+		assert.NoError(t, proof.verifyMembership(TendermintSpec, appHash, names[idx], roots[idx]))
+		assert.Error(t, proof.verifyMembership(TendermintSpec, sha(appHash), names[idx], roots[idx]))
+	}
+
+	// an inner node can not be presented as a leaf
+	root, proof := iavlProof(keys, values, 0)
+	proof.Leaf.Prefix = proof.Path[0].Prefix
+	assert.True(t, errors.Is(proof.verifyMembership(IavlSpec, root, keys[0], values[0]), errInvalidProof))
+}
+
+func TestVerify(t *testing.T) {
+	var (
+		set     = newValidatorSet("set", 10, 10, 10)
+		logs    = testLogs(t)
+		keys    = [][]byte{[]byte("transfer/1"), []byte("transfer/2")}
+		values  = [][]byte{[]byte("other"), logs}
+		names   = [][]byte{[]byte("acc"), []byte("bridge"), []byte("ibc")}
+		roots   = [][]byte{sha([]byte("0")), nil, sha([]byte("2"))}
+		genesis = makeBlock(1, set, set)
+	)
+	storeRoot, storeProof := iavlProof(keys, values, 1)
+	roots[1] = storeRoot
+	appHash, rootProof := multistoreProof(names, roots, 1)
+
+	block := makeBlock(2, set, set)
+	block.Header.AppHash = appHash
+	block.Commit = makeCommit(block.Header, set)
+
+	db := chainstest.NewStore(t, NewHeaderStore(), &resetInput{ChainID: uint64(testChainType), Block: genesis})
+	hs := NewHeaderStore()
+	hs.SetBlockTime(now(2))
+	_, err := hs.InsertHeaders(db, chainstest.EncodeRLP([]*LightBlock{block}))
+	require.NoError(t, err)
+
+	prove := func(p *TxProve) []byte {
+		data, err := rlp.EncodeToBytes(p)
+		require.NoError(t, err)
+		return data
+	}
+	txProve := &TxProve{
+		Height:     2,
+		StoreKey:   names[1],
+		Key:        keys[1],
+		Value:      logs,
+		StoreProof: storeProof,
+		RootProof:  rootProof,
+	}
+	got, err := new(Verify).Verify(db, common.Address{}, prove(txProve))
+	assert.NoError(t, err)
+	assert.Equal(t, logs, got)
+
+	unknown := *txProve
+	unknown.Height = 3
+	_, err = new(Verify).Verify(db, common.Address{}, prove(&unknown))
+	assert.True(t, errors.Is(err, errUnknownBlock))
+
+	otherStore := *txProve
+	otherStore.StoreKey = names[0]
+	_, err = new(Verify).Verify(db, common.Address{}, prove(&otherStore))
+	assert.True(t, errors.Is(err, errInvalidProof))
+
+	stale := *txProve
+	stale.Height = 1
+	_, err = new(Verify).Verify(db, common.Address{}, prove(&stale))
+	assert.True(t, errors.Is(err, errInvalidProof))
+}
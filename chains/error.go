@@ -5,4 +5,5 @@ import "errors"
 var (
 	ErrNotSupportChain = errors.New("not supported chain")
 	ErrRLPDecode       = errors.New("rlp decode error")
+	ErrEmptyHeaders    = errors.New("headers cannot be empty")
 )
@@ -0,0 +1,15 @@
+package eth2
+
+import "errors"
+
+var (
+	errNotInitialized       = errors.New("please initialize header store")
+	errStaleUpdate          = errors.New("finalized header is not newer than the stored one")
+	errInvalidUpdatePeriod  = errors.New("update skips a sync committee period")
+	errUnknownNextCommittee = errors.New("next sync committee is unknown")
+	errMissingExecution     = errors.New("finalized execution payload is missing")
+	errMissingBlobGas       = errors.New("execution payload has blob gas used but no excess blob gas")
+	errUnsupportedFork      = errors.New("unsupported fork")
+	errPayloadLayout        = errors.New("execution payload layout does not match the fork")
+	errShortInput           = errors.New("abi input is too short")
+)
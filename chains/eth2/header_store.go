@@ -0,0 +1,236 @@
+package eth2
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
+
+	"github.com/mapprotocol/atlas/chains"
+	"github.com/mapprotocol/atlas/core/types"
+	"github.com/mapprotocol/atlas/params"
+)
+
+const (
+	MaxFinalizedLimit = 100000
+)
+
+var stateStore = chains.NewStateStore(chains.Eth2HeaderStoreAddress)
+
+// HeaderStore keeps the state of the beacon chain light client: the latest
+// finalized beacon header, the sync committees that sign its successors and
+// the execution block it finalizes.
+type HeaderStore struct {
+	ChainID              uint64
+	FinalizedHeader      *BeaconBlockHeader
+	CurrentSyncCommittee *SyncCommittee
+	NextSyncCommittee    *SyncCommittee
+	CurNumber            uint64
+	CurHash              common.Hash
+
+	chainConfig *params.ChainConfig // not persisted, see SetChainConfig
+}
+
+// FinalizedBlock is the execution block committed by a finalized beacon header.
+type FinalizedBlock struct {
+	Slot         uint64
+	BeaconRoot   common.Hash
+	Number       uint64
+	Hash         common.Hash
+	ReceiptsRoot common.Hash
+}
+
+func NewHeaderStore() *HeaderStore {
+	return &HeaderStore{}
+}
+
+func finalizedBlockDbKey(number uint64) common.Hash {
+	str := fmt.Sprintf("%s-%d", "eth2finalized", number%MaxFinalizedLimit)
+	return common.BytesToHash([]byte(str))
+}
+
+// SetChainConfig sets the atlas chain config the network configuration of the followed
+// beacon chain is read from.
+func (hs *HeaderStore) SetChainConfig(config *params.ChainConfig) {
+	hs.chainConfig = config
+}
+
+// networkConfig returns the configuration of the followed beacon chain network.
+func (hs *HeaderStore) networkConfig() (*NetworkConfig, error) {
+	return loadNetworkConfig(hs.chainConfig, hs.ChainID)
+}
+
+func (hs *HeaderStore) state() *LightClientState {
+	return &LightClientState{
+		finalizedHeader:      hs.FinalizedHeader,
+		currentSyncCommittee: hs.CurrentSyncCommittee,
+		nextSyncCommittee:    hs.NextSyncCommittee,
+		chainID:              hs.ChainID,
+	}
+}
+
+// ResetHeaderStore initializes the light client with a trusted state, encoded as the
+// (finalizedHeader, currentSyncCommittee, nextSyncCommittee, chainID) abi tuple.
+func (hs *HeaderStore) ResetHeaderStore(db types.StateDB, input []byte, td *big.Int) error {
+	state, err := decodeLightClientState(input)
+	if err != nil {
+		log.Error("decode eth2 light client state failed", "err", err)
+		return err
+	}
+	if _, err := loadNetworkConfig(hs.chainConfig, state.chainID); err != nil {
+		return err
+	}
+
+	h := &HeaderStore{
+		ChainID:              state.chainID,
+		FinalizedHeader:      state.finalizedHeader,
+		CurrentSyncCommittee: state.currentSyncCommittee,
+		NextSyncCommittee:    state.nextSyncCommittee,
+	}
+	return h.Store(db)
+}
+
+func (hs *HeaderStore) Store(db types.StateDB) error {
+	return stateStore.Store(db, hs)
+}
+
+func (hs *HeaderStore) Load(db types.StateDB) error {
+	config := hs.chainConfig
+	ok, err := stateStore.Load(db, hs)
+	hs.chainConfig = config
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errNotInitialized
+	}
+	return nil
+}
+
+func (hs *HeaderStore) StoreFinalizedBlock(db types.StateDB, block *FinalizedBlock) error {
+	data, err := rlp.EncodeToBytes(block)
+	if err != nil {
+		log.Error("Failed to RLP encode FinalizedBlock", "err", err)
+		return err
+	}
+	db.SetPOWState(chains.Eth2HeaderStoreAddress, finalizedBlockDbKey(block.Number), data)
+	return nil
+}
+
+// LoadFinalizedBlock returns the finalized execution block with the given number, or
+// nil if it was never finalized through this store or has been overwritten since.
+func (hs *HeaderStore) LoadFinalizedBlock(db types.StateDB, number uint64) (*FinalizedBlock, error) {
+	data := db.GetPOWState(chains.Eth2HeaderStoreAddress, finalizedBlockDbKey(number))
+	if len(data) == 0 {
+		return nil, nil
+	}
+
+	var block FinalizedBlock
+	if err := rlp.DecodeBytes(data, &block); err != nil {
+		return nil, fmt.Errorf("FinalizedBlock RLP decode failed, error: %s", err.Error())
+	}
+	if block.Number != number {
+		return nil, nil
+	}
+	return &block, nil
+}
+
+// InsertHeaders advances the store with an already validated light client update.
+func (hs *HeaderStore) InsertHeaders(db types.StateDB, input []byte) ([]*params.NumberHash, error) {
+	if err := hs.Load(db); err != nil {
+		return nil, err
+	}
+	config, err := hs.networkConfig()
+	if err != nil {
+		return nil, err
+	}
+	update, err := decodeLightClientUpdate(config, input)
+	if err != nil {
+		log.Error("decode eth2 light client update failed", "err", err)
+		return nil, err
+	}
+	if update.finalizedExecution == nil || update.finalizedExecution.BlockNumber == nil {
+		return nil, errMissingExecution
+	}
+
+	finalizedPeriod := computeSyncCommitteePeriod(hs.FinalizedHeader.Slot)
+	updatePeriod := computeSyncCommitteePeriod(update.finalizedHeader.Slot)
+	if updatePeriod == finalizedPeriod+1 {
+		hs.CurrentSyncCommittee = hs.NextSyncCommittee
+		hs.NextSyncCommittee = update.nextSyncCommittee
+	}
+
+	root, err := update.finalizedHeader.HashTreeRoot()
+	if err != nil {
+		return nil, err
+	}
+	execution := update.finalizedExecution
+	block := &FinalizedBlock{
+		Slot:         update.finalizedHeader.Slot,
+		BeaconRoot:   root,
+		Number:       execution.BlockNumber.Uint64(),
+		Hash:         execution.BlockHash,
+		ReceiptsRoot: execution.ReceiptsRoot,
+	}
+	if err := hs.StoreFinalizedBlock(db, block); err != nil {
+		return nil, err
+	}
+
+	hs.FinalizedHeader = update.finalizedHeader
+	hs.CurNumber, hs.CurHash = block.Number, block.Hash
+	if err := hs.Store(db); err != nil {
+		return nil, err
+	}
+
+	log.Info("stored new eth2 finalized header", "slot", block.Slot, "number", block.Number, "hash", block.Hash)
+	return []*params.NumberHash{{Number: block.Number, Hash: block.Hash}}, nil
+}
+
+// FinalizedPeriod returns the sync committee period of the finalized header.
+func (hs *HeaderStore) FinalizedPeriod() uint64 {
+	return computeSyncCommitteePeriod(hs.FinalizedHeader.Slot)
+}
+
+func (hs *HeaderStore) GetCurrentNumberAndHash(db types.StateDB) (uint64, common.Hash, error) {
+	if err := hs.Load(db); err != nil {
+		return 0, common.Hash{}, err
+	}
+	return hs.CurNumber, hs.CurHash, nil
+}
+
+func (hs *HeaderStore) GetHashByNumber(db types.StateDB, number uint64) (common.Hash, error) {
+	if err := hs.Load(db); err != nil {
+		return common.Hash{}, err
+	}
+	block, err := hs.LoadFinalizedBlock(db, number)
+	if err != nil || block == nil {
+		return common.Hash{}, err
+	}
+	return block.Hash, nil
+}
+
+// DecodeHeaders decodes the finalized execution block of an updateBlockHeader input
+// for node-local indexes, it implements chains.IHeaderDecoder. The update carries no
+// execution header, so the indexed block has no raw header.
+func (hs *HeaderStore) DecodeHeaders(input []byte, chainType chains.ChainType) ([]*chains.IndexedHeader, error) {
+	config, err := loadNetworkConfig(hs.chainConfig, uint64(chainType))
+	if err != nil {
+		return nil, err
+	}
+	update, err := decodeLightClientUpdate(config, input)
+	if err != nil {
+		return nil, err
+	}
+	execution := update.finalizedExecution
+	if execution == nil || execution.BlockNumber == nil {
+		return nil, errMissingExecution
+	}
+	return []*chains.IndexedHeader{{
+		Number:      execution.BlockNumber.Uint64(),
+		Hash:        execution.BlockHash,
+		ParentHash:  execution.ParentHash,
+		ReceiptHash: execution.ReceiptsRoot,
+	}}, nil
+}
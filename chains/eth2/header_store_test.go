@@ -0,0 +1,87 @@
+package eth2
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mapprotocol/atlas/core/rawdb"
+	atlasstate "github.com/mapprotocol/atlas/core/state"
+)
+
+func getStateDB() *atlasstate.StateDB {
+	finalDb := rawdb.NewMemoryDatabase()
+	finalState, _ := atlasstate.New(common.Hash{}, atlasstate.NewDatabase(finalDb), nil)
+	return finalState
+}
+
+// splitInput splits the stateless verify INPUT into the store reset input and the update.
+// The update abi names the attested header "finalizedHeader" as well, which packing
+// resolves by name, so the input is re-encoded with a distinct name for it.
+func splitInput(t *testing.T) (reset []byte, update []byte) {
+	data, err := hexutil.Decode(INPUT)
+	assert.Nil(t, err)
+
+	args, err := genAbiArgs(false)
+	assert.Nil(t, err)
+	var updateArg abi.Argument
+	err = updateArg.UnmarshalJSON([]byte(strings.Replace(UpdateABIJSON, "finalizedHeader", "attestedHeader", 1)))
+	assert.Nil(t, err)
+	args[0] = updateArg
+
+	ret, err := args.Unpack(data)
+	assert.Nil(t, err)
+
+	update, err = args[:1].Pack(ret[0])
+	assert.Nil(t, err)
+	reset, err = args[1:].Pack(ret[1:]...)
+	assert.Nil(t, err)
+	return reset, update
+}
+
+func TestHeaderStore_InsertHeaders(t *testing.T) {
+	db := getStateDB()
+	resetInput, updateInput := splitInput(t)
+
+	hs := NewHeaderStore()
+	assert.Nil(t, hs.ResetHeaderStore(db, resetInput, nil))
+
+	number, hash, err := hs.GetCurrentNumberAndHash(db)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(0), number)
+	assert.Equal(t, common.Hash{}, hash)
+
+	v := new(Validate)
+	_, err = v.ValidateHeaderChain(db, updateInput, 0)
+	assert.Nil(t, err)
+
+	nums, err := hs.InsertHeaders(db, updateInput)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(nums))
+	assert.Equal(t, update.finalizedExecution.BlockNumber.Uint64(), nums[0].Number)
+	assert.Equal(t, update.finalizedExecution.BlockHash, nums[0].Hash)
+
+	number, hash, err = hs.GetCurrentNumberAndHash(db)
+	assert.Nil(t, err)
+	assert.Equal(t, nums[0].Number, number)
+	assert.Equal(t, nums[0].Hash, hash)
+
+	got, err := hs.GetHashByNumber(db, number)
+	assert.Nil(t, err)
+	assert.Equal(t, hash, got)
+
+	// the same update can not be applied twice
+	_, err = v.ValidateHeaderChain(db, updateInput, 0)
+	assert.Equal(t, errStaleUpdate, err)
+}
+
+func TestHeaderStore_NotInitialized(t *testing.T) {
+	_, updateInput := splitInput(t)
+
+	_, err := new(Validate).ValidateHeaderChain(getStateDB(), updateInput, 0)
+	assert.Equal(t, errNotInitialized, err)
+}
@@ -4,16 +4,23 @@ import (
 	"fmt"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/mapprotocol/atlas/chains/eth2/bls12381"
+	"github.com/mapprotocol/atlas/params"
 	ssz "github.com/prysmaticlabs/fastssz"
+	"math/big"
 )
 
 const MinSyncCommitteeParticipants uint64 = 1
 const EpochsPerSyncCommitteePeriod uint64 = 256
 const SlotsPerEpoch uint64 = 32
+const SyncCommitteeSize uint64 = 512
 
 const FinalizedRootIndex uint32 = 105
 const NextSyncCommitteeIndex uint32 = 55
 
+// The beacon state grows past 32 fields in electra, which adds a level to its tree
+const FinalizedRootIndexElectra uint32 = 169
+const NextSyncCommitteeIndexElectra uint32 = 87
+
 const BeaconBlockBodyTreeExecutionPayloadIndex uint64 = 25
 const ExecutionPayloadProofSize int = 4
 
@@ -25,15 +32,19 @@ const ExecutionProofSize = L1BeaconBlockBodyProofSize + L2ExecutionPayloadProofS
 
 var DomainSyncCommittee = [4]byte{0x07, 0x00, 0x00, 0x00}
 
-func VerifyLightClientUpdate(input []byte) error {
-	verify, err := decodeLightClientVerifyV2(input)
+// VerifyLightClientUpdate verifies an abi encoded light client update against the
+// light client state it carries. From the Eth2Deneb fork at the atlas block number,
+// deneb and electra updates are accepted and networks listed in the chain config
+// replace the built-in ones.
+func VerifyLightClientUpdate(chainConfig *params.ChainConfig, number *big.Int, input []byte) error {
+	network := updateNetworks(chainConfig, number)
+	verify, err := decodeLightClientVerify(network, input)
 	if err != nil {
-		log.Warn("decodeLightClientVerifyV2", "error", err)
-		verify, err = decodeLightClientVerifyV1(input)
-		if err != nil {
-			log.Warn("decodeLightClientVerifyV1", "error", err)
-			return err
-		}
+		return err
+	}
+	config, err := network(verify.state.chainID)
+	if err != nil {
+		return fmt.Errorf("new network failed: %v", err)
 	}
 
 	switch verify.update.(type) {
@@ -43,7 +54,14 @@ func VerifyLightClientUpdate(input []byte) error {
 			return err
 		}
 	case *LightClientUpdateV2:
-		if err := verifyFinalityV2(verify.update.(*LightClientUpdateV2)); err != nil {
+		update := verify.update.(*LightClientUpdateV2)
+		if chainConfig != nil && chainConfig.IsEth2Deneb(number) {
+			if err := checkPayloadLayout(config, update.finalizedHeader.Slot, update.finalizedExecution); err != nil {
+				log.Warn("checkPayloadLayout", "error", err)
+				return err
+			}
+		}
+		if err := verifyFinalityV2(config, update); err != nil {
 			log.Warn("verifyFinalityV2", "error", err)
 			return err
 		}
@@ -53,12 +71,12 @@ func VerifyLightClientUpdate(input []byte) error {
 		return fmt.Errorf("invalid light client update type")
 	}
 
-	if err := verifyNextSyncCommittee(verify.state, verify.update); err != nil {
+	if err := verifyNextSyncCommittee(config, verify.state, verify.update); err != nil {
 		log.Warn("verifyNextSyncCommittee", "error", err)
 		return err
 	}
 
-	if err := verifyBlsSignatures(verify.state, verify.update); err != nil {
+	if err := verifyBlsSignatures(config, verify.state, verify.update); err != nil {
 		log.Warn("verifyBlsSignatures", "error", err)
 		return err
 	}
@@ -66,13 +84,13 @@ func VerifyLightClientUpdate(input []byte) error {
 	return nil
 }
 
-func verifyFinalityV2(update *LightClientUpdateV2) error {
+func verifyFinalityV2(config *NetworkConfig, update *LightClientUpdateV2) error {
 	leaf, err := update.finalizedHeader.HashTreeRoot()
 	if err != nil {
 		return fmt.Errorf("failed to compute hash tree root of finalized header: %v", err)
 	}
 	proof := ssz.Proof{
-		Index:  int(FinalizedRootIndex),
+		Index:  int(config.finalizedRootIndex(update.attestedHeader.Slot)),
 		Leaf:   leaf[:],
 		Hashes: update.finalityBranch,
 	}
@@ -111,6 +129,22 @@ func verifyFinalityV2(update *LightClientUpdateV2) error {
 	return nil
 }
 
+// checkPayloadLayout checks that the execution payload finalized at the slot has the
+// fields of the fork active at the slot.
+func checkPayloadLayout(config *NetworkConfig, slot uint64, payload *ExecutionPayload) error {
+	if payload == nil {
+		return errMissingExecution
+	}
+	fork := config.forkAtSlot(slot)
+	if fork == nil {
+		return errUnsupportedFork
+	}
+	if fork.isAtLeast(ForkDeneb) != (payload.BlobGasUsed != nil) {
+		return fmt.Errorf("%w, fork: %s", errPayloadLayout, fork.Name)
+	}
+	return nil
+}
+
 func verifyFinalityV1(update *LightClientUpdateV1) error {
 	leaf, err := update.finalizedHeader.HashTreeRoot()
 	if err != nil {
@@ -160,7 +194,7 @@ func verifyFinalityV1(update *LightClientUpdateV1) error {
 	return nil
 }
 
-func verifyNextSyncCommittee(state *LightClientState, update ILightClientUpdate) error {
+func verifyNextSyncCommittee(config *NetworkConfig, state *LightClientState, update ILightClientUpdate) error {
 	// The active header will always be the finalized header because we don't accept updates without the finality update.
 	updatePeriod := computeSyncCommitteePeriod(update.GetFinalizedHeader().Slot)
 	finalizedPeriod := computeSyncCommitteePeriod(state.finalizedHeader.Slot)
@@ -173,7 +207,7 @@ func verifyNextSyncCommittee(state *LightClientState, update ILightClientUpdate)
 			return fmt.Errorf("failed to compute hash tree root of finalized header: %v", err)
 		}
 		proof := ssz.Proof{
-			Index:  int(NextSyncCommitteeIndex),
+			Index:  int(config.nextSyncCommitteeIndex(update.GetAttestedHeader().Slot)),
 			Leaf:   leaf[:],
 			Hashes: update.GetNextSyncCommitteeBranch(),
 		}
@@ -190,7 +224,7 @@ func verifyNextSyncCommittee(state *LightClientState, update ILightClientUpdate)
 	return nil
 }
 
-func verifyBlsSignatures(state *LightClientState, update ILightClientUpdate) error {
+func verifyBlsSignatures(config *NetworkConfig, state *LightClientState, update ILightClientUpdate) error {
 	syncCommitteeCount := update.GetSyncAggregate().SyncCommitteeBits.Count()
 	if syncCommitteeCount < MinSyncCommitteeParticipants {
 		return fmt.Errorf("invalid sync committee participants count, min required %d, got %d", MinSyncCommitteeParticipants, syncCommitteeCount)
@@ -201,10 +235,6 @@ func verifyBlsSignatures(state *LightClientState, update ILightClientUpdate) err
 	}
 
 	finalizedPeriod := computeSyncCommitteePeriod(state.finalizedHeader.Slot)
-	config, err := newNetworkConfig(state.chainID)
-	if err != nil {
-		return fmt.Errorf("new network failed: %v", err)
-	}
 
 	signaturePeriod := computeSyncCommitteePeriod(update.GetSignatureSlot())
 	var syncCommittee *SyncCommittee
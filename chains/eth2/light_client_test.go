@@ -1648,15 +1648,19 @@ func TestVerifyNextSyncCommitteeV1(t *testing.T) {
 	data, err := hexutil.Decode(INPUTV1)
 	assert.Nil(t, err)
 
-	verify, err := decodeLightClientVerify(data)
+	verify, err := decodeLightClientVerify(newNetworkConfig, data)
 	assert.Nil(t, err)
 
-	err = verifyNextSyncCommittee(verify.state, verify.update)
+	config, err := newNetworkConfig(verify.state.chainID)
+	assert.Nil(t, err)
+	err = verifyNextSyncCommittee(config, verify.state, verify.update)
 	assert.Nil(t, err)
 }
 
 func TestVerifyBlsSignaturesV1(t *testing.T) {
-	err := verifyBlsSignatures(&stateV1, &updateV1)
+	config, err := newNetworkConfig(stateV1.chainID)
+	assert.Nil(t, err)
+	err = verifyBlsSignatures(config, &stateV1, &updateV1)
 	assert.Nil(t, err)
 }
 
@@ -1664,7 +1668,7 @@ func TestDecodeLightClientVerifyV1(t *testing.T) {
 	data, err := hexutil.Decode(INPUTV1)
 	assert.Nil(t, err)
 
-	verify, err := decodeLightClientVerify(data)
+	verify, err := decodeLightClientVerify(newNetworkConfig, data)
 	assert.Nil(t, err)
 
 	assert.Equal(t, updateV1.attestedHeader, verify.update.GetAttestedHeader())
@@ -1683,6 +1687,6 @@ func TestVerifyLightClientUpdateV1(t *testing.T) {
 	data, err := hexutil.Decode(INPUT)
 	assert.Nil(t, err)
 
-	err = VerifyLightClientUpdate(data)
+	err = VerifyLightClientUpdate(nil, nil, data)
 	assert.Nil(t, err)
 }
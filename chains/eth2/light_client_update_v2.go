@@ -82,6 +82,8 @@ type ILightNodeExecution struct {
 	BlockHash        [32]byte
 	TransactionsRoot [32]byte
 	WithdrawalsRoot  [32]byte
+	BlobGasUsed      *big.Int // deneb only
+	ExcessBlobGas    *big.Int // deneb only
 }
 
 func ConvertToLightClientVerify(update *ILightNodeLightClientUpdateV2,
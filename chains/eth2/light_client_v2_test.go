@@ -1634,7 +1634,9 @@ var state = LightClientState{
 }
 
 func TestVerifyFinality(t *testing.T) {
-	err := verifyFinalityV2(&update)
+	config, err := newNetworkConfig(state.chainID)
+	assert.Nil(t, err)
+	err = verifyFinalityV2(config, &update)
 	assert.Nil(t, err)
 }
 
@@ -1642,15 +1644,19 @@ func TestVerifyNextSyncCommittee(t *testing.T) {
 	data, err := hexutil.Decode(INPUT)
 	assert.Nil(t, err)
 
-	verify, err := decodeLightClientVerify(data)
+	verify, err := decodeLightClientVerify(newNetworkConfig, data)
 	assert.Nil(t, err)
 
-	err = verifyNextSyncCommittee(verify.state, verify.update)
+	config, err := newNetworkConfig(verify.state.chainID)
+	assert.Nil(t, err)
+	err = verifyNextSyncCommittee(config, verify.state, verify.update)
 	assert.Nil(t, err)
 }
 
 func TestVerifyBlsSignatures(t *testing.T) {
-	err := verifyBlsSignatures(&state, &update)
+	config, err := newNetworkConfig(state.chainID)
+	assert.Nil(t, err)
+	err = verifyBlsSignatures(config, &state, &update)
 	assert.Nil(t, err)
 }
 
@@ -1658,7 +1664,7 @@ func TestDecodeLightClientVerify(t *testing.T) {
 	data, err := hexutil.Decode(INPUT)
 	assert.Nil(t, err)
 
-	verify, err := decodeLightClientVerify(data)
+	verify, err := decodeLightClientVerify(newNetworkConfig, data)
 	assert.Nil(t, err)
 
 	assert.Equal(t, update.attestedHeader, verify.update.GetAttestedHeader())
@@ -1681,6 +1687,6 @@ func TestVerifyLightClientUpdate(t *testing.T) {
 	data, err := hexutil.Decode(INPUT)
 	assert.Nil(t, err)
 
-	err = VerifyLightClientUpdate(data)
+	err = VerifyLightClientUpdate(nil, nil, data)
 	assert.Nil(t, err)
 }
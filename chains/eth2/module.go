@@ -0,0 +1,21 @@
+package eth2
+
+import (
+	"math/big"
+
+	"github.com/mapprotocol/atlas/chains"
+	"github.com/mapprotocol/atlas/params"
+)
+
+func init() {
+	chains.Register(&chains.Module{
+		Group: chains.ChainGroupETH2,
+		Chains: map[chains.ChainType]*chains.ChainParams{
+			chains.ChainTypeETH: {AtlasChainID: params.MainNetChainID, LondonBlock: big.NewInt(12_965_000), Network: mainnetNetwork},
+		},
+		ForkBlock:      func(config *params.ChainConfig) *big.Int { return config.Eth2Block },
+		NewValidate:    func() chains.IValidate { return new(Validate) },
+		NewHeaderStore: func() chains.IHeaderStore { return new(HeaderStore) },
+		NewVerify:      func() chains.IVerify { return new(Verify) },
+	})
+}
@@ -1,56 +1,231 @@
 package eth2
 
-import "fmt"
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/mapprotocol/atlas/chains"
+	"github.com/mapprotocol/atlas/params"
+)
+
+// Beacon chain hard forks known to the light client, in activation order.
+const (
+	ForkBellatrix = "bellatrix"
+	ForkCapella   = "capella"
+	ForkDeneb     = "deneb"
+	ForkElectra   = "electra"
+)
+
+var forkOrder = map[string]int{
+	ForkBellatrix: 0,
+	ForkCapella:   1,
+	ForkDeneb:     2,
+	ForkElectra:   3,
+}
+
+// Fork is a beacon chain hard fork and the epoch it is activated at.
+type Fork struct {
+	Name    string
+	Version ForkVersion
+	Epoch   uint64
+}
+
+// isAtLeast reports whether the fork is the named fork or a later one.
+func (f *Fork) isAtLeast(name string) bool {
+	return forkOrder[f.Name] >= forkOrder[name]
+}
 
 type NetworkConfig struct {
 	GenesisValidatorsRoot [32]byte
-	BellatrixForkVersion  ForkVersion
-	BellatrixForkEpoch    uint64
-	CapellaForkVersion    ForkVersion
-	CapellaForkEpoch      uint64
+	Forks                 []Fork // ordered by activation epoch
+}
+
+// mainnetNetwork is the network configuration of the beacon chain of Ethereum mainnet,
+// registered for the chain in the eth2 module.
+var mainnetNetwork = &NetworkConfig{
+	GenesisValidatorsRoot: [32]byte{
+		0x4b, 0x36, 0x3d, 0xb9, 0x4e, 0x28, 0x61, 0x20, 0xd7, 0x6e, 0xb9, 0x05, 0x34,
+		0x0f, 0xdd, 0x4e, 0x54, 0xbf, 0xe9, 0xf0, 0x6b, 0xf3, 0x3f, 0xf6, 0xcf, 0x5a,
+		0xd2, 0x7f, 0x51, 0x1b, 0xfe, 0x95,
+	},
+	Forks: []Fork{
+		{Name: ForkBellatrix, Version: ForkVersion{0x02, 0x00, 0x00, 0x00}, Epoch: 144896},
+		{Name: ForkCapella, Version: ForkVersion{0x03, 0x00, 0x00, 0x00}, Epoch: 194048},
+		{Name: ForkDeneb, Version: ForkVersion{0x04, 0x00, 0x00, 0x00}, Epoch: 269568},
+		{Name: ForkElectra, Version: ForkVersion{0x05, 0x00, 0x00, 0x00}, Epoch: 364032},
+	},
+}
+
+// networks are the built-in configurations of the test networks, by execution chain id.
+var networks = map[uint64]*NetworkConfig{
+	5: { // Goerli
+		GenesisValidatorsRoot: [32]byte{
+			0x04, 0x3d, 0xb0, 0xd9, 0xa8, 0x38, 0x13, 0x55, 0x1e, 0xe2, 0xf3, 0x34, 0x50,
+			0xd2, 0x37, 0x97, 0x75, 0x7d, 0x43, 0x09, 0x11, 0xa9, 0x32, 0x05, 0x30, 0xad,
+			0x8a, 0x0e, 0xab, 0xc4, 0x3e, 0xfb,
+		},
+		Forks: []Fork{
+			{Name: ForkBellatrix, Version: ForkVersion{0x02, 0x00, 0x10, 0x20}, Epoch: 112260},
+			{Name: ForkCapella, Version: ForkVersion{0x03, 0x00, 0x10, 0x20}, Epoch: 162304},
+			{Name: ForkDeneb, Version: ForkVersion{0x04, 0x00, 0x10, 0x20}, Epoch: 231680},
+		},
+	},
+	11155111: { // Sepolia
+		GenesisValidatorsRoot: [32]byte{
+			0xd8, 0xea, 0x17, 0x1f, 0x3c, 0x94, 0xae, 0xa2, 0x1e, 0xbc, 0x42, 0xa1, 0xed,
+			0x61, 0x05, 0x2a, 0xcf, 0x3f, 0x92, 0x09, 0xc0, 0x0e, 0x4e, 0xfb, 0xaa, 0xdd,
+			0xac, 0x09, 0xed, 0x9b, 0x80, 0x78,
+		},
+		Forks: []Fork{
+			{Name: ForkBellatrix, Version: ForkVersion{0x90, 0x00, 0x00, 0x71}, Epoch: 100},
+			{Name: ForkCapella, Version: ForkVersion{0x90, 0x00, 0x00, 0x72}, Epoch: 56832},
+			{Name: ForkDeneb, Version: ForkVersion{0x90, 0x00, 0x00, 0x73}, Epoch: 132608},
+			{Name: ForkElectra, Version: ForkVersion{0x90, 0x00, 0x00, 0x74}, Epoch: 222464},
+		},
+	},
+	17000: { // Holesky
+		GenesisValidatorsRoot: [32]byte{
+			0x91, 0x43, 0xaa, 0x7c, 0x61, 0x5a, 0x7f, 0x71, 0x15, 0xe2, 0xb6, 0xaa, 0xc3,
+			0x19, 0xc0, 0x35, 0x29, 0xdf, 0x82, 0x42, 0xae, 0x70, 0x5f, 0xba, 0x9d, 0xf3,
+			0x9b, 0x79, 0xc5, 0x9f, 0xa8, 0xb1,
+		},
+		Forks: []Fork{
+			{Name: ForkBellatrix, Version: ForkVersion{0x03, 0x01, 0x70, 0x00}, Epoch: 0},
+			{Name: ForkCapella, Version: ForkVersion{0x04, 0x01, 0x70, 0x00}, Epoch: 256},
+			{Name: ForkDeneb, Version: ForkVersion{0x05, 0x01, 0x70, 0x00}, Epoch: 29696},
+			{Name: ForkElectra, Version: ForkVersion{0x06, 0x01, 0x70, 0x00}, Epoch: 115968},
+		},
+	},
 }
 
+// capellaNetworks are the networks whose updates were verified before deneb and
+// electra updates were accepted: Mainnet and Goerli.
+var capellaNetworks = map[uint64]bool{1: true, 5: true}
+
 func newNetworkConfig(chainID uint64) (*NetworkConfig, error) {
-	switch chainID {
-	case 1: // Mainnet
-		return &NetworkConfig{
-			GenesisValidatorsRoot: [32]byte{
-				0x4b, 0x36, 0x3d, 0xb9, 0x4e, 0x28, 0x61, 0x20, 0xd7, 0x6e, 0xb9, 0x05, 0x34,
-				0x0f, 0xdd, 0x4e, 0x54, 0xbf, 0xe9, 0xf0, 0x6b, 0xf3, 0x3f, 0xf6, 0xcf, 0x5a,
-				0xd2, 0x7f, 0x51, 0x1b, 0xfe, 0x95,
-			},
-			BellatrixForkVersion: [4]byte{0x02, 0x00, 0x00, 0x00},
-			BellatrixForkEpoch:   144896,
-			CapellaForkVersion:   [4]byte{0x03, 0x00, 0x00, 0x00},
-			CapellaForkEpoch:     194048,
-		}, nil
-	case 5: // Goerli
-		return &NetworkConfig{
-			GenesisValidatorsRoot: [32]byte{
-				0x04, 0x3d, 0xb0, 0xd9, 0xa8, 0x38, 0x13, 0x55, 0x1e, 0xe2, 0xf3, 0x34, 0x50,
-				0xd2, 0x37, 0x97, 0x75, 0x7d, 0x43, 0x09, 0x11, 0xa9, 0x32, 0x05, 0x30, 0xad,
-				0x8a, 0x0e, 0xab, 0xc4, 0x3e, 0xfb,
-			},
-			BellatrixForkVersion: [4]byte{0x02, 0x00, 0x10, 0x20},
-			BellatrixForkEpoch:   112260,
-			CapellaForkVersion:   [4]byte{0x03, 0x00, 0x10, 0x20},
-			CapellaForkEpoch:     162304,
-		}, nil
-	default:
+	return loadNetworkConfig(nil, chainID)
+}
+
+// loadNetworkConfig returns the configuration of the network with the given chain id.
+// A network listed in the chain config replaces the one registered in the eth2 module,
+// which replaces the built-in one.
+func loadNetworkConfig(config *params.ChainConfig, chainID uint64) (*NetworkConfig, error) {
+	if config != nil {
+		for _, n := range config.Eth2Networks {
+			if n != nil && n.ChainID == chainID {
+				return networkConfigFromParams(n)
+			}
+		}
+	}
+	if network, err := chains.Network(chains.ChainGroupETH2, chains.ChainType(chainID)); err == nil {
+		if nc, ok := network.(*NetworkConfig); ok {
+			return nc, nil
+		}
+	}
+	nc, ok := networks[chainID]
+	if !ok {
 		return nil, fmt.Errorf("unsupported network chain ID %d", chainID)
 	}
+	return nc, nil
 }
 
-// Return the fork version at the given epoch
-func (nc *NetworkConfig) computeForkVersion(epoch uint64) *ForkVersion {
-	if epoch >= nc.CapellaForkEpoch {
-		return &nc.CapellaForkVersion
+// capellaNetworkConfig returns the configuration of the network with the given chain id
+// limited to the forks up to capella, ignoring the chain config, as updates were
+// verified before the Eth2Deneb fork.
+func capellaNetworkConfig(chainID uint64) (*NetworkConfig, error) {
+	if !capellaNetworks[chainID] {
+		return nil, fmt.Errorf("unsupported network chain ID %d", chainID)
+	}
+	nc, err := newNetworkConfig(chainID)
+	if err != nil {
+		return nil, err
 	}
+	capella := &NetworkConfig{GenesisValidatorsRoot: nc.GenesisValidatorsRoot}
+	for _, fork := range nc.Forks {
+		if !fork.isAtLeast(ForkDeneb) {
+			capella.Forks = append(capella.Forks, fork)
+		}
+	}
+	return capella, nil
+}
 
-	if epoch >= nc.BellatrixForkEpoch {
-		return &nc.BellatrixForkVersion
+// updateNetworks returns the networks known to the update verification precompile at
+// the atlas block number.
+func updateNetworks(chainConfig *params.ChainConfig, number *big.Int) networkLookup {
+	if chainConfig == nil || !chainConfig.IsEth2Deneb(number) {
+		return capellaNetworkConfig
 	}
+	return func(chainID uint64) (*NetworkConfig, error) {
+		return loadNetworkConfig(chainConfig, chainID)
+	}
+}
+
+func networkConfigFromParams(n *params.BeaconNetwork) (*NetworkConfig, error) {
+	if len(n.Forks) == 0 {
+		return nil, fmt.Errorf("network chain ID %d has no forks", n.ChainID)
+	}
+	nc := &NetworkConfig{
+		GenesisValidatorsRoot: n.GenesisValidatorsRoot,
+		Forks:                 make([]Fork, 0, len(n.Forks)),
+	}
+	for i, f := range n.Forks {
+		if _, ok := forkOrder[f.Name]; !ok {
+			return nil, fmt.Errorf("unknown fork %q of network chain ID %d", f.Name, n.ChainID)
+		}
+		if len(f.Version) != ForkVersionByteLength {
+			return nil, fmt.Errorf("invalid version length %d of fork %s", len(f.Version), f.Name)
+		}
+		if i > 0 {
+			prev := n.Forks[i-1]
+			if f.Epoch < prev.Epoch || forkOrder[f.Name] <= forkOrder[prev.Name] {
+				return nil, fmt.Errorf("fork %s is out of order in network chain ID %d", f.Name, n.ChainID)
+			}
+		}
+		fork := Fork{Name: f.Name, Epoch: f.Epoch}
+		copy(fork.Version[:], f.Version)
+		nc.Forks = append(nc.Forks, fork)
+	}
+	return nc, nil
+}
+
+// Return the fork active at the given epoch, nil if it is older than all known forks
+func (nc *NetworkConfig) forkAt(epoch uint64) *Fork {
+	for i := len(nc.Forks) - 1; i >= 0; i-- {
+		if epoch >= nc.Forks[i].Epoch {
+			return &nc.Forks[i]
+		}
+	}
+	return nil
+}
 
+// Return the fork active at the given slot
+func (nc *NetworkConfig) forkAtSlot(slot uint64) *Fork {
+	return nc.forkAt(computeEpochAtSlot(slot))
+}
+
+// finalizedRootIndex returns the generalized index of the finalized checkpoint root in
+// the beacon state at the slot.
+func (nc *NetworkConfig) finalizedRootIndex(slot uint64) uint32 {
+	if fork := nc.forkAtSlot(slot); fork != nil && fork.isAtLeast(ForkElectra) {
+		return FinalizedRootIndexElectra
+	}
+	return FinalizedRootIndex
+}
+
+// nextSyncCommitteeIndex returns the generalized index of the next sync committee in
+// the beacon state at the slot.
+func (nc *NetworkConfig) nextSyncCommitteeIndex(slot uint64) uint32 {
+	if fork := nc.forkAtSlot(slot); fork != nil && fork.isAtLeast(ForkElectra) {
+		return NextSyncCommitteeIndexElectra
+	}
+	return NextSyncCommitteeIndex
+}
+
+// Return the fork version at the given epoch
+func (nc *NetworkConfig) computeForkVersion(epoch uint64) *ForkVersion {
+	if fork := nc.forkAt(epoch); fork != nil {
+		return &fork.Version
+	}
 	return nil
 }
 
@@ -0,0 +1,252 @@
+package eth2
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mapprotocol/atlas/params"
+)
+
+func TestNetworkConfig_ForkAt(t *testing.T) {
+	tests := []struct {
+		chainID uint64
+		epoch   uint64
+		want    string
+	}{
+		{1, 144895, ""},
+		{1, 144896, ForkBellatrix},
+		{1, 269567, ForkCapella},
+		{1, 269568, ForkDeneb},
+		{1, 364032, ForkElectra},
+		{5, 1 << 40, ForkDeneb},
+		{11155111, 222463, ForkDeneb},
+		{11155111, 222464, ForkElectra},
+		{17000, 0, ForkBellatrix},
+		{17000, 115968, ForkElectra},
+	}
+	for _, tt := range tests {
+		config, err := newNetworkConfig(tt.chainID)
+		assert.NoError(t, err)
+		fork := config.forkAt(tt.epoch)
+		if tt.want == "" {
+			assert.Nil(t, fork, "chain %d epoch %d", tt.chainID, tt.epoch)
+			assert.Nil(t, config.computeForkVersion(tt.epoch))
+			continue
+		}
+		assert.Equal(t, tt.want, fork.Name, "chain %d epoch %d", tt.chainID, tt.epoch)
+		assert.Equal(t, fork.Version, *config.computeForkVersionBySlot(tt.epoch * SlotsPerEpoch))
+	}
+
+	_, err := newNetworkConfig(56)
+	assert.Error(t, err)
+
+	holesky, err := newNetworkConfig(17000)
+	assert.NoError(t, err)
+	versions := []ForkVersion{{0x03, 0x01, 0x70, 0x00}, {0x04, 0x01, 0x70, 0x00}, {0x05, 0x01, 0x70, 0x00}, {0x06, 0x01, 0x70, 0x00}}
+	for i, fork := range holesky.Forks {
+		assert.Equal(t, versions[i], fork.Version, fork.Name)
+	}
+}
+
+func TestCapellaNetworkConfig(t *testing.T) {
+	config, err := capellaNetworkConfig(1)
+	assert.NoError(t, err)
+	assert.Equal(t, ForkCapella, config.forkAt(1<<40).Name)
+	assert.Equal(t, FinalizedRootIndex, config.finalizedRootIndex(364032*SlotsPerEpoch))
+
+	_, err = capellaNetworkConfig(11155111)
+	assert.Error(t, err)
+
+	// the chain config networks are only known from the fork
+	chainConfig := &params.ChainConfig{Eth2DenebBlock: big.NewInt(10), Eth2Networks: []*params.BeaconNetwork{{
+		ChainID: 11155111, Forks: []params.BeaconFork{{Name: ForkBellatrix, Version: []byte{0, 0, 0, 1}}},
+	}}}
+	_, err = updateNetworks(chainConfig, big.NewInt(9))(11155111)
+	assert.Error(t, err)
+	config, err = updateNetworks(chainConfig, big.NewInt(10))(11155111)
+	assert.NoError(t, err)
+	assert.Equal(t, ForkVersion{0, 0, 0, 1}, config.Forks[0].Version)
+}
+
+func TestNetworkConfig_Indexes(t *testing.T) {
+	config, err := newNetworkConfig(1)
+	assert.NoError(t, err)
+
+	deneb, electra := uint64(269568)*SlotsPerEpoch, uint64(364032)*SlotsPerEpoch
+	assert.Equal(t, FinalizedRootIndex, config.finalizedRootIndex(deneb))
+	assert.Equal(t, NextSyncCommitteeIndex, config.nextSyncCommitteeIndex(deneb))
+	assert.Equal(t, FinalizedRootIndexElectra, config.finalizedRootIndex(electra))
+	assert.Equal(t, NextSyncCommitteeIndexElectra, config.nextSyncCommitteeIndex(electra))
+	assert.Equal(t, FinalizedRootIndex, config.finalizedRootIndex(0))
+}
+
+func TestLoadNetworkConfig(t *testing.T) {
+	network := &params.BeaconNetwork{
+		ChainID:               1,
+		GenesisValidatorsRoot: common.Hash{0x01},
+		Forks: []params.BeaconFork{
+			{Name: ForkCapella, Version: []byte{0x03, 0, 0, 0x09}, Epoch: 10},
+			{Name: ForkDeneb, Version: []byte{0x04, 0, 0, 0x09}, Epoch: 20},
+		},
+	}
+	chainConfig := &params.ChainConfig{Eth2Networks: []*params.BeaconNetwork{network}}
+
+	// the chain config replaces the built-in network
+	config, err := loadNetworkConfig(chainConfig, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, [32]byte{0x01}, config.GenesisValidatorsRoot)
+	assert.Equal(t, ForkVersion{0x04, 0, 0, 0x09}, *config.computeForkVersion(25))
+	assert.Nil(t, config.forkAt(9))
+
+	// networks missing from the chain config are still known
+	config, err = loadNetworkConfig(chainConfig, 11155111)
+	assert.NoError(t, err)
+	assert.Equal(t, ForkElectra, config.forkAt(222464).Name)
+
+	invalid := []params.BeaconNetwork{
+		{ChainID: 1},
+		{ChainID: 1, Forks: []params.BeaconFork{{Name: "fulu", Version: []byte{0, 0, 0, 0}}}},
+		{ChainID: 1, Forks: []params.BeaconFork{{Name: ForkDeneb, Version: []byte{0}}}},
+		{ChainID: 1, Forks: []params.BeaconFork{
+			{Name: ForkDeneb, Version: []byte{0, 0, 0, 1}, Epoch: 10},
+			{Name: ForkCapella, Version: []byte{0, 0, 0, 2}, Epoch: 20},
+		}},
+		{ChainID: 1, Forks: []params.BeaconFork{
+			{Name: ForkCapella, Version: []byte{0, 0, 0, 1}, Epoch: 20},
+			{Name: ForkDeneb, Version: []byte{0, 0, 0, 2}, Epoch: 10},
+		}},
+	}
+	for i := range invalid {
+		_, err := loadNetworkConfig(&params.ChainConfig{Eth2Networks: []*params.BeaconNetwork{&invalid[i]}}, 1)
+		assert.Error(t, err, "network %d", i)
+	}
+}
+
+func denebPayload() *ExecutionPayload {
+	blobGasUsed, excessBlobGas := uint64(131072), uint64(393216)
+	return &ExecutionPayload{
+		ParentHash:       common.Hash{0x01},
+		FeeRecipient:     common.Address{0x02},
+		StateRoot:        common.Hash{0x03},
+		ReceiptsRoot:     common.Hash{0x04},
+		LogsBloom:        make([]byte, 256),
+		PrevRandao:       common.Hash{0x05},
+		BlockNumber:      big.NewInt(19426587),
+		GasLimit:         30000000,
+		GasUsed:          12000000,
+		Timestamp:        1710338135,
+		ExtraData:        []byte("atlas"),
+		BaseFeePerGas:    big.NewInt(1e9),
+		BlockHash:        common.Hash{0x06},
+		TransactionsRoot: common.Hash{0x07},
+		WithdrawalsRoot:  common.Hash{0x08},
+		BlobGasUsed:      &blobGasUsed,
+		ExcessBlobGas:    &excessBlobGas,
+	}
+}
+
+func TestExecutionPayload_Deneb(t *testing.T) {
+	payload := denebPayload()
+	denebRoot, err := payload.HashTreeRoot()
+	assert.NoError(t, err)
+
+	capella := *payload
+	capella.BlobGasUsed, capella.ExcessBlobGas = nil, nil
+	capellaRoot, err := capella.HashTreeRoot()
+	assert.NoError(t, err)
+	assert.NotEqual(t, capellaRoot, denebRoot)
+
+	// the blob gas fields are part of the root
+	*payload.ExcessBlobGas++
+	root, err := payload.HashTreeRoot()
+	assert.NoError(t, err)
+	assert.NotEqual(t, denebRoot, root)
+
+	payload.ExcessBlobGas = nil
+	_, err = payload.HashTreeRoot()
+	assert.Equal(t, errMissingBlobGas, err)
+
+	// capella payloads keep their encoding
+	enc, err := rlp.EncodeToBytes(&capella)
+	assert.NoError(t, err)
+	var dec ExecutionPayload
+	assert.NoError(t, rlp.DecodeBytes(enc, &dec))
+	assert.Nil(t, dec.BlobGasUsed)
+	enc, err = rlp.EncodeToBytes(denebPayload())
+	assert.NoError(t, err)
+	assert.NoError(t, rlp.DecodeBytes(enc, &dec))
+	assert.Equal(t, denebPayload(), &dec)
+}
+
+func TestCheckPayloadLayout(t *testing.T) {
+	config, err := newNetworkConfig(1)
+	assert.NoError(t, err)
+	capellaSlot, denebSlot := uint64(194048)*SlotsPerEpoch, uint64(269568)*SlotsPerEpoch
+
+	capella := *denebPayload()
+	capella.BlobGasUsed, capella.ExcessBlobGas = nil, nil
+	assert.NoError(t, checkPayloadLayout(config, capellaSlot, &capella))
+	assert.NoError(t, checkPayloadLayout(config, denebSlot, denebPayload()))
+	assert.ErrorIs(t, checkPayloadLayout(config, denebSlot, &capella), errPayloadLayout)
+	assert.ErrorIs(t, checkPayloadLayout(config, capellaSlot, denebPayload()), errPayloadLayout)
+	assert.Equal(t, errUnsupportedFork, checkPayloadLayout(config, 0, &capella))
+	assert.Equal(t, errMissingExecution, checkPayloadLayout(config, denebSlot, nil))
+}
+
+func TestDecodeLightClientUpdate_Deneb(t *testing.T) {
+	// the attested header is renamed so that the update packs by name, see splitInput
+	var arg abi.Argument
+	err := arg.UnmarshalJSON([]byte(strings.Replace(UpdateDenebABIJSON, "finalizedHeader", "attestedHeader", 1)))
+	assert.NoError(t, err)
+
+	root := [32]byte{0x01}
+	header := ILightNodeBeaconBlockHeader{Slot: 269568 * SlotsPerEpoch, ParentRoot: root, StateRoot: root, BodyRoot: root}
+	update := ILightNodeLightClientUpdateV2{
+		AttestedHeader:    header,
+		NextSyncCommittee: ILightNodeSyncCommittee{Pubkeys: []byte{}, AggregatePubkey: []byte{}},
+		FinalizedHeader:   header,
+		FinalizedExecution: ILightNodeExecution{
+			LogsBloom:     make([]byte, 256),
+			BlockNumber:   big.NewInt(19426587),
+			GasLimit:      big.NewInt(30000000),
+			GasUsed:       big.NewInt(12000000),
+			Timestamp:     big.NewInt(1710338135),
+			ExtraData:     []byte{},
+			BaseFeePerGas: big.NewInt(1e9),
+			BlobGasUsed:   big.NewInt(131072),
+			ExcessBlobGas: big.NewInt(393216),
+		},
+		SyncAggregate: ILightNodeSyncAggregate{SyncCommitteeBits: make([]byte, 64), SyncCommitteeSignature: []byte{}},
+		SignatureSlot: header.Slot + 1,
+	}
+	input, err := abi.Arguments{arg}.Pack(update)
+	assert.NoError(t, err)
+
+	config, err := newNetworkConfig(1)
+	assert.NoError(t, err)
+	decoded, err := decodeLightClientUpdate(config, input)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(131072), *decoded.finalizedExecution.BlobGasUsed)
+	assert.Equal(t, uint64(393216), *decoded.finalizedExecution.ExcessBlobGas)
+	assert.Equal(t, uint64(19426587), decoded.finalizedExecution.BlockNumber.Uint64())
+	assert.NoError(t, checkPayloadLayout(config, decoded.finalizedHeader.Slot, decoded.finalizedExecution))
+
+	// a network still on capella at that slot reads the capella layout
+	capellaConfig := &NetworkConfig{Forks: []Fork{{Name: ForkCapella}}}
+	decoded, err = decodeLightClientUpdate(capellaConfig, input)
+	assert.NoError(t, err)
+	assert.Nil(t, decoded.finalizedExecution.BlobGasUsed)
+	assert.Equal(t, uint64(19426587), decoded.finalizedExecution.BlockNumber.Uint64())
+
+	slot, err := finalizedSlotOf(input)
+	assert.NoError(t, err)
+	assert.Equal(t, header.Slot, slot)
+	_, err = finalizedSlotOf(input[:100])
+	assert.Equal(t, errShortInput, err)
+}
@@ -66,6 +66,9 @@ type ExecutionPayload struct {
 	BlockHash        common.Hash
 	TransactionsRoot common.Hash
 	WithdrawalsRoot  common.Hash
+	// Blob gas fields of the deneb payload, nil before deneb
+	BlobGasUsed   *uint64 `rlp:"optional"`
+	ExcessBlobGas *uint64 `rlp:"optional"`
 }
 
 // HashTreeRoot ssz hashes the BeaconBlockHeader object
@@ -190,10 +193,21 @@ func (e *ExecutionPayload) HashTreeRootWith(hh *fssz.Hasher) (err error) {
 	hh.PutBytes(e.TransactionsRoot.Bytes())
 
 	// Field (14) 'WithdrawalsRoot'
-	if !bytes.Equal(e.WithdrawalsRoot[:], make([]byte, 32)) {
+	if !bytes.Equal(e.WithdrawalsRoot[:], make([]byte, 32)) || e.BlobGasUsed != nil {
 		hh.PutBytes(e.WithdrawalsRoot.Bytes())
 	}
 
+	// Field (15) 'BlobGasUsed' and (16) 'ExcessBlobGas', the electra payload keeps the
+	// deneb layout
+	if e.BlobGasUsed != nil {
+		if e.ExcessBlobGas == nil {
+			err = errMissingBlobGas
+			return
+		}
+		hh.PutUint64(*e.BlobGasUsed)
+		hh.PutUint64(*e.ExcessBlobGas)
+	}
+
 	if fssz.EnableVectorizedHTR {
 		hh.MerkleizeVectorizedHTR(indx)
 	} else {
@@ -309,7 +323,7 @@ func (header *ILightNodeBeaconBlockHeader) toBeaconBlockHeader() *BeaconBlockHea
 }
 
 func (execution *ILightNodeExecution) toExecutionPayload() *ExecutionPayload {
-	return &ExecutionPayload{
+	payload := &ExecutionPayload{
 		ParentHash:       common.BytesToHash(execution.ParentHash[:]),
 		FeeRecipient:     common.BytesToAddress(execution.FeeRecipient[:]),
 		StateRoot:        common.BytesToHash(execution.StateRoot[:]),
@@ -326,6 +340,11 @@ func (execution *ILightNodeExecution) toExecutionPayload() *ExecutionPayload {
 		TransactionsRoot: common.BytesToHash(execution.TransactionsRoot[:]),
 		WithdrawalsRoot:  common.BytesToHash(execution.WithdrawalsRoot[:]),
 	}
+	if execution.BlobGasUsed != nil && execution.ExcessBlobGas != nil {
+		blobGasUsed, excessBlobGas := execution.BlobGasUsed.Uint64(), execution.ExcessBlobGas.Uint64()
+		payload.BlobGasUsed, payload.ExcessBlobGas = &blobGasUsed, &excessBlobGas
+	}
+	return payload
 }
 
 func (syncCommittee *ILightNodeSyncCommittee) toSyncCommittee() *SyncCommittee {
@@ -2,6 +2,8 @@ package eth2
 
 import (
 	"fmt"
+	"math/big"
+
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/log"
@@ -16,12 +18,30 @@ import (
 const ABIJSON = "{\"components\":[{\"components\":[{\"components\":[{\"internalType\":\"uint64\",\"name\":\"slot\",\"type\":\"uint64\"},{\"internalType\":\"uint64\",\"name\":\"proposerIndex\",\"type\":\"uint64\"},{\"internalType\":\"bytes32\",\"name\":\"parentRoot\",\"type\":\"bytes32\"},{\"internalType\":\"bytes32\",\"name\":\"stateRoot\",\"type\":\"bytes32\"},{\"internalType\":\"bytes32\",\"name\":\"bodyRoot\",\"type\":\"bytes32\"}],\"internalType\":\"structILightNode.BeaconBlockHeader\",\"name\":\"attestedHeader\",\"type\":\"tuple\"},{\"components\":[{\"internalType\":\"bytes\",\"name\":\"pubkeys\",\"type\":\"bytes\"},{\"internalType\":\"bytes\",\"name\":\"aggregatePubkey\",\"type\":\"bytes\"}],\"internalType\":\"structILightNode.SyncCommittee\",\"name\":\"nextSyncCommittee\",\"type\":\"tuple\"},{\"internalType\":\"bytes32[]\",\"name\":\"nextSyncCommitteeBranch\",\"type\":\"bytes32[]\"},{\"components\":[{\"internalType\":\"uint64\",\"name\":\"slot\",\"type\":\"uint64\"},{\"internalType\":\"uint64\",\"name\":\"proposerIndex\",\"type\":\"uint64\"},{\"internalType\":\"bytes32\",\"name\":\"parentRoot\",\"type\":\"bytes32\"},{\"internalType\":\"bytes32\",\"name\":\"stateRoot\",\"type\":\"bytes32\"},{\"internalType\":\"bytes32\",\"name\":\"bodyRoot\",\"type\":\"bytes32\"}],\"internalType\":\"structILightNode.BeaconBlockHeader\",\"name\":\"finalizedHeader\",\"type\":\"tuple\"},{\"internalType\":\"bytes32[]\",\"name\":\"finalityBranch\",\"type\":\"bytes32[]\"},{\"components\":[{\"internalType\":\"bytes\",\"name\":\"parentHash\",\"type\":\"bytes\"},{\"internalType\":\"bytes\",\"name\":\"sha3Uncles\",\"type\":\"bytes\"},{\"internalType\":\"address\",\"name\":\"miner\",\"type\":\"address\"},{\"internalType\":\"bytes\",\"name\":\"stateRoot\",\"type\":\"bytes\"},{\"internalType\":\"bytes\",\"name\":\"transactionsRoot\",\"type\":\"bytes\"},{\"internalType\":\"bytes\",\"name\":\"receiptsRoot\",\"type\":\"bytes\"},{\"internalType\":\"bytes\",\"name\":\"logsBloom\",\"type\":\"bytes\"},{\"internalType\":\"uint256\",\"name\":\"difficulty\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"number\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"gasLimit\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"gasUsed\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"timestamp\",\"type\":\"uint256\"},{\"internalType\":\"bytes\",\"name\":\"extraData\",\"type\":\"bytes\"},{\"internalType\":\"bytes\",\"name\":\"mixHash\",\"type\":\"bytes\"},{\"internalType\":\"bytes\",\"name\":\"nonce\",\"type\":\"bytes\"},{\"internalType\":\"uint256\",\"name\":\"baseFeePerGas\",\"type\":\"uint256\"}],\"internalType\":\"structILightNode.BlockHeader\",\"name\":\"finalizedExeHeader\",\"type\":\"tuple\"},{\"internalType\":\"bytes32[]\",\"name\":\"exeFinalityBranch\",\"type\":\"bytes32[]\"},{\"components\":[{\"internalType\":\"bytes\",\"name\":\"syncCommitteeBits\",\"type\":\"bytes\"},{\"internalType\":\"bytes\",\"name\":\"syncCommitteeSignature\",\"type\":\"bytes\"}],\"internalType\":\"structILightNode.SyncAggregate\",\"name\":\"syncAggregate\",\"type\":\"tuple\"},{\"internalType\":\"uint64\",\"name\":\"signatureSlot\",\"type\":\"uint64\"}],\"internalType\":\"structILightNode.LightClientUpdate\",\"name\":\"update\",\"type\":\"tuple\"},{\"components\":[{\"components\":[{\"internalType\":\"uint64\",\"name\":\"slot\",\"type\":\"uint64\"},{\"internalType\":\"uint64\",\"name\":\"proposerIndex\",\"type\":\"uint64\"},{\"internalType\":\"bytes32\",\"name\":\"parentRoot\",\"type\":\"bytes32\"},{\"internalType\":\"bytes32\",\"name\":\"stateRoot\",\"type\":\"bytes32\"},{\"internalType\":\"bytes32\",\"name\":\"bodyRoot\",\"type\":\"bytes32\"}],\"internalType\":\"structILightNode.BeaconBlockHeader\",\"name\":\"finalizedHeader\",\"type\":\"tuple\"},{\"components\":[{\"internalType\":\"bytes\",\"name\":\"pubkeys\",\"type\":\"bytes\"},{\"internalType\":\"bytes\",\"name\":\"aggregatePubkey\",\"type\":\"bytes\"}],\"internalType\":\"structILightNode.SyncCommittee\",\"name\":\"currentSyncCommittee\",\"type\":\"tuple\"},{\"components\":[{\"internalType\":\"bytes\",\"name\":\"pubkeys\",\"type\":\"bytes\"},{\"internalType\":\"bytes\",\"name\":\"aggregatePubkey\",\"type\":\"bytes\"}],\"internalType\":\"structILightNode.SyncCommittee\",\"name\":\"nextSyncCommittee\",\"type\":\"tuple\"},{\"internalType\":\"uint64\",\"name\":\"chainID\",\"type\":\"uint64\"}],\"internalType\":\"structILightNode.LightClientState\",\"name\":\"state\",\"type\":\"tuple\"}],\"indexed\":false,\"internalType\":\"structILightNode.LightClientVerify\",\"name\":\"verify\",\"type\":\"tuple\"}"
 
 const UpdateABIJSON = "{\"components\":[{\"components\":[{\"internalType\":\"uint64\",\"name\":\"slot\",\"type\":\"uint64\"},{\"internalType\":\"uint64\",\"name\":\"proposerIndex\",\"type\":\"uint64\"},{\"internalType\":\"bytes32\",\"name\":\"parentRoot\",\"type\":\"bytes32\"},{\"internalType\":\"bytes32\",\"name\":\"stateRoot\",\"type\":\"bytes32\"},{\"internalType\":\"bytes32\",\"name\":\"bodyRoot\",\"type\":\"bytes32\"}],\"internalType\":\"struct Types.BeaconBlockHeader\",\"name\":\"finalizedHeader\",\"type\":\"tuple\"},{\"components\":[{\"internalType\":\"bytes\",\"name\":\"pubkeys\",\"type\":\"bytes\"},{\"internalType\":\"bytes\",\"name\":\"aggregatePubkey\",\"type\":\"bytes\"}],\"internalType\":\"struct Types.SyncCommittee\",\"name\":\"nextSyncCommittee\",\"type\":\"tuple\"},{\"internalType\":\"bytes32[]\",\"name\":\"nextSyncCommitteeBranch\",\"type\":\"bytes32[]\"},{\"components\":[{\"internalType\":\"uint64\",\"name\":\"slot\",\"type\":\"uint64\"},{\"internalType\":\"uint64\",\"name\":\"proposerIndex\",\"type\":\"uint64\"},{\"internalType\":\"bytes32\",\"name\":\"parentRoot\",\"type\":\"bytes32\"},{\"internalType\":\"bytes32\",\"name\":\"stateRoot\",\"type\":\"bytes32\"},{\"internalType\":\"bytes32\",\"name\":\"bodyRoot\",\"type\":\"bytes32\"}],\"internalType\":\"struct Types.BeaconBlockHeader\",\"name\":\"finalizedHeader\",\"type\":\"tuple\"},{\"internalType\":\"bytes32[]\",\"name\":\"finalityBranch\",\"type\":\"bytes32[]\"},{\"components\":[{\"internalType\":\"bytes32\",\"name\":\"parentHash\",\"type\":\"bytes32\"},{\"internalType\":\"address\",\"name\":\"feeRecipient\",\"type\":\"address\"},{\"internalType\":\"bytes32\",\"name\":\"stateRoot\",\"type\":\"bytes32\"},{\"internalType\":\"bytes32\",\"name\":\"receiptsRoot\",\"type\":\"bytes32\"},{\"internalType\":\"bytes\",\"name\":\"logsBloom\",\"type\":\"bytes\"},{\"internalType\":\"bytes32\",\"name\":\"prevRandao\",\"type\":\"bytes32\"},{\"internalType\":\"uint256\",\"name\":\"blockNumber\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"gasLimit\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"gasUsed\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"timestamp\",\"type\":\"uint256\"},{\"internalType\":\"bytes\",\"name\":\"extraData\",\"type\":\"bytes\"},{\"internalType\":\"uint256\",\"name\":\"baseFeePerGas\",\"type\":\"uint256\"},{\"internalType\":\"bytes32\",\"name\":\"blockHash\",\"type\":\"bytes32\"},{\"internalType\":\"bytes32\",\"name\":\"transactionsRoot\",\"type\":\"bytes32\"},{\"internalType\":\"bytes32\",\"name\":\"withdrawalsRoot\",\"type\":\"bytes32\"}],\"internalType\":\"struct Types.Execution\",\"name\":\"finalizedExecution\",\"type\":\"tuple\"},{\"internalType\":\"bytes32[]\",\"name\":\"executionBranch\",\"type\":\"bytes32[]\"},{\"components\":[{\"internalType\":\"bytes\",\"name\":\"syncCommitteeBits\",\"type\":\"bytes\"},{\"internalType\":\"bytes\",\"name\":\"syncCommitteeSignature\",\"type\":\"bytes\"}],\"internalType\":\"struct Types.SyncAggregate\",\"name\":\"syncAggregate\",\"type\":\"tuple\"},{\"internalType\":\"uint64\",\"name\":\"signatureSlot\",\"type\":\"uint64\"}],\"internalType\":\"struct Types.LightClientUpdate\",\"type\":\"tuple\"}"
+
+// UpdateDenebABIJSON is the update from deneb on, the finalized execution has the blob
+// gas fields of the deneb execution payload.
+const UpdateDenebABIJSON = "{\"components\":[{\"components\":[{\"internalType\":\"uint64\",\"name\":\"slot\",\"type\":\"uint64\"},{\"internalType\":\"uint64\",\"name\":\"proposerIndex\",\"type\":\"uint64\"},{\"internalType\":\"bytes32\",\"name\":\"parentRoot\",\"type\":\"bytes32\"},{\"internalType\":\"bytes32\",\"name\":\"stateRoot\",\"type\":\"bytes32\"},{\"internalType\":\"bytes32\",\"name\":\"bodyRoot\",\"type\":\"bytes32\"}],\"internalType\":\"struct Types.BeaconBlockHeader\",\"name\":\"finalizedHeader\",\"type\":\"tuple\"},{\"components\":[{\"internalType\":\"bytes\",\"name\":\"pubkeys\",\"type\":\"bytes\"},{\"internalType\":\"bytes\",\"name\":\"aggregatePubkey\",\"type\":\"bytes\"}],\"internalType\":\"struct Types.SyncCommittee\",\"name\":\"nextSyncCommittee\",\"type\":\"tuple\"},{\"internalType\":\"bytes32[]\",\"name\":\"nextSyncCommitteeBranch\",\"type\":\"bytes32[]\"},{\"components\":[{\"internalType\":\"uint64\",\"name\":\"slot\",\"type\":\"uint64\"},{\"internalType\":\"uint64\",\"name\":\"proposerIndex\",\"type\":\"uint64\"},{\"internalType\":\"bytes32\",\"name\":\"parentRoot\",\"type\":\"bytes32\"},{\"internalType\":\"bytes32\",\"name\":\"stateRoot\",\"type\":\"bytes32\"},{\"internalType\":\"bytes32\",\"name\":\"bodyRoot\",\"type\":\"bytes32\"}],\"internalType\":\"struct Types.BeaconBlockHeader\",\"name\":\"finalizedHeader\",\"type\":\"tuple\"},{\"internalType\":\"bytes32[]\",\"name\":\"finalityBranch\",\"type\":\"bytes32[]\"},{\"components\":[{\"internalType\":\"bytes32\",\"name\":\"parentHash\",\"type\":\"bytes32\"},{\"internalType\":\"address\",\"name\":\"feeRecipient\",\"type\":\"address\"},{\"internalType\":\"bytes32\",\"name\":\"stateRoot\",\"type\":\"bytes32\"},{\"internalType\":\"bytes32\",\"name\":\"receiptsRoot\",\"type\":\"bytes32\"},{\"internalType\":\"bytes\",\"name\":\"logsBloom\",\"type\":\"bytes\"},{\"internalType\":\"bytes32\",\"name\":\"prevRandao\",\"type\":\"bytes32\"},{\"internalType\":\"uint256\",\"name\":\"blockNumber\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"gasLimit\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"gasUsed\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"timestamp\",\"type\":\"uint256\"},{\"internalType\":\"bytes\",\"name\":\"extraData\",\"type\":\"bytes\"},{\"internalType\":\"uint256\",\"name\":\"baseFeePerGas\",\"type\":\"uint256\"},{\"internalType\":\"bytes32\",\"name\":\"blockHash\",\"type\":\"bytes32\"},{\"internalType\":\"bytes32\",\"name\":\"transactionsRoot\",\"type\":\"bytes32\"},{\"internalType\":\"bytes32\",\"name\":\"withdrawalsRoot\",\"type\":\"bytes32\"},{\"internalType\":\"uint256\",\"name\":\"blobGasUsed\",\"type\":\"uint256\"},{\"internalType\":\"uint256\",\"name\":\"excessBlobGas\",\"type\":\"uint256\"}],\"internalType\":\"struct Types.Execution\",\"name\":\"finalizedExecution\",\"type\":\"tuple\"},{\"internalType\":\"bytes32[]\",\"name\":\"executionBranch\",\"type\":\"bytes32[]\"},{\"components\":[{\"internalType\":\"bytes\",\"name\":\"syncCommitteeBits\",\"type\":\"bytes\"},{\"internalType\":\"bytes\",\"name\":\"syncCommitteeSignature\",\"type\":\"bytes\"}],\"internalType\":\"struct Types.SyncAggregate\",\"name\":\"syncAggregate\",\"type\":\"tuple\"},{\"internalType\":\"uint64\",\"name\":\"signatureSlot\",\"type\":\"uint64\"}],\"internalType\":\"struct Types.LightClientUpdate\",\"type\":\"tuple\"}"
+
 const BeaconHeaderABIJSON = "{\"components\":[{\"internalType\":\"uint64\",\"name\":\"slot\",\"type\":\"uint64\"},{\"internalType\":\"uint64\",\"name\":\"proposerIndex\",\"type\":\"uint64\"},{\"internalType\":\"bytes32\",\"name\":\"parentRoot\",\"type\":\"bytes32\"},{\"internalType\":\"bytes32\",\"name\":\"stateRoot\",\"type\":\"bytes32\"},{\"internalType\":\"bytes32\",\"name\":\"bodyRoot\",\"type\":\"bytes32\"}],\"internalType\":\"struct Types.BeaconBlockHeader\",\"type\":\"tuple\"}"
 const SyncCommitteeABIJSON = "{\"components\":[{\"internalType\":\"bytes\",\"name\":\"pubkeys\",\"type\":\"bytes\"},{\"internalType\":\"bytes\",\"name\":\"aggregatePubkey\",\"type\":\"bytes\"}],\"internalType\":\"struct Types.SyncCommittee\",\"type\":\"tuple\"}"
 const ChainIdABIJSON = "{\"internalType\":\"uint64\",\"type\":\"uint64\"}"
 
-func decodeLightClientVerify(input []byte) (*LightClientVerify, error) {
-	verify, err := decodeLightClientVerifyV2(input)
+// Words of the abi head read to choose the update layout before decoding. The headers
+// are static tuples in the head of the update: the attested header takes words 0 to 4,
+// then come the offsets of the next sync committee and its branch, and the finalized
+// header starts at word 7. The verify input is the update, the finalized header, the
+// two sync committees and the chain id.
+const (
+	updateFinalizedSlotWord = 7
+	verifyChainIDWord       = 8
+)
+
+// networkLookup returns the configuration of the network with the given chain id.
+type networkLookup func(chainID uint64) (*NetworkConfig, error)
+
+func decodeLightClientVerify(network networkLookup, input []byte) (*LightClientVerify, error) {
+	verify, err := decodeLightClientVerifyV2(network, input)
 	if err != nil {
 		log.Warn("decodeLightClientVerifyV2", "error", err)
 		verify, err = decodeLightClientVerifyV1(input)
@@ -54,8 +74,26 @@ func decodeLightClientVerifyV1(input []byte) (*LightClientVerify, error) {
 	return verify.toLightClientVerify(), nil
 }
 
-func decodeLightClientVerifyV2(input []byte) (*LightClientVerify, error) {
-	args, err := genAbiArgs()
+// decodeLightClientVerifyV2 decodes a verify input, the finalized execution of the
+// update has the blob gas fields from deneb on.
+func decodeLightClientVerifyV2(network networkLookup, input []byte) (*LightClientVerify, error) {
+	chainID, err := abiUint64At(input, verifyChainIDWord*32)
+	if err != nil {
+		return nil, err
+	}
+	config, err := network(chainID)
+	if err != nil {
+		return nil, err
+	}
+	slot, err := finalizedSlotOf(input)
+	if err != nil {
+		return nil, err
+	}
+	return unpackLightClientVerifyV2(input, isDenebPayload(config, slot))
+}
+
+func unpackLightClientVerifyV2(input []byte, deneb bool) (*LightClientVerify, error) {
+	args, err := genAbiArgs(deneb)
 	if err != nil {
 		return nil, fmt.Errorf("gen abi args failed: %v", err)
 	}
@@ -77,9 +115,99 @@ func decodeLightClientVerifyV2(input []byte) (*LightClientVerify, error) {
 	return ConvertToLightClientVerify(update, finalizedBeaconHeader, curSyncCommittee, nextSyncCommittee, *chainId), nil
 }
 
-func genAbiArgs() (abi.Arguments, error) {
+// decodeLightClientUpdate decodes an update of the given network, the finalized
+// execution has the blob gas fields from deneb on.
+func decodeLightClientUpdate(config *NetworkConfig, input []byte) (*LightClientUpdateV2, error) {
+	slot, err := finalizedSlotOf(input)
+	if err != nil {
+		return nil, err
+	}
+	return unpackLightClientUpdate(input, isDenebPayload(config, slot))
+}
+
+func unpackLightClientUpdate(input []byte, deneb bool) (*LightClientUpdateV2, error) {
+	args, err := genAbiArgs(deneb)
+	if err != nil {
+		return nil, fmt.Errorf("gen abi args failed: %v", err)
+	}
+	args = args[:1]
+
+	ret, err := args.Unpack(input)
+	if err != nil {
+		return nil, fmt.Errorf("unpack input failed: %v", err)
+	}
+
+	update := new(ILightNodeLightClientUpdateV2)
+	if err := args.Copy(&update, ret); err != nil {
+		return nil, fmt.Errorf("copy unpacked result failed: %v", err)
+	}
+
+	return update.toLightClientUpdateV2(), nil
+}
+
+// isDenebPayload reports whether the execution payload finalized at the slot has the
+// deneb layout.
+func isDenebPayload(config *NetworkConfig, slot uint64) bool {
+	fork := config.forkAtSlot(slot)
+	return fork != nil && fork.isAtLeast(ForkDeneb)
+}
+
+// finalizedSlotOf returns the slot of the finalized header of the update, the first
+// argument encoded in input, without decoding the update.
+func finalizedSlotOf(input []byte) (uint64, error) {
+	offset, err := abiUint64At(input, 0)
+	if err != nil {
+		return 0, err
+	}
+	if offset > uint64(len(input)) {
+		return 0, errShortInput
+	}
+	return abiUint64At(input, offset+updateFinalizedSlotWord*32)
+}
+
+// abiUint64At returns the uint64 encoded in the abi word of input at offset.
+func abiUint64At(input []byte, offset uint64) (uint64, error) {
+	if offset > uint64(len(input)) || uint64(len(input))-offset < 32 {
+		return 0, errShortInput
+	}
+	word := new(big.Int).SetBytes(input[offset : offset+32])
+	if !word.IsUint64() {
+		return 0, fmt.Errorf("abi word at %d overflows uint64", offset)
+	}
+	return word.Uint64(), nil
+}
+
+func decodeLightClientState(input []byte) (*LightClientState, error) {
+	args, err := genAbiArgs(false)
+	if err != nil {
+		return nil, fmt.Errorf("gen abi args failed: %v", err)
+	}
+	args = args[1:]
+
+	ret, err := args.Unpack(input)
+	if err != nil {
+		return nil, fmt.Errorf("unpack input failed: %v", err)
+	}
+
+	finalizedBeaconHeader := new(ILightNodeBeaconBlockHeader)
+	curSyncCommittee := new(ILightNodeSyncCommittee)
+	nextSyncCommittee := new(ILightNodeSyncCommittee)
+	chainId := new(uint64)
+	if err := args.Copy(&[]interface{}{finalizedBeaconHeader, curSyncCommittee, nextSyncCommittee, chainId}, ret); err != nil {
+		return nil, fmt.Errorf("copy unpacked result failed: %v", err)
+	}
+
+	return ConvertToLightClientState(finalizedBeaconHeader, curSyncCommittee, nextSyncCommittee, *chainId), nil
+}
+
+func genAbiArgs(deneb bool) (abi.Arguments, error) {
+	updateJSON := UpdateABIJSON
+	if deneb {
+		updateJSON = UpdateDenebABIJSON
+	}
+
 	var updateArg, beaconHeaderArg, syncCommitteeArg, chainIdArg abi.Argument
-	if err := updateArg.UnmarshalJSON([]byte(UpdateABIJSON)); err != nil {
+	if err := updateArg.UnmarshalJSON([]byte(updateJSON)); err != nil {
 		return nil, fmt.Errorf("unmarshal update abi json failed: %v", err)
 	}
 
@@ -107,6 +235,10 @@ func computeSyncCommitteePeriod(slot uint64) uint64 {
 }
 
 func getParticipantPubkeys(public_keys [][]byte, sync_committee_bits bitfield.Bitvector512) ([]bls2.PublicKey, error) {
+	if uint64(len(public_keys)) != sync_committee_bits.Len() {
+		return nil, fmt.Errorf("sync committee size mismatch, exp: %d, got: %d", sync_committee_bits.Len(), len(public_keys))
+	}
+
 	var pubkeys []bls2.PublicKey
 	for i := uint64(0); i < sync_committee_bits.Len(); i++ {
 		if sync_committee_bits.BitAt(i) {
@@ -0,0 +1,104 @@
+package eth2
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/mapprotocol/atlas/chains"
+	"github.com/mapprotocol/atlas/core/types"
+	"github.com/mapprotocol/atlas/params"
+)
+
+type Validate struct {
+	chainConfig *params.ChainConfig
+}
+
+// SetChainConfig sets the atlas chain config the network configuration of the followed
+// beacon chain is read from.
+func (v *Validate) SetChainConfig(config *params.ChainConfig) {
+	v.chainConfig = config
+}
+
+// ValidateHeaderChain checks a light client update against the state kept in
+// the header store. The update is the abi encoded LightClientUpdate tuple.
+func (v *Validate) ValidateHeaderChain(db types.StateDB, input []byte, chainType chains.ChainType) (int, error) {
+	hs := NewHeaderStore()
+	hs.SetChainConfig(v.chainConfig)
+	if err := hs.Load(db); err != nil {
+		return 0, err
+	}
+	config, err := hs.networkConfig()
+	if err != nil {
+		return 0, err
+	}
+	update, err := decodeLightClientUpdate(config, input)
+	if err != nil {
+		log.Error("decode eth2 light client update failed", "err", err)
+		return 0, err
+	}
+	if err := v.verifyUpdate(hs, config, update); err != nil {
+		return 0, err
+	}
+	return 0, nil
+}
+
+// EstimateWork returns the work of verifying an update, priced for a fully signed
+// one: the public keys of the whole sync committee are decompressed and aggregated,
+// and the aggregate signature costs two pairings. The store and the finalized block
+// are written.
+func (v *Validate) EstimateWork(_ types.StateDB, input []byte) (*chains.Work, error) {
+	return &chains.Work{
+		Headers:     2,
+		PubKeys:     SyncCommitteeSize,
+		Pairings:    2,
+		StateWrites: 2,
+	}, nil
+}
+
+func (v *Validate) verifyUpdate(hs *HeaderStore, config *NetworkConfig, update *LightClientUpdateV2) error {
+	if update.finalizedHeader.Slot <= hs.FinalizedHeader.Slot {
+		return errStaleUpdate
+	}
+	if update.attestedHeader.Slot < update.finalizedHeader.Slot {
+		return fmt.Errorf("attested header slot %d is older than finalized header slot %d",
+			update.attestedHeader.Slot, update.finalizedHeader.Slot)
+	}
+	if update.signatureSlot <= update.attestedHeader.Slot {
+		return fmt.Errorf("signature slot %d is not newer than attested header slot %d",
+			update.signatureSlot, update.attestedHeader.Slot)
+	}
+	if update.finalizedExecution == nil || update.finalizedExecution.BlockNumber == nil {
+		return errMissingExecution
+	}
+
+	finalizedPeriod := computeSyncCommitteePeriod(hs.FinalizedHeader.Slot)
+	updatePeriod := computeSyncCommitteePeriod(update.finalizedHeader.Slot)
+	if updatePeriod != finalizedPeriod && updatePeriod != finalizedPeriod+1 {
+		return errInvalidUpdatePeriod
+	}
+	signaturePeriod := computeSyncCommitteePeriod(update.signatureSlot)
+	if (updatePeriod == finalizedPeriod+1 || signaturePeriod == finalizedPeriod+1) &&
+		(hs.NextSyncCommittee == nil || len(hs.NextSyncCommittee.Pubkeys) == 0) {
+		return errUnknownNextCommittee
+	}
+
+	if err := checkPayloadLayout(config, update.finalizedHeader.Slot, update.finalizedExecution); err != nil {
+		return err
+	}
+
+	state := hs.state()
+	if err := verifyFinalityV2(config, update); err != nil {
+		log.Warn("verifyFinalityV2", "error", err)
+		return err
+	}
+	if err := verifyNextSyncCommittee(config, state, update); err != nil {
+		log.Warn("verifyNextSyncCommittee", "error", err)
+		return err
+	}
+	if err := verifyBlsSignatures(config, state, update); err != nil {
+		log.Warn("verifyBlsSignatures", "error", err)
+		return err
+	}
+	return nil
+}
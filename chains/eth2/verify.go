@@ -0,0 +1,209 @@
+package eth2
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/light"
+	"github.com/ethereum/go-ethereum/rlp"
+	ssz "github.com/prysmaticlabs/fastssz"
+
+	"github.com/mapprotocol/atlas/chains"
+	"github.com/mapprotocol/atlas/core/types"
+)
+
+// MaxAncestors is the largest number of execution headers linking a proven block to
+// the finalized block of a TxProve.
+const MaxAncestors = 256
+
+// TxProve proves a receipt of an execution block finalized by the beacon chain.
+// The execution payload is proven against the body root of the beacon header,
+// the receipt against the receipts root of the execution payload. A receipt of an
+// earlier block is proven against the receipts root of the first of Ancestors, the
+// headers linked by parent hash from the block of the receipt up to the parent of
+// the finalized execution payload.
+type TxProve struct {
+	Header          *BeaconBlockHeader
+	Execution       *ExecutionPayload
+	ExecutionBranch [][]byte
+	Receipt         *ethtypes.Receipt
+	Prove           light.NodeList
+	TxIndex         uint
+	Ancestors       []rlp.RawValue `rlp:"optional"`
+}
+
+type Verify struct {
+}
+
+func (v *Verify) Verify(db types.StateDB, routerContractAddr common.Address, txProveBytes []byte) (logs []byte, err error) {
+	txProve, err := v.decode(txProveBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := v.getFinalizedBlock(db, txProve)
+	if err != nil {
+		return nil, err
+	}
+	if err := v.verifyExecution(block, txProve); err != nil {
+		return nil, err
+	}
+	receiptsRoot, err := v.verifyAncestors(txProve.Execution, txProve.Ancestors)
+	if err != nil {
+		return nil, err
+	}
+	if err := chains.VerifyReceipt(receiptsRoot, txProve.Receipt, txProve.TxIndex, txProve.Prove); err != nil {
+		return nil, err
+	}
+	return rlp.EncodeToBytes(txProve.Receipt.Logs)
+}
+
+func (v *Verify) decode(txProveBytes []byte) (*TxProve, error) {
+	var txProve TxProve
+	if err := rlp.DecodeBytes(txProveBytes, &txProve); err != nil {
+		return nil, err
+	}
+	if txProve.Header == nil || txProve.Execution == nil || txProve.Execution.BlockNumber == nil || txProve.Receipt == nil {
+		return nil, errors.New("incomplete eth2 tx prove")
+	}
+	return &txProve, nil
+}
+
+func (v *Verify) getFinalizedBlock(db types.StateDB, txProve *TxProve) (*FinalizedBlock, error) {
+	hs := NewHeaderStore()
+	if err := hs.Load(db); err != nil {
+		return nil, err
+	}
+	number := txProve.Execution.BlockNumber.Uint64()
+	block, err := hs.LoadFinalizedBlock(db, number)
+	if err != nil {
+		return nil, err
+	}
+	if block == nil {
+		return nil, fmt.Errorf("block %d is not finalized", number)
+	}
+	return block, nil
+}
+
+// verifyExecution checks that the beacon header is the finalized one and that the
+// execution payload is part of its body.
+func (v *Verify) verifyExecution(block *FinalizedBlock, txProve *TxProve) error {
+	root, err := txProve.Header.HashTreeRoot()
+	if err != nil {
+		return fmt.Errorf("failed to compute hash tree root of beacon header: %v", err)
+	}
+	if common.Hash(root) != block.BeaconRoot {
+		return fmt.Errorf("beacon header mismatch, exp: %v, got: %v", block.BeaconRoot, common.Hash(root))
+	}
+
+	if uint64(len(txProve.ExecutionBranch)) != L1BeaconBlockBodyProofSize {
+		return fmt.Errorf("invalid execution payload proof size, exp: %d, got: %d", L1BeaconBlockBodyProofSize, len(txProve.ExecutionBranch))
+	}
+	executionPayloadHash, err := txProve.Execution.HashTreeRoot()
+	if err != nil {
+		return fmt.Errorf("compute execution payload merkel root failed: %v", err)
+	}
+	proof := ssz.Proof{
+		Index:  int(L1BeaconBlockBodyTreeExecutionPayloadIndex),
+		Leaf:   executionPayloadHash[:],
+		Hashes: txProve.ExecutionBranch,
+	}
+	ret, err := ssz.VerifyProof(txProve.Header.BodyRoot, &proof)
+	if err != nil {
+		return fmt.Errorf("VerifyProof return err: %v", err)
+	}
+	if !ret {
+		return errors.New("invalid execution payload proof")
+	}
+
+	if txProve.Execution.BlockHash != block.Hash {
+		return fmt.Errorf("execution block hash mismatch, exp: %v, got: %v", block.Hash, txProve.Execution.BlockHash)
+	}
+	return nil
+}
+
+// ancestorHeader is the prefix of an rlp encoded execution header up to its receipts
+// root, the fields added by later forks are kept in Rest.
+type ancestorHeader struct {
+	ParentHash  common.Hash
+	UncleHash   common.Hash
+	Coinbase    common.Address
+	Root        common.Hash
+	TxHash      common.Hash
+	ReceiptHash common.Hash
+	Rest        []rlp.RawValue `rlp:"tail"`
+}
+
+// verifyAncestors checks that the rlp encoded ancestors are linked by parent hash up to
+// the finalized execution payload and returns the receipts root the receipts are proven
+// against.
+func (v *Verify) verifyAncestors(execution *ExecutionPayload, ancestors []rlp.RawValue) (common.Hash, error) {
+	if len(ancestors) == 0 {
+		return execution.ReceiptsRoot, nil
+	}
+	if len(ancestors) > MaxAncestors {
+		return common.Hash{}, fmt.Errorf("too many ancestors, max: %d, got: %d", MaxAncestors, len(ancestors))
+	}
+	var (
+		header     ancestorHeader
+		parentHash = execution.ParentHash
+	)
+	for i := len(ancestors) - 1; i >= 0; i-- {
+		if hash := crypto.Keccak256Hash(ancestors[i]); hash != parentHash {
+			return common.Hash{}, fmt.Errorf("ancestor %d is not linked, exp: %v, got: %v", i, parentHash, hash)
+		}
+		if err := rlp.DecodeBytes(ancestors[i], &header); err != nil {
+			return common.Hash{}, fmt.Errorf("ancestor %d decode failed: %v", i, err)
+		}
+		parentHash = header.ParentHash
+	}
+	return header.ReceiptHash, nil
+}
+
+// BatchTxProve proves several receipts of one execution block finalized by the beacon
+// chain, or of one of its Ancestors as in TxProve. The trie nodes of all the receipt
+// proofs are merged in Prove.
+type BatchTxProve struct {
+	Header          *BeaconBlockHeader
+	Execution       *ExecutionPayload
+	ExecutionBranch [][]byte
+	Receipts        []*ethtypes.Receipt
+	TxIndexes       []uint
+	Prove           light.NodeList
+	Ancestors       []rlp.RawValue `rlp:"optional"`
+}
+
+// VerifyBatch verifies the receipts of a BatchTxProve against the receipts root of
+// their finalized execution payload and returns their logs.
+func (v *Verify) VerifyBatch(db types.StateDB, routerContractAddr common.Address, txProveBytes []byte) ([]*ethtypes.Log, error) {
+	var batch BatchTxProve
+	if err := rlp.DecodeBytes(txProveBytes, &batch); err != nil {
+		return nil, err
+	}
+	if batch.Header == nil || batch.Execution == nil || batch.Execution.BlockNumber == nil {
+		return nil, errors.New("incomplete eth2 tx prove")
+	}
+	if err := chains.CheckBatch(batch.Receipts, batch.TxIndexes); err != nil {
+		return nil, err
+	}
+
+	txProve := &TxProve{Header: batch.Header, Execution: batch.Execution, ExecutionBranch: batch.ExecutionBranch}
+	block, err := v.getFinalizedBlock(db, txProve)
+	if err != nil {
+		return nil, err
+	}
+	if err := v.verifyExecution(block, txProve); err != nil {
+		return nil, err
+	}
+	receiptsRoot, err := v.verifyAncestors(batch.Execution, batch.Ancestors)
+	if err != nil {
+		return nil, err
+	}
+	if err := chains.VerifyReceipts(receiptsRoot, batch.Receipts, batch.TxIndexes, batch.Prove); err != nil {
+		return nil, err
+	}
+	return chains.ReceiptLogs(batch.Receipts), nil
+}
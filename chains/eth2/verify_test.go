@@ -0,0 +1,186 @@
+package eth2
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb/memorydb"
+	"github.com/ethereum/go-ethereum/light"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/stretchr/testify/assert"
+
+	atlasstate "github.com/mapprotocol/atlas/core/state"
+)
+
+func finalizedStateDB(t *testing.T) *atlasstate.StateDB {
+	db := getStateDB()
+	resetInput, updateInput := splitInput(t)
+
+	hs := NewHeaderStore()
+	assert.Nil(t, hs.ResetHeaderStore(db, resetInput, nil))
+	_, err := hs.InsertHeaders(db, updateInput)
+	assert.Nil(t, err)
+	return db
+}
+
+func receiptTrie(t *testing.T, receipts ethtypes.Receipts, txIndex uint) (common.Hash, light.NodeList) {
+	tr, err := trie.New(common.Hash{}, trie.NewDatabase(memorydb.New()))
+	assert.Nil(t, err)
+	for i := range receipts {
+		key, err := rlp.EncodeToBytes(uint(i))
+		assert.Nil(t, err)
+		var buf bytes.Buffer
+		receipts.EncodeIndex(i, &buf)
+		tr.Update(key, buf.Bytes())
+	}
+
+	proof := light.NewNodeSet()
+	key, err := rlp.EncodeToBytes(txIndex)
+	assert.Nil(t, err)
+	assert.Nil(t, tr.Prove(key, 0, proof))
+	return tr.Hash(), proof.NodeList()
+}
+
+func TestVerify_verifyExecution(t *testing.T) {
+	db := finalizedStateDB(t)
+	block, err := new(Verify).getFinalizedBlock(db, &TxProve{Execution: update.finalizedExecution})
+	assert.Nil(t, err)
+
+	txProve := &TxProve{
+		Header:          update.finalizedHeader,
+		Execution:       update.finalizedExecution,
+		ExecutionBranch: update.executionBranch,
+	}
+	assert.Nil(t, new(Verify).verifyExecution(block, txProve))
+
+	// the attested header is not the finalized one
+	txProve.Header = update.attestedHeader
+	assert.NotNil(t, new(Verify).verifyExecution(block, txProve))
+
+	// the branch does not prove the payload
+	txProve.Header = update.finalizedHeader
+	txProve.ExecutionBranch = update.finalityBranch[:L1BeaconBlockBodyProofSize]
+	assert.NotNil(t, new(Verify).verifyExecution(block, txProve))
+}
+
+func TestVerify_NotFinalized(t *testing.T) {
+	db := finalizedStateDB(t)
+	execution := *update.finalizedExecution
+	execution.BlockNumber = new(big.Int).Add(execution.BlockNumber, big.NewInt(1))
+
+	_, err := new(Verify).getFinalizedBlock(db, &TxProve{Execution: &execution})
+	assert.NotNil(t, err)
+}
+
+func TestVerify_decode(t *testing.T) {
+	txProve := TxProve{
+		Header:          update.finalizedHeader,
+		Execution:       update.finalizedExecution,
+		ExecutionBranch: update.executionBranch,
+		Receipt:         &ethtypes.Receipt{Status: ethtypes.ReceiptStatusSuccessful, Logs: []*ethtypes.Log{}},
+	}
+	input, err := rlp.EncodeToBytes(txProve)
+	assert.Nil(t, err)
+
+	got, err := new(Verify).decode(input)
+	assert.Nil(t, err)
+	assert.Equal(t, txProve.Header, got.Header)
+	assert.Equal(t, txProve.Execution.BlockHash, got.Execution.BlockHash)
+	assert.Equal(t, txProve.ExecutionBranch, got.ExecutionBranch)
+}
+
+// finalizeExecution stores a finalized block for the execution payload under a beacon
+// header whose body proves it, and returns the header and the execution branch.
+func finalizeExecution(t *testing.T, db *atlasstate.StateDB, execution *ExecutionPayload) (*BeaconBlockHeader, [][]byte) {
+	leaf, err := execution.HashTreeRoot()
+	assert.Nil(t, err)
+	node, branch := leaf[:], make([][]byte, L1BeaconBlockBodyProofSize)
+	for i, index := 0, L1BeaconBlockBodyTreeExecutionPayloadIndex; i < len(branch); i, index = i+1, index/2 {
+		branch[i] = crypto.Keccak256([]byte{byte(i)})
+		var sum [32]byte
+		if index%2 == 1 {
+			sum = sha256.Sum256(append(append([]byte{}, branch[i]...), node...))
+		} else {
+			sum = sha256.Sum256(append(append([]byte{}, node...), branch[i]...))
+		}
+		node = sum[:]
+	}
+
+	header := &BeaconBlockHeader{Slot: 1 << 30, ParentRoot: make([]byte, 32), StateRoot: make([]byte, 32), BodyRoot: node}
+	root, err := header.HashTreeRoot()
+	assert.Nil(t, err)
+	assert.Nil(t, NewHeaderStore().StoreFinalizedBlock(db, &FinalizedBlock{
+		Slot:         header.Slot,
+		BeaconRoot:   root,
+		Number:       execution.BlockNumber.Uint64(),
+		Hash:         execution.BlockHash,
+		ReceiptsRoot: execution.ReceiptsRoot,
+	}))
+	return header, branch
+}
+
+func TestVerify_Verify(t *testing.T) {
+	receipts := ethtypes.Receipts{
+		{Type: ethtypes.LegacyTxType, Status: ethtypes.ReceiptStatusSuccessful, CumulativeGasUsed: 21000, Logs: []*ethtypes.Log{}},
+		{Type: ethtypes.DynamicFeeTxType, Status: ethtypes.ReceiptStatusSuccessful, CumulativeGasUsed: 63000, Logs: []*ethtypes.Log{
+			{Address: common.HexToAddress("0xd6199276959b95a68c1ee30e8569f5fe060903a6"), Topics: []common.Hash{{0x01}}, Data: []byte{0x02}},
+		}},
+	}
+	for _, r := range receipts {
+		r.Bloom = ethtypes.CreateBloom(ethtypes.Receipts{r})
+	}
+	receiptsRoot, prove := receiptTrie(t, receipts, 1)
+	wantLogs, err := rlp.EncodeToBytes(receipts[1].Logs)
+	assert.Nil(t, err)
+	router := common.HexToAddress("0xd6199276959b95a68c1ee30e8569f5fe060903a6")
+
+	// the receipt is in the finalized execution block
+	db := finalizedStateDB(t)
+	execution := *update.finalizedExecution
+	execution.BlockNumber = big.NewInt(1000)
+	execution.ReceiptsRoot = receiptsRoot
+	header, branch := finalizeExecution(t, db, &execution)
+	txProve := &TxProve{Header: header, Execution: &execution, ExecutionBranch: branch, Receipt: receipts[1], Prove: prove, TxIndex: 1}
+	input, err := rlp.EncodeToBytes(txProve)
+	assert.Nil(t, err)
+	logs, err := new(Verify).Verify(db, router, input)
+	assert.Nil(t, err)
+	assert.Equal(t, wantLogs, logs)
+
+	// the receipt is in an ancestor of the finalized execution block
+	grandparent, err := rlp.EncodeToBytes(&ethtypes.Header{Number: big.NewInt(998), Difficulty: common.Big0, ReceiptHash: receiptsRoot})
+	assert.Nil(t, err)
+	parent, err := rlp.EncodeToBytes(&ethtypes.Header{ParentHash: crypto.Keccak256Hash(grandparent), Number: big.NewInt(999), Difficulty: common.Big0})
+	assert.Nil(t, err)
+	execution = *update.finalizedExecution
+	execution.BlockNumber = big.NewInt(1000)
+	execution.ParentHash = crypto.Keccak256Hash(parent)
+	header, branch = finalizeExecution(t, db, &execution)
+	txProve = &TxProve{Header: header, Execution: &execution, ExecutionBranch: branch, Receipt: receipts[1], Prove: prove, TxIndex: 1,
+		Ancestors: []rlp.RawValue{grandparent, parent}}
+	input, err = rlp.EncodeToBytes(txProve)
+	assert.Nil(t, err)
+	logs, err = new(Verify).Verify(db, router, input)
+	assert.Nil(t, err)
+	assert.Equal(t, wantLogs, logs)
+
+	// the ancestors are not linked
+	txProve.Ancestors = []rlp.RawValue{grandparent, grandparent}
+	input, err = rlp.EncodeToBytes(txProve)
+	assert.Nil(t, err)
+	_, err = new(Verify).Verify(db, router, input)
+	assert.NotNil(t, err)
+
+	// the receipt is not in the proven block
+	txProve.Ancestors = []rlp.RawValue{parent}
+	input, err = rlp.EncodeToBytes(txProve)
+	assert.Nil(t, err)
+	_, err = new(Verify).Verify(db, router, input)
+	assert.NotNil(t, err)
+}
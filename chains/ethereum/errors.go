@@ -8,4 +8,10 @@ var (
 	errFutureBlock     = errors.New("block in the future")
 	errInvalidNumber   = errors.New("invalid block number")
 	errNotSupportChain = errors.New("not supported chain")
+	errReorgTooDeep    = errors.New("reorg exceeds the max reorg depth")
+
+	errInvalidDifficulty = errors.New("non-zero difficulty after the merge")
+	errInvalidNonce      = errors.New("non-zero nonce after the merge")
+	errInvalidUncleHash  = errors.New("non-empty uncles after the merge")
+	errNotFinalized      = errors.New("header chain does not end at a finalized block")
 )
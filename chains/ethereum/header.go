@@ -5,6 +5,9 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
+
+	"github.com/mapprotocol/atlas/chains"
 )
 
 type Header struct {
@@ -26,12 +29,39 @@ type Header struct {
 
 	// BaseFee was added by EIP-1559 and is ignored in legacy headers.
 	BaseFee *big.Int `json:"baseFeePerGas" rlp:"optional"`
+
+	// WithdrawalsHash was added by EIP-4895 in shanghai.
+	WithdrawalsHash *common.Hash `json:"withdrawalsRoot" rlp:"optional"`
+
+	// BlobGasUsed and ExcessBlobGas were added by EIP-4844 in cancun.
+	BlobGasUsed   *uint64 `json:"blobGasUsed" rlp:"optional"`
+	ExcessBlobGas *uint64 `json:"excessBlobGas" rlp:"optional"`
+
+	// ParentBeaconRoot was added by EIP-4788 in cancun.
+	ParentBeaconRoot *common.Hash `json:"parentBeaconBlockRoot" rlp:"optional"`
+
+	// RequestsHash was added by EIP-7685 in prague.
+	RequestsHash *common.Hash `json:"requestsHash" rlp:"optional"`
 }
 
 func (eh *Header) Hash() common.Hash {
 	return rlpHash(eh)
 }
 
+// Index returns the indexed part of the header, it implements chains.IIndexable.
+func (eh *Header) Index(raw rlp.RawValue) (*chains.IndexedHeader, error) {
+	if eh.Number == nil {
+		return nil, errInvalidNumber
+	}
+	return &chains.IndexedHeader{
+		Number:      eh.Number.Uint64(),
+		Hash:        eh.Hash(),
+		ParentHash:  eh.ParentHash,
+		ReceiptHash: eh.ReceiptHash,
+		Raw:         raw,
+	}, nil
+}
+
 //func (eh *Header) Genesis(chainID uint64) *Header {
 //	genesis := &Header{}
 //	g := GetGenesisByChainID(chainID)
@@ -4,7 +4,6 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
-	"math"
 	"math/big"
 	"time"
 
@@ -20,8 +19,12 @@ import (
 
 const (
 	StoreCacheSize = 20
-	MaxHeaderLimit = 100000
+	MaxHeaderLimit = 100000 // ring buffer size of stores reset without a retention policy
 	SplicingSymbol = "-"
+
+	// MaxHashLookupDepth bounds the heights GetHeaderByHash searches below the head, it
+	// is well past params.DefaultMaxReorgDepth so recent side chains are found.
+	MaxHashLookupDepth = 256
 )
 
 var (
@@ -54,6 +57,17 @@ type HeaderStore struct {
 	CurNumber uint64
 	CurHash   common.Hash
 	//CanonicalNumberToHash []*common.Hash
+
+	// Retention policy, fixed when the store is reset. Stores reset before the header
+	// retention fork leave it empty, so their encoding and layout are unchanged: they
+	// keep the MaxHeaderLimit ring buffer and are never pruned.
+	Window             uint64 `rlp:"optional"`
+	CheckpointInterval uint64 `rlp:"optional"`
+	FinalityDepth      uint64 `rlp:"optional"`
+	PrunedNumber       uint64 `rlp:"optional"` // highest height whose side chain headers have been pruned
+
+	retention  *chains.Retention  // policy applied by the next reset
+	headChange *chains.HeadChange // head change of the last insert, nil if the head did not move
 }
 
 type LightHeader struct {
@@ -79,14 +93,109 @@ func (hs *HeaderStore) canonicalHeaderDbKey(number uint64) common.Hash {
 	return key
 }
 
+func (hs *HeaderStore) checkpointDbKey(number uint64) common.Hash {
+	str := fmt.Sprintf("%s-%d", "checkpoint", number)
+	return common.BytesToHash([]byte(str))
+}
+
 func (hs *HeaderStore) loopIdx(number uint64) uint64 {
-	idx := uint64(math.Mod(float64(number), MaxHeaderLimit))
+	idx := number % hs.window()
 	log.Debug("ReadCanonicalHash loopIdx", "number", number, "idx", idx)
 	return idx
 }
 
-func (hs *HeaderStore) delOldHeaders() {
+// SetRetention sets the retention policy the store is created with by the next reset.
+func (hs *HeaderStore) SetRetention(r *chains.Retention) {
+	hs.retention = r
+}
+
+// hasRetention reports whether the store was reset with a retention policy.
+func (hs *HeaderStore) hasRetention() bool {
+	return hs.Window != 0
+}
 
+// window returns the number of heights kept in the ring buffer.
+func (hs *HeaderStore) window() uint64 {
+	if !hs.hasRetention() {
+		return MaxHeaderLimit
+	}
+	return hs.Window
+}
+
+func (hs *HeaderStore) policy() *chains.Retention {
+	return &chains.Retention{
+		Window:             hs.Window,
+		CheckpointInterval: hs.CheckpointInterval,
+		FinalityDepth:      hs.FinalityDepth,
+	}
+}
+
+// isObsolete reports whether headers of the height can no longer be imported, because
+// the height left the ring buffer or its side chains have already been pruned.
+func (hs *HeaderStore) isObsolete(number uint64) bool {
+	if hs.hasRetention() && number <= hs.PrunedNumber {
+		return true
+	}
+	return number+hs.window() <= hs.CurNumber+1
+}
+
+// dropStale removes the headers of older heights sharing the ring buffer slot of number.
+func dropStale(lh *LightHeader, number uint64) {
+	for key, data := range lh.Headers {
+		header := decodeHeader(data, common.HexToHash(key))
+		if header == nil || header.Number.Uint64() != number {
+			delete(lh.Headers, key)
+			delete(lh.TDs, key)
+		}
+	}
+}
+
+// delOldHeaders prunes the side chain headers and TDs of the heights that fell below
+// the finality depth, and keeps the canonical hash of the checkpoints among them for
+// lookups after they left the window.
+func (hs *HeaderStore) delOldHeaders(db types.StateDB) error {
+	if !hs.hasRetention() || hs.CurNumber < hs.FinalityDepth {
+		return nil
+	}
+	final := hs.CurNumber - hs.FinalityDepth
+	from := hs.PrunedNumber + 1
+	if hs.CurNumber >= hs.window() && from < hs.CurNumber-hs.window()+1 {
+		from = hs.CurNumber - hs.window() + 1
+	}
+
+	policy := hs.policy()
+	for number := from; number <= final; number++ {
+		hash := hs.ReadCanonicalHash(number, db)
+		if hash == (common.Hash{}) {
+			continue
+		}
+		if policy.IsCheckpoint(number) {
+			data, err := rlp.EncodeToBytes(hash)
+			if err != nil {
+				return err
+			}
+			db.SetPOWState(chains.EthereumHeaderStoreAddress, hs.checkpointDbKey(number), data)
+		}
+		lh, err := hs.LoadHeader(number, db)
+		if err != nil {
+			return err
+		}
+		if len(lh.Headers) <= 1 {
+			continue
+		}
+		canonical := &LightHeader{
+			Headers: map[string][]byte{hash.String(): lh.Headers[hash.String()]},
+			TDs:     map[string]*big.Int{hash.String(): lh.TDs[hash.String()]},
+		}
+		log.Debug("prune side chain headers", "number", number, "pruned", len(lh.Headers)-1)
+		if err := hs.StoreHeader(db, number, canonical); err != nil {
+			return err
+		}
+	}
+	if final > hs.PrunedNumber {
+		hs.PrunedNumber = final
+	}
+	return nil
 }
 
 func encodeHeader(header *Header) []byte {
@@ -124,6 +233,13 @@ func (hs *HeaderStore) ResetHeaderStore(state types.StateDB, ethHeaders []byte,
 		CurHash:   hash,
 		CurNumber: number,
 	}
+	if r := hs.retention; r != nil {
+		if err := r.Validate(); err != nil {
+			return err
+		}
+		h.Window, h.CheckpointInterval, h.FinalityDepth = r.Window, r.CheckpointInterval, r.FinalityDepth
+		h.PrunedNumber = number
+	}
 	if err := h.Store(state); err != nil {
 		return err
 	}
@@ -232,8 +348,7 @@ func (hs *HeaderStore) Load(state types.StateDB) (err error) {
 		if err != nil {
 			return err
 		}
-		h = *cp
-		hs.CurHash, hs.CurNumber = h.CurHash, h.CurNumber
+		hs.copyFrom(cp)
 		//hs.CanonicalNumberToHash = h.CanonicalNumberToHash
 		return nil
 	}
@@ -248,11 +363,18 @@ func (hs *HeaderStore) Load(state types.StateDB) (err error) {
 		return err
 	}
 	storeCache.Cache.Add(hash, clone)
-	hs.CurHash, hs.CurNumber = h.CurHash, h.CurNumber
+	hs.copyFrom(&h)
 	//hs.CanonicalNumberToHash = h.CanonicalNumberToHash
 	return nil
 }
 
+// copyFrom sets the persisted fields of the store, keeping the pending retention policy.
+func (hs *HeaderStore) copyFrom(h *HeaderStore) {
+	hs.CurHash, hs.CurNumber = h.CurHash, h.CurNumber
+	hs.Window, hs.CheckpointInterval, hs.FinalityDepth = h.Window, h.CheckpointInterval, h.FinalityDepth
+	hs.PrunedNumber = h.PrunedNumber
+}
+
 func (hs *HeaderStore) LoadHeader(number uint64, db types.StateDB) (lh *LightHeader, err error) {
 	key := hs.headerDbKey(number)
 	address := chains.EthereumHeaderStoreAddress
@@ -307,6 +429,9 @@ func (hs *HeaderStore) WriteHeaderAndTd(hash common.Hash, number uint64, td *big
 	if err != nil {
 		return err
 	}
+	if hs.hasRetention() {
+		dropStale(loadHeader, number)
+	}
 	loadHeader.Headers[hash.String()] = encodeHeader(header)
 	loadHeader.TDs[hash.String()] = td
 	// store
@@ -332,6 +457,15 @@ func (hs *HeaderStore) HasHeader(hash common.Hash, number uint64, db types.State
 }
 
 func (hs *HeaderStore) ReadCanonicalHash(number uint64, db types.StateDB) common.Hash {
+	if hs.hasRetention() {
+		// slots above the head hold heights that left the window
+		if number > hs.CurNumber {
+			return common.Hash{}
+		}
+		if number+hs.window() <= hs.CurNumber {
+			return hs.readCheckpoint(number, db)
+		}
+	}
 	hash, err := hs.LoadCanonicalHash(number, db)
 	if err != nil {
 		log.Error("ReadCanonicalHash failed", "number", number, "err", err)
@@ -339,6 +473,21 @@ func (hs *HeaderStore) ReadCanonicalHash(number uint64, db types.StateDB) common
 	return hash
 }
 
+// readCheckpoint returns the canonical hash of a height that left the window, or the
+// empty hash if the height is not a checkpoint.
+func (hs *HeaderStore) readCheckpoint(number uint64, db types.StateDB) common.Hash {
+	data := db.GetPOWState(chains.EthereumHeaderStoreAddress, hs.checkpointDbKey(number))
+	if len(data) == 0 {
+		return common.Hash{}
+	}
+	var hash common.Hash
+	if err := rlp.DecodeBytes(data, &hash); err != nil {
+		log.Error("ReadCheckpoint failed", "number", number, "err", err)
+		return common.Hash{}
+	}
+	return hash
+}
+
 func (hs *HeaderStore) WriteCanonicalHash(hash common.Hash, number uint64, db types.StateDB) {
 	err := hs.StoreCanonicalHash(db, number, &hash)
 	if err != nil {
@@ -351,6 +500,81 @@ func (hs *HeaderStore) DeleteCanonicalHash(number uint64, db types.StateDB) {
 	hs.WriteCanonicalHash(common.Hash{}, number, db)
 }
 
+// forkPoint returns the header the given chain forks off the canonical chain, or the
+// current head if all of its headers are canonical already.
+func (hs *HeaderStore) forkPoint(headers []*Header, db types.StateDB) (*params.NumberHash, error) {
+	for _, header := range headers {
+		number := header.Number.Uint64()
+		if number <= hs.CurNumber && hs.ReadCanonicalHash(number, db) == header.Hash() {
+			continue
+		}
+		hash, number := header.ParentHash, number-1
+		for hs.ReadCanonicalHash(number, db) != hash {
+			parent := hs.GetHeader(hash, number, db)
+			if parent == nil || number == 0 {
+				return nil, fmt.Errorf("%w, number: %d, hash: %s", errUnknownAncestor, number, hash)
+			}
+			hash, number = parent.ParentHash, number-1
+		}
+		return &params.NumberHash{Number: number, Hash: hash}, nil
+	}
+	// the chain is part of the canonical one
+	return &params.NumberHash{Number: hs.CurNumber, Hash: hs.CurHash}, nil
+}
+
+// reorgDepth returns the number of canonical headers the given chain would rewrite
+// if it became canonical.
+func (hs *HeaderStore) reorgDepth(headers []*Header, db types.StateDB) (uint64, error) {
+	ancestor, err := hs.forkPoint(headers, db)
+	if err != nil {
+		return 0, err
+	}
+	if ancestor.Number >= hs.CurNumber {
+		return 0, nil
+	}
+	return hs.CurNumber - ancestor.Number, nil
+}
+
+// extraWrites returns the entries an insert of the headers writes besides the headers
+// and their canonical hashes: the canonical hashes it deletes above the new head and
+// rewrites down to the fork point if it moves the head off the canonical chain, and the
+// side chains and checkpoints the retention policy prunes as the head advances.
+func (hs *HeaderStore) extraWrites(headers []*Header, db types.StateDB) uint64 {
+	var (
+		first  = headers[0].Number.Uint64()
+		last   = headers[len(headers)-1].Number.Uint64()
+		writes uint64
+	)
+	if headers[0].ParentHash != hs.CurHash {
+		if hs.CurNumber > last {
+			writes += hs.CurNumber - last
+		}
+		if ancestor, err := hs.forkPoint(headers, db); err == nil && first > ancestor.Number+1 {
+			writes += first - 1 - ancestor.Number
+		}
+	}
+	if !hs.hasRetention() || last < hs.FinalityDepth {
+		return writes
+	}
+	final, from := last-hs.FinalityDepth, hs.PrunedNumber+1
+	if last >= hs.window() && from < last-hs.window()+1 {
+		from = last - hs.window() + 1
+	}
+	if final < from {
+		return writes
+	}
+	writes += final - from + 1
+	if interval := hs.CheckpointInterval; interval != 0 {
+		writes += final/interval - (from-1)/interval
+	}
+	return writes
+}
+
+// LastHeadChange returns how the last insert moved the canonical head, nil if it did not.
+func (hs *HeaderStore) LastHeadChange() *chains.HeadChange {
+	return hs.headChange
+}
+
 type headerWriteResult struct {
 	status     WriteStatus
 	ignored    int
@@ -378,6 +602,18 @@ func (hs *HeaderStore) InsertHeaders(db types.StateDB, ethHeaders []byte) ([]*pa
 		context = append(context, []interface{}{"ignored", res.ignored}...)
 	}
 	log.Info("stored new ethereum block headers", context...)
+
+	if change := hs.headChange; change != nil {
+		if depth := change.Depth(); depth > 0 {
+			log.Warn("ethereum header store reorg", "depth", depth,
+				"oldNumber", change.OldHead.Number, "oldHash", change.OldHead.Hash,
+				"newNumber", change.NewHead.Number, "newHash", change.NewHead.Hash,
+				"ancestorNumber", change.Ancestor.Number, "ancestorHash", change.Ancestor.Hash)
+		} else {
+			log.Info("ethereum header store head changed", "number", change.NewHead.Number, "hash", change.NewHead.Hash,
+				"oldNumber", change.OldHead.Number, "oldHash", change.OldHead.Hash)
+		}
+	}
 	return res.imported, err
 }
 
@@ -391,6 +627,7 @@ func (hs *HeaderStore) WriteHeaders(db types.StateDB, ethHeaders []byte) (*heade
 		return &headerWriteResult{}, nil
 	}
 
+	hs.headChange = nil
 	if err := hs.Load(db); err != nil {
 		return &headerWriteResult{}, err
 	}
@@ -450,15 +687,29 @@ func (hs *HeaderStore) WriteHeaders(db types.StateDB, ethHeaders []byte) (*heade
 			reorg = true
 		}
 	}
+	// Proof of stake headers carry no difficulty, they are only accepted up to a block
+	// finalized by the beacon chain, so they always become the canonical chain.
+	if headers[len(headers)-1].Difficulty.Sign() == 0 {
+		reorg = true
+	}
 
 	// If the parent of the (first) block is already the canon header,
 	// we don't have to go backwards to delete canon blocks, but
 	// simply pile them onto the existing chain
 	chainAlreadyCanon := headers[0].ParentHash == hs.CurHash
 	if reorg {
+		ancestor, err := hs.forkPoint(headers, db)
+		if err != nil {
+			return &headerWriteResult{}, err
+		}
+		change := &chains.HeadChange{
+			OldHead:  params.NumberHash{Number: hs.CurNumber, Hash: hs.CurHash},
+			NewHead:  params.NumberHash{Number: lastNumber, Hash: lastHash},
+			Ancestor: *ancestor,
+		}
 		if !chainAlreadyCanon {
 			for i := lastNumber + 1; ; i++ {
-				if i <= hs.CurNumber-MaxHeaderLimit+1 {
+				if hs.isObsolete(i) {
 					log.Info("chainAlreadyCanon=false, obsolete block", "current", hs.CurNumber, "calNumber", i)
 					continue
 				}
@@ -502,9 +753,14 @@ func (hs *HeaderStore) WriteHeaders(db types.StateDB, ethHeaders []byte) (*heade
 			hs.WriteCanonicalHash(hn.Hash, hn.Number, db)
 		}
 
-		hs.delOldHeaders()
 		hs.CurHash = lastHash
 		hs.CurNumber = lastNumber
+		if err := hs.delOldHeaders(db); err != nil {
+			return &headerWriteResult{}, err
+		}
+		if change.NewHead != change.OldHead {
+			hs.headChange = change
+		}
 
 		// Chain status is canonical since this insert was a reorg.
 		// Note that all inserts which have higher TD than existing are 'reorg'.
@@ -539,10 +795,14 @@ func (hs *HeaderStore) GetHeader(hash common.Hash, number uint64, db types.State
 		return nil
 	}
 	data := loadHeader.Headers[hash.String()]
-	if len(data) != 0 {
-		return decodeHeader(data, hash)
+	if len(data) == 0 {
+		return nil
 	}
-	return nil
+	header := decodeHeader(data, hash)
+	if header != nil && hs.hasRetention() && header.Number.Uint64() != number {
+		return nil
+	}
+	return header
 }
 
 func (hs *HeaderStore) GetHeaderByNumber(number uint64, db types.StateDB) *Header {
@@ -550,6 +810,25 @@ func (hs *HeaderStore) GetHeaderByNumber(number uint64, db types.StateDB) *Heade
 	return hs.GetHeader(hash, number, db)
 }
 
+// GetHeaderByHash returns the stored header with the given hash among the
+// MaxHashLookupDepth most recent heights. Headers are indexed by number, so the
+// heights are walked down from the head; older headers are looked up by number.
+func (hs *HeaderStore) GetHeaderByHash(hash common.Hash, db types.StateDB) *Header {
+	depth := uint64(MaxHashLookupDepth)
+	if w := hs.window(); w < depth {
+		depth = w
+	}
+	for number, i := hs.CurNumber, uint64(0); i < depth; number, i = number-1, i+1 {
+		if header := hs.GetHeader(hash, number, db); header != nil && header.Number.Uint64() == number {
+			return header
+		}
+		if number == 0 {
+			break
+		}
+	}
+	return nil
+}
+
 func (hs *HeaderStore) GetCurrentNumberAndHash(db types.StateDB) (uint64, common.Hash, error) {
 	if err := hs.Load(db); err != nil {
 		return 0, common.Hash{}, err
@@ -563,3 +842,9 @@ func (hs *HeaderStore) GetHashByNumber(db types.StateDB, number uint64) (common.
 	}
 	return hs.ReadCanonicalHash(number, db), nil
 }
+
+// DecodeHeaders decodes the headers of an updateBlockHeader input for node-local
+// indexes, it implements chains.IHeaderDecoder.
+func (hs *HeaderStore) DecodeHeaders(input []byte, _ chains.ChainType) ([]*chains.IndexedHeader, error) {
+	return chains.DecodeIndexedHeaders(input, func() chains.IIndexable { return new(Header) })
+}
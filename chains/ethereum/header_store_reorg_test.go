@@ -0,0 +1,147 @@
+package ethereum
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mapprotocol/atlas/chains"
+	"github.com/mapprotocol/atlas/core/types"
+	"github.com/mapprotocol/atlas/params"
+)
+
+func insertForHeadChange(t *testing.T, db types.StateDB, headers []*Header) *chains.HeadChange {
+	data, err := rlp.EncodeToBytes(headers)
+	assert.NoError(t, err)
+	hs := NewHeaderStore()
+	_, err = hs.InsertHeaders(db, data)
+	assert.NoError(t, err)
+	return hs.LastHeadChange()
+}
+
+func TestHeaderStoreHeadChange(t *testing.T) {
+	db := getStateDB()
+	genesis := resetLightChain(t, db, nil)
+
+	chain := makeLightChain(genesis, 8, 1)
+	change := insertForHeadChange(t, db, chain)
+	assert.Equal(t, params.NumberHash{Number: 8, Hash: chain[7].Hash()}, change.NewHead)
+	assert.Equal(t, params.NumberHash{Number: 0, Hash: genesis.Hash()}, change.OldHead)
+	assert.Equal(t, uint64(0), change.Depth())
+
+	// a lighter side chain does not move the head
+	side := makeLightChain(chain[3], 2, 2)
+	assert.Nil(t, insertForHeadChange(t, db, side))
+
+	// a heavier one forking off block 4 rewrites blocks 5 to 8
+	side = append(side, makeLightChain(side[1], 4, 2)...)
+	change = insertForHeadChange(t, db, side[2:])
+	assert.Equal(t, params.NumberHash{Number: 8, Hash: chain[7].Hash()}, change.OldHead)
+	assert.Equal(t, params.NumberHash{Number: 10, Hash: side[5].Hash()}, change.NewHead)
+	assert.Equal(t, params.NumberHash{Number: 4, Hash: chain[3].Hash()}, change.Ancestor)
+	assert.Equal(t, uint64(4), change.Depth())
+
+	hs := NewHeaderStore()
+	assert.NoError(t, hs.Load(db))
+	assert.Equal(t, side[0].Hash(), hs.ReadCanonicalHash(5, db))
+	assert.Equal(t, chain[3].Hash(), hs.ReadCanonicalHash(4, db))
+
+	// side chain headers stay reachable by hash
+	assert.Equal(t, chain[6].Hash(), hs.GetHeaderByHash(chain[6].Hash(), db).Hash())
+	assert.Equal(t, side[4].Hash(), hs.GetHeaderByHash(side[4].Hash(), db).Hash())
+	assert.Nil(t, hs.GetHeaderByHash(makeLightChain(side[5], 1, 3)[0].Hash(), db))
+
+	// known canonical headers rewrite nothing
+	depth, err := hs.reorgDepth(side[:3], db)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(0), depth)
+	depth, err = hs.reorgDepth(makeLightChain(chain[5], 1, 3), db)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(6), depth)
+}
+
+func TestValidateMaxReorgDepth(t *testing.T) {
+	db := getStateDB()
+	genesis := resetLightChain(t, db, nil)
+
+	chain := makeLightChain(genesis, params.DefaultMaxReorgDepth+8, 1)
+	insertForHeadChange(t, db, chain)
+
+	fork := makeLightChain(chain[3], 1, 2)
+	input, err := rlp.EncodeToBytes(fork)
+	assert.NoError(t, err)
+	v := new(Validate)
+	v.SetChainConfig(&params.ChainConfig{ForkChoiceBlock: big.NewInt(10)})
+	v.SetBlockNumber(big.NewInt(10))
+	_, err = v.ValidateHeaderChain(db, input, chains.ChainTypeETH)
+	assert.True(t, errors.Is(err, errReorgTooDeep), "err: %v", err)
+
+	// the depth is not limited before the fork
+	v.SetBlockNumber(big.NewInt(9))
+	_, err = v.ValidateHeaderChain(db, input, chains.ChainTypeETH)
+	assert.False(t, errors.Is(err, errReorgTooDeep), "err: %v", err)
+
+	unknown := makeLightChain(&Header{Number: big.NewInt(20), Difficulty: big.NewInt(1)}, 1, 2)
+	input, err = rlp.EncodeToBytes(unknown)
+	assert.NoError(t, err)
+	_, err = v.ValidateHeaderChain(db, input, chains.ChainTypeETH)
+	assert.True(t, errors.Is(err, errUnknownAncestor), "err: %v", err)
+}
+
+func TestGetHeaderByHashDepth(t *testing.T) {
+	db := getStateDB()
+	genesis := resetLightChain(t, db, nil)
+
+	chain := makeLightChain(genesis, MaxHashLookupDepth+10, 1)
+	insertForHeadChange(t, db, chain)
+
+	hs := NewHeaderStore()
+	assert.NoError(t, hs.Load(db))
+	recent := chain[len(chain)-MaxHashLookupDepth]
+	assert.Equal(t, recent.Hash(), hs.GetHeaderByHash(recent.Hash(), db).Hash())
+
+	// deeper headers are only found by number
+	deep := chain[len(chain)-MaxHashLookupDepth-1]
+	assert.Nil(t, hs.GetHeaderByHash(deep.Hash(), db))
+	assert.Equal(t, deep.Hash(), hs.GetHeaderByNumber(deep.Number.Uint64(), db).Hash())
+}
+
+func TestEstimateWorkStoreWrites(t *testing.T) {
+	db := getStateDB()
+	genesis := resetLightChain(t, db, &chains.Retention{Window: 16, CheckpointInterval: 4, FinalityDepth: 3})
+	chain := makeLightChain(genesis, 8, 1)
+	insertForHeadChange(t, db, chain)
+	side := makeLightChain(chain[3], 6, 2)
+	insertForHeadChange(t, db, side[:2])
+
+	estimate := func(db types.StateDB, headers []*Header) uint64 {
+		input, err := rlp.EncodeToBytes(headers)
+		assert.NoError(t, err)
+		work, err := new(Validate).EstimateWork(db, input)
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(len(headers)), work.PoWSeals)
+		return work.StateWrites
+	}
+	// extending the head prunes the side chains of blocks 6 and 7
+	assert.Equal(t, uint64(2*2+1+2), estimate(db, makeLightChain(chain[7], 2, 1)))
+	// a side chain forking off block 4 rewrites the canonical hashes of blocks 5 and 6
+	assert.Equal(t, uint64(2*4+1+2+2), estimate(db, side[2:]))
+	// a shorter one deletes the canonical hashes of blocks 7 and 8
+	assert.Equal(t, uint64(2*2+1+2), estimate(db, makeLightChain(chain[3], 2, 3)))
+	// passing a checkpoint keeps its canonical hash
+	assert.Equal(t, uint64(2*4+1+4+1), estimate(db, makeLightChain(chain[7], 4, 1)))
+
+	// without the state the headers are priced as extending the head
+	assert.Equal(t, uint64(2*2+1), estimate(nil, makeLightChain(chain[7], 2, 1)))
+	input, err := rlp.EncodeToBytes(makeLightChain(chain[7], 2, 1))
+	assert.NoError(t, err)
+	v := new(Validate)
+	v.SetChainConfig(&params.ChainConfig{HeaderRetentionBlock: big.NewInt(10)})
+	v.SetBlockNumber(big.NewInt(10))
+	work, err := v.EstimateWork(nil, input)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2*2+1+2*2), work.StateWrites)
+}
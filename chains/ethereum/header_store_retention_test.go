@@ -0,0 +1,153 @@
+package ethereum
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mapprotocol/atlas/chains"
+	"github.com/mapprotocol/atlas/core/types"
+	"github.com/mapprotocol/atlas/params"
+)
+
+// makeLightChain creates n headers of difficulty one on top of parent.
+func makeLightChain(parent *Header, n int, seed byte) []*Header {
+	headers := make([]*Header, n)
+	for i := range headers {
+		headers[i] = &Header{
+			ParentHash: parent.Hash(),
+			Coinbase:   common.Address{seed},
+			Difficulty: big.NewInt(1),
+			Number:     new(big.Int).Add(parent.Number, common.Big1),
+			Time:       parent.Time + 1,
+		}
+		parent = headers[i]
+	}
+	return headers
+}
+
+func resetLightChain(t *testing.T, db types.StateDB, r *chains.Retention) *Header {
+	genesis := &Header{Difficulty: big.NewInt(1), Number: big.NewInt(0)}
+	data, err := rlp.EncodeToBytes(genesis)
+	assert.NoError(t, err)
+
+	hs := NewHeaderStore()
+	if r != nil {
+		hs.SetRetention(r)
+	}
+	assert.NoError(t, hs.ResetHeaderStore(db, data, big.NewInt(1)))
+	return genesis
+}
+
+func insertLightHeaders(t *testing.T, db types.StateDB, headers []*Header) {
+	data, err := rlp.EncodeToBytes(headers)
+	assert.NoError(t, err)
+	_, err = NewHeaderStore().InsertHeaders(db, data)
+	assert.NoError(t, err)
+}
+
+func TestHeaderStoreRetention(t *testing.T) {
+	db := getStateDB()
+	genesis := resetLightChain(t, db, &chains.Retention{Window: 16, CheckpointInterval: 4, FinalityDepth: 3})
+
+	chain := makeLightChain(genesis, 40, 1)
+	for _, header := range chain[:6] {
+		insertLightHeaders(t, db, []*Header{header})
+	}
+	// a lighter side chain forking off block 4
+	side := makeLightChain(chain[3], 1, 2)
+	insertLightHeaders(t, db, side)
+	for _, header := range chain[6:] {
+		insertLightHeaders(t, db, []*Header{header})
+	}
+
+	hs := NewHeaderStore()
+	assert.NoError(t, hs.Load(db))
+	assert.Equal(t, uint64(40), hs.CurNumber)
+	assert.Equal(t, uint64(37), hs.PrunedNumber)
+
+	// heights within the window
+	for _, header := range chain[24:] {
+		number := header.Number.Uint64()
+		assert.Equal(t, header.Hash(), hs.ReadCanonicalHash(number, db), "number %d", number)
+		assert.NotNil(t, hs.GetHeaderByNumber(number, db), "number %d", number)
+	}
+	// heights that left the window are only kept as checkpoints
+	for _, header := range chain[:24] {
+		number := header.Number.Uint64()
+		want := common.Hash{}
+		if number%4 == 0 {
+			want = header.Hash()
+		}
+		assert.Equal(t, want, hs.ReadCanonicalHash(number, db), "number %d", number)
+		assert.Nil(t, hs.GetHeader(header.Hash(), number, db), "number %d", number)
+	}
+	assert.Equal(t, common.Hash{}, hs.ReadCanonicalHash(41, db))
+
+	assert.True(t, hs.isObsolete(37))
+	assert.False(t, hs.isObsolete(38))
+}
+
+func TestHeaderStorePruneSideChain(t *testing.T) {
+	db := getStateDB()
+	genesis := resetLightChain(t, db, &chains.Retention{Window: 64, FinalityDepth: 4})
+
+	chain := makeLightChain(genesis, 10, 1)
+	insertLightHeaders(t, db, chain[:6])
+	side := makeLightChain(chain[3], 1, 2)
+	insertLightHeaders(t, db, side)
+
+	hs := NewHeaderStore()
+	assert.NoError(t, hs.Load(db))
+	assert.NotNil(t, hs.GetTd(side[0].Hash(), 5, db))
+
+	insertLightHeaders(t, db, chain[6:])
+	assert.NoError(t, hs.Load(db))
+	assert.Nil(t, hs.GetTd(side[0].Hash(), 5, db))
+	assert.Nil(t, hs.GetHeader(side[0].Hash(), 5, db))
+	assert.Equal(t, chain[4].Hash(), hs.GetHeaderByNumber(5, db).Hash())
+	assert.NotNil(t, hs.GetTd(chain[4].Hash(), 5, db))
+}
+
+func TestHeaderStoreLegacyLayout(t *testing.T) {
+	db := getStateDB()
+	genesis := resetLightChain(t, db, nil)
+
+	hs := NewHeaderStore()
+	assert.NoError(t, hs.Load(db))
+	assert.False(t, hs.hasRetention())
+	assert.Equal(t, uint64(MaxHeaderLimit), hs.window())
+
+	// stores without a retention policy keep their original encoding
+	address := chains.EthereumHeaderStoreAddress
+	legacy, err := rlp.EncodeToBytes(struct {
+		CurNumber uint64
+		CurHash   common.Hash
+	}{0, genesis.Hash()})
+	assert.NoError(t, err)
+	assert.Equal(t, legacy, db.GetPOWState(address, common.BytesToHash(address[:])))
+}
+
+func TestRetentionValidate(t *testing.T) {
+	assert.NoError(t, (&chains.Retention{Window: 10, CheckpointInterval: 5, FinalityDepth: 9}).Validate())
+	assert.Error(t, (&chains.Retention{}).Validate())
+	assert.Error(t, (&chains.Retention{Window: 10}).Validate())
+	assert.Error(t, (&chains.Retention{Window: 10, FinalityDepth: 10}).Validate())
+	assert.Error(t, (&chains.Retention{Window: 10, CheckpointInterval: 11, FinalityDepth: 1}).Validate())
+
+	db := getStateDB()
+	data, err := rlp.EncodeToBytes(&Header{Difficulty: big.NewInt(1), Number: big.NewInt(0)})
+	assert.NoError(t, err)
+	hs := NewHeaderStore()
+	hs.SetRetention(&chains.Retention{Window: 10})
+	assert.Error(t, hs.ResetHeaderStore(db, data, big.NewInt(1)))
+
+	config := &params.ChainConfig{HeaderRetentionBlock: big.NewInt(10)}
+	assert.Nil(t, chains.RetentionAt(config, big.NewInt(9)))
+	r := chains.RetentionAt(config, big.NewInt(10))
+	assert.NoError(t, r.Validate())
+	assert.Equal(t, params.DefaultHeaderRetention.Window, r.Window)
+}
@@ -0,0 +1,318 @@
+package ethereum
+
+import (
+	"fmt"
+	"math/big"
+
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/mapprotocol/atlas/chains"
+	"github.com/mapprotocol/atlas/chains/eth2"
+	"github.com/mapprotocol/atlas/core/types"
+	"github.com/mapprotocol/atlas/params"
+)
+
+const (
+	blobGasPerBlob = 1 << 17 // Gas consumption of a single data blob (== blob byte size)
+	blobBaseCost   = 1 << 13 // Base execution gas cost of a blob, EIP-7918
+)
+
+// blobConfig is the EIP-4844 blob schedule of a network from a timestamp on.
+type blobConfig struct {
+	Time           uint64
+	Target         uint64 // target blobs per block
+	Max            uint64 // max blobs per block
+	UpdateFraction uint64
+}
+
+// mergeSchedule are the timestamp activated forks of a network after the merge that
+// change the header. A nil timestamp means the fork is not scheduled.
+type mergeSchedule struct {
+	ShanghaiTime *uint64
+	CancunTime   *uint64
+	PragueTime   *uint64
+	OsakaTime    *uint64
+
+	// Blobs are ordered by time, the first entry starts at cancun
+	Blobs []blobConfig
+}
+
+func newUint64(v uint64) *uint64 { return &v }
+
+func isTimestampForked(fork *uint64, time uint64) bool {
+	return fork != nil && *fork <= time
+}
+
+func (s *mergeSchedule) isShanghai(time uint64) bool { return isTimestampForked(s.ShanghaiTime, time) }
+func (s *mergeSchedule) isCancun(time uint64) bool   { return isTimestampForked(s.CancunTime, time) }
+func (s *mergeSchedule) isPrague(time uint64) bool   { return isTimestampForked(s.PragueTime, time) }
+func (s *mergeSchedule) isOsaka(time uint64) bool    { return isTimestampForked(s.OsakaTime, time) }
+
+// blobConfig returns the blob schedule active at the given time.
+func (s *mergeSchedule) blobConfig(time uint64) *blobConfig {
+	var active *blobConfig
+	for i := range s.Blobs {
+		if s.Blobs[i].Time <= time {
+			active = &s.Blobs[i]
+		}
+	}
+	return active
+}
+
+// mainnetMergeSchedule is the schedule of Ethereum mainnet, registered for the chain in
+// the ethereum module.
+var mainnetMergeSchedule = &mergeSchedule{
+	ShanghaiTime: newUint64(1_681_338_455),
+	CancunTime:   newUint64(1_710_338_135),
+	PragueTime:   newUint64(1_746_612_311),
+	OsakaTime:    newUint64(1_764_798_551),
+	Blobs: []blobConfig{
+		{Time: 1_710_338_135, Target: 3, Max: 6, UpdateFraction: 3_338_477},    // cancun
+		{Time: 1_746_612_311, Target: 6, Max: 9, UpdateFraction: 5_007_716},    // prague
+		{Time: 1_765_290_071, Target: 10, Max: 15, UpdateFraction: 8_346_193},  // bpo1
+		{Time: 1_767_747_671, Target: 14, Max: 21, UpdateFraction: 11_684_671}, // bpo2
+	},
+}
+
+// ropstenMergeSchedule is the schedule of Ropsten, the network followed as the ethereum
+// test chain, which was shut down after the merge without scheduling shanghai.
+var ropstenMergeSchedule = &mergeSchedule{}
+
+// mergeSchedules are the built-in schedules of the other test networks, by execution
+// chain id.
+var mergeSchedules = map[uint64]*mergeSchedule{
+	5: { // Goerli
+		ShanghaiTime: newUint64(1_678_832_736),
+		CancunTime:   newUint64(1_705_473_120),
+		Blobs: []blobConfig{
+			{Time: 1_705_473_120, Target: 3, Max: 6, UpdateFraction: 3_338_477}, // cancun
+		},
+	},
+	11155111: { // Sepolia
+		ShanghaiTime: newUint64(1_677_557_088),
+		CancunTime:   newUint64(1_706_655_072),
+		PragueTime:   newUint64(1_741_159_776),
+		OsakaTime:    newUint64(1_760_427_360),
+		Blobs: []blobConfig{
+			{Time: 1_706_655_072, Target: 3, Max: 6, UpdateFraction: 3_338_477},    // cancun
+			{Time: 1_741_159_776, Target: 6, Max: 9, UpdateFraction: 5_007_716},    // prague
+			{Time: 1_761_017_184, Target: 10, Max: 15, UpdateFraction: 8_346_193},  // bpo1
+			{Time: 1_761_607_008, Target: 14, Max: 21, UpdateFraction: 11_684_671}, // bpo2
+		},
+	},
+	17000: { // Holesky
+		ShanghaiTime: newUint64(1_696_000_704),
+		CancunTime:   newUint64(1_707_305_664),
+		PragueTime:   newUint64(1_740_434_112),
+		OsakaTime:    newUint64(1_759_308_480),
+		Blobs: []blobConfig{
+			{Time: 1_707_305_664, Target: 3, Max: 6, UpdateFraction: 3_338_477},    // cancun
+			{Time: 1_740_434_112, Target: 6, Max: 9, UpdateFraction: 5_007_716},    // prague
+			{Time: 1_759_800_000, Target: 10, Max: 15, UpdateFraction: 8_346_193},  // bpo1
+			{Time: 1_760_389_824, Target: 14, Max: 21, UpdateFraction: 11_684_671}, // bpo2
+		},
+	},
+}
+
+// loadMergeSchedule returns the post-merge forks of the chain. A schedule listed in the
+// chain config replaces the one registered in the ethereum module, which replaces the
+// built-in one.
+func loadMergeSchedule(config *params.ChainConfig, chainType chains.ChainType) (*mergeSchedule, error) {
+	if config != nil {
+		for _, s := range config.EthMergeSchedules {
+			if s != nil && s.ChainID == uint64(chainType) {
+				return mergeScheduleFromParams(s)
+			}
+		}
+	}
+	if network, err := chains.Network(chains.ChainGroupETH, chainType); err == nil {
+		if s, ok := network.(*mergeSchedule); ok {
+			return s, nil
+		}
+	}
+	s, ok := mergeSchedules[uint64(chainType)]
+	if !ok {
+		return nil, fmt.Errorf("no merge schedule for chain %d", chainType)
+	}
+	return s, nil
+}
+
+func mergeScheduleFromParams(p *params.EthMergeSchedule) (*mergeSchedule, error) {
+	s := &mergeSchedule{
+		ShanghaiTime: p.ShanghaiTime,
+		CancunTime:   p.CancunTime,
+		PragueTime:   p.PragueTime,
+		OsakaTime:    p.OsakaTime,
+		Blobs:        make([]blobConfig, 0, len(p.Blobs)),
+	}
+	// A fork is only scheduled together with the ones before it, and not earlier
+	forks := []*uint64{s.ShanghaiTime, s.CancunTime, s.PragueTime, s.OsakaTime}
+	for i := 1; i < len(forks); i++ {
+		if forks[i] != nil && (forks[i-1] == nil || *forks[i] < *forks[i-1]) {
+			return nil, fmt.Errorf("fork %d is out of order in merge schedule of chain %d", i, p.ChainID)
+		}
+	}
+	for i, b := range p.Blobs {
+		if b.Max == 0 || b.Target > b.Max || b.UpdateFraction == 0 {
+			return nil, fmt.Errorf("invalid blob schedule at time %d of chain %d", b.Time, p.ChainID)
+		}
+		if i > 0 && b.Time <= p.Blobs[i-1].Time {
+			return nil, fmt.Errorf("blob schedule at time %d is out of order in chain %d", b.Time, p.ChainID)
+		}
+		s.Blobs = append(s.Blobs, blobConfig{Time: b.Time, Target: b.Target, Max: b.Max, UpdateFraction: b.UpdateFraction})
+	}
+	if s.CancunTime != nil && (len(s.Blobs) == 0 || s.Blobs[0].Time > *s.CancunTime) {
+		return nil, fmt.Errorf("no blob schedule at cancun of chain %d", p.ChainID)
+	}
+	return s, nil
+}
+
+// verifyMergedHeader checks the fields a proof of stake header sets instead of the
+// proof of work, and the ones added by the forks after the merge.
+func verifyMergedHeader(header, parent *Header, schedule *mergeSchedule) error {
+	if header.Difficulty.Sign() != 0 {
+		return errInvalidDifficulty
+	}
+	if header.Nonce != (ethtypes.BlockNonce{}) {
+		return errInvalidNonce
+	}
+	if header.UncleHash != ethtypes.EmptyUncleHash {
+		return errInvalidUncleHash
+	}
+
+	shanghai := schedule.isShanghai(header.Time)
+	if shanghai && header.WithdrawalsHash == nil {
+		return fmt.Errorf("missing withdrawalsHash")
+	}
+	if !shanghai && header.WithdrawalsHash != nil {
+		return fmt.Errorf("invalid withdrawalsHash: have %x, expected nil", *header.WithdrawalsHash)
+	}
+
+	cancun := schedule.isCancun(header.Time)
+	if cancun {
+		if header.ParentBeaconRoot == nil {
+			return fmt.Errorf("missing parentBeaconRoot")
+		}
+		if err := verifyBlobGas(header, parent, schedule); err != nil {
+			return err
+		}
+	} else {
+		switch {
+		case header.ExcessBlobGas != nil:
+			return fmt.Errorf("invalid excessBlobGas: have %d, expected nil", *header.ExcessBlobGas)
+		case header.BlobGasUsed != nil:
+			return fmt.Errorf("invalid blobGasUsed: have %d, expected nil", *header.BlobGasUsed)
+		case header.ParentBeaconRoot != nil:
+			return fmt.Errorf("invalid parentBeaconRoot, have %x, expected nil", *header.ParentBeaconRoot)
+		}
+	}
+
+	prague := schedule.isPrague(header.Time)
+	if prague && header.RequestsHash == nil {
+		return fmt.Errorf("missing requestsHash")
+	}
+	if !prague && header.RequestsHash != nil {
+		return fmt.Errorf("invalid requestsHash: have %x, expected nil", *header.RequestsHash)
+	}
+	return nil
+}
+
+// verifyBlobGas verifies the blob gas fields of a cancun header, EIP-4844.
+func verifyBlobGas(header, parent *Header, schedule *mergeSchedule) error {
+	if header.ExcessBlobGas == nil {
+		return fmt.Errorf("header is missing excessBlobGas")
+	}
+	if header.BlobGasUsed == nil {
+		return fmt.Errorf("header is missing blobGasUsed")
+	}
+	bcfg := schedule.blobConfig(header.Time)
+	if bcfg == nil {
+		return fmt.Errorf("no blob schedule at time %d", header.Time)
+	}
+	if max := bcfg.Max * blobGasPerBlob; *header.BlobGasUsed > max {
+		return fmt.Errorf("blob gas used %d exceeds maximum allowance %d", *header.BlobGasUsed, max)
+	}
+	if *header.BlobGasUsed%blobGasPerBlob != 0 {
+		return fmt.Errorf("blob gas used %d not a multiple of blob gas per blob %d", *header.BlobGasUsed, blobGasPerBlob)
+	}
+	expected := calcExcessBlobGas(schedule.isOsaka(header.Time), bcfg, parent)
+	if *header.ExcessBlobGas != expected {
+		return fmt.Errorf("invalid excessBlobGas: have %d, want %d, parentExcessBlobGas %d, parentBlobGasUsed %d",
+			*header.ExcessBlobGas, expected, uint64OrZero(parent.ExcessBlobGas), uint64OrZero(parent.BlobGasUsed))
+	}
+	return nil
+}
+
+func uint64OrZero(v *uint64) uint64 {
+	if v == nil {
+		return 0
+	}
+	return *v
+}
+
+// calcExcessBlobGas calculates the excess blob gas of a header from its parent. From
+// osaka on, the blob base fee is bounded by the execution cost of a blob, EIP-7918.
+func calcExcessBlobGas(osaka bool, bcfg *blobConfig, parent *Header) uint64 {
+	var (
+		parentExcessBlobGas = uint64OrZero(parent.ExcessBlobGas)
+		parentBlobGasUsed   = uint64OrZero(parent.BlobGasUsed)
+		excessBlobGas       = parentExcessBlobGas + parentBlobGasUsed
+		targetGas           = bcfg.Target * blobGasPerBlob
+	)
+	if excessBlobGas < targetGas {
+		return 0
+	}
+	if osaka && parent.BaseFee != nil {
+		var (
+			reservePrice = new(big.Int).Mul(parent.BaseFee, big.NewInt(blobBaseCost))
+			blobPrice    = new(big.Int).Mul(calcBlobFee(bcfg, parentExcessBlobGas), big.NewInt(blobGasPerBlob))
+		)
+		if reservePrice.Cmp(blobPrice) > 0 {
+			return parentExcessBlobGas + parentBlobGasUsed*(bcfg.Max-bcfg.Target)/bcfg.Max
+		}
+	}
+	return excessBlobGas - targetGas
+}
+
+// calcBlobFee calculates the blob base fee for the given excess blob gas.
+func calcBlobFee(bcfg *blobConfig, excessBlobGas uint64) *big.Int {
+	return fakeExponential(big.NewInt(1), new(big.Int).SetUint64(excessBlobGas), new(big.Int).SetUint64(bcfg.UpdateFraction))
+}
+
+// fakeExponential approximates factor * e ** (numerator / denominator) using
+// Taylor expansion.
+func fakeExponential(factor, numerator, denominator *big.Int) *big.Int {
+	var (
+		output = new(big.Int)
+		accum  = new(big.Int).Mul(factor, denominator)
+	)
+	for i := 1; accum.Sign() > 0; i++ {
+		output.Add(output, accum)
+
+		accum.Mul(accum, numerator)
+		accum.Div(accum, denominator)
+		accum.Div(accum, big.NewInt(int64(i)))
+	}
+	return output.Div(output, denominator)
+}
+
+// verifyFinalized checks that the header is an execution block finalized by the beacon
+// chain light client. Proof of stake headers carry no seal, a header chain is trusted
+// because it is linked by parent hashes to a block the beacon chain finalized.
+func verifyFinalized(db types.StateDB, header *Header, chainType chains.ChainType) error {
+	beacon := eth2.NewHeaderStore()
+	if err := beacon.Load(db); err != nil {
+		return fmt.Errorf("%w: %v", errNotFinalized, err)
+	}
+	if beacon.ChainID != uint64(chainType) {
+		return fmt.Errorf("%w: the beacon chain light client follows chain %d", errNotFinalized, beacon.ChainID)
+	}
+	block, err := beacon.LoadFinalizedBlock(db, header.Number.Uint64())
+	if err != nil {
+		return err
+	}
+	if block == nil || block.Hash != header.Hash() {
+		return fmt.Errorf("%w, number: %d, hash: %s", errNotFinalized, header.Number, header.Hash())
+	}
+	return nil
+}
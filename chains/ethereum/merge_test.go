@@ -0,0 +1,257 @@
+package ethereum
+
+import (
+	"errors"
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	ethparams "github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mapprotocol/atlas/chains"
+	"github.com/mapprotocol/atlas/chains/eth2"
+	"github.com/mapprotocol/atlas/core/types"
+	"github.com/mapprotocol/atlas/params"
+)
+
+// makeMergedChain creates n proof of stake headers on top of parent, each using the
+// gas target so the base fee stays the same.
+func makeMergedChain(parent *Header, n int) []*Header {
+	headers := make([]*Header, n)
+	for i := range headers {
+		headers[i] = &Header{
+			ParentHash: parent.Hash(),
+			UncleHash:  ethtypes.EmptyUncleHash,
+			Difficulty: new(big.Int),
+			Number:     new(big.Int).Add(parent.Number, common.Big1),
+			GasLimit:   parent.GasLimit,
+			GasUsed:    parent.GasLimit / 2,
+			Time:       parent.Time + 12,
+			BaseFee:    parent.BaseFee,
+		}
+		parent = headers[i]
+	}
+	return headers
+}
+
+func storeFinalized(t *testing.T, db types.StateDB, chainType chains.ChainType, header *Header) {
+	beacon := &eth2.HeaderStore{ChainID: uint64(chainType)}
+	require.NoError(t, beacon.Store(db))
+	require.NoError(t, beacon.StoreFinalizedBlock(db, &eth2.FinalizedBlock{
+		Number: header.Number.Uint64(),
+		Hash:   header.Hash(),
+	}))
+}
+
+func TestValidateMergedHeaderChain(t *testing.T) {
+	db := getStateDB()
+	genesis := &Header{
+		UncleHash:  ethtypes.EmptyUncleHash,
+		Difficulty: new(big.Int),
+		Number:     big.NewInt(20_000_000),
+		GasLimit:   30_000_000,
+		GasUsed:    15_000_000,
+		Time:       1_000,
+		BaseFee:    big.NewInt(ethparams.GWei),
+	}
+	data, err := rlp.EncodeToBytes(genesis)
+	require.NoError(t, err)
+	require.NoError(t, NewHeaderStore().ResetHeaderStore(db, data, big.NewInt(1)))
+
+	chain := makeMergedChain(genesis, 4)
+	input, err := rlp.EncodeToBytes(chain)
+	require.NoError(t, err)
+
+	config := &params.ChainConfig{EthMergeBlock: big.NewInt(10)}
+	newValidate := func(number int64) *Validate {
+		v := new(Validate)
+		v.SetChainConfig(config)
+		v.SetBlockNumber(big.NewInt(number))
+		return v
+	}
+
+	// before the fork headers are checked by ethash
+	_, err = newValidate(9).ValidateHeaderChain(db, input, chains.ChainTypeETHTest)
+	assert.Error(t, err)
+	assert.False(t, errors.Is(err, errNotFinalized), "err: %v", err)
+
+	// the chain must end at a block the beacon chain finalized
+	_, err = newValidate(10).ValidateHeaderChain(db, input, chains.ChainTypeETHTest)
+	assert.True(t, errors.Is(err, errNotFinalized), "err: %v", err)
+
+	storeFinalized(t, db, chains.ChainTypeETHTest, chain[2])
+	_, err = newValidate(10).ValidateHeaderChain(db, input, chains.ChainTypeETHTest)
+	assert.True(t, errors.Is(err, errNotFinalized), "err: %v", err)
+
+	// finalized by a beacon chain following another network
+	storeFinalized(t, db, chains.ChainTypeETH, chain[3])
+	_, err = newValidate(10).ValidateHeaderChain(db, input, chains.ChainTypeETHTest)
+	assert.True(t, errors.Is(err, errNotFinalized), "err: %v", err)
+
+	storeFinalized(t, db, chains.ChainTypeETHTest, chain[3])
+	_, err = newValidate(10).ValidateHeaderChain(db, input, chains.ChainTypeETHTest)
+	assert.NoError(t, err)
+
+	// headers without work still move the head
+	change := insertForHeadChange(t, db, chain)
+	assert.Equal(t, params.NumberHash{Number: chain[3].Number.Uint64(), Hash: chain[3].Hash()}, change.NewHead)
+
+	// a proof of work header is rejected after the fork
+	pow := makeMergedChain(chain[3], 1)
+	pow[0].Difficulty = big.NewInt(1)
+	input, err = rlp.EncodeToBytes(pow)
+	require.NoError(t, err)
+	storeFinalized(t, db, chains.ChainTypeETHTest, pow[0])
+	_, err = newValidate(10).ValidateHeaderChain(db, input, chains.ChainTypeETHTest)
+	assert.True(t, errors.Is(err, errInvalidDifficulty), "err: %v", err)
+}
+
+func TestVerifyMergedHeader(t *testing.T) {
+	hash := common.Hash{0x01}
+	schedule := &mergeSchedule{
+		ShanghaiTime: newUint64(100),
+		CancunTime:   newUint64(200),
+		PragueTime:   newUint64(300),
+		Blobs:        []blobConfig{{Time: 200, Target: 3, Max: 6, UpdateFraction: 3_338_477}},
+	}
+	parent := &Header{Number: big.NewInt(1), Time: 50}
+
+	tests := []struct {
+		name   string
+		modify func(h *Header)
+		err    error // the expected error, if fail is false
+		fail   bool  // any error is expected
+	}{
+		{name: "paris", modify: func(h *Header) {}},
+		{name: "difficulty", modify: func(h *Header) { h.Difficulty = big.NewInt(1) }, err: errInvalidDifficulty},
+		{name: "nonce", modify: func(h *Header) { h.Nonce = ethtypes.EncodeNonce(1) }, err: errInvalidNonce},
+		{name: "uncles", modify: func(h *Header) { h.UncleHash = common.Hash{} }, err: errInvalidUncleHash},
+		{name: "early withdrawals", modify: func(h *Header) { h.WithdrawalsHash = &hash }, fail: true},
+		{name: "shanghai", modify: func(h *Header) { h.Time, h.WithdrawalsHash = 100, &hash }},
+		{name: "missing withdrawals", modify: func(h *Header) { h.Time = 100 }, fail: true},
+		{name: "early blobs", modify: func(h *Header) {
+			h.Time, h.WithdrawalsHash, h.BlobGasUsed = 100, &hash, newUint64(0)
+		}, fail: true},
+		{name: "cancun", modify: func(h *Header) {
+			h.Time, h.WithdrawalsHash, h.ParentBeaconRoot = 200, &hash, &hash
+			h.BlobGasUsed, h.ExcessBlobGas = newUint64(blobGasPerBlob), newUint64(0)
+		}},
+		{name: "missing beacon root", modify: func(h *Header) {
+			h.Time, h.WithdrawalsHash = 200, &hash
+			h.BlobGasUsed, h.ExcessBlobGas = newUint64(0), newUint64(0)
+		}, fail: true},
+		{name: "too many blobs", modify: func(h *Header) {
+			h.Time, h.WithdrawalsHash, h.ParentBeaconRoot = 200, &hash, &hash
+			h.BlobGasUsed, h.ExcessBlobGas = newUint64(7*blobGasPerBlob), newUint64(0)
+		}, fail: true},
+		{name: "wrong excess blob gas", modify: func(h *Header) {
+			h.Time, h.WithdrawalsHash, h.ParentBeaconRoot = 200, &hash, &hash
+			h.BlobGasUsed, h.ExcessBlobGas = newUint64(0), newUint64(1)
+		}, fail: true},
+		{name: "missing requests", modify: func(h *Header) {
+			h.Time, h.WithdrawalsHash, h.ParentBeaconRoot = 300, &hash, &hash
+			h.BlobGasUsed, h.ExcessBlobGas = newUint64(0), newUint64(0)
+		}, fail: true},
+		{name: "prague", modify: func(h *Header) {
+			h.Time, h.WithdrawalsHash, h.ParentBeaconRoot, h.RequestsHash = 300, &hash, &hash, &hash
+			h.BlobGasUsed, h.ExcessBlobGas = newUint64(0), newUint64(0)
+		}},
+	}
+	for _, tt := range tests {
+		header := &Header{
+			UncleHash:  ethtypes.EmptyUncleHash,
+			Difficulty: new(big.Int),
+			Number:     big.NewInt(2),
+			Time:       60,
+		}
+		tt.modify(header)
+		err := verifyMergedHeader(header, parent, schedule)
+		if tt.fail {
+			assert.Error(t, err, tt.name)
+		} else {
+			assert.Equal(t, tt.err, err, tt.name)
+		}
+	}
+}
+
+func TestCalcExcessBlobGas(t *testing.T) {
+	bcfg := &blobConfig{Target: 6, Max: 9, UpdateFraction: 5_007_716}
+
+	parent := &Header{ExcessBlobGas: newUint64(0), BlobGasUsed: newUint64(9 * blobGasPerBlob)}
+	assert.Equal(t, uint64(3*blobGasPerBlob), calcExcessBlobGas(false, bcfg, parent))
+
+	parent = &Header{ExcessBlobGas: newUint64(blobGasPerBlob), BlobGasUsed: newUint64(2 * blobGasPerBlob)}
+	assert.Equal(t, uint64(0), calcExcessBlobGas(false, bcfg, parent))
+
+	// the execution cost bounds the blob price from osaka on, the excess grows by the
+	// scaled usage instead of falling back to the target
+	parent = &Header{
+		ExcessBlobGas: newUint64(0),
+		BlobGasUsed:   newUint64(9 * blobGasPerBlob),
+		BaseFee:       big.NewInt(ethparams.GWei),
+	}
+	assert.Equal(t, uint64(3*blobGasPerBlob), calcExcessBlobGas(true, bcfg, parent))
+	parent.ExcessBlobGas = newUint64(6 * blobGasPerBlob)
+	assert.Equal(t, uint64(6*blobGasPerBlob+3*blobGasPerBlob), calcExcessBlobGas(true, bcfg, parent))
+	assert.Equal(t, uint64(9*blobGasPerBlob), calcExcessBlobGas(false, bcfg, parent))
+
+	assert.Equal(t, big.NewInt(1), calcBlobFee(bcfg, 0))
+}
+
+func TestLoadMergeSchedule(t *testing.T) {
+	s, err := loadMergeSchedule(nil, chains.ChainTypeETH)
+	require.NoError(t, err)
+	assert.Equal(t, mainnetMergeSchedule, s)
+	assert.Equal(t, uint64(15), s.blobConfig(1_765_290_071).Max)
+
+	// ropsten never forked shanghai, its headers are validated with the paris rules
+	s, err = loadMergeSchedule(nil, chains.ChainTypeETHTest)
+	require.NoError(t, err)
+	assert.False(t, s.isShanghai(math.MaxUint64))
+
+	s, err = loadMergeSchedule(nil, 11155111)
+	require.NoError(t, err)
+	assert.True(t, s.isOsaka(1_760_427_360))
+	_, err = loadMergeSchedule(nil, 9_000_001)
+	assert.Error(t, err)
+
+	// a schedule in the chain config replaces the registered one
+	config := &params.ChainConfig{EthMergeSchedules: []*params.EthMergeSchedule{{
+		ChainID:      uint64(chains.ChainTypeETHTest),
+		ShanghaiTime: newUint64(100),
+		CancunTime:   newUint64(200),
+		Blobs: []params.EthBlobSchedule{
+			{Time: 200, Target: 3, Max: 6, UpdateFraction: 3_338_477},
+			{Time: 300, Target: 6, Max: 9, UpdateFraction: 5_007_716},
+		},
+	}}}
+	s, err = loadMergeSchedule(config, chains.ChainTypeETHTest)
+	require.NoError(t, err)
+	assert.True(t, s.isShanghai(100))
+	assert.True(t, s.isCancun(200))
+	assert.False(t, s.isPrague(math.MaxUint64))
+	assert.Equal(t, uint64(9), s.blobConfig(300).Max)
+	s, err = loadMergeSchedule(config, chains.ChainTypeETH)
+	require.NoError(t, err)
+	assert.Equal(t, mainnetMergeSchedule, s)
+
+	invalid := []params.EthMergeSchedule{
+		{CancunTime: newUint64(200), Blobs: []params.EthBlobSchedule{{Time: 200, Target: 3, Max: 6, UpdateFraction: 1}}},
+		{ShanghaiTime: newUint64(300), CancunTime: newUint64(200), Blobs: []params.EthBlobSchedule{{Time: 200, Target: 3, Max: 6, UpdateFraction: 1}}},
+		{ShanghaiTime: newUint64(100), CancunTime: newUint64(200)},
+		{ShanghaiTime: newUint64(100), CancunTime: newUint64(200), Blobs: []params.EthBlobSchedule{{Time: 200, Target: 7, Max: 6, UpdateFraction: 1}}},
+		{ShanghaiTime: newUint64(100), CancunTime: newUint64(200), Blobs: []params.EthBlobSchedule{
+			{Time: 200, Target: 3, Max: 6, UpdateFraction: 1}, {Time: 200, Target: 6, Max: 9, UpdateFraction: 1},
+		}},
+	}
+	for i := range invalid {
+		invalid[i].ChainID = uint64(chains.ChainTypeETH)
+		_, err := loadMergeSchedule(&params.ChainConfig{EthMergeSchedules: []*params.EthMergeSchedule{&invalid[i]}}, chains.ChainTypeETH)
+		assert.Error(t, err, "schedule %d", i)
+	}
+}
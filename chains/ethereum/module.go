@@ -0,0 +1,21 @@
+package ethereum
+
+import (
+	"math/big"
+
+	"github.com/mapprotocol/atlas/chains"
+	"github.com/mapprotocol/atlas/params"
+)
+
+func init() {
+	chains.Register(&chains.Module{
+		Group: chains.ChainGroupETH,
+		Chains: map[chains.ChainType]*chains.ChainParams{
+			chains.ChainTypeETH:     {AtlasChainID: params.MainNetChainID, LondonBlock: big.NewInt(12_965_000), Network: mainnetMergeSchedule},
+			chains.ChainTypeETHTest: {AtlasChainID: params.TestNetChainID, LondonBlock: big.NewInt(10_499_401), Network: ropstenMergeSchedule},
+		},
+		NewValidate:    func() chains.IValidate { return new(Validate) },
+		NewHeaderStore: func() chains.IHeaderStore { return new(HeaderStore) },
+		NewVerify:      func() chains.IVerify { return new(Verify) },
+	})
+}
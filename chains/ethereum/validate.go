@@ -16,13 +16,74 @@ import (
 	"github.com/mapprotocol/atlas/chains"
 	"github.com/mapprotocol/atlas/consensus/misc"
 	"github.com/mapprotocol/atlas/core/types"
+	"github.com/mapprotocol/atlas/params"
 )
 
 const (
 	allowedFutureBlockTimeSeconds = int64(15)
 )
 
-type Validate struct{}
+type Validate struct {
+	chainConfig *params.ChainConfig
+	number      *big.Int // the atlas block the headers are validated in
+}
+
+func (v *Validate) SetChainConfig(config *params.ChainConfig) {
+	v.chainConfig = config
+}
+
+func (v *Validate) SetBlockNumber(number *big.Int) {
+	v.number = number
+}
+
+// isHeaderChainMode reports whether headers are validated as a post-merge header chain
+// anchored to the beacon chain, rather than by their ethash seal.
+func (v *Validate) isHeaderChainMode() bool {
+	return v.chainConfig != nil && v.number != nil && v.chainConfig.IsEthMerge(v.number)
+}
+
+// maxReorgDepth returns the deepest reorg accepted at the atlas block the headers are
+// validated in, zero if it is not limited.
+func (v *Validate) maxReorgDepth() uint64 {
+	if v.chainConfig == nil || v.number == nil {
+		return 0
+	}
+	return v.chainConfig.MaxReorgDepthAt(v.number)
+}
+
+// EstimateWork returns the work of validating and inserting the headers. Every header
+// is stored with its canonical hash, and its ethash seal is verified unless the headers
+// are validated as a header chain. The canonical hashes a reorg deletes and rewrites,
+// and the entries the retention policy prunes, are written as well.
+func (v *Validate) EstimateWork(db types.StateDB, input []byte) (*chains.Work, error) {
+	n, err := chains.CountRLPList(input)
+	if err != nil {
+		return nil, err
+	}
+	work := &chains.Work{Headers: n, StateWrites: 2*n + 1}
+	if !v.isHeaderChainMode() {
+		work.PoWSeals = n
+	}
+	if db == nil {
+		// Extending the head, each new height may prune the side chains of a
+		// height and keep its canonical hash as a checkpoint
+		if v.chainConfig != nil && v.number != nil && v.chainConfig.IsHeaderRetention(v.number) {
+			work.StateWrites += 2 * n
+		}
+		return work, nil
+	}
+	var headers []*Header
+	if err := rlp.DecodeBytes(input, &headers); err != nil || len(headers) == 0 {
+		return work, nil
+	}
+	hs := NewHeaderStore()
+	if err := hs.Load(db); err != nil {
+		// the insert fails before writing anything
+		return work, nil
+	}
+	work.StateWrites += hs.extraWrites(headers, db)
+	return work, nil
+}
 
 func (v *Validate) ValidateHeaderChain(db types.StateDB, headers []byte, chainType chains.ChainType) (int, error) {
 	var chain []*Header
@@ -33,7 +94,7 @@ func (v *Validate) ValidateHeaderChain(db types.StateDB, headers []byte, chainTy
 
 	chainLength := len(chain)
 	if chainLength == 0 {
-		return 0, errors.New("headers cannot be empty")
+		return 0, chains.ErrEmptyHeaders
 	}
 	if chainLength == 1 {
 		if chain[0].Number == nil || chain[0].Difficulty == nil {
@@ -74,10 +135,20 @@ func (v *Validate) ValidateHeaderChain(db types.StateDB, headers []byte, chainTy
 		return 0, fmt.Errorf("non contiguous insert, current number: %d, first number: %d", currentNumber, firstNumber)
 	}
 
-	if firstNumber.Uint64() <= currentNumber-MaxHeaderLimit+1 {
+	if hs.isObsolete(firstNumber.Uint64()) {
 		return 0, fmt.Errorf("obsolete block, current number: %d, first number: %d", currentNumber, firstNumber)
 	}
 
+	if maxDepth := v.maxReorgDepth(); maxDepth != 0 {
+		depth, err := hs.reorgDepth(chain, db)
+		if err != nil {
+			return 0, err
+		}
+		if depth > maxDepth {
+			return 0, fmt.Errorf("%w, depth: %d, max depth: %d", errReorgTooDeep, depth, maxDepth)
+		}
+	}
+
 	abort, results := v.VerifyHeaders(hs, chain, chainType, db)
 	defer close(abort)
 
@@ -87,6 +158,11 @@ func (v *Validate) ValidateHeaderChain(db types.StateDB, headers []byte, chainTy
 		}
 	}
 
+	if v.isHeaderChainMode() {
+		if err := verifyFinalized(db, chain[chainLength-1], chainType); err != nil {
+			return chainLength - 1, err
+		}
+	}
 	return 0, nil
 }
 
@@ -207,6 +283,13 @@ func (v *Validate) verifyHeader(header, parent *Header, uncle bool, unixNow int6
 		return errInvalidNumber
 	}
 
+	if v.isHeaderChainMode() {
+		schedule, err := loadMergeSchedule(v.chainConfig, chainType)
+		if err != nil {
+			return err
+		}
+		return verifyMergedHeader(header, parent, schedule)
+	}
 	if err := VerifySeal(header); err != nil {
 		return err
 	}
@@ -0,0 +1,22 @@
+package chains
+
+import "github.com/mapprotocol/atlas/params"
+
+// HeadChange describes how an insert moved the canonical head of a header store.
+type HeadChange struct {
+	OldHead  params.NumberHash
+	NewHead  params.NumberHash
+	Ancestor params.NumberHash // latest header shared by the old and the new canonical chain
+}
+
+// Depth returns the number of canonical headers rewritten by the insert, zero if the
+// new head extends the old one.
+func (c *HeadChange) Depth() uint64 {
+	return c.OldHead.Number - c.Ancestor.Number
+}
+
+// IForkChoice is implemented by header stores that report the head change caused by
+// their last insert.
+type IForkChoice interface {
+	LastHeadChange() *HeadChange
+}
@@ -0,0 +1,51 @@
+package chains
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// IndexedHeader is the part of a header of a followed chain kept by node-local
+// indexes, such as chainsdb.
+type IndexedHeader struct {
+	Number      uint64
+	Hash        common.Hash
+	ParentHash  common.Hash
+	ReceiptHash common.Hash
+	Raw         []byte // the header as submitted to the header store, nil if it is not a header
+}
+
+// IHeaderDecoder is implemented by header stores whose updateBlockHeader input can be
+// decoded without the state, so the accepted headers can be indexed off-chain.
+type IHeaderDecoder interface {
+	DecodeHeaders(input []byte, chainType ChainType) ([]*IndexedHeader, error)
+}
+
+// IIndexable is implemented by the headers of the chains whose updateBlockHeader input
+// is an rlp list of headers.
+type IIndexable interface {
+	// Index returns the indexed part of the header decoded from raw.
+	Index(raw rlp.RawValue) (*IndexedHeader, error)
+}
+
+// DecodeIndexedHeaders decodes an updateBlockHeader input made of an rlp list of
+// headers, each of them decoded into a value returned by newHeader.
+func DecodeIndexedHeaders(input []byte, newHeader func() IIndexable) ([]*IndexedHeader, error) {
+	var raws []rlp.RawValue
+	if err := rlp.DecodeBytes(input, &raws); err != nil {
+		return nil, ErrRLPDecode
+	}
+	headers := make([]*IndexedHeader, 0, len(raws))
+	for _, raw := range raws {
+		header := newHeader()
+		if err := rlp.DecodeBytes(raw, header); err != nil {
+			return nil, ErrRLPDecode
+		}
+		indexed, err := header.Index(raw)
+		if err != nil {
+			return nil, err
+		}
+		headers = append(headers, indexed)
+	}
+	return headers, nil
+}
@@ -1,21 +1,27 @@
 package interfaces
 
 import (
-	"errors"
 	"math/big"
 
 	"github.com/ethereum/go-ethereum/common"
 
 	"github.com/mapprotocol/atlas/chains"
-	"github.com/mapprotocol/atlas/chains/ethereum"
 	"github.com/mapprotocol/atlas/core/types"
 	"github.com/mapprotocol/atlas/params"
 
+	// light client modules, they register themselves with the chain registry
+	_ "github.com/mapprotocol/atlas/chains/bsc"
+	_ "github.com/mapprotocol/atlas/chains/cosmos"
+	_ "github.com/mapprotocol/atlas/chains/eth2"
+	_ "github.com/mapprotocol/atlas/chains/ethereum"
+	_ "github.com/mapprotocol/atlas/chains/matic"
+	_ "github.com/mapprotocol/atlas/chains/near"
 )
 
 type IChain interface {
 	IValidate
 	IHeaderStore
+	chains.IForkChoice
 }
 
 type Chain struct {
@@ -43,14 +49,55 @@ func (c *Chain) GetHashByNumber(db types.StateDB, number uint64) (common.Hash, e
 	return c.HeaderStore.GetHashByNumber(db, number)
 }
 
-func ChainFactory(group chains.ChainGroup) (IChain, error) {
-	switch group {
-	case chains.ChainGroupETH:
-		return &Chain{
-			Validate:    new(ethereum.Validate),
-			HeaderStore: new(ethereum.HeaderStore),
-		}, nil
+// LastHeadChange returns how the last insert moved the canonical head, nil if it did
+// not or the header store does not report head changes.
+func (c *Chain) LastHeadChange() *chains.HeadChange {
+	if fc, ok := c.HeaderStore.(chains.IForkChoice); ok {
+		return fc.LastHeadChange()
+	}
+	return nil
+}
+
+// SetChainConfig passes the atlas chain config to the validator and the header store
+// if they read it.
+func (c *Chain) SetChainConfig(config *params.ChainConfig) {
+	if cc, ok := c.Validate.(chains.IChainConfigurable); ok {
+		cc.SetChainConfig(config)
+	}
+	if cc, ok := c.HeaderStore.(chains.IChainConfigurable); ok {
+		cc.SetChainConfig(config)
+	}
+}
+
+// SetBlockNumber passes the atlas block the call is executed in to the validator and
+// the header store if their rules depend on it.
+func (c *Chain) SetBlockNumber(number *big.Int) {
+	if bc, ok := c.Validate.(chains.IBlockConfigurable); ok {
+		bc.SetBlockNumber(number)
 	}
+	if bc, ok := c.HeaderStore.(chains.IBlockConfigurable); ok {
+		bc.SetBlockNumber(number)
+	}
+}
+
+// SetBlockTime passes the time of the atlas block the call is executed in to the
+// validator and the header store if they check the age of headers.
+func (c *Chain) SetBlockTime(time uint64) {
+	if cc, ok := c.Validate.(chains.IClockConfigurable); ok {
+		cc.SetBlockTime(time)
+	}
+	if cc, ok := c.HeaderStore.(chains.IClockConfigurable); ok {
+		cc.SetBlockTime(time)
+	}
+}
 
-	return nil, errors.New("not support chain")
+func ChainFactory(group chains.ChainGroup) (IChain, error) {
+	m, err := chains.GetModule(group)
+	if err != nil {
+		return nil, err
+	}
+	return &Chain{
+		Validate:    m.NewValidate(),
+		HeaderStore: m.NewHeaderStore(),
+	}, nil
 }
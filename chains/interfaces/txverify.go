@@ -1,21 +1,34 @@
 package interfaces
 
 import (
-	"github.com/ethereum/go-ethereum/common"
-
 	"github.com/mapprotocol/atlas/chains"
-	"github.com/mapprotocol/atlas/chains/ethereum"
-	"github.com/mapprotocol/atlas/core/types"
 )
 
-type IVerify interface {
-	Verify(db types.StateDB, router common.Address, txProveBytes []byte) (logs []byte, err error)
-}
+type IVerify = chains.IVerify
 
 func VerifyFactory(group chains.ChainGroup) (IVerify, error) {
-	switch group {
-	case chains.ChainGroupETH:
-		return new(ethereum.Verify), nil
+	m, err := chains.GetModule(group)
+	if err != nil {
+		return nil, err
+	}
+	if m.NewVerify == nil {
+		return nil, chains.ErrNotSupportChain
+	}
+	return m.NewVerify(), nil
+}
+
+type IBatchVerify = chains.IBatchVerify
+
+// BatchVerifyFactory returns the batch verifier of the group, chains.ErrBatchUnsupported
+// if its verifier only checks single receipts.
+func BatchVerifyFactory(group chains.ChainGroup) (IBatchVerify, error) {
+	v, err := VerifyFactory(group)
+	if err != nil {
+		return nil, err
+	}
+	bv, ok := v.(IBatchVerify)
+	if !ok {
+		return nil, chains.ErrBatchUnsupported
 	}
-	return nil, chains.ErrNotSupportChain
+	return bv, nil
 }
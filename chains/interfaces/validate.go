@@ -2,23 +2,14 @@ package interfaces
 
 import (
 	"github.com/mapprotocol/atlas/chains"
-	"github.com/mapprotocol/atlas/chains/ethereum"
-	"github.com/mapprotocol/atlas/core/types"
 )
 
-type IValidate interface {
-	ValidateHeaderChain(db types.StateDB, headers []byte, chainType chains.ChainType) (int, error)
-}
+type IValidate = chains.IValidate
 
 func ValidateFactory(group chains.ChainGroup) (IValidate, error) {
-	switch group {
-	case chains.ChainGroupETH:
-		return new(ethereum.Validate), nil
+	m, err := chains.GetModule(group)
+	if err != nil {
+		return nil, err
 	}
-	return nil, chains.ErrNotSupportChain
+	return m.NewValidate(), nil
 }
-
-
-type Validate interface {
-    
-}
\ No newline at end of file
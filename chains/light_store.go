@@ -0,0 +1,205 @@
+package chains
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
+	golru "github.com/hashicorp/golang-lru"
+
+	"github.com/mapprotocol/atlas/core/types"
+	"github.com/mapprotocol/atlas/params"
+	"github.com/mapprotocol/atlas/tools"
+)
+
+const (
+	StoreCacheSize = 20
+	MaxHeaderLimit = 100000
+)
+
+// StateStore persists the state of a light client in the slot of its header store
+// address. Decoded states are cached by the hash of their encoding, callers always
+// get a private copy they can modify freely.
+type StateStore struct {
+	address common.Address
+	cache   *golru.Cache
+}
+
+func NewStateStore(address common.Address) *StateStore {
+	cache, _ := golru.New(StoreCacheSize)
+	return &StateStore{address: address, cache: cache}
+}
+
+func (s *StateStore) key() common.Hash {
+	return common.BytesToHash(s.address[:])
+}
+
+// clone returns a deep copy of the state v points to.
+func clone(v interface{}) (interface{}, error) {
+	dst := reflect.New(reflect.TypeOf(v).Elem()).Interface()
+	if err := tools.DeepCopy(v, dst); err != nil {
+		return nil, err
+	}
+	return dst, nil
+}
+
+// Store writes the state v points to.
+func (s *StateStore) Store(db types.StateDB, v interface{}) error {
+	data, err := rlp.EncodeToBytes(v)
+	if err != nil {
+		log.Error("Failed to RLP encode HeaderStore", "err", err)
+		return err
+	}
+	db.SetPOWState(s.address, s.key(), data)
+
+	cp, err := clone(v)
+	if err != nil {
+		return err
+	}
+	s.cache.Add(tools.RlpHash(data), cp)
+	return nil
+}
+
+// Load reads the stored state into v, it returns false if none was stored yet.
+func (s *StateStore) Load(db types.StateDB, v interface{}) (bool, error) {
+	data := db.GetPOWState(s.address, s.key())
+	if len(data) == 0 {
+		return false, nil
+	}
+
+	hash := tools.RlpHash(data)
+	if cc, ok := s.cache.Get(hash); ok {
+		cp, err := clone(cc)
+		if err != nil {
+			return false, err
+		}
+		reflect.ValueOf(v).Elem().Set(reflect.ValueOf(cp).Elem())
+		return true, nil
+	}
+
+	h := reflect.New(reflect.TypeOf(v).Elem()).Interface()
+	if err := rlp.DecodeBytes(data, h); err != nil {
+		log.Error("HeaderStore RLP decode failed", "err", err)
+		return false, fmt.Errorf("HeaderStore RLP decode failed, error: %s", err.Error())
+	}
+
+	cp, err := clone(h)
+	if err != nil {
+		return false, err
+	}
+	s.cache.Add(hash, cp)
+	reflect.ValueOf(v).Elem().Set(reflect.ValueOf(h).Elem())
+	return true, nil
+}
+
+// LightHeader is the part of a stored header needed to verify receipts.
+type LightHeader struct {
+	Number      uint64
+	Hash        common.Hash
+	ReceiptHash common.Hash
+}
+
+// HeaderRing stores the last Limit headers of a light client, the header of a
+// block is kept until its slot is reused by a block Limit blocks higher.
+type HeaderRing struct {
+	Address common.Address
+	Name    string
+	Limit   uint64
+}
+
+// NewHeaderRing returns the ring of the last MaxHeaderLimit headers of the chain name.
+func NewHeaderRing(address common.Address, name string) *HeaderRing {
+	return &HeaderRing{Address: address, Name: name, Limit: MaxHeaderLimit}
+}
+
+// key is the slot of the header in the ring.
+func (r *HeaderRing) key(number uint64) common.Hash {
+	str := fmt.Sprintf("%s-%d", r.Name, number%r.Limit)
+	return common.BytesToHash([]byte(str))
+}
+
+func (r *HeaderRing) Store(db types.StateDB, header *LightHeader) error {
+	data, err := rlp.EncodeToBytes(header)
+	if err != nil {
+		log.Error("Failed to RLP encode LightHeader", "err", err)
+		return err
+	}
+	db.SetPOWState(r.Address, r.key(header.Number), data)
+	return nil
+}
+
+// Load returns the stored header with the given number, or nil if it was never
+// stored or its slot has been reused since.
+func (r *HeaderRing) Load(db types.StateDB, number uint64) (*LightHeader, error) {
+	data := db.GetPOWState(r.Address, r.key(number))
+	if len(data) == 0 {
+		return nil, nil
+	}
+
+	var header LightHeader
+	if err := rlp.DecodeBytes(data, &header); err != nil {
+		return nil, fmt.Errorf("LightHeader RLP decode failed, error: %s", err.Error())
+	}
+	if header.Number != number {
+		return nil, nil
+	}
+	return &header, nil
+}
+
+// HeaderChain is a light client state advanced header by header, like the
+// authorization snapshot of a proof of authority chain.
+type HeaderChain interface {
+	// ApplyHeader advances the state with the next rlp encoded header of the chain
+	// and returns the part of the header to store.
+	ApplyHeader(raw rlp.RawValue) (*LightHeader, error)
+}
+
+func decodeHeaderList(input []byte) ([]rlp.RawValue, error) {
+	var raws []rlp.RawValue
+	if err := rlp.DecodeBytes(input, &raws); err != nil {
+		log.Error("rlp decode headers failed", "err", err)
+		return nil, ErrRLPDecode
+	}
+	return raws, nil
+}
+
+// ValidateHeaderChain checks that the rlp list of headers in input extends chain,
+// which is advanced in the process. It returns the index of the first invalid header.
+func ValidateHeaderChain(chain HeaderChain, input []byte) (int, error) {
+	raws, err := decodeHeaderList(input)
+	if err != nil {
+		return 0, err
+	}
+	if len(raws) == 0 {
+		return 0, ErrEmptyHeaders
+	}
+	for i, raw := range raws {
+		if _, err := chain.ApplyHeader(raw); err != nil {
+			return i, err
+		}
+	}
+	return 0, nil
+}
+
+// InsertHeaderChain advances chain with the already validated rlp list of headers
+// in input and stores them in ring.
+func InsertHeaderChain(db types.StateDB, chain HeaderChain, ring *HeaderRing, input []byte) ([]*params.NumberHash, error) {
+	raws, err := decodeHeaderList(input)
+	if err != nil {
+		return nil, err
+	}
+	nums := make([]*params.NumberHash, 0, len(raws))
+	for _, raw := range raws {
+		lh, err := chain.ApplyHeader(raw)
+		if err != nil {
+			return nil, err
+		}
+		if err := ring.Store(db, lh); err != nil {
+			return nil, err
+		}
+		nums = append(nums, &params.NumberHash{Number: lh.Number, Hash: lh.Hash})
+	}
+	return nums, nil
+}
@@ -0,0 +1,77 @@
+package matic
+
+import (
+	"math/big"
+
+	"github.com/mapprotocol/atlas/chains"
+)
+
+// Config are the bor parameters of a Polygon PoS network.
+type Config struct {
+	ChainID       *big.Int
+	Sprint        uint64   // Number of blocks in a sprint before Delhi
+	DelhiSprint   uint64   // Number of blocks in a sprint since Delhi
+	DelhiBlock    *big.Int // Sprint length is changed
+	JaipurBlock   *big.Int // Base fee is part of the seal hash
+	NapoliBlock   *big.Int // Extra data carries rlp encoded validator bytes
+	Confirmations uint64   // Number of blocks on top of a block before its receipts can be proven
+}
+
+var mainnetConfig = &Config{
+	ChainID:       big.NewInt(137),
+	Sprint:        64,
+	DelhiSprint:   16,
+	DelhiBlock:    big.NewInt(38_189_056),
+	JaipurBlock:   big.NewInt(23_850_000),
+	NapoliBlock:   big.NewInt(54_876_000),
+	Confirmations: 128,
+}
+
+var testnetConfig = &Config{
+	ChainID:       big.NewInt(80002),
+	Sprint:        16,
+	DelhiSprint:   16,
+	DelhiBlock:    big.NewInt(0),
+	JaipurBlock:   big.NewInt(0),
+	NapoliBlock:   big.NewInt(5_423_600),
+	Confirmations: 128,
+}
+
+// getConfig returns the network configuration registered for the chain.
+func getConfig(chainID uint64) (*Config, error) {
+	network, err := chains.Network(chains.ChainGroupMatic, chains.ChainType(chainID))
+	if err != nil {
+		return nil, err
+	}
+	c, ok := network.(*Config)
+	if !ok {
+		return nil, chains.ErrNotSupportChain
+	}
+	return c, nil
+}
+
+func isForked(s *big.Int, number uint64) bool {
+	return s != nil && s.Cmp(new(big.Int).SetUint64(number)) <= 0
+}
+
+func (c *Config) IsJaipur(number uint64) bool {
+	return isForked(c.JaipurBlock, number)
+}
+
+func (c *Config) IsNapoli(number uint64) bool {
+	return isForked(c.NapoliBlock, number)
+}
+
+// CalculateSprint returns the sprint length at the given block.
+func (c *Config) CalculateSprint(number uint64) uint64 {
+	if isForked(c.DelhiBlock, number) {
+		return c.DelhiSprint
+	}
+	return c.Sprint
+}
+
+// IsSprintEnd returns whether the block is the last one of its sprint, whose extra data
+// carries the producers of the next sprint.
+func (c *Config) IsSprintEnd(number uint64) bool {
+	return (number+1)%c.CalculateSprint(number) == 0
+}
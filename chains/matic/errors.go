@@ -0,0 +1,19 @@
+package matic
+
+import "errors"
+
+var (
+	errNotInitialized     = errors.New("please initialize header store")
+	errMissingVanity      = errors.New("extra-data 32 byte vanity prefix missing")
+	errMissingSignature   = errors.New("extra-data 65 byte signature suffix missing")
+	errInvalidValidators  = errors.New("invalid validator list in sprint end block")
+	errExtraValidators    = errors.New("non-sprint-end block contains validator list")
+	errUnknownAncestor    = errors.New("unknown ancestor")
+	errInvalidNumber      = errors.New("invalid block number")
+	errOlderBlockTime     = errors.New("timestamp older than parent")
+	errUnauthorizedSigner = errors.New("unauthorized signer")
+	errWrongDifficulty    = errors.New("wrong difficulty")
+	errNotSprintEnd       = errors.New("light client must be initialized with a sprint end block")
+	errNotConfirmed       = errors.New("block is not confirmed")
+	errUnknownBlock       = errors.New("block is not stored in the light client")
+)
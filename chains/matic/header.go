@@ -0,0 +1,162 @@
+package matic
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+	"golang.org/x/crypto/sha3"
+
+	"github.com/mapprotocol/atlas/chains"
+)
+
+const (
+	extraVanity = 32 // Fixed number of extra-data prefix bytes reserved for signer vanity
+	extraSeal   = 65 // Fixed number of extra-data suffix bytes reserved for signer seal
+
+	validatorBytesLength = common.AddressLength + 20 // address and voting power
+)
+
+// Header is a Polygon PoS block header.
+type Header struct {
+	ParentHash  common.Hash      `json:"parentHash"       gencodec:"required"`
+	UncleHash   common.Hash      `json:"sha3Uncles"       gencodec:"required"`
+	Coinbase    common.Address   `json:"miner"            gencodec:"required"`
+	Root        common.Hash      `json:"stateRoot"        gencodec:"required"`
+	TxHash      common.Hash      `json:"transactionsRoot" gencodec:"required"`
+	ReceiptHash common.Hash      `json:"receiptsRoot"     gencodec:"required"`
+	Bloom       types.Bloom      `json:"logsBloom"        gencodec:"required"`
+	Difficulty  *big.Int         `json:"difficulty"       gencodec:"required"`
+	Number      *big.Int         `json:"number"           gencodec:"required"`
+	GasLimit    uint64           `json:"gasLimit"         gencodec:"required"`
+	GasUsed     uint64           `json:"gasUsed"          gencodec:"required"`
+	Time        uint64           `json:"timestamp"        gencodec:"required"`
+	Extra       []byte           `json:"extraData"        gencodec:"required"`
+	MixDigest   common.Hash      `json:"mixHash"`
+	Nonce       types.BlockNonce `json:"nonce"`
+
+	// BaseFee was added by EIP-1559 and is ignored in legacy headers.
+	BaseFee *big.Int `json:"baseFeePerGas" rlp:"optional"`
+}
+
+// Validator is a block producer of a span.
+type Validator struct {
+	Address     common.Address
+	VotingPower uint64
+}
+
+// blockExtraData is the extra data layout between vanity and seal since Napoli.
+type blockExtraData struct {
+	ValidatorBytes []byte
+	TxDependency   [][]uint64
+}
+
+func (h *Header) Hash() common.Hash {
+	return rlpHash(h)
+}
+
+// Index returns the indexed part of the header, it implements chains.IIndexable.
+func (h *Header) Index(raw rlp.RawValue) (*chains.IndexedHeader, error) {
+	if h.Number == nil {
+		return nil, errInvalidNumber
+	}
+	return &chains.IndexedHeader{
+		Number:      h.Number.Uint64(),
+		Hash:        h.Hash(),
+		ParentHash:  h.ParentHash,
+		ReceiptHash: h.ReceiptHash,
+		Raw:         raw,
+	}, nil
+}
+
+func rlpHash(x interface{}) (h common.Hash) {
+	hw := sha3.NewLegacyKeccak256()
+	rlp.Encode(hw, x)
+	hw.Sum(h[:0])
+	return h
+}
+
+// SealHash returns the hash of a block prior to it being sealed.
+func SealHash(header *Header, config *Config) common.Hash {
+	enc := []interface{}{
+		header.ParentHash,
+		header.UncleHash,
+		header.Coinbase,
+		header.Root,
+		header.TxHash,
+		header.ReceiptHash,
+		header.Bloom,
+		header.Difficulty,
+		header.Number,
+		header.GasLimit,
+		header.GasUsed,
+		header.Time,
+		header.Extra[:len(header.Extra)-extraSeal], // Yes, this will panic if extra is too short
+		header.MixDigest,
+		header.Nonce,
+	}
+	if config.IsJaipur(header.Number.Uint64()) && header.BaseFee != nil {
+		enc = append(enc, header.BaseFee)
+	}
+	return rlpHash(enc)
+}
+
+// ecrecover extracts the block producer address from a signed header.
+func ecrecover(header *Header, config *Config) (common.Address, error) {
+	if len(header.Extra) < extraSeal {
+		return common.Address{}, errMissingSignature
+	}
+	signature := header.Extra[len(header.Extra)-extraSeal:]
+
+	pubkey, err := crypto.Ecrecover(SealHash(header, config).Bytes(), signature)
+	if err != nil {
+		return common.Address{}, err
+	}
+	var signer common.Address
+	copy(signer[:], crypto.Keccak256(pubkey[1:])[12:])
+	return signer, nil
+}
+
+// validatorBytes returns the producer list carried in the extra data of a header.
+func validatorBytes(header *Header, config *Config) ([]byte, error) {
+	if len(header.Extra) < extraVanity {
+		return nil, errMissingVanity
+	}
+	if len(header.Extra) < extraVanity+extraSeal {
+		return nil, errMissingSignature
+	}
+	data := header.Extra[extraVanity : len(header.Extra)-extraSeal]
+	if !config.IsNapoli(header.Number.Uint64()) {
+		return data, nil
+	}
+
+	var extra blockExtraData
+	if err := rlp.DecodeBytes(data, &extra); err != nil {
+		return nil, errInvalidValidators
+	}
+	return extra.ValidatorBytes, nil
+}
+
+// parseValidators returns the producers of the next sprint, in the order of the
+// extra data of the sprint end block.
+func parseValidators(header *Header, config *Config) ([]Validator, error) {
+	data, err := validatorBytes(header, config)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 || len(data)%validatorBytesLength != 0 {
+		return nil, errInvalidValidators
+	}
+
+	validators := make([]Validator, len(data)/validatorBytesLength)
+	for i := range validators {
+		entry := data[i*validatorBytesLength : (i+1)*validatorBytesLength]
+		validators[i] = Validator{
+			Address:     common.BytesToAddress(entry[:common.AddressLength]),
+			VotingPower: new(big.Int).SetBytes(entry[common.AddressLength:]).Uint64(),
+		}
+	}
+	return validators, nil
+}
@@ -0,0 +1,127 @@
+package matic
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mapprotocol/atlas/chains/chainstest"
+)
+
+func testSets() []*producerSet {
+	return []*producerSet{
+		newProducerSet("span0", 3),
+		newProducerSet("span0", 3),
+		newProducerSet("span1", 4),
+	}
+}
+
+func TestHeaderStore_InsertHeaders(t *testing.T) {
+	sets := testSets()
+	headers := makeChain(20, sets, map[uint64]bool{5: true, 6: true, 13: true}, nil)
+	db := chainstest.NewStore(t, NewHeaderStore(), &resetInput{ChainID: uint64(testChainType), Header: headers[0]})
+
+	v := new(Validate)
+	for _, batch := range [][]*Header{headers[1:6], headers[6:13], headers[13:]} {
+		_, err := v.ValidateHeaderChain(db, chainstest.EncodeRLP(batch), testChainType)
+		assert.NoError(t, err)
+		nums, err := NewHeaderStore().InsertHeaders(db, chainstest.EncodeRLP(batch))
+		assert.NoError(t, err)
+		assert.Equal(t, len(batch), len(nums))
+	}
+
+	hs := NewHeaderStore()
+	number, hash, err := hs.GetCurrentNumberAndHash(db)
+	assert.NoError(t, err)
+	assert.Equal(t, headers[20].Number.Uint64(), number)
+	assert.Equal(t, headers[20].Hash(), hash)
+	assert.Equal(t, sets[2].validators, hs.Snapshot.Validators)
+
+	for _, h := range headers {
+		got, err := hs.GetHashByNumber(db, h.Number.Uint64())
+		assert.NoError(t, err)
+		assert.Equal(t, h.Hash(), got)
+	}
+}
+
+func TestHeaderStore_ResetNotSprintEnd(t *testing.T) {
+	headers := makeChain(1, testSets(), nil, nil)
+	err := NewHeaderStore().ResetHeaderStore(chainstest.NewStateDB(), chainstest.EncodeRLP(&resetInput{ChainID: uint64(testChainType), Header: headers[1]}), nil)
+	assert.Equal(t, errNotSprintEnd, err)
+}
+
+func TestValidate_InvalidHeaders(t *testing.T) {
+	sets := testSets()
+	producers := sets[0]
+	outsider, _ := crypto.ToECDSA(crypto.Keccak256([]byte("outsider")))
+	signerOf := func(h *Header) common.Address {
+		signer, _ := ecrecover(h, testConfig)
+		return signer
+	}
+
+	tests := []struct {
+		name   string
+		index  int
+		modify func(h *Header)
+		want   error
+	}{
+		{
+			name:   "unauthorized signer",
+			modify: func(h *Header) { sealHeader(h, outsider) },
+			want:   errUnauthorizedSigner,
+		},
+		{
+			name:  "primary changes within a sprint",
+			index: 1,
+			modify: func(h *Header) {
+				// sealed by the primary, but claims to be the first backup
+				key := producers.keys[signerOf(h)]
+				h.Difficulty = big.NewInt(2)
+				sealHeader(h, key)
+			},
+			want: errWrongDifficulty,
+		},
+		{
+			name: "difficulty out of range",
+			modify: func(h *Header) {
+				key := producers.keys[signerOf(h)]
+				h.Difficulty = big.NewInt(4)
+				sealHeader(h, key)
+			},
+			want: errWrongDifficulty,
+		},
+		{
+			name: "validators in non sprint end block",
+			modify: func(h *Header) {
+				key := producers.keys[signerOf(h)]
+				h.Extra = makeExtra(h.Number.Uint64(), producers)
+				sealHeader(h, key)
+			},
+			want: errExtraValidators,
+		},
+		{
+			name: "unknown ancestor",
+			modify: func(h *Header) {
+				key := producers.keys[signerOf(h)]
+				h.ParentHash = common.Hash{0x01}
+				sealHeader(h, key)
+			},
+			want: errUnknownAncestor,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			headers := makeChain(3, sets, nil, nil)
+			db := chainstest.NewStore(t, NewHeaderStore(), &resetInput{ChainID: uint64(testChainType), Header: headers[0]})
+			tt.modify(headers[tt.index+1])
+
+			i, err := new(Validate).ValidateHeaderChain(db, chainstest.EncodeRLP(headers[1:]), testChainType)
+			assert.True(t, errors.Is(err, tt.want), "got %v, want %v", err, tt.want)
+			assert.Equal(t, tt.index, i)
+		})
+	}
+}
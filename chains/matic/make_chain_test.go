@@ -0,0 +1,129 @@
+package matic
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+
+	"github.com/mapprotocol/atlas/chains"
+	"github.com/mapprotocol/atlas/chains/chainstest"
+)
+
+const testChainType chains.ChainType = 1_000_137
+
+var testConfig = &Config{
+	ChainID:       big.NewInt(1_000_137),
+	Sprint:        4,
+	DelhiSprint:   4,
+	DelhiBlock:    big.NewInt(0),
+	JaipurBlock:   big.NewInt(0),
+	NapoliBlock:   big.NewInt(12),
+	Confirmations: 4,
+}
+
+func init() {
+	chainstest.RegisterChain(chains.ChainGroupMatic, testChainType, testConfig)
+}
+
+// producerSet is a span producer set with the keys of its members.
+type producerSet struct {
+	validators []Validator
+	keys       map[common.Address]*ecdsa.PrivateKey
+}
+
+func newProducerSet(seed string, n int) *producerSet {
+	s := &producerSet{keys: make(map[common.Address]*ecdsa.PrivateKey)}
+	for i, key := range chainstest.ECDSAKeys(seed, n) {
+		addr := crypto.PubkeyToAddress(key.PublicKey)
+		s.keys[addr] = key
+		s.validators = append(s.validators, Validator{Address: addr, VotingPower: uint64(10 * (i + 1))})
+	}
+	return s
+}
+
+func (s *producerSet) validatorBytes() []byte {
+	var data []byte
+	for _, v := range s.validators {
+		data = append(data, v.Address.Bytes()...)
+		data = append(data, common.LeftPadBytes(new(big.Int).SetUint64(v.VotingPower).Bytes(), 20)...)
+	}
+	return data
+}
+
+func makeExtra(number uint64, next *producerSet) []byte {
+	var data []byte
+	if next != nil {
+		data = next.validatorBytes()
+	}
+	if testConfig.IsNapoli(number) {
+		data, _ = rlp.EncodeToBytes(&blockExtraData{ValidatorBytes: data, TxDependency: [][]uint64{}})
+	}
+	extra := append(make([]byte, extraVanity), data...)
+	return append(extra, make([]byte, extraSeal)...)
+}
+
+func sealHeader(header *Header, key *ecdsa.PrivateKey) {
+	sig, err := crypto.Sign(SealHash(header, testConfig).Bytes(), key)
+	if err != nil {
+		panic(err)
+	}
+	copy(header.Extra[len(header.Extra)-extraSeal:], sig)
+}
+
+// makeChain creates the sprint end block 3 announcing sets[0] and n blocks on top
+// of it. The sprint end block of sprint k announces sets[k+1] (the last set once the
+// schedule is exhausted). Blocks in backups are sealed by the first backup producer.
+func makeChain(n int, sets []*producerSet, backups map[uint64]bool, receiptHashes map[uint64]common.Hash) []*Header {
+	var (
+		sprint  = testConfig.Sprint
+		first   = sprint - 1
+		headers = make([]*Header, 0, n+1)
+		current *producerSet
+		parent  common.Hash
+	)
+	for number := first; number <= first+uint64(n); number++ {
+		header := &Header{
+			ParentHash:  parent,
+			UncleHash:   ethtypes.EmptyUncleHash,
+			ReceiptHash: receiptHashes[number],
+			Number:      new(big.Int).SetUint64(number),
+			GasLimit:    30_000_000,
+			Time:        1_700_000_000 + 2*number,
+			BaseFee:     big.NewInt(30_000_000_000),
+		}
+		var next *producerSet
+		if testConfig.IsSprintEnd(number) {
+			idx := int(number / sprint)
+			if idx >= len(sets) {
+				idx = len(sets) - 1
+			}
+			next = sets[idx]
+		}
+		header.Extra = makeExtra(number, next)
+
+		signer := next
+		if current != nil {
+			size := uint64(len(current.validators))
+			primary := (number / sprint) % size
+			succession := uint64(0)
+			if backups[number] {
+				succession = 1
+			}
+			header.Difficulty = new(big.Int).SetUint64(size - succession)
+			sealHeader(header, current.keys[current.validators[(primary+succession)%size].Address])
+		} else {
+			header.Difficulty = big.NewInt(1)
+			sealHeader(header, signer.keys[signer.validators[0].Address])
+		}
+		headers = append(headers, header)
+		parent = header.Hash()
+		if next != nil {
+			current = next
+		}
+	}
+	return headers
+}
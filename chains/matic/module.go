@@ -0,0 +1,22 @@
+package matic
+
+import (
+	"math/big"
+
+	"github.com/mapprotocol/atlas/chains"
+	"github.com/mapprotocol/atlas/params"
+)
+
+func init() {
+	chains.Register(&chains.Module{
+		Group: chains.ChainGroupMatic,
+		Chains: map[chains.ChainType]*chains.ChainParams{
+			chains.ChainTypeMatic:     {AtlasChainID: params.MainNetChainID, LondonBlock: big.NewInt(23_850_000), Network: mainnetConfig},
+			chains.ChainTypeMaticTest: {AtlasChainID: params.TestNetChainID, LondonBlock: big.NewInt(0), Network: testnetConfig},
+		},
+		ForkBlock:      func(config *params.ChainConfig) *big.Int { return config.MaticBlock },
+		NewValidate:    func() chains.IValidate { return new(Validate) },
+		NewHeaderStore: func() chains.IHeaderStore { return new(HeaderStore) },
+		NewVerify:      func() chains.IVerify { return new(Verify) },
+	})
+}
@@ -0,0 +1,128 @@
+package matic
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
+
+	"github.com/mapprotocol/atlas/chains"
+)
+
+// Snapshot is the bor authorization state at the head of the light client.
+type Snapshot struct {
+	Number     uint64
+	Hash       common.Hash
+	Time       uint64
+	Validators []Validator
+	// Proposer is the primary producer of the current sprint, derived from the
+	// difficulty of the first block seen in the sprint.
+	Proposer       common.Address
+	ProposerSprint uint64
+}
+
+func (s *Snapshot) indexOf(signer common.Address) int {
+	for i, v := range s.Validators {
+		if v.Address == signer {
+			return i
+		}
+	}
+	return -1
+}
+
+// apply advances the snapshot with the next header of the chain.
+func (s *Snapshot) apply(header *Header, config *Config) error {
+	number := header.Number.Uint64()
+	if number != s.Number+1 {
+		return errInvalidNumber
+	}
+	if header.ParentHash != s.Hash {
+		return errUnknownAncestor
+	}
+	if header.Time < s.Time {
+		return errOlderBlockTime
+	}
+
+	signer, err := ecrecover(header, config)
+	if err != nil {
+		return err
+	}
+	idx := s.indexOf(signer)
+	if idx < 0 {
+		return fmt.Errorf("%w: %v", errUnauthorizedSigner, signer)
+	}
+	if err := s.verifyDifficulty(header, idx, config); err != nil {
+		return err
+	}
+
+	if config.IsSprintEnd(number) {
+		validators, err := parseValidators(header, config)
+		if err != nil {
+			return err
+		}
+		s.Validators = validators
+		s.Proposer, s.ProposerSprint = common.Address{}, 0
+	} else {
+		data, err := validatorBytes(header, config)
+		if err != nil {
+			return err
+		}
+		if len(data) != 0 {
+			return errExtraValidators
+		}
+	}
+
+	s.Number, s.Hash, s.Time = number, header.Hash(), header.Time
+	return nil
+}
+
+// verifyDifficulty checks that the difficulty is the succession of the signer after
+// the primary producer of the sprint, the same primary for all blocks of a sprint.
+func (s *Snapshot) verifyDifficulty(header *Header, idx int, config *Config) error {
+	n := uint64(len(s.Validators))
+	if header.Difficulty == nil || !header.Difficulty.IsUint64() {
+		return errWrongDifficulty
+	}
+	difficulty := header.Difficulty.Uint64()
+	if difficulty == 0 || difficulty > n {
+		return errWrongDifficulty
+	}
+
+	succession := n - difficulty
+	proposer := s.Validators[(uint64(idx)+n-succession)%n].Address
+
+	number := header.Number.Uint64()
+	sprintStart := number - number%config.CalculateSprint(number)
+	if s.Proposer == (common.Address{}) || s.ProposerSprint != sprintStart {
+		s.Proposer, s.ProposerSprint = proposer, sprintStart
+		return nil
+	}
+	if proposer != s.Proposer {
+		return errWrongDifficulty
+	}
+	return nil
+}
+
+// snapshotChain advances a snapshot with the headers of the chain, it implements
+// chains.HeaderChain.
+type snapshotChain struct {
+	snap   *Snapshot
+	config *Config
+}
+
+func (c *snapshotChain) ApplyHeader(raw rlp.RawValue) (*chains.LightHeader, error) {
+	var header Header
+	if err := rlp.DecodeBytes(raw, &header); err != nil {
+		log.Error("rlp decode matic header failed", "err", err)
+		return nil, chains.ErrRLPDecode
+	}
+	if header.Number == nil {
+		return nil, errInvalidNumber
+	}
+	if err := c.snap.apply(&header, c.config); err != nil {
+		log.Warn("invalid matic header", "number", header.Number, "hash", header.Hash(), "err", err)
+		return nil, err
+	}
+	return &chains.LightHeader{Number: c.snap.Number, Hash: c.snap.Hash, ReceiptHash: header.ReceiptHash}, nil
+}
@@ -0,0 +1,36 @@
+package matic
+
+import (
+	"github.com/mapprotocol/atlas/chains"
+	"github.com/mapprotocol/atlas/core/types"
+)
+
+type Validate struct{}
+
+// ValidateHeaderChain checks that the headers extend the light client head and are
+// signed by the producers of their sprint. It returns the index of the first invalid header.
+func (v *Validate) ValidateHeaderChain(db types.StateDB, input []byte, chainType chains.ChainType) (int, error) {
+	hs := NewHeaderStore()
+	if err := hs.Load(db); err != nil {
+		return 0, err
+	}
+	if chains.ChainType(hs.ChainID) != chainType {
+		return 0, chains.ErrNotSupportChain
+	}
+	chain, err := hs.headerChain()
+	if err != nil {
+		return 0, err
+	}
+	// the loaded store is a private copy, so the snapshot can be advanced freely
+	return chains.ValidateHeaderChain(chain, input)
+}
+
+// EstimateWork returns the work of validating and inserting the headers, a recovered
+// seal per header and a store entry per header besides the store itself.
+func (v *Validate) EstimateWork(_ types.StateDB, input []byte) (*chains.Work, error) {
+	n, err := chains.CountRLPList(input)
+	if err != nil {
+		return nil, err
+	}
+	return &chains.Work{Headers: n, Signatures: n, StateWrites: n + 1}, nil
+}
@@ -0,0 +1,50 @@
+package matic
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/mapprotocol/atlas/chains"
+	"github.com/mapprotocol/atlas/core/types"
+)
+
+type TxProve = chains.TxProve
+
+type Verify struct {
+}
+
+func (v *Verify) Verify(db types.StateDB, routerContractAddr common.Address, txProveBytes []byte) (logs []byte, err error) {
+	return chains.VerifyTxProve(db, txProveBytes, v.getReceiptsRoot)
+}
+
+// getReceiptsRoot returns the receipts root of a stored block that is deep enough in
+// the chain to be considered final.
+func (v *Verify) getReceiptsRoot(db types.StateDB, blockNumber uint64) (common.Hash, error) {
+	hs := NewHeaderStore()
+	if err := hs.Load(db); err != nil {
+		return common.Hash{}, err
+	}
+	config, err := hs.config()
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if blockNumber+config.Confirmations > hs.Snapshot.Number {
+		return common.Hash{}, fmt.Errorf("%w, number: %d, current: %d", errNotConfirmed, blockNumber, hs.Snapshot.Number)
+	}
+	header, err := hs.LoadHeader(db, blockNumber)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if header == nil {
+		return common.Hash{}, fmt.Errorf("%w, number: %d", errUnknownBlock, blockNumber)
+	}
+	return header.ReceiptHash, nil
+}
+
+// VerifyBatch verifies the receipts of a chains.BatchTxProve against the receipts
+// root of their block and returns their logs.
+func (v *Verify) VerifyBatch(db types.StateDB, routerContractAddr common.Address, txProveBytes []byte) ([]*ethtypes.Log, error) {
+	return chains.VerifyBatchTxProve(db, txProveBytes, v.getReceiptsRoot)
+}
@@ -0,0 +1,110 @@
+package matic
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethdb/memorydb"
+	"github.com/ethereum/go-ethereum/light"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mapprotocol/atlas/chains"
+	"github.com/mapprotocol/atlas/chains/chainstest"
+)
+
+func receiptProof(t *testing.T, receipts ethtypes.Receipts, txIndex uint) (common.Hash, light.NodeList) {
+	tr, err := trie.New(common.Hash{}, trie.NewDatabase(memorydb.New()))
+	assert.NoError(t, err)
+	for i := range receipts {
+		key, err := rlp.EncodeToBytes(uint(i))
+		assert.NoError(t, err)
+		var buf bytes.Buffer
+		receipts.EncodeIndex(i, &buf)
+		tr.Update(key, buf.Bytes())
+	}
+
+	proof := light.NewNodeSet()
+	key, err := rlp.EncodeToBytes(txIndex)
+	assert.NoError(t, err)
+	assert.NoError(t, tr.Prove(key, 0, proof))
+	return tr.Hash(), proof.NodeList()
+}
+
+func TestVerify_Verify(t *testing.T) {
+	receipts := ethtypes.Receipts{
+		{Type: ethtypes.LegacyTxType, Status: ethtypes.ReceiptStatusSuccessful, CumulativeGasUsed: 21000, Logs: []*ethtypes.Log{}},
+		{Type: ethtypes.DynamicFeeTxType, Status: ethtypes.ReceiptStatusSuccessful, CumulativeGasUsed: 63000, Logs: []*ethtypes.Log{
+			{Address: common.HexToAddress("0xd6199276959b95a68c1ee30e8569f5fe060903a6"), Topics: []common.Hash{{0x01}}, Data: []byte{0x02}},
+		}},
+	}
+	root, prove := receiptProof(t, receipts, 1)
+
+	const proven = 5
+	headers := makeChain(12, testSets(), nil, map[uint64]common.Hash{proven: root, 14: root})
+	db := chainstest.NewStore(t, NewHeaderStore(), &resetInput{ChainID: uint64(testChainType), Header: headers[0]})
+	_, err := NewHeaderStore().InsertHeaders(db, chainstest.EncodeRLP(headers[1:]))
+	assert.NoError(t, err)
+
+	input, err := rlp.EncodeToBytes(&TxProve{Receipt: receipts[1], Prove: prove, BlockNumber: proven, TxIndex: 1})
+	assert.NoError(t, err)
+	logs, err := new(Verify).Verify(db, common.Address{}, input)
+	assert.NoError(t, err)
+	want, _ := rlp.EncodeToBytes(receipts[1].Logs)
+	assert.Equal(t, want, logs)
+
+	// the receipt is not the proven one
+	input, _ = rlp.EncodeToBytes(&TxProve{Receipt: receipts[0], Prove: prove, BlockNumber: proven, TxIndex: 1})
+	_, err = new(Verify).Verify(db, common.Address{}, input)
+	assert.Error(t, err)
+
+	// the block is not deep enough in the chain yet
+	input, _ = rlp.EncodeToBytes(&TxProve{Receipt: receipts[1], Prove: prove, BlockNumber: 14, TxIndex: 1})
+	_, err = new(Verify).Verify(db, common.Address{}, input)
+	assert.True(t, errors.Is(err, errNotConfirmed))
+}
+
+func TestVerify_VerifyBatch(t *testing.T) {
+	receipts := ethtypes.Receipts{
+		{Type: ethtypes.LegacyTxType, Status: ethtypes.ReceiptStatusSuccessful, CumulativeGasUsed: 21000, Logs: []*ethtypes.Log{
+			{Address: common.HexToAddress("0xd6199276959b95a68c1ee30e8569f5fe060903a6"), Topics: []common.Hash{{0x01}}, Data: []byte{0x01}},
+		}},
+		{Type: ethtypes.LegacyTxType, Status: ethtypes.ReceiptStatusSuccessful, CumulativeGasUsed: 42000, Logs: []*ethtypes.Log{}},
+		{Type: ethtypes.DynamicFeeTxType, Status: ethtypes.ReceiptStatusSuccessful, CumulativeGasUsed: 63000, Logs: []*ethtypes.Log{
+			{Address: common.HexToAddress("0xd6199276959b95a68c1ee30e8569f5fe060903a6"), Topics: []common.Hash{{0x02}}, Data: []byte{0x02}},
+		}},
+	}
+	root, prove0 := receiptProof(t, receipts, 0)
+	_, prove2 := receiptProof(t, receipts, 2)
+	set := light.NewNodeSet()
+	prove0.Store(set)
+	prove2.Store(set)
+
+	const proven = 5
+	headers := makeChain(12, testSets(), nil, map[uint64]common.Hash{proven: root})
+	db := chainstest.NewStore(t, NewHeaderStore(), &resetInput{ChainID: uint64(testChainType), Header: headers[0]})
+	_, err := NewHeaderStore().InsertHeaders(db, chainstest.EncodeRLP(headers[1:]))
+	assert.NoError(t, err)
+
+	batch := &chains.BatchTxProve{
+		BlockNumber: proven,
+		Receipts:    []*ethtypes.Receipt{receipts[0], receipts[2]},
+		TxIndexes:   []uint{0, 2},
+		Prove:       set.NodeList(),
+	}
+	input, err := rlp.EncodeToBytes(batch)
+	assert.NoError(t, err)
+	logs, err := new(Verify).VerifyBatch(db, common.Address{}, input)
+	assert.NoError(t, err)
+	assert.Equal(t, []*ethtypes.Log{receipts[0].Logs[0], receipts[2].Logs[0]}, logs)
+
+	// the second receipt is not proven at its index
+	batch.TxIndexes = []uint{0, 1}
+	input, _ = rlp.EncodeToBytes(batch)
+	_, err = new(Verify).VerifyBatch(db, common.Address{}, input)
+	assert.Error(t, err)
+}
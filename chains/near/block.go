@@ -0,0 +1,152 @@
+package near
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rlp"
+
+	"github.com/mapprotocol/atlas/chains"
+)
+
+const (
+	keyTypeED25519   = 0
+	keyTypeSECP256K1 = 1
+
+	secp256k1PublicKeySize = 64
+
+	// validatorStakeV1 is the variant of the versioned validator stake enum.
+	validatorStakeV1 = 0
+	// approvalEndorsement is the variant of the approval enum for a block endorsement.
+	approvalEndorsement = 0
+)
+
+func sha256Hash(data ...[]byte) common.Hash {
+	h := sha256.New()
+	for _, d := range data {
+		h.Write(d)
+	}
+	return common.BytesToHash(h.Sum(nil))
+}
+
+// BlockHeaderInnerLite is the part of a NEAR block header a light client follows.
+// Timestamp is in nanoseconds.
+type BlockHeaderInnerLite struct {
+	Height          uint64
+	EpochID         common.Hash
+	NextEpochID     common.Hash
+	PrevStateRoot   common.Hash
+	OutcomeRoot     common.Hash
+	Timestamp       uint64
+	NextBpHash      common.Hash
+	BlockMerkleRoot common.Hash
+}
+
+func (inner *BlockHeaderInnerLite) encode() []byte {
+	b := appendU64(nil, inner.Height)
+	b = append(b, inner.EpochID[:]...)
+	b = append(b, inner.NextEpochID[:]...)
+	b = append(b, inner.PrevStateRoot[:]...)
+	b = append(b, inner.OutcomeRoot[:]...)
+	b = appendU64(b, inner.Timestamp)
+	b = append(b, inner.NextBpHash[:]...)
+	return append(b, inner.BlockMerkleRoot[:]...)
+}
+
+// LightClientBlockLite is a block header reduced to what is needed to hash it.
+type LightClientBlockLite struct {
+	PrevBlockHash common.Hash
+	InnerRestHash common.Hash
+	InnerLite     BlockHeaderInnerLite
+}
+
+// Hash returns the block hash.
+func (b *LightClientBlockLite) Hash() common.Hash {
+	innerHash := sha256Hash(sha256Hash(b.InnerLite.encode()).Bytes(), b.InnerRestHash.Bytes())
+	return sha256Hash(innerHash.Bytes(), b.PrevBlockHash.Bytes())
+}
+
+// ValidatorStake is a block producer of an epoch. PublicKey is an ed25519 key, or an
+// uncompressed secp256k1 key without its prefix.
+type ValidatorStake struct {
+	AccountID string
+	PublicKey []byte
+	Stake     *big.Int
+}
+
+func (v *ValidatorStake) keyType() (uint8, error) {
+	switch len(v.PublicKey) {
+	case ed25519.PublicKeySize:
+		return keyTypeED25519, nil
+	case secp256k1PublicKeySize:
+		return keyTypeSECP256K1, nil
+	}
+	return 0, errUnsupportedKey
+}
+
+// bpsHash returns the hash of the borsh encoded block producers, the next_bp_hash of
+// the header announcing them.
+func bpsHash(bps []*ValidatorStake) (common.Hash, error) {
+	b := appendU32(nil, uint32(len(bps)))
+	for _, bp := range bps {
+		keyType, err := bp.keyType()
+		if err != nil {
+			return common.Hash{}, err
+		}
+		b = appendU8(b, validatorStakeV1)
+		b = appendString(b, bp.AccountID)
+		b = append(appendU8(b, keyType), bp.PublicKey...)
+		if b, err = appendU128(b, bp.Stake); err != nil {
+			return common.Hash{}, err
+		}
+	}
+	return sha256Hash(b), nil
+}
+
+// LightClientBlock is a block sent to the light client. NextBps is only present in
+// the blocks announcing the producers of the next epoch, and Approvals holds the
+// ed25519 signatures of the producers of the epoch, in their order, on the block two
+// heights later, empty for the producers that did not approve.
+type LightClientBlock struct {
+	PrevBlockHash      common.Hash
+	NextBlockInnerHash common.Hash
+	InnerLite          BlockHeaderInnerLite
+	InnerRestHash      common.Hash
+	NextBps            []*ValidatorStake
+	Approvals          [][]byte
+}
+
+func (b *LightClientBlock) lite() *LightClientBlockLite {
+	return &LightClientBlockLite{PrevBlockHash: b.PrevBlockHash, InnerRestHash: b.InnerRestHash, InnerLite: b.InnerLite}
+}
+
+// Hash returns the block hash.
+func (b *LightClientBlock) Hash() common.Hash {
+	return b.lite().Hash()
+}
+
+// Index returns the indexed part of the block, it implements chains.IIndexable. The
+// raw header is the rlp encoding of the lite block.
+func (b *LightClientBlock) Index(_ rlp.RawValue) (*chains.IndexedHeader, error) {
+	raw, err := rlp.EncodeToBytes(b.lite())
+	if err != nil {
+		return nil, err
+	}
+	return &chains.IndexedHeader{
+		Number:      b.InnerLite.Height,
+		Hash:        b.Hash(),
+		ParentHash:  b.PrevBlockHash,
+		ReceiptHash: b.InnerLite.BlockMerkleRoot,
+		Raw:         raw,
+	}, nil
+}
+
+// approvalMessage returns the endorsement of the next block signed by the approvals,
+// bound to the height two blocks later.
+func (b *LightClientBlock) approvalMessage() []byte {
+	nextHash := sha256Hash(b.NextBlockInnerHash.Bytes(), b.Hash().Bytes())
+	msg := append(appendU8(nil, approvalEndorsement), nextHash[:]...)
+	return appendU64(msg, b.InnerLite.Height+2)
+}
@@ -0,0 +1,49 @@
+package near
+
+import (
+	"encoding/binary"
+	"errors"
+	"math/big"
+)
+
+// NEAR hashes the borsh encoding of its types. The light client only needs to produce
+// these encodings, so the few types involved are encoded by hand: integers are little
+// endian, dynamic sequences are prefixed by their u32 length and enums by the u8 index
+// of their variant.
+
+var errU128Overflow = errors.New("value does not fit in u128")
+
+func appendU8(b []byte, v uint8) []byte {
+	return append(b, v)
+}
+
+func appendU32(b []byte, v uint32) []byte {
+	return binary.LittleEndian.AppendUint32(b, v)
+}
+
+func appendU64(b []byte, v uint64) []byte {
+	return binary.LittleEndian.AppendUint64(b, v)
+}
+
+func appendU128(b []byte, v *big.Int) ([]byte, error) {
+	if v == nil {
+		v = new(big.Int)
+	}
+	if v.Sign() < 0 || v.BitLen() > 128 {
+		return nil, errU128Overflow
+	}
+	var le [16]byte
+	v.FillBytes(le[:])
+	for i, j := 0, len(le)-1; i < j; i, j = i+1, j-1 {
+		le[i], le[j] = le[j], le[i]
+	}
+	return append(b, le[:]...), nil
+}
+
+func appendBytes(b []byte, v []byte) []byte {
+	return append(appendU32(b, uint32(len(v))), v...)
+}
+
+func appendString(b []byte, v string) []byte {
+	return appendBytes(b, []byte(v))
+}
@@ -0,0 +1,18 @@
+package near
+
+import "errors"
+
+var (
+	errNotInitialized     = errors.New("please initialize header store")
+	errInvalidNumber      = errors.New("invalid block height")
+	errUnknownEpoch       = errors.New("block is not in the epoch of the head or the next one")
+	errMissingNextBps     = errors.New("first block of an epoch must carry the next block producers")
+	errInvalidNextBps     = errors.New("next block producers do not match the header")
+	errUnsupportedKey     = errors.New("unsupported block producer key type")
+	errInvalidSignature   = errors.New("invalid approval signature")
+	errNotEnoughStake     = errors.New("block is not approved by enough stake")
+	errUnknownBlock       = errors.New("block is not stored in the light client")
+	errInvalidOutcomeRoot = errors.New("outcome is not in the outcome root of the block")
+	errInvalidBlockProof  = errors.New("block is not in the block merkle root of the head")
+	errFailedOutcome      = errors.New("execution outcome is not successful")
+)
@@ -0,0 +1,308 @@
+package near
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
+
+	"github.com/mapprotocol/atlas/chains"
+	"github.com/mapprotocol/atlas/core/types"
+	"github.com/mapprotocol/atlas/params"
+	"github.com/mapprotocol/atlas/tools"
+)
+
+const (
+	MaxHeaderLimit = 100000
+)
+
+var stateStore = chains.NewStateStore(chains.NearHeaderStoreAddress)
+
+// Head is the last block accepted by the light client.
+type Head struct {
+	Height      uint64
+	Hash        common.Hash
+	EpochID     common.Hash
+	NextEpochID common.Hash
+}
+
+// EpochProducers are the block producers of an epoch.
+type EpochProducers struct {
+	EpochID   common.Hash
+	Producers []*ValidatorStake
+}
+
+// HeaderStore is the light client of a NEAR network. It keeps the block producers of
+// the epoch of the head and, once they are announced, of the next one.
+type HeaderStore struct {
+	ChainID uint64
+	Head    *Head
+	Epochs  []*EpochProducers
+}
+
+// LightHeader is the part of a stored block needed to verify outcome proofs.
+type LightHeader struct {
+	Height          uint64
+	Hash            common.Hash
+	BlockMerkleRoot common.Hash
+}
+
+// resetInput initializes the light client with a trusted block announcing the
+// producers of the next epoch, and the producers of its own epoch.
+type resetInput struct {
+	ChainID   uint64
+	Producers []*ValidatorStake
+	Block     *LightClientBlock
+}
+
+func NewHeaderStore() *HeaderStore {
+	return &HeaderStore{}
+}
+
+func headerDbKey(height uint64) common.Hash {
+	str := fmt.Sprintf("%s-%d", "near", height%MaxHeaderLimit)
+	return common.BytesToHash([]byte(str))
+}
+
+// isNearChain reports whether the chain is followed by this module.
+func isNearChain(chainID uint64) bool {
+	m, err := chains.GetModule(chains.ChainGroupNear)
+	if err != nil {
+		return false
+	}
+	_, ok := m.Chains[chains.ChainType(chainID)]
+	return ok
+}
+
+// ResetHeaderStore initializes the light client with a trusted block, encoded as
+// rlp(chainID, producers, block).
+func (hs *HeaderStore) ResetHeaderStore(db types.StateDB, input []byte, td *big.Int) error {
+	var ri resetInput
+	if err := rlp.DecodeBytes(input, &ri); err != nil {
+		log.Error("rlp decode near reset input failed", "err", err)
+		return chains.ErrRLPDecode
+	}
+	if ri.Block == nil {
+		return errInvalidNumber
+	}
+	if !isNearChain(ri.ChainID) {
+		return chains.ErrNotSupportChain
+	}
+	block := ri.Block
+	if len(block.NextBps) == 0 {
+		return errMissingNextBps
+	}
+	if hash, err := bpsHash(block.NextBps); err != nil || hash != block.InnerLite.NextBpHash {
+		return errInvalidNextBps
+	}
+
+	inner := block.InnerLite
+	h := &HeaderStore{
+		ChainID: ri.ChainID,
+		Head: &Head{
+			Height:      inner.Height,
+			Hash:        block.Hash(),
+			EpochID:     inner.EpochID,
+			NextEpochID: inner.NextEpochID,
+		},
+		Epochs: []*EpochProducers{
+			{EpochID: inner.EpochID, Producers: ri.Producers},
+			{EpochID: inner.NextEpochID, Producers: block.NextBps},
+		},
+	}
+	if err := h.StoreHeader(db, &LightHeader{Height: inner.Height, Hash: h.Head.Hash, BlockMerkleRoot: inner.BlockMerkleRoot}); err != nil {
+		return err
+	}
+	return h.Store(db)
+}
+
+func (hs *HeaderStore) Store(db types.StateDB) error {
+	return stateStore.Store(db, hs)
+}
+
+func (hs *HeaderStore) Load(db types.StateDB) error {
+	ok, err := stateStore.Load(db, hs)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errNotInitialized
+	}
+	return nil
+}
+
+func (hs *HeaderStore) StoreHeader(db types.StateDB, header *LightHeader) error {
+	data, err := rlp.EncodeToBytes(header)
+	if err != nil {
+		log.Error("Failed to RLP encode LightHeader", "err", err)
+		return err
+	}
+	db.SetPOWState(chains.NearHeaderStoreAddress, headerDbKey(header.Height), data)
+	return nil
+}
+
+// LoadHeader returns the stored block with the given height, or nil if it was never
+// stored or has been overwritten since.
+func (hs *HeaderStore) LoadHeader(db types.StateDB, height uint64) (*LightHeader, error) {
+	data := db.GetPOWState(chains.NearHeaderStoreAddress, headerDbKey(height))
+	if len(data) == 0 {
+		return nil, nil
+	}
+
+	var header LightHeader
+	if err := rlp.DecodeBytes(data, &header); err != nil {
+		return nil, fmt.Errorf("LightHeader RLP decode failed, error: %s", err.Error())
+	}
+	if header.Height != height {
+		return nil, nil
+	}
+	return &header, nil
+}
+
+// producers returns the block producers of an epoch, nil if they are not known.
+func (hs *HeaderStore) producers(epochID common.Hash) []*ValidatorStake {
+	for _, e := range hs.Epochs {
+		if e.EpochID == epochID {
+			return e.Producers
+		}
+	}
+	return nil
+}
+
+// apply verifies a block against the head and makes it the new head. The block must
+// belong to the epoch of the head or the next one, and be approved by more than two
+// thirds of the stake of the producers of its epoch. The first block of the next epoch
+// must announce the producers of the epoch after it.
+func (hs *HeaderStore) apply(block *LightClientBlock) error {
+	if block == nil {
+		return errInvalidNumber
+	}
+	inner := &block.InnerLite
+	if inner.Height <= hs.Head.Height {
+		return errInvalidNumber
+	}
+	if inner.EpochID != hs.Head.EpochID && inner.EpochID != hs.Head.NextEpochID {
+		return errUnknownEpoch
+	}
+	if inner.EpochID == hs.Head.NextEpochID && len(block.NextBps) == 0 {
+		return errMissingNextBps
+	}
+	bps := hs.producers(inner.EpochID)
+	if len(bps) == 0 {
+		return errUnknownEpoch
+	}
+	if err := verifyApprovals(block, bps); err != nil {
+		return err
+	}
+	if len(block.NextBps) > 0 {
+		if hash, err := bpsHash(block.NextBps); err != nil || hash != inner.NextBpHash {
+			return errInvalidNextBps
+		}
+	}
+
+	epochs := []*EpochProducers{{EpochID: inner.EpochID, Producers: bps}}
+	if len(block.NextBps) > 0 {
+		epochs = append(epochs, &EpochProducers{EpochID: inner.NextEpochID, Producers: block.NextBps})
+	} else if next := hs.producers(inner.NextEpochID); next != nil {
+		epochs = append(epochs, &EpochProducers{EpochID: inner.NextEpochID, Producers: next})
+	}
+	hs.Epochs = epochs
+	hs.Head = &Head{
+		Height:      inner.Height,
+		Hash:        block.Hash(),
+		EpochID:     inner.EpochID,
+		NextEpochID: inner.NextEpochID,
+	}
+	return nil
+}
+
+// verifyApprovals checks the approvals of the block by the producers of its epoch.
+func verifyApprovals(block *LightClientBlock, bps []*ValidatorStake) error {
+	if len(block.Approvals) < len(bps) {
+		return errNotEnoughStake
+	}
+	var (
+		msg      = block.approvalMessage()
+		total    = new(big.Int)
+		approved = new(big.Int)
+	)
+	for i, bp := range bps {
+		if bp.Stake == nil {
+			continue
+		}
+		total.Add(total, bp.Stake)
+		sig := block.Approvals[i]
+		if len(sig) == 0 {
+			continue
+		}
+		if keyType, err := bp.keyType(); err != nil || keyType != keyTypeED25519 {
+			return errUnsupportedKey
+		}
+		if !tools.VerifyEd25519(bp.PublicKey, msg, sig) {
+			return errInvalidSignature
+		}
+		approved.Add(approved, bp.Stake)
+	}
+	// approved > total * 2 / 3
+	threshold := new(big.Int).Div(new(big.Int).Mul(total, big.NewInt(2)), big.NewInt(3))
+	if approved.Cmp(threshold) <= 0 {
+		return errNotEnoughStake
+	}
+	return nil
+}
+
+// InsertHeaders advances the light client with already validated blocks.
+func (hs *HeaderStore) InsertHeaders(db types.StateDB, input []byte) ([]*params.NumberHash, error) {
+	var blocks []*LightClientBlock
+	if err := rlp.DecodeBytes(input, &blocks); err != nil {
+		log.Error("rlp decode near blocks failed", "err", err)
+		return nil, chains.ErrRLPDecode
+	}
+	if err := hs.Load(db); err != nil {
+		return nil, err
+	}
+
+	nums := make([]*params.NumberHash, 0, len(blocks))
+	for _, block := range blocks {
+		if err := hs.apply(block); err != nil {
+			return nil, err
+		}
+		lh := &LightHeader{Height: hs.Head.Height, Hash: hs.Head.Hash, BlockMerkleRoot: block.InnerLite.BlockMerkleRoot}
+		if err := hs.StoreHeader(db, lh); err != nil {
+			return nil, err
+		}
+		nums = append(nums, &params.NumberHash{Number: lh.Height, Hash: lh.Hash})
+	}
+	if err := hs.Store(db); err != nil {
+		return nil, err
+	}
+	log.Info("stored new near blocks", "count", len(nums), "height", hs.Head.Height, "hash", hs.Head.Hash)
+	return nums, nil
+}
+
+func (hs *HeaderStore) GetCurrentNumberAndHash(db types.StateDB) (uint64, common.Hash, error) {
+	if err := hs.Load(db); err != nil {
+		return 0, common.Hash{}, err
+	}
+	return hs.Head.Height, hs.Head.Hash, nil
+}
+
+func (hs *HeaderStore) GetHashByNumber(db types.StateDB, number uint64) (common.Hash, error) {
+	if err := hs.Load(db); err != nil {
+		return common.Hash{}, err
+	}
+	header, err := hs.LoadHeader(db, number)
+	if err != nil || header == nil {
+		return common.Hash{}, err
+	}
+	return header.Hash, nil
+}
+
+// DecodeHeaders decodes the blocks of an updateBlockHeader input for node-local
+// indexes, it implements chains.IHeaderDecoder. The block merkle root is indexed in
+// place of the receipts root, outcomes are proven against it.
+func (hs *HeaderStore) DecodeHeaders(input []byte, _ chains.ChainType) ([]*chains.IndexedHeader, error) {
+	return chains.DecodeIndexedHeaders(input, func() chains.IIndexable { return new(LightClientBlock) })
+}
@@ -0,0 +1,233 @@
+package near
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mapprotocol/atlas/chains/chainstest"
+	"github.com/mapprotocol/atlas/core/state"
+)
+
+// rotating returns a different producer set for each epoch.
+func rotating(epoch uint64) *producerSet {
+	switch epoch % 3 {
+	case 0:
+		return newProducerSet("alice", 10, 20, 30)
+	case 1:
+		return newProducerSet("bob", 5, 5, 5, 5)
+	default:
+		return newProducerSet("carol", 100, 1)
+	}
+}
+
+// initStore resets the light client with the first block of epoch 1.
+func initStore(t *testing.T) (*state.StateDB, *LightClientBlock) {
+	genesis := makeBlock(testEpochLen, common.Hash{}, rotating)
+	db := chainstest.NewStore(t, NewHeaderStore(), &resetInput{ChainID: uint64(testChainType), Producers: rotating(1).producers, Block: genesis})
+	return db, genesis
+}
+
+func TestBpsHash(t *testing.T) {
+	bps := newProducerSet("alice", 10).producers
+	hash, err := bpsHash(bps)
+	assert.NoError(t, err)
+
+	// u32 count, u8 version, string account id, u8 key type, key, u128 stake
+	data := []byte{1, 0, 0, 0, 0}
+	data = append(data, 11, 0, 0, 0)
+	data = append(data, "alice0.near"...)
+	data = append(data, 0)
+	data = append(data, bps[0].PublicKey...)
+	data = append(data, 10, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0)
+	assert.Equal(t, sha256Hash(data), hash)
+
+	bps[0].PublicKey = bps[0].PublicKey[:31]
+	_, err = bpsHash(bps)
+	assert.Equal(t, errUnsupportedKey, err)
+}
+
+func TestHeaderStore_ResetHeaderStore(t *testing.T) {
+	genesis := makeBlock(testEpochLen, common.Hash{}, rotating)
+
+	noBps := makeBlock(testEpochLen+1, common.Hash{}, rotating)
+	assert.Equal(t, errMissingNextBps, NewHeaderStore().ResetHeaderStore(chainstest.NewStateDB(), chainstest.EncodeRLP(&resetInput{ChainID: uint64(testChainType), Producers: rotating(1).producers, Block: noBps}), nil))
+
+	badBps := makeBlock(testEpochLen, common.Hash{}, rotating)
+	badBps.NextBps = rotating(0).producers
+	assert.Equal(t, errInvalidNextBps, NewHeaderStore().ResetHeaderStore(chainstest.NewStateDB(), chainstest.EncodeRLP(&resetInput{ChainID: uint64(testChainType), Producers: rotating(1).producers, Block: badBps}), nil))
+
+	input, _ := rlp.EncodeToBytes(&resetInput{ChainID: 1, Producers: rotating(1).producers, Block: genesis})
+	assert.Error(t, NewHeaderStore().ResetHeaderStore(chainstest.NewStateDB(), input, nil))
+
+	db := chainstest.NewStore(t, NewHeaderStore(), &resetInput{ChainID: uint64(testChainType), Producers: rotating(1).producers, Block: genesis})
+	number, hash, err := NewHeaderStore().GetCurrentNumberAndHash(db)
+	assert.NoError(t, err)
+	assert.Equal(t, genesis.InnerLite.Height, number)
+	assert.Equal(t, genesis.Hash(), hash)
+}
+
+func TestHeaderStore_InsertHeaders(t *testing.T) {
+	db, genesis := initStore(t)
+	blocks := makeChain(genesis.InnerLite.Height+1, 45, rotating)
+
+	v := new(Validate)
+	for _, batch := range [][]*LightClientBlock{blocks[:5], blocks[5:18], blocks[18:]} {
+		_, err := v.ValidateHeaderChain(db, chainstest.EncodeRLP(batch), testChainType)
+		assert.NoError(t, err)
+
+		nums, err := NewHeaderStore().InsertHeaders(db, chainstest.EncodeRLP(batch))
+		assert.NoError(t, err)
+		assert.Equal(t, len(batch), len(nums))
+
+		last := batch[len(batch)-1]
+		number, hash, err := NewHeaderStore().GetCurrentNumberAndHash(db)
+		assert.NoError(t, err)
+		assert.Equal(t, last.InnerLite.Height, number)
+		assert.Equal(t, last.Hash(), hash)
+	}
+
+	for _, block := range blocks {
+		hash, err := NewHeaderStore().GetHashByNumber(db, block.InnerLite.Height)
+		assert.NoError(t, err)
+		assert.Equal(t, block.Hash(), hash)
+	}
+	hash, err := NewHeaderStore().GetHashByNumber(db, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, common.Hash{}, hash)
+}
+
+func TestValidate_ValidateHeaderChain(t *testing.T) {
+	db, genesis := initStore(t)
+	start := genesis.InnerLite.Height + 1
+
+	tests := []struct {
+		name   string
+		blocks func() []*LightClientBlock
+		index  int
+		err    error
+	}{
+		{
+			name: "next epoch",
+			blocks: func() []*LightClientBlock {
+				return makeChain(start, 2*testEpochLen+2, rotating)
+			},
+		},
+		{
+			name: "skipped blocks",
+			blocks: func() []*LightClientBlock {
+				return []*LightClientBlock{makeBlock(start+3, common.Hash{}, rotating), makeBlock(2*testEpochLen, common.Hash{}, rotating)}
+			},
+		},
+		{
+			name: "old height",
+			blocks: func() []*LightClientBlock {
+				return []*LightClientBlock{makeBlock(genesis.InnerLite.Height, common.Hash{}, rotating)}
+			},
+			err: errInvalidNumber,
+		},
+		{
+			name: "epoch after next",
+			blocks: func() []*LightClientBlock {
+				return []*LightClientBlock{makeBlock(start, common.Hash{}, rotating), makeBlock(3*testEpochLen, common.Hash{}, rotating)}
+			},
+			index: 1,
+			err:   errUnknownEpoch,
+		},
+		{
+			name: "next epoch without next producers",
+			blocks: func() []*LightClientBlock {
+				return []*LightClientBlock{makeBlock(2*testEpochLen+1, common.Hash{}, rotating)}
+			},
+			err: errMissingNextBps,
+		},
+		{
+			name: "wrong next producers",
+			blocks: func() []*LightClientBlock {
+				block := makeBlock(2*testEpochLen, common.Hash{}, rotating)
+				block.NextBps = rotating(0).producers[:2]
+				approve(block, rotating(2))
+				return []*LightClientBlock{block}
+			},
+			err: errInvalidNextBps,
+		},
+		{
+			// bob's producers have equal stakes, three of four is more than two thirds
+			name: "enough stake",
+			blocks: func() []*LightClientBlock {
+				return []*LightClientBlock{makeBlock(start, common.Hash{}, rotating, 0, 1, 3)}
+			},
+		},
+		{
+			name: "two thirds of the stake",
+			blocks: func() []*LightClientBlock {
+				set := newProducerSet("bob", 5, 5, 5, 5)
+				sets := func(epoch uint64) *producerSet {
+					if epoch == 1 {
+						return set
+					}
+					return rotating(epoch)
+				}
+				set.producers[3].Stake.SetInt64(0)
+				return []*LightClientBlock{makeBlock(start, common.Hash{}, sets, 0, 1)}
+			},
+			err: errNotEnoughStake,
+		},
+		{
+			name: "missing approvals",
+			blocks: func() []*LightClientBlock {
+				block := makeBlock(start, common.Hash{}, rotating)
+				block.Approvals = block.Approvals[:3]
+				return []*LightClientBlock{block}
+			},
+			err: errNotEnoughStake,
+		},
+		{
+			name: "invalid signature",
+			blocks: func() []*LightClientBlock {
+				block := makeBlock(start, common.Hash{}, rotating)
+				block.Approvals[2] = block.Approvals[1]
+				return []*LightClientBlock{block}
+			},
+			err: errInvalidSignature,
+		},
+		{
+			name: "tampered block",
+			blocks: func() []*LightClientBlock {
+				block := makeBlock(start, common.Hash{}, rotating)
+				block.InnerLite.OutcomeRoot = common.Hash{1}
+				return []*LightClientBlock{block}
+			},
+			err: errInvalidSignature,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			index, err := new(Validate).ValidateHeaderChain(db, chainstest.EncodeRLP(tt.blocks()), testChainType)
+			if tt.err == nil {
+				assert.NoError(t, err)
+				return
+			}
+			assert.True(t, errors.Is(err, tt.err), "got %v, want %v", err, tt.err)
+			assert.Equal(t, tt.index, index)
+		})
+	}
+
+	_, err := new(Validate).ValidateHeaderChain(db, chainstest.EncodeRLP(makeChain(start, start, rotating)), testChainType-1)
+	assert.Error(t, err)
+}
+
+func TestValidate_EstimateWork(t *testing.T) {
+	blocks := []*LightClientBlock{
+		makeBlock(testEpochLen+1, common.Hash{}, rotating),
+		makeBlock(testEpochLen+2, common.Hash{}, rotating, 0, 1, 2),
+	}
+	work, err := new(Validate).EstimateWork(nil, chainstest.EncodeRLP(blocks))
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2), work.Headers)
+	assert.Equal(t, uint64(7), work.Signatures)
+	assert.Equal(t, uint64(3), work.StateWrites)
+}
@@ -0,0 +1,108 @@
+package near
+
+import (
+	"crypto/ed25519"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/mapprotocol/atlas/chains"
+	"github.com/mapprotocol/atlas/chains/chainstest"
+)
+
+const (
+	testChainType = chains.ChainTypeNearTest
+	testEpochLen  = uint64(10)
+)
+
+// producerSet is a set of block producers with their keys.
+type producerSet struct {
+	producers []*ValidatorStake
+	keys      []ed25519.PrivateKey
+}
+
+func newProducerSet(seed string, stakes ...int64) *producerSet {
+	s := &producerSet{keys: chainstest.Ed25519Keys(seed, len(stakes))}
+	for i, stake := range stakes {
+		s.producers = append(s.producers, &ValidatorStake{
+			AccountID: fmt.Sprintf("%s%d.near", seed, i),
+			PublicKey: s.keys[i].Public().(ed25519.PublicKey),
+			Stake:     big.NewInt(stake),
+		})
+	}
+	return s
+}
+
+func epochOf(height uint64) uint64 {
+	return height / testEpochLen
+}
+
+func epochID(epoch uint64) common.Hash {
+	return sha256Hash([]byte(fmt.Sprintf("epoch-%d", epoch)))
+}
+
+func mustBpsHash(bps []*ValidatorStake) common.Hash {
+	hash, err := bpsHash(bps)
+	if err != nil {
+		panic(err)
+	}
+	return hash
+}
+
+// makeBlock returns the block at height approved by the producers of its epoch at the
+// given indexes, all of them if none are given. The first block of an epoch carries
+// the producers of the next one.
+func makeBlock(height uint64, prev common.Hash, sets func(epoch uint64) *producerSet, signers ...int) *LightClientBlock {
+	epoch := epochOf(height)
+	block := &LightClientBlock{
+		PrevBlockHash:      prev,
+		NextBlockInnerHash: sha256Hash([]byte(fmt.Sprintf("next-inner-%d", height))),
+		InnerLite: BlockHeaderInnerLite{
+			Height:          height,
+			EpochID:         epochID(epoch),
+			NextEpochID:     epochID(epoch + 1),
+			PrevStateRoot:   sha256Hash([]byte(fmt.Sprintf("state-%d", height))),
+			OutcomeRoot:     sha256Hash([]byte(fmt.Sprintf("outcome-%d", height))),
+			Timestamp:       1_700_000_000_000_000_000 + height*1_000_000_000,
+			NextBpHash:      mustBpsHash(sets(epoch + 1).producers),
+			BlockMerkleRoot: sha256Hash([]byte(fmt.Sprintf("blocks-%d", height))),
+		},
+		InnerRestHash: sha256Hash([]byte(fmt.Sprintf("inner-rest-%d", height))),
+	}
+	if height%testEpochLen == 0 {
+		block.NextBps = sets(epoch + 1).producers
+	}
+	approve(block, sets(epoch), signers...)
+	return block
+}
+
+// approve signs the block with the producers at the given indexes, all of them if
+// none are given.
+func approve(block *LightClientBlock, set *producerSet, signers ...int) {
+	if signers == nil {
+		for i := range set.producers {
+			signers = append(signers, i)
+		}
+	}
+	msg := block.approvalMessage()
+	block.Approvals = make([][]byte, len(set.producers))
+	for _, i := range signers {
+		block.Approvals[i] = ed25519.Sign(set.keys[i], msg)
+	}
+}
+
+// makeChain returns the blocks from height from to to, the producers of an epoch are
+// the ones of sets.
+func makeChain(from, to uint64, sets func(epoch uint64) *producerSet) []*LightClientBlock {
+	var (
+		blocks []*LightClientBlock
+		prev   common.Hash
+	)
+	for height := from; height <= to; height++ {
+		block := makeBlock(height, prev, sets)
+		blocks = append(blocks, block)
+		prev = block.Hash()
+	}
+	return blocks
+}
@@ -0,0 +1,22 @@
+package near
+
+import (
+	"math/big"
+
+	"github.com/mapprotocol/atlas/chains"
+	"github.com/mapprotocol/atlas/params"
+)
+
+func init() {
+	chains.Register(&chains.Module{
+		Group: chains.ChainGroupNear,
+		Chains: map[chains.ChainType]*chains.ChainParams{
+			chains.ChainTypeNear:     {AtlasChainID: params.MainNetChainID},
+			chains.ChainTypeNearTest: {AtlasChainID: params.TestNetChainID},
+		},
+		ForkBlock:      func(config *params.ChainConfig) *big.Int { return config.NearBlock },
+		NewValidate:    func() chains.IValidate { return new(Validate) },
+		NewHeaderStore: func() chains.IHeaderStore { return new(HeaderStore) },
+		NewVerify:      func() chains.IVerify { return new(Verify) },
+	})
+}
@@ -0,0 +1,60 @@
+package near
+
+import (
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
+
+	"github.com/mapprotocol/atlas/chains"
+	"github.com/mapprotocol/atlas/core/types"
+)
+
+type Validate struct{}
+
+// ValidateHeaderChain checks that each block is approved by the producers of its
+// epoch, starting from the light client head. It returns the index of the first
+// invalid block.
+func (v *Validate) ValidateHeaderChain(db types.StateDB, input []byte, chainType chains.ChainType) (int, error) {
+	var blocks []*LightClientBlock
+	if err := rlp.DecodeBytes(input, &blocks); err != nil {
+		log.Error("rlp decode near blocks failed", "err", err)
+		return 0, chains.ErrRLPDecode
+	}
+	if len(blocks) == 0 {
+		return 0, chains.ErrEmptyHeaders
+	}
+
+	hs := NewHeaderStore()
+	if err := hs.Load(db); err != nil {
+		return 0, err
+	}
+	if chains.ChainType(hs.ChainID) != chainType {
+		return 0, chains.ErrNotSupportChain
+	}
+
+	// the loaded store is a private copy, so the head can be advanced freely
+	for i, block := range blocks {
+		if err := hs.apply(block); err != nil {
+			log.Warn("invalid near block", "height", block.InnerLite.Height, "hash", block.Hash(), "err", err)
+			return i, err
+		}
+	}
+	return 0, nil
+}
+
+// EstimateWork returns the work of validating and inserting the blocks, a signature
+// per approval and a store entry per block besides the store itself.
+func (v *Validate) EstimateWork(_ types.StateDB, input []byte) (*chains.Work, error) {
+	var blocks []*LightClientBlock
+	if err := rlp.DecodeBytes(input, &blocks); err != nil {
+		return nil, err
+	}
+	work := &chains.Work{Headers: uint64(len(blocks)), StateWrites: uint64(len(blocks)) + 1}
+	for _, block := range blocks {
+		for _, sig := range block.Approvals {
+			if len(sig) > 0 {
+				work.Signatures++
+			}
+		}
+	}
+	return work, nil
+}
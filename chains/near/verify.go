@@ -0,0 +1,215 @@
+package near
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rlp"
+
+	"github.com/mapprotocol/atlas/core/types"
+)
+
+const (
+	directionLeft  = 0
+	directionRight = 1
+)
+
+// The variants of the execution status committed to by an outcome, failures are
+// committed to without their error.
+const (
+	statusUnknown = iota
+	statusFailure
+	statusSuccessValue
+	statusSuccessReceiptID
+)
+
+// MerklePathItem is a sibling on the path from a leaf to a merkle root, Direction
+// tells the side of the sibling.
+type MerklePathItem struct {
+	Hash      common.Hash
+	Direction uint8
+}
+
+// MerklePath is the path from a leaf to a merkle root.
+type MerklePath []*MerklePathItem
+
+// computeRoot returns the root the path leads to from the leaf.
+func (p MerklePath) computeRoot(leaf common.Hash) (common.Hash, error) {
+	hash := leaf
+	for _, item := range p {
+		switch item.Direction {
+		case directionLeft:
+			hash = sha256Hash(item.Hash[:], hash[:])
+		case directionRight:
+			hash = sha256Hash(hash[:], item.Hash[:])
+		default:
+			return common.Hash{}, fmt.Errorf("invalid merkle path direction %d", item.Direction)
+		}
+	}
+	return hash, nil
+}
+
+// ExecutionStatus is the status of an execution outcome, Value is the returned value
+// or the receipt id depending on the kind.
+type ExecutionStatus struct {
+	Kind  uint8
+	Value []byte
+}
+
+// ExecutionOutcome is the result of executing a transaction or a receipt.
+type ExecutionOutcome struct {
+	Logs        []string
+	ReceiptIDs  []common.Hash
+	GasBurnt    uint64
+	TokensBurnt *big.Int
+	ExecutorID  string
+	Status      ExecutionStatus
+}
+
+// ExecutionOutcomeWithID is an outcome with the id of the transaction or receipt it
+// belongs to and its path to the outcome root of its shard.
+type ExecutionOutcomeWithID struct {
+	Proof     MerklePath
+	BlockHash common.Hash
+	ID        common.Hash
+	Outcome   ExecutionOutcome
+}
+
+// hash returns the leaf of the outcome in the outcome tree of its shard: the hash of
+// the id, the hash of the outcome without its logs, and the hashes of the logs.
+func (o *ExecutionOutcomeWithID) hash() (common.Hash, error) {
+	outcome := &o.Outcome
+
+	partial := appendU32(nil, uint32(len(outcome.ReceiptIDs)))
+	for _, id := range outcome.ReceiptIDs {
+		partial = append(partial, id[:]...)
+	}
+	partial = appendU64(partial, outcome.GasBurnt)
+	partial, err := appendU128(partial, outcome.TokensBurnt)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	partial = appendString(partial, outcome.ExecutorID)
+	partial = appendU8(partial, outcome.Status.Kind)
+	switch outcome.Status.Kind {
+	case statusUnknown, statusFailure:
+	case statusSuccessValue:
+		partial = appendBytes(partial, outcome.Status.Value)
+	case statusSuccessReceiptID:
+		if len(outcome.Status.Value) != common.HashLength {
+			return common.Hash{}, errors.New("invalid receipt id")
+		}
+		partial = append(partial, outcome.Status.Value...)
+	default:
+		return common.Hash{}, fmt.Errorf("invalid execution status %d", outcome.Status.Kind)
+	}
+
+	hashes := appendU32(nil, uint32(len(outcome.Logs)+2))
+	hashes = append(hashes, o.ID[:]...)
+	hashes = append(hashes, sha256Hash(partial).Bytes()...)
+	for _, l := range outcome.Logs {
+		hashes = append(hashes, sha256Hash([]byte(l)).Bytes()...)
+	}
+	return sha256Hash(hashes), nil
+}
+
+// TxProve proves the outcome of a transaction or receipt, as returned by the light
+// client proof rpc of NEAR. The outcome is in the outcome root of BlockHeaderLite, and
+// that block is in the block merkle root of the stored block at HeadHeight.
+type TxProve struct {
+	HeadHeight       uint64
+	OutcomeProof     *ExecutionOutcomeWithID
+	OutcomeRootProof MerklePath
+	BlockHeaderLite  *LightClientBlockLite
+	BlockProof       MerklePath
+}
+
+// ProvenOutcome are the logs of a proven outcome and the account that emitted them,
+// they are returned rlp encoded by Verify.
+type ProvenOutcome struct {
+	ExecutorID string
+	Logs       []string
+}
+
+type Verify struct {
+}
+
+func (v *Verify) Verify(db types.StateDB, routerContractAddr common.Address, txProveBytes []byte) (logs []byte, err error) {
+	txProve, err := v.decode(txProveBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	blockMerkleRoot, err := v.getBlockMerkleRoot(db, txProve.HeadHeight)
+	if err != nil {
+		return nil, err
+	}
+	if err := v.verifyProof(blockMerkleRoot, txProve); err != nil {
+		return nil, err
+	}
+	outcome := &txProve.OutcomeProof.Outcome
+	return rlp.EncodeToBytes(&ProvenOutcome{ExecutorID: outcome.ExecutorID, Logs: outcome.Logs})
+}
+
+func (v *Verify) decode(txProveBytes []byte) (*TxProve, error) {
+	var txProve TxProve
+	if err := rlp.DecodeBytes(txProveBytes, &txProve); err != nil {
+		return nil, err
+	}
+	if txProve.OutcomeProof == nil || txProve.BlockHeaderLite == nil {
+		return nil, errors.New("outcome cannot be empty")
+	}
+	switch txProve.OutcomeProof.Outcome.Status.Kind {
+	case statusSuccessValue, statusSuccessReceiptID:
+	default:
+		return nil, errFailedOutcome
+	}
+	return &txProve, nil
+}
+
+// getBlockMerkleRoot returns the block merkle root of a stored block, the root of the
+// hashes of all the blocks before it.
+func (v *Verify) getBlockMerkleRoot(db types.StateDB, height uint64) (common.Hash, error) {
+	hs := NewHeaderStore()
+	if err := hs.Load(db); err != nil {
+		return common.Hash{}, err
+	}
+	header, err := hs.LoadHeader(db, height)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if header == nil {
+		return common.Hash{}, fmt.Errorf("%w, height: %d", errUnknownBlock, height)
+	}
+	return header.BlockMerkleRoot, nil
+}
+
+// verifyProof checks the outcome in the outcome root of the shard, the shard root in
+// the outcome root of the block, and the block in the block merkle root.
+func (v *Verify) verifyProof(blockMerkleRoot common.Hash, txProve *TxProve) error {
+	leaf, err := txProve.OutcomeProof.hash()
+	if err != nil {
+		return err
+	}
+	shardRoot, err := txProve.OutcomeProof.Proof.computeRoot(leaf)
+	if err != nil {
+		return err
+	}
+	outcomeRoot, err := txProve.OutcomeRootProof.computeRoot(sha256Hash(shardRoot[:]))
+	if err != nil {
+		return err
+	}
+	if outcomeRoot != txProve.BlockHeaderLite.InnerLite.OutcomeRoot {
+		return errInvalidOutcomeRoot
+	}
+	root, err := txProve.BlockProof.computeRoot(txProve.BlockHeaderLite.Hash())
+	if err != nil {
+		return err
+	}
+	if root != blockMerkleRoot {
+		return errInvalidBlockProof
+	}
+	return nil
+}
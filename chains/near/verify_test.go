@@ -0,0 +1,130 @@
+package near
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mapprotocol/atlas/chains/chainstest"
+)
+
+func TestMerklePath_ComputeRoot(t *testing.T) {
+	leaf, left, right := common.Hash{1}, common.Hash{2}, common.Hash{3}
+	path := MerklePath{{Hash: left, Direction: directionLeft}, {Hash: right, Direction: directionRight}}
+	root, err := path.computeRoot(leaf)
+	assert.NoError(t, err)
+	assert.Equal(t, sha256Hash(sha256Hash(left[:], leaf[:]).Bytes(), right[:]), root)
+
+	_, err = MerklePath{{Direction: 2}}.computeRoot(leaf)
+	assert.Error(t, err)
+}
+
+// makeTxProve returns the proof of a successful outcome of a block stored at a later
+// height, and the stored block.
+func makeTxProve(t *testing.T) (*TxProve, *LightClientBlock) {
+	outcome := &ExecutionOutcomeWithID{
+		Proof:     MerklePath{{Hash: common.Hash{0xa}, Direction: directionRight}},
+		BlockHash: common.Hash{0xb},
+		ID:        common.Hash{0xc},
+		Outcome: ExecutionOutcome{
+			Logs:        []string{"EVENT_JSON:{\"event\":\"transfer\"}", "second log"},
+			ReceiptIDs:  []common.Hash{{0xd}},
+			GasBurnt:    2428395018008,
+			TokensBurnt: big.NewInt(242839501800800000),
+			ExecutorID:  "mos.near",
+			Status:      ExecutionStatus{Kind: statusSuccessValue, Value: []byte("\"ok\"")},
+		},
+	}
+	leaf, err := outcome.hash()
+	require.NoError(t, err)
+	shardRoot, _ := outcome.Proof.computeRoot(leaf)
+	outcomeRootProof := MerklePath{{Hash: common.Hash{0xe}, Direction: directionLeft}}
+	outcomeRoot, _ := outcomeRootProof.computeRoot(sha256Hash(shardRoot[:]))
+
+	lite := makeBlock(testEpochLen+1, common.Hash{}, rotating).lite()
+	lite.InnerLite.OutcomeRoot = outcomeRoot
+
+	blockProof := MerklePath{{Hash: common.Hash{0xf}, Direction: directionLeft}}
+	blockMerkleRoot, _ := blockProof.computeRoot(lite.Hash())
+	head := makeBlock(testEpochLen+5, common.Hash{}, rotating)
+	head.InnerLite.BlockMerkleRoot = blockMerkleRoot
+	approve(head, rotating(1))
+
+	return &TxProve{
+		HeadHeight:       head.InnerLite.Height,
+		OutcomeProof:     outcome,
+		OutcomeRootProof: outcomeRootProof,
+		BlockHeaderLite:  lite,
+		BlockProof:       blockProof,
+	}, head
+}
+
+func TestVerify_Verify(t *testing.T) {
+	db, _ := initStore(t)
+	txProve, head := makeTxProve(t)
+	_, err := NewHeaderStore().InsertHeaders(db, chainstest.EncodeRLP([]*LightClientBlock{head}))
+	require.NoError(t, err)
+
+	verify := func(txProve *TxProve) ([]byte, error) {
+		input, err := rlp.EncodeToBytes(txProve)
+		require.NoError(t, err)
+		return new(Verify).Verify(db, common.Address{}, input)
+	}
+
+	logs, err := verify(txProve)
+	assert.NoError(t, err)
+	var proven ProvenOutcome
+	assert.NoError(t, rlp.DecodeBytes(logs, &proven))
+	assert.Equal(t, "mos.near", proven.ExecutorID)
+	assert.Equal(t, txProve.OutcomeProof.Outcome.Logs, proven.Logs)
+
+	tests := []struct {
+		name   string
+		modify func(p *TxProve)
+		err    error
+	}{
+		{
+			name:   "unknown block",
+			modify: func(p *TxProve) { p.HeadHeight++ },
+			err:    errUnknownBlock,
+		},
+		{
+			name:   "tampered log",
+			modify: func(p *TxProve) { p.OutcomeProof.Outcome.Logs[0] = "forged" },
+			err:    errInvalidOutcomeRoot,
+		},
+		{
+			name:   "tampered executor",
+			modify: func(p *TxProve) { p.OutcomeProof.Outcome.ExecutorID = "evil.near" },
+			err:    errInvalidOutcomeRoot,
+		},
+		{
+			name:   "tampered block",
+			modify: func(p *TxProve) { p.BlockHeaderLite.InnerRestHash = common.Hash{1} },
+			err:    errInvalidBlockProof,
+		},
+		{
+			name:   "wrong block proof",
+			modify: func(p *TxProve) { p.BlockProof[0].Direction = directionRight },
+			err:    errInvalidBlockProof,
+		},
+		{
+			name:   "failed outcome",
+			modify: func(p *TxProve) { p.OutcomeProof.Outcome.Status = ExecutionStatus{Kind: statusFailure} },
+			err:    errFailedOutcome,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			txProve, _ := makeTxProve(t)
+			tt.modify(txProve)
+			_, err := verify(txProve)
+			assert.True(t, errors.Is(err, tt.err), "got %v, want %v", err, tt.err)
+		})
+	}
+}
@@ -0,0 +1,186 @@
+package chains
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/light"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+
+	"github.com/mapprotocol/atlas/core/types"
+)
+
+// MaxBatchReceipts is the largest number of receipts proven by one batch.
+const MaxBatchReceipts = 256
+
+var (
+	errEmptyBatch       = errors.New("batch contains no receipts")
+	errBatchTooLarge    = fmt.Errorf("batch contains more than %d receipts", MaxBatchReceipts)
+	errBatchIndexes     = errors.New("batch receipts and tx indexes mismatch")
+	ErrBatchUnsupported = errors.New("batch verification is not supported")
+)
+
+// BatchTxProve proves several receipts of the same block. The trie nodes of all the
+// receipt proofs are merged in Prove, so the nodes they share are sent only once.
+type BatchTxProve struct {
+	BlockNumber uint64
+	Receipts    []*ethtypes.Receipt
+	TxIndexes   []uint
+	Prove       light.NodeList
+}
+
+// IBatchVerify is implemented by the verifiers able to check a batch of receipts of
+// one block in a single call. It returns the logs of all the receipts in batch order.
+type IBatchVerify interface {
+	VerifyBatch(db types.StateDB, router common.Address, txProveBytes []byte) ([]*ethtypes.Log, error)
+}
+
+// CheckBatch validates the shape of a batch of receipts before it is verified.
+func CheckBatch(receipts []*ethtypes.Receipt, indexes []uint) error {
+	if len(receipts) == 0 {
+		return errEmptyBatch
+	}
+	if len(receipts) > MaxBatchReceipts {
+		return errBatchTooLarge
+	}
+	if len(receipts) != len(indexes) {
+		return errBatchIndexes
+	}
+	seen := make(map[uint]struct{}, len(indexes))
+	for i, r := range receipts {
+		if r == nil {
+			return fmt.Errorf("batch receipt %d is empty", i)
+		}
+		if _, ok := seen[indexes[i]]; ok {
+			return fmt.Errorf("duplicate tx index %d in batch", indexes[i])
+		}
+		seen[indexes[i]] = struct{}{}
+	}
+	return nil
+}
+
+// verifyReceipt returns the receipt stored at the tx index in the receipts trie with
+// the given root and if it is the given receipt.
+func verifyReceipt(receiptsRoot common.Hash, receipt *ethtypes.Receipt, txIndex uint, nodeSet *light.NodeSet) (bool, error) {
+	var buf bytes.Buffer
+	rs := ethtypes.Receipts{receipt}
+	rs.EncodeIndex(0, &buf)
+
+	key := rlp.AppendUint64(nil, uint64(txIndex))
+	getReceipt, err := trie.VerifyProof(receiptsRoot, key, nodeSet)
+	if err != nil {
+		return false, err
+	}
+	return bytes.Equal(buf.Bytes(), getReceipt), nil
+}
+
+// VerifyReceipt checks that the receipt is stored at its tx index in the receipts
+// trie with the given root.
+func VerifyReceipt(receiptsRoot common.Hash, receipt *ethtypes.Receipt, txIndex uint, nodes light.NodeList) error {
+	ok, err := verifyReceipt(receiptsRoot, receipt, txIndex, nodes.NodeSet())
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.New("receipt mismatch")
+	}
+	return nil
+}
+
+// VerifyReceipts checks that every receipt is stored at its tx index in the receipts
+// trie with the given root. All receipts are proven by the same set of trie nodes.
+func VerifyReceipts(receiptsRoot common.Hash, receipts []*ethtypes.Receipt, indexes []uint, nodes light.NodeList) error {
+	if err := CheckBatch(receipts, indexes); err != nil {
+		return err
+	}
+	nodeSet := nodes.NodeSet()
+	for i, r := range receipts {
+		ok, err := verifyReceipt(receiptsRoot, r, indexes[i], nodeSet)
+		if err != nil {
+			return fmt.Errorf("receipt %d: %v", indexes[i], err)
+		}
+		if !ok {
+			return fmt.Errorf("receipt %d mismatch", indexes[i])
+		}
+	}
+	return nil
+}
+
+// TxProve proves a receipt of a block of a chain whose light client stores the
+// receipts roots of its blocks.
+type TxProve struct {
+	Receipt     *ethtypes.Receipt
+	Prove       light.NodeList
+	BlockNumber uint64
+	TxIndex     uint
+}
+
+// ReceiptsRootFunc returns the receipts root of a block a light client can prove
+// receipts of.
+type ReceiptsRootFunc func(db types.StateDB, number uint64) (common.Hash, error)
+
+// VerifyTxProve verifies the rlp encoded TxProve against the receipts root of its
+// block and returns the rlp encoded logs of the receipt.
+func VerifyTxProve(db types.StateDB, txProveBytes []byte, receiptsRoot ReceiptsRootFunc) ([]byte, error) {
+	var txProve TxProve
+	if err := rlp.DecodeBytes(txProveBytes, &txProve); err != nil {
+		return nil, err
+	}
+	root, err := receiptsRoot(db, txProve.BlockNumber)
+	if err != nil {
+		return nil, err
+	}
+	if err := VerifyReceipt(root, txProve.Receipt, txProve.TxIndex, txProve.Prove); err != nil {
+		return nil, err
+	}
+	return rlp.EncodeToBytes(txProve.Receipt.Logs)
+}
+
+// VerifyBatchTxProve verifies the receipts of the rlp encoded BatchTxProve against
+// the receipts root of their block and returns their logs.
+func VerifyBatchTxProve(db types.StateDB, txProveBytes []byte, receiptsRoot ReceiptsRootFunc) ([]*ethtypes.Log, error) {
+	var txProve BatchTxProve
+	if err := rlp.DecodeBytes(txProveBytes, &txProve); err != nil {
+		return nil, err
+	}
+	if err := CheckBatch(txProve.Receipts, txProve.TxIndexes); err != nil {
+		return nil, err
+	}
+	root, err := receiptsRoot(db, txProve.BlockNumber)
+	if err != nil {
+		return nil, err
+	}
+	if err := VerifyReceipts(root, txProve.Receipts, txProve.TxIndexes, txProve.Prove); err != nil {
+		return nil, err
+	}
+	return ReceiptLogs(txProve.Receipts), nil
+}
+
+// ReceiptLogs returns the logs of the receipts in order.
+func ReceiptLogs(receipts []*ethtypes.Receipt) []*ethtypes.Log {
+	var logs []*ethtypes.Log
+	for _, r := range receipts {
+		logs = append(logs, r.Logs...)
+	}
+	return logs
+}
+
+// FilterLogs returns the logs emitted by address with topic as first topic. A zero
+// address or topic matches any.
+func FilterLogs(logs []*ethtypes.Log, address common.Address, topic common.Hash) []*ethtypes.Log {
+	filtered := make([]*ethtypes.Log, 0, len(logs))
+	for _, lg := range logs {
+		if address != (common.Address{}) && lg.Address != address {
+			continue
+		}
+		if topic != (common.Hash{}) && (len(lg.Topics) == 0 || lg.Topics[0] != topic) {
+			continue
+		}
+		filtered = append(filtered, lg)
+	}
+	return filtered
+}
@@ -0,0 +1,132 @@
+package chains
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethdb/memorydb"
+	"github.com/ethereum/go-ethereum/light"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	logAddr1 = common.HexToAddress("0xd6199276959b95a68c1ee30e8569f5fe060903a6")
+	logAddr2 = common.HexToAddress("0x970e05ffbb2c4a3b80082e82b24f48a29a9c7651")
+)
+
+func testReceipts(n int) ethtypes.Receipts {
+	receipts := make(ethtypes.Receipts, 0, n)
+	for i := 0; i < n; i++ {
+		addr := logAddr1
+		if i%2 == 1 {
+			addr = logAddr2
+		}
+		receipts = append(receipts, &ethtypes.Receipt{
+			Type:              ethtypes.DynamicFeeTxType,
+			Status:            ethtypes.ReceiptStatusSuccessful,
+			CumulativeGasUsed: uint64(21000 * (i + 1)),
+			Logs: []*ethtypes.Log{
+				{Address: addr, Topics: []common.Hash{{byte(i % 3)}}, Data: []byte{byte(i)}},
+			},
+		})
+	}
+	return receipts
+}
+
+// batchProof returns the receipts root and the merged proof of the receipts at indexes.
+func batchProof(t *testing.T, receipts ethtypes.Receipts, indexes []uint) (common.Hash, light.NodeList) {
+	tr, err := trie.New(common.Hash{}, trie.NewDatabase(memorydb.New()))
+	assert.NoError(t, err)
+	for i := range receipts {
+		var buf bytes.Buffer
+		receipts.EncodeIndex(i, &buf)
+		tr.Update(rlp.AppendUint64(nil, uint64(i)), buf.Bytes())
+	}
+
+	proof := light.NewNodeSet()
+	for _, idx := range indexes {
+		assert.NoError(t, tr.Prove(rlp.AppendUint64(nil, uint64(idx)), 0, proof))
+	}
+	return tr.Hash(), proof.NodeList()
+}
+
+func TestVerifyReceipts(t *testing.T) {
+	receipts := testReceipts(40)
+	indexes := []uint{0, 3, 17, 39}
+	root, nodes := batchProof(t, receipts, indexes)
+
+	picked := []*ethtypes.Receipt{receipts[0], receipts[3], receipts[17], receipts[39]}
+	assert.NoError(t, VerifyReceipts(root, picked, indexes, nodes))
+
+	// the shared nodes are sent once
+	var separate int
+	for _, idx := range indexes {
+		_, single := batchProof(t, receipts, []uint{idx})
+		separate += len(single)
+	}
+	assert.Less(t, len(nodes), separate)
+
+	// a receipt at another index
+	err := VerifyReceipts(root, picked, []uint{0, 3, 18, 39}, nodes)
+	assert.Error(t, err)
+
+	// a tampered receipt
+	tampered := *receipts[17]
+	tampered.CumulativeGasUsed++
+	err = VerifyReceipts(root, []*ethtypes.Receipt{receipts[0], &tampered}, []uint{0, 17}, nodes)
+	assert.EqualError(t, err, "receipt 17 mismatch")
+
+	// a wrong root
+	assert.Error(t, VerifyReceipts(common.Hash{0x01}, picked, indexes, nodes))
+}
+
+func TestVerifyReceipt(t *testing.T) {
+	receipts := testReceipts(5)
+	root, nodes := batchProof(t, receipts, []uint{1})
+
+	assert.NoError(t, VerifyReceipt(root, receipts[1], 1, nodes))
+	assert.EqualError(t, VerifyReceipt(root, receipts[0], 1, nodes), "receipt mismatch")
+	assert.Error(t, VerifyReceipt(root, receipts[3], 3, nodes))
+}
+
+func TestCheckBatch(t *testing.T) {
+	receipts := testReceipts(2)
+
+	assert.Equal(t, errEmptyBatch, CheckBatch(nil, nil))
+	assert.Equal(t, errBatchIndexes, CheckBatch(receipts, []uint{0}))
+	assert.EqualError(t, CheckBatch(receipts, []uint{1, 1}), "duplicate tx index 1 in batch")
+	assert.EqualError(t, CheckBatch([]*ethtypes.Receipt{receipts[0], nil}, []uint{0, 1}), "batch receipt 1 is empty")
+	assert.Equal(t, errBatchTooLarge, CheckBatch(make([]*ethtypes.Receipt, MaxBatchReceipts+1), make([]uint, MaxBatchReceipts+1)))
+	assert.NoError(t, CheckBatch(receipts, []uint{0, 1}))
+}
+
+func TestFilterLogs(t *testing.T) {
+	logs := ReceiptLogs(testReceipts(6))
+	assert.Len(t, logs, 6)
+
+	tests := []struct {
+		name    string
+		address common.Address
+		topic   common.Hash
+		want    []byte // data of the matching logs
+	}{
+		{"any", common.Address{}, common.Hash{}, []byte{0, 1, 2, 3, 4, 5}},
+		{"address", logAddr2, common.Hash{}, []byte{1, 3, 5}},
+		{"topic", common.Address{}, common.Hash{0x01}, []byte{1, 4}},
+		{"address and topic", logAddr1, common.Hash{0x02}, []byte{2}},
+		{"none", logAddr1, common.Hash{0x09}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []byte
+			for _, lg := range FilterLogs(logs, tt.address, tt.topic) {
+				got = append(got, lg.Data...)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
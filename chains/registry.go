@@ -0,0 +1,196 @@
+package chains
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/mapprotocol/atlas/core/types"
+	"github.com/mapprotocol/atlas/params"
+)
+
+type IValidate interface {
+	ValidateHeaderChain(db types.StateDB, headers []byte, chainType ChainType) (int, error)
+}
+
+type IHeaderStore interface {
+	ResetHeaderStore(db types.StateDB, header []byte, td *big.Int) error
+	InsertHeaders(db types.StateDB, headers []byte) ([]*params.NumberHash, error)
+	GetCurrentNumberAndHash(db types.StateDB) (uint64, common.Hash, error)
+	GetHashByNumber(db types.StateDB, number uint64) (common.Hash, error)
+}
+
+type IVerify interface {
+	Verify(db types.StateDB, router common.Address, txProveBytes []byte) (logs []byte, err error)
+}
+
+// IChainConfigurable is implemented by light client components that read parameters
+// of the followed chain, such as its fork schedule, from the atlas chain config.
+type IChainConfigurable interface {
+	SetChainConfig(config *params.ChainConfig)
+}
+
+// IBlockConfigurable is implemented by light client components whose rules change at
+// an atlas fork, they are told the atlas block the call is executed in.
+type IBlockConfigurable interface {
+	SetBlockNumber(number *big.Int)
+}
+
+// IClockConfigurable is implemented by light client components that check the age of
+// the followed chain headers, they are told the time of the atlas block the call is
+// executed in, in seconds.
+type IClockConfigurable interface {
+	SetBlockTime(time uint64)
+}
+
+// ChainParams are the parameters of a single chain followed by a light client module.
+type ChainParams struct {
+	// AtlasChainID is the id of the atlas chain the light client of this chain runs on.
+	AtlasChainID uint64
+	// LondonBlock is the EIP-1559 fork block of the followed chain, if it has one.
+	LondonBlock *big.Int
+	// Network is the network configuration of the followed chain, such as its consensus
+	// parameters and fork schedule. Its type is defined by the module, see Network.
+	Network interface{}
+}
+
+// Module is a light client implementation together with the chains it follows.
+// A module registers itself once, usually from the init function of its package.
+type Module struct {
+	Group  ChainGroup
+	Chains map[ChainType]*ChainParams
+
+	// ForkBlock returns the atlas block the module is activated at, nil if it is
+	// not scheduled. A nil ForkBlock means the module is active since genesis.
+	ForkBlock func(config *params.ChainConfig) *big.Int
+
+	NewValidate    func() IValidate
+	NewHeaderStore func() IHeaderStore
+	NewVerify      func() IVerify // optional, nil if receipts can not be verified
+}
+
+var (
+	registryLock sync.RWMutex
+	registry     = make(map[ChainGroup]*Module)
+)
+
+// Register adds a light client module to the registry, it panics if the group is
+// registered twice or the module is incomplete.
+func Register(m *Module) {
+	registryLock.Lock()
+	defer registryLock.Unlock()
+
+	if m == nil || m.NewValidate == nil || m.NewHeaderStore == nil {
+		panic("chains: incomplete module")
+	}
+	if _, ok := registry[m.Group]; ok {
+		panic(fmt.Sprintf("chains: group %d registered twice", m.Group))
+	}
+	registry[m.Group] = m
+}
+
+// activeAt returns the activation block of the module, -1 for genesis, or nil if the
+// module is not active at the given block.
+func (m *Module) activeAt(config *params.ChainConfig, number *big.Int) *big.Int {
+	if m.ForkBlock == nil {
+		return big.NewInt(-1)
+	}
+	if config == nil || number == nil {
+		return nil
+	}
+	fork := m.ForkBlock(config)
+	if fork == nil || fork.Cmp(number) > 0 {
+		return nil
+	}
+	return fork
+}
+
+// sortedModules returns the registered modules ordered by group, so that lookups
+// never depend on the registration order.
+func sortedModules() []*Module {
+	registryLock.RLock()
+	defer registryLock.RUnlock()
+
+	ms := make([]*Module, 0, len(registry))
+	for _, m := range registry {
+		ms = append(ms, m)
+	}
+	sort.Slice(ms, func(i, j int) bool { return ms[i].Group < ms[j].Group })
+	return ms
+}
+
+// LookupModule returns the module that follows the chain at the given atlas block.
+// If several modules follow the chain, the most recently activated one is used.
+func LookupModule(config *params.ChainConfig, number *big.Int, chain ChainType) (*Module, error) {
+	var (
+		found  *Module
+		active *big.Int
+	)
+	for _, m := range sortedModules() {
+		if _, ok := m.Chains[chain]; !ok {
+			continue
+		}
+		fork := m.activeAt(config, number)
+		if fork == nil {
+			continue
+		}
+		if found == nil || fork.Cmp(active) > 0 {
+			found, active = m, fork
+		}
+	}
+	if found == nil {
+		return nil, ErrNotSupportChain
+	}
+	return found, nil
+}
+
+// GetModule returns the module registered for the group.
+func GetModule(group ChainGroup) (*Module, error) {
+	registryLock.RLock()
+	defer registryLock.RUnlock()
+
+	m, ok := registry[group]
+	if !ok {
+		return nil, ErrNotSupportChain
+	}
+	return m, nil
+}
+
+// Network returns the network configuration the module of the group registered for
+// the chain.
+func Network(group ChainGroup, chain ChainType) (interface{}, error) {
+	m, err := GetModule(group)
+	if err != nil {
+		return nil, err
+	}
+	p, ok := m.Chains[chain]
+	if !ok || p.Network == nil {
+		return nil, ErrNotSupportChain
+	}
+	return p.Network, nil
+}
+
+// chainParams returns the parameters of the chain as registered by the genesis module
+// following it, the ones of any other module otherwise.
+func chainParams(chain ChainType) (*ChainParams, error) {
+	var found *ChainParams
+	for _, m := range sortedModules() {
+		p, ok := m.Chains[chain]
+		if !ok {
+			continue
+		}
+		if m.ForkBlock == nil {
+			return p, nil
+		}
+		if found == nil {
+			found = p
+		}
+	}
+	if found == nil {
+		return nil, ErrNotSupportChain
+	}
+	return found, nil
+}
@@ -0,0 +1,76 @@
+package chains
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mapprotocol/atlas/params"
+)
+
+func TestLookupModule(t *testing.T) {
+	const (
+		chain     ChainType  = 9_000_001
+		group     ChainGroup = 9_001
+		upgrade   ChainGroup = 9_002
+		forkBlock            = 100
+	)
+	newModule := func(group ChainGroup, fork func(*params.ChainConfig) *big.Int) *Module {
+		return &Module{
+			Group:          group,
+			Chains:         map[ChainType]*ChainParams{chain: {AtlasChainID: params.DevNetChainID, Network: group}},
+			ForkBlock:      fork,
+			NewValidate:    func() IValidate { return nil },
+			NewHeaderStore: func() IHeaderStore { return nil },
+		}
+	}
+	Register(newModule(group, nil))
+	Register(newModule(upgrade, func(config *params.ChainConfig) *big.Int { return config.CalcBaseBlock }))
+	assert.Panics(t, func() { Register(newModule(group, nil)) })
+
+	assert.True(t, IsRegisteredChain(chain))
+	assert.False(t, IsRegisteredChain(chain+1))
+	assert.True(t, IsSupportedChain(nil, nil, ChainTypeMAP))
+	assert.True(t, IsSupportedChain(nil, nil, chain))
+	assert.False(t, IsSupportedChain(nil, nil, chain+1))
+
+	network, err := Network(upgrade, chain)
+	assert.Nil(t, err)
+	assert.Equal(t, upgrade, network)
+	_, err = Network(upgrade, chain+1)
+	assert.Equal(t, ErrNotSupportChain, err)
+
+	chainID, err := ChainType2ChainID(chain)
+	assert.Nil(t, err)
+	assert.Equal(t, params.DevNetChainID, chainID)
+
+	got, err := ChainType2ChainGroup(chain)
+	assert.Nil(t, err)
+	assert.Equal(t, group, got)
+
+	unscheduled := &params.ChainConfig{}
+	got, err = ChainType2ChainGroupAt(unscheduled, big.NewInt(forkBlock), chain)
+	assert.Nil(t, err)
+	assert.Equal(t, group, got)
+
+	scheduled := &params.ChainConfig{CalcBaseBlock: big.NewInt(forkBlock)}
+	got, err = ChainType2ChainGroupAt(scheduled, big.NewInt(forkBlock-1), chain)
+	assert.Nil(t, err)
+	assert.Equal(t, group, got)
+	got, err = ChainType2ChainGroupAt(scheduled, big.NewInt(forkBlock), chain)
+	assert.Nil(t, err)
+	assert.Equal(t, upgrade, got)
+
+	_, err = ChainType2ChainGroupAt(scheduled, big.NewInt(forkBlock), chain+1)
+	assert.Equal(t, ErrNotSupportChain, err)
+
+	// a chain followed from the fork on is supported from the fork on
+	m, err := GetModule(upgrade)
+	assert.Nil(t, err)
+	m.Chains[chain+2] = &ChainParams{AtlasChainID: params.DevNetChainID}
+	assert.True(t, IsRegisteredChain(chain+2))
+	assert.False(t, IsSupportedChain(nil, nil, chain+2))
+	assert.False(t, IsSupportedChain(scheduled, big.NewInt(forkBlock-1), chain+2))
+	assert.True(t, IsSupportedChain(scheduled, big.NewInt(forkBlock), chain+2))
+}
@@ -0,0 +1,10 @@
+package relayer
+
+import "errors"
+
+var (
+	errStakeTooLow       = errors.New("stake is below the minimum relayer stake")
+	errAlreadyRegistered = errors.New("relayer is already registered")
+	errNotRegistered     = errors.New("relayer is not registered")
+	errExiting           = errors.New("relayer is exiting")
+)
@@ -0,0 +1,320 @@
+package relayer
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
+
+	"github.com/mapprotocol/atlas/core/types"
+	"github.com/mapprotocol/atlas/params"
+)
+
+// Relayer is a staked header relayer of a followed chain. The stakes are held by
+// the relayer registry account.
+type Relayer struct {
+	Address common.Address
+	Stake   *big.Int
+	Epoch   uint64 // epoch the relayer registered in
+	Exiting bool   // deregistered, the stake is returned at the end of the epoch
+}
+
+// Set is the relayers of a followed chain.
+type Set struct {
+	Relayers []*Relayer
+}
+
+// Submission counts the headers a relayer submitted for a chain in an epoch.
+type Submission struct {
+	Relayer common.Address
+	Chain   uint64
+	Headers uint64
+}
+
+// EpochWork is the header submissions of an epoch, in the order they were made.
+type EpochWork struct {
+	Submissions []*Submission
+}
+
+// Payout is the epoch reward earned by a relayer.
+type Payout struct {
+	Relayer common.Address
+	Amount  *big.Int
+}
+
+func setDbKey(chain uint64) common.Hash {
+	return common.BytesToHash([]byte(fmt.Sprintf("%s-%d", "relayers", chain)))
+}
+
+func workDbKey(epoch uint64) common.Hash {
+	return common.BytesToHash([]byte(fmt.Sprintf("%s-%d", "work", epoch)))
+}
+
+func chainsDbKey() common.Hash {
+	return common.BytesToHash([]byte("chains"))
+}
+
+func load(db types.StateDB, key common.Hash, v interface{}) error {
+	data := db.GetPOWState(params.RelayerRegistryAddress, key)
+	if len(data) == 0 {
+		return nil
+	}
+	if err := rlp.DecodeBytes(data, v); err != nil {
+		return fmt.Errorf("relayer registry RLP decode failed, error: %s", err.Error())
+	}
+	return nil
+}
+
+func store(db types.StateDB, key common.Hash, v interface{}) error {
+	data, err := rlp.EncodeToBytes(v)
+	if err != nil {
+		log.Error("Failed to RLP encode relayer registry", "err", err)
+		return err
+	}
+	db.SetPOWState(params.RelayerRegistryAddress, key, data)
+	return nil
+}
+
+// remove deletes the entry of the key, if any.
+func remove(db types.StateDB, key common.Hash) {
+	if len(db.GetPOWState(params.RelayerRegistryAddress, key)) != 0 {
+		db.SetPOWState(params.RelayerRegistryAddress, key, nil)
+	}
+}
+
+// LoadSet returns the relayers of the chain.
+func LoadSet(db types.StateDB, chain uint64) (*Set, error) {
+	set := new(Set)
+	if err := load(db, setDbKey(chain), set); err != nil {
+		return nil, err
+	}
+	return set, nil
+}
+
+func storeSet(db types.StateDB, chain uint64, set *Set) error {
+	return store(db, setDbKey(chain), set)
+}
+
+// LoadWork returns the header submissions of the epoch, none once it is settled.
+func LoadWork(db types.StateDB, epoch uint64) (*EpochWork, error) {
+	work := new(EpochWork)
+	if err := load(db, workDbKey(epoch), work); err != nil {
+		return nil, err
+	}
+	return work, nil
+}
+
+// loadChains returns the chains that have had relayers, in registration order.
+func loadChains(db types.StateDB) ([]uint64, error) {
+	var chains []uint64
+	if err := load(db, chainsDbKey(), &chains); err != nil {
+		return nil, err
+	}
+	return chains, nil
+}
+
+func (s *Set) find(addr common.Address) (int, *Relayer) {
+	for i, r := range s.Relayers {
+		if r.Address == addr {
+			return i, r
+		}
+	}
+	return -1, nil
+}
+
+// Register adds a relayer to the chain with the given stake, which must have been
+// transferred to the registry already.
+func Register(db types.StateDB, chain uint64, addr common.Address, stake *big.Int, epoch uint64) error {
+	if stake == nil || stake.Cmp(params.RelayerMinStake) < 0 {
+		return errStakeTooLow
+	}
+	set, err := LoadSet(db, chain)
+	if err != nil {
+		return err
+	}
+	if _, r := set.find(addr); r != nil {
+		return errAlreadyRegistered
+	}
+	if len(set.Relayers) == 0 {
+		chains, err := loadChains(db)
+		if err != nil {
+			return err
+		}
+		known := false
+		for _, c := range chains {
+			known = known || c == chain
+		}
+		if !known {
+			if err := store(db, chainsDbKey(), append(chains, chain)); err != nil {
+				return err
+			}
+		}
+	}
+	set.Relayers = append(set.Relayers, &Relayer{Address: addr, Stake: new(big.Int).Set(stake), Epoch: epoch})
+	return storeSet(db, chain, set)
+}
+
+// Deregister stops the relayer from relaying headers of the chain, its stake is
+// returned when the epoch is settled.
+func Deregister(db types.StateDB, chain uint64, addr common.Address) error {
+	set, err := LoadSet(db, chain)
+	if err != nil {
+		return err
+	}
+	_, r := set.find(addr)
+	if r == nil {
+		return errNotRegistered
+	}
+	if r.Exiting {
+		return errExiting
+	}
+	r.Exiting = true
+	return storeSet(db, chain, set)
+}
+
+// Rotate moves the registration and the stake of a relayer to a new address, along with
+// the headers it submitted for the chain in the epoch, so that it is rewarded and judged
+// active under the new address when the epoch is settled.
+func Rotate(db types.StateDB, chain uint64, from, to common.Address, epoch uint64) error {
+	set, err := LoadSet(db, chain)
+	if err != nil {
+		return err
+	}
+	_, r := set.find(from)
+	if r == nil {
+		return errNotRegistered
+	}
+	if r.Exiting {
+		return errExiting
+	}
+	if _, other := set.find(to); other != nil {
+		return errAlreadyRegistered
+	}
+	r.Address = to
+	if err := storeSet(db, chain, set); err != nil {
+		return err
+	}
+
+	work, err := LoadWork(db, epoch)
+	if err != nil {
+		return err
+	}
+	var moved *Submission
+	submissions := work.Submissions[:0]
+	for _, s := range work.Submissions {
+		if s.Relayer == from && s.Chain == chain {
+			moved = s
+			continue
+		}
+		submissions = append(submissions, s)
+	}
+	if moved == nil {
+		return nil
+	}
+	work.Submissions = submissions
+	if err := store(db, workDbKey(epoch), work); err != nil {
+		return err
+	}
+	return RecordSubmission(db, epoch, chain, to, moved.Headers)
+}
+
+// IsRelayer reports whether the address is an active relayer of the chain.
+func IsRelayer(db types.StateDB, chain uint64, addr common.Address) bool {
+	set, err := LoadSet(db, chain)
+	if err != nil {
+		return false
+	}
+	_, r := set.find(addr)
+	return r != nil && !r.Exiting
+}
+
+// RecordSubmission credits the relayer with headers of the chain in the epoch.
+func RecordSubmission(db types.StateDB, epoch, chain uint64, addr common.Address, headers uint64) error {
+	work, err := LoadWork(db, epoch)
+	if err != nil {
+		return err
+	}
+	for _, s := range work.Submissions {
+		if s.Relayer == addr && s.Chain == chain {
+			s.Headers += headers
+			return store(db, workDbKey(epoch), work)
+		}
+	}
+	work.Submissions = append(work.Submissions, &Submission{Relayer: addr, Chain: chain, Headers: headers})
+	return store(db, workDbKey(epoch), work)
+}
+
+// Settle closes the epoch. The reward is split between the relayers in proportion to
+// the headers they submitted, relayers that were registered for the whole epoch but
+// submitted nothing lose part of their stake, and exiting relayers get their stake
+// back. The submissions of the epoch are cleared. The returned payouts are not
+// transferred, the caller mints them.
+func Settle(db types.StateDB, epoch uint64, reward *big.Int) ([]*Payout, error) {
+	work, err := LoadWork(db, epoch)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		total   uint64
+		active  = make(map[uint64]map[common.Address]bool)
+		payouts []*Payout
+	)
+	for _, s := range work.Submissions {
+		total += s.Headers
+		if active[s.Chain] == nil {
+			active[s.Chain] = make(map[common.Address]bool)
+		}
+		active[s.Chain][s.Relayer] = true
+	}
+	if total > 0 && reward.Sign() > 0 {
+		earned := make(map[common.Address]*Payout)
+		for _, s := range work.Submissions {
+			amount := new(big.Int).Mul(reward, new(big.Int).SetUint64(s.Headers))
+			amount.Div(amount, new(big.Int).SetUint64(total))
+			if p, ok := earned[s.Relayer]; ok {
+				p.Amount.Add(p.Amount, amount)
+				continue
+			}
+			p := &Payout{Relayer: s.Relayer, Amount: amount}
+			earned[s.Relayer] = p
+			payouts = append(payouts, p)
+		}
+	}
+
+	chains, err := loadChains(db)
+	if err != nil {
+		return nil, err
+	}
+	for _, chain := range chains {
+		set, err := LoadSet(db, chain)
+		if err != nil {
+			return nil, err
+		}
+		relayers := set.Relayers[:0]
+		for _, r := range set.Relayers {
+			if r.Epoch < epoch && !active[chain][r.Address] {
+				penalty := new(big.Int).Mul(r.Stake, params.RelayerPenaltyPercent)
+				penalty.Div(penalty, big.NewInt(100))
+				r.Stake.Sub(r.Stake, penalty)
+				db.SubBalance(params.RelayerRegistryAddress, penalty)
+				log.Info("penalize inactive relayer", "chain", chain, "relayer", r.Address, "penalty", penalty)
+			}
+			if r.Exiting {
+				db.SubBalance(params.RelayerRegistryAddress, r.Stake)
+				db.AddBalance(r.Address, r.Stake)
+				log.Info("return relayer stake", "chain", chain, "relayer", r.Address, "stake", r.Stake)
+				continue
+			}
+			relayers = append(relayers, r)
+		}
+		set.Relayers = relayers
+		if err := storeSet(db, chain, set); err != nil {
+			return nil, err
+		}
+	}
+	remove(db, workDbKey(epoch))
+	return payouts, nil
+}
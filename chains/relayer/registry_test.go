@@ -0,0 +1,138 @@
+package relayer
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mapprotocol/atlas/core/rawdb"
+	"github.com/mapprotocol/atlas/core/state"
+	"github.com/mapprotocol/atlas/params"
+)
+
+const (
+	chainA uint64 = 1
+	chainB uint64 = 56
+)
+
+var (
+	alice = common.Address{0xa}
+	bob   = common.Address{0xb}
+	carol = common.Address{0xc}
+)
+
+func getStateDB() *state.StateDB {
+	db, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	return db
+}
+
+// register stakes the minimum stake, as if it had been sent along with the call.
+func register(t *testing.T, db *state.StateDB, chain uint64, addr common.Address, epoch uint64) {
+	db.AddBalance(params.RelayerRegistryAddress, params.RelayerMinStake)
+	assert.NoError(t, Register(db, chain, addr, params.RelayerMinStake, epoch))
+}
+
+func TestRegistry(t *testing.T) {
+	db := getStateDB()
+
+	assert.Equal(t, errStakeTooLow, Register(db, chainA, alice, big.NewInt(1), 1))
+	register(t, db, chainA, alice, 1)
+	assert.Equal(t, errAlreadyRegistered, Register(db, chainA, alice, params.RelayerMinStake, 1))
+	register(t, db, chainB, alice, 1)
+	register(t, db, chainA, bob, 1)
+
+	assert.True(t, IsRelayer(db, chainA, alice))
+	assert.True(t, IsRelayer(db, chainB, alice))
+	assert.False(t, IsRelayer(db, chainB, bob))
+
+	assert.Equal(t, errAlreadyRegistered, Rotate(db, chainA, bob, alice, 1))
+	assert.NoError(t, Rotate(db, chainA, bob, carol, 1))
+	assert.False(t, IsRelayer(db, chainA, bob))
+	assert.True(t, IsRelayer(db, chainA, carol))
+
+	assert.Equal(t, errNotRegistered, Deregister(db, chainB, carol))
+	assert.NoError(t, Deregister(db, chainB, alice))
+	assert.Equal(t, errExiting, Deregister(db, chainB, alice))
+	assert.Equal(t, errExiting, Rotate(db, chainB, alice, bob, 1))
+	assert.False(t, IsRelayer(db, chainB, alice))
+
+	set, err := LoadSet(db, chainA)
+	assert.NoError(t, err)
+	assert.Len(t, set.Relayers, 2)
+}
+
+func TestSettle(t *testing.T) {
+	db := getStateDB()
+	register(t, db, chainA, alice, 1)
+	register(t, db, chainA, bob, 1)
+	register(t, db, chainB, carol, 1)
+	register(t, db, chainB, bob, 2)
+	assert.NoError(t, Deregister(db, chainB, carol))
+
+	assert.NoError(t, RecordSubmission(db, 2, chainA, alice, 10))
+	assert.NoError(t, RecordSubmission(db, 2, chainA, alice, 20))
+	assert.NoError(t, RecordSubmission(db, 2, chainB, carol, 10))
+	work, err := LoadWork(db, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []*Submission{{alice, chainA, 30}, {carol, chainB, 10}}, work.Submissions)
+
+	payouts, err := Settle(db, 2, big.NewInt(1000))
+	assert.NoError(t, err)
+	assert.Equal(t, []*Payout{{alice, big.NewInt(750)}, {carol, big.NewInt(250)}}, payouts)
+
+	// the submissions of a settled epoch are cleared
+	assert.Empty(t, db.GetPOWState(params.RelayerRegistryAddress, workDbKey(2)))
+	work, err = LoadWork(db, 2)
+	assert.NoError(t, err)
+	assert.Empty(t, work.Submissions)
+
+	// bob relayed nothing for chain A during the whole epoch, and joined chain B in it
+	penalty := new(big.Int).Div(params.RelayerMinStake, big.NewInt(100))
+	setA, err := LoadSet(db, chainA)
+	assert.NoError(t, err)
+	assert.Equal(t, params.RelayerMinStake, setA.Relayers[0].Stake)
+	assert.Equal(t, new(big.Int).Sub(params.RelayerMinStake, penalty), setA.Relayers[1].Stake)
+
+	// carol left with the stake returned
+	setB, err := LoadSet(db, chainB)
+	assert.NoError(t, err)
+	assert.Len(t, setB.Relayers, 1)
+	assert.Equal(t, bob, setB.Relayers[0].Address)
+	assert.Equal(t, params.RelayerMinStake, db.GetBalance(carol))
+
+	held := new(big.Int).Mul(params.RelayerMinStake, big.NewInt(3))
+	assert.Equal(t, held.Sub(held, penalty), db.GetBalance(params.RelayerRegistryAddress))
+
+	payouts, err = Settle(db, 3, big.NewInt(1000))
+	assert.NoError(t, err)
+	assert.Empty(t, payouts)
+}
+
+func TestSettleRotated(t *testing.T) {
+	db := getStateDB()
+	register(t, db, chainA, alice, 1)
+	register(t, db, chainA, bob, 1)
+	register(t, db, chainB, alice, 1)
+
+	// alice rotates on chain A mid-epoch, her submissions of chain B stay hers
+	assert.NoError(t, RecordSubmission(db, 2, chainA, alice, 10))
+	assert.NoError(t, RecordSubmission(db, 2, chainB, alice, 10))
+	assert.NoError(t, RecordSubmission(db, 2, chainA, bob, 20))
+	assert.NoError(t, Rotate(db, chainA, alice, carol, 2))
+	assert.NoError(t, RecordSubmission(db, 2, chainA, carol, 20))
+	work, err := LoadWork(db, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []*Submission{{alice, chainB, 10}, {bob, chainA, 20}, {carol, chainA, 30}}, work.Submissions)
+
+	payouts, err := Settle(db, 2, big.NewInt(600))
+	assert.NoError(t, err)
+	assert.Equal(t, []*Payout{{alice, big.NewInt(100)}, {bob, big.NewInt(200)}, {carol, big.NewInt(300)}}, payouts)
+
+	// the rotated relayer was active for the whole epoch
+	set, err := LoadSet(db, chainA)
+	assert.NoError(t, err)
+	assert.Equal(t, carol, set.Relayers[0].Address)
+	assert.Equal(t, params.RelayerMinStake, set.Relayers[0].Stake)
+}
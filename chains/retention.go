@@ -0,0 +1,64 @@
+package chains
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/mapprotocol/atlas/params"
+)
+
+// Retention is the policy a header store uses to bound the headers it keeps.
+type Retention struct {
+	// Window is the number of most recent heights kept in the ring buffer.
+	Window uint64
+	// CheckpointInterval is the spacing of the canonical hashes that are kept after
+	// they left the window, for long-range lookups. Zero disables checkpoints.
+	CheckpointInterval uint64
+	// FinalityDepth is the depth below the head from which side chain headers and
+	// their TDs are pruned, it must be smaller than the window.
+	FinalityDepth uint64
+}
+
+// IRetentionStore is implemented by header stores with a configurable retention
+// policy. The policy is applied when the store is reset, so it is fixed by the atlas
+// block of the reset, see RetentionAt.
+type IRetentionStore interface {
+	SetRetention(r *Retention)
+}
+
+var (
+	errZeroWindow       = errors.New("retention window cannot be zero")
+	errFinalityDepth    = errors.New("retention finality depth must be positive and within the window")
+	errCheckpointWindow = errors.New("retention checkpoint interval must not exceed the window")
+)
+
+// Validate checks that the policy can be applied to a header store.
+func (r *Retention) Validate() error {
+	if r.Window == 0 {
+		return errZeroWindow
+	}
+	if r.FinalityDepth == 0 || r.FinalityDepth >= r.Window {
+		return errFinalityDepth
+	}
+	if r.CheckpointInterval > r.Window {
+		return errCheckpointWindow
+	}
+	return nil
+}
+
+// IsCheckpoint reports whether the canonical hash of the height is kept after it
+// left the window.
+func (r *Retention) IsCheckpoint(number uint64) bool {
+	return r.CheckpointInterval != 0 && number%r.CheckpointInterval == 0
+}
+
+// RetentionAt returns the retention policy of a header store reset at the atlas
+// block, nil if the store keeps the legacy layout of stores reset before the
+// header retention fork.
+func RetentionAt(config *params.ChainConfig, number *big.Int) *Retention {
+	r := config.HeaderRetentionAt(number)
+	if r == nil {
+		return nil
+	}
+	return &Retention{Window: r.Window, CheckpointInterval: r.CheckpointInterval, FinalityDepth: r.FinalityDepth}
+}
@@ -0,0 +1,40 @@
+package chains
+
+import (
+	"github.com/ethereum/go-ethereum/rlp"
+
+	"github.com/mapprotocol/atlas/core/types"
+)
+
+// Work is the verification work a light client does on an input, the precompiles
+// charge gas for each unit of it.
+type Work struct {
+	Headers     uint64 // headers decoded, hashed and checked against their parent
+	PoWSeals    uint64 // ethash seals verified
+	Signatures  uint64 // ECDSA seals recovered or ed25519 signatures verified
+	StateWrites uint64 // header store entries written to the state
+	PubKeys     uint64 // BLS public keys decompressed and aggregated
+	Pairings    uint64 // BLS pairings
+}
+
+// IWorkEstimator is implemented by validators that can tell the work an input makes
+// them and the header store do, without doing it. The work depending on the stored
+// headers, such as rewriting the canonical chain on a reorg, is read from db; with a
+// nil db the input is assumed to extend the stored head.
+type IWorkEstimator interface {
+	EstimateWork(db types.StateDB, input []byte) (*Work, error)
+}
+
+// CountRLPList returns the number of items in an rlp encoded list without decoding
+// them.
+func CountRLPList(input []byte) (uint64, error) {
+	content, _, err := rlp.SplitList(input)
+	if err != nil {
+		return 0, err
+	}
+	n, err := rlp.CountValues(content)
+	if err != nil {
+		return 0, err
+	}
+	return uint64(n), nil
+}
@@ -84,6 +84,7 @@ var (
 		utils.GCModeFlag,
 		utils.SnapshotFlag,
 		utils.TxLookupLimitFlag,
+		utils.ChainsDBFlag,
 		utils.LightServeFlag,
 		utils.LightIngressFlag,
 		utils.LightEgressFlag,
@@ -220,6 +221,10 @@ func init() {
 		utils.ShowDeprecated,
 		// See snapshot.go
 		snapshotCommand,
+		// See relayercmd.go
+		relayerCommand,
+		// See signercmd.go
+		signerCommand,
 	}
 	sort.Sort(cli.CommandsByName(app.Commands))
 
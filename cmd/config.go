@@ -163,8 +163,6 @@ func makeFullNode(ctx *cli.Context) (*node.Node, atlasapi.Backend) {
 	//	cfg.Eth.OverrideLondon = new(big.Int).SetUint64(ctx.GlobalUint64(utils.OverrideLondonFlag.Name))
 	//}
 	backend, eth := utils.RegisterEthService(stack, &cfg.Eth)
-	// todo init header store
-	//chainsdb.NewStoreDb(ctx, cfg.Eth.DatabaseCache, cfg.Eth.DatabaseHandles)
 	// Configure catalyst.
 	if ctx.GlobalBool(utils.CatalystFlag.Name) {
 		if eth == nil {
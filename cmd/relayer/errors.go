@@ -0,0 +1,11 @@
+package relayer
+
+import "errors"
+
+var (
+	errHeaderNotFound = errors.New("header not found on the source chain")
+	errHashMismatch   = errors.New("encoded header does not match its hash")
+	errDiverged       = errors.New("local header store diverged from atlas")
+	errBudgetTooLow   = errors.New("gas budget too low for a single header")
+	errTxFailed       = errors.New("updateBlockHeader transaction failed")
+)
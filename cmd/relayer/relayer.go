@@ -0,0 +1,413 @@
+// Package relayer relays the headers of a chain followed by an atlas light client to
+// the header store of atlas.
+//
+// The relayer keeps a local copy of the header store started from the input the
+// header store was reset with. Headers are validated on it with the code of the light
+// client before they are submitted, so batches atlas would reject are not paid for.
+// The local store is committed to the configured database after every batch and
+// reopened on restart, as long as it was started from the same trusted input. On start
+// it is caught up with the head of the header store on atlas, which only replays the
+// headers since the last commit, or all of them if it is kept in memory.
+package relayer
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+	ethparams "github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rlp"
+
+	"github.com/mapprotocol/atlas/chains"
+	"github.com/mapprotocol/atlas/chains/interfaces"
+	"github.com/mapprotocol/atlas/core/rawdb"
+	"github.com/mapprotocol/atlas/core/state"
+	"github.com/mapprotocol/atlas/core/vm"
+	"github.com/mapprotocol/atlas/params"
+)
+
+const (
+	DefaultGasBudget  = 10000000
+	DefaultMaxHeaders = 64
+	DefaultInterval   = 10 * time.Second
+)
+
+type Config struct {
+	// Chain is the chain relayed.
+	Chain chains.ChainType
+	// AtlasConfig is the config of the atlas chain, it selects the light client of the
+	// chain and the gas schedule of the header store.
+	AtlasConfig *params.ChainConfig
+	// Trusted is the input the header store of the chain was reset with, and TrustedTd
+	// the total difficulty passed along. The local header store starts from it.
+	Trusted   []byte
+	TrustedTd *big.Int
+	// GasBudget bounds the gas of a submission and MaxHeaders the headers in it.
+	GasBudget  uint64
+	MaxHeaders uint64
+	// Confirmations is the number of headers a header must be buried under on the
+	// source chain before it is relayed.
+	Confirmations uint64
+	// Interval is the wait between two polls of the source chain once it is caught up.
+	Interval time.Duration
+	// Database keeps the local header store across restarts, it is kept in memory if
+	// nil.
+	Database ethdb.Database
+}
+
+// storeKey is the key of the local header store of a chain in the database.
+var storeKey = []byte("relayer-store-")
+
+// storedStore is the root of the committed local header store and the hash of the
+// trusted input it was started from.
+type storedStore struct {
+	Trusted common.Hash
+	Root    common.Hash
+}
+
+// updateBlockHeaderArgs is the rlp encoded argument of updateBlockHeader.
+type updateBlockHeaderArgs struct {
+	From    *big.Int
+	To      *big.Int
+	Headers []byte
+}
+
+type Relayer struct {
+	config  *Config
+	source  Source
+	target  Target
+	diskdb  ethdb.Database
+	stateDb state.Database
+	db      *state.StateDB
+	trusted common.Hash
+	now     func() time.Time
+}
+
+// New returns a relayer with the local header store committed to the database, or
+// with one reset with the trusted input if there is none or it was started from
+// another input.
+func New(ctx context.Context, config *Config, source Source, target Target) (*Relayer, error) {
+	diskdb := config.Database
+	if diskdb == nil {
+		diskdb = rawdb.NewMemoryDatabase()
+	}
+	r := &Relayer{
+		config:  config,
+		source:  source,
+		target:  target,
+		diskdb:  diskdb,
+		stateDb: state.NewDatabase(diskdb),
+		trusted: trustedHash(config),
+		now:     time.Now,
+	}
+	if stored := r.readStore(); stored != nil && stored.Trusted == r.trusted {
+		if db, err := state.New(stored.Root, r.stateDb, nil); err == nil {
+			r.db = db
+			log.Info("loaded local header store", "chain", config.Chain, "root", stored.Root)
+			return r, nil
+		}
+		log.Warn("local header store is missing, resetting it", "chain", config.Chain, "root", stored.Root)
+	}
+	db, err := state.New(common.Hash{}, r.stateDb, nil)
+	if err != nil {
+		return nil, err
+	}
+	r.db = db
+
+	number, err := target.BlockNumber(ctx)
+	if err != nil {
+		return nil, err
+	}
+	group, err := chains.ChainType2ChainGroupAt(config.AtlasConfig, new(big.Int).SetUint64(number), config.Chain)
+	if err != nil {
+		return nil, err
+	}
+	hs, err := interfaces.HeaderStoreFactory(group)
+	if err != nil {
+		return nil, err
+	}
+	if cc, ok := hs.(chains.IChainConfigurable); ok {
+		cc.SetChainConfig(config.AtlasConfig)
+	}
+	if rs, ok := hs.(chains.IRetentionStore); ok {
+		rs.SetRetention(chains.RetentionAt(config.AtlasConfig, new(big.Int).SetUint64(number)))
+	}
+	if err := hs.ResetHeaderStore(db, config.Trusted, config.TrustedTd); err != nil {
+		return nil, fmt.Errorf("reset local header store: %w", err)
+	}
+	if err := r.commit(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// trustedHash identifies the trusted input the local header store is started from.
+func trustedHash(config *Config) common.Hash {
+	var td []byte
+	if config.TrustedTd != nil {
+		td = config.TrustedTd.Bytes()
+	}
+	return crypto.Keccak256Hash(config.Trusted, td)
+}
+
+func storeKeyOf(chain chains.ChainType) []byte {
+	key := make([]byte, len(storeKey)+8)
+	copy(key, storeKey)
+	binary.BigEndian.PutUint64(key[len(storeKey):], uint64(chain))
+	return key
+}
+
+// readStore returns the local header store committed to the database, nil if there
+// is none.
+func (r *Relayer) readStore() *storedStore {
+	data, _ := r.diskdb.Get(storeKeyOf(r.config.Chain))
+	if len(data) == 0 {
+		return nil
+	}
+	stored := new(storedStore)
+	if err := rlp.DecodeBytes(data, stored); err != nil {
+		log.Warn("invalid local header store record", "chain", r.config.Chain, "err", err)
+		return nil
+	}
+	return stored
+}
+
+// commit writes the local header store to the database and records its root.
+func (r *Relayer) commit() error {
+	root, err := r.db.Commit(false)
+	if err != nil {
+		return err
+	}
+	if err := r.stateDb.TrieDB().Commit(root, false, nil); err != nil {
+		return err
+	}
+	data, err := rlp.EncodeToBytes(&storedStore{Trusted: r.trusted, Root: root})
+	if err != nil {
+		return err
+	}
+	if err := r.diskdb.Put(storeKeyOf(r.config.Chain), data); err != nil {
+		return err
+	}
+	db, err := state.New(root, r.stateDb, nil)
+	if err != nil {
+		return err
+	}
+	r.db = db
+	return nil
+}
+
+// chain returns the light client of the chain at the atlas block, configured as the
+// header store precompile configures it.
+func (r *Relayer) chain(number *big.Int) (interfaces.IChain, error) {
+	group, err := chains.ChainType2ChainGroupAt(r.config.AtlasConfig, number, r.config.Chain)
+	if err != nil {
+		return nil, err
+	}
+	chain, err := interfaces.ChainFactory(group)
+	if err != nil {
+		return nil, err
+	}
+	if cc, ok := chain.(chains.IChainConfigurable); ok {
+		cc.SetChainConfig(r.config.AtlasConfig)
+	}
+	if bc, ok := chain.(chains.IBlockConfigurable); ok {
+		bc.SetBlockNumber(number)
+	}
+	if cc, ok := chain.(chains.IClockConfigurable); ok {
+		cc.SetBlockTime(uint64(r.now().Unix()))
+	}
+	return chain, nil
+}
+
+// Run relays headers until the context is cancelled. Errors talking to the chains
+// are retried, a local header store that diverged from atlas stops the relay.
+func (r *Relayer) Run(ctx context.Context) error {
+	for {
+		n, err := r.Step(ctx)
+		switch {
+		case ctx.Err() != nil:
+			return nil
+		case err == errDiverged || err == errBudgetTooLow:
+			return err
+		case err != nil:
+			log.Warn("relay header batch failed", "chain", r.config.Chain, "err", err)
+		case n > 0:
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(r.config.Interval):
+		}
+	}
+}
+
+// Step catches the local header store up with atlas, then submits the next batch of
+// headers. It returns the number of headers submitted.
+func (r *Relayer) Step(ctx context.Context) (int, error) {
+	atlasNumber, err := r.target.BlockNumber(ctx)
+	if err != nil {
+		return 0, err
+	}
+	number := new(big.Int).SetUint64(atlasNumber)
+	chain, err := r.chain(number)
+	if err != nil {
+		return 0, err
+	}
+
+	head, err := r.catchUp(ctx, chain)
+	if err != nil {
+		return 0, err
+	}
+	sourceHead, err := r.source.HeadNumber(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if sourceHead < r.config.Confirmations || sourceHead-r.config.Confirmations <= head {
+		return 0, nil
+	}
+	to := sourceHead - r.config.Confirmations
+	if to > head+r.config.MaxHeaders {
+		to = head + r.config.MaxHeaders
+	}
+	headers, err := r.headers(ctx, head+1, to)
+	if err != nil {
+		return 0, err
+	}
+
+	input, payload, n, err := r.batch(number, headers)
+	if err != nil {
+		return 0, err
+	}
+	if err := r.validate(chain, payload, head+1); err != nil {
+		return 0, err
+	}
+	if err := r.target.Submit(ctx, input); err != nil {
+		return 0, err
+	}
+	if err := r.insert(chain, payload); err != nil {
+		return 0, err
+	}
+	log.Info("relayed headers", "chain", r.config.Chain, "from", head+1, "to", head+uint64(n))
+	return n, nil
+}
+
+// catchUp applies to the local header store the headers atlas has and it has not,
+// and checks that both agree on the head. It returns the head.
+func (r *Relayer) catchUp(ctx context.Context, chain interfaces.IChain) (uint64, error) {
+	atlasHead, atlasHash, err := r.target.CurrentNumberAndHash(ctx, r.config.Chain)
+	if err != nil {
+		return 0, err
+	}
+	head, _, err := chain.GetCurrentNumberAndHash(r.db)
+	if err != nil {
+		return 0, err
+	}
+	for head < atlasHead {
+		to := atlasHead
+		if to > head+r.config.MaxHeaders {
+			to = head + r.config.MaxHeaders
+		}
+		headers, err := r.headers(ctx, head+1, to)
+		if err != nil {
+			return 0, err
+		}
+		payload, err := rlp.EncodeToBytes(headers)
+		if err != nil {
+			return 0, err
+		}
+		if err := r.validate(chain, payload, head+1); err != nil {
+			return 0, err
+		}
+		if err := r.insert(chain, payload); err != nil {
+			return 0, err
+		}
+		log.Info("caught up with atlas", "chain", r.config.Chain, "number", to, "atlas", atlasHead)
+		head = to
+	}
+	if head != atlasHead {
+		log.Error("local header store is ahead of atlas", "chain", r.config.Chain, "local", head, "atlas", atlasHead)
+		return 0, errDiverged
+	}
+	hash, err := chain.GetHashByNumber(r.db, head)
+	if err != nil {
+		return 0, err
+	}
+	if hash != atlasHash {
+		log.Error("local header store diverged from atlas", "chain", r.config.Chain, "number", head, "local", hash, "atlas", atlasHash)
+		return 0, errDiverged
+	}
+	return head, nil
+}
+
+func (r *Relayer) headers(ctx context.Context, from, to uint64) ([]rlp.RawValue, error) {
+	headers := make([]rlp.RawValue, 0, to-from+1)
+	for number := from; number <= to; number++ {
+		header, err := r.source.Header(ctx, number)
+		if err != nil {
+			return nil, fmt.Errorf("header %d: %w", number, err)
+		}
+		headers = append(headers, header)
+	}
+	return headers, nil
+}
+
+// batch returns the updateBlockHeader input of the longest run of the headers fitting
+// the gas budget, the encoded headers in it and their number.
+func (r *Relayer) batch(number *big.Int, headers []rlp.RawValue) ([]byte, []byte, int, error) {
+	for n := len(headers); n > 0; n-- {
+		payload, err := rlp.EncodeToBytes(headers[:n])
+		if err != nil {
+			return nil, nil, 0, err
+		}
+		arg, err := rlp.EncodeToBytes(&updateBlockHeaderArgs{
+			From:    new(big.Int).SetUint64(uint64(r.config.Chain)),
+			To:      r.config.AtlasConfig.ChainID,
+			Headers: payload,
+		})
+		if err != nil {
+			return nil, nil, 0, err
+		}
+		input, err := abiHeaderStore.Pack("updateBlockHeader", arg)
+		if err != nil {
+			return nil, nil, 0, err
+		}
+		if txGas(input)+vm.UpdateBlockHeaderGas(r.config.AtlasConfig, number, input) <= r.config.GasBudget {
+			return input, payload, n, nil
+		}
+	}
+	return nil, nil, 0, errBudgetTooLow
+}
+
+func (r *Relayer) validate(chain interfaces.IChain, payload []byte, first uint64) error {
+	if i, err := chain.ValidateHeaderChain(r.db, payload, r.config.Chain); err != nil {
+		return fmt.Errorf("invalid header %d: %w", first+uint64(i), err)
+	}
+	return nil
+}
+
+func (r *Relayer) insert(chain interfaces.IChain, payload []byte) error {
+	if _, err := chain.InsertHeaders(r.db, payload); err != nil {
+		return err
+	}
+	return r.commit()
+}
+
+// txGas returns the intrinsic gas of a call with the input.
+func txGas(input []byte) uint64 {
+	gas := ethparams.TxGas
+	for _, b := range input {
+		if b == 0 {
+			gas += ethparams.TxDataZeroGas
+		} else {
+			gas += ethparams.TxDataNonZeroGasEIP2028
+		}
+	}
+	return gas
+}
@@ -0,0 +1,394 @@
+package relayer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mapprotocol/atlas/chains"
+	"github.com/mapprotocol/atlas/core/rawdb"
+	"github.com/mapprotocol/atlas/core/state"
+	"github.com/mapprotocol/atlas/core/types"
+	"github.com/mapprotocol/atlas/core/vm"
+	"github.com/mapprotocol/atlas/params"
+)
+
+const (
+	testGroup chains.ChainGroup = 9999
+	testChain chains.ChainType  = 4242
+)
+
+var (
+	testStoreAddress = common.BytesToAddress([]byte("RelayerTestHeaderStore"))
+	testHeadKey      = common.BytesToHash([]byte("head"))
+	errTestParent    = errors.New("header does not extend the head")
+)
+
+func init() {
+	chains.Register(&chains.Module{
+		Group:          testGroup,
+		Chains:         map[chains.ChainType]*chains.ChainParams{testChain: {AtlasChainID: params.TestNetChainID}},
+		NewValidate:    func() chains.IValidate { return new(testStore) },
+		NewHeaderStore: func() chains.IHeaderStore { return new(testStore) },
+	})
+}
+
+// testStore is a light client accepting any header extending its head.
+type testStore struct{}
+
+func (s *testStore) head(db types.StateDB) (uint64, common.Hash) {
+	var head struct {
+		Number uint64
+		Hash   common.Hash
+	}
+	_ = rlp.DecodeBytes(db.GetPOWState(testStoreAddress, testHeadKey), &head)
+	return head.Number, head.Hash
+}
+
+func (s *testStore) setHead(db types.StateDB, h *Header) {
+	data, _ := rlp.EncodeToBytes([]interface{}{h.Number.Uint64(), rlpHash(h)})
+	db.SetPOWState(testStoreAddress, testHeadKey, data)
+	db.SetPOWState(testStoreAddress, common.BigToHash(h.Number), rlpHash(h).Bytes())
+}
+
+func (s *testStore) ValidateHeaderChain(db types.StateDB, input []byte, _ chains.ChainType) (int, error) {
+	var headers []*Header
+	if err := rlp.DecodeBytes(input, &headers); err != nil {
+		return 0, err
+	}
+	number, hash := s.head(db)
+	for i, h := range headers {
+		if h.Number.Uint64() != number+1 || h.ParentHash != hash || string(h.Extra) == "invalid" {
+			return i, errTestParent
+		}
+		number, hash = h.Number.Uint64(), rlpHash(h)
+	}
+	return 0, nil
+}
+
+func (s *testStore) ResetHeaderStore(db types.StateDB, input []byte, _ *big.Int) error {
+	var h Header
+	if err := rlp.DecodeBytes(input, &h); err != nil {
+		return err
+	}
+	s.setHead(db, &h)
+	return nil
+}
+
+func (s *testStore) InsertHeaders(db types.StateDB, input []byte) ([]*params.NumberHash, error) {
+	if _, err := s.ValidateHeaderChain(db, input, testChain); err != nil {
+		return nil, err
+	}
+	var headers []*Header
+	_ = rlp.DecodeBytes(input, &headers)
+	nums := make([]*params.NumberHash, 0, len(headers))
+	for _, h := range headers {
+		s.setHead(db, h)
+		nums = append(nums, &params.NumberHash{Number: h.Number.Uint64(), Hash: rlpHash(h)})
+	}
+	return nums, nil
+}
+
+func (s *testStore) GetCurrentNumberAndHash(db types.StateDB) (uint64, common.Hash, error) {
+	number, hash := s.head(db)
+	return number, hash, nil
+}
+
+func (s *testStore) GetHashByNumber(db types.StateDB, number uint64) (common.Hash, error) {
+	return common.BytesToHash(db.GetPOWState(testStoreAddress, common.BigToHash(new(big.Int).SetUint64(number)))), nil
+}
+
+func rlpHash(h *Header) common.Hash {
+	enc, _ := rlp.EncodeToBytes(h)
+	return crypto.Keccak256Hash(enc)
+}
+
+func newStateDB() *state.StateDB {
+	db, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	return db
+}
+
+// makeChain returns the headers from genesis to number n.
+func makeChain(seed string, n uint64) []*Header {
+	headers := make([]*Header, 0, n+1)
+	parent := common.Hash{}
+	for i := uint64(0); i <= n; i++ {
+		baseFee := big.NewInt(7)
+		h := &Header{
+			ParentHash: parent,
+			Coinbase:   common.BytesToAddress([]byte(seed)),
+			Root:       crypto.Keccak256Hash([]byte(fmt.Sprintf("%s-%d", seed, i))),
+			Difficulty: big.NewInt(2),
+			Number:     new(big.Int).SetUint64(i),
+			GasLimit:   30000000,
+			Time:       1700000000 + i*3,
+			Extra:      make([]byte, 97),
+			BaseFee:    baseFee,
+		}
+		headers = append(headers, h)
+		parent = rlpHash(h)
+	}
+	return headers
+}
+
+// testService is a stand-in JSON-RPC endpoint of the source chain.
+type testService struct {
+	mu      sync.Mutex
+	headers []*Header
+	badHash uint64
+	fetched []uint64
+}
+
+func (s *testService) BlockNumber() hexutil.Uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return hexutil.Uint64(len(s.headers) - 1)
+}
+
+func (s *testService) GetBlockByNumber(number hexutil.Uint64, _ bool) *rpcHeader {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.fetched = append(s.fetched, uint64(number))
+	if uint64(number) >= uint64(len(s.headers)) {
+		return nil
+	}
+	h := s.headers[number]
+	hash := rlpHash(h)
+	if s.badHash != 0 && uint64(number) == s.badHash {
+		hash = common.Hash{1}
+	}
+	return &rpcHeader{
+		Hash:       hash,
+		ParentHash: h.ParentHash,
+		Coinbase:   h.Coinbase,
+		Root:       h.Root,
+		Difficulty: (*hexutil.Big)(h.Difficulty),
+		Number:     (*hexutil.Big)(h.Number),
+		GasLimit:   hexutil.Uint64(h.GasLimit),
+		Time:       hexutil.Uint64(h.Time),
+		Extra:      h.Extra,
+		BaseFee:    (*hexutil.Big)(h.BaseFee),
+	}
+}
+
+func newTestSource(t *testing.T, service *testService) *EVMSource {
+	server := rpc.NewServer()
+	require.NoError(t, server.RegisterName("eth", service))
+	t.Cleanup(server.Stop)
+	return NewEVMSource(rpc.DialInProc(server))
+}
+
+// testTarget runs the header store of the test chain as the precompile does.
+type testTarget struct {
+	db          *state.StateDB
+	submissions []int
+}
+
+func newTestTarget(genesis *Header) *testTarget {
+	t := &testTarget{db: newStateDB()}
+	(&testStore{}).setHead(t.db, genesis)
+	return t
+}
+
+func (t *testTarget) BlockNumber(context.Context) (uint64, error) {
+	return 100, nil
+}
+
+func (t *testTarget) CurrentNumberAndHash(_ context.Context, chain chains.ChainType) (uint64, common.Hash, error) {
+	return new(testStore).GetCurrentNumberAndHash(t.db)
+}
+
+func (t *testTarget) Submit(_ context.Context, input []byte) error {
+	if gas := txGas(input) + vm.UpdateBlockHeaderGas(params.TestChainConfig, big.NewInt(100), input); gas > testGasBudget {
+		return fmt.Errorf("gas %d over budget", gas)
+	}
+	method, err := abiHeaderStore.MethodById(input)
+	if err != nil {
+		return err
+	}
+	out, err := method.Inputs.Unpack(input[4:])
+	if err != nil {
+		return err
+	}
+	var args updateBlockHeaderArgs
+	if err := rlp.DecodeBytes(out[0].([]byte), &args); err != nil {
+		return err
+	}
+	if args.From.Uint64() != uint64(testChain) || args.To.Cmp(params.TestChainConfig.ChainID) != 0 {
+		return errors.New("wrong chains")
+	}
+	nums, err := new(testStore).InsertHeaders(t.db, args.Headers)
+	if err != nil {
+		return err
+	}
+	t.submissions = append(t.submissions, len(nums))
+	return nil
+}
+
+const testGasBudget = 200000
+
+func newTestRelayer(t *testing.T, source Source, target Target, genesis *Header) *Relayer {
+	return newTestRelayerWithDB(t, source, target, genesis, nil)
+}
+
+func newTestRelayerWithDB(t *testing.T, source Source, target Target, genesis *Header, db ethdb.Database) *Relayer {
+	trusted, _ := rlp.EncodeToBytes(genesis)
+	r, err := New(context.Background(), &Config{
+		Chain:         testChain,
+		AtlasConfig:   params.TestChainConfig,
+		Trusted:       trusted,
+		GasBudget:     testGasBudget,
+		MaxHeaders:    DefaultMaxHeaders,
+		Confirmations: 2,
+		Database:      db,
+	}, source, target)
+	require.NoError(t, err)
+	return r
+}
+
+// relayAll steps the relayer until it has nothing left to submit.
+func relayAll(t *testing.T, r *Relayer) {
+	for i := 0; i < 100; i++ {
+		n, err := r.Step(context.Background())
+		require.NoError(t, err)
+		if n == 0 {
+			return
+		}
+	}
+	t.Fatal("relay did not catch up")
+}
+
+func TestEVMSource_Header(t *testing.T) {
+	headers := makeChain("a", 5)
+	service := &testService{headers: headers, badHash: 4}
+	source := newTestSource(t, service)
+
+	head, err := source.HeadNumber(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(5), head)
+
+	enc, err := source.Header(context.Background(), 3)
+	assert.NoError(t, err)
+	var h Header
+	assert.NoError(t, rlp.DecodeBytes(enc, &h))
+	assert.Equal(t, rlpHash(headers[3]), rlpHash(&h))
+
+	_, err = source.Header(context.Background(), 4)
+	assert.Equal(t, errHashMismatch, err)
+	_, err = source.Header(context.Background(), 6)
+	assert.Equal(t, errHeaderNotFound, err)
+}
+
+func TestRelayer_Step(t *testing.T) {
+	headers := makeChain("a", 40)
+	service := &testService{headers: headers[:31]}
+	target := newTestTarget(headers[0])
+	r := newTestRelayer(t, newTestSource(t, service), target, headers[0])
+
+	relayAll(t, r)
+	number, hash, _ := target.CurrentNumberAndHash(context.Background(), testChain)
+	assert.Equal(t, uint64(28), number, "the last confirmations are not relayed")
+	assert.Equal(t, rlpHash(headers[28]), hash)
+	assert.Greater(t, len(target.submissions), 1, "headers are split to fit the gas budget")
+
+	// a restarted relayer catches up with atlas from the trusted header
+	service.mu.Lock()
+	service.headers = headers
+	service.mu.Unlock()
+	submitted := len(target.submissions)
+	r = newTestRelayer(t, newTestSource(t, service), target, headers[0])
+	relayAll(t, r)
+	number, _, _ = target.CurrentNumberAndHash(context.Background(), testChain)
+	assert.Equal(t, uint64(38), number)
+	sum := 0
+	for _, n := range target.submissions[submitted:] {
+		sum += n
+	}
+	assert.Equal(t, 10, sum, "headers already on atlas are not submitted again")
+}
+
+func TestRelayer_Restart(t *testing.T) {
+	headers := makeChain("a", 40)
+	service := &testService{headers: headers[:31]}
+	target := newTestTarget(headers[0])
+	db := rawdb.NewMemoryDatabase()
+	relayAll(t, newTestRelayerWithDB(t, newTestSource(t, service), target, headers[0], db))
+
+	// a relayer restarted on the database resumes from its committed header store
+	service.mu.Lock()
+	service.headers, service.fetched = headers, nil
+	service.mu.Unlock()
+	r := newTestRelayerWithDB(t, newTestSource(t, service), target, headers[0], db)
+	number, hash, _ := new(testStore).GetCurrentNumberAndHash(r.db)
+	assert.Equal(t, uint64(28), number)
+	assert.Equal(t, rlpHash(headers[28]), hash)
+
+	relayAll(t, r)
+	number, _, _ = target.CurrentNumberAndHash(context.Background(), testChain)
+	assert.Equal(t, uint64(38), number)
+	for _, n := range service.fetched {
+		assert.Greater(t, n, uint64(28), "the headers of the committed store are not fetched again")
+	}
+
+	// a store started from another trusted input is reset
+	other := makeChain("b", 1)
+	r = newTestRelayerWithDB(t, newTestSource(t, service), newTestTarget(other[0]), other[0], db)
+	number, hash, _ = new(testStore).GetCurrentNumberAndHash(r.db)
+	assert.Equal(t, uint64(0), number)
+	assert.Equal(t, rlpHash(other[0]), hash)
+}
+
+func TestRelayer_InvalidHeader(t *testing.T) {
+	headers := makeChain("a", 10)
+	headers[3].Extra = []byte("invalid")
+	target := newTestTarget(headers[0])
+	r := newTestRelayer(t, newTestSource(t, &testService{headers: headers}), target, headers[0])
+
+	_, err := r.Step(context.Background())
+	assert.True(t, errors.Is(err, errTestParent))
+	assert.Contains(t, err.Error(), "invalid header 3")
+	assert.Empty(t, target.submissions)
+}
+
+func TestRelayer_Diverged(t *testing.T) {
+	headers := makeChain("a", 10)
+	fork := *headers[4]
+	fork.Root = common.Hash{1}
+
+	// atlas follows a header 4 the source chain does not have
+	target := newTestTarget(headers[0])
+	_, err := new(testStore).InsertHeaders(target.db, encodeHeaders(append(headers[1:4:4], &fork)))
+	require.NoError(t, err)
+
+	r := newTestRelayer(t, newTestSource(t, &testService{headers: headers}), target, headers[0])
+	_, err = r.Step(context.Background())
+	assert.Equal(t, errDiverged, err)
+	assert.Equal(t, errDiverged, r.Run(context.Background()))
+}
+
+func TestRelayer_BudgetTooLow(t *testing.T) {
+	headers := makeChain("a", 10)
+	target := newTestTarget(headers[0])
+	r := newTestRelayer(t, newTestSource(t, &testService{headers: headers}), target, headers[0])
+	r.config.GasBudget = 30000
+
+	_, err := r.Step(context.Background())
+	assert.Equal(t, errBudgetTooLow, err)
+}
+
+func encodeHeaders(headers []*Header) []byte {
+	data, _ := rlp.EncodeToBytes(headers)
+	return data
+}
@@ -0,0 +1,142 @@
+package relayer
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// Source is the chain headers are relayed from.
+type Source interface {
+	// HeadNumber returns the number of the latest header.
+	HeadNumber(ctx context.Context) (uint64, error)
+	// Header returns the header with the number, rlp encoded in the form the light
+	// client of the chain decodes.
+	Header(ctx context.Context, number uint64) (rlp.RawValue, error)
+}
+
+// rpcHeader is an EVM header as returned by eth_getBlockByNumber.
+type rpcHeader struct {
+	Hash             common.Hash      `json:"hash"`
+	ParentHash       common.Hash      `json:"parentHash"`
+	UncleHash        common.Hash      `json:"sha3Uncles"`
+	Coinbase         common.Address   `json:"miner"`
+	Root             common.Hash      `json:"stateRoot"`
+	TxHash           common.Hash      `json:"transactionsRoot"`
+	ReceiptHash      common.Hash      `json:"receiptsRoot"`
+	Bloom            types.Bloom      `json:"logsBloom"`
+	Difficulty       *hexutil.Big     `json:"difficulty"`
+	Number           *hexutil.Big     `json:"number"`
+	GasLimit         hexutil.Uint64   `json:"gasLimit"`
+	GasUsed          hexutil.Uint64   `json:"gasUsed"`
+	Time             hexutil.Uint64   `json:"timestamp"`
+	Extra            hexutil.Bytes    `json:"extraData"`
+	MixDigest        common.Hash      `json:"mixHash"`
+	Nonce            types.BlockNonce `json:"nonce"`
+	BaseFee          *hexutil.Big     `json:"baseFeePerGas"`
+	WithdrawalsHash  *common.Hash     `json:"withdrawalsRoot"`
+	BlobGasUsed      *hexutil.Uint64  `json:"blobGasUsed"`
+	ExcessBlobGas    *hexutil.Uint64  `json:"excessBlobGas"`
+	ParentBeaconRoot *common.Hash     `json:"parentBeaconBlockRoot"`
+	RequestsHash     *common.Hash     `json:"requestsHash"`
+}
+
+// Header is the rlp form shared by the headers of the EVM light clients, the fields
+// a chain does not have are nil and left out of the encoding.
+type Header struct {
+	ParentHash       common.Hash
+	UncleHash        common.Hash
+	Coinbase         common.Address
+	Root             common.Hash
+	TxHash           common.Hash
+	ReceiptHash      common.Hash
+	Bloom            types.Bloom
+	Difficulty       *big.Int
+	Number           *big.Int
+	GasLimit         uint64
+	GasUsed          uint64
+	Time             uint64
+	Extra            []byte
+	MixDigest        common.Hash
+	Nonce            types.BlockNonce
+	BaseFee          *big.Int     `rlp:"optional"`
+	WithdrawalsHash  *common.Hash `rlp:"optional"`
+	BlobGasUsed      *uint64      `rlp:"optional"`
+	ExcessBlobGas    *uint64      `rlp:"optional"`
+	ParentBeaconRoot *common.Hash `rlp:"optional"`
+	RequestsHash     *common.Hash `rlp:"optional"`
+}
+
+func (h *rpcHeader) header() *Header {
+	header := &Header{
+		ParentHash:       h.ParentHash,
+		UncleHash:        h.UncleHash,
+		Coinbase:         h.Coinbase,
+		Root:             h.Root,
+		TxHash:           h.TxHash,
+		ReceiptHash:      h.ReceiptHash,
+		Bloom:            h.Bloom,
+		Difficulty:       (*big.Int)(h.Difficulty),
+		Number:           (*big.Int)(h.Number),
+		GasLimit:         uint64(h.GasLimit),
+		GasUsed:          uint64(h.GasUsed),
+		Time:             uint64(h.Time),
+		Extra:            h.Extra,
+		MixDigest:        h.MixDigest,
+		Nonce:            h.Nonce,
+		BaseFee:          (*big.Int)(h.BaseFee),
+		WithdrawalsHash:  h.WithdrawalsHash,
+		BlobGasUsed:      (*uint64)(h.BlobGasUsed),
+		ExcessBlobGas:    (*uint64)(h.ExcessBlobGas),
+		ParentBeaconRoot: h.ParentBeaconRoot,
+		RequestsHash:     h.RequestsHash,
+	}
+	if header.Difficulty == nil {
+		header.Difficulty = new(big.Int)
+	}
+	return header
+}
+
+// EVMSource reads the headers of an EVM chain from its JSON-RPC endpoint, it serves
+// the Ethereum, BSC and Polygon light clients.
+type EVMSource struct {
+	client *rpc.Client
+}
+
+func NewEVMSource(client *rpc.Client) *EVMSource {
+	return &EVMSource{client: client}
+}
+
+func (s *EVMSource) HeadNumber(ctx context.Context) (uint64, error) {
+	var number hexutil.Uint64
+	if err := s.client.CallContext(ctx, &number, "eth_blockNumber"); err != nil {
+		return 0, err
+	}
+	return uint64(number), nil
+}
+
+// Header returns the encoded header, it checks that the encoding hashes to the hash
+// reported by the node so no field of the header was dropped.
+func (s *EVMSource) Header(ctx context.Context, number uint64) (rlp.RawValue, error) {
+	var h *rpcHeader
+	if err := s.client.CallContext(ctx, &h, "eth_getBlockByNumber", hexutil.EncodeUint64(number), false); err != nil {
+		return nil, err
+	}
+	if h == nil || h.Number == nil {
+		return nil, errHeaderNotFound
+	}
+	enc, err := rlp.EncodeToBytes(h.header())
+	if err != nil {
+		return nil, err
+	}
+	if crypto.Keccak256Hash(enc) != h.Hash {
+		return nil, errHashMismatch
+	}
+	return enc, nil
+}
@@ -0,0 +1,122 @@
+package relayer
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"math/big"
+	"strings"
+	"time"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/mapprotocol/atlas/chains"
+	"github.com/mapprotocol/atlas/params"
+)
+
+var abiHeaderStore, _ = abi.JSON(strings.NewReader(params.HeaderStoreABIJSON))
+
+// Target is the atlas chain headers are submitted to.
+type Target interface {
+	// BlockNumber returns the number of the latest atlas block.
+	BlockNumber(ctx context.Context) (uint64, error)
+	// CurrentNumberAndHash returns the head of the header store of the chain.
+	CurrentNumberAndHash(ctx context.Context, chain chains.ChainType) (uint64, common.Hash, error)
+	// Submit sends an updateBlockHeader call with the input, the method selector
+	// included, and waits until it is executed.
+	Submit(ctx context.Context, input []byte) error
+}
+
+// AtlasTarget submits headers to an atlas node from an account it holds the key of.
+type AtlasTarget struct {
+	client *ethclient.Client
+	key    *ecdsa.PrivateKey
+	from   common.Address
+
+	// PollInterval is how often the receipt of a submitted transaction is polled.
+	PollInterval time.Duration
+}
+
+func NewAtlasTarget(client *ethclient.Client, key *ecdsa.PrivateKey) *AtlasTarget {
+	return &AtlasTarget{
+		client:       client,
+		key:          key,
+		from:         crypto.PubkeyToAddress(key.PublicKey),
+		PollInterval: time.Second,
+	}
+}
+
+func (t *AtlasTarget) BlockNumber(ctx context.Context) (uint64, error) {
+	return t.client.BlockNumber(ctx)
+}
+
+func (t *AtlasTarget) CurrentNumberAndHash(ctx context.Context, chain chains.ChainType) (uint64, common.Hash, error) {
+	input, err := abiHeaderStore.Pack("currentNumberAndHash", new(big.Int).SetUint64(uint64(chain)))
+	if err != nil {
+		return 0, common.Hash{}, err
+	}
+	ret, err := t.client.CallContract(ctx, ethereum.CallMsg{From: t.from, To: &params.HeaderStoreAddress, Data: input}, nil)
+	if err != nil {
+		return 0, common.Hash{}, err
+	}
+	out, err := abiHeaderStore.Unpack("currentNumberAndHash", ret)
+	if err != nil {
+		return 0, common.Hash{}, err
+	}
+	return out[0].(*big.Int).Uint64(), common.BytesToHash(out[1].([]byte)), nil
+}
+
+// Submit estimates the gas of the call first, so a batch the header store rejects is
+// reported without spending a transaction on it.
+func (t *AtlasTarget) Submit(ctx context.Context, input []byte) error {
+	chainID, err := t.client.ChainID(ctx)
+	if err != nil {
+		return err
+	}
+	nonce, err := t.client.PendingNonceAt(ctx, t.from)
+	if err != nil {
+		return err
+	}
+	gasPrice, err := t.client.SuggestGasPrice(ctx)
+	if err != nil {
+		return err
+	}
+	msg := ethereum.CallMsg{From: t.from, To: &params.HeaderStoreAddress, GasPrice: gasPrice, Data: input}
+	gas, err := t.client.EstimateGas(ctx, msg)
+	if err != nil {
+		return err
+	}
+
+	tx := types.NewTransaction(nonce, params.HeaderStoreAddress, new(big.Int), gas, gasPrice, input)
+	signed, err := types.SignTx(tx, types.LatestSignerForChainID(chainID), t.key)
+	if err != nil {
+		return err
+	}
+	if err := t.client.SendTransaction(ctx, signed); err != nil {
+		return err
+	}
+	log.Info("submitted header batch", "tx", signed.Hash(), "nonce", nonce, "gas", gas)
+
+	for {
+		receipt, err := t.client.TransactionReceipt(ctx, signed.Hash())
+		if err == nil {
+			if receipt.Status != types.ReceiptStatusSuccessful {
+				return errTxFailed
+			}
+			return nil
+		}
+		if err != ethereum.NotFound {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(t.PollInterval):
+		}
+	}
+}
@@ -0,0 +1,221 @@
+// Copyright 2021 MAP Protocol Authors.
+// This file is part of MAP Protocol.
+
+// MAP Protocol is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// MAP Protocol is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with MAP Protocol.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rpc"
+	"gopkg.in/urfave/cli.v1"
+
+	"github.com/mapprotocol/atlas/chains"
+	"github.com/mapprotocol/atlas/cmd/relayer"
+	"github.com/mapprotocol/atlas/cmd/utils"
+	"github.com/mapprotocol/atlas/core/rawdb"
+	"github.com/mapprotocol/atlas/params"
+)
+
+var (
+	relayerSourceFlag = cli.StringFlag{
+		Name:  "source",
+		Usage: "JSON-RPC endpoint of the chain the headers are relayed from",
+	}
+	relayerAtlasFlag = cli.StringFlag{
+		Name:  "atlas",
+		Usage: "JSON-RPC endpoint of the atlas node the headers are submitted to",
+		Value: "http://127.0.0.1:7445",
+	}
+	relayerChainFlag = cli.Uint64Flag{
+		Name:  "chain",
+		Usage: "Chain id of the chain the headers are relayed from",
+	}
+	relayerTrustedFlag = cli.StringFlag{
+		Name:  "trusted",
+		Usage: "File holding the hex encoded input the header store of the chain was reset with",
+	}
+	relayerTdFlag = cli.StringFlag{
+		Name:  "td",
+		Usage: "Total difficulty the header store of the chain was reset with",
+	}
+	relayerKeyFlag = cli.StringFlag{
+		Name:  "key",
+		Usage: "Keystore file of the relayer account",
+	}
+	relayerGasBudgetFlag = cli.Uint64Flag{
+		Name:  "gasbudget",
+		Usage: "Gas limit of a header batch",
+		Value: relayer.DefaultGasBudget,
+	}
+	relayerMaxHeadersFlag = cli.Uint64Flag{
+		Name:  "maxheaders",
+		Usage: "Maximum number of headers in a batch",
+		Value: relayer.DefaultMaxHeaders,
+	}
+	relayerConfirmationsFlag = cli.Uint64Flag{
+		Name:  "confirmations",
+		Usage: "Number of headers a header must be buried under before it is relayed",
+	}
+	relayerIntervalFlag = cli.DurationFlag{
+		Name:  "interval",
+		Usage: "Wait between two polls of the source chain once the relay caught up",
+		Value: relayer.DefaultInterval,
+	}
+	relayerStoreFlag = cli.StringFlag{
+		Name:  "store",
+		Usage: "Directory the local header store is kept in across restarts (kept in memory if not set)",
+	}
+
+	relayerCommand = cli.Command{
+		Action:   utils.MigrateFlags(relayHeaders),
+		Name:     "relayer",
+		Usage:    "Relay the headers of a chain to the atlas header store",
+		Category: "BLOCKCHAIN COMMANDS",
+		Flags: []cli.Flag{
+			relayerSourceFlag,
+			relayerAtlasFlag,
+			relayerChainFlag,
+			relayerTrustedFlag,
+			relayerTdFlag,
+			relayerKeyFlag,
+			utils.PasswordFileFlag,
+			relayerGasBudgetFlag,
+			relayerMaxHeadersFlag,
+			relayerConfirmationsFlag,
+			relayerIntervalFlag,
+			relayerStoreFlag,
+		},
+		Description: `
+The relayer command reads the headers of an EVM chain (Ethereum, BSC or Polygon)
+from its JSON-RPC endpoint and submits them to the header store of atlas with
+updateBlockHeader, signed by the account of the keystore file.
+
+Headers are validated before they are submitted by the light client code atlas runs,
+on a local copy of the header store started from the input the header store was
+reset with (--trusted). Batches are sized to fit the gas budget. On start the
+relayer resumes from the head of the header store on atlas. With --store the local
+header store is kept on disk, so a restart only replays the headers relayed since it
+was last written instead of all of them from the trusted input.`,
+	}
+)
+
+// atlasConfigs are the configs of the atlas networks by chain id.
+var atlasConfigs = map[uint64]*params.ChainConfig{
+	params.MainNetChainID:   params.MainnetChainConfig,
+	params.TestNetChainID:   params.TestnetConfig,
+	params.DevNetChainID:    params.DevnetConfig,
+	params.SingleNetChainID: params.SingleNetConfig,
+}
+
+func relayHeaders(ctx *cli.Context) error {
+	for _, flag := range []string{relayerSourceFlag.Name, relayerChainFlag.Name, relayerTrustedFlag.Name, relayerKeyFlag.Name} {
+		if !ctx.IsSet(flag) {
+			utils.Fatalf("--%s is required", flag)
+		}
+	}
+
+	trusted, err := ioutil.ReadFile(ctx.String(relayerTrustedFlag.Name))
+	if err != nil {
+		utils.Fatalf("Failed to read the trusted input: %v", err)
+	}
+	trustedInput, err := hexutil.Decode(strings.TrimSpace(string(trusted)))
+	if err != nil {
+		utils.Fatalf("Invalid trusted input: %v", err)
+	}
+	td := new(big.Int)
+	if ctx.IsSet(relayerTdFlag.Name) {
+		if _, ok := td.SetString(ctx.String(relayerTdFlag.Name), 0); !ok {
+			utils.Fatalf("Invalid total difficulty %q", ctx.String(relayerTdFlag.Name))
+		}
+	}
+
+	keyJSON, err := ioutil.ReadFile(ctx.String(relayerKeyFlag.Name))
+	if err != nil {
+		utils.Fatalf("Failed to read the keystore file: %v", err)
+	}
+	password := utils.GetPassPhraseWithList("Unlocking the relayer account", false, 0, utils.MakePasswordList(ctx))
+	key, err := keystore.DecryptKey(keyJSON, password)
+	if err != nil {
+		utils.Fatalf("Failed to decrypt the keystore file: %v", err)
+	}
+
+	sourceClient, err := rpc.Dial(ctx.String(relayerSourceFlag.Name))
+	if err != nil {
+		utils.Fatalf("Failed to connect to the source chain: %v", err)
+	}
+	atlasClient, err := ethclient.Dial(ctx.String(relayerAtlasFlag.Name))
+	if err != nil {
+		utils.Fatalf("Failed to connect to atlas: %v", err)
+	}
+
+	runCtx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sigc := make(chan os.Signal, 1)
+	signal.Notify(sigc, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sigc)
+	go func() {
+		<-sigc
+		log.Info("Got interrupt, shutting down relayer")
+		cancel()
+	}()
+
+	chainID, err := atlasClient.ChainID(runCtx)
+	if err != nil {
+		utils.Fatalf("Failed to get the atlas chain id: %v", err)
+	}
+	atlasConfig, ok := atlasConfigs[chainID.Uint64()]
+	if !ok {
+		utils.Fatalf("Unknown atlas chain id %v", chainID)
+	}
+
+	var db ethdb.Database
+	if ctx.IsSet(relayerStoreFlag.Name) {
+		if db, err = rawdb.NewLevelDBDatabase(ctx.String(relayerStoreFlag.Name), 16, 16, "relayer", false); err != nil {
+			utils.Fatalf("Failed to open the local header store: %v", err)
+		}
+		defer db.Close()
+	}
+
+	config := &relayer.Config{
+		Chain:         chains.ChainType(ctx.Uint64(relayerChainFlag.Name)),
+		AtlasConfig:   atlasConfig,
+		Trusted:       trustedInput,
+		TrustedTd:     td,
+		GasBudget:     ctx.Uint64(relayerGasBudgetFlag.Name),
+		MaxHeaders:    ctx.Uint64(relayerMaxHeadersFlag.Name),
+		Confirmations: ctx.Uint64(relayerConfirmationsFlag.Name),
+		Interval:      ctx.Duration(relayerIntervalFlag.Name),
+		Database:      db,
+	}
+	r, err := relayer.New(runCtx, config, relayer.NewEVMSource(sourceClient), relayer.NewAtlasTarget(atlasClient, key.PrivateKey))
+	if err != nil {
+		return fmt.Errorf("failed to start the relayer: %v", err)
+	}
+	log.Info("Relaying headers", "chain", config.Chain, "relayer", key.Address, "atlas", chainID)
+	return r.Run(runCtx)
+}
@@ -0,0 +1,140 @@
+// Copyright 2021 MAP Protocol Authors.
+// This file is part of MAP Protocol.
+
+// MAP Protocol is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// MAP Protocol is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with MAP Protocol.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/ethereum/go-ethereum/log"
+	"gopkg.in/urfave/cli.v1"
+
+	"github.com/mapprotocol/atlas/accounts/keystore"
+	"github.com/mapprotocol/atlas/cmd/utils"
+	istanbulSigner "github.com/mapprotocol/atlas/consensus/istanbul/signer"
+	"github.com/mapprotocol/atlas/core/rawdb"
+)
+
+var (
+	signerKeyFlag = cli.StringSliceFlag{
+		Name:  "key",
+		Usage: "Keystore file of a validator account, may be repeated",
+	}
+	signerAddrFlag = cli.StringFlag{
+		Name:  "addr",
+		Usage: "Address the signer service listens on",
+		Value: "127.0.0.1:8550",
+	}
+	signerTokenFlag = cli.StringFlag{
+		Name:  "tokenfile",
+		Usage: "File holding the token the validator nodes authenticate with",
+	}
+	signerProtectionFlag = cli.StringFlag{
+		Name:  "protection",
+		Usage: "Directory the slashing protection records are kept in",
+	}
+
+	signerCommand = cli.Command{
+		Action:   utils.MigrateFlags(serveSigner),
+		Name:     "signer",
+		Usage:    "Serve the keys of validators to their nodes",
+		Category: "ACCOUNT COMMANDS",
+		Flags: []cli.Flag{
+			signerKeyFlag,
+			utils.PasswordFileFlag,
+			signerAddrFlag,
+			signerTokenFlag,
+			signerProtectionFlag,
+		},
+		Description: `
+The signer command holds the keys of the keystore files and signs with them on behalf
+of the validator nodes configured with its URL as Istanbul.RemoteSigner, so that the
+validator hosts hold no keys. Nodes authenticate with the token of --tokenfile, set as
+Istanbul.RemoteSignerTokenFile on their side.
+
+The signer only signs what validators sign: consensus and announce messages, block
+seals, the randomness seed and double signing evidence transactions. It never signs two
+conflicting consensus messages for the same view, which it records in --protection.`,
+	}
+)
+
+func serveSigner(ctx *cli.Context) error {
+	for _, flag := range []string{signerKeyFlag.Name, signerTokenFlag.Name, signerProtectionFlag.Name} {
+		if !ctx.IsSet(flag) {
+			utils.Fatalf("--%s is required", flag)
+		}
+	}
+
+	token, err := istanbulSigner.ReadToken(ctx.String(signerTokenFlag.Name))
+	if err != nil {
+		utils.Fatalf("Failed to read the token: %v", err)
+	}
+	passwords := utils.MakePasswordList(ctx)
+	var keys []*ecdsa.PrivateKey
+	for i, path := range ctx.StringSlice(signerKeyFlag.Name) {
+		keyJSON, err := ioutil.ReadFile(path)
+		if err != nil {
+			utils.Fatalf("Failed to read the keystore file: %v", err)
+		}
+		password := utils.GetPassPhraseWithList(fmt.Sprintf("Unlocking %s", path), false, i, passwords)
+		key, err := keystore.DecryptKey(keyJSON, password)
+		if err != nil {
+			utils.Fatalf("Failed to decrypt the keystore file %s: %v", path, err)
+		}
+		log.Info("Serving validator key", "address", key.Address)
+		keys = append(keys, key.PrivateKey)
+	}
+
+	db, err := rawdb.NewLevelDBDatabase(ctx.String(signerProtectionFlag.Name), 16, 16, "signer", false)
+	if err != nil {
+		utils.Fatalf("Failed to open the slashing protection records: %v", err)
+	}
+	defer db.Close()
+
+	server, err := istanbulSigner.NewServer(istanbulSigner.NewService(istanbulSigner.NewProtection(db), keys...), token)
+	if err != nil {
+		return err
+	}
+	defer server.Stop()
+	listener, err := net.Listen("tcp", ctx.String(signerAddrFlag.Name))
+	if err != nil {
+		utils.Fatalf("Failed to listen: %v", err)
+	}
+	httpServer := &http.Server{Handler: server}
+
+	sigc := make(chan os.Signal, 1)
+	signal.Notify(sigc, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sigc)
+	go func() {
+		<-sigc
+		log.Info("Got interrupt, shutting down signer")
+		httpServer.Shutdown(context.Background())
+	}()
+
+	log.Info("Signer service started", "addr", listener.Addr())
+	if err := httpServer.Serve(listener); err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
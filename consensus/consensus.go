@@ -91,8 +91,9 @@ type Engine interface {
 	// but does not assemble the block.
 	//
 	// Note: The block header and state database might be updated to reflect any
-	// consensus rules that happen at finalization (e.g. block rewards).
-	Finalize(chain ChainHeaderReader, header *types.Header, state *state.StateDB, txs []*types.Transaction)
+	// consensus rules that happen at finalization (e.g. block rewards). An error
+	// makes the block invalid.
+	Finalize(chain ChainHeaderReader, header *types.Header, state *state.StateDB, txs []*types.Transaction) error
 
 	// FinalizeAndAssemble runs any post-transaction state modifications (e.g. block
 	// rewards) and assembles the final block.
@@ -189,6 +190,9 @@ type Istanbul interface {
 	// IsLastBlockOfEpoch will check to see if the header is from the last block of an epoch
 	IsLastBlockOfEpoch(header *types.Header) bool
 
+	// VerifyEpochSnarkData checks the epoch SNARK data carried in the body of the block
+	VerifyEpochSnarkData(chain ChainHeaderReader, block *types.Block) error
+
 	// LookbackWindow returns the size of the lookback window for calculating uptime (in blocks)
 	LookbackWindow(header *types.Header, state *state.StateDB) uint64
 
@@ -197,6 +201,10 @@ type Istanbul interface {
 
 	// GenerateRandomness will generate the random beacon randomness
 	GenerateRandomness(parentHash common.Hash) (common.Hash, common.Hash, error)
+
+	// EvidenceTransactions returns the signed transactions submitting the double signing
+	// evidence known to the node that can be included in the block
+	EvidenceTransactions(header *types.Header, state *state.StateDB) types.Transactions
 }
 
 // ChainContext defines a small collection of methods needed to access the local
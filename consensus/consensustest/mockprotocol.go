@@ -191,9 +191,10 @@ func (e *MockEngine) accumulateRewards(config *params.ChainConfig, state *state.
 	state.AddBalance(header.Coinbase, reward)
 }
 
-func (e *MockEngine) Finalize(chain consensus.ChainHeaderReader, header *types.Header, statedb *state.StateDB, txs []*types.Transaction) {
+func (e *MockEngine) Finalize(chain consensus.ChainHeaderReader, header *types.Header, statedb *state.StateDB, txs []*types.Transaction) error {
 	e.accumulateRewards(chain.Config(), statedb, header)
 	header.Root = statedb.IntermediateRoot(chain.Config().IsEIP158(header.Number))
+	return nil
 }
 
 func (e *MockEngine) FinalizeAndAssemble(chain consensus.ChainHeaderReader, header *types.Header, statedb *state.StateDB, txs []*types.Transaction, receipts []*types.Receipt, randomness *types.Randomness) (*types.Block, error) {
@@ -34,4 +34,9 @@ var (
 	// ErrInvalidNumber is returned if a block's number doesn't equal its parent's
 	// plus one.
 	ErrInvalidNumber = errors.New("invalid block number")
+
+	// ErrInvalidEpochSnarkData is returned if the epoch SNARK data in a block body is
+	// missing or invalid. The data is not committed by the block hash, so it may have
+	// been stripped or altered by the peer delivering the body rather than the proposer.
+	ErrInvalidEpochSnarkData = errors.New("invalid epoch snark data")
 )
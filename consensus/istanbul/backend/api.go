@@ -24,7 +24,9 @@ import (
 	"strconv"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/mapprotocol/atlas/consensus"
 	"github.com/mapprotocol/atlas/consensus/istanbul"
@@ -32,11 +34,15 @@ import (
 	"github.com/mapprotocol/atlas/consensus/istanbul/backend/internal/replica"
 	"github.com/mapprotocol/atlas/consensus/istanbul/core"
 	"github.com/mapprotocol/atlas/consensus/istanbul/proxy"
+	"github.com/mapprotocol/atlas/consensus/istanbul/slashing"
 	"github.com/mapprotocol/atlas/consensus/istanbul/uptime"
 	"github.com/mapprotocol/atlas/consensus/istanbul/uptime/store"
 	"github.com/mapprotocol/atlas/consensus/istanbul/validator"
+	mmr "github.com/mapprotocol/atlas/core/mmr"
+	"github.com/mapprotocol/atlas/core/rawdb"
 	"github.com/mapprotocol/atlas/core/types"
 	blscrypto "github.com/mapprotocol/atlas/helper/bls"
+	"github.com/mapprotocol/atlas/params"
 )
 
 // API is a user facing RPC API to dump Istanbul state
@@ -410,3 +416,246 @@ func (api *API) GetEpochInfo(epochNumber uint64) *EpochInfo {
 	}
 	return epochInfo
 }
+
+// maxParticipationBlocks is the largest range of blocks the participation is retrieved for
+const maxParticipationBlocks = 100000
+
+// ValidatorParticipation is how a validator took part in sealing a range of blocks.
+type ValidatorParticipation struct {
+	Signed            uint64 `json:"signed"`
+	Missed            uint64 `json:"missed"`
+	Proposed          uint64 `json:"proposed"`
+	ProposedAboveZero uint64 `json:"proposedAboveRoundZero"` // proposed blocks committed in a round above zero
+	LongestMissStreak uint64 `json:"longestMissStreak"`
+
+	missStreak uint64
+}
+
+// Participation is how the validators took part in sealing a range of blocks.
+type Participation struct {
+	From            uint64                                     `json:"from"`
+	To              uint64                                     `json:"to"`
+	RoundsAboveZero uint64                                     `json:"roundsAboveZero"` // blocks committed in a round above zero
+	Validators      map[common.Address]*ValidatorParticipation `json:"validators"`
+}
+
+// GetValidatorParticipation retrieves, for each signer of the blocks of the range, the blocks
+// it signed, missed and proposed. Signatures are read from the aggregated seal of the block and
+// the parent aggregated seal of the next one.
+func (api *API) GetValidatorParticipation(fromBlock, toBlock *rpc.BlockNumber) (*Participation, error) {
+	from, err := api.getHeaderByNumber(fromBlock)
+	if err != nil {
+		return nil, err
+	}
+	to, err := api.getHeaderByNumber(toBlock)
+	if err != nil {
+		return nil, err
+	}
+	start, end := from.Number.Uint64(), to.Number.Uint64()
+	if start == 0 {
+		start = 1
+	}
+	if start > end {
+		return nil, fmt.Errorf("invalid block range %d to %d", start, end)
+	}
+	if end-start+1 > maxParticipationBlocks {
+		return nil, fmt.Errorf("block range of %d blocks exceeds the limit of %d", end-start+1, maxParticipationBlocks)
+	}
+
+	res := &Participation{From: start, To: end, Validators: make(map[common.Address]*ValidatorParticipation)}
+	get := func(addr common.Address) *ValidatorParticipation {
+		v, ok := res.Validators[addr]
+		if !ok {
+			v = new(ValidatorParticipation)
+			res.Validators[addr] = v
+		}
+		return v
+	}
+	child := api.chain.GetHeaderByNumber(end + 1)
+	headers := make([]*types.Header, end-start+1)
+	for number := end; ; number-- {
+		header := api.chain.GetHeaderByNumber(number)
+		if header == nil {
+			return nil, errUnknownBlock
+		}
+		headers[number-start] = header
+		if number == start {
+			break
+		}
+	}
+	for i, header := range headers {
+		next := child
+		if i+1 < len(headers) {
+			next = headers[i+1]
+		}
+		p, err := api.istanbul.blockParticipation(header, next)
+		if err != nil {
+			return nil, fmt.Errorf("block %v: %v", header.Number, err)
+		}
+		proposer := get(p.Proposer)
+		proposer.Proposed++
+		if p.Round > 0 {
+			proposer.ProposedAboveZero++
+			res.RoundsAboveZero++
+		}
+		for j, addr := range p.Validators {
+			v := get(addr)
+			if p.Signed.Bit(j) == 1 {
+				v.Signed++
+				v.missStreak = 0
+				continue
+			}
+			v.Missed++
+			v.missStreak++
+			if v.missStreak > v.LongestMissStreak {
+				v.LongestMissStreak = v.missStreak
+			}
+		}
+	}
+	return res, nil
+}
+
+// SlashingEvent is a validator slashed during an epoch, for double signing or downtime.
+type SlashingEvent struct {
+	Reason   string         `json:"reason"`
+	Signer   common.Address `json:"signer"`
+	Account  common.Address `json:"account"`
+	Number   hexutil.Uint64 `json:"number"`
+	Penalty  *hexutil.Big   `json:"penalty"`
+	Reward   *hexutil.Big   `json:"reward"`
+	Reporter common.Address `json:"reporter"`
+}
+
+// GetSlashingEvents retrieves the validators slashed during the epoch, so far if the epoch
+// is in progress.
+func (api *API) GetSlashingEvents(epochNumber uint64) ([]*SlashingEvent, error) {
+	current := api.chain.CurrentHeader()
+	number := istanbul.GetEpochLastBlockNumber(epochNumber, api.istanbul.config.Epoch)
+	if number > current.Number.Uint64() {
+		if istanbul.GetEpochNumber(current.Number.Uint64(), api.istanbul.config.Epoch) < epochNumber {
+			return nil, fmt.Errorf("epoch %d has not started", epochNumber)
+		}
+		number = current.Number.Uint64()
+	}
+	header := api.chain.GetHeaderByNumber(number)
+	if header == nil {
+		return nil, errUnknownBlock
+	}
+	if api.istanbul.stateAt == nil {
+		return nil, errors.New("state is not available")
+	}
+	statedb, err := api.istanbul.stateAt(header.Hash())
+	if err != nil {
+		return nil, err
+	}
+	events, err := slashing.Events(statedb, epochNumber)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]*SlashingEvent, 0, len(events))
+	for _, e := range events {
+		res = append(res, &SlashingEvent{
+			Reason:   e.Reason,
+			Signer:   e.Signer,
+			Account:  e.Account,
+			Number:   hexutil.Uint64(e.Number),
+			Penalty:  (*hexutil.Big)(e.Penalty),
+			Reward:   (*hexutil.Big)(e.Reward),
+			Reporter: e.Reporter,
+		})
+	}
+	return res, nil
+}
+
+// MmrProof is the proof of a transaction receipt against the MMR root committed by
+// block End. Proof is the rlp encoded mmr.TxProof, the account and storage proofs
+// prove the root in the state of block End.
+type MmrProof struct {
+	End          hexutil.Uint64  `json:"end"`
+	Root         common.Hash     `json:"root"`
+	StateRoot    common.Hash     `json:"stateRoot"`
+	AccountProof []hexutil.Bytes `json:"accountProof"`
+	StorageProof []hexutil.Bytes `json:"storageProof"`
+	Proof        hexutil.Bytes   `json:"proof"`
+}
+
+// GetMmrProof retrieves the proof of the receipt of a transaction against the MMR root
+// committed by the requested block or current if unspecified.
+func (api *API) GetMmrProof(txHash common.Hash, end *rpc.BlockNumber) (*MmrProof, error) {
+	endHeader, err := api.getHeaderByNumber(end)
+	if err != nil {
+		return nil, err
+	}
+	if !api.chain.Config().IsMmr(endHeader.Number) {
+		return nil, fmt.Errorf("no mmr root is committed at block %v", endHeader.Number)
+	}
+	tx, blockHash, number, index := rawdb.ReadTransaction(api.istanbul.db, txHash)
+	if tx == nil {
+		return nil, errors.New("transaction not found")
+	}
+	if number >= endHeader.Number.Uint64() {
+		return nil, fmt.Errorf("transaction block %d is not before block %v", number, endHeader.Number)
+	}
+	header := api.chain.GetHeader(blockHash, number)
+	if header == nil {
+		return nil, errUnknownBlock
+	}
+	receipts := rawdb.ReadReceipts(api.istanbul.db, blockHash, number, api.chain.Config())
+	receiptProof, err := mmr.NewReceiptProof(receipts, index)
+	if err != nil {
+		return nil, err
+	}
+	cm, err := api.istanbul.getChainMmr()
+	if err != nil {
+		return nil, err
+	}
+	info, err := cm.Proof(number, endHeader.Number.Uint64())
+	if err != nil {
+		return nil, err
+	}
+
+	if api.istanbul.stateAt == nil {
+		return nil, errors.New("state is not available")
+	}
+	statedb, err := api.istanbul.stateAt(endHeader.Hash())
+	if err != nil {
+		return nil, err
+	}
+	root := mmr.CommittedRoot(statedb, endHeader.Number)
+	if root != info.RootHash {
+		return nil, fmt.Errorf("mmr root mismatch, committed %s, local %s", root.Hex(), info.RootHash.Hex())
+	}
+	accountProof, err := statedb.GetProof(params.MmrAddress)
+	if err != nil {
+		return nil, err
+	}
+	storageProof, err := statedb.GetStorageProof(params.MmrAddress, common.BigToHash(endHeader.Number))
+	if err != nil {
+		return nil, err
+	}
+	proof, err := rlp.EncodeToBytes(&mmr.TxProof{
+		End:          endHeader.Number,
+		Proof:        info,
+		Header:       header,
+		ReceiptProof: receiptProof,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	res := &MmrProof{
+		End:          hexutil.Uint64(endHeader.Number.Uint64()),
+		Root:         root,
+		StateRoot:    endHeader.Root,
+		AccountProof: make([]hexutil.Bytes, 0, len(accountProof)),
+		StorageProof: make([]hexutil.Bytes, 0, len(storageProof)),
+		Proof:        proof,
+	}
+	for _, p := range accountProof {
+		res.AccountProof = append(res.AccountProof, p)
+	}
+	for _, p := range storageProof {
+		res.StorageProof = append(res.StorageProof, p)
+	}
+	return res, nil
+}
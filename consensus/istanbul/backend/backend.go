@@ -47,6 +47,7 @@ import (
 	"github.com/mapprotocol/atlas/contracts/random"
 	"github.com/mapprotocol/atlas/contracts/validators"
 	"github.com/mapprotocol/atlas/core"
+	mmr "github.com/mapprotocol/atlas/core/mmr"
 	"github.com/mapprotocol/atlas/core/state"
 	"github.com/mapprotocol/atlas/core/types"
 	blscrypto "github.com/mapprotocol/atlas/helper/bls"
@@ -66,6 +67,7 @@ type EcdsaInfo struct {
 	decrypt  istanbul.DecryptFn    // Decrypt function to decrypt ECIES ciphertext
 	sign     istanbul.SignerFn     // Signer function to authorize hashes with
 	signHash istanbul.HashSignerFn // Signer function to create random seed
+	signTx   istanbul.TxSignerFn   // Signer function to submit double signing evidence
 }
 
 // Sign hashes and signs the data with the ecdsa account
@@ -81,6 +83,11 @@ func (ei EcdsaInfo) SignHash(hash common.Hash) ([]byte, error) {
 	return ei.signHash(accounts.Account{Address: ei.Address}, hash.Bytes())
 }
 
+// SignTx signs the transaction for the chain with the ecdsa account
+func (ei EcdsaInfo) SignTx(tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	return ei.signTx(accounts.Account{Address: ei.Address}, tx, chainID)
+}
+
 // Decrypt is a decrypt callback function to request an ECIES ciphertext to be
 // decrypted
 func (ei EcdsaInfo) Decrypt(payload []byte) ([]byte, error) {
@@ -125,6 +132,7 @@ func New(config *istanbul.Config, db ethdb.Database) consensus.Istanbul {
 		coreStarted:                        coreStarted,
 		announceRunning:                    false,
 		gossipCache:                        NewLRUGossipCache(inmemoryPeers, inmemoryMessages),
+		evidence:                           newEvidencePool(),
 		announceThreadWg:                   new(sync.WaitGroup),
 		generateAndGossipQueryEnodeCh:      make(chan struct{}, 1),
 		updateAnnounceVersionCh:            make(chan struct{}, 1),
@@ -171,6 +179,12 @@ func New(config *istanbul.Config, db ethdb.Database) consensus.Istanbul {
 			logger.Crit("Can't open ReplicaStateDB", "err", err, "dbpath", config.ReplicaStateDBPath)
 		}
 		backend.replicaState = rs
+		if config.FailoverPeer != "" {
+			backend.failover, err = newFailover(config, rs, backend.Address, backend.Sign, backend.headNumber, backend.newFailoverChainHead)
+			if err != nil {
+				logger.Crit("Can't set up the validator failover", "err", err, "peer", config.FailoverPeer)
+			}
+		}
 	} else {
 		backend.replicaState = nil
 	}
@@ -231,6 +245,7 @@ type Backend struct {
 	hasBadBlock  func(hash common.Hash) bool
 	stateAt      func(hash common.Hash) (*state.StateDB, error)
 	replicaState replica.State
+	failover     *failover
 
 	processBlock        func(block *types.Block, statedb *state.StateDB) (types.Receipts, []*types.Log, uint64, error)
 	validateState       func(block *types.Block, statedb *state.StateDB, receipts types.Receipts, usedGas uint64) error
@@ -251,6 +266,11 @@ type Backend struct {
 	// Snapshots for recent blocks to speed up reorgs
 	recentSnapshots *lru.ARCCache
 
+	// MMR over the canonical block hashes, loaded from the database on first use
+	chainMmr     *mmr.ChainMmr
+	chainMmrErr  error
+	chainMmrOnce sync.Once
+
 	// event subscription for ChainHeadEvent event
 	broadcaster consensus.Broadcaster
 
@@ -259,6 +279,9 @@ type Backend struct {
 
 	gossipCache GossipCache
 
+	// Double signing evidence waiting to be included in a block
+	evidence *evidencePool
+
 	valEnodeTable *enodes.ValidatorEnodeDB
 
 	announceManager *AnnounceManager
@@ -425,7 +448,7 @@ func (sb *Backend) SendDelegateSignMsgToProxiedValidator(msg []byte) error {
 }
 
 // Authorize implements istanbul.Backend.Authorize
-func (sb *Backend) Authorize(ecdsaAddress, blsAddress common.Address, publicKey *ecdsa.PublicKey, decryptFn istanbul.DecryptFn, signFn istanbul.SignerFn, signBLSFn istanbul.BLSSignerFn, signHashFn istanbul.HashSignerFn) {
+func (sb *Backend) Authorize(ecdsaAddress, blsAddress common.Address, publicKey *ecdsa.PublicKey, decryptFn istanbul.DecryptFn, signFn istanbul.SignerFn, signBLSFn istanbul.BLSSignerFn, signHashFn istanbul.HashSignerFn, signTxFn istanbul.TxSignerFn) {
 	bls := BlsInfo{
 		Address: blsAddress,
 		sign:    signBLSFn,
@@ -436,6 +459,7 @@ func (sb *Backend) Authorize(ecdsaAddress, blsAddress common.Address, publicKey
 		decrypt:   decryptFn,
 		sign:      signFn,
 		signHash:  signHashFn,
+		signTx:    signTxFn,
 	}
 	w := &Wallets{
 		Ecdsa: ecdsa,
@@ -445,6 +469,17 @@ func (sb *Backend) Authorize(ecdsaAddress, blsAddress common.Address, publicKey
 	sb.core.SetAddress(ecdsaAddress)
 }
 
+// AuthorizeSigner authorizes the signer to sign with the ECDSA and BLS keys of the
+// validator, as Authorize does with signing functions.
+func (sb *Backend) AuthorizeSigner(ecdsaAddress, blsAddress common.Address, signer istanbul.Signer) error {
+	publicKey, err := signer.GetPublicKey(accounts.Account{Address: ecdsaAddress})
+	if err != nil {
+		return err
+	}
+	sb.Authorize(ecdsaAddress, blsAddress, publicKey, signer.Decrypt, signer.SignData, signer.SignBLS, signer.SignHash, signer.SignTx)
+	return nil
+}
+
 func (sb *Backend) wallets() *Wallets {
 	return sb.aWallets.Load().(*Wallets)
 }
@@ -469,6 +504,9 @@ func (sb *Backend) Close() error {
 	if err := sb.announceManager.Close(); err != nil {
 		errs = append(errs, err)
 	}
+	if sb.failover != nil {
+		sb.failover.stop()
+	}
 	if sb.replicaState != nil {
 		if err := sb.replicaState.Close(); err != nil {
 			errs = append(errs, err)
@@ -513,21 +551,26 @@ func (sb *Backend) NextBlockValidators(proposal istanbul.Proposal) (istanbul.Val
 	if err != nil {
 		return nil, err
 	}
-	snap = snap.copy()
+	return applyValidatorSetDiff(snap.ValSet, istExtra)
+}
 
+// applyValidatorSetDiff returns a copy of the validator set with the validator set diff
+// of the istanbul extra applied.
+func applyValidatorSetDiff(validators istanbul.ValidatorSet, istExtra *types.IstanbulExtra) (istanbul.ValidatorSet, error) {
+	valSet := validators.Copy()
 	addedValidators, err := istanbul.CombineIstanbulExtraToValidatorData(istExtra.AddedValidators, istExtra.AddedValidatorsPublicKeys, istExtra.AddedValidatorsG1PublicKeys)
 	if err != nil {
 		return nil, err
 	}
 
-	if !snap.ValSet.RemoveValidators(istExtra.RemovedValidators) {
+	if !valSet.RemoveValidators(istExtra.RemovedValidators) {
 		return nil, fmt.Errorf("could not obtain next block validators: failed at remove validators")
 	}
-	if !snap.ValSet.AddValidators(addedValidators) {
+	if !valSet.AddValidators(addedValidators) {
 		return nil, fmt.Errorf("could not obtain next block validators: failed at add validators")
 	}
 
-	return snap.ValSet, nil
+	return valSet, nil
 }
 
 func (sb *Backend) GetValidators(blockNumber *big.Int, headerHash common.Hash) []istanbul.Validator {
@@ -658,6 +701,10 @@ func (sb *Backend) getNewValidatorSet(header *types.Header, state *state.StateDB
 	if err != nil {
 		return nil, err
 	}
+	if sb.chain.Config().IsDowntimeSlash(header.Number) {
+		epoch := istanbul.GetEpochNumber(header.Number.Uint64(), sb.config.Epoch)
+		newValSetAddresses = sb.removeJailedValidators(vmRunner, state, newValSetAddresses, epoch+1)
+	}
 	newValSet, err := validators.GetValidatorData(vmRunner, newValSetAddresses)
 	return newValSet, err
 }
@@ -1075,6 +1122,20 @@ func (sb *Backend) IsPrimary() bool {
 	return false
 }
 
+// newFailoverChainHead updates the replica state with the seq when the failover takes over,
+// as the chain events do while the core is stopped.
+func (sb *Backend) newFailoverChainHead(seq *big.Int) {
+	sb.coreMu.RLock()
+	defer sb.coreMu.RUnlock()
+	if !sb.isCoreStarted() {
+		sb.replicaState.NewChainHead(seq)
+	}
+}
+
+func (sb *Backend) headNumber() uint64 {
+	return sb.currentBlock().NumberU64()
+}
+
 // UpdateReplicaState updates the replica state with the latest seq.
 func (sb *Backend) UpdateReplicaState(seq *big.Int) {
 	if sb.replicaState != nil {
@@ -78,6 +78,10 @@ var (
 	errInvalidAggregatedSeal = errors.New("invalid aggregated seal")
 	// errInvalidAggregatedSeal is returned if the aggregated seal is missing.
 	errEmptyAggregatedSeal = errors.New("empty aggregated seal")
+	// errEmptyEpochSnarkData is returned if the last block of an epoch carries no epoch SNARK data.
+	errEmptyEpochSnarkData = errors.New("empty epoch snark data")
+	// errUnexpectedEpochSnarkData is returned if a block within an epoch carries epoch SNARK data.
+	errUnexpectedEpochSnarkData = errors.New("unexpected epoch snark data")
 	// errMismatchTxhashes is returned if the TxHash in header is mismatch.
 	errMismatchTxhashes = errors.New("mismatch transactions hashes")
 	// errInvalidValidatorSetDiff is returned if the header contains invalid validator set diff
@@ -87,6 +91,9 @@ var (
 	errUnauthorizedAnnounceMessage = errors.New("unauthorized announce message")
 	// errNotAValidator is returned when the node is not configured as a validator
 	errNotAValidator = errors.New("Not configured as a validator")
+	// errUnknownDowntimes is returned if the downtimes of the validators of an epoch cannot be
+	// computed from its headers, the block is not finalized without them.
+	errUnknownDowntimes = errors.New("unknown validator downtimes")
 )
 
 var (
@@ -304,6 +311,7 @@ func (sb *Backend) verifyAggregatedSeals(chain consensus.ChainHeaderReader, head
 	if err != nil {
 		return err
 	}
+	sb.detectConflictingSeal(chain, header, extra.AggregatedSeal)
 
 	// The genesis block is skipped since it has no parents.
 	// The first block is also skipped, since its parent
@@ -370,6 +378,86 @@ func (sb *Backend) verifyAggregatedSeal(headerHash common.Hash, validators istan
 	return nil
 }
 
+// VerifyEpochSnarkData checks the epoch SNARK data carried in the body of the block.
+// From the snark fork on, the last block of an epoch must carry the seal of a quorum
+// of its validators over the SNARK encoding of the validators of the next epoch, so
+// ultralight clients can follow the validator set from one epoch to the next.
+func (sb *Backend) VerifyEpochSnarkData(chain consensus.ChainHeaderReader, block *types.Block) error {
+	header := block.Header()
+	number := header.Number.Uint64()
+	if number == 0 || !chain.Config().IsSnark(header.Number) {
+		return nil
+	}
+	snarkData := block.EpochSnarkData()
+	if !istanbul.IsLastBlockOfEpoch(number, sb.config.Epoch) {
+		if snarkData != nil && !snarkData.IsEmpty() {
+			return fmt.Errorf("%w: %v", consensus.ErrInvalidEpochSnarkData, errUnexpectedEpochSnarkData)
+		}
+		return nil
+	}
+	if snarkData == nil || snarkData.IsEmpty() {
+		return fmt.Errorf("%w: %v", consensus.ErrInvalidEpochSnarkData, errEmptyEpochSnarkData)
+	}
+
+	extra, err := types.ExtractIstanbulExtra(header)
+	if err != nil {
+		return err
+	}
+	// The seal is made by the validators of the block over the validators its
+	// validator set diff leads to
+	snap, err := sb.snapshot(chain, number-1, header.ParentHash, nil)
+	if err != nil {
+		return err
+	}
+	next, err := applyValidatorSetDiff(snap.ValSet, extra)
+	if err != nil {
+		return err
+	}
+	parentEpochHeader := chain.GetHeaderByNumber(number - sb.config.Epoch)
+	if parentEpochHeader == nil {
+		return errUnknownBlock
+	}
+	message, extraData, err := istanbul.EpochValidatorSetData(true, number, sb.config.Epoch,
+		uint8(extra.AggregatedSeal.Round.Uint64()), header.Hash(), parentEpochHeader.Hash(), next)
+	if err != nil {
+		return err
+	}
+	fork, cur := new(big.Int).Set(chain.Config().BN256ForkBlock), new(big.Int).Set(header.Number)
+	if err := sb.verifyEpochValidatorSetSeal(message, extraData, snap.ValSet, snarkData, fork, cur); err != nil {
+		return fmt.Errorf("%w: %v", consensus.ErrInvalidEpochSnarkData, err)
+	}
+	return nil
+}
+
+// verifyEpochValidatorSetSeal checks the aggregated signature of the epoch SNARK data
+// against the validators flagged in its bitmap.
+func (sb *Backend) verifyEpochValidatorSetSeal(message, extraData []byte, validators istanbul.ValidatorSet,
+	snarkData *types.EpochSnarkData, fork, cur *big.Int) error {
+	logger := sb.logger.New("func", "Backend.verifyEpochValidatorSetSeal()")
+	if len(snarkData.Signature) != types.IstanbulExtraBlsSignature || snarkData.Bitmap == nil ||
+		snarkData.Bitmap.BitLen() > validators.Size() {
+		return errInvalidAggregatedSeal
+	}
+
+	publicKeys := []blscrypto.SerializedPublicKey{}
+	for i := 0; i < validators.Size(); i++ {
+		if snarkData.Bitmap.Bit(i) == 1 {
+			publicKeys = append(publicKeys, validators.GetByIndex(uint64(i)).BLSPublicKey())
+		}
+	}
+	if len(publicKeys) < validators.MinQuorumSize() {
+		logger.Error("Epoch snark data does not aggregate enough seals", "numSeals", len(publicKeys), "minimum quorum size", validators.MinQuorumSize())
+		return errInsufficientSeals
+	}
+	err := blscrypto.CryptoType().VerifyAggregatedSignature(publicKeys, message, extraData, snarkData.Signature,
+		true, true, fork, cur)
+	if err != nil {
+		logger.Error("Unable to verify epoch snark data signature", "err", err)
+		return errInvalidSignature
+	}
+	return nil
+}
+
 // VerifySeal checks whether the crypto seal on a header is valid according to
 // the consensus rules of the given engine.
 func (sb *Backend) VerifySeal(header *types.Header) error {
@@ -484,8 +572,9 @@ func (sb *Backend) LookbackWindow(header *types.Header, state *state.StateDB) ui
 // but does not assemble the block.
 //
 // Note: The block header and state database might be updated to reflect any
-// consensus rules that happen at finalization (e.g. block rewards).
-func (sb *Backend) Finalize(chain consensus.ChainHeaderReader, header *types.Header, state *state.StateDB, txs []*types.Transaction) {
+// consensus rules that happen at finalization (e.g. block rewards). A block whose
+// MMR root cannot be committed is invalid, its state would miss the root.
+func (sb *Backend) Finalize(chain consensus.ChainHeaderReader, header *types.Header, state *state.StateDB, txs []*types.Transaction) error {
 	start := time.Now()
 	defer sb.finalizationTimer.UpdateSince(start)
 
@@ -509,6 +598,24 @@ func (sb *Backend) Finalize(chain consensus.ChainHeaderReader, header *types.Hea
 	// Trigger an update to the gas price minimum in the GasPriceMinimum contract based on block congestion
 	snapshot = state.Snapshot()
 
+	// Chains started before the slashing forks register the slashers at the fork blocks
+	if fork := chain.Config().DoubleSignSlashBlock; fork != nil && fork.Cmp(header.Number) == 0 {
+		if err := sb.registerSlasher(vmRunner, "DoubleSigningSlasher", params.EvidenceAddress); err != nil {
+			return fmt.Errorf("failed to register the double signing slasher: %w", err)
+		}
+	}
+	if fork := chain.Config().DowntimeSlashBlock; fork != nil && fork.Cmp(header.Number) == 0 {
+		if err := sb.registerSlasher(vmRunner, "DowntimeSlasher", params.DowntimeSlasherAddress); err != nil {
+			return fmt.Errorf("failed to register the downtime slasher: %w", err)
+		}
+	}
+
+	if chain.Config().IsDoubleSignSlash(header.Number) {
+		if err := sb.slashDoubleSigners(vmRunner, header, state); err != nil {
+			logger.Error("Failed to slash double signers", "err", err)
+		}
+	}
+
 	lastBlockOfEpoch := istanbul.IsLastBlockOfEpoch(header.Number.Uint64(), sb.config.Epoch)
 	if lastBlockOfEpoch {
 		snapshot = state.Snapshot()
@@ -517,11 +624,22 @@ func (sb *Backend) Finalize(chain consensus.ChainHeaderReader, header *types.Hea
 		if err != nil {
 			sb.logger.Error("Failed to distribute epoch rewards", "blockNumber", header.Number, "err", err)
 			state.RevertToSnapshot(snapshot)
+			if errors.Is(err, errUnknownDowntimes) {
+				return err
+			}
+		}
+	}
+
+	if chain.Config().IsMmr(header.Number) {
+		if err := sb.commitMmrRoot(chain, header, state); err != nil {
+			logger.Error("Failed to commit the mmr root", "err", err)
+			return fmt.Errorf("failed to commit the mmr root: %w", err)
 		}
 	}
 
 	header.Root = state.IntermediateRoot(chain.Config().IsEIP158(header.Number))
 	logger.Info("Finalized", "duration", now().Sub(start), "lastInEpoch", lastBlockOfEpoch)
+	return nil
 }
 
 // FinalizeAndAssemble runs any post-transaction state modifications (e.g. block
@@ -531,7 +649,9 @@ func (sb *Backend) Finalize(chain consensus.ChainHeaderReader, header *types.Hea
 // consensus rules that happen at finalization (e.g. block rewards).
 func (sb *Backend) FinalizeAndAssemble(chain consensus.ChainHeaderReader, header *types.Header, state *state.StateDB, txs []*types.Transaction, receipts []*types.Receipt, randomness *types.Randomness) (*types.Block, error) {
 
-	sb.Finalize(chain, header, state, txs)
+	if err := sb.Finalize(chain, header, state, txs); err != nil {
+		return nil, err
+	}
 
 	// Add extra receipt for Block's Internal Transaction Logs
 	receipts = ethChain.AddBlockReceipt(receipts, state, header.Hash())
@@ -607,6 +727,11 @@ func (sb *Backend) APIs(chain consensus.ChainHeaderReader) []rpc.API {
 		Version:   "1.0",
 		Service:   &API{chain: chain, istanbul: sb},
 		Public:    true,
+	}, {
+		Namespace: "lightclient",
+		Version:   "1.0",
+		Service:   &LightClientAPI{chain: chain, istanbul: sb},
+		Public:    true,
 	}}
 }
 
@@ -619,6 +744,11 @@ func (sb *Backend) SetChain(chain consensus.ChainContext, currentBlock func() *t
 		go sb.newChainHeadLoop(bc)
 		go sb.updateReplicaStateLoop(bc)
 	}
+	if sb.failover != nil {
+		if err := sb.failover.start(sb.config.FailoverListenAddr); err != nil {
+			sb.logger.Error("Failed to open the failover channel", "err", err, "addr", sb.config.FailoverListenAddr)
+		}
+	}
 
 }
 
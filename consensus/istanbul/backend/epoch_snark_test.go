@@ -0,0 +1,79 @@
+package backend
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+	. "github.com/onsi/gomega"
+
+	"github.com/mapprotocol/atlas/consensus/istanbul"
+	"github.com/mapprotocol/atlas/consensus/istanbul/validator"
+	"github.com/mapprotocol/atlas/core/types"
+	blscrypto "github.com/mapprotocol/atlas/helper/bls"
+)
+
+func TestVerifyEpochValidatorSetSeal(t *testing.T) {
+	g := NewGomegaWithT(t)
+	sb := &Backend{logger: log.New()}
+	fork, cur := big.NewInt(0), big.NewInt(100)
+
+	var (
+		validators []istanbul.ValidatorData
+		secrets    []*blscrypto.SecretKey
+	)
+	for i := 0; i < 4; i++ {
+		key, _ := crypto.GenerateKey()
+		blsPrivateKey, _ := blscrypto.CryptoType().ECDSAToBLS(key)
+		blsPublicKey, _ := blscrypto.CryptoType().PrivateToPublic(blsPrivateKey)
+		secret, err := blscrypto.DeserializePrivateKey(blsPrivateKey)
+		g.Expect(err).ToNot(HaveOccurred())
+		validators = append(validators, istanbul.ValidatorData{Address: crypto.PubkeyToAddress(key.PublicKey), BLSPublicKey: blsPublicKey})
+		secrets = append(secrets, secret)
+	}
+	valSet := validator.NewSet(validators)
+
+	// The validators of block 100 elect the first three of them for the next epoch
+	next := validator.NewSet(validators[:3])
+	message, extraData, err := istanbul.EpochValidatorSetData(true, 100, 100, 0, common.Hash{0x01}, common.Hash{0x02}, next)
+	g.Expect(err).ToNot(HaveOccurred())
+	prepared, err := blscrypto.PrepareMessage(message, extraData, true)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	sign := func(indexes ...int) *types.EpochSnarkData {
+		bitmap := new(big.Int)
+		var sigs [][]byte
+		for _, i := range indexes {
+			index, _ := valSet.GetByAddress(validators[i].Address)
+			bitmap.SetBit(bitmap, index, 1)
+			sig, err := blscrypto.UnsafeSign2(secrets[i], prepared)
+			g.Expect(err).ToNot(HaveOccurred())
+			sigs = append(sigs, sig.Marshal())
+		}
+		aggSig, err := blscrypto.CryptoType().AggregateSignatures(sigs)
+		g.Expect(err).ToNot(HaveOccurred())
+		return &types.EpochSnarkData{Bitmap: bitmap, Signature: aggSig}
+	}
+
+	g.Expect(sb.verifyEpochValidatorSetSeal(message, extraData, valSet, sign(0, 1, 2), fork, cur)).To(Succeed())
+	g.Expect(sb.verifyEpochValidatorSetSeal(message, extraData, valSet, sign(0, 1, 2, 3), fork, cur)).To(Succeed())
+	g.Expect(sb.verifyEpochValidatorSetSeal(message, extraData, valSet, sign(0, 1), fork, cur)).To(BeIdenticalTo(errInsufficientSeals))
+
+	// The seal is bound to the validators of the next epoch and to the extra data
+	other, otherExtra, err := istanbul.EpochValidatorSetData(true, 100, 100, 0, common.Hash{0x01}, common.Hash{0x02}, valSet)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(sb.verifyEpochValidatorSetSeal(other, otherExtra, valSet, sign(0, 1, 2), fork, cur)).To(BeIdenticalTo(errInvalidSignature))
+	_, otherExtra, err = istanbul.EpochValidatorSetData(true, 100, 100, 1, common.Hash{0x01}, common.Hash{0x02}, next)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(sb.verifyEpochValidatorSetSeal(message, otherExtra, valSet, sign(0, 1, 2), fork, cur)).To(BeIdenticalTo(errInvalidSignature))
+
+	// The bitmap must match the signers
+	snarkData := sign(0, 1, 2)
+	snarkData.Bitmap = big.NewInt(7)
+	snarkData.Bitmap.SetBit(snarkData.Bitmap, 3, 1)
+	g.Expect(sb.verifyEpochValidatorSetSeal(message, extraData, valSet, snarkData, fork, cur)).To(BeIdenticalTo(errInvalidSignature))
+	snarkData.Bitmap = big.NewInt(1 << 4)
+	g.Expect(sb.verifyEpochValidatorSetSeal(message, extraData, valSet, snarkData, fork, cur)).To(BeIdenticalTo(errInvalidAggregatedSeal))
+}
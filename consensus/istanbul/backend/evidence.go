@@ -0,0 +1,290 @@
+// Copyright 2021 MAP Protocol Authors.
+// This file is part of MAP Protocol.
+
+// MAP Protocol is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// MAP Protocol is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with MAP Protocol.  If not, see <http://www.gnu.org/licenses/>.
+
+package backend
+
+import (
+	"math"
+	"math/big"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rlp"
+
+	"github.com/mapprotocol/atlas/accounts/abi"
+	"github.com/mapprotocol/atlas/consensus"
+	"github.com/mapprotocol/atlas/consensus/istanbul"
+	"github.com/mapprotocol/atlas/consensus/istanbul/slashing"
+	"github.com/mapprotocol/atlas/core/state"
+	"github.com/mapprotocol/atlas/core/types"
+	"github.com/mapprotocol/atlas/core/vm"
+	"github.com/mapprotocol/atlas/params"
+)
+
+// evidenceTxGas is the gas limit of the transactions submitting evidence, it covers the
+// evidence precompile and the calldata of two headers.
+const evidenceTxGas uint64 = 1000000
+
+var abiEvidence, _ = abi.JSON(strings.NewReader(params.EvidenceABIJSON))
+
+// evidenceEntry is verified evidence and the offences it proves.
+type evidenceEntry struct {
+	evidence *istanbul.Evidence
+	offences []istanbul.Offence
+}
+
+// evidencePool keeps the double signing evidence this node detected or received, until
+// the offenders are slashed or the evidence is too old to be accepted.
+type evidencePool struct {
+	mu      sync.Mutex
+	entries map[common.Hash]*evidenceEntry
+}
+
+func newEvidencePool() *evidencePool {
+	return &evidencePool{entries: make(map[common.Hash]*evidenceEntry)}
+}
+
+// add stores the entry, it returns false if the pool already has the evidence.
+func (p *evidencePool) add(hash common.Hash, entry *evidenceEntry) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, ok := p.entries[hash]; ok {
+		return false
+	}
+	p.entries[hash] = entry
+	return true
+}
+
+func (p *evidencePool) has(hash common.Hash) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	_, ok := p.entries[hash]
+	return ok
+}
+
+// pending returns the evidence that can be included in the block at number and drops
+// the evidence that no longer can. Evidence whose offenders are all slashed is dropped too.
+func (p *evidencePool) pending(number, epochSize uint64, db types.StateDB) []*istanbul.Evidence {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var evidence []*istanbul.Evidence
+	for hash, entry := range p.entries {
+		switch istanbul.CheckEvidenceAge(entry.evidence.Number(), number, epochSize) {
+		case istanbul.ErrFutureEvidence:
+			continue
+		case istanbul.ErrStaleEvidence:
+			delete(p.entries, hash)
+			continue
+		}
+		slashed := true
+		for _, o := range entry.offences {
+			if !slashing.IsSlashed(db, o.Signer, o.Number) {
+				slashed = false
+				break
+			}
+		}
+		if slashed {
+			delete(p.entries, hash)
+			continue
+		}
+		evidence = append(evidence, entry.evidence)
+	}
+	return evidence
+}
+
+// verifyEvidence checks the evidence against the validators of the block it is about. The
+// block may be the one being agreed on, one past the head of the chain.
+func (sb *Backend) verifyEvidence(ev *istanbul.Evidence) ([]istanbul.Offence, error) {
+	number := ev.Number()
+	head := sb.currentBlock().NumberU64()
+	if err := istanbul.CheckEvidenceAge(number, head+2, sb.config.Epoch); err != nil {
+		return nil, err
+	}
+	vals := sb.GetValidators(new(big.Int).SetUint64(number-1), common.Hash{})
+	if len(vals) == 0 {
+		return nil, istanbul.ErrInvalidEvidence
+	}
+	return ev.Verify(vals, sb.ChainConfig().BN256ForkBlock)
+}
+
+// addEvidence verifies the evidence and adds it to the pool, it returns whether the
+// evidence is new.
+func (sb *Backend) addEvidence(ev *istanbul.Evidence) (bool, error) {
+	hash := ev.Hash()
+	if sb.evidence.has(hash) {
+		return false, nil
+	}
+	offences, err := sb.verifyEvidence(ev)
+	if err != nil {
+		return false, err
+	}
+	for _, o := range offences {
+		sb.logger.Warn("Found double signing evidence", "signer", o.Signer, "number", o.Number)
+	}
+	return sb.evidence.add(hash, &evidenceEntry{evidence: ev, offences: offences}), nil
+}
+
+// ReportEvidence implements istanbulCore.CoreBackend.ReportEvidence
+func (sb *Backend) ReportEvidence(ev *istanbul.Evidence) {
+	if !sb.ChainConfig().IsDoubleSignSlash(sb.currentBlock().Number()) {
+		return
+	}
+	isNew, err := sb.addEvidence(ev)
+	if err != nil {
+		sb.logger.Warn("Dropping invalid double signing evidence", "err", err)
+		return
+	}
+	if !isNew {
+		return
+	}
+	payload, err := rlp.EncodeToBytes(ev)
+	if err != nil {
+		sb.logger.Error("Failed to encode double signing evidence", "err", err)
+		return
+	}
+	if err := sb.Gossip(payload, istanbul.EvidenceMsg); err != nil {
+		sb.logger.Warn("Failed to gossip double signing evidence", "err", err)
+	}
+}
+
+// handleEvidenceMsg adds the gossiped evidence to the pool and gossips it on if it is valid.
+func (sb *Backend) handleEvidenceMsg(addr common.Address, payload []byte) error {
+	logger := sb.logger.New("func", "handleEvidenceMsg")
+
+	// Since this is a gossiped messaged, mark that the peer gossiped it (and presumably processed it) and check to see if this node already processed it
+	sb.gossipCache.MarkMessageProcessedByPeer(addr, payload)
+	if sb.gossipCache.CheckIfMessageProcessedBySelf(payload) {
+		return nil
+	}
+	defer sb.gossipCache.MarkMessageProcessedBySelf(payload)
+
+	if !sb.ChainConfig().IsDoubleSignSlash(sb.currentBlock().Number()) {
+		return nil
+	}
+	ev, err := istanbul.DecodeEvidence(payload)
+	if err != nil {
+		logger.Debug("Failed to decode double signing evidence", "err", err, "from", addr)
+		return err
+	}
+	isNew, err := sb.addEvidence(ev)
+	if err != nil {
+		logger.Debug("Received invalid double signing evidence", "err", err, "from", addr)
+		return err
+	}
+	if isNew {
+		return sb.Gossip(payload, istanbul.EvidenceMsg)
+	}
+	return nil
+}
+
+// detectConflictingSeal reports the header as double signing evidence if another header of
+// the same height and round is already in the chain. The seal of the header must have been
+// verified.
+func (sb *Backend) detectConflictingSeal(chain consensus.ChainHeaderReader, header *types.Header, seal types.IstanbulAggregatedSeal) {
+	if sb.evidence == nil || !chain.Config().IsDoubleSignSlash(header.Number) {
+		return
+	}
+	canonical := chain.GetHeaderByNumber(header.Number.Uint64())
+	if canonical == nil || canonical.Hash() == header.Hash() {
+		return
+	}
+	extra, err := types.ExtractIstanbulExtra(canonical)
+	if err != nil || extra.AggregatedSeal.Round == nil || seal.Round == nil || extra.AggregatedSeal.Round.Cmp(seal.Round) != 0 {
+		return
+	}
+	go sb.ReportEvidence(&istanbul.Evidence{ConflictingSeal: &istanbul.ConflictingSealEvidence{First: canonical, Second: header}})
+}
+
+// EvidenceTransactions returns the transactions submitting the evidence in the pool that can
+// be included in the block, signed by the validator.
+func (sb *Backend) EvidenceTransactions(header *types.Header, state *state.StateDB) types.Transactions {
+	if !sb.ChainConfig().IsDoubleSignSlash(header.Number) {
+		return nil
+	}
+	evidence := sb.evidence.pending(header.Number.Uint64(), sb.config.Epoch, state)
+	if len(evidence) == 0 {
+		return nil
+	}
+
+	ecdsa := sb.wallets().Ecdsa
+	if ecdsa.signTx == nil {
+		return nil
+	}
+	gasPrice := new(big.Int)
+	if header.BaseFee != nil {
+		gasPrice.Set(header.BaseFee)
+	}
+	nonce := state.GetNonce(ecdsa.Address)
+	var txs types.Transactions
+	for _, ev := range evidence {
+		data, err := rlp.EncodeToBytes(ev)
+		if err != nil {
+			sb.logger.Error("Failed to encode double signing evidence", "err", err)
+			continue
+		}
+		input, err := abiEvidence.Pack("submitEvidence", data)
+		if err != nil {
+			sb.logger.Error("Failed to pack double signing evidence", "err", err)
+			continue
+		}
+		to := params.EvidenceAddress
+		tx := types.NewTx(&types.LegacyTx{
+			Nonce:    nonce,
+			GasPrice: gasPrice,
+			Gas:      evidenceTxGas,
+			To:       &to,
+			Value:    new(big.Int),
+			Data:     input,
+		})
+		if tx, err = ecdsa.SignTx(tx, sb.ChainConfig().ChainID); err != nil {
+			sb.logger.Error("Failed to sign double signing evidence transaction", "err", err)
+			return txs
+		}
+		txs = append(txs, tx)
+		nonce++
+	}
+	return txs
+}
+
+// slashDoubleSigners slashes the validators reported for double signing in the block. Each
+// offender loses up to the penalty from its locked MAP, of which the reporter gets the reward,
+// has its slashing multiplier halved and is jailed for good, it is never elected again.
+func (sb *Backend) slashDoubleSigners(vmRunner vm.EVMRunner, header *types.Header, state *state.StateDB) error {
+	reports, err := slashing.TakePending(state)
+	if err != nil || len(reports) == 0 {
+		return err
+	}
+	epoch := istanbul.GetEpochNumber(header.Number.Uint64(), sb.EpochSize())
+	penalty, reward := slashing.Parameters(state)
+	for _, r := range reports {
+		logger := sb.logger.New("func", "slashDoubleSigners", "signer", r.Signer, "number", r.Number, "reporter", r.Reporter)
+		snapshot := state.Snapshot()
+		event, err := sb.slashValidator(vmRunner, params.EvidenceAddress, r.Signer, penalty, r.Reporter, reward)
+		if err != nil {
+			logger.Error("Failed to slash double signer", "err", err)
+			state.RevertToSnapshot(snapshot)
+			continue
+		}
+		event.Reason, event.Number = slashing.ReasonDoubleSigning, r.Number
+		if err := slashing.AddEvent(state, epoch, event); err != nil {
+			logger.Error("Failed to record slashing event", "err", err)
+		}
+		slashing.Jail(state, event.Account, math.MaxUint64)
+		logger.Info("Slashed double signer", "account", event.Account, "penalty", event.Penalty, "block", header.Number)
+	}
+	return nil
+}
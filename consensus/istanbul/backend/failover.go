@@ -0,0 +1,341 @@
+// Copyright 2021 MAP Protocol Authors.
+// This file is part of MAP Protocol.
+
+// MAP Protocol is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// MAP Protocol is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with MAP Protocol.  If not, see <http://www.gnu.org/licenses/>.
+
+package backend
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/mapprotocol/atlas/consensus/istanbul"
+	"github.com/mapprotocol/atlas/consensus/istanbul/backend/internal/replica"
+)
+
+var (
+	errHeartbeatSigner  = errors.New("heartbeat not signed by the validator")
+	errHeartbeatRole    = errors.New("heartbeat of the wrong role")
+	errHeartbeatStale   = errors.New("stale heartbeat")
+	errFailoverPrimary  = errors.New("the node is a primary")
+	errFailoverTakeover = errors.New("the replica is taking over")
+)
+
+// Used as a salt when signing heartbeats, so that they are never valid as another message.
+var heartbeatSalt = []byte("failoverHeartbeat")
+
+// Heartbeat is sent by the primary to its replica on the failover channel and answered
+// with the heartbeat of the replica, both signed by the validator. The primary proposes to
+// stop validating at StopBlock unless it hears from its replica again, the replica
+// acknowledges it by returning the same StopBlock and Timestamp.
+type Heartbeat struct {
+	Primary   bool           `json:"primary"`
+	Number    hexutil.Uint64 `json:"number"`    // head of the sender
+	StopBlock hexutil.Uint64 `json:"stopBlock"` // first sequence the primary does not validate
+	Timestamp hexutil.Uint64 `json:"timestamp"` // unix time of the primary heartbeat in nanoseconds
+	Signature hexutil.Bytes  `json:"signature"`
+}
+
+func (hb *Heartbeat) payload() ([]byte, error) {
+	return rlp.EncodeToBytes([]interface{}{heartbeatSalt, hb.Primary, uint64(hb.Number), uint64(hb.StopBlock), uint64(hb.Timestamp)})
+}
+
+// failover switches validating between the primary and the replica node of a validator
+// without an operator. Every heartbeat of the primary proposes a lease, the block it stops
+// validating at unless renewed, which it only moves its stop block to once the replica
+// acknowledges it. A replica missing enough heartbeats in a row takes over from the highest
+// lease it acknowledged: the primary has committed to step down by then, so that the two
+// never validate the same sequence. A primary losing its replica stops at the end of its
+// lease. A replica which did not acknowledge a lease since it became one never takes over.
+//
+// An operator who knows the replica is down keeps the validator online by starting the
+// primary again with istanbul.startValidating, which clears its stop block: it then validates
+// without a lease until the replica acknowledges one again.
+type failover struct {
+	state        replica.State
+	address      func() common.Address
+	sign         func([]byte) ([]byte, error)
+	head         func() uint64
+	newChainHead func(*big.Int)
+	now          func() time.Time
+
+	interval    time.Duration
+	missed      uint64
+	leaseBlocks uint64
+
+	client *rpc.Client
+	server *http.Server
+	quit   chan struct{}
+	wg     sync.WaitGroup
+
+	mu            sync.Mutex
+	renewed       uint64    // primary: stop block last renewed, zero if the lease is not running
+	acknowledged  uint64    // replica: highest lease acknowledged to the primary
+	lastHeartbeat time.Time // replica: when the last heartbeat of the primary was accepted
+	lastTimestamp uint64    // replica: timestamp of the last heartbeat of the primary
+
+	logger log.Logger
+}
+
+func newFailover(config *istanbul.Config, state replica.State, address func() common.Address, sign func([]byte) ([]byte, error), head func() uint64, newChainHead func(*big.Int)) (*failover, error) {
+	if config.FailoverHeartbeatInterval == 0 || config.FailoverMissedHeartbeats == 0 || config.FailoverLeaseBlocks == 0 {
+		return nil, errors.New("failover heartbeat interval, missed heartbeats and lease blocks must be positive")
+	}
+	client, err := rpc.DialHTTP(config.FailoverPeer)
+	if err != nil {
+		return nil, err
+	}
+	return &failover{
+		state:        state,
+		address:      address,
+		sign:         sign,
+		head:         head,
+		newChainHead: newChainHead,
+		now:          time.Now,
+		interval:     time.Duration(config.FailoverHeartbeatInterval) * time.Millisecond,
+		missed:       config.FailoverMissedHeartbeats,
+		leaseBlocks:  config.FailoverLeaseBlocks,
+		client:       client,
+		logger:       log.New("module", "failover"),
+	}, nil
+}
+
+// start serves the failover channel on the address and starts heartbeating.
+func (f *failover) start(listenAddr string) error {
+	server := rpc.NewServer()
+	if err := server.RegisterName("failover", &failoverAPI{f}); err != nil {
+		return err
+	}
+	listener, err := net.Listen("tcp", listenAddr)
+	if err != nil {
+		return err
+	}
+	f.server = &http.Server{Handler: server}
+	f.quit = make(chan struct{})
+	f.wg.Add(2)
+	go func() {
+		defer f.wg.Done()
+		if err := f.server.Serve(listener); err != http.ErrServerClosed {
+			f.logger.Error("Failover channel closed", "err", err)
+		}
+	}()
+	go f.loop()
+	f.logger.Info("Failover channel opened", "addr", listener.Addr())
+	return nil
+}
+
+// stop closes the failover channel.
+func (f *failover) stop() {
+	if f.quit == nil {
+		return
+	}
+	close(f.quit)
+	f.server.Close()
+	f.wg.Wait()
+	f.client.Close()
+}
+
+func (f *failover) loop() {
+	defer f.wg.Done()
+	ticker := time.NewTicker(f.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			f.tick()
+		case <-f.quit:
+			return
+		}
+	}
+}
+
+func (f *failover) tick() {
+	if f.state.IsPrimary() {
+		f.mu.Lock()
+		f.acknowledged = 0
+		f.mu.Unlock()
+		f.heartbeat()
+	} else {
+		f.mu.Lock()
+		f.renewed = 0
+		f.mu.Unlock()
+		f.checkPrimary()
+	}
+}
+
+func (f *failover) signHeartbeat(hb *Heartbeat) error {
+	payload, err := hb.payload()
+	if err != nil {
+		return err
+	}
+	hb.Signature, err = f.sign(payload)
+	return err
+}
+
+func (f *failover) verifyHeartbeat(hb *Heartbeat) error {
+	payload, err := hb.payload()
+	if err != nil {
+		return err
+	}
+	signer, err := istanbul.GetSignatureAddress(payload, hb.Signature)
+	if err != nil {
+		return err
+	}
+	if signer != f.address() {
+		return errHeartbeatSigner
+	}
+	return nil
+}
+
+// heartbeat sends the heartbeat of the primary, and renews its lease once acknowledged.
+// A stop block set by the operator, or left by an earlier run of the node, is reported to
+// the replica but not renewed.
+func (f *failover) heartbeat() {
+	f.mu.Lock()
+	renewed := f.renewed
+	f.mu.Unlock()
+
+	head := f.head()
+	stop := head + f.leaseBlocks
+	summary := f.state.Summary()
+	owned := summary.StopValidatingBlock == nil || summary.StopValidatingBlock.Uint64() == renewed
+	if !owned {
+		stop = summary.StopValidatingBlock.Uint64()
+	}
+
+	hb := &Heartbeat{
+		Primary:   true,
+		Number:    hexutil.Uint64(head),
+		StopBlock: hexutil.Uint64(stop),
+		Timestamp: hexutil.Uint64(f.now().UnixNano()),
+	}
+	if err := f.signHeartbeat(hb); err != nil {
+		f.logger.Debug("Failed to sign heartbeat", "err", err)
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), f.interval)
+	defer cancel()
+	var ack Heartbeat
+	if err := f.client.CallContext(ctx, &ack, "failover_heartbeat", hb); err != nil {
+		f.logger.Debug("Heartbeat not acknowledged", "stopBlock", stop, "err", err)
+		return
+	}
+	if err := f.verifyHeartbeat(&ack); err != nil {
+		f.logger.Warn("Invalid heartbeat acknowledgement", "err", err)
+		return
+	}
+	if ack.Primary || ack.StopBlock != hb.StopBlock || ack.Timestamp != hb.Timestamp {
+		f.logger.Warn("Heartbeat acknowledgement does not match", "stopBlock", stop, "ackStopBlock", ack.StopBlock)
+		return
+	}
+	if !owned {
+		return
+	}
+	if err := f.state.SetStopValidatingBlock(new(big.Int).SetUint64(stop)); err != nil {
+		f.logger.Warn("Failed to renew the primary lease", "stopBlock", stop, "err", err)
+		return
+	}
+	f.mu.Lock()
+	f.renewed = stop
+	f.mu.Unlock()
+}
+
+// handleHeartbeat records the lease of the primary and acknowledges it.
+func (f *failover) handleHeartbeat(hb *Heartbeat) (*Heartbeat, error) {
+	if err := f.verifyHeartbeat(hb); err != nil {
+		return nil, err
+	}
+	if !hb.Primary {
+		return nil, errHeartbeatRole
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	// Both think they are the primary, the other must not renew its lease
+	if f.state.IsPrimary() {
+		return nil, errFailoverPrimary
+	}
+	if f.state.Summary().StartValidatingBlock != nil {
+		return nil, errFailoverTakeover
+	}
+	if uint64(hb.Timestamp) <= f.lastTimestamp {
+		return nil, errHeartbeatStale
+	}
+	f.lastTimestamp = uint64(hb.Timestamp)
+	f.lastHeartbeat = f.now()
+	if uint64(hb.StopBlock) > f.acknowledged {
+		f.acknowledged = uint64(hb.StopBlock)
+	}
+
+	ack := &Heartbeat{
+		Primary:   false,
+		Number:    hexutil.Uint64(f.head()),
+		StopBlock: hb.StopBlock,
+		Timestamp: hb.Timestamp,
+	}
+	if err := f.signHeartbeat(ack); err != nil {
+		return nil, err
+	}
+	return ack, nil
+}
+
+// checkPrimary makes the replica take over once the primary missed enough heartbeats, from
+// the highest lease it acknowledged or the next sequence if the lease is over.
+func (f *failover) checkPrimary() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.acknowledged == 0 {
+		return
+	}
+	missed := uint64(f.now().Sub(f.lastHeartbeat) / f.interval)
+	if missed < f.missed {
+		return
+	}
+
+	seq := f.head() + 1
+	start := f.acknowledged
+	if start < seq {
+		start = seq
+	}
+	if err := f.state.SetStartValidatingBlock(new(big.Int).SetUint64(start)); err != nil {
+		f.logger.Error("Failed to take over from the primary", "startBlock", start, "err", err)
+		return
+	}
+	f.logger.Warn("Primary missed heartbeats, taking over", "missed", missed, "startBlock", start)
+	f.acknowledged = 0
+	if start == seq {
+		f.newChainHead(new(big.Int).SetUint64(seq))
+	}
+}
+
+// failoverAPI is the failover channel of a validator node.
+type failoverAPI struct {
+	f *failover
+}
+
+// Heartbeat acknowledges the heartbeat of the primary with the one of the replica.
+func (api *failoverAPI) Heartbeat(hb Heartbeat) (*Heartbeat, error) {
+	return api.f.handleHeartbeat(&hb)
+}
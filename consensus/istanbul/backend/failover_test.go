@@ -0,0 +1,255 @@
+package backend
+
+import (
+	"math/big"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/mapprotocol/atlas/consensus/istanbul"
+	"github.com/mapprotocol/atlas/consensus/istanbul/backend/internal/replica"
+)
+
+type failoverNode struct {
+	f       *failover
+	state   replica.State
+	started int
+	stopped int
+	heads   []uint64 // sequences the failover updated the replica state with
+}
+
+func newFailoverNode(t *testing.T, isReplica bool, config *istanbul.Config, address common.Address, sign func([]byte) ([]byte, error), head *uint64, now *time.Time) *failoverNode {
+	n := new(failoverNode)
+	state, err := replica.NewState(isReplica, "", func() error { n.started++; return nil }, func() error { n.stopped++; return nil })
+	if err != nil {
+		t.Fatalf("failed to create replica state: %v", err)
+	}
+	n.state = state
+	n.f, err = newFailover(config, state, func() common.Address { return address }, sign, func() uint64 { return *head }, func(seq *big.Int) {
+		n.heads = append(n.heads, seq.Uint64())
+		state.NewChainHead(seq)
+	})
+	if err != nil {
+		t.Fatalf("failed to create failover: %v", err)
+	}
+	n.f.now = func() time.Time { return *now }
+	return n
+}
+
+func TestFailover(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	address := crypto.PubkeyToAddress(key.PublicKey)
+	sign := func(data []byte) ([]byte, error) { return crypto.Sign(crypto.Keccak256(data), key) }
+	head, now := uint64(100), time.Unix(1000, 0)
+	config := &istanbul.Config{FailoverHeartbeatInterval: 1000, FailoverMissedHeartbeats: 3, FailoverLeaseBlocks: 10}
+
+	// The replica serves its failover channel, the primary heartbeats to it
+	config.FailoverPeer = "http://localhost"
+	rep := newFailoverNode(t, true, config, address, sign, &head, &now)
+	server := rpc.NewServer()
+	if err := server.RegisterName("failover", &failoverAPI{rep.f}); err != nil {
+		t.Fatal(err)
+	}
+	httpServer := httptest.NewServer(server)
+	defer httpServer.Close()
+	config.FailoverPeer = httpServer.URL
+	primary := newFailoverNode(t, false, config, address, sign, &head, &now)
+	defer primary.f.client.Close()
+
+	stopBlock := func(n *failoverNode) uint64 {
+		if stop := n.state.Summary().StopValidatingBlock; stop != nil {
+			return stop.Uint64()
+		}
+		return 0
+	}
+	startBlock := func(n *failoverNode) uint64 {
+		if start := n.state.Summary().StartValidatingBlock; start != nil {
+			return start.Uint64()
+		}
+		return 0
+	}
+
+	// The lease of the primary is renewed as it is acknowledged
+	primary.f.tick()
+	if stop := stopBlock(primary); stop != 110 {
+		t.Fatalf("primary stop block mismatch: have %d, want %d", stop, 110)
+	}
+	head, now = 105, now.Add(time.Second)
+	primary.f.tick()
+	if stop := stopBlock(primary); stop != 115 {
+		t.Fatalf("renewed stop block mismatch: have %d, want %d", stop, 115)
+	}
+	if rep.f.acknowledged != 115 {
+		t.Fatalf("acknowledged lease mismatch: have %d, want %d", rep.f.acknowledged, 115)
+	}
+
+	// Replayed and forged heartbeats are refused
+	hb := &Heartbeat{Primary: true, Number: 105, StopBlock: 200, Timestamp: hexutil.Uint64(now.UnixNano())}
+	if err := primary.f.signHeartbeat(hb); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := rep.f.handleHeartbeat(hb); err != errHeartbeatStale {
+		t.Errorf("replayed heartbeat error mismatch: have %v, want %v", err, errHeartbeatStale)
+	}
+	other, _ := crypto.GenerateKey()
+	hb.Timestamp++
+	hb.Signature, _ = crypto.Sign(crypto.Keccak256(mustPayload(t, hb)), other)
+	if _, err := rep.f.handleHeartbeat(hb); err != errHeartbeatSigner {
+		t.Errorf("forged heartbeat error mismatch: have %v, want %v", err, errHeartbeatSigner)
+	}
+	if _, err := primary.f.handleHeartbeat(hb); err == nil {
+		t.Errorf("primary acknowledged a heartbeat")
+	}
+
+	// The replica waits for the configured number of missed heartbeats
+	now = now.Add(2 * time.Second)
+	rep.f.tick()
+	if start := startBlock(rep); start != 0 {
+		t.Fatalf("replica took over after two missed heartbeats at %d", start)
+	}
+	now = now.Add(time.Second)
+	rep.f.tick()
+	if start := startBlock(rep); start != 115 || len(rep.heads) != 0 {
+		t.Fatalf("replica start block mismatch: have %d, want %d", start, 115)
+	}
+
+	// The primary can not renew its lease anymore, the two never validate the same sequence
+	primary.f.tick()
+	if stop := stopBlock(primary); stop != 115 {
+		t.Fatalf("primary renewed its lease with a replica taking over: %d", stop)
+	}
+	for seq := int64(105); seq < 125; seq++ {
+		if primary.state.IsPrimaryForSeq(big.NewInt(seq)) == rep.state.IsPrimaryForSeq(big.NewInt(seq)) {
+			t.Errorf("sequence %d validated by both or none", seq)
+		}
+	}
+	primary.state.NewChainHead(big.NewInt(115))
+	rep.state.NewChainHead(big.NewInt(115))
+	if primary.stopped != 1 || rep.started != 1 {
+		t.Errorf("failover mismatch: primary stopped %d times, replica started %d times", primary.stopped, rep.started)
+	}
+	if !rep.state.IsPrimary() || primary.state.IsPrimary() {
+		t.Errorf("roles not switched")
+	}
+}
+
+func TestFailoverLeaseOver(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	address := crypto.PubkeyToAddress(key.PublicKey)
+	sign := func(data []byte) ([]byte, error) { return crypto.Sign(crypto.Keccak256(data), key) }
+	head, now := uint64(100), time.Unix(1000, 0)
+	config := &istanbul.Config{FailoverPeer: "http://localhost", FailoverHeartbeatInterval: 1000, FailoverMissedHeartbeats: 3, FailoverLeaseBlocks: 10}
+	rep := newFailoverNode(t, true, config, address, sign, &head, &now)
+	defer rep.f.client.Close()
+
+	// A replica which never acknowledged a lease does not take over
+	now = now.Add(time.Minute)
+	rep.f.tick()
+	if rep.state.Summary().StartValidatingBlock != nil {
+		t.Fatalf("replica took over without a lease")
+	}
+
+	hb := &Heartbeat{Primary: true, Number: 100, StopBlock: 110, Timestamp: hexutil.Uint64(now.UnixNano())}
+	if err := rep.f.signHeartbeat(hb); err != nil {
+		t.Fatal(err)
+	}
+	ack, err := rep.f.handleHeartbeat(hb)
+	if err != nil {
+		t.Fatalf("failed to acknowledge heartbeat: %v", err)
+	}
+	if ack.Primary || ack.StopBlock != hb.StopBlock || ack.Timestamp != hb.Timestamp || rep.f.verifyHeartbeat(ack) != nil {
+		t.Fatalf("invalid acknowledgement %v", ack)
+	}
+
+	// Once the lease is over, the replica takes over from the next sequence
+	head, now = 120, now.Add(3*time.Second)
+	rep.f.tick()
+	if len(rep.heads) != 1 || rep.heads[0] != 121 {
+		t.Fatalf("replica take over mismatch: have %v, want [121]", rep.heads)
+	}
+	if rep.started != 1 || !rep.state.IsPrimary() {
+		t.Errorf("replica did not start validating")
+	}
+}
+
+func TestFailoverReplicaDown(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	address := crypto.PubkeyToAddress(key.PublicKey)
+	sign := func(data []byte) ([]byte, error) { return crypto.Sign(crypto.Keccak256(data), key) }
+	head, now := uint64(100), time.Unix(1000, 0)
+	config := &istanbul.Config{FailoverHeartbeatInterval: 1000, FailoverMissedHeartbeats: 3, FailoverLeaseBlocks: 10}
+
+	config.FailoverPeer = "http://localhost"
+	rep := newFailoverNode(t, true, config, address, sign, &head, &now)
+	server := rpc.NewServer()
+	if err := server.RegisterName("failover", &failoverAPI{rep.f}); err != nil {
+		t.Fatal(err)
+	}
+	httpServer := httptest.NewServer(server)
+	config.FailoverPeer = httpServer.URL
+	primary := newFailoverNode(t, false, config, address, sign, &head, &now)
+	defer primary.f.client.Close()
+
+	stopBlock := func() uint64 {
+		if stop := primary.state.Summary().StopValidatingBlock; stop != nil {
+			return stop.Uint64()
+		}
+		return 0
+	}
+	primary.f.tick()
+	if stop := stopBlock(); stop != 110 {
+		t.Fatalf("primary stop block mismatch: have %d, want %d", stop, 110)
+	}
+
+	// The replica goes down, the primary stops at its last acknowledged lease
+	httpServer.Close()
+	for _, h := range []uint64{105, 108, 109} {
+		head, now = h, now.Add(time.Second)
+		primary.f.tick()
+		if stop := stopBlock(); stop != 110 {
+			t.Fatalf("primary renewed its lease without its replica: have %d, want %d", stop, 110)
+		}
+	}
+	primary.state.NewChainHead(big.NewInt(110))
+	if primary.stopped != 1 || primary.state.IsPrimary() {
+		t.Fatalf("primary kept validating past its lease")
+	}
+
+	// The operator starts it again, it validates without a lease while the replica is down
+	if err := primary.state.MakePrimary(); err != nil {
+		t.Fatal(err)
+	}
+	head, now = 120, now.Add(time.Second)
+	primary.f.tick()
+	if stop := stopBlock(); stop != 0 || !primary.state.IsPrimaryForSeq(big.NewInt(200)) {
+		t.Fatalf("primary stop block mismatch: have %d, want none", stop)
+	}
+
+	// Once the replica is back, it acknowledges leases again
+	httpServer = httptest.NewServer(server)
+	defer httpServer.Close()
+	primary.f.client.Close()
+	client, err := rpc.DialHTTP(httpServer.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	primary.f.client = client
+	head, now = 125, now.Add(time.Second)
+	primary.f.tick()
+	if stop := stopBlock(); stop != 135 || rep.f.acknowledged != 135 {
+		t.Fatalf("renewed stop block mismatch: have %d, want %d", stop, 135)
+	}
+}
+
+func mustPayload(t *testing.T, hb *Heartbeat) []byte {
+	payload, err := hb.payload()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return payload
+}
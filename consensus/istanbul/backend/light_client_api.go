@@ -0,0 +1,295 @@
+// Copyright 2021 MAP Protocol Authors.
+// This file is part of MAP Protocol.
+
+// MAP Protocol is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// MAP Protocol is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with MAP Protocol.  If not, see <http://www.gnu.org/licenses/>.
+
+package backend
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	"github.com/mapprotocol/atlas/accounts/abi"
+	"github.com/mapprotocol/atlas/consensus"
+	"github.com/mapprotocol/atlas/consensus/istanbul"
+	"github.com/mapprotocol/atlas/core/types"
+	blscrypto "github.com/mapprotocol/atlas/helper/bls"
+	"github.com/mapprotocol/atlas/params"
+)
+
+// MaxLightClientUpdates is the largest number of epochs returned by one request.
+const MaxLightClientUpdates = 32
+
+var (
+	errNoEpochUpdate = errors.New("the trusted epoch is not finished yet")
+
+	lightClientABI, _ = abi.JSON(strings.NewReader(params.LightClientUpdateABIJSON))
+)
+
+// LightClientAPI produces the updates a light client of Atlas running on another
+// chain needs to follow the validator set changes.
+type LightClientAPI struct {
+	chain    consensus.ChainHeaderReader
+	istanbul *Backend
+}
+
+// LightClientSeal is the json form of an aggregated seal.
+type LightClientSeal struct {
+	Bitmap    *hexutil.Big  `json:"bitmap"`
+	Signature hexutil.Bytes `json:"signature"`
+	Round     *hexutil.Big  `json:"round"`
+}
+
+// LightClientExtra is the json form of the istanbul extra of a header.
+type LightClientExtra struct {
+	AddedValidators             []common.Address                  `json:"addedValidators"`
+	AddedValidatorsPublicKeys   []blscrypto.SerializedPublicKey   `json:"addedValidatorsPublicKeys"`
+	AddedValidatorsG1PublicKeys []blscrypto.SerializedG1PublicKey `json:"addedValidatorsG1PublicKeys"`
+	RemovedValidators           *hexutil.Big                      `json:"removedValidators"`
+	Seal                        hexutil.Bytes                     `json:"seal"`
+	AggregatedSeal              LightClientSeal                   `json:"aggregatedSeal"`
+	ParentAggregatedSeal        LightClientSeal                   `json:"parentAggregatedSeal"`
+}
+
+// LightClientUpdate is the last header of an epoch. It is signed by the validators
+// of the epoch and its extra carries the changes of the validator set of the next one.
+type LightClientUpdate struct {
+	Epoch               hexutil.Uint64    `json:"epoch"`
+	Number              hexutil.Uint64    `json:"number"`
+	Hash                common.Hash       `json:"hash"`
+	Header              *types.Header     `json:"header"`
+	Extra               *LightClientExtra `json:"extra"`
+	Signers             []common.Address  `json:"signers"`
+	AggregatedPublicKey hexutil.Bytes     `json:"aggregatedPublicKey"`
+	// Input is the abi encoding of the arguments of updateBlockHeader in
+	// params.LightClientUpdateABIJSON, without the method selector.
+	Input hexutil.Bytes `json:"input"`
+}
+
+// The abi forms of the header and its istanbul extra, the fields are matched with
+// the components of the tuples in params.LightClientUpdateABIJSON.
+type abiHeader struct {
+	ParentHash  [32]byte
+	Coinbase    common.Address
+	Root        [32]byte
+	TxHash      [32]byte
+	ReceiptHash [32]byte
+	Bloom       []byte
+	Number      *big.Int
+	GasLimit    *big.Int
+	GasUsed     *big.Int
+	Time        *big.Int
+	ExtraData   []byte
+	MixDigest   [32]byte
+	Nonce       [8]byte
+	BaseFee     *big.Int
+}
+
+type abiSeal struct {
+	Bitmap    *big.Int
+	Signature []byte
+	Round     *big.Int
+}
+
+type abiExtra struct {
+	AddedValidators      []common.Address
+	AddedPubKey          [][]byte
+	AddedG1PubKey        [][]byte
+	RemoveList           *big.Int
+	Seal                 []byte
+	AggregatedSeal       abiSeal
+	ParentAggregatedSeal abiSeal
+}
+
+func bigOrZero(n *big.Int) *big.Int {
+	if n == nil {
+		return new(big.Int)
+	}
+	return n
+}
+
+func newLightClientSeal(seal types.IstanbulAggregatedSeal) LightClientSeal {
+	return LightClientSeal{
+		Bitmap:    (*hexutil.Big)(bigOrZero(seal.Bitmap)),
+		Signature: seal.Signature,
+		Round:     (*hexutil.Big)(bigOrZero(seal.Round)),
+	}
+}
+
+func newLightClientExtra(extra *types.IstanbulExtra) *LightClientExtra {
+	return &LightClientExtra{
+		AddedValidators:             extra.AddedValidators,
+		AddedValidatorsPublicKeys:   extra.AddedValidatorsPublicKeys,
+		AddedValidatorsG1PublicKeys: extra.AddedValidatorsG1PublicKeys,
+		RemovedValidators:           (*hexutil.Big)(bigOrZero(extra.RemovedValidators)),
+		Seal:                        extra.Seal,
+		AggregatedSeal:              newLightClientSeal(extra.AggregatedSeal),
+		ParentAggregatedSeal:        newLightClientSeal(extra.ParentAggregatedSeal),
+	}
+}
+
+func newABISeal(seal types.IstanbulAggregatedSeal) abiSeal {
+	return abiSeal{
+		Bitmap:    bigOrZero(seal.Bitmap),
+		Signature: seal.Signature,
+		Round:     bigOrZero(seal.Round),
+	}
+}
+
+// packLightClientUpdate abi encodes the header, its istanbul extra and the aggregated
+// public key of the signers as the arguments of updateBlockHeader.
+func packLightClientUpdate(header *types.Header, extra *types.IstanbulExtra, aggPk []byte) ([]byte, error) {
+	h := abiHeader{
+		ParentHash:  header.ParentHash,
+		Coinbase:    header.Coinbase,
+		Root:        header.Root,
+		TxHash:      header.TxHash,
+		ReceiptHash: header.ReceiptHash,
+		Bloom:       header.Bloom.Bytes(),
+		Number:      bigOrZero(header.Number),
+		GasLimit:    new(big.Int).SetUint64(header.GasLimit),
+		GasUsed:     new(big.Int).SetUint64(header.GasUsed),
+		Time:        new(big.Int).SetUint64(header.Time),
+		ExtraData:   header.Extra,
+		MixDigest:   header.MixDigest,
+		Nonce:       header.Nonce,
+		BaseFee:     bigOrZero(header.BaseFee),
+	}
+	ist := abiExtra{
+		AddedValidators:      extra.AddedValidators,
+		AddedPubKey:          make([][]byte, len(extra.AddedValidatorsPublicKeys)),
+		AddedG1PubKey:        make([][]byte, len(extra.AddedValidatorsG1PublicKeys)),
+		RemoveList:           bigOrZero(extra.RemovedValidators),
+		Seal:                 extra.Seal,
+		AggregatedSeal:       newABISeal(extra.AggregatedSeal),
+		ParentAggregatedSeal: newABISeal(extra.ParentAggregatedSeal),
+	}
+	if ist.AddedValidators == nil {
+		ist.AddedValidators = []common.Address{}
+	}
+	for i := range extra.AddedValidatorsPublicKeys {
+		ist.AddedPubKey[i] = extra.AddedValidatorsPublicKeys[i][:]
+	}
+	for i := range extra.AddedValidatorsG1PublicKeys {
+		ist.AddedG1PubKey[i] = extra.AddedValidatorsG1PublicKeys[i][:]
+	}
+	return lightClientABI.Methods["updateBlockHeader"].Inputs.Pack(h, ist, aggPk)
+}
+
+// aggregateSigners returns the validators of the set flagged in the bitmap and the
+// sum of their BLS public keys, the key the aggregated seal is verified against.
+// The key is empty if no validator signed.
+func aggregateSigners(validators istanbul.ValidatorSet, bitmap *big.Int) ([]common.Address, []byte, error) {
+	var (
+		signers []common.Address
+		pks     []*blscrypto.PublicKey
+	)
+	if bitmap == nil {
+		return signers, []byte{}, nil
+	}
+	if bitmap.BitLen() > validators.Size() {
+		return nil, nil, errInvalidAggregatedSeal
+	}
+	for i := 0; i < validators.Size(); i++ {
+		if bitmap.Bit(i) == 0 {
+			continue
+		}
+		val := validators.GetByIndex(uint64(i))
+		key := val.BLSPublicKey()
+		pk, err := blscrypto.UnmarshalPk(key[:])
+		if err != nil {
+			return nil, nil, fmt.Errorf("validator %s: %v", val.Address().Hex(), err)
+		}
+		signers = append(signers, val.Address())
+		pks = append(pks, pk)
+	}
+	if len(pks) == 0 {
+		return signers, []byte{}, nil
+	}
+	return signers, blscrypto.AggregatePK(pks).Marshal(), nil
+}
+
+// epochUpdate builds the update of the given epoch from its last header.
+func (api *LightClientAPI) epochUpdate(epoch uint64) (*LightClientUpdate, error) {
+	number := istanbul.GetEpochLastBlockNumber(epoch, api.istanbul.EpochSize())
+	header := api.chain.GetHeaderByNumber(number)
+	if header == nil {
+		return nil, errUnknownBlock
+	}
+	extra, err := types.ExtractIstanbulExtra(header)
+	if err != nil {
+		return nil, err
+	}
+	signers, aggPk := []common.Address{}, []byte{}
+	// The genesis block is trusted as is, the others are signed by the validators
+	// of the parent
+	if number > 0 {
+		snap, err := api.istanbul.snapshot(api.chain, number-1, header.ParentHash, nil)
+		if err != nil {
+			return nil, err
+		}
+		signers, aggPk, err = aggregateSigners(snap.ValSet, extra.AggregatedSeal.Bitmap)
+		if err != nil {
+			return nil, err
+		}
+	}
+	input, err := packLightClientUpdate(header, extra, aggPk)
+	if err != nil {
+		return nil, err
+	}
+	return &LightClientUpdate{
+		Epoch:               hexutil.Uint64(epoch),
+		Number:              hexutil.Uint64(number),
+		Hash:                header.Hash(),
+		Header:              header,
+		Extra:               newLightClientExtra(extra),
+		Signers:             signers,
+		AggregatedPublicKey: aggPk,
+		Input:               input,
+	}, nil
+}
+
+// GetEpochUpdates returns the last headers of the epochs from the trusted epoch on, at
+// most count of them. A light client trusting the validators of the trusted epoch
+// applies them in order to learn the validators of the following epochs.
+func (api *LightClientAPI) GetEpochUpdates(trustedEpoch uint64, count *uint64) ([]*LightClientUpdate, error) {
+	limit := uint64(MaxLightClientUpdates)
+	if count != nil && *count < limit {
+		limit = *count
+	}
+	current := api.chain.CurrentHeader().Number.Uint64()
+	lastEpoch := istanbul.GetEpochNumber(current, api.istanbul.EpochSize())
+	// The epoch in progress has no last header yet
+	if !istanbul.IsLastBlockOfEpoch(current, api.istanbul.EpochSize()) {
+		lastEpoch--
+	}
+	if trustedEpoch > lastEpoch {
+		return nil, errNoEpochUpdate
+	}
+
+	updates := make([]*LightClientUpdate, 0, limit)
+	for epoch := trustedEpoch; epoch <= lastEpoch && uint64(len(updates)) < limit; epoch++ {
+		update, err := api.epochUpdate(epoch)
+		if err != nil {
+			return nil, fmt.Errorf("epoch %d: %v", epoch, err)
+		}
+		updates = append(updates, update)
+	}
+	return updates, nil
+}
@@ -0,0 +1,108 @@
+package backend
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	. "github.com/onsi/gomega"
+
+	"github.com/mapprotocol/atlas/accounts/abi"
+	"github.com/mapprotocol/atlas/consensus/istanbul"
+	"github.com/mapprotocol/atlas/consensus/istanbul/validator"
+	"github.com/mapprotocol/atlas/core/types"
+	blscrypto "github.com/mapprotocol/atlas/helper/bls"
+)
+
+func newTestValidators(n int) []istanbul.ValidatorData {
+	validators := make([]istanbul.ValidatorData, n)
+	for i := range validators {
+		key, _ := crypto.GenerateKey()
+		blsPrivateKey, _ := blscrypto.CryptoType().ECDSAToBLS(key)
+		blsPublicKey, _ := blscrypto.CryptoType().PrivateToPublic(blsPrivateKey)
+		blsG1PublicKey, _ := blscrypto.CryptoType().PrivateToG1Public(blsPrivateKey)
+		validators[i] = istanbul.ValidatorData{
+			Address:        crypto.PubkeyToAddress(key.PublicKey),
+			BLSPublicKey:   blsPublicKey,
+			BLSG1PublicKey: blsG1PublicKey,
+		}
+	}
+	return validators
+}
+
+func TestAggregateSigners(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	validators := newTestValidators(3)
+	valSet := validator.NewSet(validators)
+
+	signers, aggPk, err := aggregateSigners(valSet, big.NewInt(5))
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(signers).To(Equal([]common.Address{valSet.GetByIndex(0).Address(), valSet.GetByIndex(2).Address()}))
+
+	key0, key2 := valSet.GetByIndex(0).BLSPublicKey(), valSet.GetByIndex(2).BLSPublicKey()
+	pk0, err := blscrypto.UnmarshalPk(key0[:])
+	g.Expect(err).ToNot(HaveOccurred())
+	pk2, err := blscrypto.UnmarshalPk(key2[:])
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(aggPk).To(Equal(blscrypto.AggregatePK([]*blscrypto.PublicKey{pk0, pk2}).Marshal()))
+
+	signers, aggPk, err = aggregateSigners(valSet, new(big.Int))
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(signers).To(BeEmpty())
+	g.Expect(aggPk).To(BeEmpty())
+
+	_, _, err = aggregateSigners(valSet, big.NewInt(8))
+	g.Expect(err).To(BeIdenticalTo(errInvalidAggregatedSeal))
+}
+
+func TestPackLightClientUpdate(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	validators := newTestValidators(2)
+	extra := &types.IstanbulExtra{
+		AddedValidators:             []common.Address{validators[0].Address, validators[1].Address},
+		AddedValidatorsPublicKeys:   []blscrypto.SerializedPublicKey{validators[0].BLSPublicKey, validators[1].BLSPublicKey},
+		AddedValidatorsG1PublicKeys: []blscrypto.SerializedG1PublicKey{validators[0].BLSG1PublicKey, validators[1].BLSG1PublicKey},
+		RemovedValidators:           big.NewInt(1),
+		Seal:                        []byte{0x01, 0x02},
+		AggregatedSeal:              types.IstanbulAggregatedSeal{Bitmap: big.NewInt(3), Signature: []byte{0x03}, Round: big.NewInt(1)},
+	}
+	header := &types.Header{
+		ParentHash: common.Hash{0x01},
+		Coinbase:   validators[0].Address,
+		Number:     big.NewInt(100),
+		GasLimit:   8000000,
+		Time:       1600000000,
+		Extra:      []byte("extra"),
+	}
+	aggPk := []byte{0x04, 0x05}
+
+	input, err := packLightClientUpdate(header, extra, aggPk)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	values, err := lightClientABI.Methods["updateBlockHeader"].Inputs.Unpack(input)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(values).To(HaveLen(3))
+
+	h := *abi.ConvertType(values[0], new(abiHeader)).(*abiHeader)
+	g.Expect(common.Hash(h.ParentHash)).To(Equal(header.ParentHash))
+	g.Expect(h.Coinbase).To(Equal(header.Coinbase))
+	g.Expect(h.Number.Uint64()).To(Equal(uint64(100)))
+	g.Expect(h.GasLimit.Uint64()).To(Equal(header.GasLimit))
+	g.Expect(h.ExtraData).To(Equal(header.Extra))
+	g.Expect(h.Bloom).To(HaveLen(types.BloomByteLength))
+	g.Expect(h.BaseFee.Sign()).To(BeZero())
+
+	ist := *abi.ConvertType(values[1], new(abiExtra)).(*abiExtra)
+	g.Expect(ist.AddedValidators).To(Equal(extra.AddedValidators))
+	g.Expect(ist.AddedPubKey).To(HaveLen(2))
+	g.Expect(ist.AddedPubKey[1]).To(Equal(validators[1].BLSPublicKey[:]))
+	g.Expect(ist.AddedG1PubKey[0]).To(Equal(validators[0].BLSG1PublicKey[:]))
+	g.Expect(ist.RemoveList.Uint64()).To(Equal(uint64(1)))
+	g.Expect(ist.AggregatedSeal.Bitmap.Uint64()).To(Equal(uint64(3)))
+	g.Expect(ist.ParentAggregatedSeal.Bitmap.Sign()).To(BeZero())
+
+	g.Expect(values[2]).To(Equal(aggPk))
+}
@@ -0,0 +1,40 @@
+package backend
+
+import (
+	"github.com/mapprotocol/atlas/consensus"
+	mmr "github.com/mapprotocol/atlas/core/mmr"
+	"github.com/mapprotocol/atlas/core/state"
+	"github.com/mapprotocol/atlas/core/types"
+)
+
+// getChainMmr returns the MMR over the canonical block hashes, loading it from the
+// database on first use.
+func (sb *Backend) getChainMmr() (*mmr.ChainMmr, error) {
+	sb.chainMmrOnce.Do(func() {
+		sb.chainMmr, sb.chainMmrErr = mmr.NewChainMmr(sb.db)
+	})
+	return sb.chainMmr, sb.chainMmrErr
+}
+
+// commitMmrRoot records in the state of the block the root of the MMR over the
+// blocks before it.
+func (sb *Backend) commitMmrRoot(chain consensus.ChainHeaderReader, header *types.Header, state *state.StateDB) error {
+	number := header.Number.Uint64()
+	if number == 0 {
+		return nil
+	}
+	parent := chain.GetHeader(header.ParentHash, number-1)
+	if parent == nil {
+		return consensus.ErrUnknownAncestor
+	}
+	cm, err := sb.getChainMmr()
+	if err != nil {
+		return err
+	}
+	root, err := cm.RootAt(parent)
+	if err != nil {
+		return err
+	}
+	mmr.CommitRoot(state, header.Number, root)
+	return nil
+}
@@ -0,0 +1,124 @@
+// Copyright 2021 MAP Protocol Authors.
+// This file is part of MAP Protocol.
+
+// MAP Protocol is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// MAP Protocol is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with MAP Protocol.  If not, see <http://www.gnu.org/licenses/>.
+
+package backend
+
+import (
+	"encoding/binary"
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rlp"
+
+	"github.com/mapprotocol/atlas/core/types"
+)
+
+// blockParticipation is who took part in sealing a block. Signed is the bitmap over the
+// validators of the block of the ones that signed it, in its own aggregated seal or in the
+// parent aggregated seal of the next block.
+type blockParticipation struct {
+	Validators []common.Address
+	Signed     *big.Int
+	Proposer   common.Address
+	Round      uint64
+}
+
+var errParticipationUnavailable = errors.New("validator set of the block is not available")
+
+// participationKey = "participation" + num (uint64 big endian) + hash
+func participationKey(number uint64, hash common.Hash) []byte {
+	enc := make([]byte, 8)
+	binary.BigEndian.PutUint64(enc, number)
+	return append(append([]byte("participation"), enc...), hash.Bytes()...)
+}
+
+func (sb *Backend) readParticipation(header *types.Header) *blockParticipation {
+	data, _ := sb.db.Get(participationKey(header.Number.Uint64(), header.Hash()))
+	if len(data) == 0 {
+		return nil
+	}
+	p := new(blockParticipation)
+	if err := rlp.DecodeBytes(data, p); err != nil {
+		sb.logger.Error("Invalid block participation RLP", "number", header.Number, "err", err)
+		return nil
+	}
+	return p
+}
+
+func (sb *Backend) writeParticipation(header *types.Header, p *blockParticipation) {
+	data, err := rlp.EncodeToBytes(p)
+	if err != nil {
+		sb.logger.Error("Failed to RLP encode block participation", "err", err)
+		return
+	}
+	if err := sb.db.Put(participationKey(header.Number.Uint64(), header.Hash()), data); err != nil {
+		sb.logger.Error("Failed to store block participation", "err", err)
+	}
+}
+
+// blockParticipation decodes the seals of the header, and of its child if any, into the
+// participation of its validators. Participation with the child seal is indexed on disk if
+// the node is configured to.
+func (sb *Backend) blockParticipation(header, child *types.Header) (*blockParticipation, error) {
+	complete := child != nil && child.ParentHash == header.Hash()
+	if complete && sb.config.ParticipationIndex {
+		if p := sb.readParticipation(header); p != nil {
+			return p, nil
+		}
+	}
+
+	extra, err := types.ExtractIstanbulExtra(header)
+	if err != nil {
+		return nil, err
+	}
+	validators := sb.GetValidators(new(big.Int).Sub(header.Number, common.Big1), header.ParentHash)
+	if len(validators) == 0 {
+		return nil, errParticipationUnavailable
+	}
+	proposer, err := sb.Author(header)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &blockParticipation{
+		Validators: make([]common.Address, 0, len(validators)),
+		Signed:     new(big.Int),
+		Proposer:   proposer,
+	}
+	for _, val := range validators {
+		p.Validators = append(p.Validators, val.Address())
+	}
+	if extra.AggregatedSeal.Bitmap != nil {
+		p.Signed.Or(p.Signed, extra.AggregatedSeal.Bitmap)
+	}
+	if extra.AggregatedSeal.Round != nil {
+		p.Round = extra.AggregatedSeal.Round.Uint64()
+	}
+	if complete {
+		childExtra, err := types.ExtractIstanbulExtra(child)
+		if err != nil {
+			return nil, err
+		}
+		if childExtra.ParentAggregatedSeal.Bitmap != nil {
+			p.Signed.Or(p.Signed, childExtra.ParentAggregatedSeal.Bitmap)
+		}
+		if sb.config.ParticipationIndex {
+			sb.writeParticipation(header, p)
+		}
+	}
+	return p, nil
+}
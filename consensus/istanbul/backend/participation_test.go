@@ -0,0 +1,48 @@
+package backend
+
+import (
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/mapprotocol/atlas/consensus/istanbul"
+	"github.com/mapprotocol/atlas/core/rawdb"
+	"github.com/mapprotocol/atlas/core/types"
+)
+
+func TestParticipationIndex(t *testing.T) {
+	sb := &Backend{
+		config: &istanbul.Config{ParticipationIndex: true},
+		db:     rawdb.NewMemoryDatabase(),
+		logger: log.New(),
+	}
+	header := &types.Header{Number: big.NewInt(10)}
+	child := &types.Header{Number: big.NewInt(11), ParentHash: header.Hash()}
+	want := &blockParticipation{
+		Validators: []common.Address{{0x1}, {0x2}, {0x3}},
+		Signed:     big.NewInt(5),
+		Proposer:   common.Address{0x3},
+		Round:      1,
+	}
+	sb.writeParticipation(header, want)
+
+	got, err := sb.blockParticipation(header, child)
+	if err != nil {
+		t.Fatalf("failed to read indexed participation: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("participation mismatch: have %v, want %v", got, want)
+	}
+
+	// A header of the same height on another branch is not indexed
+	if p := sb.readParticipation(&types.Header{Number: big.NewInt(10), Extra: []byte{0x1}}); p != nil {
+		t.Errorf("unexpected participation for another header: %v", p)
+	}
+	// The participation of the head is not complete, it is not read from the index
+	if _, err := sb.blockParticipation(header, nil); err == nil {
+		t.Error("expected an error decoding the seals of a header without istanbul extra")
+	}
+}
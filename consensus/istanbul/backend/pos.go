@@ -20,6 +20,7 @@ import (
 	"errors"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/mapprotocol/atlas/chains/relayer"
 	"github.com/mapprotocol/atlas/consensus/istanbul"
 	"github.com/mapprotocol/atlas/consensus/istanbul/uptime"
 	"github.com/mapprotocol/atlas/consensus/istanbul/uptime/store"
@@ -79,6 +80,28 @@ func (sb *Backend) distributeEpochRewards(header *types.Header, state *state.Sta
 		return err
 	}
 
+	// Downtime is only slashed for epochs fully monitored since the fork
+	epochFirstBlock := istanbul.MustGetEpochFirstBlockGivenBlockNumber(header.Number.Uint64(), sb.EpochSize())
+	if sb.chain.Config().IsDowntimeSlash(new(big.Int).SetUint64(epochFirstBlock)) {
+		if err := sb.slashDowntimes(vmRunner, header, state, signerSet); err != nil {
+			return err
+		}
+	}
+
+	// Relayers are paid out of the validator reward
+	if sb.chain.Config().IsRelayer(header.Number) {
+		relayerReward := big.NewInt(0)
+		if header.Number.Cmp(EnableRewardBlock) > 0 {
+			relayerReward.Mul(validatorVoterReward, params.RelayerRewardPercent)
+			relayerReward.Div(relayerReward, big.NewInt(100))
+		}
+		paid, err := sb.distributeRelayerRewards(vmRunner, header, state, relayerReward)
+		if err != nil {
+			return err
+		}
+		validatorVoterReward = new(big.Int).Sub(validatorVoterReward, paid)
+	}
+
 	if header.Number.Cmp(EnableRewardBlock) > 0 {
 		scores, err := sb.calculatePaymentScoreDenominator(vmRunner, uptimeRets, ignores)
 		if err != nil {
@@ -211,6 +234,29 @@ func (sb *Backend) distributeValidatorRewards(vmRunner vm.EVMRunner, signerSet [
 	return totalValidatorRewards, voterRewards, nil
 }
 
+// distributeRelayerRewards settles the relayer registry for the epoch ending with the
+// header and mints the relayer rewards. It returns the total amount minted.
+func (sb *Backend) distributeRelayerRewards(vmRunner vm.EVMRunner, header *types.Header, state *state.StateDB, reward *big.Int) (*big.Int, error) {
+	epoch := istanbul.GetEpochNumber(header.Number.Uint64(), sb.EpochSize())
+	payouts, err := relayer.Settle(state, epoch, reward)
+	if err != nil {
+		return nil, err
+	}
+	total := big.NewInt(0)
+	for _, p := range payouts {
+		if p.Amount.Sign() == 0 {
+			continue
+		}
+		if err := gold_token.Mint(vmRunner, p.Relayer, p.Amount); err != nil {
+			sb.logger.Error("Error in distributing rewards to relayer", "address", p.Relayer, "err", err)
+			return nil, err
+		}
+		total.Add(total, p.Amount)
+	}
+	log.Info("distributeRelayerRewards", "epoch", epoch, "relayers", len(payouts), "totalRelayerRewards", total.String())
+	return total, nil
+}
+
 func (sb *Backend) setInitialGoldTokenTotalSupplyIfUnset(vmRunner vm.EVMRunner) error {
 	totalSupply, err := gold_token.GetTotalSupply(vmRunner)
 	if err != nil {
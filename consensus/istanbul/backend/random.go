@@ -19,12 +19,10 @@ package backend
 import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/mapprotocol/atlas/consensus/istanbul"
 	"github.com/mapprotocol/atlas/contracts/random"
 )
 
-// String for creating the random seed
-var randomSeedString = []byte("Randomness seed string")
-
 // GenerateRandomness will generate the random beacon randomness
 func (sb *Backend) GenerateRandomness(parentHash common.Hash) (common.Hash, common.Hash, error) {
 	logger := sb.logger.New("func", "GenerateRandomness")
@@ -43,7 +41,7 @@ func (sb *Backend) GenerateRandomness(parentHash common.Hash) (common.Hash, comm
 	if sb.randomSeed == nil {
 		var err error
 		w := sb.wallets()
-		sb.randomSeed, err = w.Ecdsa.SignHash(common.BytesToHash(randomSeedString))
+		sb.randomSeed, err = w.Ecdsa.SignHash(istanbul.RandomSeedHash)
 		if err != nil {
 			logger.Error("Failed to create randomSeed", "err", err)
 			sb.randomSeedMu.Unlock()
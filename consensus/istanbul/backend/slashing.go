@@ -0,0 +1,189 @@
+// Copyright 2021 MAP Protocol Authors.
+// This file is part of MAP Protocol.
+
+// MAP Protocol is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// MAP Protocol is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with MAP Protocol.  If not, see <http://www.gnu.org/licenses/>.
+
+package backend
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/mapprotocol/atlas/consensus/istanbul"
+	"github.com/mapprotocol/atlas/consensus/istanbul/slashing"
+	"github.com/mapprotocol/atlas/consensus/istanbul/uptime"
+	"github.com/mapprotocol/atlas/contracts"
+	"github.com/mapprotocol/atlas/contracts/accounts"
+	"github.com/mapprotocol/atlas/contracts/election"
+	"github.com/mapprotocol/atlas/contracts/locked_gold"
+	"github.com/mapprotocol/atlas/contracts/validators"
+	"github.com/mapprotocol/atlas/core/state"
+	"github.com/mapprotocol/atlas/core/types"
+	"github.com/mapprotocol/atlas/core/vm"
+	"github.com/mapprotocol/atlas/params"
+)
+
+// slashValidator takes up to the penalty from the locked MAP of the validator signing with
+// signer and halves its slashing multiplier, on behalf of the slasher. The reporter gets up
+// to the reward out of the penalty if it signs for an account.
+func (sb *Backend) slashValidator(vmRunner vm.EVMRunner, slasher, signer common.Address, penalty *big.Int, reporter common.Address, reward *big.Int) (*slashing.Event, error) {
+	account, err := accounts.GetSignerToAccountMethod(vmRunner, signer)
+	if err != nil {
+		return nil, err
+	}
+	event := &slashing.Event{Signer: signer, Account: account, Penalty: new(big.Int), Reward: new(big.Int)}
+	if reporterAccount, err := accounts.GetSignerToAccountMethod(vmRunner, reporter); err == nil {
+		event.Reporter = reporterAccount
+		event.Reward.Set(reward)
+	}
+
+	total, err := locked_gold.GetAccountTotalLockedGold(vmRunner, account)
+	if err != nil {
+		return nil, err
+	}
+	nonvoting, err := locked_gold.GetAccountNonvotingLockedGold(vmRunner, account)
+	if err != nil {
+		return nil, err
+	}
+	event.Penalty.Set(penalty)
+	if total.Cmp(event.Penalty) < 0 {
+		event.Penalty.Set(total)
+	}
+	if event.Reward.Cmp(event.Penalty) > 0 {
+		event.Reward.Set(event.Penalty)
+	}
+	if event.Penalty.Sign() > 0 {
+		var (
+			lessers, greaters []common.Address
+			indices           []*big.Int
+		)
+		if nonvoting.Cmp(event.Penalty) < 0 {
+			lessers, greaters, indices, err = election.GetLessersAndGreatersAfterSlash(vmRunner, account, new(big.Int).Sub(event.Penalty, nonvoting))
+			if err != nil {
+				return nil, err
+			}
+		}
+		err = locked_gold.Slash(vmRunner, slasher, account, event.Penalty, event.Reporter, event.Reward, lessers, greaters, indices)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := validators.HalveSlashingMultiplier(vmRunner, slasher, account); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// registerSlasher registers the address the node slashes from under the name and allows it
+// to slash locked gold, unless it already can. Genesis blocks made after the slashing forks
+// register the slashers, older chains register them at the fork block.
+func (sb *Backend) registerSlasher(vmRunner vm.EVMRunner, name string, slasher common.Address) error {
+	if registered, err := locked_gold.IsSlasher(vmRunner, slasher); err != nil {
+		return err
+	} else if registered {
+		return nil
+	}
+	if err := contracts.SetRegisteredAddress(vmRunner, name, slasher); err != nil {
+		return err
+	}
+	if err := locked_gold.AddSlasher(vmRunner, name); err != nil {
+		return err
+	}
+	sb.logger.Info("Registered slasher", "name", name, "address", slasher)
+	return nil
+}
+
+// slashDowntimes slashes the validators of the epoch ending with the header that signed no
+// block for more than the slashable number of consecutive lookback windows, and jails them
+// out of the next election. The proposer of the header reports them.
+//
+// The downtimes are computed from the headers of the epoch with the lookback window in
+// effect at the header, so that every node slashes the same validators. It fails with
+// errUnknownDowntimes if they cannot be, which fails the finalization of the header.
+func (sb *Backend) slashDowntimes(vmRunner vm.EVMRunner, header *types.Header, state *state.StateDB, signerSet []istanbul.Validator) error {
+	epoch := istanbul.GetEpochNumber(header.Number.Uint64(), sb.EpochSize())
+	logger := sb.logger.New("func", "Backend.slashDowntimes", "blocknum", header.Number.Uint64(), "epoch", epoch)
+
+	headers, err := sb.epochHeaders(header)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errUnknownDowntimes, err)
+	}
+	monitor := uptime.NewMonitor(nil, sb.EpochSize(), sb.LookbackWindow(header, state))
+	downtimes, err := monitor.ComputeValidatorsDowntime(epoch, len(signerSet), headers)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errUnknownDowntimes, err)
+	}
+
+	penalty, reward, slashableWindows := slashing.DowntimeParameters(state)
+	for i, val := range signerSet {
+		if downtimes[i] <= slashableWindows {
+			continue
+		}
+		logger := logger.New("signer", val.Address(), "missedWindows", downtimes[i])
+		snapshot := state.Snapshot()
+		event, err := sb.slashValidator(vmRunner, params.DowntimeSlasherAddress, val.Address(), penalty, header.Coinbase, reward)
+		if err != nil {
+			logger.Error("Failed to slash validator for downtime", "err", err)
+			state.RevertToSnapshot(snapshot)
+			continue
+		}
+		event.Reason, event.Number = slashing.ReasonDowntime, header.Number.Uint64()
+		if err := slashing.AddEvent(state, epoch, event); err != nil {
+			logger.Error("Failed to record slashing event", "err", err)
+		}
+		slashing.Jail(state, event.Account, epoch+1)
+		logger.Info("Slashed validator for downtime", "account", event.Account, "penalty", event.Penalty)
+	}
+	return nil
+}
+
+// epochHeaders returns the headers of the epoch of the header that precede it, in ascending
+// order. Their parent aggregated seals cover the blocks of the epoch up to the header's parent.
+func (sb *Backend) epochHeaders(header *types.Header) ([]*types.Header, error) {
+	first := istanbul.MustGetEpochFirstBlockGivenBlockNumber(header.Number.Uint64(), sb.EpochSize())
+	headers := make([]*types.Header, 0, header.Number.Uint64()-first)
+	for number, hash := header.Number.Uint64(), header.ParentHash; number > first; {
+		number--
+		parent := sb.chain.GetHeader(hash, number)
+		if parent == nil {
+			return nil, fmt.Errorf("missing header %d %x", number, hash)
+		}
+		headers = append(headers, parent)
+		hash = parent.ParentHash
+	}
+	for i, j := 0, len(headers)-1; i < j; i, j = i+1, j-1 {
+		headers[i], headers[j] = headers[j], headers[i]
+	}
+	return headers, nil
+}
+
+// removeJailedValidators removes the signers of the accounts jailed for the epoch from the
+// elected ones. The election is kept as is rather than left without validators.
+func (sb *Backend) removeJailedValidators(vmRunner vm.EVMRunner, state *state.StateDB, signers []common.Address, epoch uint64) []common.Address {
+	kept := make([]common.Address, 0, len(signers))
+	for _, signer := range signers {
+		account, err := accounts.GetSignerToAccountMethod(vmRunner, signer)
+		if err == nil && slashing.IsJailed(state, account, epoch) {
+			sb.logger.Info("Jailed validator is not elected", "signer", signer, "account", account, "epoch", epoch)
+			continue
+		}
+		kept = append(kept, signer)
+	}
+	if len(kept) == 0 {
+		return signers
+	}
+	return kept
+}
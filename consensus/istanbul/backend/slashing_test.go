@@ -0,0 +1,113 @@
+package backend
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+	. "github.com/onsi/gomega"
+
+	"github.com/mapprotocol/atlas/contracts/abis"
+	"github.com/mapprotocol/atlas/contracts/locked_gold"
+	"github.com/mapprotocol/atlas/contracts/testutil"
+	"github.com/mapprotocol/atlas/params"
+)
+
+var contractsOwner = common.HexToAddress("0x0fe0")
+
+type registryMock struct {
+	testutil.ContractMock
+	contracts map[common.Hash]common.Address
+	updates   int
+}
+
+func (rm *registryMock) GetAddressFor(id common.Hash) common.Address {
+	return rm.contracts[id]
+}
+
+func (rm *registryMock) SetAddressFor(name string, address common.Address) {
+	rm.contracts[crypto.Keccak256Hash([]byte(name))] = address
+	rm.updates++
+}
+
+func (rm *registryMock) Owner() common.Address {
+	return contractsOwner
+}
+
+type lockedGoldMock struct {
+	testutil.ContractMock
+	registry  *registryMock
+	whitelist []common.Hash
+}
+
+func (lm *lockedGoldMock) IsSlasher(slasher common.Address) bool {
+	for _, id := range lm.whitelist {
+		if lm.registry.contracts[id] == slasher {
+			return true
+		}
+	}
+	return false
+}
+
+func (lm *lockedGoldMock) AddSlasher(name string) error {
+	id := crypto.Keccak256Hash([]byte(name))
+	if lm.registry.contracts[id] == (common.Address{}) {
+		return errors.New("identifier is not registered")
+	}
+	for _, slasher := range lm.whitelist {
+		if slasher == id {
+			return errors.New("cannot add slasher ID twice")
+		}
+	}
+	lm.whitelist = append(lm.whitelist, id)
+	return nil
+}
+
+func (lm *lockedGoldMock) Owner() common.Address {
+	return contractsOwner
+}
+
+// newPreForkRunner returns the contracts of a chain whose genesis predates the slashing
+// forks, LockedGold allows no slasher.
+func newPreForkRunner() (*testutil.MockEVMRunner, *registryMock) {
+	registry := &registryMock{contracts: make(map[common.Hash]common.Address)}
+	registry.ContractMock = testutil.NewContractMock(abis.Registry, registry)
+	lockedGold := &lockedGoldMock{registry: registry}
+	lockedGold.ContractMock = testutil.NewContractMock(abis.LockedGold, lockedGold)
+
+	runner := testutil.NewMockEVMRunner()
+	runner.RegisterContract(params.RegistrySmartContractAddress, registry)
+	lockedGoldAddress := common.HexToAddress("0x0fe1")
+	registry.contracts[params.LockedGoldRegistryId] = lockedGoldAddress
+	runner.RegisterContract(lockedGoldAddress, lockedGold)
+	return runner, registry
+}
+
+func TestRegisterSlasher(t *testing.T) {
+	g := NewGomegaWithT(t)
+	sb := &Backend{logger: log.New()}
+	runner, registry := newPreForkRunner()
+
+	for _, addr := range []common.Address{params.EvidenceAddress, params.DowntimeSlasherAddress} {
+		registered, err := locked_gold.IsSlasher(runner, addr)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(registered).To(BeFalse())
+	}
+
+	// The fork blocks register the slashers
+	g.Expect(sb.registerSlasher(runner, "DoubleSigningSlasher", params.EvidenceAddress)).To(Succeed())
+	g.Expect(sb.registerSlasher(runner, "DowntimeSlasher", params.DowntimeSlasherAddress)).To(Succeed())
+	g.Expect(registry.contracts[params.DoubleSigningSlasherRegistryId]).To(Equal(params.EvidenceAddress))
+	g.Expect(registry.contracts[params.DowntimeSlasherRegistryId]).To(Equal(params.DowntimeSlasherAddress))
+	for _, addr := range []common.Address{params.EvidenceAddress, params.DowntimeSlasherAddress} {
+		registered, err := locked_gold.IsSlasher(runner, addr)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(registered).To(BeTrue())
+	}
+
+	// Slashers registered by the genesis are left as they are
+	g.Expect(sb.registerSlasher(runner, "DowntimeSlasher", params.DowntimeSlasherAddress)).To(Succeed())
+	g.Expect(registry.updates).To(Equal(2))
+}
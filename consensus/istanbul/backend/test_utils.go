@@ -61,7 +61,8 @@ func newBlockChainWithKeys(isProxy bool, proxiedValAddress common.Address, isPro
 		signerFn := SignFn(privateKey)
 		signerBLSFn := SignBLSFn(privateKey)
 		signerHashFn := SignHashFn(privateKey)
-		b.Authorize(address, address, &publicKey, decryptFn, signerFn, signerBLSFn, signerHashFn)
+		signerTxFn := SignTxFn(privateKey)
+		b.Authorize(address, address, &publicKey, decryptFn, signerFn, signerBLSFn, signerHashFn, signerTxFn)
 	} else {
 		proxyNodeKey, _ := crypto.GenerateKey()
 		publicKey = proxyNodeKey.PublicKey
@@ -338,6 +339,10 @@ func SignBLSFn(key *ecdsa.PrivateKey) istanbul.BLSSignerFn {
 		//if err != nil {
 		//	return blscrypto.SerializedSignature{}, err
 		//}
+		data, err = blscrypto.PrepareMessage(data, extraData, useComposite)
+		if err != nil {
+			return blscrypto.SerializedSignature{}, err
+		}
 		var signature *blscrypto.Signature
 		if params.IsBN256Fork(fork, cur) {
 			signature, err = blscrypto.Sign2(prikey, prikey.ToPublic(), data)
@@ -364,12 +369,22 @@ func SignHashFn(key *ecdsa.PrivateKey) istanbul.HashSignerFn {
 	}
 }
 
+func SignTxFn(key *ecdsa.PrivateKey) istanbul.TxSignerFn {
+	if key == nil {
+		key, _ = generatePrivateKey()
+	}
+
+	return func(_ accounts.Account, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+		return types.SignTx(tx, types.LatestSignerForChainID(chainID), key)
+	}
+}
+
 func newBackend() (b *Backend) {
 	_, b = newBlockChain(4, true)
 
 	key, _ := generatePrivateKey()
 	address := crypto.PubkeyToAddress(key.PublicKey)
-	b.Authorize(address, address, &key.PublicKey, DecryptFn(key), SignFn(key), SignBLSFn(key), SignHashFn(key))
+	b.Authorize(address, address, &key.PublicKey, DecryptFn(key), SignFn(key), SignBLSFn(key), SignHashFn(key), SignTxFn(key))
 	return
 }
 
@@ -55,6 +55,9 @@ type Config struct {
 	RoundStateDBPath            string         `toml:",omitempty"` // The location for the round states DB
 	Validator                   bool           `toml:",omitempty"` // Specified if this node is configured to validate  (specifically if --mine command line is set)
 	Replica                     bool           `toml:",omitempty"` // Specified if this node is configured to be a replica
+	ParticipationIndex          bool           `toml:",omitempty"` // Specifies if the validator participation of queried blocks is indexed on disk
+	RemoteSigner                string         `toml:",omitempty"` // The URL of the signer service holding the validator keys, if they are not in the keystore
+	RemoteSignerTokenFile       string         `toml:",omitempty"` // The file holding the token the signer service authenticates the node with
 
 	// Proxy Configs
 	Proxy                   bool           `toml:",omitempty"` // Specifies if this node is a proxy
@@ -64,6 +67,13 @@ type Config struct {
 	Proxied      bool           `toml:",omitempty"` // Specifies if this node is proxied
 	ProxyConfigs []*ProxyConfig `toml:",omitempty"` // The set of proxy configs for this proxied validator at startup
 
+	// Failover Configs
+	FailoverPeer              string `toml:",omitempty"` // The URL of the failover channel of the other node of the validator, enables the failover if set
+	FailoverListenAddr        string `toml:",omitempty"` // The address the failover channel listens on for the heartbeats of the other node
+	FailoverHeartbeatInterval uint64 `toml:",omitempty"` // Time duration (in milliseconds) between heartbeats of the primary
+	FailoverMissedHeartbeats  uint64 `toml:",omitempty"` // The number of heartbeats in a row the primary must miss before the replica takes over
+	FailoverLeaseBlocks       uint64 `toml:",omitempty"` // The number of blocks the primary keeps validating for without its replica acknowledging it
+
 	// Announce Configs
 	AnnounceQueryEnodeGossipPeriod                 uint64 `toml:",omitempty"` // Time duration (in seconds) between gossiped query enode messages
 	AnnounceAggressiveQueryEnodeGossipOnEnablement bool   `toml:",omitempty"` // Specifies if this node should aggressively query enodes on announce enablement
@@ -97,6 +107,10 @@ var DefaultConfig = &Config{
 	Replica:                        false,
 	Proxy:                          false,
 	Proxied:                        false,
+	FailoverListenAddr:             ":7449",
+	FailoverHeartbeatInterval:      1000,
+	FailoverMissedHeartbeats:       10,
+	FailoverLeaseBlocks:            12,
 	AnnounceQueryEnodeGossipPeriod: 300, // 5 minutes
 	AnnounceAggressiveQueryEnodeGossipOnEnablement: true,
 	AnnounceAdditionalValidatorsToGossip:           10,
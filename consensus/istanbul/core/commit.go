@@ -28,11 +28,6 @@ import (
 	blscrypto "github.com/mapprotocol/atlas/helper/bls"
 )
 
-// maxValidators represents the maximum number of validators the SNARK circuit supports
-// The prover code will then pad any proofs to this maximum to ensure consistent proof structure
-// TODO: Eventually make this governable
-const maxValidators = uint32(150)
-
 func (c *core) sendCommit() {
 	logger := c.newLogger("func", "sendCommit")
 	logger.Trace("Sending commit")
@@ -57,31 +52,16 @@ func (c *core) generateEpochValidatorSetData(blockNumber uint64, round uint8, bl
 		return nil, nil, false, errNotLastBlockInEpoch
 	}
 
-	// Serialize the public keys for the validators in the validator set.
-	blsPubKeys := []blscrypto.SerializedPublicKey{}
-	for _, v := range newValSet.List() {
-		blsPubKeys = append(blsPubKeys, v.BLSPublicKey())
-	}
-
-	maxNonSigners := uint32(newValSet.Size() - newValSet.MinQuorumSize())
-
-	// Before the Donut fork, use the snark data encoding with epoch entropy.
-
 	// Retrieve the block hash for the last block of the previous epoch.
 	parentEpochBlockHash := c.backend.HashForBlock(blockNumber - c.config.Epoch)
 	if blockNumber > 0 && parentEpochBlockHash == (common.Hash{}) {
 		return nil, nil, false, errors.New("unknown block")
 	}
 
-	maxNonSigners = maxValidators - uint32(newValSet.MinQuorumSize())
-	message, extraData, err := blscrypto.CryptoType().EncodeEpochSnarkDataCIP22(
-		blsPubKeys, maxNonSigners, maxValidators,
-		uint16(istanbul.GetEpochNumber(blockNumber, c.config.Epoch)),
-		round,
-		blscrypto.EpochEntropyFromHash(blockHash),
-		blscrypto.EpochEntropyFromHash(parentEpochBlockHash),
-	)
-	// This is after the Donut hardfork, so signify this uses CIP22.
+	// From the snark fork on, the validators are encoded for the SNARK circuit instead of CIP22.
+	snark := c.backend.ChainConfig().IsSnark(new(big.Int).SetUint64(blockNumber))
+	message, extraData, err := istanbul.EpochValidatorSetData(snark, blockNumber, c.config.Epoch, round, blockHash, parentEpochBlockHash, newValSet)
+	// Both encodings are signed with the composite hasher and CIP22, as after the Donut hardfork.
 	return message, extraData, true, err
 }
 
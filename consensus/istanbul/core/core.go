@@ -103,6 +103,9 @@ type CoreBackend interface {
 
 	IsPrimaryForSeq(seq *big.Int) bool
 	UpdateReplicaState(seq *big.Int)
+
+	// ReportEvidence hands evidence of double signing to the evidence pool
+	ReportEvidence(ev *istanbul.Evidence)
 }
 
 type core struct {
@@ -147,6 +150,8 @@ type core struct {
 	handlePrepareTimer    metrics.Timer
 	handleCommitTimer     metrics.Timer
 	forwardedMap          map[string]struct{}
+
+	doubleSigns *doubleSignDetector
 }
 
 // New creates an Istanbul consensus core
@@ -174,6 +179,7 @@ func New(backend CoreBackend, config *istanbul.Config) Engine {
 		handlePrepareTimer:        metrics.NewRegisteredTimer("consensus/istanbul/core/handle_prepare", nil),
 		handleCommitTimer:         metrics.NewRegisteredTimer("consensus/istanbul/core/handle_commit", nil),
 		forwardedMap:              make(map[string]struct{}),
+		doubleSigns:               newDoubleSignDetector(),
 	}
 	msgBacklog := newMsgBacklog(
 		func(msg *istanbul.Message) {
@@ -0,0 +1,99 @@
+// Copyright 2021 MAP Protocol Authors.
+// This file is part of MAP Protocol.
+
+// MAP Protocol is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// MAP Protocol is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with MAP Protocol.  If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/mapprotocol/atlas/consensus/istanbul"
+)
+
+// signedKey identifies the PREPARE or COMMIT a validator may sign in a view.
+type signedKey struct {
+	sequence uint64
+	round    uint64
+	code     uint64
+	address  common.Address
+}
+
+// doubleSignDetector remembers the first PREPARE and COMMIT each validator signed in a
+// view. Another one for a different digest in the same view proves a double signing.
+// It is only used from the core event loop.
+type doubleSignDetector struct {
+	seen        map[signedKey]*istanbul.Message
+	maxSequence uint64
+}
+
+func newDoubleSignDetector() *doubleSignDetector {
+	return &doubleSignDetector{seen: make(map[signedKey]*istanbul.Message)}
+}
+
+// check records the signed message and returns evidence if it conflicts with one seen
+// before. The message signature must have been checked already.
+func (d *doubleSignDetector) check(msg *istanbul.Message) *istanbul.Evidence {
+	var sub *istanbul.Subject
+	switch msg.Code {
+	case istanbul.MsgPrepare:
+		sub = msg.Prepare()
+	case istanbul.MsgCommit:
+		if commit := msg.Commit(); commit != nil {
+			sub = commit.Subject
+		}
+	}
+	if sub == nil || sub.View == nil || sub.View.Sequence == nil || sub.View.Round == nil {
+		return nil
+	}
+	sequence := sub.View.Sequence.Uint64()
+	if sequence > d.maxSequence {
+		d.prune(sequence)
+	} else if sequence+1 < d.maxSequence {
+		return nil
+	}
+
+	key := signedKey{sequence: sequence, round: sub.View.Round.Uint64(), code: msg.Code, address: msg.Address}
+	first, ok := d.seen[key]
+	if !ok {
+		d.seen[key] = msg
+		return nil
+	}
+	firstSub := first.Prepare()
+	if msg.Code == istanbul.MsgCommit {
+		firstSub = first.Commit().Subject
+	}
+	if firstSub.Digest == sub.Digest {
+		return nil
+	}
+	return &istanbul.Evidence{DoubleSign: &istanbul.DoubleSignEvidence{First: first, Second: msg}}
+}
+
+// prune forgets the messages of the sequences before the previous one.
+func (d *doubleSignDetector) prune(sequence uint64) {
+	d.maxSequence = sequence
+	for key := range d.seen {
+		if key.sequence+1 < sequence {
+			delete(d.seen, key)
+		}
+	}
+}
+
+// detectDoubleSign hands evidence of a validator signing conflicting messages to the backend.
+func (c *core) detectDoubleSign(msg *istanbul.Message) {
+	if ev := c.doubleSigns.check(msg); ev != nil {
+		c.newLogger("func", "detectDoubleSign").Warn("Validator signed conflicting messages", "address", msg.Address, "code", msg.Code)
+		c.backend.ReportEvidence(ev)
+	}
+}
@@ -198,8 +198,10 @@ func (c *core) handleCheckedMsg(msg *istanbul.Message, src istanbul.Validator) e
 	case istanbul.MsgPreprepare:
 		return catchFutureMessages(c.handlePreprepare(msg))
 	case istanbul.MsgPrepare:
+		c.detectDoubleSign(msg)
 		return catchFutureMessages(c.handlePrepare(msg))
 	case istanbul.MsgCommit:
+		c.detectDoubleSign(msg)
 		return catchFutureMessages(c.handleCommit(msg))
 	case istanbul.MsgRoundChange:
 		return catchFutureMessages(c.handleRoundChange(msg))
@@ -59,6 +59,7 @@ type testSystemBackend struct {
 
 	committedMsgs []testCommittedMsgs
 	sentMsgs      [][]byte // store the message when Send is called by core
+	evidence      []*istanbul.Evidence
 
 	key     ecdsa.PrivateKey
 	blsKey  []byte
@@ -166,6 +167,10 @@ func (self *testSystemBackend) SignBLS(data []byte, extra []byte, useComposite,
 	if err != nil {
 		return bls.SerializedSignature{}, err
 	}
+	data, err = bls.PrepareMessage(data, extra, useComposite)
+	if err != nil {
+		return bls.SerializedSignature{}, err
+	}
 	var signature *bls.Signature
 	if params.IsBN256Fork(fork, cur) {
 		signature, err = bls.Sign2(privateKey, pubkey, data)
@@ -277,6 +282,10 @@ func (self *testSystemBackend) ParentBlockValidators(proposal istanbul.Proposal)
 
 func (self *testSystemBackend) UpdateReplicaState(seq *big.Int) { /* pass */ }
 
+func (self *testSystemBackend) ReportEvidence(ev *istanbul.Evidence) {
+	self.evidence = append(self.evidence, ev)
+}
+
 func (self *testSystemBackend) finalizeAndReturnMessage(msg *istanbul.Message) (istanbul.Message, error) {
 	message := new(istanbul.Message)
 	data, err := self.engine.(*core).finalizeMessage(msg)
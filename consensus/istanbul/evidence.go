@@ -0,0 +1,267 @@
+// Copyright 2021 MAP Protocol Authors.
+// This file is part of MAP Protocol.
+
+// MAP Protocol is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// MAP Protocol is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with MAP Protocol.  If not, see <http://www.gnu.org/licenses/>.
+
+package istanbul
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rlp"
+
+	"github.com/mapprotocol/atlas/core/types"
+	blscrypto "github.com/mapprotocol/atlas/helper/bls"
+)
+
+var (
+	// ErrInvalidEvidence is returned if the evidence is malformed or does not prove
+	// a double signing.
+	ErrInvalidEvidence = errors.New("invalid double signing evidence")
+	// ErrNoConflict is returned if the signed messages or headers of the evidence
+	// do not conflict with each other.
+	ErrNoConflict = errors.New("evidence does not conflict")
+	// ErrStaleEvidence is returned if the evidence is about a block older than the
+	// previous epoch.
+	ErrStaleEvidence = errors.New("stale double signing evidence")
+	// ErrFutureEvidence is returned if the evidence is about a block that is not
+	// before the one it is included in.
+	ErrFutureEvidence = errors.New("evidence is about a block not yet finalized")
+)
+
+// Offence is a double signing proven by evidence: the validator signed conflicting
+// messages or seals for the block at Number.
+type Offence struct {
+	Signer common.Address
+	Number uint64
+}
+
+// DoubleSignEvidence is two PREPARE or two COMMIT messages signed by the same validator
+// for different proposals in the same view.
+type DoubleSignEvidence struct {
+	First  *Message
+	Second *Message
+}
+
+// ConflictingSealEvidence is two different headers of the same height sealed in the
+// same round. The validators that took part in both aggregated seals committed two
+// proposals in one view.
+type ConflictingSealEvidence struct {
+	First  *types.Header
+	Second *types.Header
+}
+
+// Evidence is the compact proof of a double signing, it is gossiped between the
+// validators and submitted to the evidence precompile. Exactly one field is set.
+type Evidence struct {
+	DoubleSign      *DoubleSignEvidence      `rlp:"nil"`
+	ConflictingSeal *ConflictingSealEvidence `rlp:"nil"`
+}
+
+// DecodeEvidence decodes rlp encoded evidence.
+func DecodeEvidence(data []byte) (*Evidence, error) {
+	ev := new(Evidence)
+	if err := rlp.DecodeBytes(data, ev); err != nil {
+		return nil, err
+	}
+	if (ev.DoubleSign == nil) == (ev.ConflictingSeal == nil) {
+		return nil, ErrInvalidEvidence
+	}
+	return ev, nil
+}
+
+// Hash returns the hash of the evidence, used to tell gossiped evidence apart.
+func (ev *Evidence) Hash() common.Hash {
+	return RLPHash(ev)
+}
+
+// Number returns the block the evidence is about.
+func (ev *Evidence) Number() uint64 {
+	switch {
+	case ev.DoubleSign != nil && ev.DoubleSign.First != nil:
+		if sub, err := signedSubject(ev.DoubleSign.First); err == nil {
+			return sub.View.Sequence.Uint64()
+		}
+	case ev.ConflictingSeal != nil && ev.ConflictingSeal.First != nil:
+		return ev.ConflictingSeal.First.Number.Uint64()
+	}
+	return 0
+}
+
+// CheckEvidenceAge checks that evidence about the block at number can be included in the
+// block at current. Evidence is accepted up to the end of the epoch after the one it is
+// about, later the offenders may have left the validator set and withdrawn.
+func CheckEvidenceAge(number, current, epochSize uint64) error {
+	if number == 0 || number >= current {
+		return ErrFutureEvidence
+	}
+	if GetEpochNumber(number, epochSize)+1 < GetEpochNumber(current, epochSize) {
+		return ErrStaleEvidence
+	}
+	return nil
+}
+
+// Verify checks the evidence against the validators of the block it is about and
+// returns the offences it proves. The BN256 fork block selects the BLS scheme the
+// seals are verified with.
+func (ev *Evidence) Verify(validators []Validator, fork *big.Int) ([]Offence, error) {
+	switch {
+	case ev.DoubleSign != nil:
+		offence, err := ev.DoubleSign.verify(validators)
+		if err != nil {
+			return nil, err
+		}
+		return []Offence{offence}, nil
+	case ev.ConflictingSeal != nil:
+		return ev.ConflictingSeal.verify(validators, fork)
+	}
+	return nil, ErrInvalidEvidence
+}
+
+// signedSubject returns the subject of a PREPARE or COMMIT message.
+func signedSubject(msg *Message) (*Subject, error) {
+	var sub *Subject
+	switch msg.Code {
+	case MsgPrepare:
+		sub = msg.Prepare()
+	case MsgCommit:
+		if commit := msg.Commit(); commit != nil {
+			sub = commit.Subject
+		}
+	}
+	if sub == nil || sub.View == nil || sub.View.Round == nil || sub.View.Sequence == nil {
+		return nil, ErrInvalidEvidence
+	}
+	return sub, nil
+}
+
+// checkSignature checks that the message is signed by its sender.
+func checkSignature(msg *Message) error {
+	payload, err := msg.PayloadNoSig()
+	if err != nil {
+		return err
+	}
+	signer, err := GetSignatureAddress(payload, msg.Signature)
+	if err != nil {
+		return err
+	}
+	if signer != msg.Address {
+		return ErrInvalidSigner
+	}
+	return nil
+}
+
+func (e *DoubleSignEvidence) verify(validators []Validator) (Offence, error) {
+	if e.First == nil || e.Second == nil || e.First.Address != e.Second.Address || e.First.Code != e.Second.Code {
+		return Offence{}, ErrInvalidEvidence
+	}
+	first, err := signedSubject(e.First)
+	if err != nil {
+		return Offence{}, err
+	}
+	second, err := signedSubject(e.Second)
+	if err != nil {
+		return Offence{}, err
+	}
+	if first.View.Cmp(second.View) != 0 || first.Digest == second.Digest {
+		return Offence{}, ErrNoConflict
+	}
+	if err := checkSignature(e.First); err != nil {
+		return Offence{}, err
+	}
+	if err := checkSignature(e.Second); err != nil {
+		return Offence{}, err
+	}
+	for _, val := range validators {
+		if val.Address() == e.First.Address {
+			return Offence{Signer: e.First.Address, Number: first.View.Sequence.Uint64()}, nil
+		}
+	}
+	return Offence{}, ErrUnauthorizedAddress
+}
+
+// committedSeal returns the message signed by a committed seal, as built by
+// core.PrepareCommittedSeal.
+func committedSeal(hash common.Hash, round *big.Int) []byte {
+	var buf bytes.Buffer
+	buf.Write(hash.Bytes())
+	buf.Write(round.Bytes())
+	buf.Write([]byte{byte(MsgCommit)})
+	return buf.Bytes()
+}
+
+// verifySeal checks the aggregated seal of the header and returns its bitmap.
+func verifySeal(header *types.Header, validators []Validator, fork *big.Int) (*big.Int, *big.Int, error) {
+	extra, err := types.ExtractIstanbulExtra(header)
+	if err != nil {
+		return nil, nil, err
+	}
+	seal := extra.AggregatedSeal
+	if seal.Bitmap == nil || seal.Round == nil || len(seal.Signature) != types.IstanbulExtraBlsSignature {
+		return nil, nil, ErrInvalidEvidence
+	}
+	if seal.Bitmap.BitLen() > len(validators) {
+		return nil, nil, ErrInvalidEvidence
+	}
+	var publicKeys []blscrypto.SerializedPublicKey
+	for i, val := range validators {
+		if seal.Bitmap.Bit(i) == 1 {
+			publicKeys = append(publicKeys, val.BLSPublicKey())
+		}
+	}
+	if len(publicKeys) == 0 {
+		return nil, nil, ErrInvalidEvidence
+	}
+	err = blscrypto.CryptoType().VerifyAggregatedSignature(publicKeys, committedSeal(header.Hash(), seal.Round), []byte{},
+		seal.Signature, false, false, fork, header.Number)
+	if err != nil {
+		return nil, nil, err
+	}
+	return seal.Bitmap, seal.Round, nil
+}
+
+func (e *ConflictingSealEvidence) verify(validators []Validator, fork *big.Int) ([]Offence, error) {
+	if e.First == nil || e.Second == nil || e.First.Number == nil || e.Second.Number == nil {
+		return nil, ErrInvalidEvidence
+	}
+	if e.First.Number.Cmp(e.Second.Number) != 0 || e.First.Hash() == e.Second.Hash() {
+		return nil, ErrNoConflict
+	}
+	firstBitmap, firstRound, err := verifySeal(e.First, validators, fork)
+	if err != nil {
+		return nil, err
+	}
+	secondBitmap, secondRound, err := verifySeal(e.Second, validators, fork)
+	if err != nil {
+		return nil, err
+	}
+	// Committing different proposals in different rounds can be legitimate
+	if firstRound.Cmp(secondRound) != 0 {
+		return nil, ErrNoConflict
+	}
+	both := new(big.Int).And(firstBitmap, secondBitmap)
+	var offences []Offence
+	for i, val := range validators {
+		if both.Bit(i) == 1 {
+			offences = append(offences, Offence{Signer: val.Address(), Number: e.First.Number.Uint64()})
+		}
+	}
+	if len(offences) == 0 {
+		return nil, ErrNoConflict
+	}
+	return offences, nil
+}
@@ -0,0 +1,117 @@
+package istanbul
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// addressValidator is a validator that only knows its address.
+type addressValidator struct {
+	Validator
+	address common.Address
+}
+
+func (v addressValidator) Address() common.Address { return v.address }
+
+func signedPrepare(t *testing.T, key *ecdsa.PrivateKey, view *View, digest common.Hash) *Message {
+	msg := NewPrepareMessage(&Subject{View: view, Digest: digest}, crypto.PubkeyToAddress(key.PublicKey))
+	err := msg.Sign(func(data []byte) ([]byte, error) {
+		return crypto.Sign(crypto.Keccak256(data), key)
+	})
+	if err != nil {
+		t.Fatalf("failed to sign message: %v", err)
+	}
+	return msg
+}
+
+func TestDoubleSignEvidence(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	signer := crypto.PubkeyToAddress(key.PublicKey)
+	validators := []Validator{addressValidator{address: common.HexToAddress("0x01")}, addressValidator{address: signer}}
+	view := &View{Round: big.NewInt(1), Sequence: big.NewInt(42)}
+
+	first := signedPrepare(t, key, view, common.HexToHash("0x01"))
+	second := signedPrepare(t, key, view, common.HexToHash("0x02"))
+
+	// The evidence goes over the wire rlp encoded
+	data, err := rlp.EncodeToBytes(&Evidence{DoubleSign: &DoubleSignEvidence{First: first, Second: second}})
+	if err != nil {
+		t.Fatalf("failed to encode evidence: %v", err)
+	}
+	ev, err := DecodeEvidence(data)
+	if err != nil {
+		t.Fatalf("failed to decode evidence: %v", err)
+	}
+	if ev.Number() != 42 {
+		t.Errorf("evidence number mismatch: have %d, want 42", ev.Number())
+	}
+	offences, err := ev.Verify(validators, nil)
+	if err != nil {
+		t.Fatalf("failed to verify evidence: %v", err)
+	}
+	if len(offences) != 1 || offences[0] != (Offence{Signer: signer, Number: 42}) {
+		t.Errorf("offences mismatch: have %v", offences)
+	}
+
+	tests := []struct {
+		name       string
+		evidence   *DoubleSignEvidence
+		validators []Validator
+		err        error
+	}{
+		{"same digest", &DoubleSignEvidence{First: first, Second: signedPrepare(t, key, view, common.HexToHash("0x01"))}, validators, ErrNoConflict},
+		{"different view", &DoubleSignEvidence{First: first, Second: signedPrepare(t, key, &View{Round: big.NewInt(2), Sequence: big.NewInt(42)}, common.HexToHash("0x02"))}, validators, ErrNoConflict},
+		{"not a validator", &DoubleSignEvidence{First: first, Second: second}, validators[:1], ErrUnauthorizedAddress},
+		{"missing message", &DoubleSignEvidence{First: first}, validators, ErrInvalidEvidence},
+	}
+	for _, test := range tests {
+		if _, err := (&Evidence{DoubleSign: test.evidence}).Verify(test.validators, nil); err != test.err {
+			t.Errorf("%s: error mismatch: have %v, want %v", test.name, err, test.err)
+		}
+	}
+
+	// Messages claiming to be from the validator must be signed by it
+	other, _ := crypto.GenerateKey()
+	forged := signedPrepare(t, other, view, common.HexToHash("0x02"))
+	forged.Address = signer
+	if _, err := (&Evidence{DoubleSign: &DoubleSignEvidence{First: first, Second: forged}}).Verify(validators, nil); err != ErrInvalidSigner {
+		t.Errorf("forged signature: error mismatch: have %v, want %v", err, ErrInvalidSigner)
+	}
+}
+
+func TestDecodeEvidence(t *testing.T) {
+	data, _ := rlp.EncodeToBytes(&Evidence{})
+	if _, err := DecodeEvidence(data); err != ErrInvalidEvidence {
+		t.Errorf("empty evidence: error mismatch: have %v, want %v", err, ErrInvalidEvidence)
+	}
+	if _, err := DecodeEvidence([]byte{0x01, 0x02}); err == nil {
+		t.Error("expected an error decoding malformed evidence")
+	}
+}
+
+func TestCheckEvidenceAge(t *testing.T) {
+	tests := []struct {
+		number, current uint64
+		err             error
+	}{
+		{0, 10, ErrFutureEvidence},
+		{10, 10, ErrFutureEvidence},
+		{11, 10, ErrFutureEvidence},
+		{9, 10, nil},
+		// Evidence is accepted until the end of the next epoch
+		{5, 200, nil},
+		{100, 101, nil},
+		{100, 201, ErrStaleEvidence},
+		{99, 201, ErrStaleEvidence},
+	}
+	for _, test := range tests {
+		if err := CheckEvidenceAge(test.number, test.current, 100); err != test.err {
+			t.Errorf("number %d, current %d: error mismatch: have %v, want %v", test.number, test.current, err, test.err)
+		}
+	}
+}
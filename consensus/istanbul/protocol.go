@@ -32,13 +32,14 @@ const (
 	VersionCertificatesMsg = 0x16
 	EnodeCertificateMsg    = 0x17
 	ValidatorHandshakeMsg  = 0x18
+	EvidenceMsg            = 0x19
 )
 
 func IsIstanbulMsg(msg p2p.Msg) bool {
-	return msg.Code >= ConsensusMsg && msg.Code <= ValidatorHandshakeMsg
+	return msg.Code >= ConsensusMsg && msg.Code <= EvidenceMsg
 }
 
 // IsGossipedMsg specifies which messages should be gossiped throughout the network (as opposed to directly sent to a peer).
 func IsGossipedMsg(msgCode uint64) bool {
-	return msgCode == QueryEnodeMsg || msgCode == VersionCertificatesMsg
+	return msgCode == QueryEnodeMsg || msgCode == VersionCertificatesMsg || msgCode == EvidenceMsg
 }
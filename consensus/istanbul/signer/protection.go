@@ -0,0 +1,127 @@
+// Copyright 2021 MAP Protocol Authors.
+// This file is part of MAP Protocol.
+
+// MAP Protocol is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// MAP Protocol is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with MAP Protocol.  If not, see <http://www.gnu.org/licenses/>.
+
+package signer
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/rlp"
+
+	"github.com/mapprotocol/atlas/consensus/istanbul"
+)
+
+// ErrConflictingView is returned when asked to sign a message conflicting with the
+// one signed before for the same view.
+var ErrConflictingView = errors.New("refusing to sign a conflicting message for an already signed view")
+
+// Kinds of signed messages a view is recorded for
+const (
+	kindPreprepare byte = iota
+	kindPrepare
+	kindCommit
+	kindCommittedSeal
+)
+
+// Protection records the digest signed for each view, so that a validator never signs
+// two conflicting preprepare, prepare or commit messages, or committed seals, for the
+// same view. The other messages, block seals and announce messages included, carry no
+// view and are signed as is.
+type Protection struct {
+	db ethdb.KeyValueStore
+	mu sync.Mutex
+}
+
+// NewProtection returns a slashing protection keeping its records in the database.
+func NewProtection(db ethdb.KeyValueStore) *Protection {
+	return &Protection{db: db}
+}
+
+// protectionKey = "protection" + kind + sequence (hash) + round (hash)
+func protectionKey(kind byte, view *istanbul.View) []byte {
+	key := append([]byte("protection"), kind)
+	key = append(key, common.BigToHash(view.Sequence).Bytes()...)
+	return append(key, common.BigToHash(view.Round).Bytes()...)
+}
+
+// check records the digest for the view of the kind, unless a different one is recorded.
+func (p *Protection) check(kind byte, view *istanbul.View, digest common.Hash) error {
+	if view == nil || view.Sequence == nil || view.Round == nil {
+		return errors.New("message without view")
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	key := protectionKey(kind, view)
+	if signed, _ := p.db.Get(key); len(signed) != 0 {
+		if !bytes.Equal(signed, digest.Bytes()) {
+			return ErrConflictingView
+		}
+		return nil
+	}
+	return p.db.Put(key, digest.Bytes())
+}
+
+// CheckData checks the payload of an ECDSA signature. Payloads which are not consensus
+// messages are not protected.
+func (p *Protection) CheckData(data []byte) error {
+	msg := new(istanbul.Message)
+	if err := rlp.DecodeBytes(data, msg); err != nil {
+		return nil
+	}
+	switch msg.Code {
+	case istanbul.MsgPreprepare:
+		preprepare := msg.Preprepare()
+		if preprepare == nil || preprepare.Proposal == nil {
+			return errors.New("invalid preprepare message")
+		}
+		return p.check(kindPreprepare, preprepare.View, preprepare.Proposal.Hash())
+	case istanbul.MsgPrepare:
+		prepare := msg.Prepare()
+		if prepare == nil {
+			return errors.New("invalid prepare message")
+		}
+		return p.check(kindPrepare, prepare.View, prepare.Digest)
+	case istanbul.MsgCommit:
+		commit := msg.Commit()
+		if commit == nil || commit.Subject == nil {
+			return errors.New("invalid commit message")
+		}
+		return p.check(kindCommit, commit.Subject.View, commit.Subject.Digest)
+	}
+	return nil
+}
+
+// CheckBLS checks the message of a BLS signature. Committed seals, the block hash followed
+// by the round and the commit code signed for the block number cur, are protected.
+func (p *Protection) CheckBLS(msg, extraData []byte, useComposite bool, cur *big.Int) error {
+	if useComposite || len(extraData) != 0 || cur == nil {
+		return nil
+	}
+	if len(msg) < common.HashLength+1 || len(msg) > 2*common.HashLength+1 || msg[len(msg)-1] != byte(istanbul.MsgCommit) {
+		return nil
+	}
+	view := &istanbul.View{
+		Sequence: cur,
+		Round:    new(big.Int).SetBytes(msg[common.HashLength : len(msg)-1]),
+	}
+	return p.check(kindCommittedSeal, view, common.BytesToHash(msg[:common.HashLength]))
+}
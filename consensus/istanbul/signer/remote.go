@@ -0,0 +1,138 @@
+// Copyright 2021 MAP Protocol Authors.
+// This file is part of MAP Protocol.
+
+// MAP Protocol is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// MAP Protocol is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with MAP Protocol.  If not, see <http://www.gnu.org/licenses/>.
+
+// Package signer implements an istanbul signer keeping the validator keys on a remote
+// host, and the signing service it talks to over HTTP/JSON-RPC. The service refuses
+// to sign two conflicting consensus messages for the same view, or anything validators
+// never sign, and only answers the clients holding its token.
+package signer
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/mapprotocol/atlas/accounts"
+	"github.com/mapprotocol/atlas/consensus/istanbul"
+	"github.com/mapprotocol/atlas/core/types"
+	blscrypto "github.com/mapprotocol/atlas/helper/bls"
+)
+
+// remoteTimeout bounds the time a signature request may take.
+const remoteTimeout = 5 * time.Second
+
+// Remote signs with the keys held by a signer service.
+type Remote struct {
+	client *rpc.Client
+}
+
+var _ istanbul.Signer = (*Remote)(nil)
+
+// DialRemote connects to the signer service at the url, authenticating with the token.
+func DialRemote(url, token string) (*Remote, error) {
+	client, err := rpc.DialHTTP(url)
+	if err != nil {
+		return nil, err
+	}
+	client.SetHeader("Authorization", "Bearer "+token)
+	return &Remote{client: client}, nil
+}
+
+// Close closes the connection to the signer service.
+func (r *Remote) Close() {
+	r.client.Close()
+}
+
+func (r *Remote) call(result interface{}, method string, args ...interface{}) error {
+	ctx, cancel := context.WithTimeout(context.Background(), remoteTimeout)
+	defer cancel()
+	return r.client.CallContext(ctx, result, "signer_"+method, args...)
+}
+
+// GetPublicKey implements istanbul.Signer.GetPublicKey
+func (r *Remote) GetPublicKey(account accounts.Account) (*ecdsa.PublicKey, error) {
+	var pub hexutil.Bytes
+	if err := r.call(&pub, "publicKey", account.Address); err != nil {
+		return nil, err
+	}
+	return crypto.UnmarshalPubkey(pub)
+}
+
+// SignData implements istanbul.Signer.SignData
+func (r *Remote) SignData(account accounts.Account, mimeType string, data []byte) ([]byte, error) {
+	var sig hexutil.Bytes
+	if err := r.call(&sig, "signData", account.Address, mimeType, hexutil.Bytes(data)); err != nil {
+		return nil, err
+	}
+	return sig, nil
+}
+
+// SignHash implements istanbul.Signer.SignHash
+func (r *Remote) SignHash(account accounts.Account, hash []byte) ([]byte, error) {
+	var sig hexutil.Bytes
+	if err := r.call(&sig, "signHash", account.Address, hexutil.Bytes(hash)); err != nil {
+		return nil, err
+	}
+	return sig, nil
+}
+
+// SignTx implements istanbul.Signer.SignTx
+func (r *Remote) SignTx(account accounts.Account, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	data, err := tx.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	var signed hexutil.Bytes
+	if err := r.call(&signed, "signTx", account.Address, hexutil.Bytes(data), (*hexutil.Big)(chainID)); err != nil {
+		return nil, err
+	}
+	tx = new(types.Transaction)
+	if err := tx.UnmarshalBinary(signed); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+// SignBLS implements istanbul.Signer.SignBLS
+func (r *Remote) SignBLS(account accounts.Account, msg []byte, extraData []byte, useComposite, cip22 bool, fork, cur *big.Int) (blscrypto.SerializedSignature, error) {
+	var sig hexutil.Bytes
+	args := BLSArgs{
+		Msg:          msg,
+		ExtraData:    extraData,
+		UseComposite: useComposite,
+		Cip22:        cip22,
+		Fork:         (*hexutil.Big)(fork),
+		Cur:          (*hexutil.Big)(cur),
+	}
+	if err := r.call(&sig, "signBLS", account.Address, args); err != nil {
+		return blscrypto.SerializedSignature{}, err
+	}
+	return blscrypto.SerializedSignatureFromBytes(sig)
+}
+
+// Decrypt implements istanbul.Signer.Decrypt
+func (r *Remote) Decrypt(account accounts.Account, c, s1, s2 []byte) ([]byte, error) {
+	var plain hexutil.Bytes
+	if err := r.call(&plain, "decrypt", account.Address, hexutil.Bytes(c), hexutil.Bytes(s1), hexutil.Bytes(s2)); err != nil {
+		return nil, err
+	}
+	return plain, nil
+}
@@ -0,0 +1,253 @@
+// Copyright 2021 MAP Protocol Authors.
+// This file is part of MAP Protocol.
+
+// MAP Protocol is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// MAP Protocol is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with MAP Protocol.  If not, see <http://www.gnu.org/licenses/>.
+
+package signer
+
+import (
+	"crypto/ecdsa"
+	"crypto/subtle"
+	"errors"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/ecies"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/mapprotocol/atlas/consensus/istanbul"
+	"github.com/mapprotocol/atlas/core/types"
+	blscrypto "github.com/mapprotocol/atlas/helper/bls"
+	"github.com/mapprotocol/atlas/params"
+)
+
+var (
+	errUnknownAccount    = errors.New("unknown account")
+	errMissingNumber     = errors.New("BLS request without fork or block number")
+	errMissingToken      = errors.New("empty signer token")
+	errUnexpectedPayload = errors.New("refusing to sign a payload validators do not sign")
+)
+
+// BLSArgs is the message of a BLS signature request.
+type BLSArgs struct {
+	Msg          hexutil.Bytes `json:"msg"`
+	ExtraData    hexutil.Bytes `json:"extraData"`
+	UseComposite bool          `json:"useComposite"`
+	Cip22        bool          `json:"cip22"`
+	Fork         *hexutil.Big  `json:"fork"`
+	Cur          *hexutil.Big  `json:"cur"`
+}
+
+// Service holds the keys of validators and signs for them, refusing to sign what the
+// slashing protection does not allow and anything a validator never signs, such as
+// arbitrary hashes or transactions. It is served as the "signer" JSON-RPC namespace.
+type Service struct {
+	keys       map[common.Address]*ecdsa.PrivateKey
+	protection *Protection
+}
+
+// NewService returns a service signing with the keys. The BLS keys are derived from the
+// ECDSA ones, as the keystore does.
+func NewService(protection *Protection, keys ...*ecdsa.PrivateKey) *Service {
+	s := &Service{
+		keys:       make(map[common.Address]*ecdsa.PrivateKey, len(keys)),
+		protection: protection,
+	}
+	for _, key := range keys {
+		s.keys[crypto.PubkeyToAddress(key.PublicKey)] = key
+	}
+	return s
+}
+
+// Server serves the JSON-RPC API of a service over HTTP to the clients presenting its
+// token as a bearer token.
+type Server struct {
+	rpc   *rpc.Server
+	token []byte
+}
+
+// NewServer returns a server of the service only answering the holders of the token.
+func NewServer(service *Service, token string) (*Server, error) {
+	if token == "" {
+		return nil, errMissingToken
+	}
+	server := rpc.NewServer()
+	if err := server.RegisterName("signer", service); err != nil {
+		return nil, err
+	}
+	return &Server{rpc: server, token: []byte("Bearer " + token)}, nil
+}
+
+// ServeHTTP implements http.Handler, refusing the requests without the token.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), s.token) != 1 {
+		http.Error(w, "invalid signer token", http.StatusUnauthorized)
+		return
+	}
+	s.rpc.ServeHTTP(w, r)
+}
+
+// Stop stops the JSON-RPC server, pending requests are cancelled.
+func (s *Server) Stop() {
+	s.rpc.Stop()
+}
+
+// ReadToken reads the token shared by the signer service and its clients from the file.
+func ReadToken(path string) (string, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	token := strings.TrimSpace(string(data))
+	if token == "" {
+		return "", errMissingToken
+	}
+	return token, nil
+}
+
+// isValidatorPayload reports whether the data is signed by validators with SignData: an
+// istanbul message, enode certificates and announces included, a version certificate or
+// the seal hash of a header.
+func isValidatorPayload(data []byte) bool {
+	if len(data) == common.HashLength || istanbul.IsVersionCertificatePayload(data) {
+		return true
+	}
+	return rlp.DecodeBytes(data, new(istanbul.Message)) == nil
+}
+
+func (s *Service) key(address common.Address) (*ecdsa.PrivateKey, error) {
+	key, ok := s.keys[address]
+	if !ok {
+		return nil, errUnknownAccount
+	}
+	return key, nil
+}
+
+// PublicKey returns the uncompressed ECDSA public key of the account.
+func (s *Service) PublicKey(address common.Address) (hexutil.Bytes, error) {
+	key, err := s.key(address)
+	if err != nil {
+		return nil, err
+	}
+	return crypto.FromECDSAPub(&key.PublicKey), nil
+}
+
+// SignData signs keccak256(data) with the account.
+func (s *Service) SignData(address common.Address, mimeType string, data hexutil.Bytes) (hexutil.Bytes, error) {
+	key, err := s.key(address)
+	if err != nil {
+		return nil, err
+	}
+	if !isValidatorPayload(data) {
+		log.Warn("Refused to sign data", "address", address, "mimeType", mimeType, "err", errUnexpectedPayload)
+		return nil, errUnexpectedPayload
+	}
+	if err := s.protection.CheckData(data); err != nil {
+		log.Warn("Refused to sign data", "address", address, "mimeType", mimeType, "err", err)
+		return nil, err
+	}
+	return crypto.Sign(crypto.Keccak256(data), key)
+}
+
+// SignHash signs the hash with the account, the only hash validators sign is the one of
+// their randomness seed.
+func (s *Service) SignHash(address common.Address, hash hexutil.Bytes) (hexutil.Bytes, error) {
+	key, err := s.key(address)
+	if err != nil {
+		return nil, err
+	}
+	if len(hash) != common.HashLength || common.BytesToHash(hash) != istanbul.RandomSeedHash {
+		log.Warn("Refused to sign hash", "address", address, "hash", hash)
+		return nil, errUnexpectedPayload
+	}
+	return crypto.Sign(hash, key)
+}
+
+// SignTx signs the binary encoded transaction for the chain with the account and returns
+// it encoded with the signature. Validators only sign the transactions submitting double
+// signing evidence.
+func (s *Service) SignTx(address common.Address, data hexutil.Bytes, chainID *hexutil.Big) (hexutil.Bytes, error) {
+	key, err := s.key(address)
+	if err != nil {
+		return nil, err
+	}
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(data); err != nil {
+		return nil, err
+	}
+	if tx.To() == nil || *tx.To() != params.EvidenceAddress || tx.Value().Sign() != 0 {
+		log.Warn("Refused to sign transaction", "address", address, "to", tx.To(), "value", tx.Value())
+		return nil, errUnexpectedPayload
+	}
+	tx, err = types.SignTx(tx, types.LatestSignerForChainID((*big.Int)(chainID)), key)
+	if err != nil {
+		return nil, err
+	}
+	return tx.MarshalBinary()
+}
+
+// SignBLS signs the message with the BLS key of the account.
+func (s *Service) SignBLS(address common.Address, args BLSArgs) (hexutil.Bytes, error) {
+	key, err := s.key(address)
+	if err != nil {
+		return nil, err
+	}
+	if args.Fork == nil || args.Cur == nil {
+		return nil, errMissingNumber
+	}
+	fork, cur := args.Fork.ToInt(), args.Cur.ToInt()
+	if err := s.protection.CheckBLS(args.Msg, args.ExtraData, args.UseComposite, cur); err != nil {
+		log.Warn("Refused to sign BLS message", "address", address, "number", cur, "err", err)
+		return nil, err
+	}
+
+	privateKeyBytes, err := blscrypto.CryptoType().ECDSAToBLS(key)
+	if err != nil {
+		return nil, err
+	}
+	blsKey, err := blscrypto.DeserializePrivateKey(privateKeyBytes)
+	if err != nil {
+		return nil, err
+	}
+	msg, err := blscrypto.PrepareMessage(args.Msg, args.ExtraData, args.UseComposite)
+	if err != nil {
+		return nil, err
+	}
+	var sign *blscrypto.UnsafeSignature
+	if params.IsBN256Fork(fork, cur) {
+		sign, err = blscrypto.UnsafeSign2(blsKey, msg)
+	} else {
+		sign, err = blscrypto.UnsafeSign(blsKey, msg)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return sign.Marshal(), nil
+}
+
+// Decrypt decrypts the ECIES ciphertext with the account.
+func (s *Service) Decrypt(address common.Address, c, s1, s2 hexutil.Bytes) (hexutil.Bytes, error) {
+	key, err := s.key(address)
+	if err != nil {
+		return nil, err
+	}
+	return ecies.ImportECDSA(key).Decrypt(c, s1, s2)
+}
@@ -0,0 +1,205 @@
+package signer
+
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"math/big"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/ecies"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mapprotocol/atlas/accounts"
+	"github.com/mapprotocol/atlas/consensus/istanbul"
+	"github.com/mapprotocol/atlas/consensus/istanbul/core"
+	"github.com/mapprotocol/atlas/core/rawdb"
+	"github.com/mapprotocol/atlas/core/types"
+	blscrypto "github.com/mapprotocol/atlas/helper/bls"
+	"github.com/mapprotocol/atlas/params"
+)
+
+const testToken = "secret"
+
+func newTestRemote(t *testing.T) (*Remote, *ecdsa.PrivateKey, func()) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	server, err := NewServer(NewService(NewProtection(rawdb.NewMemoryDatabase()), key), testToken)
+	require.NoError(t, err)
+	httpServer := httptest.NewServer(server)
+	remote, err := DialRemote(httpServer.URL, testToken)
+	require.NoError(t, err)
+	return remote, key, func() {
+		remote.Close()
+		httpServer.Close()
+		server.Stop()
+	}
+}
+
+func view(sequence, round int64) *istanbul.View {
+	return &istanbul.View{Sequence: big.NewInt(sequence), Round: big.NewInt(round)}
+}
+
+func TestRemoteSigner(t *testing.T) {
+	remote, key, stop := newTestRemote(t)
+	defer stop()
+	account := accounts.Account{Address: crypto.PubkeyToAddress(key.PublicKey)}
+
+	pub, err := remote.GetPublicKey(account)
+	assert.NoError(t, err)
+	assert.Equal(t, key.PublicKey, *pub)
+	_, err = remote.GetPublicKey(accounts.Account{Address: common.Address{0x1}})
+	assert.Error(t, err)
+
+	data := crypto.Keccak256([]byte("header"))
+	sig, err := remote.SignData(account, "application/x-istanbul-msg", data)
+	assert.NoError(t, err)
+	signer, err := istanbul.GetSignatureAddress(data, sig)
+	assert.NoError(t, err)
+	assert.Equal(t, account.Address, signer)
+
+	hash := istanbul.RandomSeedHash.Bytes()
+	sig, err = remote.SignHash(account, hash)
+	assert.NoError(t, err)
+	recovered, err := crypto.SigToPub(hash, sig)
+	assert.NoError(t, err)
+	assert.Equal(t, account.Address, crypto.PubkeyToAddress(*recovered))
+
+	to, chainID := params.EvidenceAddress, big.NewInt(211)
+	tx, err := remote.SignTx(account, types.NewTx(&types.LegacyTx{To: &to, Value: new(big.Int), Gas: 21000, GasPrice: new(big.Int)}), chainID)
+	assert.NoError(t, err)
+	sender, err := types.Sender(types.LatestSignerForChainID(chainID), tx)
+	assert.NoError(t, err)
+	assert.Equal(t, account.Address, sender)
+
+	msg := core.PrepareCommittedSeal(common.Hash{0x1}, big.NewInt(0))
+	fork, cur := big.NewInt(0), big.NewInt(10)
+	blsSig, err := remote.SignBLS(account, msg, nil, false, false, fork, cur)
+	assert.NoError(t, err)
+	blsKey, err := blscrypto.CryptoType().ECDSAToBLS(key)
+	assert.NoError(t, err)
+	blsPub, err := blscrypto.CryptoType().PrivateToPublic(blsKey)
+	assert.NoError(t, err)
+	assert.NoError(t, blscrypto.CryptoType().VerifySignature(blsPub, msg, nil, blsSig[:], false, false, fork, cur))
+
+	plain := []byte("enode")
+	ciphertext, err := ecies.Encrypt(rand.Reader, ecies.ImportECDSAPublic(&key.PublicKey), plain, nil, nil)
+	assert.NoError(t, err)
+	decrypted, err := remote.Decrypt(account, ciphertext, nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, plain, decrypted)
+}
+
+func TestRemoteSignerRestrictions(t *testing.T) {
+	remote, key, stop := newTestRemote(t)
+	defer stop()
+	account := accounts.Account{Address: crypto.PubkeyToAddress(key.PublicKey)}
+
+	// Validators never sign arbitrary hashes, data or transactions
+	_, err := remote.SignHash(account, crypto.Keccak256([]byte("transaction")))
+	assert.Error(t, err)
+	to := common.Address{0x1}
+	transfer := types.NewTx(&types.LegacyTx{To: &to, Value: big.NewInt(1), Gas: 21000, GasPrice: new(big.Int)})
+	_, err = remote.SignTx(account, transfer, big.NewInt(211))
+	assert.Error(t, err)
+	evidence := types.NewTx(&types.LegacyTx{To: &params.EvidenceAddress, Value: big.NewInt(1), Gas: 21000, GasPrice: new(big.Int)})
+	_, err = remote.SignTx(account, evidence, big.NewInt(211))
+	assert.Error(t, err)
+	unsigned, err := rlp.EncodeToBytes([]interface{}{uint64(0), new(big.Int), uint64(21000), to, big.NewInt(1), []byte{}})
+	require.NoError(t, err)
+	_, err = remote.SignData(account, "application/x-istanbul-msg", unsigned)
+	assert.Error(t, err)
+}
+
+func TestRemoteSignerToken(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	service := NewService(NewProtection(rawdb.NewMemoryDatabase()), key)
+	_, err = NewServer(service, "")
+	assert.Error(t, err)
+
+	server, err := NewServer(service, testToken)
+	require.NoError(t, err)
+	defer server.Stop()
+	httpServer := httptest.NewServer(server)
+	defer httpServer.Close()
+
+	account := accounts.Account{Address: crypto.PubkeyToAddress(key.PublicKey)}
+	for _, token := range []string{"", "wrong"} {
+		remote, err := DialRemote(httpServer.URL, token)
+		require.NoError(t, err)
+		_, err = remote.GetPublicKey(account)
+		assert.Error(t, err)
+		remote.Close()
+	}
+}
+
+func TestSlashingProtection(t *testing.T) {
+	remote, key, stop := newTestRemote(t)
+	defer stop()
+	account := accounts.Account{Address: crypto.PubkeyToAddress(key.PublicKey)}
+
+	sign := func(msg *istanbul.Message) error {
+		payload, err := msg.PayloadNoSig()
+		require.NoError(t, err)
+		_, err = remote.SignData(account, "application/x-istanbul-msg", payload)
+		return err
+	}
+	prepare := func(v *istanbul.View, digest common.Hash) *istanbul.Message {
+		return istanbul.NewPrepareMessage(&istanbul.Subject{View: v, Digest: digest}, account.Address)
+	}
+	commit := func(v *istanbul.View, digest common.Hash) *istanbul.Message {
+		return istanbul.NewCommitMessage(&istanbul.CommittedSubject{Subject: &istanbul.Subject{View: v, Digest: digest}}, account.Address)
+	}
+	preprepare := func(v *istanbul.View, time uint64) *istanbul.Message {
+		block := types.NewBlockWithHeader(&types.Header{Number: v.Sequence, Time: time})
+		return istanbul.NewPreprepareMessage(&istanbul.Preprepare{View: v, Proposal: block}, account.Address)
+	}
+
+	// A message may be signed again, not a conflicting one for the same view
+	assert.NoError(t, sign(prepare(view(1, 0), common.Hash{0x1})))
+	assert.NoError(t, sign(prepare(view(1, 0), common.Hash{0x1})))
+	assert.Equal(t, ErrConflictingView.Error(), sign(prepare(view(1, 0), common.Hash{0x2})).Error())
+	assert.NoError(t, sign(prepare(view(1, 1), common.Hash{0x2})))
+	assert.NoError(t, sign(prepare(view(2, 0), common.Hash{0x2})))
+
+	assert.NoError(t, sign(commit(view(1, 0), common.Hash{0x1})))
+	assert.Error(t, sign(commit(view(1, 0), common.Hash{0x2})))
+
+	assert.NoError(t, sign(preprepare(view(3, 0), 1)))
+	assert.NoError(t, sign(preprepare(view(3, 0), 1)))
+	assert.Error(t, sign(preprepare(view(3, 0), 2)))
+	assert.NoError(t, sign(preprepare(view(3, 1), 2)))
+
+	// Committed seals are protected by the block number they are signed for
+	fork := big.NewInt(0)
+	signSeal := func(number int64, hash common.Hash, round int64) error {
+		msg := core.PrepareCommittedSeal(hash, big.NewInt(round))
+		_, err := remote.SignBLS(account, msg, nil, false, false, fork, big.NewInt(number))
+		return err
+	}
+	assert.NoError(t, signSeal(5, common.Hash{0x1}, 0))
+	assert.NoError(t, signSeal(5, common.Hash{0x1}, 0))
+	assert.Error(t, signSeal(5, common.Hash{0x2}, 0))
+	assert.NoError(t, signSeal(5, common.Hash{0x2}, 1))
+	assert.NoError(t, signSeal(6, common.Hash{0x2}, 0))
+
+	// Messages without a view are not protected
+	cert, err := istanbul.NewVersionCertificate(1, func(data []byte) ([]byte, error) {
+		return remote.SignData(account, "application/x-istanbul-msg", data)
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, account.Address, cert.Address())
+	_, err = istanbul.NewVersionCertificate(2, func(data []byte) ([]byte, error) {
+		return remote.SignData(account, "application/x-istanbul-msg", data)
+	})
+	assert.NoError(t, err)
+	_, err = remote.SignBLS(account, []byte("epoch"), []byte("extra"), true, true, fork, big.NewInt(5))
+	assert.NoError(t, err)
+	_, err = remote.SignBLS(account, []byte("epoch2"), []byte("extra"), true, true, fork, big.NewInt(5))
+	assert.NoError(t, err)
+}
@@ -0,0 +1,60 @@
+package slashing
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/mapprotocol/atlas/core/types"
+	"github.com/mapprotocol/atlas/params"
+)
+
+var (
+	downtimePenaltyKey          = crypto.Keccak256Hash([]byte("downtimePenalty"))
+	downtimeRewardKey           = crypto.Keccak256Hash([]byte("downtimeReward"))
+	downtimeSlashableWindowsKey = crypto.Keccak256Hash([]byte("downtimeSlashableWindows"))
+)
+
+// SetDowntimeParameters stores the penalty and reward of downtime and the number of
+// consecutive lookback windows a validator may miss without being slashed, as configured
+// by the genesis.
+func SetDowntimeParameters(db types.StateDB, penalty, reward *big.Int, slashableWindows uint64) {
+	keepAccount(db, params.DowntimeSlasherAddress)
+	db.SetState(params.DowntimeSlasherAddress, downtimePenaltyKey, common.BigToHash(penalty))
+	db.SetState(params.DowntimeSlasherAddress, downtimeRewardKey, common.BigToHash(reward))
+	db.SetState(params.DowntimeSlasherAddress, downtimeSlashableWindowsKey, common.BigToHash(new(big.Int).SetUint64(slashableWindows)))
+}
+
+// DowntimeParameters returns the penalty and reward of downtime and the number of
+// consecutive lookback windows a validator may miss. Chains whose genesis does not
+// configure them use the default ones.
+func DowntimeParameters(db types.StateDB) (penalty *big.Int, reward *big.Int, slashableWindows uint64) {
+	penalty = db.GetState(params.DowntimeSlasherAddress, downtimePenaltyKey).Big()
+	reward = db.GetState(params.DowntimeSlasherAddress, downtimeRewardKey).Big()
+	slashableWindows = db.GetState(params.DowntimeSlasherAddress, downtimeSlashableWindowsKey).Big().Uint64()
+	if penalty.Sign() == 0 {
+		return new(big.Int).Set(params.DowntimeSlashingPenalty), new(big.Int).Set(params.DowntimeSlashingReward), params.DowntimeSlashableWindows
+	}
+	return penalty, reward, slashableWindows
+}
+
+func jailedDbKey(account common.Address) common.Hash {
+	return crypto.Keccak256Hash([]byte("jailed"), account[:])
+}
+
+// Jail keeps the account out of the validator elections up to and including the epoch. A
+// longer jail the account is already in is kept.
+func Jail(db types.StateDB, account common.Address, epoch uint64) {
+	if IsJailed(db, account, epoch) {
+		return
+	}
+	keepAccount(db, params.DowntimeSlasherAddress)
+	db.SetPOWState(params.DowntimeSlasherAddress, jailedDbKey(account), new(big.Int).SetUint64(epoch).Bytes())
+}
+
+// IsJailed returns whether the account may not be elected for the epoch.
+func IsJailed(db types.StateDB, account common.Address, epoch uint64) bool {
+	data := db.GetPOWState(params.DowntimeSlasherAddress, jailedDbKey(account))
+	return len(data) != 0 && new(big.Int).SetBytes(data).Uint64() >= epoch
+}
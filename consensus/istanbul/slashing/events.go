@@ -0,0 +1,84 @@
+package slashing
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
+
+	"github.com/mapprotocol/atlas/core/types"
+	"github.com/mapprotocol/atlas/params"
+)
+
+// Reasons a validator is slashed for
+const (
+	ReasonDoubleSigning = "doubleSigning"
+	ReasonDowntime      = "downtime"
+)
+
+// Event is a validator slashed by the node while finalizing a block.
+type Event struct {
+	Reason   string
+	Signer   common.Address
+	Account  common.Address
+	Number   uint64 // block the offence is about
+	Penalty  *big.Int
+	Reward   *big.Int
+	Reporter common.Address
+}
+
+// eventsAddress returns the slasher the events of the reason are kept by.
+func eventsAddress(reason string) common.Address {
+	if reason == ReasonDowntime {
+		return params.DowntimeSlasherAddress
+	}
+	return params.EvidenceAddress
+}
+
+func eventsDbKey(epoch uint64) common.Hash {
+	return common.BytesToHash(append([]byte("events"), new(big.Int).SetUint64(epoch).Bytes()...))
+}
+
+func loadEvents(db types.StateDB, addr common.Address, epoch uint64) ([]*Event, error) {
+	var events []*Event
+	data := db.GetPOWState(addr, eventsDbKey(epoch))
+	if len(data) == 0 {
+		return nil, nil
+	}
+	if err := rlp.DecodeBytes(data, &events); err != nil {
+		return nil, fmt.Errorf("slashing events RLP decode failed, error: %s", err.Error())
+	}
+	return events, nil
+}
+
+// AddEvent appends the event to the slashing events of the epoch.
+func AddEvent(db types.StateDB, epoch uint64, event *Event) error {
+	addr := eventsAddress(event.Reason)
+	events, err := loadEvents(db, addr, epoch)
+	if err != nil {
+		return err
+	}
+	data, err := rlp.EncodeToBytes(append(events, event))
+	if err != nil {
+		log.Error("Failed to RLP encode slashing events", "err", err)
+		return err
+	}
+	keepAccount(db, addr)
+	db.SetPOWState(addr, eventsDbKey(epoch), data)
+	return nil
+}
+
+// Events returns the slashing events of the epoch, the double signing ones first.
+func Events(db types.StateDB, epoch uint64) ([]*Event, error) {
+	doubleSigning, err := loadEvents(db, eventsAddress(ReasonDoubleSigning), epoch)
+	if err != nil {
+		return nil, err
+	}
+	downtime, err := loadEvents(db, eventsAddress(ReasonDowntime), epoch)
+	if err != nil {
+		return nil, err
+	}
+	return append(doubleSigning, downtime...), nil
+}
@@ -0,0 +1,138 @@
+// Package slashing keeps the double signing offences proven to the evidence
+// precompile, until the block they are reported in is finalized and the offenders
+// are slashed. It also keeps the validators jailed for downtime and the slashing
+// events of each epoch.
+package slashing
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
+
+	"github.com/mapprotocol/atlas/consensus/istanbul"
+	"github.com/mapprotocol/atlas/core/types"
+	"github.com/mapprotocol/atlas/params"
+)
+
+// Report is an offence together with the account that proved it.
+type Report struct {
+	Signer   common.Address
+	Number   uint64
+	Reporter common.Address
+}
+
+// Pending is the reports of the block being processed, in the order they were made.
+type Pending struct {
+	Reports []*Report
+}
+
+var (
+	penaltyKey = crypto.Keccak256Hash([]byte("doubleSigningPenalty"))
+	rewardKey  = crypto.Keccak256Hash([]byte("doubleSigningReward"))
+)
+
+// SetParameters stores the penalty and reward of double signing, as configured by the genesis.
+// They are kept in the contract storage so that they are part of the genesis alloc.
+func SetParameters(db types.StateDB, penalty, reward *big.Int) {
+	keepAccount(db, params.EvidenceAddress)
+	db.SetState(params.EvidenceAddress, penaltyKey, common.BigToHash(penalty))
+	db.SetState(params.EvidenceAddress, rewardKey, common.BigToHash(reward))
+}
+
+// Parameters returns the penalty and reward of double signing. Chains whose genesis does
+// not configure them use the default ones.
+func Parameters(db types.StateDB) (penalty *big.Int, reward *big.Int) {
+	penalty = db.GetState(params.EvidenceAddress, penaltyKey).Big()
+	reward = db.GetState(params.EvidenceAddress, rewardKey).Big()
+	if penalty.Sign() == 0 {
+		return new(big.Int).Set(params.DoubleSigningPenalty), new(big.Int).Set(params.DoubleSigningReward)
+	}
+	return penalty, reward
+}
+
+// keepAccount gives the account a code so that it is not removed as an empty account.
+func keepAccount(db types.StateDB, addr common.Address) {
+	if db.GetCodeSize(addr) == 0 {
+		db.SetCode(addr, addr[:])
+	}
+}
+
+func pendingDbKey() common.Hash {
+	return common.BytesToHash([]byte("pending"))
+}
+
+func slashedDbKey(signer common.Address, number uint64) common.Hash {
+	return crypto.Keccak256Hash([]byte("slashed"), signer[:], new(big.Int).SetUint64(number).Bytes())
+}
+
+func loadPending(db types.StateDB) (*Pending, error) {
+	pending := new(Pending)
+	data := db.GetPOWState(params.EvidenceAddress, pendingDbKey())
+	if len(data) == 0 {
+		return pending, nil
+	}
+	if err := rlp.DecodeBytes(data, pending); err != nil {
+		return nil, fmt.Errorf("slashing RLP decode failed, error: %s", err.Error())
+	}
+	return pending, nil
+}
+
+func storePending(db types.StateDB, pending *Pending) error {
+	data, err := rlp.EncodeToBytes(pending)
+	if err != nil {
+		log.Error("Failed to RLP encode slashing reports", "err", err)
+		return err
+	}
+	keepAccount(db, params.EvidenceAddress)
+	db.SetPOWState(params.EvidenceAddress, pendingDbKey(), data)
+	return nil
+}
+
+// IsSlashed returns whether the signer has been reported for double signing the block.
+func IsSlashed(db types.StateDB, signer common.Address, number uint64) bool {
+	return len(db.GetPOWState(params.EvidenceAddress, slashedDbKey(signer, number))) != 0
+}
+
+// Record adds the offences to the reports of the block, the ones already reported
+// are skipped. It returns the offences recorded.
+func Record(db types.StateDB, offences []istanbul.Offence, reporter common.Address) ([]istanbul.Offence, error) {
+	pending, err := loadPending(db)
+	if err != nil {
+		return nil, err
+	}
+	var recorded []istanbul.Offence
+	for _, o := range offences {
+		if IsSlashed(db, o.Signer, o.Number) {
+			continue
+		}
+		db.SetPOWState(params.EvidenceAddress, slashedDbKey(o.Signer, o.Number), []byte{1})
+		pending.Reports = append(pending.Reports, &Report{Signer: o.Signer, Number: o.Number, Reporter: reporter})
+		recorded = append(recorded, o)
+	}
+	if len(recorded) == 0 {
+		return nil, nil
+	}
+	if err := storePending(db, pending); err != nil {
+		return nil, err
+	}
+	return recorded, nil
+}
+
+// TakePending returns the reports of the block and clears them.
+func TakePending(db types.StateDB) ([]*Report, error) {
+	pending, err := loadPending(db)
+	if err != nil {
+		return nil, err
+	}
+	if len(pending.Reports) == 0 {
+		return nil, nil
+	}
+	if err := storePending(db, new(Pending)); err != nil {
+		return nil, err
+	}
+	return pending.Reports, nil
+}
@@ -0,0 +1,127 @@
+package slashing
+
+import (
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mapprotocol/atlas/consensus/istanbul"
+	"github.com/mapprotocol/atlas/core/rawdb"
+	"github.com/mapprotocol/atlas/core/state"
+	"github.com/mapprotocol/atlas/params"
+)
+
+var (
+	alice    = common.Address{0xa}
+	bob      = common.Address{0xb}
+	reporter = common.Address{0xc}
+)
+
+func getStateDB() *state.StateDB {
+	db, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	return db
+}
+
+func TestRecord(t *testing.T) {
+	db := getStateDB()
+
+	offences := []istanbul.Offence{{Signer: alice, Number: 10}, {Signer: bob, Number: 10}}
+	recorded, err := Record(db, offences, reporter)
+	assert.NoError(t, err)
+	assert.Equal(t, offences, recorded)
+	assert.True(t, IsSlashed(db, alice, 10))
+	assert.True(t, IsSlashed(db, bob, 10))
+	assert.False(t, IsSlashed(db, alice, 11))
+
+	// An offence is only reported once
+	recorded, err = Record(db, []istanbul.Offence{{Signer: alice, Number: 10}, {Signer: alice, Number: 11}}, bob)
+	assert.NoError(t, err)
+	assert.Equal(t, []istanbul.Offence{{Signer: alice, Number: 11}}, recorded)
+	recorded, err = Record(db, offences, reporter)
+	assert.NoError(t, err)
+	assert.Empty(t, recorded)
+
+	reports, err := TakePending(db)
+	assert.NoError(t, err)
+	assert.Equal(t, []*Report{
+		{Signer: alice, Number: 10, Reporter: reporter},
+		{Signer: bob, Number: 10, Reporter: reporter},
+		{Signer: alice, Number: 11, Reporter: bob},
+	}, reports)
+
+	// The reports are cleared, the offences stay slashed
+	reports, err = TakePending(db)
+	assert.NoError(t, err)
+	assert.Empty(t, reports)
+	assert.True(t, IsSlashed(db, alice, 10))
+}
+
+func TestParameters(t *testing.T) {
+	db := getStateDB()
+
+	penalty, reward := Parameters(db)
+	assert.Equal(t, params.DoubleSigningPenalty, penalty)
+	assert.Equal(t, params.DoubleSigningReward, reward)
+
+	SetParameters(db, big.NewInt(100), big.NewInt(10))
+	penalty, reward = Parameters(db)
+	assert.Equal(t, big.NewInt(100), penalty)
+	assert.Equal(t, big.NewInt(10), reward)
+
+	// The storage must not be dropped as an empty account
+	db.Finalise(true)
+	assert.True(t, db.Exist(params.EvidenceAddress))
+}
+
+func TestDowntimeParameters(t *testing.T) {
+	db := getStateDB()
+
+	penalty, reward, windows := DowntimeParameters(db)
+	assert.Equal(t, params.DowntimeSlashingPenalty, penalty)
+	assert.Equal(t, params.DowntimeSlashingReward, reward)
+	assert.Equal(t, params.DowntimeSlashableWindows, windows)
+
+	SetDowntimeParameters(db, big.NewInt(100), big.NewInt(10), 8)
+	penalty, reward, windows = DowntimeParameters(db)
+	assert.Equal(t, big.NewInt(100), penalty)
+	assert.Equal(t, big.NewInt(10), reward)
+	assert.Equal(t, uint64(8), windows)
+}
+
+func TestJail(t *testing.T) {
+	db := getStateDB()
+
+	assert.False(t, IsJailed(db, alice, 1))
+	Jail(db, alice, 3)
+	assert.True(t, IsJailed(db, alice, 2))
+	assert.True(t, IsJailed(db, alice, 3))
+	assert.False(t, IsJailed(db, alice, 4))
+	assert.False(t, IsJailed(db, bob, 3))
+
+	// a shorter jail does not cut the current one
+	Jail(db, alice, 2)
+	assert.True(t, IsJailed(db, alice, 3))
+	Jail(db, alice, math.MaxUint64)
+	Jail(db, alice, 5)
+	assert.True(t, IsJailed(db, alice, 1000))
+}
+
+func TestEvents(t *testing.T) {
+	db := getStateDB()
+
+	downtime := &Event{Reason: ReasonDowntime, Signer: alice, Account: bob, Number: 100, Penalty: big.NewInt(10), Reward: big.NewInt(1), Reporter: reporter}
+	doubleSigning := &Event{Reason: ReasonDoubleSigning, Signer: bob, Account: alice, Number: 42, Penalty: big.NewInt(90), Reward: big.NewInt(0)}
+	assert.NoError(t, AddEvent(db, 1, downtime))
+	assert.NoError(t, AddEvent(db, 1, doubleSigning))
+
+	events, err := Events(db, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, []*Event{doubleSigning, downtime}, events)
+
+	events, err = Events(db, 2)
+	assert.NoError(t, err)
+	assert.Empty(t, events)
+}
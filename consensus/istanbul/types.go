@@ -17,6 +17,7 @@
 package istanbul
 
 import (
+	"bytes"
 	"crypto/ecdsa"
 	"fmt"
 	"github.com/ethereum/go-ethereum/log"
@@ -49,6 +50,25 @@ type BLSSignerFn func(accounts.Account, []byte, []byte, bool, bool, *big.Int, *b
 // backing account.
 type HashSignerFn func(accounts.Account, []byte) ([]byte, error)
 
+// TxSignerFn is a signer callback function to request a transaction to be signed by a
+// backing account.
+type TxSignerFn func(accounts.Account, *types.Transaction, *big.Int) (*types.Transaction, error)
+
+// RandomSeedHash is the hash validators sign to derive the seed of their randomness.
+var RandomSeedHash = common.BytesToHash([]byte("Randomness seed string"))
+
+// Signer holds the ECDSA and BLS keys of a validator and signs on its behalf. Wallets
+// of the account manager are signers, so are the remote signers keeping the keys away
+// from the validator host.
+type Signer interface {
+	GetPublicKey(account accounts.Account) (*ecdsa.PublicKey, error)
+	SignData(account accounts.Account, mimeType string, data []byte) ([]byte, error)
+	SignHash(account accounts.Account, hash []byte) ([]byte, error)
+	SignTx(account accounts.Account, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error)
+	SignBLS(account accounts.Account, msg []byte, extraData []byte, useComposite, cip22 bool, fork, cur *big.Int) (blscrypto.SerializedSignature, error)
+	Decrypt(account accounts.Account, c, s1, s2 []byte) ([]byte, error)
+}
+
 // Proposal supports retrieving height and serialized block to be used during Istanbul consensus.
 type Proposal interface {
 	// Number retrieves the sequence number of this proposal.
@@ -1099,6 +1119,16 @@ func (vc *VersionCertificate) signaturePayload() ([]byte, error) {
 	return rlp.EncodeToBytes([]interface{}{versionCertificateSalt, vc.Version})
 }
 
+// IsVersionCertificatePayload reports whether the data is the signature payload of a
+// version certificate.
+func IsVersionCertificatePayload(data []byte) bool {
+	var payload struct {
+		Salt    []byte
+		Version uint
+	}
+	return rlp.DecodeBytes(data, &payload) == nil && bytes.Equal(payload.Salt, versionCertificateSalt)
+}
+
 func (vc *VersionCertificate) Address() common.Address {
 	return vc.address
 }
@@ -39,6 +39,25 @@ func (u *UptimeEntry) String() string {
 	return fmt.Sprintf("UptimeEntry { upBlocks: %v, lastBlock: %v}", u.UpBlocks, u.LastSignedBlock)
 }
 
+// Downtime contains an array of Entries where the `i`th entry represents how long the `i`th
+// validator in the validator set for that epoch was down. A validator is down at a monitored
+// block if it signed none of the blocks of the lookback window ending at it.
+type Downtime struct {
+	Entries []DowntimeEntry
+}
+
+// DowntimeEntry contains the numbers of consecutive monitored blocks a validator was down at
+type DowntimeEntry struct {
+	// Numbers of consecutive blocks validator is considered DOWN up to the latest block
+	DownBlocks uint64
+	// Longest run of consecutive blocks validator was considered DOWN within the epoch
+	LongestDownBlocks uint64
+}
+
+func (d *DowntimeEntry) String() string {
+	return fmt.Sprintf("DowntimeEntry { downBlocks: %v, longestDownBlocks: %v}", d.DownBlocks, d.LongestDownBlocks)
+}
+
 // Monitor is responsible for monitoring uptime by processing blocks
 type Monitor struct {
 	epochSize      uint64
@@ -105,6 +124,58 @@ func (um *Monitor) ComputeValidatorsUptime(epoch uint64, valSetSize int) ([]*big
 	return uptimes, nil
 }
 
+// ComputeValidatorsDowntime computes, for each validator, the largest number of consecutive
+// lookback windows of the epoch in which it signed no block, by replaying the parent
+// aggregated seals of the given headers of the epoch. The headers must be consecutive and in
+// ascending order. It does not depend on the store, so every node computes the same ones.
+func (um *Monitor) ComputeValidatorsDowntime(epoch uint64, valSetSize int, headers []*types.Header) ([]uint64, error) {
+	var (
+		uptime   *Uptime
+		downtime *Downtime
+		window   = um.MonitoringWindow(epoch)
+	)
+	for i, header := range headers {
+		number := header.Number.Uint64()
+		if i > 0 && number != headers[i-1].Number.Uint64()+1 {
+			return nil, fmt.Errorf("non contiguous headers %d and %d", headers[i-1].Number, number)
+		}
+		if istanbul.GetEpochNumber(number, um.epochSize) != epoch {
+			return nil, fmt.Errorf("header %d out of epoch %d", number, epoch)
+		}
+		if istanbul.IsFirstBlockOfEpoch(number, um.epochSize) {
+			continue
+		}
+		extra, err := types.ExtractIstanbulExtra(header)
+		if err != nil {
+			return nil, err
+		}
+		uptime = updateUptime(uptime, number-1, extra.ParentAggregatedSeal.Bitmap, um.lookbackWindow, window)
+		downtime = updateDowntime(downtime, uptime, number-1, um.lookbackWindow, window)
+	}
+	if downtime == nil {
+		downtime = new(Downtime)
+	}
+	return um.downtimes(downtime, valSetSize), nil
+}
+
+// downtimes converts the accumulated downtime of the first valSetSize validators into numbers
+// of lookback windows, validators without an entry were never seen down.
+func (um *Monitor) downtimes(accumulated *Downtime, valSetSize int) []uint64 {
+	downtimes := make([]uint64, valSetSize)
+	for i, entry := range accumulated.Entries {
+		if i >= valSetSize {
+			break
+		}
+		if entry.LongestDownBlocks == 0 {
+			continue
+		}
+		// Being down for n consecutive blocks means not signing the n+lookbackWindow-1 blocks
+		// from the start of the lookback window of the first one
+		downtimes[i] = (entry.LongestDownBlocks + um.lookbackWindow - 1) / um.lookbackWindow
+	}
+	return downtimes
+}
+
 func (um *Monitor) GetValidatorsActivity(epoch, numberWithinEpoch uint64, valSetSize int) ([]UptimeEntry, []float64, error) {
 	logger := um.logger.New("func", "Monitor.GetValidatorsActivity", "epoch", epoch)
 
@@ -198,6 +269,35 @@ func updateUptime(uptime *Uptime, blockNumber uint64, bitmap *big.Int, lookbackW
 	return uptime
 }
 
+// updateDowntime updates the accumulated downtime given a block and the uptime updated with it
+func updateDowntime(downtime *Downtime, uptime *Uptime, blockNumber uint64, lookbackWindowSize uint64, monitoringWindow Window) *Downtime {
+	if downtime == nil {
+		downtime = new(Downtime)
+	}
+	if len(downtime.Entries) < len(uptime.Entries) {
+		entries := make([]DowntimeEntry, len(uptime.Entries))
+		copy(entries, downtime.Entries)
+		downtime.Entries = entries
+	}
+	if !monitoringWindow.Contains(blockNumber) {
+		return downtime
+	}
+
+	currentLookbackWindow := newWindowEndingAt(blockNumber, lookbackWindowSize)
+	for i := 0; i < len(uptime.Entries); i++ {
+		entry := &downtime.Entries[i]
+		if currentLookbackWindow.Contains(uptime.Entries[i].LastSignedBlock) {
+			entry.DownBlocks = 0
+			continue
+		}
+		entry.DownBlocks++
+		if entry.DownBlocks > entry.LongestDownBlocks {
+			entry.LongestDownBlocks = entry.DownBlocks
+		}
+	}
+	return downtime
+}
+
 // https://stackoverflow.com/questions/19105791/is-there-a-big-bitcount/32702348#32702348
 func bitCount(n *big.Int) int {
 	count := 0
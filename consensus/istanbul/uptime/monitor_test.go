@@ -4,6 +4,10 @@ import (
 	"math/big"
 	"reflect"
 	"testing"
+
+	"github.com/ethereum/go-ethereum/rlp"
+
+	"github.com/mapprotocol/atlas/core/types"
 )
 
 func TestUptime(t *testing.T) {
@@ -102,3 +106,75 @@ func TestUptimeSingle(t *testing.T) {
 		t.Fatalf("uptimes were not updated correctly, got %v, expected %v", uptimes, expected)
 	}
 }
+
+func TestDowntime(t *testing.T) {
+	var (
+		uptimes   *Uptime
+		downtimes *Downtime
+	)
+	monitoringWindow := MustMonitoringWindow(1, 20, 2) // [2,18]
+	for block := uint64(1); block <= 18; block++ {
+		// validator 0 always signs, validator 1 misses blocks 5 to 12, validator 2 never signs
+		bitmap := big.NewInt(3)
+		if block >= 5 && block <= 12 {
+			bitmap = big.NewInt(1)
+		}
+		uptimes = updateUptime(uptimes, block, bitmap, 2, monitoringWindow)
+		downtimes = updateDowntime(downtimes, uptimes, block, 2, monitoringWindow)
+	}
+
+	expected := &Downtime{
+		Entries: []DowntimeEntry{
+			{DownBlocks: 0, LongestDownBlocks: 0},
+			// down from block 6, whose lookback window is the first one it did not sign in
+			{DownBlocks: 0, LongestDownBlocks: 7},
+			{DownBlocks: 17, LongestDownBlocks: 17},
+			// plus a dummy due to the *2
+			{DownBlocks: 17, LongestDownBlocks: 17},
+		},
+	}
+	if !reflect.DeepEqual(downtimes, expected) {
+		t.Fatalf("downtimes were not updated correctly, got %v, expected %v", downtimes, expected)
+	}
+}
+
+func TestComputeValidatorsDowntime(t *testing.T) {
+	var headers []*types.Header
+	for number := uint64(1); number <= 19; number++ {
+		// the parent seal of block n is signed by the validators of block n-1: validator 0
+		// always signs, validator 1 misses blocks 5 to 12, validator 2 never signs
+		bitmap := big.NewInt(3)
+		if number-1 >= 5 && number-1 <= 12 {
+			bitmap = big.NewInt(1)
+		}
+		extra, err := rlp.EncodeToBytes(&types.IstanbulExtra{
+			RemovedValidators:    new(big.Int),
+			AggregatedSeal:       types.IstanbulAggregatedSeal{Bitmap: new(big.Int), Round: new(big.Int)},
+			ParentAggregatedSeal: types.IstanbulAggregatedSeal{Bitmap: bitmap, Round: new(big.Int)},
+		})
+		if err != nil {
+			t.Fatalf("failed to encode istanbul extra: %v", err)
+		}
+		headers = append(headers, &types.Header{
+			Number: new(big.Int).SetUint64(number),
+			Extra:  append(make([]byte, types.IstanbulExtraVanity), extra...),
+		})
+	}
+
+	monitor := NewMonitor(nil, 20, 2)
+	missed, err := monitor.ComputeValidatorsDowntime(1, 3, headers)
+	if err != nil {
+		t.Fatalf("failed to compute downtimes: %v", err)
+	}
+	// Missing blocks 5 to 12 is missing 4 lookback windows
+	if want := []uint64{0, 4, 9}; !reflect.DeepEqual(missed, want) {
+		t.Errorf("missed windows mismatch, got %v, expected %v", missed, want)
+	}
+
+	if _, err := monitor.ComputeValidatorsDowntime(1, 3, []*types.Header{headers[2], headers[4]}); err == nil {
+		t.Error("expected an error computing the downtimes of non contiguous headers")
+	}
+	if _, err := monitor.ComputeValidatorsDowntime(2, 3, headers); err == nil {
+		t.Error("expected an error computing the downtimes of headers of another epoch")
+	}
+}
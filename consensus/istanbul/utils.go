@@ -250,3 +250,32 @@ func RandomnessCommitmentDBLocation(commitment common.Hash) []byte {
 	dbRandomnessPrefix := []byte("db-randomness-prefix")
 	return append(dbRandomnessPrefix, commitment.Bytes()...)
 }
+
+// MaxSnarkValidators represents the maximum number of validators the SNARK circuit supports
+// The prover code will then pad any proofs to this maximum to ensure consistent proof structure
+const MaxSnarkValidators = uint32(150)
+
+// EpochValidatorSetData returns the message and extra data the validators of the last
+// block of an epoch sign for the SNARK circuit. From the snark fork on, the validators
+// of the next epoch are encoded with fixed width fields, before it with the CIP22
+// encoding. Both are signed with the composite hasher.
+func EpochValidatorSetData(snark bool, blockNumber, epochSize uint64, round uint8, blockHash, parentEpochBlockHash common.Hash, newValSet ValidatorSet) ([]byte, []byte, error) {
+	// Serialize the public keys for the validators in the validator set.
+	blsPubKeys := make([]blscrypto.SerializedPublicKey, 0, newValSet.Size())
+	for _, v := range newValSet.List() {
+		blsPubKeys = append(blsPubKeys, v.BLSPublicKey())
+	}
+
+	encode := blscrypto.CryptoType().EncodeEpochSnarkDataCIP22
+	if snark {
+		encode = blscrypto.CryptoType().EncodeEpochSnarkData
+	}
+	maxNonSigners := MaxSnarkValidators - uint32(newValSet.MinQuorumSize())
+	return encode(
+		blsPubKeys, maxNonSigners, MaxSnarkValidators,
+		uint16(GetEpochNumber(blockNumber, epochSize)),
+		round,
+		blscrypto.EpochEntropyFromHash(blockHash),
+		blscrypto.EpochEntropyFromHash(parentEpochBlockHash),
+	)
+}
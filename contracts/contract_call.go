@@ -75,16 +75,22 @@ type BoundMethod struct {
 // Query executes the method with the given EVMRunner as a read only action, the returned
 // value is unpacked into result.
 func (bm *BoundMethod) Query(vmRunner vm.EVMRunner, result interface{}, args ...interface{}) error {
-	return bm.run(vmRunner, result, true, nil, args...)
+	return bm.run(vmRunner, result, true, nil, nil, args...)
 }
 
 // Execute executes the method with the given EVMRunner and unpacks the return value into result.
 // If the method does not return a value then result should be nil.
 func (bm *BoundMethod) Execute(vmRunner vm.EVMRunner, result interface{}, value *big.Int, args ...interface{}) error {
-	return bm.run(vmRunner, result, false, value, args...)
+	return bm.run(vmRunner, result, false, nil, value, args...)
 }
 
-func (bm *BoundMethod) run(vmRunner vm.EVMRunner, result interface{}, readOnly bool, value *big.Int, args ...interface{}) error {
+// ExecuteFrom is like Execute, but the method is called by sender. It lets the protocol act as
+// a registered contract, e.g. the slashers that are allowed to slash locked gold.
+func (bm *BoundMethod) ExecuteFrom(vmRunner vm.EVMRunner, sender common.Address, result interface{}, value *big.Int, args ...interface{}) error {
+	return bm.run(vmRunner, result, false, &sender, value, args...)
+}
+
+func (bm *BoundMethod) run(vmRunner vm.EVMRunner, result interface{}, readOnly bool, sender *common.Address, value *big.Int, args ...interface{}) error {
 	defer meterExecutionTime(bm.method)()
 
 	contractAddress, err := bm.resolveAddress(vmRunner)
@@ -103,6 +109,8 @@ func (bm *BoundMethod) run(vmRunner vm.EVMRunner, result interface{}, readOnly b
 	var output []byte
 	if readOnly {
 		output, err = vmRunner.Query(contractAddress, input, bm.maxGas)
+	} else if sender != nil {
+		output, err = vmRunner.ExecuteFrom(*sender, contractAddress, input, bm.maxGas, value)
 	} else {
 		output, err = vmRunner.Execute(contractAddress, input, bm.maxGas, value)
 	}
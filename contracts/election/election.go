@@ -36,6 +36,9 @@ var (
 
 	activeAllPendingMethod             = contracts.NewRegisteredContractMethod(params.ElectionRegistryId, abis.Elections, "activeAllPending", params.MaxGasForActiveAllPending)
 	getPendingVotersForValidatorMethod = contracts.NewRegisteredContractMethod(params.ElectionRegistryId, abis.Elections, "getPendingVotersForValidator", params.MaxGasForActiveAllPending)
+
+	getValidatorsVotedForByAccountMethod     = contracts.NewRegisteredContractMethod(params.ElectionRegistryId, abis.Elections, "getValidatorsVotedForByAccount", params.MaxGasForGetVotesByAccount)
+	getTotalVotesForValidatorByAccountMethod = contracts.NewRegisteredContractMethod(params.ElectionRegistryId, abis.Elections, "getTotalVotesForValidatorByAccount", params.MaxGasForGetVotesByAccount)
 )
 
 func GetElectedValidators(vmRunner vm.EVMRunner) ([]common.Address, error) {
@@ -155,3 +158,64 @@ func ActiveAllPending(vmRunner vm.EVMRunner, validators []common.Address) (bool,
 	log.Info("ActiveAllPending", "end", time.Now().Sub(start))
 	return success, nil
 }
+
+// GetLessersAndGreatersAfterSlash returns the arguments forceDecrementVotes needs to take value
+// from the votes of the account. Votes are taken from the last validator the account voted
+// for to the first, each entry places the validator among the eligible ones once its votes
+// are decremented.
+func GetLessersAndGreatersAfterSlash(vmRunner vm.EVMRunner, account common.Address, value *big.Int) ([]common.Address, []common.Address, []*big.Int, error) {
+	var votedFor []common.Address
+	if err := getValidatorsVotedForByAccountMethod.Query(vmRunner, &votedFor, account); err != nil {
+		return nil, nil, nil, err
+	}
+	voteTotals, err := getTotalVotesForEligibleValidators(vmRunner)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	lessers := make([]common.Address, len(votedFor))
+	greaters := make([]common.Address, len(votedFor))
+	indices := make([]*big.Int, len(votedFor))
+	for i := range votedFor {
+		lessers[i] = params.ZeroAddress
+		greaters[i] = params.ZeroAddress
+		indices[i] = big.NewInt(int64(i))
+	}
+
+	remaining := new(big.Int).Set(value)
+	for i := len(votedFor) - 1; i >= 0 && remaining.Sign() > 0; i-- {
+		validator := votedFor[i]
+		var votes *big.Int
+		if err := getTotalVotesForValidatorByAccountMethod.Query(vmRunner, &votes, validator, account); err != nil {
+			return nil, nil, nil, err
+		}
+		decrement := new(big.Int).Set(votes)
+		if remaining.Cmp(votes) < 0 {
+			decrement.Set(remaining)
+		}
+		remaining.Sub(remaining, decrement)
+
+		for j := range voteTotals {
+			if voteTotals[j].Validator == validator {
+				voteTotals[j].Value = new(big.Int).Sub(voteTotals[j].Value, decrement)
+				break
+			}
+		}
+		// Sorting in descending order is necessary to match the order on-chain.
+		sort.SliceStable(voteTotals, func(j, k int) bool {
+			return voteTotals[j].Value.Cmp(voteTotals[k].Value) > 0
+		})
+		for j, voteTotal := range voteTotals {
+			if voteTotal.Validator == validator {
+				if j > 0 {
+					greaters[i] = voteTotals[j-1].Validator
+				}
+				if j+1 < len(voteTotals) {
+					lessers[i] = voteTotals[j+1].Validator
+				}
+				break
+			}
+		}
+	}
+	return lessers, greaters, indices, nil
+}
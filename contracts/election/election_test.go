@@ -1,13 +1,89 @@
 package election
 
 import (
+	"math/big"
 	"testing"
 
+	"github.com/ethereum/go-ethereum/common"
+	. "github.com/onsi/gomega"
+
 	"github.com/mapprotocol/atlas/contracts"
+	"github.com/mapprotocol/atlas/contracts/abis"
 	"github.com/mapprotocol/atlas/contracts/testutil"
+	"github.com/mapprotocol/atlas/params"
 )
 
 func TestGetElectedValidators(t *testing.T) {
 	testutil.TestFailOnFailingRunner(t, GetElectedValidators)
 	testutil.TestFailsWhenContractNotDeployed(t, contracts.ErrSmartContractNotDeployed, GetElectedValidators)
 }
+
+type electionMock struct {
+	testutil.ContractMock
+	votedFor     []common.Address
+	accountVotes map[common.Address]*big.Int
+	totals       map[common.Address]*big.Int
+}
+
+func (em *electionMock) GetValidatorsVotedForByAccount(account common.Address) []common.Address {
+	return em.votedFor
+}
+
+func (em *electionMock) GetTotalVotesForValidatorByAccount(validator common.Address, account common.Address) *big.Int {
+	return em.accountVotes[validator]
+}
+
+func (em *electionMock) GetTotalVotesForEligibleValidators() ([]common.Address, []*big.Int) {
+	validators := []common.Address{validatorA, validatorB, validatorC}
+	values := make([]*big.Int, len(validators))
+	for i, v := range validators {
+		values[i] = em.totals[v]
+	}
+	return validators, values
+}
+
+var (
+	voter      = common.HexToAddress("0x0a")
+	validatorA = common.HexToAddress("0x01")
+	validatorB = common.HexToAddress("0x02")
+	validatorC = common.HexToAddress("0x03")
+)
+
+func TestGetLessersAndGreatersAfterSlash(t *testing.T) {
+	testutil.TestFailOnFailingRunner(t, GetLessersAndGreatersAfterSlash, voter, big.NewInt(1))
+	testutil.TestFailsWhenContractNotDeployed(t, contracts.ErrSmartContractNotDeployed, GetLessersAndGreatersAfterSlash, voter, big.NewInt(1))
+
+	newRunner := func() *testutil.MockEVMRunner {
+		mock := &electionMock{
+			votedFor:     []common.Address{validatorA, validatorC},
+			accountVotes: map[common.Address]*big.Int{validatorA: big.NewInt(30), validatorC: big.NewInt(20)},
+			totals:       map[common.Address]*big.Int{validatorA: big.NewInt(100), validatorB: big.NewInt(80), validatorC: big.NewInt(50)},
+		}
+		mock.ContractMock = testutil.NewContractMock(abis.Elections, mock)
+		runner := testutil.NewMockEVMRunner()
+		registry := testutil.NewRegistryMock()
+		runner.RegisterContract(params.RegistrySmartContractAddress, registry)
+		registry.AddContract(params.ElectionRegistryId, common.HexToAddress("0x0e"))
+		runner.RegisterContract(common.HexToAddress("0x0e"), mock)
+		return runner
+	}
+
+	t.Run("should only decrement the last validator voted for", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+		lessers, greaters, indices, err := GetLessersAndGreatersAfterSlash(newRunner(), voter, big.NewInt(10))
+		g.Expect(err).NotTo(HaveOccurred())
+		// C 40 stays below B 80
+		g.Expect(lessers).To(Equal([]common.Address{params.ZeroAddress, params.ZeroAddress}))
+		g.Expect(greaters).To(Equal([]common.Address{params.ZeroAddress, validatorB}))
+		g.Expect(indices).To(Equal([]*big.Int{big.NewInt(0), big.NewInt(1)}))
+	})
+
+	t.Run("should decrement from the last validator to the first", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+		lessers, greaters, _, err := GetLessersAndGreatersAfterSlash(newRunner(), voter, big.NewInt(35))
+		g.Expect(err).NotTo(HaveOccurred())
+		// C loses all of its 20 votes down to 30, A loses the remaining 15 down to 85
+		g.Expect(lessers).To(Equal([]common.Address{validatorB, params.ZeroAddress}))
+		g.Expect(greaters).To(Equal([]common.Address{params.ZeroAddress, validatorB}))
+	})
+}
@@ -0,0 +1,59 @@
+package locked_gold
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/mapprotocol/atlas/contracts"
+	"github.com/mapprotocol/atlas/contracts/abis"
+	"github.com/mapprotocol/atlas/core/vm"
+	"github.com/mapprotocol/atlas/params"
+)
+
+var (
+	getAccountTotalLockedGoldMethod     = contracts.NewRegisteredContractMethod(params.LockedGoldRegistryId, abis.LockedGold, "getAccountTotalLockedGold", params.MaxGasForGetVotesByAccount)
+	getAccountNonvotingLockedGoldMethod = contracts.NewRegisteredContractMethod(params.LockedGoldRegistryId, abis.LockedGold, "getAccountNonvotingLockedGold", params.MaxGasForGetVotesByAccount)
+	slashMethod                         = contracts.NewRegisteredContractMethod(params.LockedGoldRegistryId, abis.LockedGold, "slash", params.MaxGasForSlash)
+	isSlasherMethod                     = contracts.NewRegisteredContractMethod(params.LockedGoldRegistryId, abis.LockedGold, "isSlasher", params.MaxGasForGetVotesByAccount)
+	getOwnerMethod                      = contracts.NewRegisteredContractMethod(params.LockedGoldRegistryId, abis.LockedGold, "owner", params.MaxGasForGetVotesByAccount)
+	addSlasherMethod                    = contracts.NewRegisteredContractMethod(params.LockedGoldRegistryId, abis.LockedGold, "addSlasher", params.MaxGasForSlash)
+)
+
+func GetAccountTotalLockedGold(vmRunner vm.EVMRunner, account common.Address) (*big.Int, error) {
+	var total *big.Int
+	err := getAccountTotalLockedGoldMethod.Query(vmRunner, &total, account)
+	return total, err
+}
+
+func GetAccountNonvotingLockedGold(vmRunner vm.EVMRunner, account common.Address) (*big.Int, error) {
+	var nonvoting *big.Int
+	err := getAccountNonvotingLockedGoldMethod.Query(vmRunner, &nonvoting, account)
+	return nonvoting, err
+}
+
+// Slash takes the penalty from the locked gold of the account and pays the reward out of
+// it to the reporter, called by a registered slasher. The votes of the account are
+// decremented when its nonvoting locked gold does not cover the penalty, lessers, greaters
+// and indices place the validators it voted for after the decrement.
+func Slash(vmRunner vm.EVMRunner, slasher common.Address, account common.Address, penalty *big.Int, reporter common.Address, reward *big.Int,
+	lessers []common.Address, greaters []common.Address, indices []*big.Int) error {
+	return slashMethod.ExecuteFrom(vmRunner, slasher, nil, common.Big0, account, penalty, reporter, reward, lessers, greaters, indices)
+}
+
+// IsSlasher returns whether the address is registered under one of the slashers allowed to
+// slash locked gold.
+func IsSlasher(vmRunner vm.EVMRunner, slasher common.Address) (bool, error) {
+	var registered bool
+	err := isSlasherMethod.Query(vmRunner, &registered, slasher)
+	return registered, err
+}
+
+// AddSlasher allows the contract registered under the name to slash locked gold, on behalf
+// of the owner of LockedGold.
+func AddSlasher(vmRunner vm.EVMRunner, name string) error {
+	var owner common.Address
+	if err := getOwnerMethod.Query(vmRunner, &owner); err != nil {
+		return err
+	}
+	return addSlasherMethod.ExecuteFrom(vmRunner, owner, nil, common.Big0, name)
+}
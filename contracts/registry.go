@@ -8,7 +8,11 @@ import (
 	"github.com/mapprotocol/atlas/params"
 )
 
-var getAddressMethod = NewBoundMethod(params.RegistrySmartContractAddress, abis.Registry, "getAddressFor", params.MaxGasForGetAddressFor)
+var (
+	getAddressMethod    = NewBoundMethod(params.RegistrySmartContractAddress, abis.Registry, "getAddressFor", params.MaxGasForGetAddressFor)
+	getOwnerMethod      = NewBoundMethod(params.RegistrySmartContractAddress, abis.Registry, "owner", params.MaxGasForGetAddressFor)
+	setAddressForMethod = NewBoundMethod(params.RegistrySmartContractAddress, abis.Registry, "setAddressFor", params.MaxGasForSlash)
+)
 
 // TODO(kevjue) - Re-Enable caching of the retrieved registered address
 
@@ -35,3 +39,13 @@ func GetRegisteredAddress(vmRunner vm.EVMRunner, registryId common.Hash) (common
 
 	return contractAddress, nil
 }
+
+// SetRegisteredAddress registers the address on the registry under the name, on behalf of
+// the owner of the registry. It lets a fork register the contracts it introduces.
+func SetRegisteredAddress(vmRunner vm.EVMRunner, name string, address common.Address) error {
+	var owner common.Address
+	if err := getOwnerMethod.Query(vmRunner, &owner); err != nil {
+		return err
+	}
+	return setAddressForMethod.ExecuteFrom(vmRunner, owner, nil, common.Big0, name, address)
+}
@@ -50,6 +50,7 @@ var (
 	deRegisterValidatorsInPendingMethod        = contracts.NewRegisteredContractMethod(params.ValidatorsRegistryId, abis.Validators, "deRegisterAllValidatorsInPending", params.MaxGasForDeregisterPayment1)
 	getDeRegisteredValidatorsTMethod           = contracts.NewRegisteredContractMethod(params.ValidatorsRegistryId, abis.Validators, "getDeRegisteredValidatorsT", params.MaxGasForDistributeEpochPayment)
 	deRegisterValidatorsInPendingMethod2       = contracts.NewRegisteredContractMethod(params.ValidatorsRegistryId, abis.Validators, "deRegisterAllValidatorsInPending", params.MaxGasForDeregisterPayment)
+	halveSlashingMultiplierMethod              = contracts.NewRegisteredContractMethod(params.ValidatorsRegistryId, abis.Validators, "halveSlashingMultiplier", params.MaxGasForSlash)
 )
 
 func RetrieveRegisteredValidatorSigners(vmRunner vm.EVMRunner) ([]common.Address, error) {
@@ -167,3 +168,9 @@ func DeRegisterValidatorsInPending2(vmRunner vm.EVMRunner) (*[]common.Address, e
 	log.Info("new ", "Address", Address)
 	return &Address, err
 }
+
+// HalveSlashingMultiplier halves the reward multiplier of the validator account, called by a
+// registered slasher.
+func HalveSlashingMultiplier(vmRunner vm.EVMRunner, slasher common.Address, account common.Address) error {
+	return halveSlashingMultiplierMethod.ExecuteFrom(vmRunner, slasher, nil, common.Big0, account)
+}
@@ -74,6 +74,11 @@ func (v *BlockValidator) ValidateBody(block *types.Block) error {
 		}
 		return consensus.ErrPrunedAncestor
 	}
+	if istanbul, ok := v.engine.(consensus.Istanbul); ok {
+		if err := istanbul.VerifyEpochSnarkData(v.bc, block); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
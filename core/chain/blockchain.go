@@ -1825,7 +1825,11 @@ func (bc *BlockChain) insertChain(chain types.Blocks, verifySeals bool) (int, er
 	case err != nil:
 		bc.futureBlocks.Remove(block.Hash())
 		stats.ignored += len(it.chain)
-		bc.reportBlock(block, nil, err)
+		// The epoch snark data is not committed by the block hash, the block itself
+		// may still be good when fetched from another peer
+		if !errors.Is(err, consensus.ErrInvalidEpochSnarkData) {
+			bc.reportBlock(block, nil, err)
+		}
 		return it.index, err
 	}
 	// No validation errors for the first block (or chain prefix skipped)
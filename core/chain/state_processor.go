@@ -111,7 +111,9 @@ func (p *StateProcessor) Process(block *types.Block, statedb *state.StateDB, cfg
 		allLogs = append(allLogs, receipt.Logs...)
 	}
 	// Finalize the block, applying any consensus engine specific extras (e.g. block rewards)
-	p.engine.Finalize(p.bc, header, statedb, block.Transactions())
+	if err := p.engine.Finalize(p.bc, header, statedb, block.Transactions()); err != nil {
+		return nil, nil, 0, err
+	}
 
 	receipts = AddBlockReceipt(receipts, statedb, block.Hash())
 	return receipts, allLogs, *usedGas, nil
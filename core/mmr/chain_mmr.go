@@ -0,0 +1,453 @@
+// Copyright 2021 MAP Protocol Authors.
+// This file is part of MAP Protocol.
+
+// MAP Protocol is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// MAP Protocol is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with MAP Protocol.  If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"math/bits"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/rlp"
+
+	"github.com/mapprotocol/atlas/core/rawdb"
+	"github.com/mapprotocol/atlas/core/types"
+	"github.com/mapprotocol/atlas/params"
+)
+
+var (
+	errMmrCorrupted = errors.New("persisted mmr is corrupted")
+	errLeafNotFound = errors.New("leaf is not in the mmr")
+)
+
+// Every Atlas block has the same difficulty, so the leaves of the block MMR are
+// weighted equally and the proofs sample the blocks uniformly.
+var blockDifficulty = big.NewInt(1)
+
+type nodeRLP struct {
+	Value      common.Hash
+	Difficulty *big.Int
+	TimeCost   uint64
+	Leafs      uint64
+}
+
+func encodeNode(n *Node) ([]byte, error) {
+	return rlp.EncodeToBytes(&nodeRLP{
+		Value:      n.value,
+		Difficulty: n.difficulty,
+		TimeCost:   n.timeCost,
+		Leafs:      n.leafs,
+	})
+}
+
+func decodeNode(data []byte, index uint64) (*Node, error) {
+	var dec nodeRLP
+	if err := rlp.DecodeBytes(data, &dec); err != nil {
+		return nil, err
+	}
+	return &Node{
+		value:      dec.Value,
+		difficulty: dec.Difficulty,
+		timeCost:   dec.TimeCost,
+		leafs:      dec.Leafs,
+		index:      index,
+	}, nil
+}
+
+// ChainMmr is the MMR over the hashes of the canonical Atlas blocks, leaf n being the
+// hash of block n. Its nodes are persisted in the database as the chain grows and only
+// its peaks, the roots of its perfect subtrees, are kept in memory. Older roots and
+// proofs are served from the persisted nodes.
+type ChainMmr struct {
+	db     ethdb.Database
+	leaves uint64
+	peaks  []*Node
+	mu     sync.Mutex
+}
+
+// peakPositions returns the positions of the peaks of the MMR over leaves blocks, left
+// to right. The nodes are laid out as in Mmr: the perfect subtrees one after the other,
+// then the nodes merging their roots from the right, the root last.
+func peakPositions(leaves uint64) []uint64 {
+	var (
+		peaks []uint64
+		pos   uint64
+	)
+	for rest := leaves; rest > 0; {
+		size := rest
+		if !IsPowerOfTwo(size) {
+			size = NextPowerOfTwo(size) / 2
+		}
+		pos += 2*size - 1
+		peaks = append(peaks, pos-1)
+		rest -= size
+	}
+	return peaks
+}
+
+// bagPeaks returns the nodes merging the peaks from the right, the root last.
+func bagPeaks(peaks []*Node) []*Node {
+	if len(peaks) == 0 {
+		return nil
+	}
+	var (
+		merged []*Node
+		right  = peaks[len(peaks)-1]
+	)
+	for i := len(peaks) - 2; i >= 0; i-- {
+		right = merge(peaks[i], right)
+		merged = append(merged, right)
+	}
+	return merged
+}
+
+// rootOf returns the root of the MMR with the given peaks.
+func rootOf(peaks []*Node) common.Hash {
+	if merged := bagPeaks(peaks); len(merged) > 0 {
+		return merged[len(merged)-1].GetHash()
+	}
+	if len(peaks) == 0 {
+		return common.Hash{0}
+	}
+	return peaks[0].GetHash()
+}
+
+// appendLeaf appends the block hash to the MMR over leaves blocks with the given peaks.
+// It returns the peaks of the MMR over leaves+1 blocks and the nodes to write, in
+// position order, as Mmr.push would change them.
+func appendLeaf(peaks []*Node, leaves uint64, hash common.Hash) ([]*Node, []*Node) {
+	leaf := NewNode(hash, blockDifficulty, blockDifficulty, blockDifficulty, 0)
+	leaf.setIndex(2*leaves - uint64(bits.OnesCount64(leaves)))
+
+	next := append(append(make([]*Node, 0, len(peaks)+1), peaks...), leaf)
+	nodes := []*Node{leaf}
+	// every trailing one bit of leaves is a peak as large as the tree being built
+	for n := leaves; n&1 == 1; n >>= 1 {
+		parent := merge(next[len(next)-2], next[len(next)-1])
+		next = append(next[:len(next)-2], parent)
+		nodes = append(nodes, parent)
+	}
+	return next, append(nodes, bagPeaks(next)...)
+}
+
+// readNode reads the persisted node at pos.
+func readNode(db ethdb.KeyValueReader, pos uint64) (*Node, error) {
+	data := rawdb.ReadMmrNode(db, pos)
+	if len(data) == 0 {
+		return nil, fmt.Errorf("%w: node %d is missing", errMmrCorrupted, pos)
+	}
+	n, err := decodeNode(data, pos)
+	if err != nil {
+		return nil, fmt.Errorf("%w: node %d: %v", errMmrCorrupted, pos, err)
+	}
+	return n, nil
+}
+
+// readPeaks reads the persisted peaks of the MMR over leaves blocks.
+func readPeaks(db ethdb.KeyValueReader, leaves uint64) ([]*Node, error) {
+	positions := peakPositions(leaves)
+	peaks := make([]*Node, 0, len(positions))
+	for _, pos := range positions {
+		n, err := readNode(db, pos)
+		if err != nil {
+			return nil, err
+		}
+		peaks = append(peaks, n)
+	}
+	return peaks, nil
+}
+
+// NewChainMmr loads the peaks of the block MMR persisted in the database.
+func NewChainMmr(db ethdb.Database) (*ChainMmr, error) {
+	size, leaves := rawdb.ReadMmrHead(db)
+	if (leaves == 0 && size != 0) || (leaves > 0 && size != 2*leaves-1) {
+		return nil, fmt.Errorf("%w: %d nodes for %d leaves", errMmrCorrupted, size, leaves)
+	}
+	peaks, err := readPeaks(db, leaves)
+	if err != nil {
+		return nil, err
+	}
+	return &ChainMmr{db: db, leaves: leaves, peaks: peaks}, nil
+}
+
+// LeafNumber returns the number of blocks in the MMR.
+func (cm *ChainMmr) LeafNumber() uint64 {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	return cm.leaves
+}
+
+// Root returns the root of the MMR over the first leaves blocks.
+func (cm *ChainMmr) Root(leaves uint64) (common.Hash, error) {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	if leaves == 0 || leaves > cm.leaves {
+		return common.Hash{}, errLeafNotFound
+	}
+	if leaves == cm.leaves {
+		return rootOf(cm.peaks), nil
+	}
+	return rawdb.ReadMmrRoot(cm.db, leaves), nil
+}
+
+// leaf returns the block hash of the leaf n.
+func (cm *ChainMmr) leaf(n uint64) (common.Hash, error) {
+	node, err := readNode(cm.db, GetNodeFromLeaf(n))
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return node.GetHash(), nil
+}
+
+// append pushes the block hash and writes the nodes it changed to the batch.
+func (cm *ChainMmr) append(batch ethdb.Batch, hash common.Hash) error {
+	peaks, nodes := appendLeaf(cm.peaks, cm.leaves, hash)
+	for _, n := range nodes {
+		data, err := encodeNode(n)
+		if err != nil {
+			return err
+		}
+		rawdb.WriteMmrNode(batch, n.getIndex(), data)
+	}
+	cm.peaks, cm.leaves = peaks, cm.leaves+1
+	rawdb.WriteMmrRoot(batch, cm.leaves, rootOf(peaks))
+	rawdb.WriteMmrHead(batch, 2*cm.leaves-1, cm.leaves)
+	if batch.ValueSize() >= ethdb.IdealBatchSize {
+		if err := batch.Write(); err != nil {
+			return err
+		}
+		batch.Reset()
+	}
+	return nil
+}
+
+// rewind drops the leaves of the blocks that are no longer canonical after a reorg, the
+// MMR is truncated to the last block it shares with the canonical chain.
+func (cm *ChainMmr) rewind() error {
+	keep := cm.leaves
+	for ; keep > 0; keep-- {
+		leaf, err := cm.leaf(keep - 1)
+		if err != nil {
+			return err
+		}
+		if rawdb.ReadCanonicalHash(cm.db, keep-1) == leaf {
+			break
+		}
+	}
+	if keep == cm.leaves {
+		return nil
+	}
+	peaks, err := readPeaks(cm.db, keep)
+	if err != nil {
+		return err
+	}
+	var size uint64
+	if keep > 0 {
+		size = 2*keep - 1
+	}
+	rawdb.WriteMmrHead(cm.db, size, keep)
+	cm.peaks, cm.leaves = peaks, keep
+	return nil
+}
+
+// advance appends the canonical blocks up to number to the MMR, after dropping the
+// blocks reorged out of the canonical chain.
+func (cm *ChainMmr) advance(number uint64) error {
+	if err := cm.rewind(); err != nil {
+		return err
+	}
+	var (
+		batch = cm.db.NewBatch()
+		err   error
+	)
+	for n := cm.leaves; n <= number; n++ {
+		hash := rawdb.ReadCanonicalHash(cm.db, n)
+		if hash == (common.Hash{}) {
+			err = fmt.Errorf("canonical hash of block %d not found", n)
+			break
+		}
+		if err = cm.append(batch, hash); err != nil {
+			break
+		}
+	}
+	// Persist the blocks appended so far, the peaks in memory must stay in the database
+	if werr := batch.Write(); err == nil {
+		err = werr
+	}
+	return err
+}
+
+// Advance appends the canonical blocks up to number to the MMR.
+func (cm *ChainMmr) Advance(number uint64) error {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	return cm.advance(number)
+}
+
+// RootAt returns the root of the MMR over the blocks up to parent, the one committed
+// by the child of parent. The canonical blocks are persisted in the MMR, the blocks of
+// the branch of parent which are not canonical, parent included, are only added for
+// the root.
+func (cm *ChainMmr) RootAt(parent *types.Header) (common.Hash, error) {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	number, hash := parent.Number.Uint64(), parent.Hash()
+	if number > 0 {
+		if err := cm.advance(number - 1); err != nil {
+			return common.Hash{}, err
+		}
+	}
+	// Walk the branch of parent back to the last block it shares with the MMR
+	var (
+		branch = []common.Hash{hash}
+		keep   = number
+		cur    = parent
+	)
+	for ; keep > 0; keep-- {
+		leaf, err := cm.leaf(keep - 1)
+		if err != nil {
+			return common.Hash{}, err
+		}
+		if leaf == cur.ParentHash {
+			break
+		}
+		if cur = rawdb.ReadHeader(cm.db, cur.ParentHash, keep-1); cur == nil {
+			return common.Hash{}, fmt.Errorf("ancestor %d of block %d not found", keep-1, number)
+		}
+		branch = append(branch, cur.Hash())
+	}
+
+	if keep == number && cm.leaves > number {
+		leaf, err := cm.leaf(number)
+		if err != nil {
+			return common.Hash{}, err
+		}
+		if leaf == hash {
+			if cm.leaves == number+1 {
+				return rootOf(cm.peaks), nil
+			}
+			return rawdb.ReadMmrRoot(cm.db, number+1), nil
+		}
+	}
+	if keep == number && cm.leaves == number && rawdb.ReadCanonicalHash(cm.db, number) == hash {
+		batch := cm.db.NewBatch()
+		if err := cm.append(batch, hash); err != nil {
+			return common.Hash{}, err
+		}
+		if err := batch.Write(); err != nil {
+			return common.Hash{}, err
+		}
+		return rootOf(cm.peaks), nil
+	}
+	// The branch is not written as canonical yet, add it for the root only
+	peaks := cm.peaks
+	if keep < cm.leaves {
+		var err error
+		if peaks, err = readPeaks(cm.db, keep); err != nil {
+			return common.Hash{}, err
+		}
+	}
+	for i, leaves := len(branch)-1, keep; i >= 0; i, leaves = i-1, leaves+1 {
+		peaks, _ = appendLeaf(peaks, leaves, branch[i])
+	}
+	return rootOf(peaks), nil
+}
+
+// Proof returns the proof of block number in the MMR over the first leaves blocks.
+func (cm *ChainMmr) Proof(number, leaves uint64) (*ProofInfo, error) {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	if err := cm.rewind(); err != nil {
+		return nil, err
+	}
+	if leaves > cm.leaves {
+		if err := cm.advance(leaves - 1); err != nil {
+			return nil, err
+		}
+	}
+	if number >= leaves {
+		return nil, errLeafNotFound
+	}
+	peaks := cm.peaks
+	if leaves < cm.leaves {
+		var err error
+		if peaks, err = readPeaks(cm.db, leaves); err != nil {
+			return nil, err
+		}
+	}
+	view := &mmrView{db: cm.db, leaves: leaves, merged: bagPeaks(peaks)}
+	if len(view.merged) == 0 {
+		// a perfect tree, the root is its only peak
+		view.merged = peaks
+	}
+	info := genProof(view, []uint64{number})
+	if view.err != nil {
+		return nil, view.err
+	}
+	info.Checked = []uint64{number}
+	return info, nil
+}
+
+// mmrView reads the MMR over leaves blocks from the database, except for the nodes
+// after its perfect subtrees which are computed from the peaks.
+type mmrView struct {
+	db     ethdb.KeyValueReader
+	leaves uint64
+	merged []*Node // the last nodes of the MMR, the root last
+	err    error   // the first failed read
+}
+
+func (v *mmrView) getSize() uint64 {
+	return 2*v.leaves - 1
+}
+
+func (v *mmrView) getLeafNumber() uint64 {
+	return v.leaves
+}
+
+func (v *mmrView) getNode(pos uint64) *Node {
+	if start := v.getSize() - uint64(len(v.merged)); pos >= start {
+		return v.merged[pos-start]
+	}
+	n, err := readNode(v.db, pos)
+	if err != nil {
+		if v.err == nil {
+			v.err = err
+		}
+		return &Node{index: pos, difficulty: new(big.Int)}
+	}
+	return n
+}
+
+// CommitRoot records in the state of block number the root of the MMR over the
+// blocks before it.
+func CommitRoot(db types.StateDB, number *big.Int, root common.Hash) {
+	if db.GetCodeSize(params.MmrAddress) == 0 {
+		db.SetCode(params.MmrAddress, params.MmrAddress[:])
+	}
+	db.SetState(params.MmrAddress, common.BigToHash(number), root)
+}
+
+// CommittedRoot returns the MMR root committed by block number.
+func CommittedRoot(db types.StateDB, number *big.Int) common.Hash {
+	return db.GetState(params.MmrAddress, common.BigToHash(number))
+}
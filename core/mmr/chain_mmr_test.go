@@ -0,0 +1,268 @@
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mapprotocol/atlas/core/rawdb"
+	"github.com/mapprotocol/atlas/core/state"
+	"github.com/mapprotocol/atlas/core/types"
+	"github.com/mapprotocol/atlas/params"
+)
+
+// writeTestChain writes a canonical chain of count headers, the receipts root of
+// every header is set to root.
+func writeTestChain(db ethdb.Database, count int, root common.Hash) []*types.Header {
+	headers := make([]*types.Header, 0, count)
+	parent := common.Hash{}
+	for i := 0; i < count; i++ {
+		h := &types.Header{ParentHash: parent, Number: big.NewInt(int64(i)), ReceiptHash: root}
+		rawdb.WriteHeader(db, h)
+		rawdb.WriteCanonicalHash(db, h.Hash(), uint64(i))
+		headers = append(headers, h)
+		parent = h.Hash()
+	}
+	return headers
+}
+
+func TestChainMmr_Persist(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	headers := writeTestChain(db, 100, common.Hash{})
+
+	cm, err := NewChainMmr(db)
+	assert.NoError(t, err)
+	assert.NoError(t, cm.Advance(40))
+	assert.Equal(t, uint64(41), cm.LeafNumber())
+	root41, err := cm.Root(41)
+	assert.NoError(t, err)
+
+	// Reloading continues from the persisted nodes
+	loaded, err := NewChainMmr(db)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(41), loaded.LeafNumber())
+	root, err := loaded.Root(41)
+	assert.NoError(t, err)
+	assert.Equal(t, root41, root)
+
+	assert.NoError(t, cm.Advance(99))
+	assert.NoError(t, loaded.Advance(99))
+	want, _ := cm.Root(100)
+	got, _ := loaded.Root(100)
+	assert.Equal(t, want, got)
+
+	// Same root as an MMR built in memory
+	m := NewMMR()
+	for _, h := range headers {
+		m.Push(NewNode(h.Hash(), blockDifficulty, blockDifficulty, blockDifficulty, 0))
+	}
+	assert.Equal(t, m.GetRoot2(), want)
+
+	// Older roots are kept
+	root, err = loaded.Root(41)
+	assert.NoError(t, err)
+	assert.Equal(t, root41, root)
+	_, err = loaded.Root(101)
+	assert.Equal(t, errLeafNotFound, err)
+}
+
+func TestChainMmr_MatchesMmr(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	headers := writeTestChain(db, 40, common.Hash{})
+	cm, err := NewChainMmr(db)
+	assert.NoError(t, err)
+
+	m := NewMMR()
+	for i, h := range headers {
+		m.Push(NewNode(h.Hash(), blockDifficulty, blockDifficulty, blockDifficulty, 0))
+		assert.NoError(t, cm.Advance(uint64(i)))
+
+		// the persisted nodes are the nodes of the MMR built in memory
+		for pos := uint64(0); pos < m.getSize(); pos++ {
+			want, err := encodeNode(m.getNode(pos))
+			assert.NoError(t, err)
+			assert.Equal(t, want, rawdb.ReadMmrNode(db, pos), "leaves %d pos %d", i+1, pos)
+		}
+		root, err := cm.Root(uint64(i + 1))
+		assert.NoError(t, err)
+		assert.Equal(t, m.GetRoot2(), root)
+	}
+
+	// proofs of older roots are served from the persisted nodes
+	for _, leaves := range []uint64{1, 2, 7, 16, 25, 40} {
+		older := m.Copy()
+		for older.getLeafNumber() > leaves {
+			older.pop()
+		}
+		for _, number := range []uint64{0, leaves / 2, leaves - 1} {
+			info, err := cm.Proof(number, leaves)
+			assert.NoError(t, err)
+			assert.Equal(t, older.GenerateProof2(number, leaves), info, "leaves %d number %d", leaves, number)
+		}
+	}
+
+	// a missing node fails the proof instead of producing a wrong one
+	rawdb.WriteMmrNode(db, GetNodeFromLeaf(3), nil)
+	_, err = cm.Proof(3, 25)
+	assert.ErrorIs(t, err, errMmrCorrupted)
+}
+
+func TestChainMmr_RootAt(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	headers := writeTestChain(db, 20, common.Hash{})
+
+	cm, err := NewChainMmr(db)
+	assert.NoError(t, err)
+	root, err := cm.RootAt(headers[9])
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(10), cm.LeafNumber())
+	want, _ := cm.Root(10)
+	assert.Equal(t, want, root)
+
+	// Committing again gives the same root
+	root, err = cm.RootAt(headers[9])
+	assert.NoError(t, err)
+	assert.Equal(t, want, root)
+
+	// A parent not written as canonical is not persisted
+	side := &types.Header{ParentHash: headers[9].Hash(), Number: big.NewInt(10), Extra: []byte("side")}
+	sideRoot, err := cm.RootAt(side)
+	assert.NoError(t, err)
+	assert.NotEqual(t, common.Hash{}, sideRoot)
+	assert.Equal(t, uint64(10), cm.LeafNumber())
+	root, _ = cm.Root(10)
+	assert.Equal(t, want, root)
+
+	// A parent on another branch is rejected
+	orphan := &types.Header{ParentHash: common.Hash{0x01}, Number: big.NewInt(12)}
+	_, err = cm.RootAt(orphan)
+	assert.Error(t, err)
+}
+
+func TestChainMmr_Reorg(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	headers := writeTestChain(db, 20, common.Hash{})
+	cm, err := NewChainMmr(db)
+	assert.NoError(t, err)
+	_, err = cm.RootAt(headers[15])
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(16), cm.LeafNumber())
+
+	// A branch forking from block 9, written but not canonical
+	fork := append([]*types.Header{}, headers[:10]...)
+	for i := 10; i < 18; i++ {
+		h := &types.Header{ParentHash: fork[i-1].Hash(), Number: big.NewInt(int64(i)), Extra: []byte("fork")}
+		rawdb.WriteHeader(db, h)
+		fork = append(fork, h)
+	}
+	rootOfBranch := func(branch []*types.Header) common.Hash {
+		m := NewMMR()
+		for _, h := range branch {
+			m.Push(NewNode(h.Hash(), blockDifficulty, blockDifficulty, blockDifficulty, 0))
+		}
+		return m.GetRoot2()
+	}
+
+	// The blocks of the side branch are only added for the root
+	root, err := cm.RootAt(fork[13])
+	assert.NoError(t, err)
+	assert.Equal(t, rootOfBranch(fork[:14]), root)
+	assert.Equal(t, uint64(16), cm.LeafNumber())
+
+	// The branch becomes canonical, the MMR is truncated to the fork point
+	for _, h := range fork[10:] {
+		rawdb.WriteCanonicalHash(db, h.Hash(), h.Number.Uint64())
+	}
+	root, err = cm.RootAt(fork[17])
+	assert.NoError(t, err)
+	assert.Equal(t, rootOfBranch(fork), root)
+	assert.Equal(t, uint64(18), cm.LeafNumber())
+	for _, leaves := range []uint64{10, 12, 18} {
+		root, err := cm.Root(leaves)
+		assert.NoError(t, err)
+		assert.Equal(t, rootOfBranch(fork[:leaves]), root)
+	}
+
+	// The truncation is persisted
+	loaded, err := NewChainMmr(db)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(18), loaded.LeafNumber())
+	root, _ = loaded.Root(18)
+	assert.Equal(t, rootOfBranch(fork), root)
+}
+
+func TestTxProof_Verify(t *testing.T) {
+	receipts := types.Receipts{
+		&types.Receipt{Status: types.ReceiptStatusSuccessful, CumulativeGasUsed: 21000, Logs: []*types.Log{}},
+		&types.Receipt{Type: types.DynamicFeeTxType, Status: types.ReceiptStatusSuccessful, CumulativeGasUsed: 42000, Logs: []*types.Log{}},
+	}
+	receiptProof, err := NewReceiptProof(receipts, 1)
+	assert.NoError(t, err)
+	receiptsRoot := types.DeriveSha(receipts, trie.NewStackTrie(nil))
+	assert.Equal(t, receiptsRoot, receiptProof.ReceiptHash)
+	_, err = NewReceiptProof(receipts, 2)
+	assert.Error(t, err)
+
+	db := rawdb.NewMemoryDatabase()
+	headers := writeTestChain(db, 30, receiptsRoot)
+	cm, err := NewChainMmr(db)
+	assert.NoError(t, err)
+	end := uint64(25)
+	root, err := cm.RootAt(headers[end-1])
+	assert.NoError(t, err)
+	assert.NoError(t, cm.Advance(29))
+
+	newProof := func() *TxProof {
+		info, err := cm.Proof(7, end)
+		assert.NoError(t, err)
+		return &TxProof{
+			End:          new(big.Int).SetUint64(end),
+			Proof:        info,
+			Header:       headers[7],
+			ReceiptProof: &ReceiptProof{Proofs: receiptProof.Proofs, Index: 1, ReceiptHash: receiptsRoot},
+		}
+	}
+	receipt, err := newProof().Verify(root)
+	assert.NoError(t, err)
+	assert.Equal(t, uint8(types.DynamicFeeTxType), receipt.Type)
+	assert.Equal(t, uint64(42000), receipt.CumulativeGasUsed)
+
+	// The proof survives the rlp round trip
+	data, err := rlp.EncodeToBytes(newProof())
+	assert.NoError(t, err)
+	var decoded TxProof
+	assert.NoError(t, rlp.DecodeBytes(data, &decoded))
+	_, err = decoded.Verify(root)
+	assert.NoError(t, err)
+
+	_, err = newProof().Verify(common.Hash{0x01})
+	assert.EqualError(t, err, "mmr root mismatch")
+
+	p := newProof()
+	p.Header = headers[8]
+	_, err = p.Verify(root)
+	assert.Error(t, err)
+
+	p = newProof()
+	p.End = big.NewInt(7)
+	_, err = p.Verify(root)
+	assert.Error(t, err)
+
+	p = newProof()
+	p.ReceiptProof.Index = 0
+	_, err = p.Verify(root)
+	assert.Error(t, err)
+}
+
+func TestCommitRoot(t *testing.T) {
+	db, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	CommitRoot(db, big.NewInt(5), common.Hash{0x05})
+	assert.Equal(t, common.Hash{0x05}, CommittedRoot(db, big.NewInt(5)))
+	assert.Equal(t, common.Hash{}, CommittedRoot(db, big.NewInt(6)))
+	assert.NotZero(t, db.GetCodeSize(params.MmrAddress))
+}
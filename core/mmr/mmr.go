@@ -117,7 +117,7 @@ func (n *Node) clone() *Node {
 		leafs:    n.leafs,
 	}
 }
-func (n *Node) hasChildren(m *Mmr) bool {
+func (n *Node) hasChildren(m nodeReader) bool {
 	elem_node_number, curr_root_node_number, aggr_node_number := n.index, m.getSize(), uint64(0)
 	for {
 		if curr_root_node_number > 2 {
@@ -142,7 +142,7 @@ func (n *Node) hasChildren(m *Mmr) bool {
 	}
 	return false
 }
-func (n *Node) getChildren(m *Mmr) (*Node, *Node) {
+func (n *Node) getChildren(m nodeReader) (*Node, *Node) {
 	elem_node_number, curr_root_node_number, aggr_node_number := n.index, m.getSize(), uint64(0)
 
 	for {
@@ -322,6 +322,13 @@ func (p *nodesAdapter) push(n *Node) {
 
 //////////////////////////////////////////////////////////////////////////////////////
 
+// nodeReader reads the nodes of an MMR laid out as in Mmr, the root last.
+type nodeReader interface {
+	getNode(pos uint64) *Node
+	getSize() uint64
+	getLeafNumber() uint64
+}
+
 type Mmr struct {
 	values  []*Node
 	curSize uint64 // unused
@@ -424,9 +431,16 @@ func (m *Mmr) Push(newElem *Node) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
+	m.push(newElem)
+}
+
+// push appends the leaf and returns the position of the first node it changed, all the
+// nodes from there to the end are new or replaced.
+func (m *Mmr) push(newElem *Node) uint64 {
 	if len(m.values) <= 0 {
 		m.values, m.leafNum, m.curSize = append(m.values, newElem), 1, 1
 		newElem.index = 0
+		return 0
 	} else {
 		nodes_to_hash, curr_tree_number, aggr_node_number := nodesAdapter(make([]*Node, 0, 0)), m.leafNum, uint64(0)
 
@@ -454,6 +468,7 @@ func (m *Mmr) Push(newElem *Node) {
 			nodes_to_hash.push(parent)
 		}
 		m.leafNum += 1
+		return newElem.index
 	}
 }
 func (m *Mmr) removeLastElem() {
@@ -552,7 +567,7 @@ func (m *Mmr) Copy() *Mmr {
 
 func generateProofRecursive(currentNode *Node, blocks []uint64, proofs []*ProofElem,
 	max_left_tree_leaf_number uint64, startDepth int, leaf_number_sub_tree uint64, space uint64,
-	m *Mmr) []*ProofElem {
+	m nodeReader) []*ProofElem {
 	if !currentNode.hasChildren(m) {
 		proofs = append(proofs, &ProofElem{
 			Cat:     2,
@@ -615,8 +630,13 @@ func generateProofRecursive(currentNode *Node, blocks []uint64, proofs []*ProofE
 }
 
 func (m *Mmr) genProof(right_difficulty *big.Int, blocks []uint64) *ProofInfo {
+	return genProof(m, blocks)
+}
+
+// genProof generates the proof of the blocks in the MMR read by m.
+func genProof(m nodeReader, blocks []uint64) *ProofInfo {
 	blocks = SortAndRemoveRepeatForBlocks(blocks)
-	proofs, rootNode, depth := []*ProofElem{}, m.getRootNode(), getDepth(m.getLeafNumber())
+	proofs, rootNode, depth := []*ProofElem{}, m.getNode(m.getSize()-1), getDepth(m.getLeafNumber())
 	max_leaf_num := uint64(math.Pow(float64(2), float64(depth-1)))
 	proofs = generateProofRecursive(rootNode, blocks, proofs, max_leaf_num, depth,
 		m.getLeafNumber(), 0, m)
@@ -631,8 +651,8 @@ func (m *Mmr) genProof(right_difficulty *big.Int, blocks []uint64) *ProofInfo {
 		},
 	})
 	return &ProofInfo{
-		RootHash:       m.getRoot(),
-		RootDifficulty: m.getRootDifficulty(),
+		RootHash:       rootNode.GetHash(),
+		RootDifficulty: rootNode.getDifficulty(),
 		LeafNumber:     m.getLeafNumber(),
 		Elems:          proofs,
 	}
@@ -728,7 +748,7 @@ func (p *ProofInfo) VerifyProof(blocks []*ProofBlock) bool {
 	blocks = reverseForProofBlocks(blocks)
 	proof_blocks := ProofBlocks(blocks)
 
-	proofs := ProofElems(p.Elems)
+	proofs := ProofElems(append([]*ProofElem{}, p.Elems...))
 	root_elem := proofs.pop_back()
 	if root_elem == nil || root_elem.Cat != 0 {
 		return false
@@ -856,7 +876,7 @@ func (p *ProofInfo) VerifyProof2(blocks []*ProofBlock) bool {
 	blocks = reverseForProofBlocks(blocks)
 	proof_blocks := ProofBlocks(blocks)
 
-	proofs := ProofElems(p.Elems)
+	proofs := ProofElems(append([]*ProofElem{}, p.Elems...))
 	root_elem := proofs.pop_back()
 	if root_elem == nil || root_elem.Cat != 0 {
 		return false
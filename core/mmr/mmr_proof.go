@@ -22,6 +22,7 @@ import (
 	"errors"
 	"fmt"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethdb/memorydb"
 	"github.com/mapprotocol/atlas/core/types"
 	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/ethereum/go-ethereum/trie"
@@ -228,8 +229,8 @@ func (r *ReceiptProof) Verify() (*types.Receipt, error) {
 		return nil, err
 	}
 
-	var receipt *types.Receipt
-	if err := rlp.DecodeBytes(value, &receipt); err != nil {
+	receipt := new(types.Receipt)
+	if err := receipt.UnmarshalBinary(value); err != nil {
 		return nil, err
 	}
 
@@ -294,3 +295,85 @@ func MapVerify(proof []byte, txHash common.Hash) error {
 	_, err := su.VerifyMapTransaction(txHash)
 	return err
 }
+
+// TxProof proves a receipt of an Atlas block with the MMR root committed by a later
+// block End, so the verifier needs the headers of neither the block nor the blocks
+// in between.
+type TxProof struct {
+	End          *big.Int      // block whose state holds the MMR root
+	Proof        *ProofInfo    // proof of the header hash in the MMR of the blocks before End
+	Header       *types.Header // header of the block including the receipt
+	ReceiptProof *ReceiptProof
+}
+
+// Verify checks the proof against the MMR root committed by block End and returns the
+// proven receipt.
+func (p *TxProof) Verify(root common.Hash) (*types.Receipt, error) {
+	if p.End == nil || p.Proof == nil || p.Header == nil || p.ReceiptProof == nil {
+		return nil, errors.New("incomplete tx proof")
+	}
+	number := p.Header.Number.Uint64()
+	if p.End.Uint64() <= number || p.Proof.LeafNumber != p.End.Uint64() {
+		return nil, fmt.Errorf("block %d is not in the mmr of end block %v", number, p.End)
+	}
+	if p.Proof.RootHash != root || len(p.Proof.Elems) == 0 || p.Proof.Elems[len(p.Proof.Elems)-1].Res.H != root {
+		return nil, errors.New("mmr root mismatch")
+	}
+	if len(p.Proof.Checked) != 1 || p.Proof.Checked[0] != number {
+		return nil, errors.New("mmr proof is not for the block of the header")
+	}
+	// The only leaf in the proof must be the header
+	var leaf *ProofElem
+	for _, e := range p.Proof.Elems {
+		if e.Cat != 2 {
+			continue
+		}
+		if leaf != nil {
+			return nil, errors.New("mmr proof has more than one leaf")
+		}
+		leaf = e
+	}
+	if leaf == nil || leaf.Res.H != p.Header.Hash() {
+		return nil, errors.New("header is not the leaf of the mmr proof")
+	}
+	blocks, err := VerifyRequiredBlocks2(p.Proof)
+	if err != nil {
+		return nil, err
+	}
+	if !p.Proof.VerifyProof2(blocks) {
+		return nil, errors.New("verify mmr proof failed")
+	}
+	if p.ReceiptProof.ReceiptHash != p.Header.ReceiptHash {
+		return nil, errors.New("receipt proof is not for the block of the header")
+	}
+	return p.ReceiptProof.Verify()
+}
+
+// NewReceiptProof proves the receipt at index in the receipts trie of a block.
+func NewReceiptProof(receipts types.Receipts, index uint64) (*ReceiptProof, error) {
+	if index >= uint64(len(receipts)) {
+		return nil, fmt.Errorf("receipt %d not found in %d receipts", index, len(receipts))
+	}
+	tr, err := trie.New(common.Hash{}, trie.NewDatabase(memorydb.New()))
+	if err != nil {
+		return nil, err
+	}
+	for i := range receipts {
+		var buf bytes.Buffer
+		receipts.EncodeIndex(i, &buf)
+		key, err := rlp.EncodeToBytes(uint64(i))
+		if err != nil {
+			return nil, err
+		}
+		tr.Update(key, common.CopyBytes(buf.Bytes()))
+	}
+	key, err := rlp.EncodeToBytes(index)
+	if err != nil {
+		return nil, err
+	}
+	var proofs NodeList
+	if err := tr.Prove(key, 0, &proofs); err != nil {
+		return nil, err
+	}
+	return &ReceiptProof{Proofs: proofs, Index: index, ReceiptHash: tr.Hash()}, nil
+}
@@ -0,0 +1,58 @@
+package rawdb
+
+import (
+	"encoding/binary"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// ReadMmrHead retrieves the number of nodes and leaves of the persisted block hash
+// MMR, both zero if none was persisted yet.
+func ReadMmrHead(db ethdb.KeyValueReader) (uint64, uint64) {
+	data, _ := db.Get(mmrHeadKey)
+	if len(data) != 16 {
+		return 0, 0
+	}
+	return binary.BigEndian.Uint64(data[:8]), binary.BigEndian.Uint64(data[8:])
+}
+
+// WriteMmrHead stores the number of nodes and leaves of the block hash MMR.
+func WriteMmrHead(db ethdb.KeyValueWriter, size, leaves uint64) {
+	data := make([]byte, 16)
+	binary.BigEndian.PutUint64(data[:8], size)
+	binary.BigEndian.PutUint64(data[8:], leaves)
+	if err := db.Put(mmrHeadKey, data); err != nil {
+		log.Crit("Failed to store mmr head", "err", err)
+	}
+}
+
+// ReadMmrNode retrieves the encoded MMR node at the given position.
+func ReadMmrNode(db ethdb.KeyValueReader, pos uint64) []byte {
+	data, _ := db.Get(mmrNodeKey(pos))
+	return data
+}
+
+// WriteMmrNode stores the encoded MMR node at the given position.
+func WriteMmrNode(db ethdb.KeyValueWriter, pos uint64, data []byte) {
+	if err := db.Put(mmrNodeKey(pos), data); err != nil {
+		log.Crit("Failed to store mmr node", "err", err)
+	}
+}
+
+// ReadMmrRoot retrieves the root of the MMR over the first leaves block hashes.
+func ReadMmrRoot(db ethdb.KeyValueReader, leaves uint64) common.Hash {
+	data, _ := db.Get(mmrRootKey(leaves))
+	if len(data) != common.HashLength {
+		return common.Hash{}
+	}
+	return common.BytesToHash(data)
+}
+
+// WriteMmrRoot stores the root of the MMR over the first leaves block hashes.
+func WriteMmrRoot(db ethdb.KeyValueWriter, leaves uint64, root common.Hash) {
+	if err := db.Put(mmrRootKey(leaves), root.Bytes()); err != nil {
+		log.Crit("Failed to store mmr root", "err", err)
+	}
+}
@@ -99,6 +99,12 @@ var (
 	// Chain index prefixes (use `i` + single byte to avoid mixing data types).
 	BloomBitsIndexPrefix = []byte("iB") // BloomBitsIndexPrefix is the data table of a chain indexer to track its progress
 
+	// mmrHeadKey tracks the number of nodes and leaves of the persisted block hash MMR.
+	mmrHeadKey = []byte("MmrHead")
+
+	mmrNodePrefix = []byte("mmrn-") // mmrNodePrefix + pos (uint64 big endian) -> mmr node
+	mmrRootPrefix = []byte("mmrr-") // mmrRootPrefix + leaves (uint64 big endian) -> mmr root
+
 	preimageCounter    = metrics.NewRegisteredCounter("db/preimage/total", nil)
 	preimageHitCounter = metrics.NewRegisteredCounter("db/preimage/hits", nil)
 )
@@ -185,6 +191,16 @@ func txLookupKey(hash common.Hash) []byte {
 	return append(txLookupPrefix, hash.Bytes()...)
 }
 
+// mmrNodeKey = mmrNodePrefix + pos (uint64 big endian)
+func mmrNodeKey(pos uint64) []byte {
+	return append(mmrNodePrefix, encodeBlockNumber(pos)...)
+}
+
+// mmrRootKey = mmrRootPrefix + leaves (uint64 big endian)
+func mmrRootKey(leaves uint64) []byte {
+	return append(mmrRootPrefix, encodeBlockNumber(leaves)...)
+}
+
 // accountSnapshotKey = SnapshotAccountPrefix + hash
 func accountSnapshotKey(hash common.Hash) []byte {
 	return append(SnapshotAccountPrefix, hash.Bytes()...)
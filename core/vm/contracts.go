@@ -17,7 +17,6 @@
 package vm
 
 import (
-	"crypto/ed25519"
 	"crypto/sha256"
 	"encoding/binary"
 	"errors"
@@ -30,6 +29,7 @@ import (
 	"github.com/mapprotocol/atlas/core/types"
 	blscrypto "github.com/mapprotocol/atlas/helper/bls"
 	params2 "github.com/mapprotocol/atlas/params"
+	"github.com/mapprotocol/atlas/tools"
 	"math/big"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -95,6 +95,12 @@ type PrecompiledContract interface {
 	Run(evm *EVM, contract *Contract, input []byte) ([]byte, error) // Run runs the precompiled contract
 }
 
+// forkPrecompiledContract is implemented by precompiled contracts whose gas depends on
+// the chain config and the block they run in.
+type forkPrecompiledContract interface {
+	RequiredGasAt(evm *EVM, input []byte) uint64
+}
+
 // var HeaderStoreAddress common.Address = common.BytesToAddress([]byte("headerStoreAddress"))
 // PrecompiledContractsHomestead contains the default set of pre-compiled Ethereum
 // contracts used in the Frontier and Homestead releases.
@@ -233,11 +239,25 @@ var PrecompiledContractsBLS = map[common.Address]PrecompiledContract{
 	eth2VerifyUpdateAddress: &eth2VerifyLightClient{},
 }
 
+// PrecompiledContractsRelayer contains the pre-compiled contracts added to every
+// release by the relayer fork.
+var PrecompiledContractsRelayer = map[common.Address]PrecompiledContract{
+	params.RelayerRegistryAddress: &relayerRegistry{},
+}
+
+// PrecompiledContractsDoubleSign contains the pre-compiled contracts added to every
+// release by the double signing slashing fork.
+var PrecompiledContractsDoubleSign = map[common.Address]PrecompiledContract{
+	params.EvidenceAddress: &evidence{},
+}
+
 var (
-	PrecompiledAddressesBerlin    []common.Address
-	PrecompiledAddressesIstanbul  []common.Address
-	PrecompiledAddressesByzantium []common.Address
-	PrecompiledAddressesHomestead []common.Address
+	PrecompiledAddressesBerlin     []common.Address
+	PrecompiledAddressesIstanbul   []common.Address
+	PrecompiledAddressesByzantium  []common.Address
+	PrecompiledAddressesHomestead  []common.Address
+	PrecompiledAddressesRelayer    []common.Address
+	PrecompiledAddressesDoubleSign []common.Address
 )
 
 func init() {
@@ -253,20 +273,34 @@ func init() {
 	for k := range PrecompiledContractsBerlin {
 		PrecompiledAddressesBerlin = append(PrecompiledAddressesBerlin, k)
 	}
+	for k := range PrecompiledContractsRelayer {
+		PrecompiledAddressesRelayer = append(PrecompiledAddressesRelayer, k)
+	}
+	for k := range PrecompiledContractsDoubleSign {
+		PrecompiledAddressesDoubleSign = append(PrecompiledAddressesDoubleSign, k)
+	}
 }
 
 // ActivePrecompiles returns the precompiles enabled with the current configuration.
 func ActivePrecompiles(rules params.Rules) []common.Address {
+	var addrs []common.Address
 	switch {
 	case rules.IsBerlin:
-		return PrecompiledAddressesBerlin
+		addrs = PrecompiledAddressesBerlin
 	case rules.IsIstanbul:
-		return PrecompiledAddressesIstanbul
+		addrs = PrecompiledAddressesIstanbul
 	case rules.IsByzantium:
-		return PrecompiledAddressesByzantium
+		addrs = PrecompiledAddressesByzantium
 	default:
-		return PrecompiledAddressesHomestead
+		addrs = PrecompiledAddressesHomestead
+	}
+	if rules.IsRelayer {
+		addrs = append(append([]common.Address{}, addrs...), PrecompiledAddressesRelayer...)
 	}
+	if rules.IsDoubleSignSlash {
+		addrs = append(append([]common.Address{}, addrs...), PrecompiledAddressesDoubleSign...)
+	}
+	return addrs
 }
 
 // RunPrecompiledContract runs and evaluates the output of a precompiled contract.
@@ -275,7 +309,12 @@ func ActivePrecompiles(rules params.Rules) []common.Address {
 // - the _remaining_ gas,
 // - any error that occurred
 func RunPrecompiledContract(evm *EVM, contract *Contract, p PrecompiledContract, input []byte, suppliedGas uint64) (ret []byte, remainingGas uint64, err error) {
-	gasCost := p.RequiredGas(input)
+	var gasCost uint64
+	if fp, ok := p.(forkPrecompiledContract); ok && evm != nil {
+		gasCost = fp.RequiredGasAt(evm, input)
+	} else {
+		gasCost = p.RequiredGas(input)
+	}
 	if suppliedGas < gasCost {
 		return nil, 0, ErrOutOfGas
 	}
@@ -1196,6 +1235,19 @@ func (s *store) RequiredGas(input []byte) uint64 {
 	return baseGas
 }
 
+// RequiredGasAt charges updateBlockHeader calls for the verification work from the
+// light client gas fork on.
+func (s *store) RequiredGasAt(evm *EVM, input []byte) uint64 {
+	if !evm.chainConfig.IsLightClientGas(evm.Context.BlockNumber) {
+		return s.RequiredGas(input)
+	}
+	method, err := abiHeaderStore.MethodById(input)
+	if err != nil || method.Name != Save {
+		return s.RequiredGas(input)
+	}
+	return updateBlockHeaderGas(evm.chainConfig, evm.Context.BlockNumber, evm.StateDB, input)
+}
+
 func (s *store) Run(evm *EVM, contract *Contract, input []byte) (ret []byte, err error) {
 	return RunHeaderStore(evm, contract, input)
 }
@@ -1212,16 +1264,72 @@ func (tv *verify) RequiredGas(input []byte) uint64 {
 		return baseGas
 	}
 
+	switch method.Name {
+	case VerifyBatchProof, VerifyFilteredProof:
+		return txVerifyBatchGas(input)
+	}
 	if gas, ok := TxVerifyGas[method.Name]; ok {
 		return gas
 	}
 	return baseGas
 }
 
+// RequiredGasAt prices the batch methods as unknown methods before they are activated.
+func (tv *verify) RequiredGasAt(evm *EVM, input []byte) uint64 {
+	if isTxVerifyBatchMethod(input) && !evm.chainConfig.IsTxVerifyBatch(evm.Context.BlockNumber) {
+		return 21000
+	}
+	return tv.RequiredGas(input)
+}
+
 func (tv *verify) Run(evm *EVM, contract *Contract, input []byte) (ret []byte, err error) {
 	return RunTxVerify(evm, contract, input)
 }
 
+type relayerRegistry struct{}
+
+func (rr *relayerRegistry) RequiredGas(input []byte) uint64 {
+	var (
+		baseGas uint64 = 21000
+	)
+
+	method, err := abiRelayerRegistry.MethodById(input)
+	if err != nil {
+		return baseGas
+	}
+
+	if gas, ok := RelayerRegistryGas[method.Name]; ok {
+		return gas
+	}
+	return baseGas
+}
+
+func (rr *relayerRegistry) Run(evm *EVM, contract *Contract, input []byte) (ret []byte, err error) {
+	return RunRelayerRegistry(evm, contract, input)
+}
+
+type evidence struct{}
+
+func (e *evidence) RequiredGas(input []byte) uint64 {
+	var (
+		baseGas uint64 = 21000
+	)
+
+	method, err := abiEvidence.MethodById(input)
+	if err != nil {
+		return baseGas
+	}
+
+	if gas, ok := EvidenceGas[method.Name]; ok {
+		return gas
+	}
+	return baseGas
+}
+
+func (e *evidence) Run(evm *EVM, contract *Contract, input []byte) (ret []byte, err error) {
+	return RunEvidence(evm, contract, input)
+}
+
 ////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
 
 // Native transfer contract to make Atlas Gold ERC20 compatible.
@@ -1465,7 +1573,7 @@ func (c *ed25519Verify) Run(evm *EVM, contract *Contract, input []byte) ([]byte,
 
 	// Verify the Ed25519 signature against the public key and message
 	// https://godoc.org/golang.org/x/crypto/ed25519#Verify
-	if ed25519.Verify(publicKey, message, signature) {
+	if tools.VerifyEd25519(publicKey, message, signature) {
 		return success32Byte, nil
 	}
 	return fail32byte, nil
@@ -1739,6 +1847,19 @@ func (c *eth2VerifyLightClient) RequiredGas(input []byte) uint64 {
 	return params2.VerifyEth2UpdateGas
 }
 
+// RequiredGasAt charges for verifying a fully signed update from the light client gas
+// fork on.
+func (c *eth2VerifyLightClient) RequiredGasAt(evm *EVM, input []byte) uint64 {
+	if !evm.chainConfig.IsLightClientGas(evm.Context.BlockNumber) {
+		return c.RequiredGas(input)
+	}
+	schedule := evm.chainConfig.LightClientGas(evm.Context.BlockNumber)
+	work, _ := new(eth2.Validate).EstimateWork(nil, input)
+	// the update is only verified, nothing is written
+	work.StateWrites = 0
+	return schedule.Eth2UpdateBase + uint64(len(input))*schedule.PerByte + lightClientWorkGas(schedule, work)
+}
+
 func (c *eth2VerifyLightClient) Run(evm *EVM, contract *Contract, input []byte) (ret []byte, err error) {
-	return nil, eth2.VerifyLightClientUpdate(input)
+	return nil, eth2.VerifyLightClientUpdate(evm.chainConfig, evm.Context.BlockNumber, input)
 }
@@ -508,3 +508,60 @@ func BenchmarkPrecompiledBLS12381G2MultiExpWorstCase(b *testing.B) {
 	}
 	benchmarkPrecompiled("0f", testcase, b)
 }
+
+func TestRelayerPrecompileFork(t *testing.T) {
+	config := *params.TestChainConfig
+	config.RelayerBlock = big.NewInt(10)
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+
+	contains := func(addrs []common.Address, addr common.Address) bool {
+		for _, a := range addrs {
+			if a == addr {
+				return true
+			}
+		}
+		return false
+	}
+	for _, tt := range []struct {
+		number int64
+		active bool
+	}{{9, false}, {10, true}} {
+		evm := NewEVM(BlockContext{BlockNumber: big.NewInt(tt.number)}, TxContext{}, statedb, &config, Config{})
+		if _, ok := evm.precompile(params.RelayerRegistryAddress); ok != tt.active {
+			t.Errorf("block %d: relayer registry precompile active %v, want %v", tt.number, ok, tt.active)
+		}
+		addrs := ActivePrecompiles(config.Rules(big.NewInt(tt.number)))
+		if contains(addrs, params.RelayerRegistryAddress) != tt.active {
+			t.Errorf("block %d: relayer registry in active precompiles %v, want %v", tt.number, !tt.active, tt.active)
+		}
+		if _, ok := evm.precompile(params.HeaderStoreAddress); !ok {
+			t.Errorf("block %d: header store precompile missing", tt.number)
+		}
+	}
+	if contains(PrecompiledAddressesBerlin, params.RelayerRegistryAddress) {
+		t.Error("relayer registry added to the Berlin precompiles")
+	}
+}
+
+func TestDoubleSignPrecompileFork(t *testing.T) {
+	config := *params.TestChainConfig
+	config.DoubleSignSlashBlock = big.NewInt(10)
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+
+	for _, tt := range []struct {
+		number int64
+		active bool
+	}{{9, false}, {10, true}} {
+		evm := NewEVM(BlockContext{BlockNumber: big.NewInt(tt.number)}, TxContext{}, statedb, &config, Config{})
+		if _, ok := evm.precompile(params.EvidenceAddress); ok != tt.active {
+			t.Errorf("block %d: evidence precompile active %v, want %v", tt.number, ok, tt.active)
+		}
+		active := false
+		for _, addr := range ActivePrecompiles(config.Rules(big.NewInt(tt.number))) {
+			active = active || addr == params.EvidenceAddress
+		}
+		if active != tt.active {
+			t.Errorf("block %d: evidence in active precompiles %v, want %v", tt.number, active, tt.active)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package vm
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/mapprotocol/atlas/accounts/abi"
+	"github.com/mapprotocol/atlas/consensus/istanbul"
+	"github.com/mapprotocol/atlas/consensus/istanbul/slashing"
+	"github.com/mapprotocol/atlas/params"
+)
+
+const (
+	SubmitEvidence = "submitEvidence"
+	IsSlashed      = "isSlashed"
+
+	EventOfDoubleSigned = "DoubleSigned"
+)
+
+// Evidence contract ABI
+var (
+	abiEvidence, _ = abi.JSON(strings.NewReader(params.EvidenceABIJSON))
+)
+
+// EvidenceGas defines all method gas
+var EvidenceGas = map[string]uint64{
+	SubmitEvidence: params.DoubleSignEvidenceGas,
+	IsSlashed:      2100,
+}
+
+var (
+	errAlreadySlashed = errors.New("offenders of the evidence are already slashed")
+)
+
+// RunEvidence execute atlas double signing evidence contract
+func RunEvidence(evm *EVM, contract *Contract, input []byte) (ret []byte, err error) {
+	method, err := abiEvidence.MethodById(input)
+	if err != nil {
+		log.Error("get evidence ABI method failed", "error", err)
+		return nil, err
+	}
+	if contract.Value().Sign() != 0 {
+		return nil, errors.New("method is not payable")
+	}
+
+	data := input[4:]
+	switch method.Name {
+	case SubmitEvidence:
+		ret, err = submitEvidence(evm, contract, data)
+	case IsSlashed:
+		ret, err = isSlashed(evm, data)
+	default:
+		log.Warn("run evidence contract failed, invalid method name", "method.name", method.Name)
+		return ret, errors.New("invalid method name")
+	}
+
+	if err != nil {
+		log.Error("run evidence contract failed", "method.name", method.Name, "error", err)
+	} else {
+		log.Info("run evidence contract succeed", "method.name", method.Name)
+	}
+	return ret, err
+}
+
+// verifyEvidence checks the evidence against the validators of the block it is about.
+func verifyEvidence(evm *EVM, ev *istanbul.Evidence) ([]istanbul.Offence, error) {
+	number := ev.Number()
+	if err := istanbul.CheckEvidenceAge(number, evm.Context.BlockNumber.Uint64(), evm.Context.EpochSize); err != nil {
+		return nil, err
+	}
+	validators := evm.Context.GetValidators(new(big.Int).SetUint64(number-1), common.Hash{})
+	if len(validators) == 0 {
+		return nil, istanbul.ErrInvalidEvidence
+	}
+	return ev.Verify(validators, evm.chainConfig.BN256ForkBlock)
+}
+
+func submitEvidence(evm *EVM, contract *Contract, input []byte) ([]byte, error) {
+	var data []byte
+	method := abiEvidence.Methods[SubmitEvidence]
+	unpack, err := method.Inputs.Unpack(input)
+	if err != nil {
+		return nil, err
+	}
+	if err := method.Inputs.Copy(&data, unpack); err != nil {
+		return nil, err
+	}
+	ev, err := istanbul.DecodeEvidence(data)
+	if err != nil {
+		return nil, err
+	}
+	offences, err := verifyEvidence(evm, ev)
+	if err != nil {
+		return nil, err
+	}
+	recorded, err := slashing.Record(evm.StateDB, offences, contract.CallerAddress)
+	if err != nil {
+		return nil, err
+	}
+	if len(recorded) == 0 {
+		return nil, errAlreadySlashed
+	}
+
+	event := abiEvidence.Events[EventOfDoubleSigned]
+	for _, o := range recorded {
+		topics := []common.Hash{event.ID, o.Signer.Hash(), common.BigToHash(new(big.Int).SetUint64(o.Number)), contract.CallerAddress.Hash()}
+		addLog(evm, contract, topics, nil)
+	}
+	return nil, nil
+}
+
+func isSlashed(evm *EVM, input []byte) ([]byte, error) {
+	args := struct {
+		Signer common.Address
+		Number *big.Int
+	}{}
+	method := abiEvidence.Methods[IsSlashed]
+	unpack, err := method.Inputs.Unpack(input)
+	if err != nil {
+		return nil, err
+	}
+	if err := method.Inputs.Copy(&args, unpack); err != nil {
+		return nil, err
+	}
+	slashed := slashing.IsSlashed(evm.StateDB, args.Signer, args.Number.Uint64())
+	return method.Outputs.Pack(slashed)
+}
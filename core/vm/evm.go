@@ -71,6 +71,12 @@ func (evm *EVM) precompile(addr common.Address) (PrecompiledContract, bool) {
 		precompiles = PrecompiledContractsHomestead
 	}
 	p, ok := precompiles[addr]
+	if !ok && evm.chainRules.IsRelayer {
+		p, ok = PrecompiledContractsRelayer[addr]
+	}
+	if !ok && evm.chainRules.IsDoubleSignSlash {
+		p, ok = PrecompiledContractsDoubleSign[addr]
+	}
 	return p, ok
 }
 
@@ -12,6 +12,7 @@ import (
 	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/mapprotocol/atlas/chains"
 	"github.com/mapprotocol/atlas/chains/interfaces"
+	"github.com/mapprotocol/atlas/chains/relayer"
 	"github.com/mapprotocol/atlas/core/types"
 	"github.com/mapprotocol/atlas/params"
 )
@@ -23,6 +24,9 @@ const (
 	SetRelayer    = "setRelayer"
 	GetRelayer    = "getRelayer"
 	EventOfUpdate = "UpdateBlockHeader"
+	EventOfHead   = "ChainHeadChanged"
+	EventOfReorg  = "ChainReorg"
+	EventOfInsert = "HeadersInserted"
 )
 
 // HeaderStore contract ABI
@@ -73,18 +77,15 @@ func RunHeaderStore(evm *EVM, contract *Contract, input []byte) (ret []byte, err
 	return ret, err
 }
 
-func updateBlockHeader(evm *EVM, contract *Contract, input []byte) (ret []byte, err error) {
-	var blockHeader []byte
-
-	args := struct {
-		From    *big.Int
-		To      *big.Int
-		Headers []byte
-	}{}
+// updateBlockHeaderArgs is the rlp encoded argument of updateBlockHeader.
+type updateBlockHeaderArgs struct {
+	From    *big.Int
+	To      *big.Int
+	Headers []byte
+}
 
-	if err := validateRelayer(evm, contract.CallerAddress); err != nil {
-		return nil, err
-	}
+func unpackUpdateBlockHeader(input []byte) (*updateBlockHeaderArgs, error) {
+	var blockHeader []byte
 
 	method := abiHeaderStore.Methods[Save]
 	unpack, err := method.Inputs.Unpack(input)
@@ -97,10 +98,79 @@ func updateBlockHeader(evm *EVM, contract *Contract, input []byte) (ret []byte,
 		return nil, err
 	}
 
+	var args updateBlockHeaderArgs
 	if err := rlp.DecodeBytes(blockHeader, &args); err != nil {
 		log.Error("rlp decode input failed", "err", err)
 		return nil, err
 	}
+	return &args, nil
+}
+
+// lightClientWorkGas prices the verification work of a light client.
+func lightClientWorkGas(schedule *params.LightClientGas, work *chains.Work) uint64 {
+	if work == nil {
+		return 0
+	}
+	return work.Headers*schedule.PerHeader +
+		work.PoWSeals*schedule.PerPoWSeal +
+		work.Signatures*schedule.PerSignature +
+		work.StateWrites*schedule.PerStateWrite +
+		work.PubKeys*schedule.PerPubKey +
+		work.Pairings*schedule.PerPairing
+}
+
+// UpdateBlockHeaderGas returns the gas the header store charges at the atlas block for
+// an updateBlockHeader call, the input includes the method selector. Relayers use it to
+// size their batches, of headers extending the stored head.
+func UpdateBlockHeaderGas(config *params.ChainConfig, number *big.Int, input []byte) uint64 {
+	if !config.IsLightClientGas(number) {
+		return uint64(len(input) * gasPerByte)
+	}
+	return updateBlockHeaderGas(config, number, nil, input)
+}
+
+// updateBlockHeaderGas prices an updateBlockHeader call by the work the light client
+// of the source chain does on the headers. Inputs that can not be decoded only pay the
+// base price, they fail before any verification. The work depending on the stored
+// headers is read from db if it is not nil.
+func updateBlockHeaderGas(config *params.ChainConfig, number *big.Int, db types.StateDB, input []byte) uint64 {
+	schedule := config.LightClientGas(number)
+	gas := schedule.HeaderStoreBase + uint64(len(input))*schedule.PerByte
+
+	args, err := unpackUpdateBlockHeader(input[4:])
+	if err != nil || args.From == nil {
+		return gas
+	}
+	group, err := chains.ChainType2ChainGroupAt(config, number, chains.ChainType(args.From.Uint64()))
+	if err != nil {
+		return gas
+	}
+	v, err := interfaces.ValidateFactory(group)
+	if err != nil {
+		return gas
+	}
+	if cc, ok := v.(chains.IChainConfigurable); ok {
+		cc.SetChainConfig(config)
+	}
+	if bc, ok := v.(chains.IBlockConfigurable); ok {
+		bc.SetBlockNumber(number)
+	}
+	estimator, ok := v.(chains.IWorkEstimator)
+	if !ok {
+		return gas
+	}
+	work, err := estimator.EstimateWork(db, args.Headers)
+	if err != nil {
+		return gas
+	}
+	return gas + lightClientWorkGas(schedule, work)
+}
+
+func updateBlockHeader(evm *EVM, contract *Contract, input []byte) (ret []byte, err error) {
+	args, err := unpackUpdateBlockHeader(input)
+	if err != nil {
+		return nil, err
+	}
 
 	if len(args.Headers) == 0 {
 		return nil, errors.New("headers cannot be empty")
@@ -108,11 +178,16 @@ func updateBlockHeader(evm *EVM, contract *Contract, input []byte) (ret []byte,
 	// check if it is a supported chain
 	fromChain := chains.ChainType(args.From.Uint64())
 	toChain := chains.ChainType(args.To.Uint64())
-	if !(chains.IsSupportedChain(fromChain) && chains.IsSupportedChain(toChain)) {
+	number := evm.Context.BlockNumber
+	if !(chains.IsSupportedChain(evm.chainConfig, number, fromChain) && chains.IsSupportedChain(evm.chainConfig, number, toChain)) {
 		return nil, ErrNotSupportChain
 	}
+	staked, err := validateChainRelayer(evm, contract.CallerAddress, fromChain)
+	if err != nil {
+		return nil, err
+	}
 
-	group, err := chains.ChainType2ChainGroup(fromChain)
+	group, err := chains.ChainType2ChainGroupAt(evm.chainConfig, evm.Context.BlockNumber, fromChain)
 	if err != nil {
 		return nil, err
 	}
@@ -121,6 +196,15 @@ func updateBlockHeader(evm *EVM, contract *Contract, input []byte) (ret []byte,
 	if err != nil {
 		return nil, err
 	}
+	if cc, ok := chain.(chains.IChainConfigurable); ok {
+		cc.SetChainConfig(evm.chainConfig)
+	}
+	if bc, ok := chain.(chains.IBlockConfigurable); ok {
+		bc.SetBlockNumber(evm.Context.BlockNumber)
+	}
+	if cc, ok := chain.(chains.IClockConfigurable); ok {
+		cc.SetBlockTime(evm.Context.Time.Uint64())
+	}
 	if _, err := chain.ValidateHeaderChain(evm.StateDB, args.Headers, fromChain); err != nil {
 		log.Error("failed to validate header chain", "error", err)
 		return nil, err
@@ -131,6 +215,11 @@ func updateBlockHeader(evm *EVM, contract *Contract, input []byte) (ret []byte,
 		log.Error("failed to write headers", "error", err)
 		return nil, err
 	}
+	if staked && len(nums) > 0 {
+		if err := relayer.RecordSubmission(evm.StateDB, currentEpoch(evm), uint64(fromChain), contract.CallerAddress, uint64(len(nums))); err != nil {
+			return nil, err
+		}
+	}
 
 	// make event
 	event := abiHeaderStore.Events[EventOfUpdate]
@@ -144,10 +233,64 @@ func updateBlockHeader(evm *EVM, contract *Contract, input []byte) (ret []byte,
 		addLog(evm, contract, topics, logData)
 		log.Info("event produce", "height", n, "topics", topics, "event.ID", event.ID)
 	}
+	if evm.chainConfig.IsHeaderLog(evm.Context.BlockNumber) {
+		if err := addInsertLog(evm, contract, fromChain, args.Headers); err != nil {
+			return nil, err
+		}
+	}
+	if change := chain.LastHeadChange(); change != nil && evm.chainConfig.IsForkChoice(evm.Context.BlockNumber) {
+		if err := addHeadChangeLogs(evm, contract, fromChain, change); err != nil {
+			return nil, err
+		}
+	}
 
 	return nil, nil
 }
 
+// addInsertLog emits the headers accepted by the header store, so node-local indexes
+// see the updates of internal calls as well as those of transactions.
+func addInsertLog(evm *EVM, contract *Contract, from chains.ChainType, headers []byte) error {
+	event := abiHeaderStore.Events[EventOfInsert]
+	data, err := event.Inputs.NonIndexed().Pack(headers)
+	if err != nil {
+		return err
+	}
+	addLog(evm, contract, []common.Hash{event.ID, common.BigToHash(new(big.Int).SetUint64(uint64(from)))}, data)
+	return nil
+}
+
+// addHeadChangeLogs emits the new canonical head of the chain, and the reorg that
+// led to it if the insert rewrote canonical headers.
+func addHeadChangeLogs(evm *EVM, contract *Contract, from chains.ChainType, change *chains.HeadChange) error {
+	chainID := common.BigToHash(new(big.Int).SetUint64(uint64(from)))
+
+	event := abiHeaderStore.Events[EventOfHead]
+	data, err := event.Inputs.NonIndexed().Pack(change.NewHead.Hash)
+	if err != nil {
+		return err
+	}
+	topics := []common.Hash{event.ID, chainID, common.BigToHash(new(big.Int).SetUint64(change.NewHead.Number))}
+	addLog(evm, contract, topics, data)
+
+	depth := change.Depth()
+	if depth == 0 {
+		return nil
+	}
+	event = abiHeaderStore.Events[EventOfReorg]
+	data, err = event.Inputs.NonIndexed().Pack(
+		new(big.Int).SetUint64(depth),
+		new(big.Int).SetUint64(change.OldHead.Number), change.OldHead.Hash,
+		new(big.Int).SetUint64(change.NewHead.Number), change.NewHead.Hash,
+		new(big.Int).SetUint64(change.Ancestor.Number), change.Ancestor.Hash,
+	)
+	if err != nil {
+		return err
+	}
+	addLog(evm, contract, []common.Hash{event.ID, chainID}, data)
+	log.Info("header store reorg event", "chain", from, "depth", depth, "number", change.NewHead.Number, "hash", change.NewHead.Hash)
+	return nil
+}
+
 func reset(evm *EVM, contract *Contract, input []byte) (ret []byte, err error) {
 	args := struct {
 		From   *big.Int
@@ -179,7 +322,7 @@ func reset(evm *EVM, contract *Contract, input []byte) (ret []byte, err error) {
 		return nil, errors.New("current chainID does not match the from parameter")
 	}
 
-	group, err := chains.ChainType2ChainGroup(from)
+	group, err := chains.ChainType2ChainGroupAt(evm.chainConfig, evm.Context.BlockNumber, from)
 	if err != nil {
 		return nil, err
 	}
@@ -187,6 +330,12 @@ func reset(evm *EVM, contract *Contract, input []byte) (ret []byte, err error) {
 	if err != nil {
 		return nil, err
 	}
+	if cc, ok := hs.(chains.IChainConfigurable); ok {
+		cc.SetChainConfig(evm.chainConfig)
+	}
+	if rs, ok := hs.(chains.IRetentionStore); ok {
+		rs.SetRetention(chains.RetentionAt(evm.chainConfig, evm.Context.BlockNumber))
+	}
 	if err := hs.ResetHeaderStore(evm.StateDB, args.Header, args.Td); err != nil {
 		log.Error("failed to reset header store", "error", err)
 		return nil, err
@@ -207,7 +356,7 @@ func currentNumberAndHash(evm *EVM, contract *Contract, input []byte) (ret []byt
 		return nil, err
 	}
 
-	group, err := chains.ChainType2ChainGroup(chains.ChainType(args.ChainID.Uint64()))
+	group, err := chains.ChainType2ChainGroupAt(evm.chainConfig, evm.Context.BlockNumber, chains.ChainType(args.ChainID.Uint64()))
 	if err != nil {
 		return nil, err
 	}
@@ -257,6 +406,16 @@ func validateRelayer(evm *EVM, caller common.Address) error {
 	return nil
 }
 
+// validateChainRelayer checks that the caller may submit headers of the chain, either
+// as the relayer set by the admin or as a staked relayer of the chain. It reports
+// whether the caller is a staked relayer.
+func validateChainRelayer(evm *EVM, caller common.Address, chain chains.ChainType) (bool, error) {
+	if evm.chainConfig.IsRelayer(evm.Context.BlockNumber) && relayer.IsRelayer(evm.StateDB, uint64(chain), caller) {
+		return true, nil
+	}
+	return false, validateRelayer(evm, caller)
+}
+
 func addLog(evm *EVM, contract *Contract, topics []common.Hash, data []byte) {
 	evm.StateDB.AddLog(&types.Log{
 		Address:     contract.Address(),
@@ -2,8 +2,17 @@ package vm
 
 import (
 	"fmt"
+	"math/big"
 	"reflect"
 	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mapprotocol/atlas/chains"
+	"github.com/mapprotocol/atlas/core/rawdb"
+	"github.com/mapprotocol/atlas/core/state"
+	"github.com/mapprotocol/atlas/params"
 )
 
 func headerStorePack(method string, args ...interface{}) []byte {
@@ -77,3 +86,56 @@ func TestCurrentNumberAndHash(t *testing.T) {
 		})
 	}
 }
+
+func TestAddHeadChangeLogs(t *testing.T) {
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	evm := NewEVM(BlockContext{BlockNumber: big.NewInt(1)}, TxContext{}, statedb, params.TestChainConfig, Config{})
+	contract := NewContract(AccountRef(common.Address{1}), AccountRef(params.HeaderStoreAddress), big.NewInt(0), 0)
+
+	change := &chains.HeadChange{
+		OldHead:  params.NumberHash{Number: 12, Hash: common.Hash{12}},
+		NewHead:  params.NumberHash{Number: 13, Hash: common.Hash{13}},
+		Ancestor: params.NumberHash{Number: 10, Hash: common.Hash{10}},
+	}
+	assert.NoError(t, addHeadChangeLogs(evm, contract, chains.ChainTypeETH, change))
+
+	logs := statedb.Logs()
+	assert.Len(t, logs, 2)
+
+	head := abiHeaderStore.Events[EventOfHead]
+	assert.Equal(t, []common.Hash{head.ID, common.BigToHash(big.NewInt(1)), common.BigToHash(big.NewInt(13))}, logs[0].Topics)
+	out, err := head.Inputs.NonIndexed().Unpack(logs[0].Data)
+	assert.NoError(t, err)
+	assert.Equal(t, [32]byte(change.NewHead.Hash), out[0])
+
+	reorg := abiHeaderStore.Events[EventOfReorg]
+	assert.Equal(t, reorg.ID, logs[1].Topics[0])
+	out, err = reorg.Inputs.NonIndexed().Unpack(logs[1].Data)
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(2), out[0])
+	assert.Equal(t, big.NewInt(10), out[5])
+	assert.Equal(t, [32]byte(change.Ancestor.Hash), out[6])
+
+	// extending the head is not a reorg
+	change.Ancestor = change.OldHead
+	assert.NoError(t, addHeadChangeLogs(evm, contract, chains.ChainTypeETH, change))
+	assert.Len(t, statedb.Logs(), 3)
+}
+
+func TestAddInsertLog(t *testing.T) {
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	evm := NewEVM(BlockContext{BlockNumber: big.NewInt(1)}, TxContext{}, statedb, params.TestChainConfig, Config{})
+	contract := NewContract(AccountRef(common.Address{1}), AccountRef(params.HeaderStoreAddress), big.NewInt(0), 0)
+
+	headers := []byte{0xc2, 0x01, 0x02}
+	assert.NoError(t, addInsertLog(evm, contract, chains.ChainTypeBSC, headers))
+
+	logs := statedb.Logs()
+	assert.Len(t, logs, 1)
+	event := abiHeaderStore.Events[EventOfInsert]
+	assert.Equal(t, params.HeaderStoreAddress, logs[0].Address)
+	assert.Equal(t, []common.Hash{event.ID, common.BigToHash(new(big.Int).SetUint64(uint64(chains.ChainTypeBSC)))}, logs[0].Topics)
+	out, err := event.Inputs.NonIndexed().Unpack(logs[0].Data)
+	assert.NoError(t, err)
+	assert.Equal(t, headers, out[0])
+}
@@ -0,0 +1,267 @@
+package vm
+
+import (
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	ethparams "github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mapprotocol/atlas/chains"
+	"github.com/mapprotocol/atlas/chains/eth2"
+	bls "github.com/mapprotocol/atlas/chains/eth2/bls12381"
+	"github.com/mapprotocol/atlas/chains/ethereum"
+	"github.com/mapprotocol/atlas/core/rawdb"
+	"github.com/mapprotocol/atlas/core/state"
+	"github.com/mapprotocol/atlas/params"
+)
+
+func newLightClientGasEVM(config *params.ChainConfig, number int64) *EVM {
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	return NewEVM(BlockContext{BlockNumber: big.NewInt(number)}, TxContext{}, statedb, config, Config{})
+}
+
+func makeGasTestHeaders(n int) []*ethereum.Header {
+	headers := make([]*ethereum.Header, n)
+	for i := range headers {
+		headers[i] = &ethereum.Header{
+			ParentHash: common.Hash{byte(i)},
+			Difficulty: big.NewInt(1),
+			Number:     big.NewInt(int64(i + 1)),
+			GasLimit:   30_000_000,
+			GasUsed:    15_000_000,
+			Time:       uint64(i),
+			Extra:      make([]byte, 32),
+			BaseFee:    big.NewInt(ethparams.GWei),
+		}
+	}
+	return headers
+}
+
+func packUpdateBlockHeader(t testing.TB, from chains.ChainType, headers []*ethereum.Header) []byte {
+	data, err := rlp.EncodeToBytes(headers)
+	require.NoError(t, err)
+	args, err := rlp.EncodeToBytes(&updateBlockHeaderArgs{
+		From:    new(big.Int).SetUint64(uint64(from)),
+		To:      big.NewInt(int64(params.MainNetChainID)),
+		Headers: data,
+	})
+	require.NoError(t, err)
+	input, err := abiHeaderStore.Pack(Save, args)
+	require.NoError(t, err)
+	return input
+}
+
+func TestUpdateBlockHeaderGas(t *testing.T) {
+	config := &params.ChainConfig{LightClientGasBlock: big.NewInt(10), EthMergeBlock: big.NewInt(20)}
+	input := packUpdateBlockHeader(t, chains.ChainTypeETH, makeGasTestHeaders(4))
+	p := &store{}
+
+	// before the fork the input is paid per byte
+	assert.Equal(t, uint64(len(input))*68, p.RequiredGasAt(newLightClientGasEVM(config, 9), input))
+
+	gas := params.LightClientGasMetered
+	base := gas.HeaderStoreBase + uint64(len(input))*gas.PerByte
+	assert.Equal(t, base+4*(gas.PerHeader+gas.PerPoWSeal+2*gas.PerStateWrite)+gas.PerStateWrite,
+		p.RequiredGasAt(newLightClientGasEVM(config, 10), input))
+
+	// headers validated as a header chain have no seal to verify
+	assert.Equal(t, base+4*(gas.PerHeader+2*gas.PerStateWrite)+gas.PerStateWrite,
+		p.RequiredGasAt(newLightClientGasEVM(config, 20), input))
+
+	// undecodable headers only pay the base price
+	bad, err := abiHeaderStore.Pack(Save, []byte{0x01})
+	require.NoError(t, err)
+	assert.Equal(t, gas.HeaderStoreBase+uint64(len(bad))*gas.PerByte, p.RequiredGasAt(newLightClientGasEVM(config, 10), bad))
+
+	// other methods keep their price
+	input, err = abiHeaderStore.Pack(CurNbrAndHash, big.NewInt(1))
+	require.NoError(t, err)
+	assert.Equal(t, SyncGas[CurNbrAndHash], p.RequiredGasAt(newLightClientGasEVM(config, 10), input))
+}
+
+func TestEth2VerifyLightClientGas(t *testing.T) {
+	config := &params.ChainConfig{LightClientGasBlock: big.NewInt(10)}
+	input := make([]byte, 1000)
+	p := &eth2VerifyLightClient{}
+
+	assert.Equal(t, params.VerifyEth2UpdateGas, p.RequiredGasAt(newLightClientGasEVM(config, 9), input))
+
+	gas := params.LightClientGasMetered
+	assert.Equal(t, gas.Eth2UpdateBase+1000*gas.PerByte+2*gas.PerHeader+eth2.SyncCommitteeSize*gas.PerPubKey+2*gas.PerPairing,
+		p.RequiredGasAt(newLightClientGasEVM(config, 10), input))
+}
+
+// minLightClientGasRate is the share of the gas rate of the ecrecover precompile the
+// light client work must at least be paid at, the margin absorbs the noise of a run.
+const minLightClientGasRate = 0.5
+
+var ecrecoverRate struct {
+	once sync.Once
+	rate float64
+}
+
+// ecrecoverGasRate returns the rate in mgas/s the ecrecover precompile is paid at on
+// this machine, the reference the light client prices are compared with.
+func ecrecoverGasRate(b *testing.B) float64 {
+	ecrecoverRate.once.Do(func() {
+		key, _ := crypto.GenerateKey()
+		hash := crypto.Keccak256([]byte("reference"))
+		sig, err := crypto.Sign(hash, key)
+		require.NoError(b, err)
+
+		const rounds = 500
+		start := time.Now()
+		for i := 0; i < rounds; i++ {
+			if _, err := crypto.Ecrecover(hash, sig); err != nil {
+				b.Fatal(err)
+			}
+		}
+		ecrecoverRate.rate = float64(rounds*params.EcrecoverGas) * 1000 / float64(time.Since(start))
+	})
+	return ecrecoverRate.rate
+}
+
+// reportLightClientGas reports the rate gas is paid at for the work of a benchmark, and
+// fails it if the work is underpriced compared to the ecrecover precompile.
+func reportLightClientGas(b *testing.B, gas uint64, elapsed time.Duration) {
+	if elapsed < 1 {
+		elapsed = 1
+	}
+	rate := float64(gas*uint64(b.N)) * 1000 / float64(elapsed)
+	b.ReportMetric(float64(gas), "gas/op")
+	b.ReportMetric(rate, "mgas/s")
+
+	// the single round probing the run length is too noisy to be compared
+	if b.N == 1 {
+		return
+	}
+	if reference := ecrecoverGasRate(b); rate < minLightClientGasRate*reference {
+		b.Errorf("work is paid %.2f mgas/s, less than %.0f%% of the %.2f mgas/s of ecrecover",
+			rate, minLightClientGasRate*100, reference)
+	}
+}
+
+func BenchmarkLightClientGasHeaders(b *testing.B) {
+	data, err := rlp.EncodeToBytes(makeGasTestHeaders(100))
+	require.NoError(b, err)
+
+	b.ResetTimer()
+	start := time.Now()
+	for i := 0; i < b.N; i++ {
+		var headers []*ethereum.Header
+		if err := rlp.DecodeBytes(data, &headers); err != nil {
+			b.Fatal(err)
+		}
+		for _, header := range headers {
+			header.Hash()
+		}
+	}
+	reportLightClientGas(b, 100*params.LightClientGasMetered.PerHeader, time.Since(start))
+}
+
+// BenchmarkLightClientGasPoWSeal verifies an ethash seal with a verification cache,
+// the cache of the epoch is generated once before the timer starts. The seal does not
+// match, which is only detected after the whole hashimoto computation.
+func BenchmarkLightClientGasPoWSeal(b *testing.B) {
+	ethereum.MakeGlobalEthash(b.TempDir())
+	header := makeGasTestHeaders(1)[0]
+	ethereum.VerifySeal(header)
+
+	b.ResetTimer()
+	start := time.Now()
+	for i := 0; i < b.N; i++ {
+		header.Nonce = ethtypes.EncodeNonce(uint64(i))
+		if err := ethereum.VerifySeal(header); err == nil {
+			b.Fatal("unexpected valid seal")
+		}
+	}
+	reportLightClientGas(b, params.LightClientGasMetered.PerPoWSeal, time.Since(start))
+}
+
+func BenchmarkLightClientGasSignature(b *testing.B) {
+	key, _ := crypto.GenerateKey()
+	hash := crypto.Keccak256([]byte("header"))
+	sig, err := crypto.Sign(hash, key)
+	require.NoError(b, err)
+
+	b.ResetTimer()
+	start := time.Now()
+	for i := 0; i < b.N; i++ {
+		if _, err := crypto.Ecrecover(hash, sig); err != nil {
+			b.Fatal(err)
+		}
+	}
+	reportLightClientGas(b, params.LightClientGasMetered.PerSignature, time.Since(start))
+}
+
+func BenchmarkLightClientGasStateWrite(b *testing.B) {
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	value := make([]byte, 600)
+
+	b.ResetTimer()
+	start := time.Now()
+	for i := 0; i < b.N; i++ {
+		statedb.SetPOWState(chains.EthereumHeaderStoreAddress, common.BigToHash(big.NewInt(int64(i))), value)
+		if i%100 == 99 {
+			statedb.IntermediateRoot(false)
+		}
+	}
+	statedb.IntermediateRoot(false)
+	reportLightClientGas(b, params.LightClientGasMetered.PerStateWrite, time.Since(start))
+}
+
+// BenchmarkLightClientGasPubKeys decompresses and aggregates the keys of a sync
+// committee, the keys are new every round as decompressed keys are cached.
+func BenchmarkLightClientGasPubKeys(b *testing.B) {
+	newKeys := func() [][]byte {
+		keys := make([][]byte, eth2.SyncCommitteeSize)
+		for j := range keys {
+			sk, err := bls.RandKey()
+			require.NoError(b, err)
+			keys[j] = sk.PublicKey().Marshal()
+		}
+		return keys
+	}
+	// the first aggregation initializes the library
+	if _, err := bls.AggregatePublicKeys(newKeys()); err != nil {
+		b.Fatal(err)
+	}
+
+	var elapsed time.Duration
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		keys := newKeys()
+		b.StartTimer()
+
+		start := time.Now()
+		if _, err := bls.AggregatePublicKeys(keys); err != nil {
+			b.Fatal(err)
+		}
+		elapsed += time.Since(start)
+	}
+	reportLightClientGas(b, eth2.SyncCommitteeSize*params.LightClientGasMetered.PerPubKey, elapsed)
+}
+
+func BenchmarkLightClientGasPairing(b *testing.B) {
+	sk, err := bls.RandKey()
+	require.NoError(b, err)
+	msg := crypto.Keccak256([]byte("signing root"))
+	sig := sk.Sign(msg)
+
+	b.ResetTimer()
+	start := time.Now()
+	for i := 0; i < b.N; i++ {
+		if !sig.Verify(sk.PublicKey(), msg) {
+			b.Fatal("invalid signature")
+		}
+	}
+	reportLightClientGas(b, 2*params.LightClientGasMetered.PerPairing, time.Since(start))
+}
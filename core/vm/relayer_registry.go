@@ -0,0 +1,212 @@
+package vm
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/mapprotocol/atlas/accounts/abi"
+	"github.com/mapprotocol/atlas/chains"
+	"github.com/mapprotocol/atlas/chains/relayer"
+	"github.com/mapprotocol/atlas/consensus/istanbul"
+	"github.com/mapprotocol/atlas/params"
+)
+
+const (
+	RegisterRelayer     = "register"
+	DeregisterRelayer   = "deregister"
+	RotateRelayer       = "rotate"
+	GetRelayers         = "getRelayers"
+	GetEpochSubmissions = "getEpochSubmissions"
+
+	EventOfRegistered   = "RelayerRegistered"
+	EventOfDeregistered = "RelayerDeregistered"
+	EventOfRotated      = "RelayerRotated"
+)
+
+// RelayerRegistry contract ABI
+var (
+	abiRelayerRegistry, _ = abi.JSON(strings.NewReader(params.RelayerRegistryABIJSON))
+)
+
+// RelayerRegistryGas defines all method gas
+var RelayerRegistryGas = map[string]uint64{
+	RegisterRelayer:     42000,
+	DeregisterRelayer:   21000,
+	RotateRelayer:       21000,
+	GetRelayers:         2100,
+	GetEpochSubmissions: 2100,
+}
+
+// RunRelayerRegistry execute atlas relayer registry contract
+func RunRelayerRegistry(evm *EVM, contract *Contract, input []byte) (ret []byte, err error) {
+	method, err := abiRelayerRegistry.MethodById(input)
+	if err != nil {
+		log.Error("get relayer registry ABI method failed", "error", err)
+		return nil, err
+	}
+	if method.Name != RegisterRelayer && contract.Value().Sign() != 0 {
+		return nil, errors.New("method is not payable")
+	}
+
+	data := input[4:]
+	switch method.Name {
+	case RegisterRelayer:
+		ret, err = registerRelayer(evm, contract, data)
+	case DeregisterRelayer:
+		ret, err = deregisterRelayer(evm, contract, data)
+	case RotateRelayer:
+		ret, err = rotateRelayer(evm, contract, data)
+	case GetRelayers:
+		ret, err = getRelayers(evm, data)
+	case GetEpochSubmissions:
+		ret, err = getEpochSubmissions(evm, data)
+	default:
+		log.Warn("run relayer registry contract failed, invalid method name", "method.name", method.Name)
+		return ret, errors.New("invalid method name")
+	}
+
+	if err != nil {
+		log.Error("run relayer registry contract failed", "method.name", method.Name, "error", err)
+	} else {
+		log.Info("run relayer registry contract succeed", "method.name", method.Name)
+	}
+	return ret, err
+}
+
+// currentEpoch returns the epoch of the block being processed.
+func currentEpoch(evm *EVM) uint64 {
+	return istanbul.GetEpochNumber(evm.Context.BlockNumber.Uint64(), evm.Context.EpochSize)
+}
+
+func unpackRelayerChain(evm *EVM, method string, input []byte) (uint64, error) {
+	var chainID *big.Int
+	m := abiRelayerRegistry.Methods[method]
+	unpack, err := m.Inputs.Unpack(input)
+	if err != nil {
+		return 0, err
+	}
+	if err := m.Inputs.Copy(&chainID, unpack); err != nil {
+		return 0, err
+	}
+	if !chains.IsSupportedChain(evm.chainConfig, evm.Context.BlockNumber, chains.ChainType(chainID.Uint64())) {
+		return 0, ErrNotSupportChain
+	}
+	return chainID.Uint64(), nil
+}
+
+func registerRelayer(evm *EVM, contract *Contract, input []byte) ([]byte, error) {
+	chain, err := unpackRelayerChain(evm, RegisterRelayer, input)
+	if err != nil {
+		return nil, err
+	}
+	stake := contract.Value()
+	if err := relayer.Register(evm.StateDB, chain, contract.CallerAddress, stake, currentEpoch(evm)); err != nil {
+		return nil, err
+	}
+
+	event := abiRelayerRegistry.Events[EventOfRegistered]
+	data, err := event.Inputs.NonIndexed().Pack(stake)
+	if err != nil {
+		return nil, err
+	}
+	topics := []common.Hash{event.ID, common.BigToHash(new(big.Int).SetUint64(chain)), contract.CallerAddress.Hash()}
+	addLog(evm, contract, topics, data)
+	return nil, nil
+}
+
+func deregisterRelayer(evm *EVM, contract *Contract, input []byte) ([]byte, error) {
+	chain, err := unpackRelayerChain(evm, DeregisterRelayer, input)
+	if err != nil {
+		return nil, err
+	}
+	if err := relayer.Deregister(evm.StateDB, chain, contract.CallerAddress); err != nil {
+		return nil, err
+	}
+
+	event := abiRelayerRegistry.Events[EventOfDeregistered]
+	topics := []common.Hash{event.ID, common.BigToHash(new(big.Int).SetUint64(chain)), contract.CallerAddress.Hash()}
+	addLog(evm, contract, topics, nil)
+	return nil, nil
+}
+
+func rotateRelayer(evm *EVM, contract *Contract, input []byte) ([]byte, error) {
+	args := struct {
+		ChainID    *big.Int
+		NewRelayer common.Address
+	}{}
+	method := abiRelayerRegistry.Methods[RotateRelayer]
+	unpack, err := method.Inputs.Unpack(input)
+	if err != nil {
+		return nil, err
+	}
+	if err := method.Inputs.Copy(&args, unpack); err != nil {
+		return nil, err
+	}
+	if args.NewRelayer == (common.Address{}) {
+		return nil, errors.New("new relayer address is empty")
+	}
+	chain := args.ChainID.Uint64()
+	if !chains.IsSupportedChain(evm.chainConfig, evm.Context.BlockNumber, chains.ChainType(chain)) {
+		return nil, ErrNotSupportChain
+	}
+	if err := relayer.Rotate(evm.StateDB, chain, contract.CallerAddress, args.NewRelayer, currentEpoch(evm)); err != nil {
+		return nil, err
+	}
+
+	event := abiRelayerRegistry.Events[EventOfRotated]
+	topics := []common.Hash{event.ID, common.BigToHash(args.ChainID), contract.CallerAddress.Hash(), args.NewRelayer.Hash()}
+	addLog(evm, contract, topics, nil)
+	return nil, nil
+}
+
+func getRelayers(evm *EVM, input []byte) ([]byte, error) {
+	chain, err := unpackRelayerChain(evm, GetRelayers, input)
+	if err != nil {
+		return nil, err
+	}
+	set, err := relayer.LoadSet(evm.StateDB, chain)
+	if err != nil {
+		return nil, err
+	}
+	addrs := make([]common.Address, 0, len(set.Relayers))
+	stakes := make([]*big.Int, 0, len(set.Relayers))
+	for _, r := range set.Relayers {
+		if r.Exiting {
+			continue
+		}
+		addrs = append(addrs, r.Address)
+		stakes = append(stakes, r.Stake)
+	}
+	return abiRelayerRegistry.Methods[GetRelayers].Outputs.Pack(addrs, stakes)
+}
+
+func getEpochSubmissions(evm *EVM, input []byte) ([]byte, error) {
+	var epoch *big.Int
+	method := abiRelayerRegistry.Methods[GetEpochSubmissions]
+	unpack, err := method.Inputs.Unpack(input)
+	if err != nil {
+		return nil, err
+	}
+	if err := method.Inputs.Copy(&epoch, unpack); err != nil {
+		return nil, err
+	}
+	work, err := relayer.LoadWork(evm.StateDB, epoch.Uint64())
+	if err != nil {
+		return nil, err
+	}
+	var (
+		addrs   = make([]common.Address, len(work.Submissions))
+		ids     = make([]*big.Int, len(work.Submissions))
+		headers = make([]*big.Int, len(work.Submissions))
+	)
+	for i, s := range work.Submissions {
+		addrs[i] = s.Relayer
+		ids[i] = new(big.Int).SetUint64(s.Chain)
+		headers[i] = new(big.Int).SetUint64(s.Headers)
+	}
+	return method.Outputs.Pack(addrs, ids, headers)
+}
@@ -3,6 +3,7 @@ package vm
 import (
 	"bytes"
 	"errors"
+	"fmt"
 	"math/big"
 	"strings"
 
@@ -17,7 +18,9 @@ import (
 )
 
 const (
-	VerifyProof = "verifyProofData"
+	VerifyProof         = "verifyProofData"
+	VerifyBatchProof    = "verifyBatchProofData"
+	VerifyFilteredProof = "verifyFilteredProofData"
 )
 
 // TxVerify contract ABI
@@ -29,9 +32,40 @@ var TxVerifyGas = map[string]uint64{
 	VerifyProof: 42000,
 }
 
+// The gas of the batch methods grows with the size of the proof, so that batches of
+// many receipts are not verified for the price of a single one.
+const (
+	TxVerifyBatchBaseGas    uint64 = 42000
+	TxVerifyBatchPerByteGas uint64 = 16
+)
+
+// txVerifyBatchGas returns the gas of a batch method called with input.
+func txVerifyBatchGas(input []byte) uint64 {
+	return TxVerifyBatchBaseGas + uint64(len(input))*TxVerifyBatchPerByteGas
+}
+
+// isTxVerifyBatchMethod reports whether the input calls one of the batch methods.
+func isTxVerifyBatchMethod(input []byte) bool {
+	method, err := abiTxVerify.MethodById(input)
+	return err == nil && (method.Name == VerifyBatchProof || method.Name == VerifyFilteredProof)
+}
+
+// receiptProofArgs is the rlp encoded receiptProof argument of the verify methods.
+type receiptProofArgs struct {
+	Router   common.Address
+	Coin     common.Address
+	SrcChain *big.Int
+	DstChain *big.Int
+	TxProve  []byte
+}
+
 // RunTxVerify execute atlas tx verify contract
 func RunTxVerify(evm *EVM, contract *Contract, input []byte) (ret []byte, err error) {
 	method, err := abiTxVerify.MethodById(input)
+	if err == nil && isTxVerifyBatchMethod(input) && !evm.chainConfig.IsTxVerifyBatch(evm.Context.BlockNumber) {
+		// the batch methods do not exist before they are activated
+		err = fmt.Errorf("no method with id: %#x", input[:4])
+	}
 	if err != nil {
 		log.Error("get tx verify ABI method failed", "error", err)
 		return nil, err
@@ -41,6 +75,8 @@ func RunTxVerify(evm *EVM, contract *Contract, input []byte) (ret []byte, err er
 	switch method.Name {
 	case VerifyProof:
 		ret, err = verifyProofData(evm, contract, data)
+	case VerifyBatchProof, VerifyFilteredProof:
+		ret, err = verifyBatchProofData(evm, contract, method.Name, data)
 	default:
 		log.Warn("run tx verify contract failed, invalid method", "method", method.Name)
 		return ret, errors.New("invalid method name")
@@ -61,14 +97,6 @@ func verifyProofData(evm *EVM, contract *Contract, input []byte) (ret []byte, er
 		logs         []byte
 		receiptProof []byte
 	)
-	args := struct {
-		Router   common.Address
-		Coin     common.Address
-		SrcChain *big.Int
-		DstChain *big.Int
-		TxProve  []byte
-	}{}
-
 	verifyProof := abiTxVerify.Methods[VerifyProof]
 	defer func() {
 		var packErr error
@@ -108,35 +136,113 @@ func verifyProofData(evm *EVM, contract *Contract, input []byte) (ret []byte, er
 	if err = verifyProof.Inputs.Copy(&receiptProof, unpack); err != nil {
 		return nil, err
 	}
+	args, group, err := decodeReceiptProof(evm, receiptProof)
+	if err != nil {
+		return nil, err
+	}
+
+	v, err := interfaces.VerifyFactory(group)
+	if err != nil {
+		return nil, err
+	}
+	logs, err = v.Verify(evm.StateDB, args.Router, args.TxProve)
+	if err != nil {
+		log.Error("verify proof failed", "err", err.Error())
+		return nil, err
+	}
+	return nil, nil
+}
+
+// decodeReceiptProof decodes a receiptProof argument and returns the light client
+// group of its source chain.
+func decodeReceiptProof(evm *EVM, receiptProof []byte) (*receiptProofArgs, chains.ChainGroup, error) {
+	var args receiptProofArgs
 	if err := rlp.DecodeBytes(receiptProof, &args); err != nil {
 		log.Error("rlp decode receiptProof failed", "err", err)
-		return nil, err
+		return nil, 0, err
+	}
+	if args.SrcChain == nil {
+		return nil, 0, ErrNotSupportChain
 	}
 	log.Info("verifyProofData args", "router", args.Router, "coin", args.Coin, "srcChain", args.SrcChain, "dstChain", args.DstChain)
 
 	// params check
 	if bytes.Equal(args.Router.Bytes(), common.Address{}.Bytes()) {
-		return nil, errors.New("router address is empty")
+		return nil, 0, errors.New("router address is empty")
 	}
 	//if bytes.Equal(args.Coin.Bytes(), common.Address{}.Bytes()) {
 	//	return nil, errors.New("coin address is empty")
 	//}
-	if !chains.IsSupportedChain(chains.ChainType(args.SrcChain.Uint64())) {
-		return nil, ErrNotSupportChain
+	if !chains.IsSupportedChain(evm.chainConfig, evm.Context.BlockNumber, chains.ChainType(args.SrcChain.Uint64())) {
+		return nil, 0, ErrNotSupportChain
+	}
+	group, err := chains.ChainType2ChainGroupAt(evm.chainConfig, evm.Context.BlockNumber, chains.ChainType(args.SrcChain.Uint64()))
+	if err != nil {
+		return nil, 0, err
+	}
+	return &args, group, nil
+}
+
+// verifyBatchProofData verifies a batch of receipts of one block. With
+// VerifyFilteredProof only the logs matching the log address and topic arguments are
+// returned, a zero address or topic matches any.
+func verifyBatchProofData(evm *EVM, contract *Contract, name string, input []byte) (ret []byte, err error) {
+	var (
+		success = true
+		message = ""
+		logs    []byte
+		args    struct {
+			ReceiptProof []byte
+			LogAddress   common.Address
+			Topic        [32]byte
+		}
+	)
+
+	method := abiTxVerify.Methods[name]
+	defer func() {
+		var packErr error
+
+		if err != nil {
+			success, message, logs = false, err.Error(), []byte{}
+		}
+		ret, packErr = method.Outputs.Pack(success, message, logs)
+		if packErr != nil {
+			log.Error("verify batch proof outputs pack failed", "error", packErr.Error())
+		}
+	}()
+
+	unpack, err := method.Inputs.Unpack(input)
+	if err != nil {
+		return nil, err
+	}
+	if name == VerifyBatchProof {
+		err = method.Inputs.Copy(&args.ReceiptProof, unpack)
+	} else {
+		err = method.Inputs.Copy(&args, unpack)
 	}
-	group, err := chains.ChainType2ChainGroup(chains.ChainType(args.SrcChain.Uint64()))
 	if err != nil {
 		return nil, err
 	}
 
-	v, err := interfaces.VerifyFactory(group)
+	proof, group, err := decodeReceiptProof(evm, args.ReceiptProof)
 	if err != nil {
 		return nil, err
 	}
-	logs, err = v.Verify(evm.StateDB, args.Router, args.TxProve)
+	v, err := interfaces.BatchVerifyFactory(group)
 	if err != nil {
-		log.Error("verify proof failed", "err", err.Error())
 		return nil, err
 	}
-	return nil, nil
+	lgs, err := v.VerifyBatch(evm.StateDB, proof.Router, proof.TxProve)
+	if err != nil {
+		log.Error("verify batch proof failed", "err", err.Error())
+		return nil, err
+	}
+	if name == VerifyFilteredProof {
+		lgs = chains.FilterLogs(lgs, args.LogAddress, args.Topic)
+		if len(lgs) == 0 {
+			return nil, fmt.Errorf("no log matches the filter, address: %v, topic: %v", args.LogAddress, common.Hash(args.Topic))
+		}
+	}
+	logs, err = rlp.EncodeToBytes(lgs)
+	return nil, err
 }
@@ -0,0 +1,96 @@
+package vm
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mapprotocol/atlas/chains"
+	"github.com/mapprotocol/atlas/params"
+)
+
+func TestTxVerifyBatchGas(t *testing.T) {
+	tv := &verify{}
+
+	single, err := abiTxVerify.Pack(VerifyProof, make([]byte, 4096))
+	assert.NoError(t, err)
+	assert.Equal(t, TxVerifyGas[VerifyProof], tv.RequiredGas(single))
+
+	small, err := abiTxVerify.Pack(VerifyBatchProof, make([]byte, 100))
+	assert.NoError(t, err)
+	large, err := abiTxVerify.Pack(VerifyBatchProof, make([]byte, 4096))
+	assert.NoError(t, err)
+	assert.Equal(t, TxVerifyBatchBaseGas+uint64(len(small))*TxVerifyBatchPerByteGas, tv.RequiredGas(small))
+	assert.Equal(t, uint64(len(large)-len(small))*TxVerifyBatchPerByteGas, tv.RequiredGas(large)-tv.RequiredGas(small))
+
+	filtered, err := abiTxVerify.Pack(VerifyFilteredProof, make([]byte, 4096), common.Address{}, [32]byte{})
+	assert.NoError(t, err)
+	assert.Equal(t, TxVerifyBatchBaseGas+uint64(len(filtered))*TxVerifyBatchPerByteGas, tv.RequiredGas(filtered))
+}
+
+func txVerifyBatchEVM(fork, number int64) *EVM {
+	config := *params.TestChainConfig
+	config.TxVerifyBatchBlock = big.NewInt(fork)
+	return &EVM{chainConfig: &config, Context: BlockContext{BlockNumber: big.NewInt(number)}}
+}
+
+func TestTxVerifyBatchFork(t *testing.T) {
+	tv := &verify{}
+	input := abiTxVerifyInput(t, VerifyBatchProof, make([]byte, 100))
+
+	before := txVerifyBatchEVM(10, 9)
+	assert.Equal(t, uint64(21000), tv.RequiredGasAt(before, input))
+	ret, err := RunTxVerify(before, &Contract{}, input)
+	assert.EqualError(t, err, fmt.Sprintf("no method with id: %#x", input[:4]))
+	assert.Nil(t, ret)
+
+	after := txVerifyBatchEVM(10, 10)
+	assert.Equal(t, tv.RequiredGas(input), tv.RequiredGasAt(after, input))
+
+	single := abiTxVerifyInput(t, VerifyProof, make([]byte, 100))
+	assert.Equal(t, tv.RequiredGas(single), tv.RequiredGasAt(before, single))
+}
+
+func TestVerifyBatchProofData(t *testing.T) {
+	evm := txVerifyBatchEVM(0, 1)
+	encode := func(router common.Address, src chains.ChainType) []byte {
+		data, err := rlp.EncodeToBytes(&receiptProofArgs{
+			Router: router, SrcChain: new(big.Int).SetUint64(uint64(src)), DstChain: big.NewInt(22776), TxProve: []byte{},
+		})
+		assert.NoError(t, err)
+		return data
+	}
+
+	tests := []struct {
+		name  string
+		input []byte
+		want  string
+	}{
+		{"empty router", abiTxVerifyInput(t, VerifyBatchProof, encode(common.Address{}, chains.ChainTypeETH)), "router address is empty"},
+		{"unsupported chain", abiTxVerifyInput(t, VerifyBatchProof, encode(common.Address{1}, 12345)), chains.ErrNotSupportChain.Error()},
+		{"invalid batch", abiTxVerifyInput(t, VerifyBatchProof, encode(common.Address{1}, chains.ChainTypeETH)), "EOF"},
+		{"invalid filtered batch", abiTxVerifyInput(t, VerifyFilteredProof, encode(common.Address{1}, chains.ChainTypeETH), common.Address{1}, [32]byte{1}), "EOF"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ret, err := RunTxVerify(evm, &Contract{}, tt.input)
+			assert.Error(t, err)
+
+			out, err := abiTxVerify.Methods[VerifyBatchProof].Outputs.Unpack(ret)
+			assert.NoError(t, err)
+			assert.False(t, out[0].(bool))
+			assert.Contains(t, out[1].(string), tt.want)
+			assert.Empty(t, out[2].([]byte))
+		})
+	}
+}
+
+func abiTxVerifyInput(t *testing.T, method string, args ...interface{}) []byte {
+	input, err := abiTxVerify.Pack(method, args...)
+	assert.NoError(t, err)
+	return input
+}
@@ -376,3 +376,58 @@ func TestBls04(t *testing.T) {
 	b0 := g1.Marshal()
 	t.Logf("hash: %x", b0)
 }
+
+func TestEncodeEpochSnarkData(t *testing.T) {
+	pk1, pk2 := SerializedPublicKey{0x01}, SerializedPublicKey{0x02}
+	message, extraData, err := BN256{}.EncodeEpochSnarkData([]SerializedPublicKey{pk1, pk2}, 49, 150, 3, 1,
+		EpochEntropy{0xaa}, EpochEntropy{0xbb})
+	require.NoError(t, err)
+	require.Len(t, message, 10+2*PUBLICKEYBYTES)
+	require.Equal(t, []byte{3, 0, 49, 0, 0, 0, 150, 0, 0, 0}, message[:10])
+	require.Equal(t, pk1[:], message[10:10+PUBLICKEYBYTES])
+	require.Equal(t, pk2[:], message[10+PUBLICKEYBYTES:])
+	require.Len(t, extraData, 1+2*EPOCHENTROPYBYTES)
+	require.Equal(t, []byte{1, 0xaa}, extraData[:2])
+	require.Equal(t, byte(0xbb), extraData[1+EPOCHENTROPYBYTES])
+
+	_, _, err = BN256{}.EncodeEpochSnarkData([]SerializedPublicKey{pk1, pk2}, 0, 1, 3, 1, EpochEntropy{}, EpochEntropy{})
+	require.Error(t, err)
+}
+
+func TestCompositeSignVerify(t *testing.T) {
+	fork, cur := big.NewInt(0), big.NewInt(1)
+	message, extraData := randomMessage(), []byte{0x01, 0x02}
+
+	var (
+		keys []SerializedPublicKey
+		sigs [][]byte
+	)
+	for i := 0; i < 3; i++ {
+		pub, priv, err := GenKeyPair(rand.Reader)
+		require.NoError(t, err)
+		prepared, err := PrepareMessage(message, extraData, true)
+		require.NoError(t, err)
+		require.Len(t, prepared, CompositeHashLength)
+		sig, err := UnsafeSign2(priv, prepared)
+		require.NoError(t, err)
+
+		var key SerializedPublicKey
+		copy(key[:], pub.Marshal())
+		keys = append(keys, key)
+		sigs = append(sigs, sig.Marshal())
+		require.NoError(t, CryptoType().VerifySignature(key, message, extraData, sig.Marshal(), true, true, fork, cur))
+		// The extra data is signed by the composite hasher only
+		require.Error(t, CryptoType().VerifySignature(key, message, []byte{0x03}, sig.Marshal(), true, true, fork, cur))
+		require.Error(t, CryptoType().VerifySignature(key, message, extraData, sig.Marshal(), false, true, fork, cur))
+	}
+
+	aggSig, err := CryptoType().AggregateSignatures(sigs)
+	require.NoError(t, err)
+	require.NoError(t, CryptoType().VerifyAggregatedSignature(keys, message, extraData, aggSig, true, true, fork, cur))
+	require.Error(t, CryptoType().VerifyAggregatedSignature(keys[:2], message, extraData, aggSig, true, true, fork, cur))
+
+	// Without the composite hasher the message is signed as is
+	prepared, err := PrepareMessage(message, extraData, false)
+	require.NoError(t, err)
+	require.Equal(t, message, prepared)
+}
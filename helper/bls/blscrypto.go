@@ -3,9 +3,12 @@ package bls
 import (
 	"bytes"
 	"crypto/ecdsa"
+	"encoding/binary"
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"io"
+
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/mapprotocol/atlas/params"
@@ -14,6 +17,7 @@ import (
 
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/crypto"
+	"golang.org/x/crypto/blake2s"
 )
 
 const (
@@ -111,6 +115,7 @@ type BLSCryptoSelector interface {
 	AggregateSignatures(signatures [][]byte) ([]byte, error)
 	VerifySignature(publicKey SerializedPublicKey, message []byte, extraData []byte, signature []byte, shouldUseCompositeHasher, cip22 bool, fork, cur *big.Int) error
 	EncodeEpochSnarkDataCIP22(newValSet []SerializedPublicKey, maximumNonSigners, maxValidators uint32, epochIndex uint16, round uint8, blockHash, parentHash EpochEntropy) ([]byte, []byte, error)
+	EncodeEpochSnarkData(newValSet []SerializedPublicKey, maximumNonSigners, maxValidators uint32, epochIndex uint16, round uint8, blockHash, parentHash EpochEntropy) ([]byte, []byte, error)
 	UncompressKey(serialized SerializedPublicKey) ([]byte, error)
 }
 
@@ -214,6 +219,10 @@ func (BN256) VerifyAggregatedSignature(publicKeys []SerializedPublicKey, message
 	if err != nil {
 		return err
 	}
+	message, err = PrepareMessage(message, extraData, shouldUseCompositeHasher)
+	if err != nil {
+		return err
+	}
 
 	var pks []*PublicKey
 	for _, v := range publicKeys {
@@ -260,6 +269,10 @@ func (BN256) VerifySignature(publicKey SerializedPublicKey, message []byte, extr
 	if err != nil {
 		return err
 	}
+	message, err = PrepareMessage(message, extraData, shouldUseCompositeHasher)
+	if err != nil {
+		return err
+	}
 	pk, err := UnmarshalPk(publicKey[:])
 	if err != nil {
 		return err
@@ -300,6 +313,66 @@ func (BN256) EncodeEpochSnarkDataCIP22(newValSet []SerializedPublicKey, maximumN
 	return ret1, ret2, nil
 }
 
+// EncodeEpochSnarkData encodes the validator set of the next epoch with fixed width
+// little endian fields, the layout a SNARK circuit reads without parsing:
+//
+//	message   = epochIndex (2) || maximumNonSigners (4) || maxValidators (4) || keys (128 each)
+//	extraData = round (1) || blockHash entropy (16) || parentHash entropy (16)
+func (BN256) EncodeEpochSnarkData(newValSet []SerializedPublicKey, maximumNonSigners, maxValidators uint32, epochIndex uint16, round uint8, blockHash, parentHash EpochEntropy) ([]byte, []byte, error) {
+	if uint32(len(newValSet)) > maxValidators {
+		return nil, nil, fmt.Errorf("validator set size %d exceeds the maximum %d", len(newValSet), maxValidators)
+	}
+	message := make([]byte, 10, 10+len(newValSet)*PUBLICKEYBYTES)
+	binary.LittleEndian.PutUint16(message[0:2], epochIndex)
+	binary.LittleEndian.PutUint32(message[2:6], maximumNonSigners)
+	binary.LittleEndian.PutUint32(message[6:10], maxValidators)
+	for _, pk := range newValSet {
+		message = append(message, pk[:]...)
+	}
+
+	extraData := make([]byte, 0, 1+2*EPOCHENTROPYBYTES)
+	extraData = append(extraData, round)
+	extraData = append(extraData, blockHash[:]...)
+	extraData = append(extraData, parentHash[:]...)
+	return message, extraData, nil
+}
+
+// compositeHashKey separates the composite hash from other uses of Blake2Xs.
+var compositeHashKey = []byte("ULforxof")
+
+// CompositeHashLength is the size of the digest of the composite hash.
+const CompositeHashLength = 64
+
+// CompositeHash is the collision resistant part of the composite hasher. It digests
+// the extra data and the message with Blake2Xs, which is cheap in a SNARK circuit,
+// and the digest is then hashed to the curve in place of the message.
+func CompositeHash(message, extraData []byte) ([]byte, error) {
+	xof, err := blake2s.NewXOF(CompositeHashLength, compositeHashKey)
+	if err != nil {
+		return nil, err
+	}
+	var length [4]byte
+	binary.LittleEndian.PutUint32(length[:], uint32(len(extraData)))
+	xof.Write(length[:])
+	xof.Write(extraData)
+	xof.Write(message)
+
+	digest := make([]byte, CompositeHashLength)
+	if _, err := io.ReadFull(xof, digest); err != nil {
+		return nil, err
+	}
+	return digest, nil
+}
+
+// PrepareMessage returns the bytes hashed to the curve when signing the message. The
+// extra data is only signed by the composite hasher.
+func PrepareMessage(message, extraData []byte, useComposite bool) ([]byte, error) {
+	if !useComposite {
+		return message, nil
+	}
+	return CompositeHash(message, extraData)
+}
+
 func (BN256) UncompressKey(serialized SerializedPublicKey) ([]byte, error) {
 	pk, err := UnmarshalPk(serialized[:])
 	if err != nil {
@@ -105,7 +105,7 @@ type DoubleSigningSlasherParametersMarshaling struct {
 type DowntimeSlasherParameters struct {
 	Penalty           *big.Int `json:"penalty"`
 	Reward            *big.Int `json:"reward"`
-	SlashableDowntime uint64   `json:"slashableDowntime"`
+	SlashableDowntime uint64   `json:"slashableDowntime"` // consecutive lookback windows a validator may sign no block in
 }
 
 type DowntimeSlasherParametersMarshaling struct {
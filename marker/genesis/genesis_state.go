@@ -7,6 +7,7 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/mapprotocol/atlas/consensus/istanbul/slashing"
 	"github.com/mapprotocol/atlas/core/chain"
 	"github.com/mapprotocol/atlas/core/rawdb"
 	"github.com/mapprotocol/atlas/core/state"
@@ -108,6 +109,12 @@ func (ctx *deployContext) deploy() (chain.GenesisAlloc, error) {
 
 		// 10 Elect Validators
 		ctx.electValidators,
+
+		// 11 DoubleSigningSlasher
+		ctx.deployDoubleSigningSlasher,
+
+		// 12 DowntimeSlasher
+		ctx.deployDowntimeSlasher,
 	}
 
 	logger := ctx.logger.New()
@@ -245,6 +252,35 @@ func (ctx *deployContext) addSlasher(slasherName string) error {
 	return ctx.contract("LockedGold").SimpleCall("addSlasher", slasherName)
 }
 
+// deployDoubleSigningSlasher registers the evidence precompile as the DoubleSigningSlasher,
+// which allows the node to slash double signers on its behalf, and stores its parameters.
+func (ctx *deployContext) deployDoubleSigningSlasher() error {
+	ctx.logger.Info("Add entry to registry", "name", "DoubleSigningSlasher", "address", params.EvidenceAddress)
+	if err := ctx.contract("Registry").SimpleCall("setAddressFor", "DoubleSigningSlasher", params.EvidenceAddress); err != nil {
+		return err
+	}
+	if err := ctx.addSlasher("DoubleSigningSlasher"); err != nil {
+		return err
+	}
+	slashing.SetParameters(ctx.statedb, ctx.genesisConfig.DoubleSigningSlasher.Penalty, ctx.genesisConfig.DoubleSigningSlasher.Reward)
+	return nil
+}
+
+// deployDowntimeSlasher registers the address the node slashes validators that were down
+// from as the DowntimeSlasher, and stores its parameters.
+func (ctx *deployContext) deployDowntimeSlasher() error {
+	ctx.logger.Info("Add entry to registry", "name", "DowntimeSlasher", "address", params.DowntimeSlasherAddress)
+	if err := ctx.contract("Registry").SimpleCall("setAddressFor", "DowntimeSlasher", params.DowntimeSlasherAddress); err != nil {
+		return err
+	}
+	if err := ctx.addSlasher("DowntimeSlasher"); err != nil {
+		return err
+	}
+	cfg := ctx.genesisConfig.DowntimeSlasher
+	slashing.SetDowntimeParameters(ctx.statedb, cfg.Penalty, cfg.Reward, cfg.SlashableDowntime)
+	return nil
+}
+
 func (ctx *deployContext) deployGoldToken() error {
 	err := ctx.deployCoreContract("GoldToken", func(contract *contract.EVMBackend) error {
 		return contract.SimpleCall("initialize", env.MustProxyAddressFor("Registry"))
@@ -167,6 +167,20 @@ func prepareBlock(w *worker) (*blockState, error) {
 
 // selectAndApplyTransactions selects and applies transactions to the in flight block state.
 func (b *blockState) selectAndApplyTransactions(ctx context.Context, w *worker) error {
+	// Submit the double signing evidence known to the validator ahead of the pool transactions.
+	if istanbul, ok := w.engine.(consensus.Istanbul); ok {
+		if evidence := istanbul.EvidenceTransactions(b.header, b.state); len(evidence) > 0 {
+			from, err := types.Sender(b.signer, evidence[0])
+			if err != nil {
+				return fmt.Errorf("failed to recover the evidence transactions sender: %w", err)
+			}
+			txs := types.NewTransactionsByPriceAndNonce(b.signer, map[common.Address]types.Transactions{from: evidence}, b.header.BaseFee)
+			if err := b.commitTransactions(ctx, w, txs, b.txFeeRecipient); err != nil {
+				return fmt.Errorf("failed to commit evidence transactions: %w", err)
+			}
+		}
+	}
+
 	// Fill the block with all available pending transactions.
 	pending := w.eth.TxPool().Pending(false)
 
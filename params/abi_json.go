@@ -11,6 +11,9 @@ type BlockHeader struct {
 
 contract HeaderStore {
     event UpdateBlockHeader(address indexed account, uint256 indexed blockHeight);
+    event ChainHeadChanged(uint256 indexed chainID, uint256 indexed number, bytes32 hash);
+    event ChainReorg(uint256 indexed chainID, uint256 depth, uint256 oldNumber, bytes32 oldHash, uint256 newNumber, bytes32 newHash, uint256 ancestorNumber, bytes32 ancestorHash);
+    event HeadersInserted(uint256 indexed chainID, bytes headers);
     function updateBlockHeader(bytes memory blockHeader) public {}
     function currentNumberAndHash(uint256 chainID) public returns (uint256 number, bytes memory hash) {}
     function setRelayer(address relayer) public {}
@@ -39,6 +42,105 @@ const HeaderStoreABIJSON = `[
 	   "name": "UpdateBlockHeader",
 	   "type": "event"
 	},
+	{
+	   "anonymous": false,
+	   "inputs": [
+		  {
+			 "indexed": true,
+			 "internalType": "uint256",
+			 "name": "chainID",
+			 "type": "uint256"
+		  },
+		  {
+			 "indexed": true,
+			 "internalType": "uint256",
+			 "name": "number",
+			 "type": "uint256"
+		  },
+		  {
+			 "indexed": false,
+			 "internalType": "bytes32",
+			 "name": "hash",
+			 "type": "bytes32"
+		  }
+	   ],
+	   "name": "ChainHeadChanged",
+	   "type": "event"
+	},
+	{
+	   "anonymous": false,
+	   "inputs": [
+		  {
+			 "indexed": true,
+			 "internalType": "uint256",
+			 "name": "chainID",
+			 "type": "uint256"
+		  },
+		  {
+			 "indexed": false,
+			 "internalType": "uint256",
+			 "name": "depth",
+			 "type": "uint256"
+		  },
+		  {
+			 "indexed": false,
+			 "internalType": "uint256",
+			 "name": "oldNumber",
+			 "type": "uint256"
+		  },
+		  {
+			 "indexed": false,
+			 "internalType": "bytes32",
+			 "name": "oldHash",
+			 "type": "bytes32"
+		  },
+		  {
+			 "indexed": false,
+			 "internalType": "uint256",
+			 "name": "newNumber",
+			 "type": "uint256"
+		  },
+		  {
+			 "indexed": false,
+			 "internalType": "bytes32",
+			 "name": "newHash",
+			 "type": "bytes32"
+		  },
+		  {
+			 "indexed": false,
+			 "internalType": "uint256",
+			 "name": "ancestorNumber",
+			 "type": "uint256"
+		  },
+		  {
+			 "indexed": false,
+			 "internalType": "bytes32",
+			 "name": "ancestorHash",
+			 "type": "bytes32"
+		  }
+	   ],
+	   "name": "ChainReorg",
+	   "type": "event"
+	},
+	{
+	   "anonymous": false,
+	   "inputs": [
+		  {
+			 "indexed": true,
+			 "internalType": "uint256",
+			 "name": "chainID",
+			 "type": "uint256"
+		  },
+		  {
+			 "indexed": false,
+			 "internalType": "bytes",
+			 "name": "headers",
+			 "type": "bytes"
+		  }
+	   ],
+	   "name": "HeadersInserted",
+	   "type": "event"
+	},
 	{
 	   "inputs": [
 		  {
@@ -174,8 +276,17 @@ type TxProve struct {
 	TxIndex     uint
 }
 
+type BatchTxProve struct {
+	BlockNumber uint64
+	Receipts    []*ethtypes.Receipt
+	TxIndexes   []uint
+	Prove       light.NodeList
+}
+
 contract TxVerify {
     function verifyProofData(bytes memory receiptProof) public returns(bool success, string memory message, bytes memory logs) {}
+    function verifyBatchProofData(bytes memory receiptProof) public returns(bool success, string memory message, bytes memory logs) {}
+    function verifyFilteredProofData(bytes memory receiptProof, address logAddress, bytes32 topic) public returns(bool success, string memory message, bytes memory logs) {}
 }
 */
 const TxVerifyABIJSON = `[
@@ -207,5 +318,536 @@ const TxVerifyABIJSON = `[
 		],
 		"stateMutability": "nonpayable",
 		"type": "function"
+	},
+	{
+		"inputs": [
+			{
+				"internalType": "bytes",
+				"name": "receiptProof",
+				"type": "bytes"
+			}
+		],
+		"name": "verifyBatchProofData",
+		"outputs": [
+			{
+				"internalType": "bool",
+				"name": "success",
+				"type": "bool"
+			},
+			{
+				"internalType": "string",
+				"name": "message",
+				"type": "string"
+			},
+			{
+				"internalType": "bytes",
+				"name": "logs",
+				"type": "bytes"
+			}
+		],
+		"stateMutability": "nonpayable",
+		"type": "function"
+	},
+	{
+		"inputs": [
+			{
+				"internalType": "bytes",
+				"name": "receiptProof",
+				"type": "bytes"
+			},
+			{
+				"internalType": "address",
+				"name": "logAddress",
+				"type": "address"
+			},
+			{
+				"internalType": "bytes32",
+				"name": "topic",
+				"type": "bytes32"
+			}
+		],
+		"name": "verifyFilteredProofData",
+		"outputs": [
+			{
+				"internalType": "bool",
+				"name": "success",
+				"type": "bool"
+			},
+			{
+				"internalType": "string",
+				"name": "message",
+				"type": "string"
+			},
+			{
+				"internalType": "bytes",
+				"name": "logs",
+				"type": "bytes"
+			}
+		],
+		"stateMutability": "nonpayable",
+		"type": "function"
+	}
+]`
+
+// RelayerRegistryABIJSON relayer registry abi json
+/*
+
+contract RelayerRegistry {
+    event RelayerRegistered(uint256 indexed chainID, address indexed relayer, uint256 stake);
+    event RelayerDeregistered(uint256 indexed chainID, address indexed relayer);
+    event RelayerRotated(uint256 indexed chainID, address indexed oldRelayer, address indexed newRelayer);
+    function register(uint256 chainID) public payable {}
+    function deregister(uint256 chainID) public {}
+    function rotate(uint256 chainID, address newRelayer) public {}
+    function getRelayers(uint256 chainID) public view returns (address[] memory relayers, uint256[] memory stakes) {}
+    function getEpochSubmissions(uint256 epoch) public view returns (address[] memory relayers, uint256[] memory chainIDs, uint256[] memory headers) {}
+}
+*/
+const RelayerRegistryABIJSON = `[
+	{
+		"anonymous": false,
+		"inputs": [
+			{
+				"indexed": true,
+				"internalType": "uint256",
+				"name": "chainID",
+				"type": "uint256"
+			},
+			{
+				"indexed": true,
+				"internalType": "address",
+				"name": "relayer",
+				"type": "address"
+			},
+			{
+				"indexed": false,
+				"internalType": "uint256",
+				"name": "stake",
+				"type": "uint256"
+			}
+		],
+		"name": "RelayerRegistered",
+		"type": "event"
+	},
+	{
+		"anonymous": false,
+		"inputs": [
+			{
+				"indexed": true,
+				"internalType": "uint256",
+				"name": "chainID",
+				"type": "uint256"
+			},
+			{
+				"indexed": true,
+				"internalType": "address",
+				"name": "relayer",
+				"type": "address"
+			}
+		],
+		"name": "RelayerDeregistered",
+		"type": "event"
+	},
+	{
+		"anonymous": false,
+		"inputs": [
+			{
+				"indexed": true,
+				"internalType": "uint256",
+				"name": "chainID",
+				"type": "uint256"
+			},
+			{
+				"indexed": true,
+				"internalType": "address",
+				"name": "oldRelayer",
+				"type": "address"
+			},
+			{
+				"indexed": true,
+				"internalType": "address",
+				"name": "newRelayer",
+				"type": "address"
+			}
+		],
+		"name": "RelayerRotated",
+		"type": "event"
+	},
+	{
+		"inputs": [
+			{
+				"internalType": "uint256",
+				"name": "chainID",
+				"type": "uint256"
+			}
+		],
+		"name": "register",
+		"outputs": [],
+		"stateMutability": "payable",
+		"type": "function"
+	},
+	{
+		"inputs": [
+			{
+				"internalType": "uint256",
+				"name": "chainID",
+				"type": "uint256"
+			}
+		],
+		"name": "deregister",
+		"outputs": [],
+		"stateMutability": "nonpayable",
+		"type": "function"
+	},
+	{
+		"inputs": [
+			{
+				"internalType": "uint256",
+				"name": "chainID",
+				"type": "uint256"
+			},
+			{
+				"internalType": "address",
+				"name": "newRelayer",
+				"type": "address"
+			}
+		],
+		"name": "rotate",
+		"outputs": [],
+		"stateMutability": "nonpayable",
+		"type": "function"
+	},
+	{
+		"inputs": [
+			{
+				"internalType": "uint256",
+				"name": "chainID",
+				"type": "uint256"
+			}
+		],
+		"name": "getRelayers",
+		"outputs": [
+			{
+				"internalType": "address[]",
+				"name": "relayers",
+				"type": "address[]"
+			},
+			{
+				"internalType": "uint256[]",
+				"name": "stakes",
+				"type": "uint256[]"
+			}
+		],
+		"stateMutability": "view",
+		"type": "function"
+	},
+	{
+		"inputs": [
+			{
+				"internalType": "uint256",
+				"name": "epoch",
+				"type": "uint256"
+			}
+		],
+		"name": "getEpochSubmissions",
+		"outputs": [
+			{
+				"internalType": "address[]",
+				"name": "relayers",
+				"type": "address[]"
+			},
+			{
+				"internalType": "uint256[]",
+				"name": "chainIDs",
+				"type": "uint256[]"
+			},
+			{
+				"internalType": "uint256[]",
+				"name": "headers",
+				"type": "uint256[]"
+			}
+		],
+		"stateMutability": "view",
+		"type": "function"
+	}
+]`
+
+// LightClientUpdateABIJSON light client update abi json
+/*
+
+contract LightNode {
+    struct blockHeader {
+        bytes32 parentHash;
+        address coinbase;
+        bytes32 root;
+        bytes32 txHash;
+        bytes32 receiptHash;
+        bytes bloom;
+        uint256 number;
+        uint256 gasLimit;
+        uint256 gasUsed;
+        uint256 time;
+        bytes extraData;
+        bytes32 mixDigest;
+        bytes8 nonce;
+        uint256 baseFee;
+    }
+    struct istanbulAggregatedSeal {
+        uint256 bitmap;
+        bytes signature;
+        uint256 round;
+    }
+    struct istanbulExtra {
+        address[] addedValidators;
+        bytes[] addedPubKey;
+        bytes[] addedG1PubKey;
+        uint256 removeList;
+        bytes seal;
+        istanbulAggregatedSeal aggregatedSeal;
+        istanbulAggregatedSeal parentAggregatedSeal;
+    }
+    function updateBlockHeader(blockHeader memory header, istanbulExtra memory ist, bytes memory aggPk) public {}
+}
+*/
+const LightClientUpdateABIJSON = `[
+	{
+		"inputs": [
+			{
+				"components": [
+					{
+						"internalType": "bytes32",
+						"name": "parentHash",
+						"type": "bytes32"
+					},
+					{
+						"internalType": "address",
+						"name": "coinbase",
+						"type": "address"
+					},
+					{
+						"internalType": "bytes32",
+						"name": "root",
+						"type": "bytes32"
+					},
+					{
+						"internalType": "bytes32",
+						"name": "txHash",
+						"type": "bytes32"
+					},
+					{
+						"internalType": "bytes32",
+						"name": "receiptHash",
+						"type": "bytes32"
+					},
+					{
+						"internalType": "bytes",
+						"name": "bloom",
+						"type": "bytes"
+					},
+					{
+						"internalType": "uint256",
+						"name": "number",
+						"type": "uint256"
+					},
+					{
+						"internalType": "uint256",
+						"name": "gasLimit",
+						"type": "uint256"
+					},
+					{
+						"internalType": "uint256",
+						"name": "gasUsed",
+						"type": "uint256"
+					},
+					{
+						"internalType": "uint256",
+						"name": "time",
+						"type": "uint256"
+					},
+					{
+						"internalType": "bytes",
+						"name": "extraData",
+						"type": "bytes"
+					},
+					{
+						"internalType": "bytes32",
+						"name": "mixDigest",
+						"type": "bytes32"
+					},
+					{
+						"internalType": "bytes8",
+						"name": "nonce",
+						"type": "bytes8"
+					},
+					{
+						"internalType": "uint256",
+						"name": "baseFee",
+						"type": "uint256"
+					}
+				],
+				"internalType": "struct LightNode.blockHeader",
+				"name": "header",
+				"type": "tuple"
+			},
+			{
+				"components": [
+					{
+						"internalType": "address[]",
+						"name": "addedValidators",
+						"type": "address[]"
+					},
+					{
+						"internalType": "bytes[]",
+						"name": "addedPubKey",
+						"type": "bytes[]"
+					},
+					{
+						"internalType": "bytes[]",
+						"name": "addedG1PubKey",
+						"type": "bytes[]"
+					},
+					{
+						"internalType": "uint256",
+						"name": "removeList",
+						"type": "uint256"
+					},
+					{
+						"internalType": "bytes",
+						"name": "seal",
+						"type": "bytes"
+					},
+					{
+						"components": [
+							{
+								"internalType": "uint256",
+								"name": "bitmap",
+								"type": "uint256"
+							},
+							{
+								"internalType": "bytes",
+								"name": "signature",
+								"type": "bytes"
+							},
+							{
+								"internalType": "uint256",
+								"name": "round",
+								"type": "uint256"
+							}
+						],
+						"internalType": "struct LightNode.istanbulAggregatedSeal",
+						"name": "aggregatedSeal",
+						"type": "tuple"
+					},
+					{
+						"components": [
+							{
+								"internalType": "uint256",
+								"name": "bitmap",
+								"type": "uint256"
+							},
+							{
+								"internalType": "bytes",
+								"name": "signature",
+								"type": "bytes"
+							},
+							{
+								"internalType": "uint256",
+								"name": "round",
+								"type": "uint256"
+							}
+						],
+						"internalType": "struct LightNode.istanbulAggregatedSeal",
+						"name": "parentAggregatedSeal",
+						"type": "tuple"
+					}
+				],
+				"internalType": "struct LightNode.istanbulExtra",
+				"name": "ist",
+				"type": "tuple"
+			},
+			{
+				"internalType": "bytes",
+				"name": "aggPk",
+				"type": "bytes"
+			}
+		],
+		"name": "updateBlockHeader",
+		"outputs": [],
+		"stateMutability": "nonpayable",
+		"type": "function"
+	}
+]`
+
+// EvidenceABIJSON double signing evidence abi json
+/*
+
+contract Evidence {
+    event DoubleSigned(address indexed signer, uint256 indexed number, address indexed reporter);
+    function submitEvidence(bytes memory evidence) public {}
+    function isSlashed(address signer, uint256 number) public view returns (bool slashed) {}
+}
+*/
+const EvidenceABIJSON = `[
+	{
+		"anonymous": false,
+		"inputs": [
+			{
+				"indexed": true,
+				"internalType": "address",
+				"name": "signer",
+				"type": "address"
+			},
+			{
+				"indexed": true,
+				"internalType": "uint256",
+				"name": "number",
+				"type": "uint256"
+			},
+			{
+				"indexed": true,
+				"internalType": "address",
+				"name": "reporter",
+				"type": "address"
+			}
+		],
+		"name": "DoubleSigned",
+		"type": "event"
+	},
+	{
+		"inputs": [
+			{
+				"internalType": "bytes",
+				"name": "evidence",
+				"type": "bytes"
+			}
+		],
+		"name": "submitEvidence",
+		"outputs": [],
+		"stateMutability": "nonpayable",
+		"type": "function"
+	},
+	{
+		"inputs": [
+			{
+				"internalType": "address",
+				"name": "signer",
+				"type": "address"
+			},
+			{
+				"internalType": "uint256",
+				"name": "number",
+				"type": "uint256"
+			}
+		],
+		"name": "isSlashed",
+		"outputs": [
+			{
+				"internalType": "bool",
+				"name": "slashed",
+				"type": "bool"
+			}
+		],
+		"stateMutability": "view",
+		"type": "function"
 	}
 ]`
@@ -21,6 +21,35 @@ var (
 	NewRelayerAddress  = common.BytesToAddress([]byte("relayerAddress"))
 	HeaderStoreAddress = common.BytesToAddress([]byte("headerstoreAddress"))
 	TxVerifyAddress    = common.BytesToAddress([]byte("txVerifyAddress"))
+
+	RelayerRegistryAddress = common.BytesToAddress([]byte("relayerRegistry"))
+	MmrAddress             = common.BytesToAddress([]byte("mmrAddress")) // storage slot n holds the MMR root committed by block n
+	EvidenceAddress        = common.BytesToAddress([]byte("evidenceAddress"))
+	DowntimeSlasherAddress = common.BytesToAddress([]byte("downtimeSlasher")) // slashes and jails validators that were down, registered as the DowntimeSlasher
+)
+
+// Header relayer economics, active from the relayer fork block.
+var (
+	RelayerMinStake = new(big.Int).Mul(big.NewInt(100_000), big.NewInt(1e18)) // minimum stake to relay headers of a chain
+
+	RelayerRewardPercent  = big.NewInt(5) // share of the validator epoch reward paid to relayers
+	RelayerPenaltyPercent = big.NewInt(1) // share of the stake burnt when a relayer submits no headers in an epoch
+)
+
+// Default double signing slashing, used when the genesis does not configure it. The penalty
+// is taken from the locked MAP of the offender, the reward is paid out of it to the reporter.
+var (
+	DoubleSigningPenalty = new(big.Int).Mul(big.NewInt(9_000), big.NewInt(1e18))
+	DoubleSigningReward  = new(big.Int).Mul(big.NewInt(1_000), big.NewInt(1e18))
+)
+
+// Default downtime slashing, used when the genesis does not configure it. A validator that
+// signed no block for more than DowntimeSlashableWindows consecutive lookback windows of an
+// epoch is slashed, the reward goes to the proposer of the last block of the epoch.
+var (
+	DowntimeSlashingPenalty         = new(big.Int).Mul(big.NewInt(100), big.NewInt(1e18))
+	DowntimeSlashingReward          = new(big.Int).Mul(big.NewInt(10), big.NewInt(1e18))
+	DowntimeSlashableWindows uint64 = 4
 )
 
 const (
@@ -65,6 +94,8 @@ var (
 	GasPriceMinimumRegistryId      = makeRegistryId("GasPriceMinimum")
 	GoldTokenRegistryId            = makeRegistryId("GoldToken")
 	GovernanceRegistryId           = makeRegistryId("Governance")
+	DoubleSigningSlasherRegistryId = makeRegistryId("DoubleSigningSlasher")
+	DowntimeSlasherRegistryId      = makeRegistryId("DowntimeSlasher")
 	LockedGoldRegistryId           = makeRegistryId("LockedGold")
 	RandomRegistryId               = makeRegistryId("Random")
 
@@ -49,6 +49,8 @@ const (
 	MaxGasForIsReserveLow                          uint64 = 1 * million
 	MaxGasForGetCommunityPartnerSettingPartner     uint64 = 100 * thousand
 	MaxGasForGetMgrMaintainerAddress               uint64 = 100 * thousand
+	MaxGasForSlash                                 uint64 = 50 * million
+	MaxGasForGetVotesByAccount                     uint64 = 100 * million
 
 	////////////////////////////////////////////////////////////////////////////////////////////////
 	CallValueTransferGas uint64 = 9000  // Paid for CALL when the value transfer is non-zero.
@@ -115,9 +117,61 @@ const (
 	Bls12381MapG1Gas          uint64 = 5500  // Gas price for BLS12-381 mapping field element to G1 operation
 	Bls12381MapG2Gas          uint64 = 75000 // Gas price for BLS12-381 mapping field element to G2 operation
 
-	VerifyEth2UpdateGas uint64 = 50000 // Cost of verifying the eth2.0 light client update
+	VerifyEth2UpdateGas   uint64 = 50000  // Cost of verifying the eth2.0 light client update
+	DoubleSignEvidenceGas uint64 = 350000 // Cost of verifying double signing evidence, at most two aggregated seals.
 	////////////////////////////////////////////////////////////////////////////////////////////////
 
 	MaxCodeSize        = 49152              // Maximum bytecode to permit for a contract
 	MaxGasLimit uint64 = 0x7fffffffffffffff // Maximum the gas limit (2^63-1).
 )
+
+// DefaultHeaderRetention keeps a ring buffer of the 100000 most recent heights, and
+// prunes side chains once they are deeper than any reorg seen on the followed networks.
+var DefaultHeaderRetention = &HeaderRetention{
+	Window:             100_000,
+	CheckpointInterval: 10_000,
+	FinalityDepth:      1024,
+}
+
+// DefaultMaxReorgDepth is the confirmation count past which stored headers are never
+// rewritten.
+const DefaultMaxReorgDepth = 64
+
+// LightClientGas is the gas schedule of the light client precompiles. A call pays for
+// the work it makes the light client do on top of a price per byte of input.
+type LightClientGas struct {
+	HeaderStoreBase uint64 // Base price of an updateBlockHeader call
+	Eth2UpdateBase  uint64 // Base price of verifying an eth2 light client update
+	PerByte         uint64 // Price per byte of input
+
+	PerHeader     uint64 // Price per header decoded, hashed and checked against its parent
+	PerPoWSeal    uint64 // Price per ethash seal verified
+	PerSignature  uint64 // Price per ECDSA seal recovered
+	PerStateWrite uint64 // Price per header store entry written to the state
+	PerPubKey     uint64 // Price per BLS public key decompressed and aggregated
+	PerPairing    uint64 // Price per BLS pairing
+}
+
+var (
+	// LightClientGasLegacy is the schedule before the light client gas fork. Headers
+	// are paid per byte of input and eth2 updates at a flat price.
+	LightClientGasLegacy = &LightClientGas{
+		PerByte:        68,
+		Eth2UpdateBase: VerifyEth2UpdateGas,
+	}
+
+	// LightClientGasMetered is the schedule from the light client gas fork on. The
+	// verification units are priced from the BenchmarkLightClientGas benchmarks in
+	// core/vm to run at the rate of ecrecover, state writes at the price of SSTORE.
+	LightClientGasMetered = &LightClientGas{
+		HeaderStoreBase: 20000,
+		Eth2UpdateBase:  50000,
+		PerByte:         8,
+		PerHeader:       500,
+		PerPoWSeal:      450000,
+		PerSignature:    3000,
+		PerStateWrite:   20000,
+		PerPubKey:       4500,
+		PerPairing:      43000,
+	}
+)
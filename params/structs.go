@@ -99,9 +99,46 @@ type ChainConfig struct {
 	// Various consensus engines
 	Istanbul *IstanbulConfig `json:"istanbul,omitempty"`
 
-	EnableRewardBlock *big.Int `json:"rewardblock,omitempty"`
-	DeregisterBlock   *big.Int `json:"deregisterblock,omitempty"`
-	CalcBaseBlock     *big.Int `json:"calcbaseblock,omitempty"`
+	EnableRewardBlock    *big.Int `json:"rewardblock,omitempty"`
+	DeregisterBlock      *big.Int `json:"deregisterblock,omitempty"`
+	CalcBaseBlock        *big.Int `json:"calcbaseblock,omitempty"`
+	Eth2Block            *big.Int `json:"eth2block,omitempty"`            // Ethereum is followed by the beacon chain light client (nil = no fork)
+	BSCBlock             *big.Int `json:"bscblock,omitempty"`             // BNB Smart Chain light client is enabled (nil = no fork)
+	MaticBlock           *big.Int `json:"maticblock,omitempty"`           // Polygon PoS light client is enabled (nil = no fork)
+	RelayerBlock         *big.Int `json:"relayerblock,omitempty"`         // Staked relayer registry and relayer rewards are enabled (nil = no fork)
+	MmrBlock             *big.Int `json:"mmrblock,omitempty"`             // Blocks commit the MMR root of their ancestors (nil = no fork)
+	SnarkBlock           *big.Int `json:"snarkblock,omitempty"`           // Epoch blocks carry a seal over the SNARK encoding of the next validators (nil = no fork)
+	EthMergeBlock        *big.Int `json:"ethmergeblock,omitempty"`        // Ethereum headers are validated as a post-merge header chain instead of by ethash (nil = no fork)
+	LightClientGasBlock  *big.Int `json:"lightclientgasblock,omitempty"`  // Light client precompiles charge gas for the verification work they do (nil = no fork)
+	CosmosBlock          *big.Int `json:"cosmosblock,omitempty"`          // CometBFT light client of the Cosmos chains is enabled (nil = no fork)
+	NearBlock            *big.Int `json:"nearblock,omitempty"`            // NEAR light client is enabled (nil = no fork)
+	DoubleSignSlashBlock *big.Int `json:"doublesignslashblock,omitempty"` // Double signing evidence is accepted and the offenders are slashed (nil = no fork)
+	DowntimeSlashBlock   *big.Int `json:"downtimeslashblock,omitempty"`   // Validators down for too long in an epoch are slashed and jailed (nil = no fork)
+	HeaderRetentionBlock *big.Int `json:"headerretentionblock,omitempty"` // Header stores reset from this block bound the headers they keep (nil = no fork)
+	ForkChoiceBlock      *big.Int `json:"forkchoiceblock,omitempty"`      // Header stores log head changes and reject reorgs deeper than MaxReorgDepth (nil = no fork)
+	TxVerifyBatchBlock   *big.Int `json:"txverifybatchblock,omitempty"`   // Receipt proofs can be verified in batches and filtered by log (nil = no fork)
+	Eth2DenebBlock       *big.Int `json:"eth2denebblock,omitempty"`       // Eth2 update verification accepts deneb and electra updates and the configured networks (nil = no fork)
+	HeaderLogBlock       *big.Int `json:"headerlogblock,omitempty"`       // Header stores log the headers they insert, whatever the depth of the call (nil = no fork)
+
+	// MaxReorgDepth is the deepest rewrite of the canonical chain a header store accepts
+	// from ForkChoiceBlock, DefaultMaxReorgDepth if zero.
+	MaxReorgDepth uint64 `json:"maxreorgdepth,omitempty"`
+
+	// HeaderRetention is the policy of the header stores reset from HeaderRetentionBlock,
+	// DefaultHeaderRetention if empty.
+	HeaderRetention *HeaderRetention `json:"headerretention,omitempty"`
+
+	// Eth2Networks are beacon chain networks followed by the eth2 light client. An entry
+	// replaces the built-in configuration of the network with the same chain id, so a
+	// new beacon hard fork is scheduled by a chain config update.
+	Eth2Networks []*BeaconNetwork `json:"eth2networks,omitempty"`
+
+	// EthMergeSchedules are the post-merge forks of the networks followed by the ethereum
+	// light client. An entry replaces the built-in schedule of the network with the same
+	// chain id, so a hard fork or a blob parameter only fork is scheduled by a chain
+	// config update.
+	EthMergeSchedules []*EthMergeSchedule `json:"ethmergeschedules,omitempty"`
+
 	// This does not belong here but passing it to every function is not possible since that breaks
 	// some implemented interfaces and introduces churn across the geth codebase.
 	FullHeaderChainAvailable bool // False for lightest Sync mode, true otherwise
@@ -129,6 +166,46 @@ func (c *CliqueConfig) String() string {
 	return "clique"
 }
 
+// BeaconNetwork is the consensus layer configuration of an ethereum network.
+type BeaconNetwork struct {
+	ChainID               uint64       `json:"chainId"`
+	GenesisValidatorsRoot common.Hash  `json:"genesisValidatorsRoot"`
+	Forks                 []BeaconFork `json:"forks"` // ordered by activation epoch
+}
+
+// BeaconFork is a beacon chain hard fork: bellatrix, capella, deneb or electra.
+type BeaconFork struct {
+	Name    string        `json:"name"`
+	Version hexutil.Bytes `json:"version"`
+	Epoch   uint64        `json:"epoch"`
+}
+
+// EthMergeSchedule are the timestamp activated forks of an ethereum network after the
+// merge that change the header. A nil timestamp means the fork is not scheduled.
+type EthMergeSchedule struct {
+	ChainID      uint64            `json:"chainId"` // chain id of the network in the light client
+	ShanghaiTime *uint64           `json:"shanghaiTime,omitempty"`
+	CancunTime   *uint64           `json:"cancunTime,omitempty"`
+	PragueTime   *uint64           `json:"pragueTime,omitempty"`
+	OsakaTime    *uint64           `json:"osakaTime,omitempty"`
+	Blobs        []EthBlobSchedule `json:"blobs,omitempty"` // ordered by time, the first entry starts at cancun
+}
+
+// EthBlobSchedule is the EIP-4844 blob schedule of an ethereum network from a timestamp on.
+type EthBlobSchedule struct {
+	Time           uint64 `json:"time"`
+	Target         uint64 `json:"target"` // target blobs per block
+	Max            uint64 `json:"max"`    // max blobs per block
+	UpdateFraction uint64 `json:"updateFraction"`
+}
+
+// HeaderRetention bounds the headers kept by a header store.
+type HeaderRetention struct {
+	Window             uint64 `json:"window"`             // Number of most recent heights kept in the ring buffer
+	CheckpointInterval uint64 `json:"checkpointInterval"` // Spacing of the canonical hashes kept after they left the window, zero disables them
+	FinalityDepth      uint64 `json:"finalityDepth"`      // Depth below the head from which side chain headers are pruned
+}
+
 // IstanbulConfig is the consensus engine configs for Istanbul based sealing.
 type IstanbulConfig struct {
 	Epoch          uint64 `json:"epoch"`                 // Epoch length to reset votes and checkpoint
@@ -157,7 +234,7 @@ func (c *ChainConfig) String() string {
 	default:
 		engine = "unknown"
 	}
-	return fmt.Sprintf("{ChainID: %v Homestead: %v DAO: %v DAOSupport: %v EIP150: %v EIP155: %v EIP158: %v BN256Fork: %v Byzantium: %v Constantinople: %v Petersburg: %v Istanbul: %v, Muir Glacier: %v, Berlin: %v, London: %v, Reward: %v, Deregister: %v,Calc: %v,Engine: %v}",
+	return fmt.Sprintf("{ChainID: %v Homestead: %v DAO: %v DAOSupport: %v EIP150: %v EIP155: %v EIP158: %v BN256Fork: %v Byzantium: %v Constantinople: %v Petersburg: %v Istanbul: %v, Muir Glacier: %v, Berlin: %v, London: %v, Reward: %v, Deregister: %v,Calc: %v, Eth2: %v, BSC: %v, Matic: %v, Relayer: %v, Mmr: %v, Snark: %v, EthMerge: %v, LightClientGas: %v, Cosmos: %v, Near: %v, DoubleSignSlash: %v, DowntimeSlash: %v, HeaderRetention: %v, ForkChoice: %v, TxVerifyBatch: %v, Eth2Deneb: %v, HeaderLog: %v,Engine: %v}",
 		c.ChainID,
 		c.HomesteadBlock,
 		c.DAOForkBlock,
@@ -176,6 +253,23 @@ func (c *ChainConfig) String() string {
 		c.EnableRewardBlock,
 		c.DeregisterBlock,
 		c.CalcBaseBlock,
+		c.Eth2Block,
+		c.BSCBlock,
+		c.MaticBlock,
+		c.RelayerBlock,
+		c.MmrBlock,
+		c.SnarkBlock,
+		c.EthMergeBlock,
+		c.LightClientGasBlock,
+		c.CosmosBlock,
+		c.NearBlock,
+		c.DoubleSignSlashBlock,
+		c.DowntimeSlashBlock,
+		c.HeaderRetentionBlock,
+		c.ForkChoiceBlock,
+		c.TxVerifyBatchBlock,
+		c.Eth2DenebBlock,
+		c.HeaderLogBlock,
 		engine,
 	)
 }
@@ -259,6 +353,123 @@ func (c *ChainConfig) IsCalc(num *big.Int) bool {
 	return isForked(c.CalcBaseBlock, num)
 }
 
+// IsEth2 returns whether num is either equal to the Eth2 fork block or greater.
+func (c *ChainConfig) IsEth2(num *big.Int) bool {
+	return isForked(c.Eth2Block, num)
+}
+
+// IsBSC returns whether num is either equal to the BSC fork block or greater.
+func (c *ChainConfig) IsBSC(num *big.Int) bool {
+	return isForked(c.BSCBlock, num)
+}
+
+// IsMatic returns whether num is either equal to the Matic fork block or greater.
+func (c *ChainConfig) IsMatic(num *big.Int) bool {
+	return isForked(c.MaticBlock, num)
+}
+
+// IsRelayer returns whether num is either equal to the relayer fork block or greater.
+func (c *ChainConfig) IsRelayer(num *big.Int) bool {
+	return isForked(c.RelayerBlock, num)
+}
+
+// IsMmr returns whether num is either equal to the MMR fork block or greater.
+func (c *ChainConfig) IsMmr(num *big.Int) bool {
+	return isForked(c.MmrBlock, num)
+}
+
+// IsSnark returns whether num is either equal to the epoch SNARK data fork block or greater.
+func (c *ChainConfig) IsSnark(num *big.Int) bool {
+	return isForked(c.SnarkBlock, num)
+}
+
+// IsEthMerge returns whether num is either equal to the Ethereum header chain fork block or greater.
+func (c *ChainConfig) IsEthMerge(num *big.Int) bool {
+	return isForked(c.EthMergeBlock, num)
+}
+
+// IsLightClientGas returns whether num is either equal to the light client gas fork block or greater.
+func (c *ChainConfig) IsLightClientGas(num *big.Int) bool {
+	return isForked(c.LightClientGasBlock, num)
+}
+
+// IsCosmos returns whether num is either equal to the Cosmos light client fork block or greater.
+func (c *ChainConfig) IsCosmos(num *big.Int) bool {
+	return isForked(c.CosmosBlock, num)
+}
+
+// IsNear returns whether num is either equal to the NEAR light client fork block or greater.
+func (c *ChainConfig) IsNear(num *big.Int) bool {
+	return isForked(c.NearBlock, num)
+}
+
+// IsDoubleSignSlash returns whether num is either equal to the double signing slashing fork block or greater.
+func (c *ChainConfig) IsDoubleSignSlash(num *big.Int) bool {
+	return isForked(c.DoubleSignSlashBlock, num)
+}
+
+// IsDowntimeSlash returns whether num is either equal to the downtime slashing fork block or greater.
+func (c *ChainConfig) IsDowntimeSlash(num *big.Int) bool {
+	return isForked(c.DowntimeSlashBlock, num)
+}
+
+// IsHeaderRetention returns whether num is either equal to the header retention fork block or greater.
+func (c *ChainConfig) IsHeaderRetention(num *big.Int) bool {
+	return isForked(c.HeaderRetentionBlock, num)
+}
+
+// HeaderRetentionAt returns the retention policy of a header store reset at num, nil
+// if stores reset at num keep the legacy layout.
+func (c *ChainConfig) HeaderRetentionAt(num *big.Int) *HeaderRetention {
+	if !c.IsHeaderRetention(num) {
+		return nil
+	}
+	if c.HeaderRetention != nil {
+		return c.HeaderRetention
+	}
+	return DefaultHeaderRetention
+}
+
+// IsForkChoice returns whether num is either equal to the fork choice fork block or greater.
+func (c *ChainConfig) IsForkChoice(num *big.Int) bool {
+	return isForked(c.ForkChoiceBlock, num)
+}
+
+// IsTxVerifyBatch returns whether num is either equal to the batch receipt verification fork block or greater.
+func (c *ChainConfig) IsTxVerifyBatch(num *big.Int) bool {
+	return isForked(c.TxVerifyBatchBlock, num)
+}
+
+// IsEth2Deneb returns whether num is either equal to the Eth2 deneb fork block or greater.
+func (c *ChainConfig) IsEth2Deneb(num *big.Int) bool {
+	return isForked(c.Eth2DenebBlock, num)
+}
+
+// IsHeaderLog returns whether num is either equal to the header log fork block or greater.
+func (c *ChainConfig) IsHeaderLog(num *big.Int) bool {
+	return isForked(c.HeaderLogBlock, num)
+}
+
+// MaxReorgDepthAt returns the deepest reorg a header store accepts at num, zero if
+// it is not limited.
+func (c *ChainConfig) MaxReorgDepthAt(num *big.Int) uint64 {
+	if !c.IsForkChoice(num) {
+		return 0
+	}
+	if c.MaxReorgDepth != 0 {
+		return c.MaxReorgDepth
+	}
+	return DefaultMaxReorgDepth
+}
+
+// LightClientGas returns the gas schedule of the light client precompiles at num.
+func (c *ChainConfig) LightClientGas(num *big.Int) *LightClientGas {
+	if c.IsLightClientGas(num) {
+		return LightClientGasMetered
+	}
+	return LightClientGasLegacy
+}
+
 // CheckCompatible checks whether scheduled fork transitions have been imported
 // with a mismatching chain configuration.
 func (c *ChainConfig) CheckCompatible(newcfg *ChainConfig, height uint64) *ConfigCompatError {
@@ -439,6 +650,7 @@ type Rules struct {
 	IsHomestead, IsEIP150, IsEIP155, IsEIP158               bool
 	IsByzantium, IsConstantinople, IsPetersburg, IsIstanbul bool
 	IsBerlin, IsLondon, IsCatalyst                          bool
+	IsRelayer, IsDoubleSignSlash                            bool
 }
 
 // Rules ensures c's ChainID is not nil.
@@ -448,18 +660,20 @@ func (c *ChainConfig) Rules(num *big.Int) Rules {
 		chainID = new(big.Int)
 	}
 	return Rules{
-		ChainID:          new(big.Int).Set(chainID),
-		IsHomestead:      c.IsHomestead(num),
-		IsEIP150:         c.IsEIP150(num),
-		IsEIP155:         c.IsEIP155(num),
-		IsEIP158:         c.IsEIP158(num),
-		IsByzantium:      c.IsByzantium(num),
-		IsConstantinople: c.IsConstantinople(num),
-		IsPetersburg:     c.IsPetersburg(num),
-		IsIstanbul:       c.IsIstanbul(num),
-		IsBerlin:         c.IsBerlin(num),
-		IsLondon:         c.IsLondon(num),
-		IsCatalyst:       c.IsCatalyst(num),
+		ChainID:           new(big.Int).Set(chainID),
+		IsHomestead:       c.IsHomestead(num),
+		IsEIP150:          c.IsEIP150(num),
+		IsEIP155:          c.IsEIP155(num),
+		IsEIP158:          c.IsEIP158(num),
+		IsByzantium:       c.IsByzantium(num),
+		IsConstantinople:  c.IsConstantinople(num),
+		IsPetersburg:      c.IsPetersburg(num),
+		IsIstanbul:        c.IsIstanbul(num),
+		IsBerlin:          c.IsBerlin(num),
+		IsLondon:          c.IsLondon(num),
+		IsCatalyst:        c.IsCatalyst(num),
+		IsRelayer:         c.IsRelayer(num),
+		IsDoubleSignSlash: c.IsDoubleSignSlash(num),
 	}
 }
 
@@ -2,6 +2,7 @@ package tools
 
 import (
 	"bytes"
+	"crypto/ed25519"
 	"encoding/gob"
 	"encoding/hex"
 
@@ -30,6 +31,15 @@ func DeepCopy(src, dst interface{}) error {
 	return gob.NewDecoder(bytes.NewBuffer(buf.Bytes())).Decode(dst)
 }
 
+// VerifyEd25519 reports whether sig is a valid Ed25519 signature of msg by pubKey,
+// keys and signatures of the wrong size are never valid.
+func VerifyEd25519(pubKey, msg, sig []byte) bool {
+	if len(pubKey) != ed25519.PublicKeySize || len(sig) != ed25519.SignatureSize {
+		return false
+	}
+	return ed25519.Verify(pubKey, msg, sig)
+}
+
 func Bytes2Hex(bs []byte) string {
 	return hexPrefix + hex.EncodeToString(bs)
 }